@@ -0,0 +1,31 @@
+package xollm
+
+import (
+	"github.com/xostack/xollm/config"
+	"github.com/xostack/xollm/llm"
+)
+
+// RegisterModelAlias makes the logical model name alias resolve to model
+// on provider. Aliases can also be set in the [model_aliases] table of the
+// configuration.
+func RegisterModelAlias(alias, provider, model string) {
+	llm.RegisterModelAlias(alias, provider, model)
+}
+
+// ResolveModelAlias returns the identifier provider serves the logical
+// model name under, such as "llama3-8b-8192" on Groq for "llama3-8b".
+// Other names are returned unchanged with false.
+func ResolveModelAlias(provider, name string) (string, bool) {
+	return llm.ResolveModelAlias(provider, name)
+}
+
+// resolveModel returns the model to create a client of provider with:
+// the model configured for it, with aliases of the configuration's
+// [model_aliases] table taking precedence over registered ones.
+func resolveModel(cfg config.Config, provider, model string) string {
+	if mapped, ok := cfg.ModelAliases[model][provider]; ok && mapped != "" {
+		return mapped
+	}
+	resolved, _ := llm.ResolveModelAlias(provider, model)
+	return resolved
+}
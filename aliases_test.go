@@ -0,0 +1,31 @@
+package xollm
+
+import (
+	"testing"
+
+	"github.com/xostack/xollm/config"
+)
+
+func TestResolveModel(t *testing.T) {
+	cfg := config.Config{
+		ModelAliases: map[string]map[string]string{
+			"fast":      {"groq": "llama-3.1-8b-instant", "ollama": "llama3.2"},
+			"llama3-8b": {"ollama": "llama3:8b-instruct-q4_0"},
+		},
+	}
+	tests := []struct {
+		provider, model, want string
+	}{
+		{"groq", "fast", "llama-3.1-8b-instant"},
+		{"ollama", "fast", "llama3.2"},
+		{"ollama", "llama3-8b", "llama3:8b-instruct-q4_0"},
+		{"groq", "llama3-8b", "llama3-8b-8192"},
+		{"gemini", "gemma-3-27b-it", "gemma-3-27b-it"},
+		{"groq", "", ""},
+	}
+	for _, tt := range tests {
+		if got := resolveModel(cfg, tt.provider, tt.model); got != tt.want {
+			t.Errorf("resolveModel(%q, %q) = %q, want %q", tt.provider, tt.model, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// ErrPending is returned by Job.Result while the job has not finished.
+var ErrPending = errors.New("async job has not finished")
+
+// JobStatus is the state of a Job.
+type JobStatus int
+
+const (
+	// JobQueued jobs are waiting for a free worker.
+	JobQueued JobStatus = iota
+	// JobRunning jobs are being generated.
+	JobRunning
+	// JobSucceeded jobs finished with a response.
+	JobSucceeded
+	// JobFailed jobs finished with an error.
+	JobFailed
+	// JobCancelled jobs were cancelled before they finished.
+	JobCancelled
+)
+
+// String returns the lower-case name of the status.
+func (s JobStatus) String() string {
+	switch s {
+	case JobQueued:
+		return "queued"
+	case JobRunning:
+		return "running"
+	case JobSucceeded:
+		return "succeeded"
+	case JobFailed:
+		return "failed"
+	case JobCancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("JobStatus(%d)", int(s))
+	}
+}
+
+// Done reports whether the status is final.
+func (s JobStatus) Done() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// Job is a handle to a generation submitted to a JobQueue. Its methods are
+// safe for concurrent use.
+type Job struct {
+	id     string
+	prompt string
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mutex    sync.Mutex
+	status   JobStatus
+	response string
+	err      error
+}
+
+// ID returns the job's identifier, which JobQueue.Job looks up.
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Status returns the job's current state without blocking.
+func (j *Job) Status() JobStatus {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.status
+}
+
+// Done returns a channel that is closed when the job finishes.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Result returns the job's response or error without blocking. It returns
+// ErrPending while the job is queued or running, and context.Canceled once
+// it has been cancelled.
+func (j *Job) Result() (string, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if !j.status.Done() {
+		return "", ErrPending
+	}
+	return j.response, j.err
+}
+
+// Wait blocks until the job finishes or ctx is done and returns its result.
+// A ctx ending only stops the wait; use Cancel to stop the job itself.
+func (j *Job) Wait(ctx context.Context) (string, error) {
+	select {
+	case <-j.done:
+		return j.Result()
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Cancel stops the job: a queued job is never run, and a running one has
+// its context cancelled. Cancelling a finished job does nothing.
+func (j *Job) Cancel() {
+	j.mutex.Lock()
+	queued := j.status == JobQueued
+	if queued {
+		j.finish(JobCancelled, "", context.Canceled)
+	}
+	j.mutex.Unlock()
+	j.cancel()
+}
+
+// start moves a queued job to running, reporting false if it was cancelled.
+func (j *Job) start() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.status != JobQueued {
+		return false
+	}
+	j.status = JobRunning
+	return true
+}
+
+// finish records the job's result and releases its waiters. The caller
+// must hold j.mutex.
+func (j *Job) finish(status JobStatus, response string, err error) {
+	j.status, j.response, j.err = status, response, err
+	close(j.done)
+}
+
+// JobQueue runs generations on a fixed pool of workers and hands out a Job
+// for each, so callers can submit a long generation, do other work and
+// collect the result later by polling or waiting, or cancel it. Servers can
+// return the job ID to their own callers and look the job up again with Job.
+//
+// Finished jobs are kept until Forget is called for them.
+//
+// It is safe for concurrent use.
+type JobQueue struct {
+	client xollm.Client
+	queue  chan *Job
+
+	// JobTimeout bounds each generation. Defaults to 5 minutes.
+	JobTimeout time.Duration
+
+	wg     sync.WaitGroup
+	mutex  sync.RWMutex
+	jobs   map[string]*Job
+	closed bool
+}
+
+// NewJobQueue creates a queue that runs generations on client using the
+// given number of workers.
+func NewJobQueue(client xollm.Client, workers int) (*JobQueue, error) {
+	if client == nil {
+		return nil, fmt.Errorf("async job queue requires a client")
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	q := &JobQueue{
+		client:     client,
+		queue:      make(chan *Job, defaultQueueSize),
+		JobTimeout: defaultJobTimeout,
+		jobs:       make(map[string]*Job),
+	}
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+
+	return q, nil
+}
+
+// Submit enqueues a generation of prompt and returns its handle. The values
+// of ctx, such as tags and session keys, are passed to the client, but its
+// cancellation is not: the job outlives the call and is stopped with
+// Job.Cancel. It never blocks; ErrQueueFull is returned when the queue is
+// saturated.
+func (q *JobQueue) Submit(ctx context.Context, prompt string) (*Job, error) {
+	if prompt == "" {
+		return nil, fmt.Errorf("async request prompt cannot be empty")
+	}
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	job := &Job{
+		id:     newJobID(),
+		prompt: prompt,
+		ctx:    jobCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		cancel()
+		return nil, ErrClosed
+	}
+
+	select {
+	case q.queue <- job:
+		q.jobs[job.id] = job
+		return job, nil
+	default:
+		cancel()
+		return nil, ErrQueueFull
+	}
+}
+
+// Job returns the job with the given ID, if it has not been forgotten.
+func (q *JobQueue) Job(id string) (*Job, bool) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	job, ok := q.jobs[id]
+	return job, ok
+}
+
+// Forget drops the job with the given ID from the queue's index, cancelling
+// it if it has not finished.
+func (q *JobQueue) Forget(id string) {
+	q.mutex.Lock()
+	job, ok := q.jobs[id]
+	delete(q.jobs, id)
+	q.mutex.Unlock()
+	if ok {
+		job.Cancel()
+	}
+}
+
+// Close stops accepting new jobs and waits for queued and running jobs to
+// finish. Jobs can still be looked up and collected afterwards. It does not
+// close the wrapped client. Close is idempotent.
+func (q *JobQueue) Close() error {
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.queue)
+	q.mutex.Unlock()
+
+	q.wg.Wait()
+	return nil
+}
+
+// worker runs queued jobs until the queue is closed.
+func (q *JobQueue) worker() {
+	defer q.wg.Done()
+	for job := range q.queue {
+		q.run(job)
+	}
+}
+
+// run executes a single job unless it was cancelled while queued.
+func (q *JobQueue) run(job *Job) {
+	defer job.cancel()
+	if !job.start() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(job.ctx, q.JobTimeout)
+	defer cancel()
+	response, err := q.client.Generate(ctx, job.prompt)
+
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	switch {
+	case err == nil:
+		job.finish(JobSucceeded, response, nil)
+	case errors.Is(job.ctx.Err(), context.Canceled):
+		job.finish(JobCancelled, "", fmt.Errorf("async job cancelled: %w", err))
+	default:
+		job.finish(JobFailed, "", err)
+	}
+}
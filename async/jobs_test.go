@@ -0,0 +1,215 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type jobsCtxKey struct{}
+
+func waitDone(t *testing.T, job *Job) {
+	t.Helper()
+	select {
+	case <-job.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for job %s", job.ID())
+	}
+}
+
+func TestNewJobQueue_Validation(t *testing.T) {
+	if _, err := NewJobQueue(nil, 1); err == nil {
+		t.Error("Expected error for nil client")
+	}
+
+	q, err := NewJobQueue(&mockClient{}, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer q.Close()
+
+	if _, err := q.Submit(context.Background(), ""); err == nil {
+		t.Error("Expected error for empty prompt")
+	}
+}
+
+func TestJobQueue_SubmitAndWait(t *testing.T) {
+	release := make(chan struct{})
+	client := &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+		<-release
+		if ctx.Value(jobsCtxKey{}) != "kept" {
+			t.Error("Expected the submit context's values to reach the client")
+		}
+		return "Done: " + prompt, nil
+	}}
+	q, err := NewJobQueue(client, 1)
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	// The job must outlive the context it was submitted with
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), jobsCtxKey{}, "kept"))
+	job, err := q.Submit(ctx, "report")
+	cancel()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := job.Result(); !errors.Is(err, ErrPending) {
+		t.Errorf("Expected ErrPending before the job finished, got: %v", err)
+	}
+	if job.Status().Done() {
+		t.Errorf("Expected an unfinished status, got %s", job.Status())
+	}
+	if found, ok := q.Job(job.ID()); !ok || found != job {
+		t.Error("Expected the job to be found by its ID")
+	}
+
+	close(release)
+	response, err := job.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if response != "Done: report" {
+		t.Errorf("Unexpected response: %s", response)
+	}
+	if job.Status() != JobSucceeded {
+		t.Errorf("Expected status succeeded, got %s", job.Status())
+	}
+
+	q.Forget(job.ID())
+	if _, ok := q.Job(job.ID()); ok {
+		t.Error("Expected a forgotten job not to be found")
+	}
+}
+
+func TestJobQueue_GenerationError(t *testing.T) {
+	client := &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+		return "", errors.New("model overloaded")
+	}}
+	q, err := NewJobQueue(client, 1)
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	job, err := q.Submit(context.Background(), "Hello")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	waitDone(t, job)
+
+	if _, err := job.Result(); err == nil || err.Error() != "model overloaded" {
+		t.Errorf("Expected the generation error, got: %v", err)
+	}
+	if job.Status() != JobFailed {
+		t.Errorf("Expected status failed, got %s", job.Status())
+	}
+}
+
+func TestJobQueue_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	client := &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+	q, err := NewJobQueue(client, 1)
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	running, err := q.Submit(context.Background(), "first")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	queued, err := q.Submit(context.Background(), "second")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	<-started
+
+	// The single worker is busy, so the second job is still queued
+	queued.Cancel()
+	if queued.Status() != JobCancelled {
+		t.Errorf("Expected the queued job to be cancelled at once, got %s", queued.Status())
+	}
+	if _, err := queued.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+
+	running.Cancel()
+	waitDone(t, running)
+	if running.Status() != JobCancelled {
+		t.Errorf("Expected the running job to be cancelled, got %s", running.Status())
+	}
+	if _, err := running.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestJobQueue_WaitContext(t *testing.T) {
+	release := make(chan struct{})
+	client := &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+		<-release
+		return "late", nil
+	}}
+	q, err := NewJobQueue(client, 1)
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	job, err := q.Submit(context.Background(), "Hello")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := job.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to time out, got: %v", err)
+	}
+
+	// Giving up on the wait leaves the job running
+	close(release)
+	if response, err := job.Wait(context.Background()); err != nil || response != "late" {
+		t.Errorf("Expected the job to finish, got %q, %v", response, err)
+	}
+}
+
+func TestJobQueue_SubmitAfterClose(t *testing.T) {
+	q, err := NewJobQueue(&mockClient{}, 1)
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Expected no error on close, got: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Expected Close to be idempotent, got: %v", err)
+	}
+
+	if _, err := q.Submit(context.Background(), "Hello"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got: %v", err)
+	}
+}
+
+func TestJobStatus_String(t *testing.T) {
+	tests := map[JobStatus]string{
+		JobQueued:    "queued",
+		JobRunning:   "running",
+		JobSucceeded: "succeeded",
+		JobFailed:    "failed",
+		JobCancelled: "cancelled",
+		JobStatus(9): "JobStatus(9)",
+	}
+	for status, want := range tests {
+		if got := status.String(); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
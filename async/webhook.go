@@ -0,0 +1,384 @@
+// Package async provides asynchronous generation on top of xollm clients.
+//
+// The JobQueue runs generations in the background and returns a Job handle
+// for each, which the caller can poll, wait on or cancel later:
+//
+//	queue, err := async.NewJobQueue(client, 4)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer queue.Close()
+//
+//	job, err := queue.Submit(ctx, "Summarize the quarterly report")
+//	// ... do other work ...
+//	response, err := job.Wait(ctx)
+//
+// The WebhookDispatcher queues generation requests and delivers each result
+// to a caller-supplied webhook URL once it is ready. This is useful for
+// serverless frontends and other callers that cannot hold a connection open
+// for the duration of a long generation.
+//
+// Every delivery is signed with HMAC-SHA256 over its timestamp and raw JSON
+// body using a shared secret. Receivers should verify the signature with
+// VerifySignature before trusting the payload, which also rejects stale
+// timestamps so a captured delivery can't be replayed later.
+//
+// Example webhook usage:
+//
+//	dispatcher, err := async.NewWebhookDispatcher(client, "shared-secret", 4, async.WithDeliveryAttempts(5))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer dispatcher.Close()
+//
+//	id, err := dispatcher.Submit(async.WebhookRequest{
+//		Prompt:      "Summarize the quarterly report",
+//		CallbackURL: "https://example.com/hooks/llm",
+//	})
+package async
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+const (
+	// SignatureHeader carries the hex-encoded HMAC-SHA256 signature of the webhook timestamp and body.
+	SignatureHeader = "X-Xollm-Signature"
+	// TimestampHeader carries the Unix time in seconds the delivery was signed at.
+	TimestampHeader = "X-Xollm-Timestamp"
+	// DefaultSignatureTolerance is how far a delivery's timestamp may be from
+	// the receiver's clock for VerifySignature to accept it.
+	DefaultSignatureTolerance = 5 * time.Minute
+	// JobIDHeader carries the job ID of the delivered result.
+	JobIDHeader = "X-Xollm-Job-Id"
+
+	signaturePrefix      = "sha256="
+	defaultQueueSize     = 100
+	defaultJobTimeout    = 5 * time.Minute
+	defaultDeliveryTries = 3
+	deliveryRetryDelay   = 1 * time.Second
+	deliveryTimeout      = 10 * time.Second
+)
+
+var (
+	// ErrQueueFull is returned by Submit when the dispatcher queue has no free slots.
+	ErrQueueFull = errors.New("async queue is full")
+	// ErrClosed is returned by Submit after the dispatcher has been closed.
+	ErrClosed = errors.New("async dispatcher is closed")
+)
+
+// WebhookRequest describes a generation to run in the background.
+type WebhookRequest struct {
+	// ID is an optional caller-chosen job identifier. A random ID is generated if empty.
+	ID string
+	// Prompt is the prompt passed to the client's Generate method.
+	Prompt string
+	// CallbackURL is the http(s) URL that receives the result as a JSON POST.
+	CallbackURL string
+	// Metadata is echoed back unchanged in the webhook payload.
+	Metadata map[string]string
+}
+
+// WebhookPayload is the JSON body POSTed to the callback URL.
+type WebhookPayload struct {
+	ID          string            `json:"id"`
+	Provider    string            `json:"provider"`
+	Response    string            `json:"response,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	DurationMS  int64             `json:"duration_ms"`
+	CompletedAt time.Time         `json:"completed_at"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// WebhookDispatcher runs queued generations on a fixed pool of workers and
+// delivers their results to webhooks.
+//
+// It is safe for concurrent use.
+type WebhookDispatcher struct {
+	client     xollm.Client
+	secret     []byte
+	httpClient *http.Client
+	queue      chan WebhookRequest
+
+	// jobTimeout bounds each generation, see WithJobTimeout
+	jobTimeout time.Duration
+	// deliveryAttempts is the number of times a webhook POST is attempted,
+	// see WithDeliveryAttempts
+	deliveryAttempts int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// closing is closed by Close, cutting pending retry delays short
+	closing chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.RWMutex
+	closed  bool
+}
+
+// WebhookOption configures a WebhookDispatcher.
+type WebhookOption func(*WebhookDispatcher)
+
+// WithJobTimeout bounds each generation to d. It defaults to 5 minutes.
+func WithJobTimeout(d time.Duration) WebhookOption {
+	return func(w *WebhookDispatcher) {
+		w.jobTimeout = d
+	}
+}
+
+// WithDeliveryAttempts sets the number of times a webhook POST is
+// attempted. It defaults to 3.
+func WithDeliveryAttempts(n int) WebhookOption {
+	return func(w *WebhookDispatcher) {
+		w.deliveryAttempts = n
+	}
+}
+
+// NewWebhookDispatcher creates a dispatcher that runs generations on client
+// using the given number of workers and signs deliveries with secret. The
+// options apply before the workers start.
+func NewWebhookDispatcher(client xollm.Client, secret string, workers int, opts ...WebhookOption) (*WebhookDispatcher, error) {
+	if client == nil {
+		return nil, fmt.Errorf("async dispatcher requires a client")
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("async dispatcher requires a signing secret")
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &WebhookDispatcher{
+		client:           client,
+		secret:           []byte(secret),
+		httpClient:       &http.Client{Timeout: deliveryTimeout},
+		queue:            make(chan WebhookRequest, defaultQueueSize),
+		jobTimeout:       defaultJobTimeout,
+		deliveryAttempts: defaultDeliveryTries,
+		ctx:              ctx,
+		cancel:           cancel,
+		closing:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+
+	return d, nil
+}
+
+// Submit validates and enqueues a request, returning its job ID.
+// It never blocks; ErrQueueFull is returned when the queue is saturated.
+func (d *WebhookDispatcher) Submit(req WebhookRequest) (string, error) {
+	if req.Prompt == "" {
+		return "", fmt.Errorf("async request prompt cannot be empty")
+	}
+	if err := validateCallbackURL(req.CallbackURL); err != nil {
+		return "", err
+	}
+	if req.ID == "" {
+		req.ID = newJobID()
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if d.closed {
+		return "", ErrClosed
+	}
+
+	select {
+	case d.queue <- req:
+		return req.ID, nil
+	default:
+		return "", ErrQueueFull
+	}
+}
+
+// Close stops accepting new requests, waits for queued jobs to finish and
+// be delivered, and releases resources. Deliveries that failed are not
+// retried after Close is called. It does not close the wrapped client.
+// Close is idempotent.
+func (d *WebhookDispatcher) Close() error {
+	d.mutex.Lock()
+	if d.closed {
+		d.mutex.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	close(d.closing)
+	d.mutex.Unlock()
+
+	d.wg.Wait()
+	d.cancel()
+	return nil
+}
+
+// worker processes queued requests until the queue is closed.
+func (d *WebhookDispatcher) worker() {
+	defer d.wg.Done()
+	for req := range d.queue {
+		payload := d.run(req)
+		if err := d.deliver(req.CallbackURL, payload); err != nil {
+			xollm.Logger(d.ctx).Error("async: failed to deliver result", "job", req.ID, "error", err)
+		}
+	}
+}
+
+// run executes a single generation and builds its webhook payload.
+func (d *WebhookDispatcher) run(req WebhookRequest) WebhookPayload {
+	ctx, cancel := context.WithTimeout(d.ctx, d.jobTimeout)
+	defer cancel()
+
+	start := time.Now()
+	response, err := d.client.Generate(ctx, req.Prompt)
+
+	payload := WebhookPayload{
+		ID:          req.ID,
+		Provider:    d.client.ProviderName(),
+		Response:    response,
+		DurationMS:  time.Since(start).Milliseconds(),
+		CompletedAt: time.Now().UTC(),
+		Metadata:    req.Metadata,
+	}
+	if err != nil {
+		payload.Response = ""
+		payload.Error = err.Error()
+	}
+	return payload
+}
+
+// deliver POSTs the signed payload to the callback URL, retrying on failure
+// until the dispatcher is closed.
+func (d *WebhookDispatcher) deliver(callbackURL string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	attempts := d.deliveryAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(deliveryRetryDelay):
+			case <-d.closing:
+				return lastErr
+			case <-d.ctx.Done():
+				return lastErr
+			}
+		}
+
+		req, err := http.NewRequestWithContext(d.ctx, "POST", callbackURL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create webhook request: %w", err)
+		}
+		// Each attempt is signed afresh, so retries carry a current timestamp
+		timestamp := time.Now().Unix()
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, Sign(d.secret, timestamp, body))
+		req.Header.Set(JobIDHeader, payload.ID)
+
+		resp, err := d.httpClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send webhook to %s: %w", callbackURL, err)
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook %s responded with status %s", callbackURL, resp.Status)
+		// Client errors will not succeed on retry
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+// Sign returns the signature header value for body sent at timestamp, in
+// Unix seconds, using secret. The signature covers timestamp + "." + body.
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature (the SignatureHeader value) is
+// a valid HMAC-SHA256 signature of timestamp (the TimestampHeader value)
+// and body for secret, and timestamp is within tolerance of the current
+// time. A tolerance <= 0 means DefaultSignatureTolerance. Receivers should
+// call this before trusting a webhook payload:
+//
+//	ok := async.VerifySignature(secret, body, r.Header.Get(async.TimestampHeader),
+//		r.Header.Get(async.SignatureHeader), 0)
+func VerifySignature(secret []byte, body []byte, timestamp, signature string, tolerance time.Duration) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultSignatureTolerance
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return false
+	}
+	expected := Sign(secret, ts, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// validateCallbackURL checks that the callback URL is an absolute http(s) URL.
+func validateCallbackURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("async request callback URL is required")
+	}
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid callback URL '%s': %w", rawURL, err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("callback URL scheme must be http or https, got '%s'", parsedURL.Scheme)
+	}
+	if parsedURL.Host == "" {
+		return fmt.Errorf("callback URL '%s' has no host", rawURL)
+	}
+	return nil
+}
+
+// newJobID returns a random 16-byte hex job identifier.
+func newJobID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand failing is exceptional; fall back to a time-based ID
+		return strings.ReplaceAll(time.Now().UTC().Format("20060102150405.000000000"), ".", "")
+	}
+	return hex.EncodeToString(b[:])
+}
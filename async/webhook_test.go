@@ -0,0 +1,344 @@
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	generateFunc func(ctx context.Context, prompt string) (string, error)
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if m.generateFunc != nil {
+		return m.generateFunc(ctx, prompt)
+	}
+	return fmt.Sprintf("Mock response to: %s", prompt), nil
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error { return nil }
+
+// webhookReceiver records deliveries and verifies signatures
+type webhookReceiver struct {
+	t        *testing.T
+	secret   []byte
+	mutex    sync.Mutex
+	payloads []WebhookPayload
+	received chan struct{}
+}
+
+func newWebhookReceiver(t *testing.T, secret string) *webhookReceiver {
+	return &webhookReceiver{t: t, secret: []byte(secret), received: make(chan struct{}, 10)}
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		wr.t.Errorf("Failed to read webhook body: %v", err)
+		return
+	}
+
+	if !VerifySignature(wr.secret, body, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), 0) {
+		wr.t.Errorf("Webhook signature did not verify: %s", r.Header.Get(SignatureHeader))
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		wr.t.Errorf("Failed to decode webhook payload: %v", err)
+	}
+
+	if r.Header.Get(JobIDHeader) != payload.ID {
+		wr.t.Errorf("Expected job ID header '%s', got '%s'", payload.ID, r.Header.Get(JobIDHeader))
+	}
+
+	wr.mutex.Lock()
+	wr.payloads = append(wr.payloads, payload)
+	wr.mutex.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+	wr.received <- struct{}{}
+}
+
+func (wr *webhookReceiver) wait(t *testing.T, n int) []WebhookPayload {
+	for i := 0; i < n; i++ {
+		select {
+		case <-wr.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for webhook %d of %d", i+1, n)
+		}
+	}
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+	return append([]WebhookPayload(nil), wr.payloads...)
+}
+
+func TestNewWebhookDispatcher_Validation(t *testing.T) {
+	if _, err := NewWebhookDispatcher(nil, "secret", 1); err == nil {
+		t.Error("Expected error for nil client")
+	}
+
+	if _, err := NewWebhookDispatcher(&mockClient{}, "", 1); err == nil {
+		t.Error("Expected error for empty secret")
+	}
+
+	d, err := NewWebhookDispatcher(&mockClient{}, "secret", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer d.Close()
+}
+
+func TestWebhookDispatcher_DeliversSignedResult(t *testing.T) {
+	receiver := newWebhookReceiver(t, "secret")
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	d, err := NewWebhookDispatcher(&mockClient{}, "secret", 2)
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	defer d.Close()
+
+	id, err := d.Submit(WebhookRequest{
+		ID:          "job-1",
+		Prompt:      "Hello",
+		CallbackURL: server.URL,
+		Metadata:    map[string]string{"user": "42"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if id != "job-1" {
+		t.Errorf("Expected job ID 'job-1', got '%s'", id)
+	}
+
+	payloads := receiver.wait(t, 1)
+	if payloads[0].Response != "Mock response to: Hello" {
+		t.Errorf("Unexpected response: %s", payloads[0].Response)
+	}
+	if payloads[0].Provider != "mock" {
+		t.Errorf("Expected provider 'mock', got '%s'", payloads[0].Provider)
+	}
+	if payloads[0].Metadata["user"] != "42" {
+		t.Errorf("Expected metadata to be echoed, got %v", payloads[0].Metadata)
+	}
+}
+
+func TestWebhookDispatcher_DeliversGenerationError(t *testing.T) {
+	receiver := newWebhookReceiver(t, "secret")
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	client := &mockClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			return "", errors.New("model exploded")
+		},
+	}
+	d, err := NewWebhookDispatcher(client, "secret", 1)
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	defer d.Close()
+
+	id, err := d.Submit(WebhookRequest{Prompt: "Hello", CallbackURL: server.URL})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if id == "" {
+		t.Error("Expected a generated job ID")
+	}
+
+	payloads := receiver.wait(t, 1)
+	if payloads[0].Error != "model exploded" {
+		t.Errorf("Expected error to be delivered, got '%s'", payloads[0].Error)
+	}
+	if payloads[0].ID != id {
+		t.Errorf("Expected payload ID '%s', got '%s'", id, payloads[0].ID)
+	}
+}
+
+func TestWebhookDispatcher_JobTimeout(t *testing.T) {
+	receiver := newWebhookReceiver(t, "secret")
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	client := &mockClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	}
+	d, err := NewWebhookDispatcher(client, "secret", 1, WithJobTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	defer d.Close()
+
+	if _, err := d.Submit(WebhookRequest{Prompt: "Hello", CallbackURL: server.URL}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if payloads := receiver.wait(t, 1); payloads[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected the job to time out, got %q", payloads[0].Error)
+	}
+}
+
+func TestWebhookDispatcher_SubmitValidation(t *testing.T) {
+	d, err := NewWebhookDispatcher(&mockClient{}, "secret", 1)
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	defer d.Close()
+
+	tests := []struct {
+		name string
+		req  WebhookRequest
+	}{
+		{name: "empty prompt", req: WebhookRequest{CallbackURL: "http://localhost/hook"}},
+		{name: "missing callback", req: WebhookRequest{Prompt: "hi"}},
+		{name: "bad scheme", req: WebhookRequest{Prompt: "hi", CallbackURL: "ftp://localhost/hook"}},
+		{name: "no host", req: WebhookRequest{Prompt: "hi", CallbackURL: "http:///hook"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := d.Submit(tt.req); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcher_SubmitAfterClose(t *testing.T) {
+	d, err := NewWebhookDispatcher(&mockClient{}, "secret", 1)
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+
+	if err := d.Close(); err != nil {
+		t.Fatalf("Expected no error from Close(), got: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Errorf("Expected second Close() to succeed (idempotent), got: %v", err)
+	}
+
+	_, err = d.Submit(WebhookRequest{Prompt: "hi", CallbackURL: "http://localhost/hook"})
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got: %v", err)
+	}
+}
+
+func TestWebhookDispatcher_DeliveryRetriesServerErrors(t *testing.T) {
+	var mutex sync.Mutex
+	attempts := 0
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		close(done)
+	}))
+	defer server.Close()
+
+	d, err := NewWebhookDispatcher(&mockClient{}, "secret", 1)
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	defer d.Close()
+
+	if _, err := d.Submit(WebhookRequest{Prompt: "hi", CallbackURL: server.URL}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for webhook retry")
+	}
+}
+
+func TestWebhookDispatcher_CloseSkipsRetryDelay(t *testing.T) {
+	attempted := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempted <- struct{}{}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	d, err := NewWebhookDispatcher(&mockClient{}, "secret", 1, WithDeliveryAttempts(10))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	if _, err := d.Submit(WebhookRequest{Prompt: "hi", CallbackURL: server.URL}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	<-attempted
+
+	start := time.Now()
+	d.Close()
+	if elapsed := time.Since(start); elapsed > deliveryRetryDelay {
+		t.Errorf("Expected Close to skip the retry delays, took %v", elapsed)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"id":"1"}`)
+	now := time.Now().Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	signature := Sign(secret, now, body)
+
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Errorf("Expected signature to start with 'sha256=', got '%s'", signature)
+	}
+	if !VerifySignature(secret, body, timestamp, signature, 0) {
+		t.Error("Expected signature to verify")
+	}
+	if VerifySignature([]byte("other"), body, timestamp, signature, 0) {
+		t.Error("Expected signature with wrong secret to fail")
+	}
+	if VerifySignature(secret, []byte(`{"id":"2"}`), timestamp, signature, 0) {
+		t.Error("Expected signature over tampered body to fail")
+	}
+	if VerifySignature(secret, body, strconv.FormatInt(now+1, 10), signature, 0) {
+		t.Error("Expected signature with tampered timestamp to fail")
+	}
+	if VerifySignature(secret, body, "", signature, 0) {
+		t.Error("Expected signature without timestamp to fail")
+	}
+}
+
+func TestVerifySignature_Replay(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"id":"1"}`)
+	old := time.Now().Add(-10 * time.Minute).Unix()
+	signature := Sign(secret, old, body)
+
+	if VerifySignature(secret, body, strconv.FormatInt(old, 10), signature, 0) {
+		t.Error("Expected a delivery older than the default tolerance to fail")
+	}
+	if !VerifySignature(secret, body, strconv.FormatInt(old, 10), signature, time.Hour) {
+		t.Error("Expected a delivery within a wider tolerance to verify")
+	}
+	future := time.Now().Add(10 * time.Minute).Unix()
+	if VerifySignature(secret, body, strconv.FormatInt(future, 10), Sign(secret, future, body), 0) {
+		t.Error("Expected a delivery from the future to fail")
+	}
+}
@@ -0,0 +1,214 @@
+// Package bench measures the latency and throughput of LLM clients under
+// load.
+//
+// Run sends prompts to a client from several workers at once, cycling
+// through the prompts, for a fixed duration or number of requests, and
+// returns the latency distribution and token throughput:
+//
+//	result, err := bench.Run(ctx, client, prompts, bench.Options{Concurrency: 8, Duration: time.Minute})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	bench.WriteText(os.Stdout, result)
+//
+// Output tokens are those reported by the provider, or estimated for
+// clients that don't report usage.
+package bench
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// Options configure a benchmark run. At least one of Duration and Requests
+// must be set; the run stops at whichever limit comes first.
+type Options struct {
+	// Concurrency is the number of requests in flight at once. If <= 0,
+	// requests are sent one at a time.
+	Concurrency int
+	// Duration is how long new requests are started for. Requests in
+	// flight when it ends are waited for.
+	Duration time.Duration
+	// Requests is the total number of requests to send.
+	Requests int
+}
+
+// Result is the outcome of benchmarking one client.
+type Result struct {
+	// Provider is the provider name of the benchmarked client.
+	Provider string
+	// Requests is the number of requests sent, including failed ones.
+	Requests int
+	// Errors is the number of failed requests.
+	Errors int
+	// LastError is the error of the last failed request, if any.
+	LastError error
+	// Elapsed is the wall time of the run.
+	Elapsed time.Duration
+	// Latencies are the durations of the successful requests, sorted.
+	Latencies []time.Duration
+	// PromptTokens and OutputTokens are the token totals of the
+	// successful requests.
+	PromptTokens int
+	OutputTokens int
+}
+
+// Percentile returns the latency below which p percent of the successful
+// requests completed, using the nearest-rank method, or 0 without any.
+func (r *Result) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(r.Latencies)))) - 1
+	rank = min(max(rank, 0), len(r.Latencies)-1)
+	return r.Latencies[rank]
+}
+
+// RequestsPerSecond returns the rate of successful requests.
+func (r *Result) RequestsPerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(len(r.Latencies)) / r.Elapsed.Seconds()
+}
+
+// TokensPerSecond returns the rate of output tokens across all workers.
+func (r *Result) TokensPerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.OutputTokens) / r.Elapsed.Seconds()
+}
+
+// Run benchmarks client with prompts. It fails only if the options or
+// prompts are invalid; request failures are counted in the result.
+// Cancelling ctx ends the run early.
+func Run(ctx context.Context, client xollm.Client, prompts []string, opts Options) (*Result, error) {
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("no prompts to benchmark with")
+	}
+	if opts.Duration <= 0 && opts.Requests <= 0 {
+		return nil, fmt.Errorf("benchmark needs a duration or a number of requests")
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	result := &Result{Provider: client.ProviderName()}
+	var mu sync.Mutex
+	var next atomic.Int64
+	start := time.Now()
+	deadline := start.Add(opts.Duration)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if opts.Duration > 0 && !time.Now().Before(deadline) {
+					return
+				}
+				n := next.Add(1) - 1
+				if opts.Requests > 0 && n >= int64(opts.Requests) {
+					return
+				}
+
+				begin := time.Now()
+				_, md, err := xollm.GenerateWithMetadata(ctx, client, prompts[n%int64(len(prompts))])
+				latency := time.Since(begin)
+
+				mu.Lock()
+				result.Requests++
+				if err != nil {
+					result.Errors++
+					result.LastError = err
+				} else {
+					result.Latencies = append(result.Latencies, latency)
+					result.PromptTokens += md.Usage.PromptTokens
+					result.OutputTokens += md.Usage.CompletionTokens
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.Elapsed = time.Since(start)
+	sort.Slice(result.Latencies, func(i, j int) bool { return result.Latencies[i] < result.Latencies[j] })
+	return result, nil
+}
+
+// ReadPrompts reads prompts from r, one per line. Blank lines and lines
+// starting with '#' are skipped.
+func ReadPrompts(r io.Reader) ([]string, error) {
+	var prompts []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prompts = append(prompts, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read prompts: %w", err)
+	}
+	return prompts, nil
+}
+
+// ReadPromptsFile reads the prompts file at path. See ReadPrompts.
+func ReadPromptsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prompts file: %w", err)
+	}
+	defer f.Close()
+	return ReadPrompts(f)
+}
+
+// WriteText writes a table of the results, one row per provider, with
+// latency percentiles and throughput.
+func WriteText(w io.Writer, results ...*Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "provider\trequests\terrors\tp50\tp90\tp99\tmax\treq/s\ttokens/s\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%v\t%v\t%v\t%v\t%.2f\t%.1f\t\n",
+			r.Provider, r.Requests, r.Errors,
+			roundLatency(r.Percentile(50)), roundLatency(r.Percentile(90)),
+			roundLatency(r.Percentile(99)), roundLatency(r.Percentile(100)),
+			r.RequestsPerSecond(), r.TokensPerSecond())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.LastError != nil {
+			if _, err := fmt.Fprintf(w, "%s: last error: %v\n", r.Provider, r.LastError); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// roundLatency rounds d for display.
+func roundLatency(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,139 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	mu       sync.Mutex
+	prompts  []string
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	delay    time.Duration
+	failOn   string
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if n := m.inFlight.Add(1); n > m.peak.Load() {
+		m.peak.Store(n)
+	}
+	defer m.inFlight.Add(-1)
+	m.mu.Lock()
+	m.prompts = append(m.prompts, prompt)
+	m.mu.Unlock()
+	time.Sleep(m.delay)
+	if prompt == m.failOn {
+		return "", errors.New("boom")
+	}
+	return "one two three four", nil
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error { return nil }
+
+func TestRun_Requests(t *testing.T) {
+	client := &mockClient{delay: 5 * time.Millisecond, failOn: "b"}
+	result, err := Run(context.Background(), client, []string{"a", "b", "c"}, Options{Concurrency: 3, Requests: 9})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Provider != "mock" || result.Requests != 9 || result.Errors != 3 || len(result.Latencies) != 6 {
+		t.Errorf("Unexpected counts: %+v", result)
+	}
+	if result.LastError == nil || result.LastError.Error() != "boom" {
+		t.Errorf("Expected the last error to be recorded, got %v", result.LastError)
+	}
+	if client.peak.Load() > 3 {
+		t.Errorf("Expected at most 3 requests in flight, got %d", client.peak.Load())
+	}
+	if result.OutputTokens <= 0 || result.TokensPerSecond() <= 0 || result.RequestsPerSecond() <= 0 {
+		t.Errorf("Expected estimated output tokens and rates, got %+v", result)
+	}
+	for i := 1; i < len(result.Latencies); i++ {
+		if result.Latencies[i] < result.Latencies[i-1] {
+			t.Fatalf("Expected sorted latencies, got %v", result.Latencies)
+		}
+	}
+}
+
+func TestRun_Duration(t *testing.T) {
+	client := &mockClient{delay: 10 * time.Millisecond}
+	start := time.Now()
+	result, err := Run(context.Background(), client, []string{"a"}, Options{Concurrency: 2, Duration: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the run to stop after its duration, took %v", elapsed)
+	}
+	if result.Requests < 2 || result.Errors != 0 {
+		t.Errorf("Expected several successful requests, got %+v", result)
+	}
+}
+
+func TestRun_InvalidOptions(t *testing.T) {
+	client := &mockClient{}
+	if _, err := Run(context.Background(), client, nil, Options{Requests: 1}); err == nil {
+		t.Error("Expected an error without prompts")
+	}
+	if _, err := Run(context.Background(), client, []string{"a"}, Options{}); err == nil {
+		t.Error("Expected an error without a duration or request count")
+	}
+}
+
+func TestResult_Percentile(t *testing.T) {
+	r := &Result{}
+	if r.Percentile(50) != 0 {
+		t.Error("Expected 0 without latencies")
+	}
+	for i := 1; i <= 10; i++ {
+		r.Latencies = append(r.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	tests := map[float64]time.Duration{0: time.Millisecond, 50: 5 * time.Millisecond, 90: 9 * time.Millisecond, 99: 10 * time.Millisecond, 100: 10 * time.Millisecond}
+	for p, want := range tests {
+		if got := r.Percentile(p); got != want {
+			t.Errorf("Percentile(%g) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestReadPrompts(t *testing.T) {
+	prompts, err := ReadPrompts(strings.NewReader("# warm-up prompts\nHello\n\n  What is Go?  \n"))
+	if err != nil {
+		t.Fatalf("ReadPrompts failed: %v", err)
+	}
+	if len(prompts) != 2 || prompts[0] != "Hello" || prompts[1] != "What is Go?" {
+		t.Errorf("Unexpected prompts: %q", prompts)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	r := &Result{
+		Provider:     "groq",
+		Requests:     3,
+		Errors:       1,
+		LastError:    errors.New("rate limited"),
+		Elapsed:      2 * time.Second,
+		Latencies:    []time.Duration{100 * time.Millisecond, 300 * time.Millisecond},
+		OutputTokens: 50,
+	}
+	var out bytes.Buffer
+	if err := WriteText(&out, r); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"p50", "tokens/s", "groq", "100ms", "300ms", "1.00", "25.0", "groq: last error: rate limited"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
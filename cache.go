@@ -0,0 +1,35 @@
+package xollm
+
+import (
+	"context"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// PromptPart is a piece of a prompt, optionally marked cacheable. See
+// llm.PromptPart.
+type PromptPart = llm.PromptPart
+
+// PromptCacher is implemented by clients that support prompt caching.
+type PromptCacher = llm.PromptCacher
+
+// GenerateCached generates a response to the prompt made of parts, letting
+// the provider cache the leading cacheable parts where it can: Gemini
+// clients store them with context caching and reuse the cache on later
+// calls with the same prefix. Other clients receive the joined prompt
+// through Generate, so the same code works with every provider.
+//
+//	text, usage, err := xollm.GenerateCached(ctx, client,
+//		xollm.PromptPart{Text: manual, Cacheable: true},
+//		xollm.PromptPart{Text: "\n\nQuestion: " + question},
+//	)
+//	log.Printf("%d of %d prompt tokens cached", usage.CachedTokens, usage.PromptTokens)
+//
+// Usage is zero where the client reports none.
+func GenerateCached(ctx context.Context, client Client, parts ...PromptPart) (string, Usage, error) {
+	if cacher, ok := client.(PromptCacher); ok {
+		return cacher.GenerateCached(ctx, parts)
+	}
+	text, err := client.Generate(ctx, llm.JoinParts(parts))
+	return text, Usage{}, err
+}
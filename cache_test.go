@@ -0,0 +1,50 @@
+package xollm
+
+import (
+	"context"
+	"testing"
+)
+
+// promptRecorder records the prompt given to Generate
+type promptRecorder struct {
+	stubClient
+	prompt string
+}
+
+func (p *promptRecorder) Generate(ctx context.Context, prompt string) (string, error) {
+	p.prompt = prompt
+	return "ok", nil
+}
+
+// cachingClient adds prompt caching to stubClient
+type cachingClient struct {
+	stubClient
+}
+
+func (c *cachingClient) GenerateCached(ctx context.Context, parts []PromptPart) (string, Usage, error) {
+	return "cached", Usage{PromptTokens: 100, CachedTokens: 90}, nil
+}
+
+func TestGenerateCached_UsesPromptCacher(t *testing.T) {
+	text, usage, err := GenerateCached(context.Background(), &cachingClient{}, PromptPart{Text: "doc", Cacheable: true}, PromptPart{Text: "q"})
+	if err != nil || text != "cached" {
+		t.Fatalf("Expected cached response, got %q, %v", text, err)
+	}
+	if usage.CachedTokens != 90 {
+		t.Errorf("Expected cache hit in usage, got %+v", usage)
+	}
+}
+
+func TestGenerateCached_FallsBackToGenerate(t *testing.T) {
+	client := &promptRecorder{}
+	text, usage, err := GenerateCached(context.Background(), client, PromptPart{Text: "doc\n", Cacheable: true}, PromptPart{Text: "question"})
+	if err != nil || text != "ok" {
+		t.Fatalf("Expected plain response, got %q, %v", text, err)
+	}
+	if client.prompt != "doc\nquestion" {
+		t.Errorf("Expected joined prompt, got %q", client.prompt)
+	}
+	if usage != (Usage{}) {
+		t.Errorf("Expected no usage from a plain client, got %+v", usage)
+	}
+}
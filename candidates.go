@@ -0,0 +1,38 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// CandidateGenerator is implemented by clients that return several
+// completions of one prompt from a single request. The Gemini client
+// implements it. See llm.CandidateGenerator.
+type CandidateGenerator = llm.CandidateGenerator
+
+// GenerateCandidates returns n completions of prompt. Clients that
+// implement CandidateGenerator request them all at once, so the prompt is
+// billed once; for the others, which Groq and Ollama don't allow, it sends
+// n separate requests, one after another, failing on the first error.
+//
+//	answers, err := xollm.GenerateCandidates(ctx, client, prompt, 5)
+func GenerateCandidates(ctx context.Context, client Client, prompt string, n int) ([]string, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("candidate count must be at least 1, got %d", n)
+	}
+	if cg, ok := client.(CandidateGenerator); ok {
+		texts, _, err := cg.GenerateCandidates(ctx, prompt, n)
+		return texts, err
+	}
+	texts := make([]string, n)
+	for i := range texts {
+		text, err := client.Generate(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("candidate %d: %w", i+1, err)
+		}
+		texts[i] = text
+	}
+	return texts, nil
+}
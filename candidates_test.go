@@ -0,0 +1,68 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// candidateClient returns its candidates in one call.
+type candidateClient struct {
+	stubClient
+	n int
+}
+
+func (c *candidateClient) GenerateCandidates(ctx context.Context, prompt string, n int) ([]string, *llm.ResponseMetadata, error) {
+	c.n = n
+	texts := make([]string, n)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("answer %d", i+1)
+	}
+	return texts, &llm.ResponseMetadata{}, nil
+}
+
+// numberingClient numbers its responses.
+type numberingClient struct {
+	stubClient
+	calls int
+}
+
+func (c *numberingClient) Generate(ctx context.Context, prompt string) (string, error) {
+	c.calls++
+	return fmt.Sprintf("response %d", c.calls), nil
+}
+
+func TestGenerateCandidates(t *testing.T) {
+	client := &candidateClient{}
+	texts, err := GenerateCandidates(context.Background(), client, "prompt", 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if client.n != 2 || !reflect.DeepEqual(texts, []string{"answer 1", "answer 2"}) {
+		t.Errorf("Expected two candidates from one call, got %q", texts)
+	}
+
+	if _, err := GenerateCandidates(context.Background(), client, "prompt", 0); err == nil {
+		t.Error("Expected an error for a count of 0")
+	}
+}
+
+func TestGenerateCandidates_Fallback(t *testing.T) {
+	client := &numberingClient{}
+	texts, err := GenerateCandidates(context.Background(), client, "prompt", 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if expected := []string{"response 1", "response 2", "response 3"}; !reflect.DeepEqual(texts, expected) {
+		t.Errorf("Expected one request per candidate, got %q", texts)
+	}
+
+	failing := errors.New("boom")
+	if _, err := GenerateCandidates(context.Background(), &stubClient{err: failing}, "prompt", 2); !errors.Is(err, failing) {
+		t.Errorf("Expected the request's error, got: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package xollm
+
+// Capabilities reports the features a client supports, so applications
+// targeting several providers can branch on them instead of on provider
+// names. Each flag means the matching helper, e.g. Embed for Embeddings,
+// uses the provider natively rather than failing or falling back.
+type Capabilities struct {
+	// Streaming: GenerateStream streams natively (Streamer).
+	Streaming bool
+	// Chat: Chat sends messages with their roles (Chatter).
+	Chat bool
+	// Tools: GenerateWithTools offers tools to the model (ToolCaller), and
+	// ToolChat: ChatWithTools continues conversations with tool results
+	// (ToolChatter).
+	Tools    bool
+	ToolChat bool
+	// Vision: the client sends images (VisionGenerator) and its model is
+	// known to accept them (ModelInfo.Vision).
+	Vision bool
+	// Audio: the client's model is known to accept audio input.
+	Audio bool
+	// Embeddings: Embed computes embeddings (Embedder).
+	Embeddings bool
+	// Transcription: Transcribe turns speech into text (Transcriber).
+	Transcription bool
+	// ImageGeneration: GenerateImages creates images (ImageGenerator).
+	ImageGeneration bool
+	// Moderation: Moderate rates text with the provider's safety policies
+	// (ContentModerator).
+	Moderation bool
+	// JSON: GenerateJSON constrains the output to JSON (JSONGenerator).
+	JSON bool
+	// Sampling: GenerateWithSampling overrides sampling per call
+	// (SamplingGenerator).
+	Sampling bool
+	// Candidates: GenerateCandidates gets several completions from one
+	// request (CandidateGenerator).
+	Candidates bool
+	// Logprobs: GenerateWithLogprobs reports token log probabilities
+	// (LogprobGenerator).
+	Logprobs bool
+	// Metadata: GenerateWithMetadata reports the provider's usage rather
+	// than an estimate (MetadataGenerator).
+	Metadata bool
+	// TokenCounting: CountTokens counts exactly (TokenCounter).
+	TokenCounting bool
+	// PromptCaching: GenerateCached reuses a cached prompt prefix
+	// (PromptCacher).
+	PromptCaching bool
+	// Ping: Ping checks the provider (Pinger).
+	Ping bool
+}
+
+// CapabilitiesOf returns the capabilities of client, detected from the
+// optional interfaces it implements and, for the modalities, the model
+// registry entry of its model (see ModelNamer and LookupModel). Models
+// missing from the registry are assumed to accept text only; register
+// them, or call the client's ModelInfo, to report their modalities.
+//
+//	if xollm.CapabilitiesOf(client).Vision {
+//		text, err = xollm.GenerateWithImages(ctx, client, prompt, images)
+//	}
+func CapabilitiesOf(client Client) Capabilities {
+	var caps Capabilities
+	_, caps.Streaming = client.(Streamer)
+	_, caps.Chat = client.(Chatter)
+	_, caps.Tools = client.(ToolCaller)
+	_, caps.ToolChat = client.(ToolChatter)
+	_, caps.Embeddings = client.(Embedder)
+	_, caps.Transcription = client.(Transcriber)
+	_, caps.ImageGeneration = client.(ImageGenerator)
+	_, caps.Moderation = client.(ContentModerator)
+	_, caps.JSON = client.(JSONGenerator)
+	_, caps.Sampling = client.(SamplingGenerator)
+	_, caps.Candidates = client.(CandidateGenerator)
+	_, caps.Logprobs = client.(LogprobGenerator)
+	_, caps.Metadata = client.(MetadataGenerator)
+	_, caps.TokenCounting = client.(TokenCounter)
+	_, caps.PromptCaching = client.(PromptCacher)
+	_, caps.Ping = client.(Pinger)
+
+	if namer, ok := client.(ModelNamer); ok {
+		if info, ok := LookupModel(namer.ModelName()); ok {
+			_, sendsImages := client.(VisionGenerator)
+			caps.Vision = sendsImages && info.Vision
+			caps.Audio = info.Audio
+		}
+	}
+	return caps
+}
@@ -0,0 +1,46 @@
+package xollm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xostack/xollm/groq"
+)
+
+// namedVisionClient adds a model name to visionClient
+type namedVisionClient struct {
+	visionClient
+	model string
+}
+
+func (c *namedVisionClient) ModelName() string { return c.model }
+
+func TestCapabilitiesOf(t *testing.T) {
+	if caps := CapabilitiesOf(&stubClient{}); caps != (Capabilities{}) {
+		t.Errorf("Expected a plain client to have no capabilities, got %+v", caps)
+	}
+
+	client, err := groq.NewClient(context.Background(), "test-key", "llama-3.3-70b-versatile", 30, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	caps := CapabilitiesOf(client)
+	if !caps.Streaming || !caps.Chat || !caps.Tools || !caps.ToolChat || !caps.JSON || !caps.Logprobs || !caps.Ping || !caps.Transcription {
+		t.Errorf("Expected Groq's capabilities, got %+v", caps)
+	}
+	if caps.Embeddings || caps.Candidates || caps.Vision || caps.ImageGeneration {
+		t.Errorf("Expected Groq without embeddings, candidates, vision or image generation for a text model, got %+v", caps)
+	}
+}
+
+func TestCapabilitiesOf_Vision(t *testing.T) {
+	RegisterModel(ModelInfo{Name: "test-vision-model", Provider: "stub", Vision: true, Audio: true})
+	caps := CapabilitiesOf(&namedVisionClient{model: "test-vision-model"})
+	if !caps.Vision || !caps.Audio {
+		t.Errorf("Expected the model's modalities, got %+v", caps)
+	}
+
+	if caps := CapabilitiesOf(&namedVisionClient{model: "unknown-model"}); caps.Vision {
+		t.Error("Expected an unknown model not to count as accepting images")
+	}
+}
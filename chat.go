@@ -0,0 +1,66 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// Role is the author of a chat message. See llm.Role.
+type Role = llm.Role
+
+// Chat message roles.
+const (
+	RoleSystem    = llm.RoleSystem
+	RoleUser      = llm.RoleUser
+	RoleAssistant = llm.RoleAssistant
+	RoleTool      = llm.RoleTool
+)
+
+// Message is one turn of a chat. See llm.Message.
+type Message = llm.Message
+
+// Chatter is implemented by clients that send conversations to the
+// provider's chat API with each message's role. See llm.Chatter.
+type Chatter = llm.Chatter
+
+// GenerateWithSystem sends prompt with a system prompt for this call,
+// mapped to the provider's system instruction as a chat with a system and
+// a user message. It adds to the client's system prompt, if any, rather
+// than replacing it.
+func GenerateWithSystem(ctx context.Context, client Client, system, prompt string) (string, error) {
+	text, _, err := Chat(ctx, client, []Message{
+		{Role: RoleSystem, Content: system},
+		{Role: RoleUser, Content: prompt},
+	})
+	return text, err
+}
+
+// Chat sends a conversation to client and returns the model's reply with
+// its metadata. Clients implementing Chatter send the messages natively:
+// Ollama to /api/chat, Groq as chat completion messages and Gemini as
+// contents with a system instruction. Other clients get the conversation
+// flattened into one prompt, see llm.FlattenMessages, and can't take
+// images.
+//
+//	reply, md, err := xollm.Chat(ctx, client, []xollm.Message{
+//		{Role: xollm.RoleSystem, Content: "You are a terse assistant."},
+//		{Role: xollm.RoleUser, Content: "What is the capital of Norway?"},
+//		{Role: xollm.RoleAssistant, Content: "Oslo."},
+//		{Role: xollm.RoleUser, Content: "And of Sweden?"},
+//	})
+func Chat(ctx context.Context, client Client, messages []Message) (string, *ResponseMetadata, error) {
+	if chatter, ok := client.(Chatter); ok {
+		return chatter.Chat(ctx, messages)
+	}
+	if err := llm.CheckMessages(messages); err != nil {
+		return "", nil, err
+	}
+	for _, m := range messages {
+		if len(m.Images) > 0 {
+			return "", nil, fmt.Errorf("%s client does not support images", client.ProviderName())
+		}
+	}
+	return GenerateWithMetadata(ctx, client, llm.FlattenMessages(messages))
+}
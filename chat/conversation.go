@@ -0,0 +1,272 @@
+// Package chat keeps stateful conversations with an LLM.
+//
+// A Conversation holds the history of a chat and sends it, with the
+// system prompt and the new message, to the client's chat API on every
+// turn, so the model sees the whole exchange:
+//
+//	conv := chat.New(client, chat.Options{
+//		SystemPrompt: "You are a terse assistant.",
+//		MaxHistory:   20,
+//	})
+//
+//	reply, err := conv.SendMessage(ctx, "What is the capital of Norway?")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	reply, err = conv.SendMessage(ctx, "And of Sweden?")
+//
+// The history is trimmed to MaxHistory messages and, before every turn, to
+// the context window of the client's model or an explicit TokenBudget, so
+// long conversations don't exceed the model's limit. Statistics reports
+// the messages, tokens and estimated cost of the session. A Conversation
+// is safe for concurrent use; turns are sent one at a time, in the order
+// SendMessage is called.
+//
+// Save writes a conversation to a Store and Load resumes it, e.g. after a
+// restart: FileStore keeps each one as a JSON file, SQLStore as a row of a
+// SQLite database, either sealed with an encryption.Cipher if one is
+// configured.
+package chat
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// Message is one message of a conversation's history.
+type Message struct {
+	ID        string      // Stable random ID, kept when the conversation is saved
+	Role      xollm.Role  // xollm.RoleUser or xollm.RoleAssistant
+	Content   string      // The message content
+	Timestamp time.Time   // When the message was created
+	Usage     xollm.Usage // Tokens used to generate the message (assistant messages only)
+}
+
+// Statistics describes a conversation.
+type Statistics struct {
+	TotalMessages        int           // Total number of messages in the history
+	UserMessages         int           // Number of user messages in the history
+	AssistantMessages    int           // Number of assistant messages in the history
+	AverageMessageLength float64       // Average length of the messages in the history
+	ConversationDuration time.Duration // Duration since the conversation started
+	StartTime            time.Time     // When the conversation started
+	PromptTokens         int           // Prompt tokens sent over the whole session
+	CompletionTokens     int           // Completion tokens generated over the whole session
+	TokensEstimated      bool          // Whether any token counts were estimated locally
+	EstimatedCostUSD     float64       // Estimated cost of the session in US dollars
+	CostKnown            bool          // Whether pricing was known for every response
+}
+
+// Options configure a Conversation.
+type Options struct {
+	// ID identifies the conversation in a Store. If empty, a random ID is
+	// generated.
+	ID string
+	// SystemPrompt is sent before the history on every turn. It is not
+	// part of the history, so trimming never removes it.
+	SystemPrompt string
+	// MaxHistory is the number of messages the history keeps, dropping
+	// the oldest first. If <= 0, the number is unlimited.
+	MaxHistory int
+	// TokenBudget is the most tokens the system prompt, the history and
+	// the new message may take up; the oldest messages are dropped before
+	// each turn to fit. Tokens are counted locally with the tokenizer of
+	// the client's model (see xollm.EstimateTokensFor). If zero, the
+	// budget is the context window of the client's model less
+	// ReserveTokens: the one the client reports (see
+	// xollm.ContextWindowProvider), else the one xollm.LookupModel knows.
+	// If negative, the history isn't trimmed by tokens.
+	TokenBudget int
+	// ReserveTokens is the part of the context window left for the reply
+	// when TokenBudget is zero. If <= 0, it is the model's maximum output,
+	// up to a quarter of the window.
+	ReserveTokens int
+}
+
+// Conversation manages a stateful conversation with an LLM.
+type Conversation struct {
+	id      string
+	client  xollm.Client
+	options Options
+	// send serializes turns, so each one sees the history of the last
+	send      sync.Mutex
+	mu        sync.RWMutex
+	messages  []Message
+	startTime time.Time
+	totals    sessionTotals
+}
+
+// sessionTotals accumulates token usage and cost over a session, kept
+// across history trims and clears.
+type sessionTotals struct {
+	promptTokens     int
+	completionTokens int
+	estimated        bool
+	costUSD          float64
+	costUnknown      bool
+}
+
+// New returns an empty conversation with client. The caller keeps
+// ownership of client and closes it when done.
+func New(client xollm.Client, opts Options) *Conversation {
+	id := opts.ID
+	if id == "" {
+		id = newID()
+	}
+	return &Conversation{
+		id:        id,
+		client:    client,
+		options:   opts,
+		startTime: time.Now(),
+	}
+}
+
+// SystemPrompt returns the system prompt.
+func (c *Conversation) SystemPrompt() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.options.SystemPrompt
+}
+
+// SetSystemPrompt replaces the system prompt from the next turn on.
+func (c *Conversation) SetSystemPrompt(prompt string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options.SystemPrompt = prompt
+}
+
+// Len returns the number of messages in the history.
+func (c *Conversation) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.messages)
+}
+
+// History returns a copy of the history, oldest message first.
+func (c *Conversation) History() []Message {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	history := make([]Message, len(c.messages))
+	copy(history, c.messages)
+	return history
+}
+
+// ClearHistory empties the history. The session's token and cost totals
+// are kept.
+func (c *Conversation) ClearHistory() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = nil
+}
+
+// SendMessage sends the system prompt, the history and text to the model
+// with xollm.Chat and returns the reply, first dropping the oldest messages
+// that don't fit in the token budget (see Options.TokenBudget). The
+// message and the reply are added to the history only if the request
+// succeeds; a message too long for the budget by itself is refused with a
+// *xollm.ContextLengthError. Concurrent calls are sent one after the
+// other, while History and Statistics stay readable.
+func (c *Conversation) SendMessage(ctx context.Context, text string) (string, error) {
+	c.send.Lock()
+	defer c.send.Unlock()
+
+	c.mu.Lock()
+	if err := c.fitTokenBudget(text); err != nil {
+		c.mu.Unlock()
+		return "", err
+	}
+	messages := c.buildMessages(text)
+	c.mu.Unlock()
+
+	sent := time.Now()
+	reply, md, err := xollm.Chat(ctx, c.client, messages)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate response: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addUsage(md)
+	c.messages = append(c.messages,
+		Message{ID: newID(), Role: xollm.RoleUser, Content: text, Timestamp: sent},
+		Message{ID: newID(), Role: xollm.RoleAssistant, Content: reply, Timestamp: time.Now(), Usage: md.Usage},
+	)
+	c.trimHistory()
+	return reply, nil
+}
+
+// buildMessages returns the system prompt, the history and text as chat
+// messages.
+func (c *Conversation) buildMessages(text string) []xollm.Message {
+	messages := make([]xollm.Message, 0, len(c.messages)+2)
+	if c.options.SystemPrompt != "" {
+		messages = append(messages, xollm.Message{Role: xollm.RoleSystem, Content: c.options.SystemPrompt})
+	}
+	for _, m := range c.messages {
+		messages = append(messages, xollm.Message{Role: m.Role, Content: m.Content})
+	}
+	return append(messages, xollm.Message{Role: xollm.RoleUser, Content: text})
+}
+
+// addUsage adds the usage and estimated cost of a reply to the session
+// totals.
+func (c *Conversation) addUsage(md *xollm.ResponseMetadata) {
+	c.totals.promptTokens += md.Usage.PromptTokens
+	c.totals.completionTokens += md.Usage.CompletionTokens
+	c.totals.estimated = c.totals.estimated || md.Usage.Estimated
+
+	// Prefer the model the provider reports, falling back to the client's
+	model := md.Model
+	if model == "" {
+		model = c.model()
+	}
+	if cost, ok := xollm.EstimateCost(c.client.ProviderName(), model, md.Usage); ok {
+		c.totals.costUSD += cost
+	} else {
+		c.totals.costUnknown = true
+	}
+}
+
+// trimHistory drops the oldest messages beyond MaxHistory.
+func (c *Conversation) trimHistory() {
+	if c.options.MaxHistory > 0 && len(c.messages) > c.options.MaxHistory {
+		c.messages = c.messages[len(c.messages)-c.options.MaxHistory:]
+	}
+}
+
+// Statistics returns statistics about the conversation.
+func (c *Conversation) Statistics() Statistics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := Statistics{
+		TotalMessages:        len(c.messages),
+		ConversationDuration: time.Since(c.startTime),
+		StartTime:            c.startTime,
+		PromptTokens:         c.totals.promptTokens,
+		CompletionTokens:     c.totals.completionTokens,
+		TokensEstimated:      c.totals.estimated,
+		EstimatedCostUSD:     c.totals.costUSD,
+		CostKnown:            !c.totals.costUnknown,
+	}
+	if len(c.messages) == 0 {
+		return stats
+	}
+
+	var totalLength int
+	for _, m := range c.messages {
+		totalLength += len(m.Content)
+		switch m.Role {
+		case xollm.RoleUser:
+			stats.UserMessages++
+		case xollm.RoleAssistant:
+			stats.AssistantMessages++
+		}
+	}
+	stats.AverageMessageLength = float64(totalLength) / float64(len(c.messages))
+	return stats
+}
@@ -0,0 +1,218 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient answers chats with the last user message, recording the
+// messages it was sent.
+type mockClient struct {
+	mu       sync.Mutex
+	provider string
+	model    string
+	usage    xollm.Usage
+	err      error
+	sent     [][]xollm.Message
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return "Response to: " + prompt, nil
+}
+
+func (m *mockClient) Chat(ctx context.Context, messages []xollm.Message) (string, *xollm.ResponseMetadata, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, messages)
+	if m.err != nil {
+		return "", nil, m.err
+	}
+	return "Response to: " + messages[len(messages)-1].Content, &xollm.ResponseMetadata{Model: "llama-3.1-8b-instant", Usage: m.usage}, nil
+}
+
+func (m *mockClient) ProviderName() string {
+	if m.provider != "" {
+		return m.provider
+	}
+	return "groq"
+}
+
+func (m *mockClient) ModelName() string { return m.model }
+
+func (m *mockClient) Close() error { return nil }
+
+// generateOnlyClient implements only xollm.Client.
+type generateOnlyClient struct{}
+
+func (generateOnlyClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return "ok", nil
+}
+
+func (generateOnlyClient) ProviderName() string { return "ollama" }
+
+func (generateOnlyClient) Close() error { return nil }
+
+func TestNew(t *testing.T) {
+	conv := New(&mockClient{}, Options{SystemPrompt: "Be terse."})
+	if conv.SystemPrompt() != "Be terse." {
+		t.Errorf("Expected the system prompt, got %q", conv.SystemPrompt())
+	}
+	if conv.Len() != 0 || len(conv.History()) != 0 {
+		t.Errorf("Expected an empty history, got %d messages", conv.Len())
+	}
+}
+
+func TestConversation_SendMessage(t *testing.T) {
+	client := &mockClient{}
+	conv := New(client, Options{SystemPrompt: "Be terse."})
+	ctx := context.Background()
+
+	if _, err := conv.SendMessage(ctx, "Capital of Norway?"); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	reply, err := conv.SendMessage(ctx, "And of Sweden?")
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if reply != "Response to: And of Sweden?" {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+
+	expected := []xollm.Message{
+		{Role: xollm.RoleSystem, Content: "Be terse."},
+		{Role: xollm.RoleUser, Content: "Capital of Norway?"},
+		{Role: xollm.RoleAssistant, Content: "Response to: Capital of Norway?"},
+		{Role: xollm.RoleUser, Content: "And of Sweden?"},
+	}
+	if !reflect.DeepEqual(client.sent[1], expected) {
+		t.Errorf("Expected the second turn to send %+v, got %+v", expected, client.sent[1])
+	}
+
+	history := conv.History()
+	roles := []xollm.Role{xollm.RoleUser, xollm.RoleAssistant, xollm.RoleUser, xollm.RoleAssistant}
+	if len(history) != len(roles) {
+		t.Fatalf("Expected %d messages in the history, got %d", len(roles), len(history))
+	}
+	for i, m := range history {
+		if m.Role != roles[i] {
+			t.Errorf("Expected message %d to have role %q, got %q", i, roles[i], m.Role)
+		}
+		if m.Timestamp.IsZero() {
+			t.Errorf("Expected message %d to have a timestamp", i)
+		}
+	}
+}
+
+func TestConversation_SendMessageError(t *testing.T) {
+	conv := New(&mockClient{err: errors.New("mock error")}, Options{})
+	reply, err := conv.SendMessage(context.Background(), "This will fail")
+	if err == nil || !strings.Contains(err.Error(), "mock error") {
+		t.Fatalf("Expected the client's error, got %v", err)
+	}
+	if reply != "" || conv.Len() != 0 {
+		t.Errorf("Expected no reply and no history after an error, got %q and %d messages", reply, conv.Len())
+	}
+}
+
+func TestConversation_FlattenedForPlainClients(t *testing.T) {
+	conv := New(generateOnlyClient{}, Options{})
+	if _, err := conv.SendMessage(context.Background(), "Hello"); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	stats := conv.Statistics()
+	if !stats.TokensEstimated || stats.PromptTokens == 0 {
+		t.Errorf("Expected estimated token counts, got %+v", stats)
+	}
+	// Ollama is self-hosted, so the cost is known to be zero
+	if !stats.CostKnown || stats.EstimatedCostUSD != 0 {
+		t.Errorf("Expected a known zero cost, got %f (known: %v)", stats.EstimatedCostUSD, stats.CostKnown)
+	}
+}
+
+func TestConversation_MaxHistory(t *testing.T) {
+	conv := New(&mockClient{}, Options{MaxHistory: 4})
+	for i := 0; i < 5; i++ {
+		if _, err := conv.SendMessage(context.Background(), fmt.Sprintf("Message %d", i)); err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+	history := conv.History()
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 messages, got %d", len(history))
+	}
+	if history[0].Content != "Message 3" || history[3].Content != "Response to: Message 4" {
+		t.Errorf("Expected the most recent messages to be kept, got %+v", history)
+	}
+}
+
+func TestConversation_ClearHistoryKeepsTotals(t *testing.T) {
+	client := &mockClient{usage: xollm.Usage{PromptTokens: 1_000_000, CompletionTokens: 500_000}}
+	conv := New(client, Options{MaxHistory: 2})
+	ctx := context.Background()
+	conv.SendMessage(ctx, "First")
+	conv.SendMessage(ctx, "Second")
+	conv.ClearHistory()
+
+	stats := conv.Statistics()
+	if stats.TotalMessages != 0 {
+		t.Errorf("Expected an empty history, got %d messages", stats.TotalMessages)
+	}
+	if stats.PromptTokens != 2_000_000 || stats.CompletionTokens != 1_000_000 {
+		t.Errorf("Expected session token totals to survive trimming and clearing, got %d prompt, %d completion",
+			stats.PromptTokens, stats.CompletionTokens)
+	}
+	// llama-3.1-8b-instant: 2M * $0.05 + 1M * $0.08 per million
+	if !stats.CostKnown || stats.EstimatedCostUSD < 0.1799 || stats.EstimatedCostUSD > 0.1801 {
+		t.Errorf("Expected a known cost of $0.18, got %f (known: %v)", stats.EstimatedCostUSD, stats.CostKnown)
+	}
+}
+
+func TestConversation_Statistics(t *testing.T) {
+	conv := New(&mockClient{usage: xollm.Usage{PromptTokens: 3, CompletionTokens: 2}}, Options{})
+	ctx := context.Background()
+	conv.SendMessage(ctx, "Short")
+	conv.SendMessage(ctx, "This is a longer message with more words")
+
+	stats := conv.Statistics()
+	if stats.TotalMessages != 4 || stats.UserMessages != 2 || stats.AssistantMessages != 2 {
+		t.Errorf("Expected 2 user and 2 assistant messages, got %+v", stats)
+	}
+	if stats.AverageMessageLength <= 0 || stats.ConversationDuration <= 0 {
+		t.Errorf("Expected a positive average length and duration, got %+v", stats)
+	}
+	if history := conv.History(); history[1].Usage.CompletionTokens != 2 {
+		t.Error("Expected the assistant message to carry its usage")
+	}
+}
+
+func TestConversation_ConcurrentSends(t *testing.T) {
+	client := &mockClient{}
+	conv := New(client, Options{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conv.SendMessage(context.Background(), fmt.Sprintf("Message %d", i))
+			conv.Statistics()
+		}(i)
+	}
+	wg.Wait()
+
+	if conv.Len() != 20 {
+		t.Fatalf("Expected 20 messages, got %d", conv.Len())
+	}
+	// Turns are sent one at a time, each with the history of all before it
+	for i, sent := range client.sent {
+		if len(sent) != 2*i+1 {
+			t.Errorf("Expected turn %d to send %d messages, got %d", i, 2*i+1, len(sent))
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package chat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// transcriptVersion is the version of the serialized format written by
+// Save. Load refuses newer versions.
+const transcriptVersion = 1
+
+// transcript is the serialized form of a conversation.
+type transcript struct {
+	Version      int                 `json:"version"`
+	ID           string              `json:"id"`
+	SystemPrompt string              `json:"system_prompt,omitempty"`
+	StartTime    time.Time           `json:"start_time"`
+	Messages     []transcriptMessage `json:"messages"`
+	Totals       transcriptTotals    `json:"totals"`
+}
+
+// transcriptMessage is the serialized form of a Message.
+type transcriptMessage struct {
+	ID               string     `json:"id"`
+	Role             xollm.Role `json:"role"`
+	Content          string     `json:"content"`
+	Timestamp        time.Time  `json:"timestamp"`
+	PromptTokens     int        `json:"prompt_tokens,omitempty"`
+	CompletionTokens int        `json:"completion_tokens,omitempty"`
+	CachedTokens     int        `json:"cached_tokens,omitempty"`
+	TokensEstimated  bool       `json:"tokens_estimated,omitempty"`
+}
+
+// transcriptTotals is the serialized form of the session totals.
+type transcriptTotals struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TokensEstimated  bool    `json:"tokens_estimated,omitempty"`
+	CostUSD          float64 `json:"cost_usd"`
+	CostUnknown      bool    `json:"cost_unknown,omitempty"`
+}
+
+// ID returns the conversation's ID, under which Save stores it.
+func (c *Conversation) ID() string {
+	return c.id
+}
+
+// Save writes the conversation to store under its ID: the system prompt,
+// the history with each message's ID and timestamp, and the session's
+// token and cost totals, so that Load can resume it, e.g. after a restart.
+// A turn in flight is not included.
+func (c *Conversation) Save(ctx context.Context, store Store) error {
+	c.mu.RLock()
+	t := transcript{
+		Version:      transcriptVersion,
+		ID:           c.id,
+		SystemPrompt: c.options.SystemPrompt,
+		StartTime:    c.startTime,
+		Messages:     make([]transcriptMessage, len(c.messages)),
+		Totals: transcriptTotals{
+			PromptTokens:     c.totals.promptTokens,
+			CompletionTokens: c.totals.completionTokens,
+			TokensEstimated:  c.totals.estimated,
+			CostUSD:          c.totals.costUSD,
+			CostUnknown:      c.totals.costUnknown,
+		},
+	}
+	for i, m := range c.messages {
+		t.Messages[i] = transcriptMessage{
+			ID:               m.ID,
+			Role:             m.Role,
+			Content:          m.Content,
+			Timestamp:        m.Timestamp,
+			PromptTokens:     m.Usage.PromptTokens,
+			CompletionTokens: m.Usage.CompletionTokens,
+			CachedTokens:     m.Usage.CachedTokens,
+			TokensEstimated:  m.Usage.Estimated,
+		}
+	}
+	c.mu.RUnlock()
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation %s: %w", c.id, err)
+	}
+	if err := store.Save(ctx, c.id, data); err != nil {
+		return fmt.Errorf("failed to save conversation %s: %w", c.id, err)
+	}
+	return nil
+}
+
+// Load resumes the conversation saved under id in store, sending its next
+// turns to client. opts apply as in New, except that the ID is id and the
+// saved system prompt is kept unless opts sets one. It fails with an error
+// wrapping ErrNotFound if store has no such conversation.
+func Load(ctx context.Context, store Store, id string, client xollm.Client, opts Options) (*Conversation, error) {
+	data, err := store.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation %s: %w", id, err)
+	}
+	var t transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to decode conversation %s: %w", id, err)
+	}
+	if t.Version > transcriptVersion {
+		return nil, fmt.Errorf("conversation %s has format version %d, newer than the supported %d", id, t.Version, transcriptVersion)
+	}
+
+	opts.ID = id
+	if opts.SystemPrompt == "" {
+		opts.SystemPrompt = t.SystemPrompt
+	}
+	c := New(client, opts)
+	c.startTime = t.StartTime
+	c.totals = sessionTotals{
+		promptTokens:     t.Totals.PromptTokens,
+		completionTokens: t.Totals.CompletionTokens,
+		estimated:        t.Totals.TokensEstimated,
+		costUSD:          t.Totals.CostUSD,
+		costUnknown:      t.Totals.CostUnknown,
+	}
+	for _, m := range t.Messages {
+		c.messages = append(c.messages, Message{
+			ID:        m.ID,
+			Role:      m.Role,
+			Content:   m.Content,
+			Timestamp: m.Timestamp,
+			Usage: xollm.Usage{
+				PromptTokens:     m.PromptTokens,
+				CompletionTokens: m.CompletionTokens,
+				CachedTokens:     m.CachedTokens,
+				Estimated:        m.TokensEstimated,
+			},
+		})
+	}
+	return c, nil
+}
+
+// newID returns a random ID for a conversation or message.
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand failing is exceptional; fall back to a time-based ID
+		return strings.ReplaceAll(time.Now().UTC().Format("20060102150405.000000000"), ".", "")
+	}
+	return hex.EncodeToString(b[:])
+}
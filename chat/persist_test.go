@@ -0,0 +1,82 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+func TestConversation_SaveLoad(t *testing.T) {
+	store, err := NewFileStore(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	ctx := context.Background()
+	client := &mockClient{usage: xollm.Usage{PromptTokens: 10, CompletionTokens: 5}}
+	conv := New(client, Options{ID: "support-42", SystemPrompt: "Be terse."})
+	conv.SendMessage(ctx, "Capital of Norway?")
+	conv.SendMessage(ctx, "And of Sweden?")
+	if err := conv.Save(ctx, store); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	resumed, err := Load(ctx, store, "support-42", client, Options{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if resumed.ID() != "support-42" || resumed.SystemPrompt() != "Be terse." {
+		t.Errorf("Expected the ID and system prompt back, got %q and %q", resumed.ID(), resumed.SystemPrompt())
+	}
+	saved, loaded := conv.History(), resumed.History()
+	if len(loaded) != len(saved) {
+		t.Fatalf("Expected %d messages back, got %d", len(saved), len(loaded))
+	}
+	for i := range saved {
+		if loaded[i].ID == "" || loaded[i].ID != saved[i].ID || !loaded[i].Timestamp.Equal(saved[i].Timestamp) ||
+			loaded[i].Content != saved[i].Content || loaded[i].Usage != saved[i].Usage {
+			t.Errorf("Expected message %d to round-trip, got %+v, want %+v", i, loaded[i], saved[i])
+		}
+	}
+	if stats := resumed.Statistics(); stats.PromptTokens != 20 || !stats.StartTime.Equal(conv.Statistics().StartTime) {
+		t.Errorf("Expected the session totals and start time back, got %+v", stats)
+	}
+
+	// The resumed conversation continues with its history
+	resumed.SendMessage(ctx, "And of Denmark?")
+	if sent := client.sent[len(client.sent)-1]; len(sent) != 6 || sent[1].Content != "Capital of Norway?" {
+		t.Errorf("Expected the history to be sent after resuming, got %+v", sent)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	store, _ := NewFileStore(t.TempDir(), nil)
+	ctx := context.Background()
+	if _, err := Load(ctx, store, "missing", &mockClient{}, Options{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	store.Save(ctx, "future", []byte(`{"version": 99, "id": "future"}`))
+	if _, err := Load(ctx, store, "future", &mockClient{}, Options{}); err == nil || !strings.Contains(err.Error(), "version 99") {
+		t.Errorf("Expected a newer format version to be refused, got %v", err)
+	}
+}
+
+func TestLoad_OptionsOverrideSystemPrompt(t *testing.T) {
+	store, _ := NewFileStore(t.TempDir(), nil)
+	ctx := context.Background()
+	New(&mockClient{}, Options{ID: "c1", SystemPrompt: "old"}).Save(ctx, store)
+	conv, err := Load(ctx, store, "c1", &mockClient{}, Options{SystemPrompt: "new"})
+	if err != nil || conv.SystemPrompt() != "new" {
+		t.Errorf("Expected the given system prompt to win, got %v", err)
+	}
+}
+
+func TestNew_GeneratesIDs(t *testing.T) {
+	a, b := New(&mockClient{}, Options{}), New(&mockClient{}, Options{})
+	if a.ID() == "" || a.ID() == b.ID() {
+		t.Errorf("Expected distinct random IDs, got %q and %q", a.ID(), b.ID())
+	}
+}
@@ -0,0 +1,72 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xostack/xollm/encryption"
+)
+
+// SQLTable is the table a SQLStore keeps conversations in.
+const SQLTable = "xollm_conversations"
+
+// SQLStore is a Store keeping conversations in a SQLite database, one row
+// per conversation. The module doesn't depend on a driver: open the
+// database with the one of your choice, e.g. modernc.org/sqlite or
+// github.com/mattn/go-sqlite3.
+type SQLStore struct {
+	db     *sql.DB
+	cipher *encryption.Cipher
+}
+
+// NewSQLStore returns a store using db, creating SQLTable if it doesn't
+// exist. The upsert it uses needs SQLite 3.24 or later. With a cipher,
+// e.g. from encryption.FromConfig, rows are sealed with their ID as
+// associated data; with a nil cipher they are plaintext JSON. Rows written
+// before encryption was enabled fail to load unless the cipher allows
+// plaintext; see encryption.Cipher.SetAllowPlaintext.
+func NewSQLStore(ctx context.Context, db *sql.DB, cipher *encryption.Cipher) (*SQLStore, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+SQLTable+` (
+	id TEXT PRIMARY KEY,
+	data BLOB NOT NULL,
+	updated_at INTEGER NOT NULL
+)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table %s: %w", SQLTable, err)
+	}
+	return &SQLStore{db: db, cipher: cipher}, nil
+}
+
+// Save implements Store.
+func (s *SQLStore) Save(ctx context.Context, id string, data []byte) error {
+	sealed, err := s.cipher.Seal(data, []byte(id))
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO `+SQLTable+` (id, data, updated_at) VALUES (?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		id, sealed, time.Now().Unix())
+	return err
+}
+
+// Load implements Store.
+func (s *SQLStore) Load(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM `+SQLTable+` WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.cipher.Open(data, []byte(id))
+}
+
+// Delete implements Store.
+func (s *SQLStore) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM `+SQLTable+` WHERE id = ?`, id)
+	return err
+}
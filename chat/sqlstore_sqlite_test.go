@@ -0,0 +1,66 @@
+//go:build sqlite && cgo
+
+// Run with: go test -tags sqlite ./chat
+
+package chat
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestSQLStore_SQLite runs SQLStore's statements against a real SQLite
+// database, which the fakeDB of the other tests only matches as strings.
+func TestSQLStore_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "chat.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	store, err := NewSQLStore(ctx, db, newTestCipher(t))
+	if err != nil {
+		t.Fatalf("NewSQLStore failed: %v", err)
+	}
+	// The table already exists the second time
+	if _, err := NewSQLStore(ctx, db, nil); err != nil {
+		t.Fatalf("NewSQLStore failed on an existing table: %v", err)
+	}
+
+	if err := store.Save(ctx, "c1", []byte(`{"messages":["hello"]}`)); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// The upsert replaces the row
+	data := []byte(`{"messages":["hello","again"]}`)
+	if err := store.Save(ctx, "c1", data); err != nil {
+		t.Fatalf("Save over an existing row failed: %v", err)
+	}
+	var rows int
+	var raw []byte
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*), MAX(data) FROM `+SQLTable).Scan(&rows, &raw); err != nil {
+		t.Fatalf("Failed to read the table: %v", err)
+	}
+	if rows != 1 || bytes.Contains(raw, []byte("hello")) {
+		t.Errorf("Expected one sealed row, got %d: %q", rows, raw)
+	}
+	if got, err := store.Load(ctx, "c1"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Expected the last save back, got %q, %v", got, err)
+	}
+
+	if err := store.Delete(ctx, "c1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load(ctx, "c1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+	}
+	if err := store.Delete(ctx, "c1"); err != nil {
+		t.Errorf("Expected deleting a missing ID to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/xostack/xollm/encryption"
+)
+
+// fakeDB is a database/sql driver understanding only the statements of
+// SQLStore, keeping rows in memory. TestSQLStore_SQLite, built with the
+// sqlite tag, checks the statements against a real database.
+type fakeDB struct {
+	mu      sync.Mutex
+	created bool
+	rows    map[string][]byte
+}
+
+func (d *fakeDB) Open(name string) (driver.Conn, error) { return &fakeConn{db: d}, nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: strings.TrimSpace(query)}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE IF NOT EXISTS "+SQLTable):
+		s.db.created = true
+	case strings.HasPrefix(s.query, "INSERT INTO "+SQLTable) && strings.Contains(s.query, "ON CONFLICT(id) DO UPDATE"):
+		s.db.rows[args[0].(string)] = append([]byte(nil), args[1].([]byte)...)
+	case strings.HasPrefix(s.query, "DELETE FROM "+SQLTable):
+		delete(s.db.rows, args[0].(string))
+	default:
+		return nil, errors.New("unexpected statement: " + s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(s.query, "SELECT data FROM "+SQLTable+" WHERE id = ?") {
+		return nil, errors.New("unexpected query: " + s.query)
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	data, ok := s.db.rows[args[0].(string)]
+	return &fakeRows{data: data, done: !ok}, nil
+}
+
+type fakeRows struct {
+	data []byte
+	done bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"data"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.data
+	return nil
+}
+
+// fakeConnector connects to a fakeDB.
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.db.Open("") }
+func (c fakeConnector) Driver() driver.Driver                        { return c.db }
+
+func openFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{rows: map[string][]byte{}}
+	db := sql.OpenDB(fakeConnector{fake})
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+func TestSQLStore(t *testing.T) {
+	db, fake := openFakeDB(t)
+	ctx := context.Background()
+	store, err := NewSQLStore(ctx, db, newTestCipher(t))
+	if err != nil {
+		t.Fatalf("NewSQLStore failed: %v", err)
+	}
+	if !fake.created {
+		t.Error("Expected the table to be created")
+	}
+
+	data := []byte(`{"messages":["hello"]}`)
+	if err := store.Save(ctx, "c1", data); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if bytes.Contains(fake.rows["c1"], []byte("hello")) {
+		t.Error("Expected the row to be sealed")
+	}
+	if got, err := store.Load(ctx, "c1"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Expected the data back, got %q, %v", got, err)
+	}
+
+	// A sealed row copied to another ID doesn't open
+	fake.rows["c2"] = fake.rows["c1"]
+	if _, err := store.Load(ctx, "c2"); err == nil {
+		t.Error("Expected a swapped row to fail")
+	}
+
+	if err := store.Delete(ctx, "c1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load(ctx, "c1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+	}
+}
+
+func TestSQLStore_Plaintext(t *testing.T) {
+	db, fake := openFakeDB(t)
+	ctx := context.Background()
+	fake.rows["legacy"] = []byte(`{"version":1}`)
+
+	cipher := newTestCipher(t)
+	store, _ := NewSQLStore(ctx, db, cipher)
+	if _, err := store.Load(ctx, "legacy"); !errors.Is(err, encryption.ErrDecrypt) {
+		t.Errorf("Expected a plaintext row to be rejected, got %v", err)
+	}
+	cipher.SetAllowPlaintext(true)
+	if got, err := store.Load(ctx, "legacy"); err != nil || string(got) != `{"version":1}` {
+		t.Errorf("Expected a plaintext row to be readable while migrating, got %q, %v", got, err)
+	}
+
+	plain, _ := NewSQLStore(ctx, db, nil)
+	conv := New(&mockClient{}, Options{ID: "c1"})
+	conv.SendMessage(ctx, "Hello")
+	if err := conv.Save(ctx, plain); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if !bytes.Contains(fake.rows["c1"], []byte(`"content":"Hello"`)) {
+		t.Errorf("Expected a nil cipher to store plaintext JSON, got %q", fake.rows["c1"])
+	}
+	if resumed, err := Load(ctx, plain, "c1", &mockClient{}, Options{}); err != nil || resumed.Len() != 2 {
+		t.Errorf("Expected the conversation back, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xostack/xollm/config"
+	"github.com/xostack/xollm/encryption"
+)
+
+// ErrNotFound is returned, wrapped, when a store has no conversation with
+// the requested ID.
+var ErrNotFound = errors.New("conversation not found")
+
+// Store keeps serialized conversations by ID, see Conversation.Save and
+// Load. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save stores data under id, replacing any previous version.
+	Save(ctx context.Context, id string, data []byte) error
+	// Load returns the data stored under id, or an error wrapping
+	// ErrNotFound.
+	Load(ctx context.Context, id string) ([]byte, error)
+	// Delete removes the data stored under id. Deleting a missing ID is
+	// not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// FileStore is a Store keeping each conversation as a JSON file named
+// after its ID in a directory, readable by the owner only.
+type FileStore struct {
+	dir    string
+	cipher *encryption.Cipher
+}
+
+// NewFileStore returns a store writing to dir, creating it if needed.
+// With a cipher, e.g. from encryption.FromConfig, files are sealed; with a
+// nil cipher they are plaintext JSON. Files written before encryption was
+// enabled fail to load unless the cipher allows plaintext; see
+// encryption.Cipher.SetAllowPlaintext.
+func NewFileStore(dir string, cipher *encryption.Cipher) (*FileStore, error) {
+	if err := os.MkdirAll(dir, config.DefaultDirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create conversation directory: %w", err)
+	}
+	return &FileStore{dir: dir, cipher: cipher}, nil
+}
+
+// Save implements Store. The file is replaced atomically.
+func (s *FileStore) Save(ctx context.Context, id string, data []byte) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	return s.cipher.WriteFile(path, data)
+}
+
+// Load implements Store.
+func (s *FileStore) Load(ctx context.Context, id string) ([]byte, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.cipher.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return data, err
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the file of the conversation id, which must be a plain file
+// name.
+func (s *FileStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return "", fmt.Errorf("invalid conversation ID %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xostack/xollm/encryption"
+)
+
+func newTestCipher(t *testing.T) *encryption.Cipher {
+	t.Helper()
+	key, err := encryption.NewKey()
+	if err != nil {
+		t.Fatalf("NewKey failed: %v", err)
+	}
+	c, err := encryption.New(key)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return c
+}
+
+func TestFileStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "conversations")
+	store, err := NewFileStore(dir, newTestCipher(t))
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	ctx := context.Background()
+	data := []byte(`{"messages":["hello"]}`)
+	if err := store.Save(ctx, "c1", data); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "c1.json"))
+	if err != nil || bytes.Contains(raw, []byte("hello")) {
+		t.Errorf("Expected a sealed file, got %q, %v", raw, err)
+	}
+	if got, err := store.Load(ctx, "c1"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Expected the data back, got %q, %v", got, err)
+	}
+
+	if err := store.Delete(ctx, "c1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load(ctx, "c1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+	}
+	if err := store.Delete(ctx, "c1"); err != nil {
+		t.Errorf("Expected deleting a missing conversation to succeed, got %v", err)
+	}
+}
+
+func TestFileStore_InvalidID(t *testing.T) {
+	store, _ := NewFileStore(t.TempDir(), nil)
+	for _, id := range []string{"", "..", "../escape", "a/b"} {
+		if err := store.Save(context.Background(), id, []byte("{}")); err == nil {
+			t.Errorf("Expected ID %q to be refused", id)
+		}
+	}
+}
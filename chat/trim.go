@@ -0,0 +1,86 @@
+package chat
+
+import "github.com/xostack/xollm"
+
+// messageOverheadTokens approximates the tokens chat templates add around
+// each message for its role and separators.
+const messageOverheadTokens = 4
+
+// tokenBudget returns the tokens a turn's messages may take up, or 0 if
+// the history isn't trimmed by tokens.
+func (c *Conversation) tokenBudget() int {
+	if c.options.TokenBudget != 0 {
+		return max(c.options.TokenBudget, 0)
+	}
+	info, _ := xollm.LookupModel(c.model())
+	window, maxOutput := info.ContextWindow, info.MaxOutputTokens
+	// Prefer the window the client is actually served with, like Ollama's
+	// num_ctx, to the one the model supports
+	if provider, ok := c.client.(xollm.ContextWindowProvider); ok {
+		if n := provider.ContextWindow(); n > 0 {
+			window = n
+			if maxOutput <= 0 || maxOutput > n {
+				maxOutput = n
+			}
+		}
+	}
+	if window <= 0 {
+		return 0
+	}
+	reserve := c.options.ReserveTokens
+	if reserve <= 0 {
+		reserve = min(maxOutput, window/4)
+	}
+	return window - reserve
+}
+
+// model returns the client's model, or "" if it doesn't report one.
+func (c *Conversation) model() string {
+	if namer, ok := c.client.(xollm.ModelNamer); ok {
+		return namer.ModelName()
+	}
+	return ""
+}
+
+// messageTokens counts the tokens of a message with content, with the
+// tokenizer of the client's model.
+func (c *Conversation) messageTokens(content string) int {
+	return xollm.EstimateTokensFor(c.client, content) + messageOverheadTokens
+}
+
+// fitTokenBudget drops the oldest messages until the system prompt, the
+// history and text fit in the token budget. It doesn't leave a reply at
+// the start of the history whose message was dropped. If the system
+// prompt and text alone don't fit, it returns a *xollm.ContextLengthError
+// and leaves the history untouched.
+func (c *Conversation) fitTokenBudget(text string) error {
+	budget := c.tokenBudget()
+	if budget <= 0 {
+		return nil
+	}
+	used := c.messageTokens(text)
+	if c.options.SystemPrompt != "" {
+		used += c.messageTokens(c.options.SystemPrompt)
+	}
+	if used > budget {
+		return &xollm.ContextLengthError{Model: c.model(), PromptTokens: used, MaxTokens: budget, Estimated: true}
+	}
+
+	start := len(c.messages)
+	for start > 0 {
+		n := c.messageTokens(c.messages[start-1].Content)
+		if used+n > budget {
+			break
+		}
+		used += n
+		start--
+	}
+	if start == 0 {
+		return nil
+	}
+	for start < len(c.messages) && c.messages[start].Role != xollm.RoleUser {
+		start++
+	}
+	c.messages = c.messages[start:]
+	return nil
+}
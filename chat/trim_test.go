@@ -0,0 +1,135 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+// sentTokens counts the tokens of a turn's messages as the budget does.
+func sentTokens(c *Conversation, messages []xollm.Message) int {
+	var n int
+	for _, m := range messages {
+		n += c.messageTokens(m.Content)
+	}
+	return n
+}
+
+func TestConversation_TokenBudget(t *testing.T) {
+	client := &mockClient{}
+	conv := New(client, Options{SystemPrompt: "Be terse."})
+	long := strings.Repeat("word ", 40)
+	conv.messages = []Message{
+		{Role: xollm.RoleUser, Content: long},
+		{Role: xollm.RoleAssistant, Content: long},
+		{Role: xollm.RoleUser, Content: "short"},
+		{Role: xollm.RoleAssistant, Content: "reply"},
+	}
+	// Room for the system prompt, the last turn and the new message only
+	conv.options.TokenBudget = conv.messageTokens("Be terse.") + conv.messageTokens("short") +
+		conv.messageTokens("reply") + conv.messageTokens("next") + 1
+
+	if _, err := conv.SendMessage(context.Background(), "next"); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	sent := client.sent[0]
+	if len(sent) != 4 || sent[1].Content != "short" {
+		t.Errorf("Expected the oldest turn to be dropped, sent %+v", sent)
+	}
+	if n := sentTokens(conv, sent); n > conv.options.TokenBudget {
+		t.Errorf("Expected at most %d tokens to be sent, got %d", conv.options.TokenBudget, n)
+	}
+}
+
+func TestConversation_TokenBudgetDropsOrphanedReply(t *testing.T) {
+	client := &mockClient{}
+	conv := New(client, Options{})
+	conv.messages = []Message{
+		{Role: xollm.RoleUser, Content: strings.Repeat("word ", 40)},
+		{Role: xollm.RoleAssistant, Content: "reply"},
+	}
+	// The reply fits, the message it answers doesn't
+	conv.options.TokenBudget = conv.messageTokens("reply") + conv.messageTokens("next")
+
+	if _, err := conv.SendMessage(context.Background(), "next"); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if sent := client.sent[0]; len(sent) != 1 || sent[0].Role != xollm.RoleUser {
+		t.Errorf("Expected only the new message to be sent, got %+v", sent)
+	}
+}
+
+func TestConversation_ContextWindow(t *testing.T) {
+	xollm.RegisterModel(xollm.ModelInfo{Name: "chat-test-tiny", Provider: "groq", ContextWindow: 400, MaxOutputTokens: 300})
+	client := &mockClient{model: "chat-test-tiny"}
+	conv := New(client, Options{})
+	// The reserve is capped at a quarter of the window
+	if budget := conv.tokenBudget(); budget != 300 {
+		t.Fatalf("Expected a budget of 300 tokens, got %d", budget)
+	}
+
+	message := strings.Repeat("word ", 30)
+	for i := 0; i < 10; i++ {
+		if _, err := conv.SendMessage(context.Background(), message); err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+	last := client.sent[len(client.sent)-1]
+	if n := sentTokens(conv, last); n > 300 {
+		t.Errorf("Expected turns to fit in 300 tokens, the last sent %d", n)
+	}
+	if len(last) >= 19 {
+		t.Errorf("Expected the history to be trimmed, the last turn sent %d messages", len(last))
+	}
+
+	conv = New(client, Options{ReserveTokens: 100})
+	if budget := conv.tokenBudget(); budget != 300 {
+		t.Errorf("Expected a budget of 300 tokens with the reserve set, got %d", budget)
+	}
+	conv = New(&mockClient{model: "unknown-model"}, Options{})
+	if budget := conv.tokenBudget(); budget != 0 {
+		t.Errorf("Expected no budget for an unknown model, got %d", budget)
+	}
+	conv = New(client, Options{TokenBudget: -1})
+	if budget := conv.tokenBudget(); budget != 0 {
+		t.Errorf("Expected a negative budget to disable trimming, got %d", budget)
+	}
+}
+
+// windowClient is a mockClient served with a context window of its own.
+type windowClient struct {
+	mockClient
+	window int
+}
+
+func (w *windowClient) ContextWindow() int { return w.window }
+
+func TestConversation_ClientContextWindow(t *testing.T) {
+	// llama3.1 supports 128k tokens, but the client runs it with 2048
+	conv := New(&windowClient{mockClient: mockClient{provider: "ollama", model: "llama3.1"}, window: 2048}, Options{})
+	if budget := conv.tokenBudget(); budget != 2048-512 {
+		t.Errorf("Expected the client's window to set the budget, got %d", budget)
+	}
+	conv = New(&windowClient{mockClient: mockClient{model: "unknown-model"}, window: 1000}, Options{ReserveTokens: 200})
+	if budget := conv.tokenBudget(); budget != 800 {
+		t.Errorf("Expected a budget for an unregistered model with a known window, got %d", budget)
+	}
+}
+
+func TestConversation_MessageOverBudget(t *testing.T) {
+	client := &mockClient{}
+	conv := New(client, Options{TokenBudget: 10})
+	conv.messages = []Message{{Role: xollm.RoleUser, Content: "hi"}, {Role: xollm.RoleAssistant, Content: "hello"}}
+
+	_, err := conv.SendMessage(context.Background(), strings.Repeat("word ", 40))
+	var lengthErr *xollm.ContextLengthError
+	if !errors.As(err, &lengthErr) || !errors.Is(err, xollm.ErrContextTooLong) || lengthErr.MaxTokens != 10 {
+		t.Fatalf("Expected a context length error for a budget of 10, got %v", err)
+	}
+	if len(client.sent) != 0 || conv.Len() != 2 {
+		t.Errorf("Expected nothing sent and the history kept, got %d requests and %d messages", len(client.sent), conv.Len())
+	}
+}
@@ -0,0 +1,65 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// chattingClient implements Chatter, recording the messages sent
+type chattingClient struct {
+	stubClient
+	messages []Message
+}
+
+func (c *chattingClient) Chat(ctx context.Context, messages []Message) (string, *ResponseMetadata, error) {
+	c.messages = messages
+	return "native", &ResponseMetadata{FinishReason: "stop"}, nil
+}
+
+func TestChat_Native(t *testing.T) {
+	client := &chattingClient{}
+	messages := []Message{{Role: RoleUser, Content: "Hi"}}
+	reply, md, err := Chat(context.Background(), client, messages)
+	if err != nil || reply != "native" || md.FinishReason != "stop" || len(client.messages) != 1 {
+		t.Errorf("Expected the client's chat API to be used, got %q, %+v, %v", reply, md, err)
+	}
+}
+
+func TestChat_Flattened(t *testing.T) {
+	client := &promptRecorder{}
+	reply, md, err := Chat(context.Background(), client, []Message{
+		{Role: RoleSystem, Content: "Be terse."},
+		{Role: RoleUser, Content: "Capital of Norway?"},
+		{Role: RoleAssistant, Content: "Oslo."},
+		{Role: RoleUser, Content: "And of Sweden?"},
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if reply != "ok" || !md.Usage.Estimated {
+		t.Errorf("Unexpected reply %q, %+v", reply, md)
+	}
+	want := "System: Be terse.\n\nUser: Capital of Norway?\n\nAssistant: Oslo.\n\nUser: And of Sweden?\n\nAssistant:"
+	if client.prompt != want {
+		t.Errorf("Expected the flattened conversation, got %q", client.prompt)
+	}
+
+	if _, _, err := Chat(context.Background(), client, nil); err == nil {
+		t.Error("Expected an error for an empty chat")
+	}
+	if _, _, err := Chat(context.Background(), client, []Message{{Role: "function", Content: "x"}}); err == nil || !strings.Contains(err.Error(), "unknown role") {
+		t.Errorf("Expected an unknown role error, got: %v", err)
+	}
+}
+
+func TestGenerateWithSystem(t *testing.T) {
+	client := &chattingClient{}
+	text, err := GenerateWithSystem(context.Background(), client, "Answer in French.", "Hello")
+	if err != nil || text != "native" {
+		t.Fatalf("Expected the chat reply, got %q, %v", text, err)
+	}
+	if len(client.messages) != 2 || client.messages[0].Role != RoleSystem || client.messages[0].Content != "Answer in French." || client.messages[1].Content != "Hello" {
+		t.Errorf("Expected a system and a user message, got %+v", client.messages)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/xostack/xollm"
+	"github.com/xostack/xollm/bench"
+	"github.com/xostack/xollm/config"
+)
+
+// runBench implements 'xollm bench [flags] <prompts.txt>'. Providers are
+// benchmarked one after another so they don't compete for bandwidth. It
+// exits 1 when any request fails, and 2 on usage, configuration or read
+// failures.
+func runBench(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	providers := fs.String("providers", "", "Comma-separated providers to benchmark (defaults to the default provider)")
+	configPath := fs.String("config", "", "Configuration file (defaults to the xollm config file)")
+	concurrency := fs.Int("concurrency", 4, "Number of requests in flight at once")
+	duration := fs.Duration("duration", 30*time.Second, "How long to benchmark each provider for")
+	requests := fs.Int("requests", 0, "Stop after this many requests per provider (0 for no limit)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: xollm bench [flags] <prompts.txt>")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "The prompts file holds one prompt per line; blank lines and lines")
+		fmt.Fprintln(stderr, "starting with '#' are skipped.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	prompts, err := bench.ReadPromptsFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "xollm bench: %s: %v\n", fs.Arg(0), err)
+		return 2
+	}
+	if len(prompts) == 0 {
+		fmt.Fprintf(stderr, "xollm bench: %s: no prompts\n", fs.Arg(0))
+		return 2
+	}
+
+	path := *configPath
+	if path == "" {
+		if path, err = config.GetConfigFilePath(); err != nil {
+			fmt.Fprintf(stderr, "xollm bench: %v\n", err)
+			return 2
+		}
+	}
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "xollm bench: %v\n", err)
+		return 2
+	}
+	pool := xollm.NewPool(cfg, false)
+	defer pool.Close()
+
+	var clients []xollm.Client
+	if *providers == "" {
+		client, err := pool.Default()
+		if err != nil {
+			fmt.Fprintf(stderr, "xollm bench: %v\n", err)
+			return 2
+		}
+		clients = append(clients, client)
+	}
+	for _, name := range strings.Split(*providers, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		client, err := pool.Get(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "xollm bench: %v\n", err)
+			return 2
+		}
+		clients = append(clients, client)
+	}
+
+	// Interrupting stops the run and still reports what was measured
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := bench.Options{Concurrency: *concurrency, Duration: *duration, Requests: *requests}
+	var results []*bench.Result
+	failed := false
+	for _, client := range clients {
+		result, err := bench.Run(ctx, client, prompts, opts)
+		if err != nil {
+			fmt.Fprintf(stderr, "xollm bench: %v\n", err)
+			return 2
+		}
+		results = append(results, result)
+		failed = failed || result.Errors > 0
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	bench.WriteText(stdout, results...)
+	if failed {
+		return 1
+	}
+	return 0
+}
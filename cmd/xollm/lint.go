@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xostack/xollm/prompt"
+)
+
+// runLint implements 'xollm lint [flags] <dir>...'. It exits 1 when any
+// template has errors (or warnings with -strict), and 2 on usage or load
+// failures.
+func runLint(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	vars := fs.String("vars", "", "Comma-separated variables supplied when rendering (enables variable checks)")
+	model := fs.String("model", "", "Target model for the length check (defaults to each template's model hint)")
+	fraction := fs.Float64("max-context", 0.5, "Fraction of the model context window a template may use before warning")
+	strict := fs.Bool("strict", false, "Exit non-zero on warnings as well as errors")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: xollm lint [flags] <dir>...")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	opts := prompt.LintOptions{Model: *model, MaxContextFraction: *fraction}
+	if *vars != "" {
+		opts.Variables = splitList(*vars)
+	}
+
+	var issues prompt.Issues
+	for _, dir := range fs.Args() {
+		set, err := prompt.LoadDir(dir)
+		if err != nil {
+			fmt.Fprintf(stderr, "xollm lint: %v\n", err)
+			return 2
+		}
+		issues = append(issues, set.Lint(opts)...)
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+
+	if issues.HasErrors() || (*strict && len(issues) > 0) {
+		return 1
+	}
+	return 0
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,71 @@
+// Command xollm provides developer tooling for applications built on the
+// xollm library.
+//
+// Usage:
+//
+//	xollm <command> [flags] [arguments]
+//
+// Commands:
+//
+//	bench   Measure provider latency and throughput
+//	lint    Check prompt templates for common mistakes
+//	replay  Re-run recorded prompts and diff the responses
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a single xollm subcommand. run returns the process exit code.
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands maps subcommand names to their implementations
+var commands = map[string]command{
+	"bench":  {summary: "Measure provider latency and throughput", run: runBench},
+	"lint":   {summary: "Check prompt templates for common mistakes", run: runLint},
+	"replay": {summary: "Re-run recorded prompts and diff the responses", run: runReplay},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches to the subcommand named by args[0]
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "xollm: unknown command '%s'\n\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+// printUsage lists the available subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: xollm <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].summary)
+	}
+}
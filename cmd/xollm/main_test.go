@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePrompt(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without a command, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "lint") {
+		t.Errorf("Expected usage to list commands, got: %s", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := run([]string{"bogus"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for unknown command, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown command 'bogus'") {
+		t.Errorf("Expected unknown command message, got: %s", stderr.String())
+	}
+}
+
+func TestLint_Clean(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "greet.prompt", "Hello {{.Name}}")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint", "-vars", "Name", dir}, &stdout, &stderr); code != 0 {
+		t.Errorf("Expected exit code 0, got %d (stdout: %s, stderr: %s)", code, stdout.String(), stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected no output, got: %s", stdout.String())
+	}
+}
+
+func TestLint_ReportsIssues(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "greet.prompt", "Hello {{.Name}} from {{.Place}}")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint", "-vars", "Name, Extra", dir}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "greet: error: template references .Place") {
+		t.Errorf("Expected missing variable error, got: %s", output)
+	}
+	if !strings.Contains(output, "greet: warning: variable .Extra") {
+		t.Errorf("Expected unreferenced variable warning, got: %s", output)
+	}
+}
+
+func TestLint_Strict(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "style.prompt", "Be concise. Describe it in detail.")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint", dir}, &stdout, &stderr); code != 0 {
+		t.Errorf("Expected warnings alone to exit 0, got %d", code)
+	}
+	if code := run([]string{"lint", "-strict", dir}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected -strict to exit 1 on warnings, got %d", code)
+	}
+}
+
+func TestLint_UsageErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without a directory, got %d", code)
+	}
+	if code := run([]string{"lint", filepath.Join(t.TempDir(), "missing")}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for a missing directory, got %d", code)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model": "gemma:2b", "response": "Paris", "done": true}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	writePrompt(t, dir, "config.toml", "default_provider = \"ollama\"\n\n[llms.ollama]\nbase_url = \""+server.URL+"\"\n")
+	writePrompt(t, dir, "traffic.jsonl",
+		`{"messages":[{"role":"user","content":"Capital of France?"},{"role":"assistant","content":"Paris"}],"metadata":{"provider":"ollama"}}`+"\n"+
+			`{"messages":[{"role":"user","content":"Capital of Italy?"},{"role":"assistant","content":"Rome"}]}`+"\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"replay", "-config", configPath, filepath.Join(dir, "traffic.jsonl")}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	output := stdout.String()
+	for _, want := range []string{`#1 identical "Capital of France?"`, `#2 changed "Capital of Italy?"`, "- Rome\n+ Paris\n", "2 replayed: 1 identical, 1 changed, 0 failed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestReplay_UsageErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"replay"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without a file, got %d", code)
+	}
+	if code := run([]string{"replay", filepath.Join(t.TempDir(), "missing.jsonl")}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for a missing file, got %d", code)
+	}
+}
+
+func TestBench(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model": "gemma:2b", "response": "Paris", "done": true, "prompt_eval_count": 5, "eval_count": 2}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	writePrompt(t, dir, "config.toml", "default_provider = \"ollama\"\n\n[llms.ollama]\nbase_url = \""+server.URL+"\"\n")
+	writePrompt(t, dir, "prompts.txt", "# capitals\nCapital of France?\nCapital of Italy?\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"bench", "-config", configPath, "-providers", "ollama", "-requests", "6", "-concurrency", "2", filepath.Join(dir, "prompts.txt")}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	output := stdout.String()
+	for _, want := range []string{"p50", "p99", "tokens/s", "ollama", " 6 "} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestBench_UsageErrors(t *testing.T) {
+	dir := t.TempDir()
+	writePrompt(t, dir, "empty.txt", "# nothing here\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bench"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without a file, got %d", code)
+	}
+	if code := run([]string{"bench", filepath.Join(dir, "missing.txt")}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for a missing file, got %d", code)
+	}
+	if code := run([]string{"bench", filepath.Join(dir, "empty.txt")}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for a file without prompts, got %d", code)
+	}
+}
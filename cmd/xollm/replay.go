@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/xostack/xollm"
+	"github.com/xostack/xollm/config"
+	"github.com/xostack/xollm/dataset"
+	"github.com/xostack/xollm/replay"
+)
+
+// runReplay implements 'xollm replay [flags] <file.jsonl>...'. It exits 1
+// when any replay fails, and 2 on usage, configuration or read failures.
+func runReplay(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	provider := fs.String("provider", "", "Provider to replay against (defaults to each example's recorded provider)")
+	configPath := fs.String("config", "", "Configuration file (defaults to the xollm config file)")
+	concurrency := fs.Int("concurrency", 1, "Number of prompts replayed at once")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: xollm replay [flags] <file.jsonl>...")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var examples []dataset.Example
+	for _, path := range fs.Args() {
+		read, err := dataset.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "xollm replay: %s: %v\n", path, err)
+			return 2
+		}
+		examples = append(examples, read...)
+	}
+
+	path := *configPath
+	if path == "" {
+		var err error
+		if path, err = config.GetConfigFilePath(); err != nil {
+			fmt.Fprintf(stderr, "xollm replay: %v\n", err)
+			return 2
+		}
+	}
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "xollm replay: %v\n", err)
+		return 2
+	}
+	pool := xollm.NewPool(cfg, false)
+	defer pool.Close()
+
+	target := replay.Original(pool)
+	if *provider != "" {
+		client, err := pool.Get(*provider)
+		if err != nil {
+			fmt.Fprintf(stderr, "xollm replay: %v\n", err)
+			return 2
+		}
+		target = replay.To(client)
+	}
+
+	report := replay.Run(context.Background(), examples, target, replay.Options{Concurrency: *concurrency})
+	report.WriteText(stdout)
+	if report.Summary().Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -21,6 +21,9 @@
 //	api_key = "your-gemini-api-key"
 //	model = "gemma-3-27b-it"
 //
+// API keys may instead refer to a secret kept elsewhere, such as
+// "env:GROQ_API_KEY" or "vault:kv/xollm#groq"; see SecretsSource.
+//
 // Example programmatic usage:
 //
 //	cfg := config.NewConfig("gemini", 30, map[string]config.LLMConfig{
@@ -30,8 +33,10 @@ package config
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -40,6 +45,7 @@ import (
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/xostack/xollm/llm"
 )
 
 const (
@@ -63,16 +69,44 @@ type Config struct {
 	// If <= 0, a default timeout of 60 seconds will be used.
 	RequestTimeoutSeconds int `toml:"request_timeout_seconds"`
 
+	// PreflightTokenCheck makes clients estimate each prompt's size and
+	// reject prompts that can't fit in the model's context window before
+	// sending them. Models without a known context window are not checked.
+	PreflightTokenCheck bool `toml:"preflight_token_check,omitempty"`
+
+	// LazyInit defers expensive client setup, such as creating the Gemini
+	// SDK client, until a client is first used. Call xollm.Init to validate
+	// a client eagerly.
+	LazyInit bool `toml:"lazy_init,omitempty"`
+
 	// LLMs contains provider-specific configurations keyed by provider name.
 	// Each provider may have different required fields (e.g., APIKey vs BaseURL).
 	LLMs map[string]LLMConfig `toml:"llms"`
+
+	// ModelAliases maps logical model names to the identifier of the model
+	// on each provider, so the same name can be set as the model of every
+	// provider. They add to and override the bundled aliases.
+	// Example:
+	//
+	//	[model_aliases.fast]
+	//	groq = "llama-3.1-8b-instant"
+	//	ollama = "llama3.2"
+	ModelAliases map[string]map[string]string `toml:"model_aliases,omitempty"`
+
+	// StorageEncryptionKey is the key that encrypts conversations and
+	// sessions persisted to disk or a database, 32 bytes encoded in hex or
+	// base64. Like API keys it may be a secret reference, such as
+	// "env:XOLLM_STORAGE_KEY" or a scheme registered for the OS keyring
+	// (see RegisterSecretsSource). If empty, stores write plaintext. See
+	// the encryption package.
+	StorageEncryptionKey string `toml:"storage_encryption_key,omitempty"`
 }
 
 // LLMConfig holds configuration specific to an LLM provider.
 //
 // Different providers require different fields:
 //   - Gemini/Groq: Require APIKey
-//   - Ollama: Requires BaseURL
+//   - Ollama: Requires BaseURL or BaseURLs
 //   - All providers: Support optional Model override
 //
 // Use pointers to distinguish between unset and explicitly empty values if needed,
@@ -83,14 +117,79 @@ type LLMConfig struct {
 	// Example: "http://localhost:11434"
 	BaseURL string `toml:"base_url,omitempty"`
 
+	// BaseURLs lists several servers serving the same models (used by
+	// Ollama). Requests are balanced across them and skip servers that
+	// fail; BaseURL, if also set, is the first server.
+	// Example: ["http://gpu-1:11434", "http://gpu-2:11434"]
+	BaseURLs []string `toml:"base_urls,omitempty"`
+
+	// StickySessions sends the requests of one session to the same server
+	// of BaseURLs while it is healthy, so conversation state cached on the
+	// server is reused. See xollm.WithStickySessions.
+	StickySessions bool `toml:"sticky_sessions,omitempty"`
+
 	// APIKey is the authentication key for cloud-based providers (Gemini, Groq).
 	// This field contains sensitive information and should be handled securely.
+	// It may be a secret reference such as "env:GEMINI_API_KEY", resolved
+	// when the configuration is loaded.
 	APIKey string `toml:"api_key,omitempty"`
 
 	// Model is an optional model name override for the provider.
-	// If empty, the provider's default model will be used.
+	// If empty, the provider's default model will be used. It may be a
+	// logical name such as "llama3-8b", resolved through Config.ModelAliases
+	// and the bundled aliases.
 	// Example: "gemini-1.5-pro", "gemma:2b", "mixtral-8x7b-32768"
 	Model string `toml:"model,omitempty"`
+
+	// SystemPrompt is an optional system instruction sent with every
+	// request to this provider, separately from the user prompt.
+	// Example: "You are a concise assistant. Answer in plain text."
+	SystemPrompt string `toml:"system_prompt,omitempty"`
+
+	// EmbeddingModel is the model that computes embeddings, for providers
+	// with an embeddings API (Ollama, Gemini). If empty, Ollama uses Model
+	// and Gemini uses text-embedding-004.
+	// Example: "nomic-embed-text", "text-embedding-004"
+	EmbeddingModel string `toml:"embedding_model,omitempty"`
+
+	// TranscriptionModel is the model that transcribes speech, for
+	// providers with a transcription API (Groq). If empty, Groq uses
+	// whisper-large-v3-turbo.
+	// Example: "whisper-large-v3"
+	TranscriptionModel string `toml:"transcription_model,omitempty"`
+
+	// ImageModel is the model that generates images, for providers with an
+	// image generation API (Gemini, with Imagen). If empty, Gemini uses
+	// imagen-3.0-generate-002.
+	// Example: "imagen-4.0-generate-001"
+	ImageModel string `toml:"image_model,omitempty"`
+
+	// Organization and Project attribute requests to an organization and
+	// project of the provider account for billing, for providers that
+	// support it. Groq sends them as the OpenAI-style OpenAI-Organization
+	// and OpenAI-Project headers; Gemini sends Project as the Google Cloud
+	// billing project (x-goog-user-project).
+	// Example: "org-123abc", "proj_456def"
+	Organization string `toml:"organization,omitempty"`
+	Project      string `toml:"project,omitempty"`
+
+	// Temperature, MaxTokens, TopP, Stop, Seed and the presence and
+	// frequency penalties set the default sampling parameters of requests.
+	// If unset, the provider's defaults apply. A fixed seed makes Ollama
+	// and Groq outputs repeatable; the penalties, from -2 to 2, reduce
+	// repetition on Ollama and Groq.
+	Temperature      *float64 `toml:"temperature,omitempty"`
+	MaxTokens        *int     `toml:"max_tokens,omitempty"`
+	TopP             *float64 `toml:"top_p,omitempty"`
+	Stop             []string `toml:"stop,omitempty"`
+	Seed             *int     `toml:"seed,omitempty"`
+	PresencePenalty  *float64 `toml:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `toml:"frequency_penalty,omitempty"`
+
+	// Options are model runtime settings for self-hosted servers (used by
+	// Ollama), such as num_ctx, num_gpu, num_thread, mirostat and
+	// repeat_penalty, set in a [llms.ollama.options] table.
+	Options llm.RuntimeOptions `toml:"options,omitempty"`
 }
 
 // Default configuration values.
@@ -138,8 +237,14 @@ func GetConfigFilePath() (string, error) { // EXPORTED and RENAMED
 }
 
 // Load reads the configuration file, creates it interactively if missing,
-// merges with defaults, and returns the final Config.
-func Load(debugMode bool) (Config, error) { // MODIFIED: Added debugMode
+// merges with defaults, and returns the final Config. It logs its progress
+// at debug level to the default logger; see llm.SetDefaultLogger.
+//
+// debugMode is deprecated and kept for compatibility: true logs to
+// standard error at debug level instead, like llm.DebugLogger. Pass false
+// and set the default logger.
+func Load(debugMode bool) (Config, error) {
+	logger := loadLogger(debugMode)
 	cfgPath, err := GetConfigFilePath()
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to determine config path: %w", err)
@@ -152,18 +257,14 @@ func Load(debugMode bool) (Config, error) { // MODIFIED: Added debugMode
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			// Config file doesn't exist, ask to create
-			if debugMode {
-				fmt.Printf("Configuration file not found at %s\n", cfgPath)
-			}
+			logger.Debug("configuration file not found", "path", cfgPath)
 			if askToCreateConfigFile() {
-				err = createConfigFileInteractive(cfgPath, &cfg, debugMode) // MODIFIED: Pass debugMode
+				err = createConfigFileInteractive(cfgPath, &cfg, logger)
 				if err != nil {
 					return Config{}, fmt.Errorf("failed to create configuration file: %w", err)
 				}
 				// File created, proceed to load (or just use the interactively filled cfg)
-				if debugMode {
-					fmt.Printf("Configuration file created successfully at %s\n", cfgPath)
-				}
+				logger.Debug("configuration file created", "path", cfgPath)
 				// No need to reload here, createConfigFileInteractive populates cfg
 			} else {
 				return Config{}, fmt.Errorf("configuration file creation declined by user.\n\nTo create a configuration file later, use the xollm config API or manually create the config file")
@@ -174,9 +275,7 @@ func Load(debugMode bool) (Config, error) { // MODIFIED: Added debugMode
 		}
 	} else {
 		// File exists, load it and merge over defaults
-		if debugMode {
-			fmt.Printf("Loading configuration from %s\n", cfgPath) // MODIFIED: Conditional print
-		}
+		logger.Debug("loading configuration", "path", cfgPath)
 		meta, err := toml.DecodeFile(cfgPath, &cfg)
 		if err != nil {
 			return Config{}, fmt.Errorf("failed to decode TOML config file %s: %w", cfgPath, err)
@@ -187,6 +286,10 @@ func Load(debugMode bool) (Config, error) { // MODIFIED: Added debugMode
 		}
 	}
 
+	if err := ResolveSecrets(context.Background(), &cfg); err != nil {
+		return Config{}, err
+	}
+
 	// Final validation (e.g., ensure default provider is configured)
 	if _, exists := cfg.LLMs[cfg.DefaultProvider]; !exists {
 		return Config{}, fmt.Errorf("default provider '%s' is specified but has no configuration section in [llms]", cfg.DefaultProvider)
@@ -196,6 +299,13 @@ func Load(debugMode bool) (Config, error) { // MODIFIED: Added debugMode
 	return cfg, nil
 }
 
+// loadLogger returns the logger of Load: a debug logger writing to
+// standard error if debugMode, else the default logger at the time of the
+// call.
+func loadLogger(debugMode bool) *slog.Logger {
+	return llm.Logger(context.Background(), llm.ClientOptions{}.ClientLogger(debugMode))
+}
+
 // askToCreateConfigFile prompts the user if they want to create the config file.
 func askToCreateConfigFile() bool {
 	reader := bufio.NewReader(os.Stdin)
@@ -208,7 +318,7 @@ func askToCreateConfigFile() bool {
 }
 
 // createConfigFileInteractive guides the user through setting up the initial config.
-func createConfigFileInteractive(cfgPath string, cfg *Config, debugMode bool) error { // MODIFIED: Added debugMode
+func createConfigFileInteractive(cfgPath string, cfg *Config, logger *slog.Logger) error {
 	reader := bufio.NewReader(os.Stdin)
 	configuredProvider := false
 
@@ -220,7 +330,7 @@ func createConfigFileInteractive(cfgPath string, cfg *Config, debugMode bool) er
 	ollamaURLInput, _ := reader.ReadString('\n')
 	ollamaURLInput = strings.TrimSpace(ollamaURLInput)
 	if ollamaURLInput != "" {
-		if err := validateOllamaURL(ollamaURLInput, debugMode); err != nil {
+		if err := validateOllamaURL(ollamaURLInput, logger); err != nil {
 			fmt.Printf("⚠️  Warning: Could not connect to Ollama at %s: %v\n", ollamaURLInput, err)
 			fmt.Printf("   The configuration will be saved anyway. Make sure Ollama is running.\n")
 		} else {
@@ -328,8 +438,9 @@ func createConfigFileInteractive(cfgPath string, cfg *Config, debugMode bool) er
 	return nil // Success
 }
 
-// validateOllamaURL attempts to connect to the Ollama base URL.
-func validateOllamaURL(rawURL string, debugMode bool) error { // MODIFIED: Added debugMode
+// validateOllamaURL attempts to connect to the Ollama base URL, logging
+// the outcome to logger.
+func validateOllamaURL(rawURL string, logger *slog.Logger) error {
 	if rawURL == "" {
 		return errors.New("URL cannot be empty")
 	}
@@ -357,10 +468,7 @@ func validateOllamaURL(rawURL string, debugMode bool) error { // MODIFIED: Added
 
 	resp, err := client.Do(req)
 	if err != nil {
-		// Only print warning if in debug mode, otherwise fail more silently for interactive setup
-		if debugMode {
-			fmt.Fprintf(os.Stderr, "Warning: Ollama URL validation failed for %s: %v.\n", rawURL, err)
-		}
+		logger.Debug("Ollama URL validation failed", "url", rawURL, "error", err)
 		return fmt.Errorf("failed to connect to Ollama server at %s: %w", rawURL, err)
 	}
 	defer resp.Body.Close()
@@ -371,9 +479,7 @@ func validateOllamaURL(rawURL string, debugMode bool) error { // MODIFIED: Added
 	// return fmt.Errorf("server responded with status %s", resp.Status)
 	// }
 	// For now, just succeeding the connection is good enough validation.
-	if debugMode {
-		fmt.Printf("Successfully connected to Ollama at %s (Status: %s)\n", rawURL, resp.Status) // MODIFIED: Conditional print
-	}
+	logger.Debug("connected to Ollama", "url", rawURL, "status", resp.Status)
 	return nil
 }
 
@@ -449,6 +555,10 @@ func LoadFromFile(filePath string) (Config, error) {
 		// For now, we'll just ignore them but could return an error in strict mode
 	}
 
+	if err := ResolveSecrets(context.Background(), &cfg); err != nil {
+		return Config{}, err
+	}
+
 	// Final validation (e.g., ensure default provider is configured)
 	if _, exists := cfg.LLMs[cfg.DefaultProvider]; !exists {
 		return Config{}, fmt.Errorf("default provider '%s' is specified but has no configuration section in [llms]", cfg.DefaultProvider)
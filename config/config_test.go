@@ -1,10 +1,15 @@
 package config
 
 import (
+	"bytes"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/xostack/xollm/llm"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -173,7 +178,7 @@ func TestValidateOllamaURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateOllamaURL(tt.url, false)
+			err := validateOllamaURL(tt.url, llm.DefaultLogger())
 			if tt.shouldErr && err == nil {
 				t.Error("Expected error but got none")
 			}
@@ -236,3 +241,125 @@ model = "gemma-3-27b-it"
 		t.Errorf("Expected ollama URL 'http://localhost:11434', got '%s'", ollamaCfg.BaseURL)
 	}
 }
+
+func TestLoad_LogsToDefaultLogger(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := GetConfigFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("default_provider = \"ollama\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	llm.SetDefaultLogger(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer llm.SetDefaultLogger(nil)
+	if _, err := Load(false); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "loading configuration") || !strings.Contains(out.String(), path) {
+		t.Errorf("Expected Load to log to the default logger, got %q", out.String())
+	}
+}
+
+func TestLLMConfig_SamplingFromTOML(t *testing.T) {
+	var cfg Config
+	_, err := toml.Decode(`
+[llms.groq]
+api_key = "key"
+temperature = 0.3
+max_tokens = 512
+stop = ["\n\nUser:", "###"]
+seed = 42
+presence_penalty = 0.6
+frequency_penalty = -0.5
+`, &cfg)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	groq := cfg.LLMs["groq"]
+	if groq.Temperature == nil || *groq.Temperature != 0.3 {
+		t.Errorf("Expected temperature 0.3, got %v", groq.Temperature)
+	}
+	if groq.MaxTokens == nil || *groq.MaxTokens != 512 {
+		t.Errorf("Expected max_tokens 512, got %v", groq.MaxTokens)
+	}
+	if groq.TopP != nil {
+		t.Errorf("Expected unset top_p to stay nil, got %v", *groq.TopP)
+	}
+	if len(groq.Stop) != 2 || groq.Stop[0] != "\n\nUser:" || groq.Stop[1] != "###" {
+		t.Errorf("Expected two stop sequences, got %q", groq.Stop)
+	}
+	if groq.Seed == nil || *groq.Seed != 42 {
+		t.Errorf("Expected seed 42, got %v", groq.Seed)
+	}
+	if groq.PresencePenalty == nil || *groq.PresencePenalty != 0.6 || groq.FrequencyPenalty == nil || *groq.FrequencyPenalty != -0.5 {
+		t.Errorf("Expected penalties 0.6 and -0.5, got %v and %v", groq.PresencePenalty, groq.FrequencyPenalty)
+	}
+}
+
+func TestLLMConfig_OrganizationFromTOML(t *testing.T) {
+	var cfg Config
+	_, err := toml.Decode(`
+[llms.groq]
+api_key = "key"
+organization = "org-123"
+project = "proj_456"
+`, &cfg)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if groq := cfg.LLMs["groq"]; groq.Organization != "org-123" || groq.Project != "proj_456" {
+		t.Errorf("Expected the organization and project, got %q, %q", groq.Organization, groq.Project)
+	}
+}
+
+func TestLLMConfig_RuntimeOptionsFromTOML(t *testing.T) {
+	var cfg Config
+	_, err := toml.Decode(`
+[llms.ollama]
+base_url = "http://localhost:11434"
+
+[llms.ollama.options]
+num_ctx = 32768
+num_thread = 8
+mirostat = 2
+`, &cfg)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	options := cfg.LLMs["ollama"].Options
+	if options.NumCtx == nil || *options.NumCtx != 32768 || options.NumThread == nil || *options.NumThread != 8 || options.Mirostat == nil || *options.Mirostat != 2 {
+		t.Errorf("Unexpected options: %+v", options)
+	}
+	if options.NumGPU != nil {
+		t.Error("Expected unset num_gpu to stay nil")
+	}
+}
+
+func TestConfig_ModelAliasesFromTOML(t *testing.T) {
+	content := `default_provider = "groq"
+
+[llms.groq]
+api_key = "key"
+model = "fast"
+
+[model_aliases.fast]
+groq = "llama-3.1-8b-instant"
+ollama = "llama3.2"
+`
+	var cfg Config
+	if _, err := toml.Decode(content, &cfg); err != nil {
+		t.Fatalf("Failed to decode TOML: %v", err)
+	}
+	if got := cfg.ModelAliases["fast"]["groq"]; got != "llama-3.1-8b-instant" {
+		t.Errorf("Expected the groq alias, got %q", got)
+	}
+	if got := cfg.ModelAliases["fast"]["ollama"]; got != "llama3.2" {
+		t.Errorf("Expected the ollama alias, got %q", got)
+	}
+}
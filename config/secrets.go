@@ -0,0 +1,220 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretsSource resolves secret references in the configuration, so API
+// keys don't have to be stored in the TOML file. A reference is written as
+// "<scheme>:<ref>", e.g.
+//
+//	api_key = "env:GEMINI_API_KEY"
+//	api_key = "file:/run/secrets/groq"
+//	api_key = "vault:kv/xollm#gemini"
+//
+// and Resolve is called with the part after the scheme.
+type SecretsSource interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretsSourceFunc adapts a function to a SecretsSource.
+type SecretsSourceFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f.
+func (f SecretsSourceFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	secretsMu      sync.RWMutex
+	secretsSources = map[string]SecretsSource{
+		"env":   EnvSource{},
+		"file":  FileSource{},
+		"vault": NewVaultSourceFromEnv(),
+	}
+)
+
+// RegisterSecretsSource makes src resolve references with the given scheme,
+// replacing any source registered for it, e.g. to add AWS Secrets Manager
+// or to configure the Vault source explicitly.
+func RegisterSecretsSource(scheme string, src SecretsSource) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secretsSources[scheme] = src
+}
+
+// lookupSecretsSource returns the source for the scheme of value and the
+// reference to resolve. Values without a registered scheme are not
+// references and are used as they are.
+func lookupSecretsSource(value string) (SecretsSource, string, bool) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, "", false
+	}
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	src, ok := secretsSources[scheme]
+	return src, ref, ok
+}
+
+// ResolveSecrets replaces the secret references in the API keys and the
+// storage encryption key of cfg with the secrets they refer to. Load and
+// LoadFromFile call it; call it for configurations built programmatically
+// or with NewConfig.
+func ResolveSecrets(ctx context.Context, cfg *Config) error {
+	if src, ref, ok := lookupSecretsSource(cfg.StorageEncryptionKey); ok {
+		secret, err := src.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("failed to resolve storage_encryption_key: %w", err)
+		}
+		cfg.StorageEncryptionKey = secret
+	}
+	for name, llmCfg := range cfg.LLMs {
+		src, ref, ok := lookupSecretsSource(llmCfg.APIKey)
+		if !ok {
+			continue
+		}
+		secret, err := src.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("failed to resolve api_key for %s: %w", name, err)
+		}
+		llmCfg.APIKey = secret
+		cfg.LLMs[name] = llmCfg
+	}
+	return nil
+}
+
+// EnvSource resolves references to environment variables, e.g.
+// "env:GROQ_API_KEY".
+type EnvSource struct{}
+
+// Resolve returns the value of the environment variable ref.
+func (EnvSource) Resolve(ctx context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+	return value, nil
+}
+
+// FileSource resolves references to files holding a single secret, such as
+// Docker or Kubernetes secrets, e.g. "file:/run/secrets/groq". Surrounding
+// whitespace, including the trailing newline, is removed.
+type FileSource struct{}
+
+// Resolve returns the contents of the file ref.
+func (FileSource) Resolve(ctx context.Context, ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", ref)
+	}
+	return secret, nil
+}
+
+// VaultSource resolves references to fields of HashiCorp Vault KV secrets.
+// A reference is the secret's path, starting with the mount, and the field:
+// "kv/xollm#gemini" reads the field gemini of the secret xollm in the
+// engine mounted at kv.
+type VaultSource struct {
+	// Address is the Vault server URL, e.g. "https://vault.example.com:8200".
+	Address string
+	// Token authenticates the requests.
+	Token string
+	// Namespace is the Vault Enterprise namespace, if any.
+	Namespace string
+	// KVVersion is the version of the KV secrets engine, 1 or 2. If 0,
+	// version 2 is assumed.
+	KVVersion int
+	// HTTPClient sends the requests. If nil, a client with a 10 second
+	// timeout is used.
+	HTTPClient *http.Client
+}
+
+// NewVaultSourceFromEnv returns a VaultSource configured from the standard
+// VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE environment variables, read
+// when a reference is resolved.
+func NewVaultSourceFromEnv() SecretsSource {
+	return SecretsSourceFunc(func(ctx context.Context, ref string) (string, error) {
+		v := &VaultSource{
+			Address:   os.Getenv("VAULT_ADDR"),
+			Token:     os.Getenv("VAULT_TOKEN"),
+			Namespace: os.Getenv("VAULT_NAMESPACE"),
+		}
+		return v.Resolve(ctx, ref)
+	})
+}
+
+// Resolve reads the field of the secret ref refers to.
+func (v *VaultSource) Resolve(ctx context.Context, ref string) (string, error) {
+	if v.Address == "" || v.Token == "" {
+		return "", fmt.Errorf("vault address and token are required")
+	}
+	path, field, ok := strings.Cut(strings.Trim(ref, "/"), "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("invalid vault reference %q, expected <mount>/<path>#<field>", ref)
+	}
+	if v.KVVersion != 1 {
+		// KV v2 serves secrets under <mount>/data/<path>
+		mount, rest, _ := strings.Cut(path, "/")
+		path = mount + "/data/" + rest
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(v.Address, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	httpClient := v.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, path)
+	}
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	data := secret.Data
+	if v.KVVersion != 1 {
+		data = nil
+		if err := json.Unmarshal(secret.Data["data"], &data); err != nil {
+			return "", fmt.Errorf("failed to decode vault response: %w", err)
+		}
+	}
+
+	var value string
+	if raw, ok := data[field]; !ok || json.Unmarshal(raw, &value) != nil || value == "" {
+		return "", fmt.Errorf("vault secret %s has no string field %q", path, field)
+	}
+	return value, nil
+}
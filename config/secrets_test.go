@@ -0,0 +1,133 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("XOLLM_TEST_GROQ_KEY", "groq-secret")
+	secretFile := filepath.Join(t.TempDir(), "gemini")
+	if err := os.WriteFile(secretFile, []byte("gemini-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig("groq", 30, map[string]LLMConfig{
+		"groq":   {APIKey: "env:XOLLM_TEST_GROQ_KEY"},
+		"gemini": {APIKey: "file:" + secretFile},
+		"plain":  {APIKey: "gsk_plain"},
+		"ollama": {BaseURL: "http://localhost:11434"},
+	})
+	if err := ResolveSecrets(context.Background(), &cfg); err != nil {
+		t.Fatalf("ResolveSecrets failed: %v", err)
+	}
+	for name, want := range map[string]string{"groq": "groq-secret", "gemini": "gemini-secret", "plain": "gsk_plain", "ollama": ""} {
+		if got := cfg.LLMs[name].APIKey; got != want {
+			t.Errorf("Expected %s key %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestResolveSecrets_Error(t *testing.T) {
+	cfg := NewConfig("groq", 30, map[string]LLMConfig{"groq": {APIKey: "env:XOLLM_TEST_UNSET_KEY"}})
+	err := ResolveSecrets(context.Background(), &cfg)
+	if err == nil || !strings.Contains(err.Error(), "groq") || !strings.Contains(err.Error(), "XOLLM_TEST_UNSET_KEY") {
+		t.Errorf("Expected an unset variable error, got %v", err)
+	}
+}
+
+func TestRegisterSecretsSource(t *testing.T) {
+	RegisterSecretsSource("test", SecretsSourceFunc(func(ctx context.Context, ref string) (string, error) {
+		return "resolved-" + ref, nil
+	}))
+	defer func() {
+		secretsMu.Lock()
+		delete(secretsSources, "test")
+		secretsMu.Unlock()
+	}()
+
+	cfg := NewConfig("groq", 30, map[string]LLMConfig{"groq": {APIKey: "test:groq"}})
+	if err := ResolveSecrets(context.Background(), &cfg); err != nil {
+		t.Fatalf("ResolveSecrets failed: %v", err)
+	}
+	if cfg.LLMs["groq"].APIKey != "resolved-groq" {
+		t.Errorf("Expected the custom source to resolve the key, got %q", cfg.LLMs["groq"].APIKey)
+	}
+}
+
+func TestVaultSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/xollm":
+			w.Write([]byte(`{"data": {"data": {"gemini": "v2-secret"}, "metadata": {"version": 1}}}`))
+		case "/v1/secret/xollm":
+			w.Write([]byte(`{"data": {"gemini": "v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	v2 := &VaultSource{Address: server.URL, Token: "root"}
+	if got, err := v2.Resolve(context.Background(), "kv/xollm#gemini"); err != nil || got != "v2-secret" {
+		t.Errorf("Expected the KV v2 secret, got %q, %v", got, err)
+	}
+	v1 := &VaultSource{Address: server.URL, Token: "root", KVVersion: 1}
+	if got, err := v1.Resolve(context.Background(), "secret/xollm#gemini"); err != nil || got != "v1-secret" {
+		t.Errorf("Expected the KV v1 secret, got %q, %v", got, err)
+	}
+
+	if _, err := v2.Resolve(context.Background(), "kv/xollm#groq"); err == nil {
+		t.Error("Expected an error for a missing field")
+	}
+	if _, err := v2.Resolve(context.Background(), "kv/xollm"); err == nil {
+		t.Error("Expected an error for a reference without a field")
+	}
+	bad := &VaultSource{Address: server.URL, Token: "wrong"}
+	if _, err := bad.Resolve(context.Background(), "kv/xollm#gemini"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected a forbidden error, got %v", err)
+	}
+}
+
+func TestLoadFromFile_ResolvesSecrets(t *testing.T) {
+	t.Setenv("XOLLM_TEST_GROQ_KEY", "groq-secret")
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "default_provider = \"groq\"\n\n[llms.groq]\napi_key = \"env:XOLLM_TEST_GROQ_KEY\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.LLMs["groq"].APIKey != "groq-secret" {
+		t.Errorf("Expected the resolved key, got %q", cfg.LLMs["groq"].APIKey)
+	}
+}
+
+func TestResolveSecrets_StorageEncryptionKey(t *testing.T) {
+	t.Setenv("XOLLM_TEST_STORAGE_KEY", "c2VjcmV0")
+	cfg := NewConfig("groq", 30, map[string]LLMConfig{"groq": {APIKey: "gsk_plain"}})
+	cfg.StorageEncryptionKey = "env:XOLLM_TEST_STORAGE_KEY"
+	if err := ResolveSecrets(context.Background(), &cfg); err != nil {
+		t.Fatalf("ResolveSecrets failed: %v", err)
+	}
+	if cfg.StorageEncryptionKey != "c2VjcmV0" {
+		t.Errorf("Expected the resolved key, got %q", cfg.StorageEncryptionKey)
+	}
+
+	cfg.StorageEncryptionKey = "env:XOLLM_TEST_UNSET_KEY"
+	if err := ResolveSecrets(context.Background(), &cfg); err == nil || !strings.Contains(err.Error(), "storage_encryption_key") {
+		t.Errorf("Expected an error naming the key, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultMaxContinuations is the follow-up request cap of
+	// GenerateContinued.
+	defaultMaxContinuations = 3
+	// minStitchOverlap and maxStitchOverlap bound the text a continuation
+	// may repeat from the end of the previous part, in bytes. The minimum
+	// keeps a continuation that merely starts with the letters the text
+	// ends with from being cut.
+	minStitchOverlap = 8
+	maxStitchOverlap = 500
+)
+
+// ContinueOption configures GenerateContinued.
+type ContinueOption func(*continueOptions)
+
+// continueOptions holds the settings of GenerateContinued.
+type continueOptions struct {
+	max    int
+	prompt func(prompt, soFar string) string
+}
+
+// WithMaxContinuations caps the follow-up requests GenerateContinued makes
+// at n, 3 by default. A response still truncated after n continuations is
+// returned with FinishReason unchanged, so callers can tell.
+func WithMaxContinuations(n int) ContinueOption {
+	return func(o *continueOptions) {
+		o.max = n
+	}
+}
+
+// WithContinuationPrompt replaces the prompt of follow-up requests. fn
+// gets the original prompt and the response so far, and returns the prompt
+// asking the model to continue it.
+func WithContinuationPrompt(fn func(prompt, soFar string) string) ContinueOption {
+	return func(o *continueOptions) {
+		o.prompt = fn
+	}
+}
+
+// continuationPrompt is the default prompt of follow-up requests. Clients
+// have no conversation state, so it repeats the request and the response
+// so far.
+func continuationPrompt(prompt, soFar string) string {
+	return prompt + "\n\nYour response was cut off by the length limit. Here is what you wrote so far:\n\n" +
+		soFar + "\n\nContinue exactly where it stops. Do not repeat any of it and do not add any preamble."
+}
+
+// IsTruncated reports whether md's finish reason says the generation was
+// cut off by the token limit: "length" for Groq and Ollama, "MaxTokens" for
+// Gemini.
+func IsTruncated(md *ResponseMetadata) bool {
+	return md != nil && md.Finish() == FinishLength
+}
+
+// GenerateContinued is GenerateWithMetadata for long outputs: while the
+// response is truncated by the token limit (see IsTruncated), it asks the
+// model to continue, up to WithMaxContinuations times, and returns the
+// parts stitched together. Text a continuation repeats from the end of the
+// previous part is dropped.
+//
+// The metadata is that of the last request, with Usage and
+// EstimatedCostUSD summed over all of them and Continuations counting the
+// follow-ups. If a follow-up fails, the text so far is returned with the
+// error.
+//
+//	text, md, err := xollm.GenerateContinued(ctx, client, "Write the full report.", xollm.WithMaxContinuations(5))
+func GenerateContinued(ctx context.Context, client Client, prompt string, opts ...ContinueOption) (string, *ResponseMetadata, error) {
+	o := continueOptions{max: defaultMaxContinuations, prompt: continuationPrompt}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	text, md, err := GenerateWithMetadata(ctx, client, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	total := *md
+	for total.Continuations < o.max && IsTruncated(md) {
+		var next string
+		next, md, err = GenerateWithMetadata(ctx, client, o.prompt(prompt, text))
+		if err != nil {
+			return text, &total, fmt.Errorf("continuation %d failed: %w", total.Continuations+1, err)
+		}
+		text = stitch(text, next)
+		prev := total
+		total = *md
+		total.Usage = addUsage(prev.Usage, md.Usage)
+		total.EstimatedCostUSD = prev.EstimatedCostUSD + md.EstimatedCostUSD
+		total.Continuations = prev.Continuations + 1
+	}
+	return text, &total, nil
+}
+
+// addUsage returns the sum of two requests' usage, estimated if either is.
+func addUsage(a, b Usage) Usage {
+	return Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		CachedTokens:     a.CachedTokens + b.CachedTokens,
+		Estimated:        a.Estimated || b.Estimated,
+	}
+}
+
+// stitch appends a continuation to the text before it. Models often start
+// a continuation by repeating the end of the text; the longest such
+// overlap is dropped. Providers trim the whitespace around responses, and a
+// truncation falls between tokens, which mostly start with a space, so a
+// continuation starting with a word is joined to a word or punctuation
+// with a space.
+func stitch(before, next string) string {
+	trimmed := strings.TrimLeftFunc(next, unicode.IsSpace)
+	for n := min(len(before), len(trimmed), maxStitchOverlap); n >= minStitchOverlap; n-- {
+		if strings.HasSuffix(before, trimmed[:n]) {
+			return before + trimmed[n:]
+		}
+	}
+	last, _ := utf8.DecodeLastRuneInString(before)
+	first, _ := utf8.DecodeRuneInString(next)
+	if endsWord(last) && startsWord(first) {
+		return before + " " + next
+	}
+	return before + next
+}
+
+// endsWord reports whether r is a letter, digit or punctuation that is
+// usually followed by a space.
+func endsWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".,;:!?)", r)
+}
+
+// startsWord reports whether r is a letter or digit.
+func startsWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
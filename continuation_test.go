@@ -0,0 +1,121 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// scriptedClient returns one response per call, in order, and records the
+// prompts it was sent
+type scriptedClient struct {
+	stubClient
+	responses []string
+	reasons   []string
+	errs      []error
+	prompts   []string
+}
+
+func (s *scriptedClient) GenerateWithMetadata(ctx context.Context, prompt string) (string, *ResponseMetadata, error) {
+	i := len(s.prompts)
+	s.prompts = append(s.prompts, prompt)
+	if i < len(s.errs) && s.errs[i] != nil {
+		return "", nil, s.errs[i]
+	}
+	return s.responses[i], &ResponseMetadata{
+		FinishReason:     s.reasons[i],
+		Usage:            Usage{PromptTokens: 10, CompletionTokens: 100},
+		EstimatedCostUSD: 0.01,
+	}, nil
+}
+
+func TestIsTruncated(t *testing.T) {
+	for reason, want := range map[string]bool{"length": true, "MaxTokens": true, "max_tokens": true, "stop": false, "STOP": false, "": false} {
+		if got := IsTruncated(&ResponseMetadata{FinishReason: reason}); got != want {
+			t.Errorf("IsTruncated(%q) = %v, want %v", reason, got, want)
+		}
+	}
+	if IsTruncated(nil) {
+		t.Error("Expected nil metadata not to be truncated")
+	}
+}
+
+func TestGenerateContinued(t *testing.T) {
+	client := &scriptedClient{
+		responses: []string{"Chapter one ends here. The hero", "The hero walked into the dark", "forest and was never seen again."},
+		reasons:   []string{"length", "length", "stop"},
+	}
+	text, md, err := GenerateContinued(context.Background(), client, "Write a story.")
+	if err != nil {
+		t.Fatalf("GenerateContinued failed: %v", err)
+	}
+	want := "Chapter one ends here. The hero walked into the dark forest and was never seen again."
+	if text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+	if md.Continuations != 2 || md.FinishReason != "stop" || md.Usage.CompletionTokens != 300 || md.Usage.PromptTokens != 30 {
+		t.Errorf("Unexpected metadata: %+v", md)
+	}
+	if md.EstimatedCostUSD < 0.0299 || md.EstimatedCostUSD > 0.0301 {
+		t.Errorf("Expected the summed cost, got %f", md.EstimatedCostUSD)
+	}
+	if len(client.prompts) != 3 || !strings.Contains(client.prompts[1], "Write a story.") || !strings.Contains(client.prompts[1], "The hero") {
+		t.Errorf("Expected follow-ups to carry the prompt and the text so far, got %q", client.prompts)
+	}
+}
+
+func TestGenerateContinued_Cap(t *testing.T) {
+	client := &scriptedClient{
+		responses: []string{"a", "b", "c"},
+		reasons:   []string{"length", "length", "length"},
+	}
+	prompts := 0
+	text, md, err := GenerateContinued(context.Background(), client, "go", WithMaxContinuations(1),
+		WithContinuationPrompt(func(prompt, soFar string) string {
+			prompts++
+			return "continue: " + soFar
+		}))
+	if err != nil {
+		t.Fatalf("GenerateContinued failed: %v", err)
+	}
+	if text != "a b" || md.Continuations != 1 || !IsTruncated(md) || prompts != 1 || client.prompts[1] != "continue: a" {
+		t.Errorf("Expected one continuation and a truncated result, got %q, %+v", text, md)
+	}
+}
+
+func TestGenerateContinued_Errors(t *testing.T) {
+	genErr := errors.New("provider down")
+	first := &scriptedClient{errs: []error{genErr}}
+	if _, _, err := GenerateContinued(context.Background(), first, "go"); !errors.Is(err, genErr) {
+		t.Errorf("Expected the first request's error, got: %v", err)
+	}
+
+	later := &scriptedClient{responses: []string{"partial"}, reasons: []string{"length"}, errs: []error{nil, genErr}}
+	text, md, err := GenerateContinued(context.Background(), later, "go")
+	if !errors.Is(err, genErr) || text != "partial" || md == nil {
+		t.Errorf("Expected the text so far with the error, got %q, %v", text, err)
+	}
+
+	// Clients without metadata are never seen as truncated
+	text, md, err = GenerateContinued(context.Background(), &stubClient{response: "done"}, "go")
+	if err != nil || text != "done" || md.Continuations != 0 {
+		t.Errorf("Expected a single request, got %q, %+v, %v", text, md, err)
+	}
+}
+
+func TestStitch(t *testing.T) {
+	tests := []struct{ before, next, want string }{
+		{"The quick brown", "fox jumps", "The quick brown fox jumps"},
+		{"The quick brown", "quick brown fox", "The quick brown fox"},
+		{"End of sentence.", "Next one", "End of sentence. Next one"},
+		{"A list:", "\n- item", "A list:\n- item"},
+		{"word", ", then", "word, then"},
+		{"aaa", "ab", "aaa ab"},
+	}
+	for _, tt := range tests {
+		if got := stitch(tt.before, tt.next); got != tt.want {
+			t.Errorf("stitch(%q, %q) = %q, want %q", tt.before, tt.next, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package xollm
+
+import (
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// CredentialSetter is implemented by clients whose API key can be rotated
+// while they are in use. The Gemini and Groq clients implement it.
+type CredentialSetter = llm.CredentialSetter
+
+// SetCredentials replaces the API key of client without recreating it, so
+// in-flight requests are not dropped. It fails for clients that don't
+// implement CredentialSetter, such as Ollama, which has no API key.
+func SetCredentials(client Client, apiKey string) error {
+	cs, ok := client.(CredentialSetter)
+	if !ok {
+		return fmt.Errorf("%s client does not support credential rotation", client.ProviderName())
+	}
+	return cs.SetCredentials(apiKey)
+}
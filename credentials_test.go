@@ -0,0 +1,21 @@
+package xollm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetCredentials(t *testing.T) {
+	client := &credentialClient{}
+	if err := SetCredentials(client, "new-key"); err != nil {
+		t.Fatalf("SetCredentials failed: %v", err)
+	}
+	if len(client.keys) != 1 || client.keys[0] != "new-key" {
+		t.Errorf("Expected the key to be passed on, got %v", client.keys)
+	}
+
+	err := SetCredentials(&stubClient{}, "new-key")
+	if err == nil || !strings.Contains(err.Error(), "does not support credential rotation") {
+		t.Errorf("Expected an unsupported error, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+// Package dataset turns prompt/response pairs into fine-tuning datasets.
+//
+// A Writer encodes Examples as JSON Lines in the chat format of a
+// fine-tuning API, one example per line:
+//
+//	FormatOpenAI:    {"messages": [{"role": "system", ...}, {"role": "user", ...}, {"role": "assistant", ...}]}
+//	FormatAnthropic: {"system": "...", "messages": [{"role": "user", ...}, {"role": "assistant", ...}]}
+//
+// Examples carry request tags and quality flags, which can be kept in a
+// "metadata" field for curating the dataset before upload, and used to
+// decide what is written at all. Wrap records the traffic of a client:
+//
+//	w, err := dataset.Create("train.jsonl", dataset.FormatOpenAI)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer w.Close()
+//
+//	client = dataset.Wrap(client, w)
+//	ctx = dataset.WithFlags(ctx, "reviewed")
+//	text, err := client.Generate(ctx, prompt)
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Format is a fine-tuning JSONL format.
+type Format int
+
+const (
+	// FormatOpenAI is OpenAI's chat fine-tuning format, also accepted by
+	// Groq-hosted and most open-source training tools.
+	FormatOpenAI Format = iota
+	// FormatAnthropic is Anthropic's format, with the system prompt in a
+	// top-level field.
+	FormatAnthropic
+)
+
+// String returns the format's name.
+func (f Format) String() string {
+	switch f {
+	case FormatOpenAI:
+		return "openai"
+	case FormatAnthropic:
+		return "anthropic"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// ParseFormat returns the format named "openai" or "anthropic".
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "openai":
+		return FormatOpenAI, nil
+	case "anthropic":
+		return FormatAnthropic, nil
+	default:
+		return 0, fmt.Errorf("unknown dataset format %q", name)
+	}
+}
+
+// Example is one prompt/response pair.
+type Example struct {
+	// System is the system prompt, if any.
+	System string
+	// Prompt is the user prompt.
+	Prompt string
+	// Response is the model's response.
+	Response string
+	// Provider and Model identify what generated the response.
+	Provider string
+	Model    string
+	// Tags are the request's tags (see xollm.WithTags).
+	Tags map[string]string
+	// Flags are quality flags, such as "reviewed" or "thumbs_down".
+	Flags []string
+	// Time is when the response was generated.
+	Time time.Time
+}
+
+// HasFlag reports whether the example is flagged with flag.
+func (e Example) HasFlag(flag string) bool {
+	for _, f := range e.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// message is a chat message of a training line.
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// metadata is the optional metadata field of a training line.
+type metadata struct {
+	Provider string            `json:"provider,omitempty"`
+	Model    string            `json:"model,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Flags    []string          `json:"flags,omitempty"`
+	Time     *time.Time        `json:"time,omitempty"`
+}
+
+// line is a training line in either format.
+type line struct {
+	System   string    `json:"system,omitempty"`
+	Messages []message `json:"messages"`
+	Metadata *metadata `json:"metadata,omitempty"`
+}
+
+// Writer writes examples as JSON Lines. It is safe for concurrent use.
+type Writer struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+	format Format
+
+	// IncludeMetadata adds a "metadata" field with the provider, model,
+	// tags, flags and time to every line. Training APIs may reject
+	// unknown fields, so strip it before uploading.
+	IncludeMetadata bool
+	// Filter, if set, decides which examples are written, e.g. to skip
+	// those flagged "thumbs_down".
+	Filter func(Example) bool
+}
+
+// NewWriter returns a Writer writing examples in format to w.
+func NewWriter(w io.Writer, format Format) *Writer {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &Writer{enc: enc, format: format}
+}
+
+// Create returns a Writer appending examples in format to the file at
+// path, creating it if needed. Close closes the file.
+func Create(path string, format Format) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset file: %w", err)
+	}
+	w := NewWriter(f, format)
+	w.closer = f
+	return w, nil
+}
+
+// Write writes ex as one line, unless Filter rejects it. Examples without
+// a prompt or response are an error.
+func (w *Writer) Write(ex Example) error {
+	if ex.Prompt == "" || ex.Response == "" {
+		return fmt.Errorf("dataset example needs a prompt and a response")
+	}
+	if w.Filter != nil && !w.Filter(ex) {
+		return nil
+	}
+
+	var l line
+	switch w.format {
+	case FormatOpenAI:
+		if ex.System != "" {
+			l.Messages = append(l.Messages, message{Role: "system", Content: ex.System})
+		}
+	case FormatAnthropic:
+		l.System = ex.System
+	default:
+		return fmt.Errorf("unknown dataset format %v", w.format)
+	}
+	l.Messages = append(l.Messages,
+		message{Role: "user", Content: ex.Prompt},
+		message{Role: "assistant", Content: ex.Response},
+	)
+	if w.IncludeMetadata {
+		l.Metadata = &metadata{Provider: ex.Provider, Model: ex.Model, Tags: ex.Tags, Flags: ex.Flags}
+		if !ex.Time.IsZero() {
+			l.Metadata.Time = &ex.Time
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(l); err != nil {
+		return fmt.Errorf("failed to write dataset example: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file of a Writer returned by Create. It does nothing
+// for Writers returned by NewWriter.
+func (w *Writer) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
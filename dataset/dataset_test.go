@@ -0,0 +1,104 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter_OpenAI(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatOpenAI)
+	if err := w.Write(Example{System: "Be brief.", Prompt: "Hi <there>", Response: "Hello"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := `{"messages":[{"role":"system","content":"Be brief."},{"role":"user","content":"Hi <there>"},{"role":"assistant","content":"Hello"}]}` + "\n"
+	if buf.String() != want {
+		t.Errorf("Expected %s, got %s", want, buf.String())
+	}
+}
+
+func TestWriter_Anthropic(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatAnthropic)
+	w.Write(Example{System: "Be brief.", Prompt: "Hi", Response: "Hello"})
+	w.Write(Example{Prompt: "Bye", Response: "Goodbye"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`{"system":"Be brief.","messages":[{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello"}]}`,
+		`{"messages":[{"role":"user","content":"Bye"},{"role":"assistant","content":"Goodbye"}]}`,
+	}
+	if len(lines) != 2 || lines[0] != want[0] || lines[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, lines)
+	}
+}
+
+func TestWriter_MetadataAndFilter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatOpenAI)
+	w.IncludeMetadata = true
+	w.Filter = func(ex Example) bool { return !ex.HasFlag("thumbs_down") }
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	w.Write(Example{Prompt: "a", Response: "b", Provider: "groq", Model: "m", Tags: map[string]string{"team": "x"}, Flags: []string{"reviewed"}, Time: at})
+	w.Write(Example{Prompt: "c", Response: "d", Flags: []string{"thumbs_down"}})
+
+	var got struct {
+		Metadata metadata `json:"metadata"`
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected the flagged example to be filtered, got %d lines", len(lines))
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	md := got.Metadata
+	if md.Provider != "groq" || md.Model != "m" || md.Tags["team"] != "x" || len(md.Flags) != 1 || !md.Time.Equal(at) {
+		t.Errorf("Unexpected metadata: %+v", md)
+	}
+}
+
+func TestWriter_Validation(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{}, FormatOpenAI)
+	if err := w.Write(Example{Prompt: "a"}); err == nil {
+		t.Error("Expected an error for an example without a response")
+	}
+	if err := NewWriter(&bytes.Buffer{}, Format(9)).Write(Example{Prompt: "a", Response: "b"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestCreate_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "train.jsonl")
+	for i := 0; i < 2; i++ {
+		w, err := Create(path, FormatOpenAI)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		w.Write(Example{Prompt: "p", Response: "r"})
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Errorf("Expected 2 lines, got %d", n)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range []Format{FormatOpenAI, FormatAnthropic} {
+		if got, err := ParseFormat(f.String()); err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %v, %v", f.String(), got, err)
+		}
+	}
+	if _, err := ParseFormat("csv"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
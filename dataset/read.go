@@ -0,0 +1,78 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxLineBytes is the longest line Read accepts.
+const maxLineBytes = 16 << 20
+
+// Read decodes the examples of a JSONL file written in either format,
+// including the metadata of files written with IncludeMetadata. Blank lines
+// are skipped. For conversations with several turns, the last user message
+// and the final assistant message form the example.
+func Read(r io.Reader) ([]Example, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	var examples []Example
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var l line
+		if err := json.Unmarshal([]byte(text), &l); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON: %w", n, err)
+		}
+		ex, err := l.example()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		examples = append(examples, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	return examples, nil
+}
+
+// ReadFile reads the examples of the JSONL file at path.
+func ReadFile(path string) ([]Example, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset file: %w", err)
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// example converts a decoded training line back to an Example.
+func (l line) example() (Example, error) {
+	ex := Example{System: l.System}
+	for _, m := range l.Messages {
+		switch m.Role {
+		case "system":
+			ex.System = m.Content
+		case "user":
+			ex.Prompt = m.Content
+		case "assistant":
+			ex.Response = m.Content
+		}
+	}
+	if ex.Prompt == "" || ex.Response == "" {
+		return Example{}, fmt.Errorf("example needs a user and an assistant message")
+	}
+	if md := l.Metadata; md != nil {
+		ex.Provider, ex.Model, ex.Tags, ex.Flags = md.Provider, md.Model, md.Tags, md.Flags
+		if md.Time != nil {
+			ex.Time = *md.Time
+		}
+	}
+	return ex, nil
+}
@@ -0,0 +1,51 @@
+package dataset
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRead_RoundTrip(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	written := Example{System: "Be brief.", Prompt: "Hi", Response: "Hello", Provider: "groq", Model: "m", Tags: map[string]string{"a": "b"}, Flags: []string{"reviewed"}, Time: at}
+
+	for _, format := range []Format{FormatOpenAI, FormatAnthropic} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, format)
+		w.IncludeMetadata = true
+		w.Write(written)
+		buf.WriteString("\n")
+
+		examples, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("%v: Read failed: %v", format, err)
+		}
+		if len(examples) != 1 {
+			t.Fatalf("%v: expected 1 example, got %d", format, len(examples))
+		}
+		got := examples[0]
+		if got.System != written.System || got.Prompt != written.Prompt || got.Response != written.Response ||
+			got.Provider != "groq" || got.Model != "m" || got.Tags["a"] != "b" || !got.HasFlag("reviewed") || !got.Time.Equal(at) {
+			t.Errorf("%v: expected %+v, got %+v", format, written, got)
+		}
+	}
+}
+
+func TestRead_MultiTurn(t *testing.T) {
+	input := `{"messages":[{"role":"user","content":"first"},{"role":"assistant","content":"one"},{"role":"user","content":"second"},{"role":"assistant","content":"two"}]}`
+	examples, err := Read(strings.NewReader(input))
+	if err != nil || len(examples) != 1 || examples[0].Prompt != "second" || examples[0].Response != "two" {
+		t.Errorf("Expected the last turn, got %+v, %v", examples, err)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	if _, err := Read(strings.NewReader("{not json}\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected an invalid JSON error, got %v", err)
+	}
+	if _, err := Read(strings.NewReader("\n" + `{"messages":[{"role":"user","content":"hi"}]}`)); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected a missing response error, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package dataset
+
+import (
+	"context"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+type flagsKey struct{}
+
+// WithFlags returns a copy of ctx whose recorded examples are flagged with
+// flags, in addition to any flags already carried by ctx.
+func WithFlags(ctx context.Context, flags ...string) context.Context {
+	all := append(append([]string(nil), flagsFromContext(ctx)...), flags...)
+	return context.WithValue(ctx, flagsKey{}, all)
+}
+
+// flagsFromContext returns the flags carried by ctx.
+func flagsFromContext(ctx context.Context) []string {
+	flags, _ := ctx.Value(flagsKey{}).([]string)
+	return flags
+}
+
+// Client is an xollm.Client that records its successful generations to a
+// Writer.
+type Client struct {
+	client xollm.Client
+	w      *Writer
+	system string
+}
+
+// Wrap returns a client that records every successful generation of
+// client to w, with the request's tags and flags. Recording failures are
+// logged and don't fail the request.
+func Wrap(client xollm.Client, w *Writer) *Client {
+	return &Client{client: client, w: w}
+}
+
+// SetSystemPrompt sets the system prompt recorded with the examples, which
+// should match the one configured for the wrapped client.
+func (c *Client) SetSystemPrompt(system string) {
+	c.system = system
+}
+
+// Generate sends prompt to the wrapped client and records the response.
+func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
+	text, _, err := c.GenerateWithMetadata(ctx, prompt)
+	return text, err
+}
+
+// GenerateWithMetadata implements xollm.MetadataGenerator, recording the
+// model reported in the metadata with the example.
+func (c *Client) GenerateWithMetadata(ctx context.Context, prompt string) (string, *xollm.ResponseMetadata, error) {
+	text, md, err := xollm.GenerateWithMetadata(ctx, c.client, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	ex := Example{
+		System:   c.system,
+		Prompt:   prompt,
+		Response: text,
+		Provider: c.client.ProviderName(),
+		Model:    md.Model,
+		Tags:     xollm.TagsFromContext(ctx),
+		Flags:    flagsFromContext(ctx),
+		Time:     time.Now(),
+	}
+	if err := c.w.Write(ex); err != nil {
+		xollm.Logger(ctx).Error("dataset: failed to record example", "error", err)
+	}
+	return text, md, nil
+}
+
+// ProviderName returns the wrapped client's provider name.
+func (c *Client) ProviderName() string {
+	return c.client.ProviderName()
+}
+
+// Close closes the wrapped client. The Writer is left open.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
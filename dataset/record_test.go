@@ -0,0 +1,70 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	response string
+	err      error
+	closed   bool
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return m.response, m.err
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestWrap_Records(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, FormatOpenAI)
+	w.IncludeMetadata = true
+	client := Wrap(&mockClient{response: "Paris"}, w)
+	client.SetSystemPrompt("Answer in one word.")
+
+	ctx := xollm.WithTags(context.Background(), map[string]string{"feature": "geo"})
+	ctx = WithFlags(WithFlags(ctx, "reviewed"), "golden")
+	text, err := client.Generate(ctx, "Capital of France?")
+	if err != nil || text != "Paris" {
+		t.Fatalf("Expected \"Paris\", got %q, %v", text, err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{`"content":"Answer in one word."`, `"content":"Capital of France?"`, `"content":"Paris"`, `"provider":"mock"`, `"feature":"geo"`, `"flags":["reviewed","golden"]`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected the recorded line to contain %s, got %s", want, out)
+		}
+	}
+}
+
+func TestWrap_SkipsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	inner := &mockClient{err: errors.New("boom")}
+	client := Wrap(inner, NewWriter(&buf, FormatOpenAI))
+
+	if _, err := client.Generate(context.Background(), "hi"); err == nil {
+		t.Error("Expected the client error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing to be recorded, got %s", buf.String())
+	}
+	if client.ProviderName() != "mock" {
+		t.Errorf("Expected the wrapped provider name, got %q", client.ProviderName())
+	}
+	if client.Close(); !inner.closed {
+		t.Error("Expected Close to close the wrapped client")
+	}
+}
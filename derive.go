@@ -0,0 +1,60 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xostack/xollm/gemini"
+	"github.com/xostack/xollm/groq"
+	"github.com/xostack/xollm/llm"
+	"github.com/xostack/xollm/ollama"
+)
+
+// DeriveModel returns a client like client that uses model instead, sharing
+// its transport, credentials and rate limit state rather than creating a
+// new client. This makes per-request model switching cheap, notably for
+// Gemini, whose clients are expensive to initialize. "" keeps the model.
+//
+//	fast, err := xollm.DeriveModel(client, "gemini-2.0-flash-lite")
+//
+// It fails for clients other than those of the gemini, groq and ollama
+// packages; their WithModel methods return the concrete client type.
+func DeriveModel(client Client, model string) (Client, error) {
+	switch c := client.(type) {
+	case *gemini.Client:
+		return c.WithModel(model), nil
+	case *groq.Client:
+		return c.WithModel(model), nil
+	case *ollama.Client:
+		return c.WithModel(model), nil
+	}
+	return nil, fmt.Errorf("%s client does not support derived clients", client.ProviderName())
+}
+
+// DeriveTimeout returns a client like client whose requests time out after
+// d, sharing its transport, credentials and rate limit state. A d of 0 or
+// less leaves only the deadline of each request's context. Like
+// DeriveModel, it supports the clients of the provider packages only; to
+// change the timeout of a single call, use WithRequestTimeout.
+func DeriveTimeout(client Client, d time.Duration) (Client, error) {
+	switch c := client.(type) {
+	case *gemini.Client:
+		return c.WithTimeout(d), nil
+	case *groq.Client:
+		return c.WithTimeout(d), nil
+	case *ollama.Client:
+		return c.WithTimeout(d), nil
+	}
+	return nil, fmt.Errorf("%s client does not support derived clients", client.ProviderName())
+}
+
+// WithRequestTimeout returns a copy of ctx whose requests time out after d
+// instead of after the client's request timeout, which may be longer. A d
+// of 0 or less leaves only ctx's deadline. See llm.WithRequestTimeout.
+//
+//	label, err := client.Generate(xollm.WithRequestTimeout(ctx, 5*time.Second), classifyPrompt)
+//	essay, err := client.Generate(xollm.WithRequestTimeout(ctx, 5*time.Minute), essayPrompt)
+func WithRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return llm.WithRequestTimeout(ctx, d)
+}
@@ -0,0 +1,54 @@
+package xollm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm/groq"
+	"github.com/xostack/xollm/llm"
+	"github.com/xostack/xollm/ollama"
+)
+
+func TestDeriveModel(t *testing.T) {
+	client, err := ollama.NewClient(context.Background(), "http://localhost:11434", "gemma:2b", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	derived, err := DeriveModel(client, "llama3.1")
+	if err != nil {
+		t.Fatalf("DeriveModel failed: %v", err)
+	}
+	if _, ok := derived.(*ollama.Client); !ok || derived == Client(client) {
+		t.Errorf("Expected a new Ollama client, got %T", derived)
+	}
+
+	if _, err := DeriveModel(&stubClient{}, "x"); err == nil {
+		t.Error("Expected an error for a client that can't be derived")
+	}
+}
+
+func TestDeriveTimeout(t *testing.T) {
+	client, err := groq.NewClient(context.Background(), "test-key", "", 60, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	derived, err := DeriveTimeout(client, 5*time.Second)
+	if err != nil {
+		t.Fatalf("DeriveTimeout failed: %v", err)
+	}
+	if _, ok := derived.(*groq.Client); !ok {
+		t.Errorf("Expected a Groq client, got %T", derived)
+	}
+
+	if _, err := DeriveTimeout(&stubClient{}, time.Second); err == nil {
+		t.Error("Expected an error for a client that can't be derived")
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	ctx := WithRequestTimeout(context.Background(), 5*time.Second)
+	if d, ok := llm.RequestTimeoutFromContext(ctx); !ok || d != 5*time.Second {
+		t.Errorf("Expected a 5s request timeout, got %v (set: %v)", d, ok)
+	}
+}
@@ -0,0 +1,45 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// RenderedRequest is a provider request as it would have been sent, with
+// credentials redacted. See llm.RenderedRequest.
+type RenderedRequest = llm.RenderedRequest
+
+// DryRunError carries the request a provider would have sent in dry-run
+// mode. It matches ErrDryRun.
+type DryRunError = llm.DryRunError
+
+// ErrDryRun is matched by the errors providers return in dry-run mode.
+var ErrDryRun = llm.ErrDryRun
+
+// WithDryRun returns a copy of ctx in which the bundled providers render
+// their requests and fail with a *DryRunError instead of sending them.
+func WithDryRun(ctx context.Context) context.Context {
+	return llm.WithDryRun(ctx)
+}
+
+// DryRun returns the request client would send for prompt, without
+// sending it, to debug prompt construction, templates and the mapping of
+// options to provider parameters. Middleware such as quotas or moderation
+// wrapped around client still runs.
+//
+// Clients that don't support dry runs fail, but may already have sent the
+// request by then; all bundled providers support it.
+func DryRun(ctx context.Context, client Client, prompt string) (*RenderedRequest, error) {
+	_, err := client.Generate(llm.WithDryRun(ctx), prompt)
+	var dryRun *DryRunError
+	if errors.As(err, &dryRun) {
+		return dryRun.Request, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%s client does not support dry runs", client.ProviderName())
+}
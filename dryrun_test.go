@@ -0,0 +1,41 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// dryRunClient renders its request in dry-run mode
+type dryRunClient struct {
+	stubClient
+}
+
+func (c *dryRunClient) Generate(ctx context.Context, prompt string) (string, error) {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://example.com", strings.NewReader(prompt))
+	if err := llm.InterceptDryRun("stub", req); err != nil {
+		return "", err
+	}
+	return "sent", nil
+}
+
+func TestDryRun(t *testing.T) {
+	req, err := DryRun(context.Background(), &dryRunClient{}, "hello")
+	if err != nil || string(req.Body) != "hello" {
+		t.Errorf("Expected the rendered request, got %+v, %v", req, err)
+	}
+
+	_, err = DryRun(context.Background(), &stubClient{response: "sent"}, "hello")
+	if err == nil || !strings.Contains(err.Error(), "does not support dry runs") {
+		t.Errorf("Expected an unsupported error, got %v", err)
+	}
+
+	failure := errors.New("boom")
+	if _, err := DryRun(context.Background(), &stubClient{err: failure}, "hello"); !errors.Is(err, failure) {
+		t.Errorf("Expected the client's error, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// Embedder is implemented by clients that compute text embeddings. The
+// Ollama and Gemini clients implement it. See llm.Embedder.
+type Embedder = llm.Embedder
+
+// WithEmbeddingModel sets the model that computes the client's embeddings.
+// Ollama clients default to their generation model, which works but is
+// rarely what you want; Gemini clients default to text-embedding-004.
+func WithEmbeddingModel(model string) ClientOption {
+	return llm.WithEmbeddingModel(model)
+}
+
+// Embed returns one embedding vector per text, in order. It fails for
+// clients that don't implement Embedder, such as Groq.
+//
+//	vectors, err := xollm.Embed(ctx, client, []string{"first document", "second document"})
+func Embed(ctx context.Context, client Client, texts []string) ([][]float32, error) {
+	e, ok := client.(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("%s client does not support embeddings", client.ProviderName())
+	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	return e.Embed(ctx, texts)
+}
@@ -0,0 +1,44 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// embeddingClient embeds each text as its length.
+type embeddingClient struct {
+	stubClient
+	calls int
+}
+
+func (c *embeddingClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	c.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func TestEmbed(t *testing.T) {
+	client := &embeddingClient{}
+	vectors, err := Embed(context.Background(), client, []string{"a", "abc"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 3 {
+		t.Errorf("Unexpected embeddings: %v", vectors)
+	}
+
+	if vectors, err := Embed(context.Background(), client, nil); err != nil || vectors != nil || client.calls != 1 {
+		t.Errorf("Expected no request for no texts, got %v, %v after %d calls", vectors, err, client.calls)
+	}
+}
+
+func TestEmbed_Unsupported(t *testing.T) {
+	_, err := Embed(context.Background(), &stubClient{}, []string{"text"})
+	if err == nil || !strings.Contains(err.Error(), "does not support embeddings") {
+		t.Errorf("Expected an unsupported error, got: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package encryption encrypts conversations and sessions at rest, so chat
+// transcripts persisted to disk or a database are not readable in
+// plaintext.
+//
+// A Cipher seals records with AES-256-GCM, which also detects tampering.
+// The key usually comes from the configuration's storage_encryption_key,
+// which may be a secret reference resolved from the environment, a file,
+// Vault or a keyring source (see config.RegisterSecretsSource):
+//
+//	cipher, err := encryption.FromConfig(cfg)
+//	if err != nil {
+//		return err
+//	}
+//	sealed, err := cipher.Seal(transcript, []byte(conversationID))
+//	...
+//	transcript, err = cipher.Open(sealed, []byte(conversationID))
+//
+// Generate a key with NewKey, e.g. once at deployment:
+//
+//	key, _ := encryption.NewKey()
+//	fmt.Println(hex.EncodeToString(key))
+//
+// Sealed records start with a short header, so they can be recognised
+// with IsSealed. Records that aren't sealed are rejected, unless
+// SetAllowPlaintext enables reading those written before encryption was
+// enabled while they are migrated. To rotate keys, create the Cipher with
+// the new key first and the old ones after: records are opened with
+// whichever key sealed them, and sealed with the first.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xostack/xollm/config"
+)
+
+// KeySize is the size of an AES-256 key in bytes.
+const KeySize = 32
+
+// header starts every sealed record, identifying the format version.
+var header = []byte("xoe1")
+
+// ErrDecrypt is returned by Open when a record can't be decrypted with any
+// of the Cipher's keys: the key is wrong, or the record was altered.
+var ErrDecrypt = errors.New("failed to decrypt record")
+
+// Cipher seals and opens records with AES-256-GCM. It is safe for
+// concurrent use. A nil *Cipher leaves records in plaintext, so stores can
+// use the result of FromConfig whether or not a key is configured.
+type Cipher struct {
+	aeads          []cipher.AEAD // the sealing key's first
+	allowPlaintext bool
+}
+
+// New returns a cipher sealing with key and opening with key or any of
+// oldKeys. Keys must be KeySize bytes.
+func New(key []byte, oldKeys ...[]byte) (*Cipher, error) {
+	c := &Cipher{}
+	for i, k := range append([][]byte{key}, oldKeys...) {
+		if len(k) != KeySize {
+			return nil, fmt.Errorf("encryption key %d is %d bytes, must be %d", i+1, len(k), KeySize)
+		}
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+		c.aeads = append(c.aeads, aead)
+	}
+	return c, nil
+}
+
+// FromConfig returns a cipher with the configuration's
+// StorageEncryptionKey, or nil if none is set, in which case stores write
+// plaintext. The key must already be resolved, as Load does.
+func FromConfig(cfg config.Config) (*Cipher, error) {
+	if cfg.StorageEncryptionKey == "" {
+		return nil, nil
+	}
+	key, err := ParseKey(cfg.StorageEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage_encryption_key: %w", err)
+	}
+	return New(key)
+}
+
+// SetAllowPlaintext makes Open return records that aren't sealed as they
+// are instead of failing, so records written before encryption was enabled
+// stay readable; stores seal them the next time they are written. Enable
+// it only while migrating: otherwise anyone able to write to the store can
+// plant plaintext records that are read as if they had been sealed. Set it
+// before the cipher is used.
+func (c *Cipher) SetAllowPlaintext(allow bool) {
+	c.allowPlaintext = allow
+}
+
+// NewKey returns a random key.
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// ParseKey decodes a KeySize-byte key written in hex or in standard or
+// URL-safe base64, with or without padding.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := hex.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(s); err == nil && len(key) == KeySize {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key must be %d bytes encoded in hex or base64", KeySize)
+}
+
+// Seal encrypts plaintext. associatedData, such as the record's ID, is
+// authenticated but not stored: Open needs the same value, so a sealed
+// record can't be passed off as another one. It may be nil. A nil cipher
+// returns plaintext unchanged.
+func (c *Cipher) Seal(plaintext, associatedData []byte) ([]byte, error) {
+	if c == nil {
+		return plaintext, nil
+	}
+	aead := c.aeads[0]
+	out := make([]byte, len(header)+aead.NonceSize(), len(header)+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	copy(out, header)
+	nonce := out[len(header):]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(out, nonce, plaintext, associatedData), nil
+}
+
+// Open decrypts a record sealed by Seal with the same associatedData. It
+// fails with ErrDecrypt if no key of the cipher sealed it, it was altered or
+// it isn't sealed, unless SetAllowPlaintext allows plaintext records. A nil
+// cipher returns plaintext records as they are.
+func (c *Cipher) Open(sealed, associatedData []byte) ([]byte, error) {
+	if c == nil {
+		if IsSealed(sealed) {
+			return nil, fmt.Errorf("%w: record is encrypted but no key is configured", ErrDecrypt)
+		}
+		return sealed, nil
+	}
+	if !IsSealed(sealed) {
+		if c.allowPlaintext {
+			return sealed, nil
+		}
+		return nil, fmt.Errorf("%w: not a sealed record", ErrDecrypt)
+	}
+	body := sealed[len(header):]
+	for _, aead := range c.aeads {
+		if len(body) < aead.NonceSize()+aead.Overhead() {
+			return nil, fmt.Errorf("%w: record truncated", ErrDecrypt)
+		}
+		nonce, ciphertext := body[:aead.NonceSize()], body[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrDecrypt
+}
+
+// IsSealed reports whether data looks like a record sealed by a Cipher,
+// rather than plaintext written without encryption.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
@@ -0,0 +1,135 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/xostack/xollm/config"
+)
+
+func newTestCipher(t *testing.T) (*Cipher, []byte) {
+	t.Helper()
+	key, err := NewKey()
+	if err != nil {
+		t.Fatalf("NewKey failed: %v", err)
+	}
+	c, err := New(key)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return c, key
+}
+
+func TestCipher_SealOpen(t *testing.T) {
+	c, _ := newTestCipher(t)
+	transcript := []byte(`[{"role":"user","content":"my account number is 1234"}]`)
+
+	sealed, err := c.Seal(transcript, []byte("conv-1"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, []byte("account")) {
+		t.Error("Expected the sealed record to hide the plaintext")
+	}
+	again, _ := c.Seal(transcript, []byte("conv-1"))
+	if bytes.Equal(sealed, again) {
+		t.Error("Expected a fresh nonce for every seal")
+	}
+
+	opened, err := c.Open(sealed, []byte("conv-1"))
+	if err != nil || !bytes.Equal(opened, transcript) {
+		t.Fatalf("Expected the transcript back, got %q, %v", opened, err)
+	}
+	if _, err := c.Open(sealed, []byte("conv-2")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected other associated data to fail, got %v", err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := c.Open(sealed, []byte("conv-1")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected a tampered record to fail, got %v", err)
+	}
+	if _, err := c.Open([]byte("xoe1short"), nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected a truncated record to fail, got %v", err)
+	}
+	if _, err := c.Open(transcript, nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected plaintext to fail, got %v", err)
+	}
+}
+
+func TestCipher_KeyRotation(t *testing.T) {
+	old, oldKey := newTestCipher(t)
+	sealed, _ := old.Seal([]byte("history"), nil)
+
+	newKey, _ := NewKey()
+	rotated, err := New(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if opened, err := rotated.Open(sealed, nil); err != nil || string(opened) != "history" {
+		t.Errorf("Expected an old record to open with the old key, got %q, %v", opened, err)
+	}
+	resealed, _ := rotated.Seal([]byte("history"), nil)
+	if _, err := old.Open(resealed, nil); !errors.Is(err, ErrDecrypt) {
+		t.Error("Expected new records to be sealed with the new key")
+	}
+}
+
+func TestCipher_Nil(t *testing.T) {
+	var c *Cipher
+	sealed, err := c.Seal([]byte("plain"), nil)
+	if err != nil || string(sealed) != "plain" {
+		t.Errorf("Expected a nil cipher to leave plaintext, got %q, %v", sealed, err)
+	}
+	if opened, err := c.Open([]byte("plain"), nil); err != nil || string(opened) != "plain" {
+		t.Errorf("Expected plaintext back, got %q, %v", opened, err)
+	}
+	real, _ := newTestCipher(t)
+	encrypted, _ := real.Seal([]byte("secret"), nil)
+	if _, err := c.Open(encrypted, nil); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected an encrypted record to fail without a key, got %v", err)
+	}
+}
+
+func TestNew_KeySize(t *testing.T) {
+	if _, err := New(make([]byte, 16)); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+	if _, err := New(make([]byte, KeySize), make([]byte, 8)); err == nil {
+		t.Error("Expected an error for a short old key")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key, _ := NewKey()
+	for _, s := range []string{
+		hex.EncodeToString(key),
+		base64.StdEncoding.EncodeToString(key),
+		base64.RawURLEncoding.EncodeToString(key),
+		" " + hex.EncodeToString(key) + "\n",
+	} {
+		parsed, err := ParseKey(s)
+		if err != nil || !bytes.Equal(parsed, key) {
+			t.Errorf("ParseKey(%q) = %x, %v", s, parsed, err)
+		}
+	}
+	if _, err := ParseKey("too-short"); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	c, err := FromConfig(config.Config{})
+	if err != nil || c != nil {
+		t.Errorf("Expected no cipher without a key, got %v, %v", c, err)
+	}
+	key, _ := NewKey()
+	c, err = FromConfig(config.Config{StorageEncryptionKey: hex.EncodeToString(key)})
+	if err != nil || c == nil {
+		t.Errorf("Expected a cipher, got %v, %v", c, err)
+	}
+	if _, err := FromConfig(config.Config{StorageEncryptionKey: "env:UNRESOLVED"}); err == nil {
+		t.Error("Expected an error for an invalid key")
+	}
+}
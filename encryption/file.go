@@ -0,0 +1,55 @@
+package encryption
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xostack/xollm/config"
+)
+
+// WriteFile seals data and writes it to path, readable by the owner only.
+// The file is replaced atomically, so a crash never leaves a half-written
+// transcript. The file's base name is the associated data, so a sealed
+// file can't be swapped with another. A nil cipher writes plaintext.
+func (c *Cipher) WriteFile(path string, data []byte) error {
+	sealed, err := c.Seal(data, []byte(filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if err := tmp.Chmod(config.DefaultFilePerm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := tmp.Write(sealed); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadFile reads a file written by WriteFile and opens it. Files written
+// in plaintext, e.g. before encryption was enabled, fail with ErrDecrypt
+// unless SetAllowPlaintext allows them.
+func (c *Cipher) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := c.Open(data, []byte(filepath.Base(path)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plaintext, nil
+}
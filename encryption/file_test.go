@@ -0,0 +1,61 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCipher_WriteReadFile(t *testing.T) {
+	c, _ := newTestCipher(t)
+	path := filepath.Join(t.TempDir(), "conv-1.json")
+	transcript := []byte(`{"messages":["hello"]}`)
+
+	if err := c.WriteFile(path, transcript); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	if bytes.Contains(raw, []byte("hello")) {
+		t.Error("Expected the file to be encrypted")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected an owner-only file, got %v", info.Mode().Perm())
+	}
+	got, err := c.ReadFile(path)
+	if err != nil || !bytes.Equal(got, transcript) {
+		t.Fatalf("Expected the transcript back, got %q, %v", got, err)
+	}
+
+	// A sealed file renamed to another record's name doesn't open
+	other := filepath.Join(filepath.Dir(path), "conv-2.json")
+	os.Rename(path, other)
+	if _, err := c.ReadFile(other); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected a swapped file to fail, got %v", err)
+	}
+}
+
+func TestCipher_ReadPlaintextFile(t *testing.T) {
+	c, _ := newTestCipher(t)
+	path := filepath.Join(t.TempDir(), "legacy.json")
+	os.WriteFile(path, []byte("legacy"), 0600)
+	if _, err := c.ReadFile(path); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected a plaintext file to be rejected, got %v", err)
+	}
+	c.SetAllowPlaintext(true)
+	if got, err := c.ReadFile(path); err != nil || string(got) != "legacy" {
+		t.Errorf("Expected a plaintext file to be readable while migrating, got %q, %v", got, err)
+	}
+	if _, err := c.ReadFile(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+
+	var none *Cipher
+	if err := none.WriteFile(path, []byte("plain")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if raw, _ := os.ReadFile(path); string(raw) != "plain" {
+		t.Errorf("Expected a nil cipher to write plaintext, got %q", raw)
+	}
+}
@@ -0,0 +1,186 @@
+// Package ensemble queries several LLM clients with the same prompt and
+// combines their answers into one.
+//
+// An Ensemble sends the prompt to every member concurrently and hands the
+// successful answers to a Strategy, which picks one: MajorityVote for
+// classifications and other short answers where agreement signals
+// correctness, or Judge, which asks a judge model to choose among
+// free-text answers. The Result holds the chosen answer and every
+// candidate, so callers can log or display the alternatives.
+//
+// Example usage:
+//
+//	ens, err := ensemble.New(ensemble.MajorityVote(nil), geminiClient, groqClient, ollamaClient)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer ens.Close()
+//
+//	result, err := ens.Run(ctx, "Classify the sentiment as positive, negative or neutral: ...")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%s (%d of %d votes)\n", result.Answer, result.Votes, len(result.Candidates))
+//
+// An Ensemble is itself an xollm.Client, so it can be used wherever a
+// single client is expected.
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// ProviderName is the provider name reported by an Ensemble.
+const ProviderName = "ensemble"
+
+// Candidate is one member's answer to the prompt.
+type Candidate struct {
+	Provider string        // Name of the member's provider (e.g., "ollama", "gemini")
+	Response string        // Generated response text
+	Duration time.Duration // Time taken to generate the response
+	Error    error         // Error encountered during generation, if any
+}
+
+// Result is the outcome of an ensemble run.
+type Result struct {
+	// Answer is the chosen response.
+	Answer string
+	// Chosen is the index in Candidates of the chosen response.
+	Chosen int
+	// Votes is the number of candidates that agree with the chosen
+	// response under MajorityVote, and 1 under other strategies.
+	Votes int
+	// Candidates holds every member's answer, in member order, including
+	// failed ones.
+	Candidates []Candidate
+}
+
+// Decision is a Strategy's choice among the candidates.
+type Decision struct {
+	// Index is the index of the chosen candidate.
+	Index int
+	// Votes is the number of candidates supporting the choice.
+	Votes int
+}
+
+// Strategy chooses the answer among candidates. It is only given
+// candidates without an Error, and at least one of them.
+type Strategy interface {
+	Choose(ctx context.Context, prompt string, candidates []Candidate) (Decision, error)
+}
+
+// StrategyFunc adapts a function to the Strategy interface.
+type StrategyFunc func(ctx context.Context, prompt string, candidates []Candidate) (Decision, error)
+
+// Choose calls f.
+func (f StrategyFunc) Choose(ctx context.Context, prompt string, candidates []Candidate) (Decision, error) {
+	return f(ctx, prompt, candidates)
+}
+
+// Ensemble queries several clients and combines their answers.
+type Ensemble struct {
+	members  []xollm.Client
+	strategy Strategy
+}
+
+// New creates an ensemble of members combined by strategy. The ensemble
+// owns the members: Close closes them.
+func New(strategy Strategy, members ...xollm.Client) (*Ensemble, error) {
+	if strategy == nil {
+		return nil, fmt.Errorf("ensemble strategy is required")
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("ensemble needs at least one member")
+	}
+	for i, member := range members {
+		if member == nil {
+			return nil, fmt.Errorf("ensemble member %d is nil", i)
+		}
+	}
+	return &Ensemble{members: members, strategy: strategy}, nil
+}
+
+// Run sends prompt to every member concurrently and returns the answer the
+// strategy chooses. Members that fail are recorded in the candidates and
+// left out of the choice; Run fails only if every member fails or the
+// strategy does.
+func (e *Ensemble) Run(ctx context.Context, prompt string) (*Result, error) {
+	candidates := make([]Candidate, len(e.members))
+	var wg sync.WaitGroup
+	for i, member := range e.members {
+		wg.Add(1)
+		go func(i int, member xollm.Client) {
+			defer wg.Done()
+			start := time.Now()
+			response, err := member.Generate(ctx, prompt)
+			candidates[i] = Candidate{
+				Provider: member.ProviderName(),
+				Response: response,
+				Duration: time.Since(start),
+				Error:    err,
+			}
+		}(i, member)
+	}
+	wg.Wait()
+
+	// The strategy sees only successful candidates; map its choice back
+	var succeeded []Candidate
+	var indexes []int
+	var errs []error
+	for i, c := range candidates {
+		if c.Error != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Provider, c.Error))
+			continue
+		}
+		succeeded = append(succeeded, c)
+		indexes = append(indexes, i)
+	}
+	if len(succeeded) == 0 {
+		return nil, fmt.Errorf("all ensemble members failed: %w", errors.Join(errs...))
+	}
+
+	decision, err := e.strategy.Choose(ctx, prompt, succeeded)
+	if err != nil {
+		return nil, fmt.Errorf("ensemble strategy failed: %w", err)
+	}
+	if decision.Index < 0 || decision.Index >= len(succeeded) {
+		return nil, fmt.Errorf("ensemble strategy chose candidate %d of %d", decision.Index, len(succeeded))
+	}
+	return &Result{
+		Answer:     succeeded[decision.Index].Response,
+		Chosen:     indexes[decision.Index],
+		Votes:      decision.Votes,
+		Candidates: candidates,
+	}, nil
+}
+
+// Generate implements xollm.Client, returning the chosen answer.
+func (e *Ensemble) Generate(ctx context.Context, prompt string) (string, error) {
+	result, err := e.Run(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return result.Answer, nil
+}
+
+// ProviderName returns "ensemble".
+func (e *Ensemble) ProviderName() string {
+	return ProviderName
+}
+
+// Close closes every member.
+func (e *Ensemble) Close() error {
+	var errs []error
+	for _, member := range e.members {
+		if err := member.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s client: %w", member.ProviderName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
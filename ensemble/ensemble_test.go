@@ -0,0 +1,146 @@
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	name     string
+	prompt   string
+	response string
+	err      error
+	closeErr error
+	closed   bool
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	m.prompt = prompt
+	return m.response, m.err
+}
+
+func (m *mockClient) ProviderName() string { return m.name }
+
+func (m *mockClient) Close() error {
+	m.closed = true
+	return m.closeErr
+}
+
+func TestNew_Validation(t *testing.T) {
+	member := &mockClient{name: "a"}
+	if _, err := New(nil, member); err == nil {
+		t.Error("Expected error for nil strategy")
+	}
+	if _, err := New(MajorityVote(nil)); err == nil {
+		t.Error("Expected error for no members")
+	}
+	if _, err := New(MajorityVote(nil), member, nil); err == nil {
+		t.Error("Expected error for nil member")
+	}
+}
+
+func TestRun_MajorityVote(t *testing.T) {
+	ens, err := New(MajorityVote(nil),
+		&mockClient{name: "a", response: "Negative"},
+		&mockClient{name: "b", response: "positive."},
+		&mockClient{name: "c", response: " Positive\n"},
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	result, err := ens.Run(context.Background(), "Classify")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Chosen != 1 || result.Answer != "positive." || result.Votes != 2 {
+		t.Errorf("Expected candidate 1 with 2 votes, got %d %q with %d votes", result.Chosen, result.Answer, result.Votes)
+	}
+	if len(result.Candidates) != 3 {
+		t.Fatalf("Expected 3 candidates, got %d", len(result.Candidates))
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		if result.Candidates[i].Provider != name {
+			t.Errorf("Candidate %d: expected provider %q, got %q", i, name, result.Candidates[i].Provider)
+		}
+	}
+}
+
+func TestRun_SkipsFailedMembers(t *testing.T) {
+	failure := errors.New("boom")
+	ens, _ := New(MajorityVote(nil),
+		&mockClient{name: "a", err: failure},
+		&mockClient{name: "b", response: "yes"},
+	)
+
+	result, err := ens.Run(context.Background(), "Question?")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Chosen != 1 || result.Answer != "yes" {
+		t.Errorf("Expected the surviving candidate, got %d %q", result.Chosen, result.Answer)
+	}
+	if !errors.Is(result.Candidates[0].Error, failure) {
+		t.Errorf("Expected the failure to be recorded, got %v", result.Candidates[0].Error)
+	}
+}
+
+func TestRun_AllMembersFail(t *testing.T) {
+	failA, failB := errors.New("fail a"), errors.New("fail b")
+	ens, _ := New(MajorityVote(nil),
+		&mockClient{name: "a", err: failA},
+		&mockClient{name: "b", err: failB},
+	)
+
+	_, err := ens.Run(context.Background(), "Question?")
+	if !errors.Is(err, failA) || !errors.Is(err, failB) {
+		t.Errorf("Expected both failures to be wrapped, got %v", err)
+	}
+}
+
+func TestRun_StrategyError(t *testing.T) {
+	failure := errors.New("no decision")
+	strategy := StrategyFunc(func(ctx context.Context, prompt string, candidates []Candidate) (Decision, error) {
+		return Decision{}, failure
+	})
+	ens, _ := New(strategy, &mockClient{name: "a", response: "x"})
+
+	if _, err := ens.Run(context.Background(), "Question?"); !errors.Is(err, failure) {
+		t.Errorf("Expected strategy error, got %v", err)
+	}
+}
+
+func TestRun_StrategyOutOfRange(t *testing.T) {
+	strategy := StrategyFunc(func(ctx context.Context, prompt string, candidates []Candidate) (Decision, error) {
+		return Decision{Index: 5}, nil
+	})
+	ens, _ := New(strategy, &mockClient{name: "a", response: "x"})
+
+	if _, err := ens.Run(context.Background(), "Question?"); err == nil || !strings.Contains(err.Error(), "chose candidate 5") {
+		t.Errorf("Expected out of range error, got %v", err)
+	}
+}
+
+func TestEnsemble_Client(t *testing.T) {
+	closeErr := errors.New("close failed")
+	a := &mockClient{name: "a", response: "answer"}
+	b := &mockClient{name: "b", response: "answer", closeErr: closeErr}
+	ens, _ := New(MajorityVote(nil), a, b)
+
+	if ens.ProviderName() != "ensemble" {
+		t.Errorf("Expected provider name 'ensemble', got %q", ens.ProviderName())
+	}
+	text, err := ens.Generate(context.Background(), "Question?")
+	if err != nil || text != "answer" {
+		t.Errorf("Expected 'answer', got %q, %v", text, err)
+	}
+	if err := ens.Close(); !errors.Is(err, closeErr) {
+		t.Errorf("Expected close error, got %v", err)
+	}
+	if !a.closed || !b.closed {
+		t.Error("Expected every member to be closed")
+	}
+}
@@ -0,0 +1,87 @@
+package ensemble
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xostack/xollm"
+)
+
+// MajorityVote returns a strategy that picks the answer most candidates
+// agree on. Answers are compared after normalize, which defaults to
+// DefaultNormalize; ties go to the earliest member. Use it for
+// classifications, yes/no questions and other short answers.
+func MajorityVote(normalize func(string) string) Strategy {
+	if normalize == nil {
+		normalize = DefaultNormalize
+	}
+	return StrategyFunc(func(ctx context.Context, prompt string, candidates []Candidate) (Decision, error) {
+		counts := make(map[string]int)
+		first := make(map[string]int)
+		for i, c := range candidates {
+			key := normalize(c.Response)
+			if _, seen := first[key]; !seen {
+				first[key] = i
+			}
+			counts[key]++
+		}
+		best := Decision{Index: -1}
+		for key, votes := range counts {
+			index := first[key]
+			if votes > best.Votes || (votes == best.Votes && index < best.Index) {
+				best = Decision{Index: index, Votes: votes}
+			}
+		}
+		return best, nil
+	})
+}
+
+// DefaultNormalize lowercases s, trims surrounding whitespace and
+// punctuation and collapses inner whitespace, so "Positive." and
+// " positive" count as the same vote.
+func DefaultNormalize(s string) string {
+	s = strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	return strings.Trim(s, ".,;:!?\"'`*")
+}
+
+// judgeChoicePattern finds the first number in the judge's reply.
+var judgeChoicePattern = regexp.MustCompile(`\d+`)
+
+// Judge returns a strategy that asks the judge client to pick the best
+// answer, for free-text answers that can't be compared directly. The judge
+// is shown the prompt and the numbered candidates and must reply with the
+// number of the best one.
+func Judge(judge xollm.Client) Strategy {
+	return StrategyFunc(func(ctx context.Context, prompt string, candidates []Candidate) (Decision, error) {
+		if len(candidates) == 1 {
+			return Decision{Index: 0, Votes: 1}, nil
+		}
+		reply, err := judge.Generate(ctx, judgePrompt(prompt, candidates))
+		if err != nil {
+			return Decision{}, fmt.Errorf("judge failed: %w", err)
+		}
+		match := judgeChoicePattern.FindString(reply)
+		choice, err := strconv.Atoi(match)
+		if err != nil || choice < 1 || choice > len(candidates) {
+			return Decision{}, fmt.Errorf("judge reply %q does not name an answer between 1 and %d", reply, len(candidates))
+		}
+		return Decision{Index: choice - 1, Votes: 1}, nil
+	})
+}
+
+// judgePrompt asks the judge to pick among candidates.
+func judgePrompt(prompt string, candidates []Candidate) string {
+	var b strings.Builder
+	b.WriteString("You are judging answers to the following prompt.\n\n")
+	b.WriteString("Prompt:\n")
+	b.WriteString(prompt)
+	b.WriteString("\n\n")
+	for i, c := range candidates {
+		fmt.Fprintf(&b, "Answer %d:\n%s\n\n", i+1, c.Response)
+	}
+	fmt.Fprintf(&b, "Which answer is the most accurate, complete and helpful? Reply with the answer number only, from 1 to %d.", len(candidates))
+	return b.String()
+}
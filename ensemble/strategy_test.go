@@ -0,0 +1,83 @@
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func candidates(responses ...string) []Candidate {
+	cs := make([]Candidate, len(responses))
+	for i, r := range responses {
+		cs[i] = Candidate{Provider: "mock", Response: r}
+	}
+	return cs
+}
+
+func TestMajorityVote_TieGoesToEarliest(t *testing.T) {
+	decision, err := MajorityVote(nil).Choose(context.Background(), "", candidates("b", "a", "a", "b", "c"))
+	if err != nil {
+		t.Fatalf("Choose failed: %v", err)
+	}
+	if decision.Index != 0 || decision.Votes != 2 {
+		t.Errorf("Expected index 0 with 2 votes, got %+v", decision)
+	}
+}
+
+func TestMajorityVote_CustomNormalize(t *testing.T) {
+	firstWord := func(s string) string { return strings.Fields(s)[0] }
+	decision, _ := MajorityVote(firstWord).Choose(context.Background(), "", candidates("no way", "yes indeed", "yes sir"))
+	if decision.Index != 1 || decision.Votes != 2 {
+		t.Errorf("Expected index 1 with 2 votes, got %+v", decision)
+	}
+}
+
+func TestDefaultNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Positive.":           "positive",
+		"  \"Yes\"!\n":        "yes",
+		"Not  sure\tat  all?": "not sure at all",
+	}
+	for in, want := range tests {
+		if got := DefaultNormalize(in); got != want {
+			t.Errorf("DefaultNormalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestJudge(t *testing.T) {
+	judge := &mockClient{name: "judge", response: "Answer 2 is best."}
+	decision, err := Judge(judge).Choose(context.Background(), "Explain gravity", candidates("first", "second", "third"))
+	if err != nil {
+		t.Fatalf("Choose failed: %v", err)
+	}
+	if decision.Index != 1 {
+		t.Errorf("Expected index 1, got %d", decision.Index)
+	}
+	for _, want := range []string{"Explain gravity", "Answer 1:\nfirst", "Answer 3:\nthird", "from 1 to 3"} {
+		if !strings.Contains(judge.prompt, want) {
+			t.Errorf("Expected judge prompt to contain %q", want)
+		}
+	}
+}
+
+func TestJudge_SingleCandidate(t *testing.T) {
+	judge := &mockClient{name: "judge", err: errors.New("should not be called")}
+	decision, err := Judge(judge).Choose(context.Background(), "", candidates("only"))
+	if err != nil || decision.Index != 0 {
+		t.Errorf("Expected the only candidate without asking the judge, got %+v, %v", decision, err)
+	}
+}
+
+func TestJudge_Errors(t *testing.T) {
+	failure := errors.New("judge down")
+	if _, err := Judge(&mockClient{err: failure}).Choose(context.Background(), "", candidates("a", "b")); !errors.Is(err, failure) {
+		t.Errorf("Expected judge error, got %v", err)
+	}
+	for _, reply := range []string{"I like them both", "4", "0"} {
+		if _, err := Judge(&mockClient{response: reply}).Choose(context.Background(), "", candidates("a", "b")); err == nil {
+			t.Errorf("Expected error for judge reply %q", reply)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package xollm
+
+import "github.com/xostack/xollm/llm"
+
+// Sentinel errors wrapped by every provider client. Use errors.Is to
+// classify failures:
+//
+//	text, err := client.Generate(ctx, prompt)
+//	if errors.Is(err, xollm.ErrRateLimited) {
+//		// back off and retry later
+//	}
+var (
+	// ErrRateLimited means a rate limit or quota was exceeded.
+	ErrRateLimited = llm.ErrRateLimited
+	// ErrAuthentication means the credentials were missing, invalid or
+	// lack permission.
+	ErrAuthentication = llm.ErrAuthentication
+	// ErrContentFiltered means the prompt or response was blocked by the
+	// provider's safety filters.
+	ErrContentFiltered = llm.ErrContentFiltered
+	// ErrModelNotFound means the requested model is not available.
+	ErrModelNotFound = llm.ErrModelNotFound
+	// ErrContextTooLong means the prompt does not fit in the model's
+	// context window.
+	ErrContextTooLong = llm.ErrContextTooLong
+	// ErrTimeout means the request did not complete in time.
+	ErrTimeout = llm.ErrTimeout
+	// ErrUnavailable means the provider could not be reached or returned a
+	// server error.
+	ErrUnavailable = llm.ErrUnavailable
+)
+
+// Error describes a failed provider API call with the provider's HTTP
+// status, error code and type, request ID and raw body. It wraps one of the
+// sentinels above. See llm.Error.
+type Error = llm.Error
+
+// ContextLengthError is returned for requests that don't fit in the model's
+// context window. It carries the prompt size and limit where the provider
+// reports them, so callers can trim the prompt by Excess() and retry. It
+// matches ErrContextTooLong. See llm.ContextLengthError.
+type ContextLengthError = llm.ContextLengthError
+
+// ContentFilteredError is returned when a provider's safety filters block
+// the prompt or response. It carries the block reason and per-category
+// safety ratings and matches ErrContentFiltered. See llm.ContentFilteredError.
+type ContentFilteredError = llm.ContentFilteredError
+
+// SafetyRating is a provider's assessment of one harm category.
+type SafetyRating = llm.SafetyRating
+
+// MaxErrorBodyBytes caps the raw response body kept in Error.Body.
+const MaxErrorBodyBytes = llm.MaxErrorBodyBytes
+
+// IsRetryable reports whether sending the same request again later may
+// succeed, i.e. the error is a rate limit, a timeout or a temporary provider
+// failure. Retry loops, fallbacks and batch jobs use it so that every layer
+// makes the same decision.
+func IsRetryable(err error) bool {
+	return llm.IsRetryable(err)
+}
+
+// IsFatal reports whether the request can never succeed as sent: the
+// credentials are bad, the model doesn't exist, the content was filtered,
+// the prompt is too long, or the context was cancelled. Errors that are
+// neither retryable nor fatal are unclassified.
+func IsFatal(err error) bool {
+	return llm.IsFatal(err)
+}
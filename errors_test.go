@@ -0,0 +1,59 @@
+package xollm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestSentinelErrors_MatchProviderErrors(t *testing.T) {
+	// Providers wrap the llm sentinels; the xollm names must match them
+	providerErr := llm.WrapError(llm.ErrRateLimited, errors.New("groq API request failed with status 429"))
+	if !errors.Is(providerErr, ErrRateLimited) {
+		t.Error("Expected provider error to match xollm.ErrRateLimited")
+	}
+
+	sentinels := []error{ErrRateLimited, ErrAuthentication, ErrContentFiltered, ErrModelNotFound, ErrContextTooLong, ErrTimeout, ErrUnavailable}
+	for i, a := range sentinels {
+		for j, b := range sentinels {
+			if i != j && errors.Is(a, b) {
+				t.Errorf("Expected sentinels to be distinct: %v matches %v", a, b)
+			}
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	if !IsRetryable(llm.WrapError(ErrRateLimited, errors.New("slow down"))) {
+		t.Error("Expected rate limit to be retryable")
+	}
+	if IsRetryable(ErrAuthentication) {
+		t.Error("Expected authentication failure not to be retryable")
+	}
+	if !IsFatal(ErrAuthentication) || IsFatal(ErrTimeout) {
+		t.Error("Expected only authentication failure to be fatal")
+	}
+}
+
+func TestError_Alias(t *testing.T) {
+	var err error = &llm.Error{Provider: "groq", StatusCode: 401, Kind: llm.ErrAuthentication}
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 401 {
+		t.Error("Expected xollm.Error to match provider errors")
+	}
+	if !errors.Is(err, ErrAuthentication) {
+		t.Error("Expected sentinel to be wrapped")
+	}
+}
+
+func TestContextLengthError_Alias(t *testing.T) {
+	err := llm.WrapContextLength("gemma2-9b-it", llm.WrapError(llm.ErrContextTooLong, errors.New("maximum context length is 8192 tokens. However, you requested 9000 tokens")))
+	var cle *ContextLengthError
+	if !errors.As(err, &cle) || cle.Excess() != 808 {
+		t.Errorf("Expected structured context length error, got %v", err)
+	}
+	if !errors.Is(err, ErrContextTooLong) {
+		t.Error("Expected ErrContextTooLong to match")
+	}
+}
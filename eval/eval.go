@@ -0,0 +1,231 @@
+// Package eval scores LLM outputs against a rubric with a judge model, and
+// runs suites of test cases across providers and prompt versions.
+//
+// A Rubric lists the criteria an output is judged on. A Judge sends the
+// rubric and the candidate output to its judge client, asks for a score per
+// criterion as JSON, and returns the parsed scores with their weighted
+// overall score, so pipelines comparing prompts or providers can rank
+// outputs without reading them.
+//
+// Example usage:
+//
+//	rubric := eval.Rubric{
+//		Task: "Summarize the incident report for an executive audience.",
+//		Criteria: []eval.Criterion{
+//			{Name: "accuracy", Description: "States only facts from the report", Weight: 2},
+//			{Name: "brevity", Description: "Three sentences or fewer"},
+//		},
+//	}
+//
+//	judge := eval.NewJudge(judgeClient)
+//	result, err := judge.Judge(ctx, rubric, summary)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("overall %.2f\n", result.Normalized())
+//
+// A Suite runs test cases against several targets and prompt versions,
+// checking each output against patterns, a JSON Schema, a rubric or a
+// custom Scorer, and writes the scored Report as JSON or markdown.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xostack/xollm"
+)
+
+// DefaultMaxScore is the top of the scoring scale when Rubric.MaxScore is
+// not set.
+const DefaultMaxScore = 10
+
+// Criterion is one aspect an output is judged on.
+type Criterion struct {
+	// Name identifies the criterion in the scores, e.g. "accuracy".
+	Name string
+	// Description tells the judge what a good output does.
+	Description string
+	// Weight is the criterion's share of the overall score. Zero counts
+	// as 1.
+	Weight float64
+}
+
+// Rubric describes how outputs are judged.
+type Rubric struct {
+	// Task is the instruction or prompt the candidate output answers. It
+	// gives the judge the context for its scores and may be empty.
+	Task string
+	// Criteria are the aspects the output is scored on. At least one is
+	// required and names must be unique.
+	Criteria []Criterion
+	// MaxScore is the top of the scoring scale, which runs from 0.
+	// Defaults to DefaultMaxScore.
+	MaxScore int
+}
+
+// maxScore returns MaxScore or its default.
+func (r Rubric) maxScore() int {
+	if r.MaxScore > 0 {
+		return r.MaxScore
+	}
+	return DefaultMaxScore
+}
+
+// validate checks that the rubric can be judged.
+func (r Rubric) validate() error {
+	if len(r.Criteria) == 0 {
+		return fmt.Errorf("rubric has no criteria")
+	}
+	seen := make(map[string]bool)
+	for _, c := range r.Criteria {
+		if c.Name == "" {
+			return fmt.Errorf("rubric criterion has no name")
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("rubric criterion %q is listed twice", c.Name)
+		}
+		if c.Weight < 0 {
+			return fmt.Errorf("rubric criterion %q has negative weight", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
+
+// Score is the judge's score for one criterion.
+type Score struct {
+	Criterion string  `json:"criterion"`
+	Score     float64 `json:"score"`
+	Reason    string  `json:"reason,omitempty"`
+}
+
+// Result is the judge's evaluation of one candidate output.
+type Result struct {
+	// Scores holds one score per rubric criterion, in rubric order.
+	Scores []Score `json:"scores"`
+	// Overall is the weighted mean of the scores, on the rubric's scale.
+	Overall float64 `json:"overall"`
+	// MaxScore is the top of the scale the scores are on.
+	MaxScore int `json:"max_score"`
+	// Judge is the provider name of the judge client.
+	Judge string `json:"judge"`
+	// Raw is the judge's unparsed reply.
+	Raw string `json:"-"`
+}
+
+// Normalized returns Overall scaled to the range 0 to 1, for comparing
+// results judged on different scales.
+func (r *Result) Normalized() float64 {
+	if r.MaxScore <= 0 {
+		return 0
+	}
+	return r.Overall / float64(r.MaxScore)
+}
+
+// Score returns the score for the named criterion.
+func (r *Result) Score(criterion string) (Score, bool) {
+	for _, s := range r.Scores {
+		if s.Criterion == criterion {
+			return s, true
+		}
+	}
+	return Score{}, false
+}
+
+// Judge scores outputs with a judge model. It is safe for concurrent use
+// if its client is.
+type Judge struct {
+	client xollm.Client
+}
+
+// NewJudge returns a judge that asks client for its scores. A capable
+// model with a low temperature gives the most consistent scores.
+func NewJudge(client xollm.Client) *Judge {
+	return &Judge{client: client}
+}
+
+// Judge scores candidate against rubric. It fails if the judge's reply is
+// not valid JSON, leaves out a criterion, or scores outside the scale.
+func (j *Judge) Judge(ctx context.Context, rubric Rubric, candidate string) (*Result, error) {
+	if err := rubric.validate(); err != nil {
+		return nil, err
+	}
+	reply, err := j.client.Generate(ctx, judgePrompt(rubric, candidate))
+	if err != nil {
+		return nil, fmt.Errorf("judge generation failed: %w", err)
+	}
+	result, err := parseScores(rubric, reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse judge reply: %w", err)
+	}
+	result.Judge = j.client.ProviderName()
+	result.Raw = reply
+	return result, nil
+}
+
+// judgePrompt asks the judge to score candidate on each criterion.
+func judgePrompt(rubric Rubric, candidate string) string {
+	var b strings.Builder
+	b.WriteString("You are an impartial judge evaluating an AI-generated output.\n\n")
+	if rubric.Task != "" {
+		fmt.Fprintf(&b, "Task given to the AI:\n%s\n\n", rubric.Task)
+	}
+	fmt.Fprintf(&b, "Output to evaluate:\n<output>\n%s\n</output>\n\n", candidate)
+	fmt.Fprintf(&b, "Score the output on each criterion from 0 (worst) to %d (best):\n", rubric.maxScore())
+	for _, c := range rubric.Criteria {
+		if c.Description != "" {
+			fmt.Fprintf(&b, "- %s: %s\n", c.Name, c.Description)
+		} else {
+			fmt.Fprintf(&b, "- %s\n", c.Name)
+		}
+	}
+	b.WriteString("\nReply with JSON only, in this form:\n")
+	b.WriteString(`{"scores": [{"criterion": "<name>", "score": <number>, "reason": "<one sentence>"}]}`)
+	return b.String()
+}
+
+// parseScores decodes the judge's reply and checks it against the rubric.
+func parseScores(rubric Rubric, reply string) (*Result, error) {
+	body := xollm.ExtractJSON(reply)
+	if !strings.HasPrefix(body, "{") {
+		return nil, fmt.Errorf("no JSON object in reply")
+	}
+	var parsed struct {
+		Scores []Score `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	byName := make(map[string]Score, len(parsed.Scores))
+	for _, s := range parsed.Scores {
+		byName[strings.ToLower(strings.TrimSpace(s.Criterion))] = s
+	}
+
+	maxScore := float64(rubric.maxScore())
+	result := &Result{MaxScore: rubric.maxScore()}
+	var total, weights float64
+	for _, c := range rubric.Criteria {
+		s, ok := byName[strings.ToLower(c.Name)]
+		if !ok {
+			return nil, fmt.Errorf("missing score for criterion %q", c.Name)
+		}
+		if s.Score < 0 || s.Score > maxScore {
+			return nil, fmt.Errorf("score %g for criterion %q is outside 0 to %d", s.Score, c.Name, rubric.maxScore())
+		}
+		s.Criterion = c.Name
+		result.Scores = append(result.Scores, s)
+
+		weight := c.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		total += s.Score * weight
+		weights += weight
+	}
+	result.Overall = total / weights
+	return result, nil
+}
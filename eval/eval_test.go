@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	prompt   string
+	response string
+	err      error
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	m.prompt = prompt
+	return m.response, m.err
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error { return nil }
+
+var testRubric = Rubric{
+	Task: "Summarize the report",
+	Criteria: []Criterion{
+		{Name: "accuracy", Description: "Only facts from the report", Weight: 3},
+		{Name: "brevity"},
+	},
+}
+
+func TestJudge(t *testing.T) {
+	client := &mockClient{response: "Here you go:\n```json\n" +
+		`{"scores": [{"criterion": "Brevity", "score": 4, "reason": "Too long"}, {"criterion": "accuracy", "score": 8}]}` +
+		"\n```"}
+
+	result, err := NewJudge(client).Judge(context.Background(), testRubric, "The summary.")
+	if err != nil {
+		t.Fatalf("Judge failed: %v", err)
+	}
+
+	if len(result.Scores) != 2 || result.Scores[0].Criterion != "accuracy" || result.Scores[1].Criterion != "brevity" {
+		t.Fatalf("Expected scores in rubric order, got %+v", result.Scores)
+	}
+	if s, ok := result.Score("brevity"); !ok || s.Score != 4 || s.Reason != "Too long" {
+		t.Errorf("Unexpected brevity score: %+v", s)
+	}
+	// (8*3 + 4*1) / 4
+	if result.Overall != 7 {
+		t.Errorf("Expected overall 7, got %g", result.Overall)
+	}
+	if math.Abs(result.Normalized()-0.7) > 1e-9 {
+		t.Errorf("Expected normalized 0.7, got %g", result.Normalized())
+	}
+	if result.Judge != "mock" || result.MaxScore != DefaultMaxScore {
+		t.Errorf("Unexpected judge metadata: %q, %d", result.Judge, result.MaxScore)
+	}
+
+	for _, want := range []string{"Summarize the report", "The summary.", "- accuracy: Only facts from the report", "- brevity\n", "0 (worst) to 10 (best)"} {
+		if !strings.Contains(client.prompt, want) {
+			t.Errorf("Expected judge prompt to contain %q", want)
+		}
+	}
+}
+
+func TestJudge_InvalidRubric(t *testing.T) {
+	tests := map[string]Rubric{
+		"no criteria":     {},
+		"unnamed":         {Criteria: []Criterion{{Description: "x"}}},
+		"duplicate":       {Criteria: []Criterion{{Name: "a"}, {Name: "a"}}},
+		"negative weight": {Criteria: []Criterion{{Name: "a", Weight: -1}}},
+	}
+	for name, rubric := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &mockClient{}
+			if _, err := NewJudge(client).Judge(context.Background(), rubric, "x"); err == nil {
+				t.Error("Expected error")
+			}
+			if client.prompt != "" {
+				t.Error("Expected the judge not to be called")
+			}
+		})
+	}
+}
+
+func TestJudge_BadReplies(t *testing.T) {
+	tests := map[string]string{
+		"no JSON":           "I'd give it an 8.",
+		"invalid JSON":      `{"scores": [}`,
+		"missing criterion": `{"scores": [{"criterion": "accuracy", "score": 8}]}`,
+		"out of scale":      `{"scores": [{"criterion": "accuracy", "score": 11}, {"criterion": "brevity", "score": 5}]}`,
+	}
+	for name, reply := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewJudge(&mockClient{response: reply}).Judge(context.Background(), testRubric, "x")
+			if err == nil || !strings.Contains(err.Error(), "failed to parse judge reply") {
+				t.Errorf("Expected parse error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJudge_GenerationError(t *testing.T) {
+	failure := errors.New("judge down")
+	_, err := NewJudge(&mockClient{err: failure}).Judge(context.Background(), testRubric, "x")
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected wrapped generation error, got %v", err)
+	}
+}
+
+func TestJudge_CustomScale(t *testing.T) {
+	rubric := Rubric{Criteria: []Criterion{{Name: "tone"}}, MaxScore: 5}
+	client := &mockClient{response: `{"scores": [{"criterion": "tone", "score": 5}]}`}
+	result, err := NewJudge(client).Judge(context.Background(), rubric, "x")
+	if err != nil {
+		t.Fatalf("Judge failed: %v", err)
+	}
+	if result.Normalized() != 1 || !strings.Contains(client.prompt, "to 5 (best)") {
+		t.Errorf("Expected a 5 point scale, got %+v", result)
+	}
+}
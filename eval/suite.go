@@ -0,0 +1,459 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xostack/xollm"
+	"github.com/xostack/xollm/prompt"
+)
+
+// Scorer scores an output with custom logic. It returns a score from 0 to 1
+// and an optional reason.
+type Scorer func(ctx context.Context, c Case, output string) (score float64, reason string, err error)
+
+// Case is one test case of a Suite.
+type Case struct {
+	// Name identifies the case in reports.
+	Name string
+	// Prompt is sent as-is when the suite has no prompt versions.
+	Prompt string
+	// Vars is the data prompt versions are rendered with.
+	Vars map[string]interface{}
+	// Patterns are regular expressions the output must match.
+	Patterns []string
+	// Forbidden are regular expressions the output must not match.
+	Forbidden []string
+	// Schema, if set, is a JSON Schema the output must be valid JSON for.
+	// A code fence around the JSON is ignored.
+	Schema json.RawMessage
+	// Rubric, if set, has the suite's judge score the output. An empty
+	// Task is filled in with the rendered prompt.
+	Rubric *Rubric
+	// Scorer, if set, scores the output with custom logic.
+	Scorer Scorer
+	// MinScore is the lowest score the case passes with, from 0 to 1.
+	MinScore float64
+}
+
+// Target is a client a suite is run against. Use one target per provider
+// and model combination being compared.
+type Target struct {
+	// Name identifies the target in reports, e.g. "groq/llama3-70b".
+	// Defaults to the client's provider name.
+	Name   string
+	Client xollm.Client
+}
+
+// PromptVersion is a prompt template a suite's cases are rendered with.
+type PromptVersion struct {
+	// ID identifies the version in reports, e.g. "v2".
+	ID       string
+	Template *prompt.Template
+}
+
+// Suite runs test cases across targets and prompt versions and scores the
+// outputs.
+//
+//	suite := eval.Suite{
+//		Cases: []eval.Case{{
+//			Name:     "capital",
+//			Prompt:   "What is the capital of France?",
+//			Patterns: []string{`(?i)\bparis\b`},
+//		}},
+//	}
+//	report, err := suite.Run(ctx, eval.Target{Client: groq}, eval.Target{Client: gemini})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	report.WriteMarkdown(os.Stdout)
+type Suite struct {
+	Cases []Case
+	// Prompts are the prompt versions to compare. If empty, each case's
+	// Prompt is sent.
+	Prompts []PromptVersion
+	// Judge scores the cases with a Rubric.
+	Judge *Judge
+	// Concurrency is the number of cases run at once. If <= 0, cases are
+	// run one at a time.
+	Concurrency int
+}
+
+// Check is the outcome of one check of an output.
+type Check struct {
+	// Name describes the check, e.g. "pattern (?i)paris" or "rubric".
+	Name string `json:"name"`
+	// Score is from 0 to 1; pass/fail checks score 0 or 1.
+	Score float64 `json:"score"`
+	// Passed reports whether the check passed.
+	Passed bool `json:"passed"`
+	// Detail explains the score, if there is anything to explain.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Run is the outcome of one case on one target and prompt version.
+type Run struct {
+	Case    string `json:"case"`
+	Target  string `json:"target"`
+	Version string `json:"version,omitempty"`
+	// Prompt is the prompt that was sent.
+	Prompt string `json:"prompt"`
+	// Output is the target's response.
+	Output  string        `json:"output"`
+	Latency time.Duration `json:"latency_ns"`
+	// Error is why the case couldn't be run or scored, if it couldn't.
+	Error  string  `json:"error,omitempty"`
+	Checks []Check `json:"checks"`
+	// Score is the mean of the check scores, from 0 to 1.
+	Score float64 `json:"score"`
+	// Passed reports whether every pass/fail check passed and Score
+	// reached the case's MinScore.
+	Passed bool `json:"passed"`
+}
+
+// TargetSummary aggregates the runs of one target and prompt version.
+type TargetSummary struct {
+	Target      string        `json:"target"`
+	Version     string        `json:"version,omitempty"`
+	Cases       int           `json:"cases"`
+	Passed      int           `json:"passed"`
+	Errors      int           `json:"errors"`
+	MeanScore   float64       `json:"mean_score"`
+	MeanLatency time.Duration `json:"mean_latency_ns"`
+}
+
+// Report is the outcome of a suite run.
+type Report struct {
+	Started time.Time `json:"started"`
+	Runs    []Run     `json:"runs"`
+}
+
+// compiledCase is a case with its regular expressions compiled.
+type compiledCase struct {
+	Case
+	patterns  []*regexp.Regexp
+	forbidden []*regexp.Regexp
+}
+
+// Run runs every case against every target and prompt version. It fails
+// only if the suite is invalid; generation and scoring failures are
+// recorded in the report's runs, ordered by case, target and version.
+func (s *Suite) Run(ctx context.Context, targets ...Target) (*Report, error) {
+	cases, err := s.compile()
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("eval suite has no targets")
+	}
+	targets = append([]Target(nil), targets...)
+	for i, t := range targets {
+		if t.Client == nil {
+			return nil, fmt.Errorf("eval target %d has no client", i)
+		}
+		if t.Name == "" {
+			targets[i].Name = t.Client.ProviderName()
+		}
+	}
+	versions := s.Prompts
+	if len(versions) == 0 {
+		versions = []PromptVersion{{}}
+	}
+
+	concurrency := s.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	report := &Report{Started: time.Now()}
+	report.Runs = make([]Run, len(cases)*len(targets)*len(versions))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	i := 0
+	for _, c := range cases {
+		for _, t := range targets {
+			for _, v := range versions {
+				wg.Add(1)
+				sem <- struct{}{}
+				go func(i int, c *compiledCase, t Target, v PromptVersion) {
+					defer wg.Done()
+					defer func() { <-sem }()
+					report.Runs[i] = s.runOne(ctx, c, t, v)
+				}(i, c, t, v)
+				i++
+			}
+		}
+	}
+	wg.Wait()
+	return report, nil
+}
+
+// compile validates the suite and compiles its cases' patterns.
+func (s *Suite) compile() ([]*compiledCase, error) {
+	if len(s.Cases) == 0 {
+		return nil, fmt.Errorf("eval suite has no cases")
+	}
+	for _, v := range s.Prompts {
+		if v.ID == "" || v.Template == nil {
+			return nil, fmt.Errorf("eval suite prompt versions need an ID and a template")
+		}
+	}
+
+	seen := make(map[string]bool, len(s.Cases))
+	cases := make([]*compiledCase, 0, len(s.Cases))
+	for _, c := range s.Cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("eval case has no name")
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("eval case %q is listed twice", c.Name)
+		}
+		seen[c.Name] = true
+		if len(s.Prompts) == 0 && c.Prompt == "" {
+			return nil, fmt.Errorf("eval case %q has no prompt", c.Name)
+		}
+		if c.Rubric != nil && s.Judge == nil {
+			return nil, fmt.Errorf("eval case %q has a rubric but the suite has no judge", c.Name)
+		}
+
+		cc := &compiledCase{Case: c}
+		for _, p := range c.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("eval case %q: invalid pattern: %w", c.Name, err)
+			}
+			cc.patterns = append(cc.patterns, re)
+		}
+		for _, p := range c.Forbidden {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("eval case %q: invalid forbidden pattern: %w", c.Name, err)
+			}
+			cc.forbidden = append(cc.forbidden, re)
+		}
+		cases = append(cases, cc)
+	}
+	return cases, nil
+}
+
+// runOne runs and scores a single case.
+func (s *Suite) runOne(ctx context.Context, c *compiledCase, t Target, v PromptVersion) Run {
+	run := Run{Case: c.Name, Target: t.Name, Version: v.ID, Prompt: c.Prompt}
+	if v.Template != nil {
+		rendered, err := v.Template.Render(c.Vars)
+		if err != nil {
+			run.Error = err.Error()
+			return run
+		}
+		run.Prompt = rendered
+	}
+
+	start := time.Now()
+	output, err := t.Client.Generate(ctx, run.Prompt)
+	run.Latency = time.Since(start)
+	if err != nil {
+		run.Error = fmt.Sprintf("generation failed: %v", err)
+		return run
+	}
+	run.Output = output
+
+	for _, re := range c.patterns {
+		run.Checks = append(run.Checks, passFail("pattern "+re.String(), re.MatchString(output), "no match"))
+	}
+	for _, re := range c.forbidden {
+		run.Checks = append(run.Checks, passFail("forbidden "+re.String(), !re.MatchString(output), "matched "+strings.TrimSpace(re.FindString(output))))
+	}
+	if len(c.Schema) > 0 {
+		err := xollm.ValidateJSON(c.Schema, []byte(xollm.ExtractJSON(output)))
+		detail := ""
+		if err != nil {
+			detail = err.Error()
+		}
+		run.Checks = append(run.Checks, passFail("schema", err == nil, detail))
+	}
+	if c.Rubric != nil {
+		rubric := *c.Rubric
+		if rubric.Task == "" {
+			rubric.Task = run.Prompt
+		}
+		result, err := s.Judge.Judge(ctx, rubric, output)
+		if err != nil {
+			run.Error = err.Error()
+			return run
+		}
+		run.Checks = append(run.Checks, graded("rubric", result.Normalized(), c.MinScore, rubricDetail(result)))
+	}
+	if c.Scorer != nil {
+		score, reason, err := c.Scorer(ctx, c.Case, output)
+		if err != nil {
+			run.Error = fmt.Sprintf("scorer failed: %v", err)
+			return run
+		}
+		run.Checks = append(run.Checks, graded("scorer", score, c.MinScore, reason))
+	}
+
+	run.Passed = true
+	var total float64
+	for _, check := range run.Checks {
+		total += check.Score
+		if !check.Passed {
+			run.Passed = false
+		}
+	}
+	if len(run.Checks) > 0 {
+		run.Score = total / float64(len(run.Checks))
+	} else {
+		// A case without checks only tests that generation succeeds
+		run.Score = 1
+	}
+	if run.Score < c.MinScore {
+		run.Passed = false
+	}
+	return run
+}
+
+// passFail returns a check scoring 1 if passed and 0 with detail if not.
+func passFail(name string, passed bool, detail string) Check {
+	if passed {
+		return Check{Name: name, Score: 1, Passed: true}
+	}
+	return Check{Name: name, Detail: detail}
+}
+
+// graded returns a check for a score from 0 to 1, clamped to that range.
+func graded(name string, score, minScore float64, detail string) Check {
+	score = min(max(score, 0), 1)
+	return Check{Name: name, Score: score, Passed: score >= minScore, Detail: detail}
+}
+
+// rubricDetail lists a judge result's criterion scores.
+func rubricDetail(r *Result) string {
+	parts := make([]string, len(r.Scores))
+	for i, s := range r.Scores {
+		parts[i] = fmt.Sprintf("%s %g/%d", s.Criterion, s.Score, r.MaxScore)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Summary aggregates the runs per target and prompt version, in the order
+// they first appear.
+func (r *Report) Summary() []TargetSummary {
+	type key struct{ target, version string }
+	index := make(map[key]int)
+	var summaries []TargetSummary
+	var latencies []time.Duration
+	var scores []float64
+	for _, run := range r.Runs {
+		k := key{run.Target, run.Version}
+		i, ok := index[k]
+		if !ok {
+			i = len(summaries)
+			index[k] = i
+			summaries = append(summaries, TargetSummary{Target: run.Target, Version: run.Version})
+			latencies = append(latencies, 0)
+			scores = append(scores, 0)
+		}
+		s := &summaries[i]
+		s.Cases++
+		if run.Passed {
+			s.Passed++
+		}
+		if run.Error != "" {
+			s.Errors++
+		}
+		scores[i] += run.Score
+		latencies[i] += run.Latency
+	}
+	for i := range summaries {
+		summaries[i].MeanScore = scores[i] / float64(summaries[i].Cases)
+		summaries[i].MeanLatency = latencies[i] / time.Duration(summaries[i].Cases)
+	}
+	return summaries
+}
+
+// WriteJSON writes the report and its summary as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*Report
+		Summary []TargetSummary `json:"summary"`
+	}{r, r.Summary()})
+}
+
+// WriteMarkdown writes the report as markdown: a summary table ranked by
+// mean score, a table of scores per case, and the checks each failed run
+// missed.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	summaries := r.Summary()
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].MeanScore > summaries[j].MeanScore
+	})
+
+	var b strings.Builder
+	b.WriteString("# Eval report\n\n")
+	b.WriteString("| Target | Version | Passed | Errors | Mean score | Mean latency |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, s := range summaries {
+		fmt.Fprintf(&b, "| %s | %s | %d/%d | %d | %.2f | %v |\n",
+			escapeCell(s.Target), escapeCell(s.Version), s.Passed, s.Cases, s.Errors, s.MeanScore, s.MeanLatency.Round(time.Millisecond))
+	}
+
+	b.WriteString("\n## Cases\n\n")
+	b.WriteString("| Case | Target | Version | Score | Result |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, run := range r.Runs {
+		result := "pass"
+		switch {
+		case run.Error != "":
+			result = "error"
+		case !run.Passed:
+			result = "fail"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %.2f | %s |\n",
+			escapeCell(run.Case), escapeCell(run.Target), escapeCell(run.Version), run.Score, result)
+	}
+
+	var failures strings.Builder
+	for _, run := range r.Runs {
+		if run.Passed {
+			continue
+		}
+		fmt.Fprintf(&failures, "\n### %s on %s", run.Case, run.Target)
+		if run.Version != "" {
+			fmt.Fprintf(&failures, " (%s)", run.Version)
+		}
+		failures.WriteString("\n\n")
+		if run.Error != "" {
+			fmt.Fprintf(&failures, "- error: %s\n", run.Error)
+		}
+		for _, check := range run.Checks {
+			if !check.Passed {
+				fmt.Fprintf(&failures, "- %s: %.2f", check.Name, check.Score)
+				if check.Detail != "" {
+					fmt.Fprintf(&failures, " (%s)", check.Detail)
+				}
+				failures.WriteString("\n")
+			}
+		}
+	}
+	if failures.Len() > 0 {
+		b.WriteString("\n## Failures\n")
+		b.WriteString(failures.String())
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeCell makes s safe to use in a markdown table cell.
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,186 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm/prompt"
+)
+
+// echoClient answers each prompt with the response registered for it.
+type echoClient struct {
+	name      string
+	responses map[string]string
+}
+
+func (c *echoClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if r, ok := c.responses[prompt]; ok {
+		return r, nil
+	}
+	return "", errors.New("no response")
+}
+
+func (c *echoClient) ProviderName() string { return c.name }
+
+func (c *echoClient) Close() error { return nil }
+
+func TestSuite_Run(t *testing.T) {
+	good := &echoClient{name: "good", responses: map[string]string{
+		"capital of France?": "Paris.",
+		"list colors":        "```json\n{\"colors\": [\"red\"]}\n```",
+	}}
+	bad := &echoClient{name: "bad", responses: map[string]string{
+		"capital of France?": "I think it's Lyon, not Paris? Lyon.",
+		"list colors":        "red, green",
+	}}
+	suite := Suite{
+		Cases: []Case{
+			{Name: "capital", Prompt: "capital of France?", Patterns: []string{`(?i)paris`}, Forbidden: []string{`Lyon`}},
+			{Name: "colors", Prompt: "list colors", Schema: json.RawMessage(`{"type": "object", "required": ["colors"]}`)},
+		},
+		Concurrency: 3,
+	}
+
+	report, err := suite.Run(context.Background(), Target{Client: good}, Target{Name: "bad/v1", Client: bad})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Runs) != 4 {
+		t.Fatalf("Expected 4 runs, got %d", len(report.Runs))
+	}
+
+	want := []struct {
+		target string
+		passed bool
+		score  float64
+	}{{"good", true, 1}, {"bad/v1", false, 0.5}, {"good", true, 1}, {"bad/v1", false, 0}}
+	for i, w := range want {
+		run := report.Runs[i]
+		if run.Target != w.target || run.Passed != w.passed || run.Score != w.score {
+			t.Errorf("Run %d: expected %s passed=%v score=%g, got %+v", i, w.target, w.passed, w.score, run)
+		}
+	}
+
+	summary := report.Summary()
+	if len(summary) != 2 || summary[0].Target != "good" || summary[0].Passed != 2 || summary[1].Passed != 0 || summary[1].MeanScore != 0.25 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+}
+
+func TestSuite_PromptVersionsAndScorers(t *testing.T) {
+	v1, err := prompt.Parse("greet@v1", "Say hi to {{.name}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := prompt.Parse("greet@v2", "Greet {{.name}} warmly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &echoClient{name: "mock", responses: map[string]string{
+		"Say hi to Ada":    "hi",
+		"Greet Ada warmly": "Hello Ada, lovely to meet you!",
+	}}
+	judgeClient := &mockClient{response: `{"scores": [{"criterion": "warmth", "score": 8}]}`}
+
+	suite := Suite{
+		Cases: []Case{{
+			Name:   "greeting",
+			Vars:   map[string]interface{}{"name": "Ada"},
+			Rubric: &Rubric{Criteria: []Criterion{{Name: "warmth"}}},
+			Scorer: func(ctx context.Context, c Case, output string) (float64, string, error) {
+				return float64(len(output)) / 10, "length", nil
+			},
+			MinScore: 0.5,
+		}},
+		Prompts: []PromptVersion{{ID: "v1", Template: v1}, {ID: "v2", Template: v2}},
+		Judge:   NewJudge(judgeClient),
+	}
+
+	report, err := suite.Run(context.Background(), Target{Client: client})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %d", len(report.Runs))
+	}
+	v1Run, v2Run := report.Runs[0], report.Runs[1]
+	if v1Run.Version != "v1" || v1Run.Prompt != "Say hi to Ada" || v1Run.Passed {
+		t.Errorf("Expected v1 to fail the length scorer, got %+v", v1Run)
+	}
+	if v2Run.Version != "v2" || !v2Run.Passed || v2Run.Score != 0.9 {
+		t.Errorf("Expected v2 to pass with score 0.9, got %+v", v2Run)
+	}
+	if !strings.Contains(judgeClient.prompt, "Greet Ada warmly") {
+		t.Errorf("Expected the rendered prompt as the rubric task, got %q", judgeClient.prompt)
+	}
+}
+
+func TestSuite_Errors(t *testing.T) {
+	client := &echoClient{name: "mock"}
+	tests := []struct {
+		name  string
+		suite Suite
+		want  string
+	}{
+		{"no cases", Suite{}, "no cases"},
+		{"no prompt", Suite{Cases: []Case{{Name: "a"}}}, "has no prompt"},
+		{"duplicate", Suite{Cases: []Case{{Name: "a", Prompt: "p"}, {Name: "a", Prompt: "p"}}}, "listed twice"},
+		{"bad pattern", Suite{Cases: []Case{{Name: "a", Prompt: "p", Patterns: []string{"("}}}}, "invalid pattern"},
+		{"no judge", Suite{Cases: []Case{{Name: "a", Prompt: "p", Rubric: &testRubric}}}, "has no judge"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.suite.Run(context.Background(), Target{Client: client})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	report, err := (&Suite{Cases: []Case{{Name: "a", Prompt: "p"}}}).Run(context.Background(), Target{Client: client})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if run := report.Runs[0]; run.Passed || !strings.Contains(run.Error, "generation failed") {
+		t.Errorf("Expected a recorded generation failure, got %+v", run)
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	report := &Report{Runs: []Run{
+		{Case: "a", Target: "groq", Score: 1, Passed: true, Checks: []Check{{Name: "schema", Score: 1, Passed: true}}},
+		{Case: "a", Target: "gemini", Checks: []Check{{Name: "pattern x|y", Detail: "no match"}}},
+	}}
+
+	var md bytes.Buffer
+	if err := report.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| groq |  | 1/1 | 0 | 1.00 |", "| a | gemini |  | 0.00 | fail |", "### a on gemini", `- pattern x|y: 0.00 (no match)`} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Expected markdown to contain %q:\n%s", want, md.String())
+		}
+	}
+	if strings.Index(md.String(), "| groq |") > strings.Index(md.String(), "| gemini |") {
+		t.Errorf("Expected targets ranked by score:\n%s", md.String())
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Runs    []Run           `json:"runs"`
+		Summary []TargetSummary `json:"summary"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON report: %v", err)
+	}
+	if len(decoded.Runs) != 2 || len(decoded.Summary) != 2 || decoded.Runs[1].Checks[0].Detail != "no match" {
+		t.Errorf("Unexpected JSON report: %s", out.String())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"sort"
 	"strings"
 	"sync"
@@ -30,6 +31,7 @@ type BatchResult struct {
 	Duration time.Duration // Time taken to process the job
 	Error    error         // Any error that occurred during processing
 	Worker   int           // Which worker processed this job
+	Attempts int           // Number of generation attempts made
 }
 
 // BatchStatistics holds statistics about batch processing
@@ -42,14 +44,28 @@ type BatchStatistics struct {
 	WorkerCount     int           // Number of workers used
 	StartTime       time.Time     // When batch processing started
 	EndTime         time.Time     // When batch processing ended
+
+	RetryBudget xollm.RetryBudgetStats // Retries spent from the batch's shared budget
 }
 
+// Retry settings for jobs that fail with a retryable error (see xollm.IsRetryable)
+const maxJobAttempts = 3
+
+var jobRetryDelay = 2 * time.Second
+
 // BatchProcessor manages concurrent processing of multiple LLM jobs
 type BatchProcessor struct {
 	config      config.Config   // LLM configuration
+	pool        *xollm.Pool     // Client shared by all workers
 	workerCount int             // Number of concurrent workers
 	stats       BatchStatistics // Processing statistics
 	mutex       sync.RWMutex    // For thread-safe access to statistics
+
+	maxRetries     int                // Retries allowed per batch run, 0 for no limit
+	maxRetryTokens int                // Prompt tokens retries may resend per batch run, 0 for no limit
+	retryBudget    *xollm.RetryBudget // Budget of the current batch run
+
+	drainer xollm.Drainer // Tracks jobs in flight for Shutdown
 }
 
 // NewBatchProcessor creates a new batch processor with the specified number of workers
@@ -60,6 +76,7 @@ func NewBatchProcessor(cfg config.Config, workerCount int) *BatchProcessor {
 
 	return &BatchProcessor{
 		config:      cfg,
+		pool:        xollm.NewPool(cfg, false),
 		workerCount: workerCount,
 		stats: BatchStatistics{
 			WorkerCount: workerCount,
@@ -67,6 +84,15 @@ func NewBatchProcessor(cfg config.Config, workerCount int) *BatchProcessor {
 	}
 }
 
+// SetRetryBudget limits the retries of each batch run to maxRetries in total,
+// resending at most maxRetryTokens prompt tokens. Zero means no limit.
+func (bp *BatchProcessor) SetRetryBudget(maxRetries, maxRetryTokens int) {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	bp.maxRetries = maxRetries
+	bp.maxRetryTokens = maxRetryTokens
+}
+
 // GetWorkerCount returns the number of workers configured for this processor
 func (bp *BatchProcessor) GetWorkerCount() int {
 	bp.mutex.RLock()
@@ -92,7 +118,11 @@ func (bp *BatchProcessor) GetErrorCount() int {
 func (bp *BatchProcessor) GetStatistics() BatchStatistics {
 	bp.mutex.RLock()
 	defer bp.mutex.RUnlock()
-	return bp.stats
+	stats := bp.stats
+	if bp.retryBudget != nil {
+		stats.RetryBudget = bp.retryBudget.Stats()
+	}
+	return stats
 }
 
 // ProcessJobs processes a batch of jobs concurrently using the configured number of workers
@@ -108,6 +138,8 @@ func (bp *BatchProcessor) ProcessJobs(ctx context.Context, jobs []BatchJob) ([]B
 		WorkerCount: bp.workerCount,
 		StartTime:   time.Now(),
 	}
+	budget := xollm.NewRetryBudget(bp.maxRetries, bp.maxRetryTokens)
+	bp.retryBudget = budget
 	bp.mutex.Unlock()
 
 	// Create channels for job distribution and result collection
@@ -118,7 +150,7 @@ func (bp *BatchProcessor) ProcessJobs(ctx context.Context, jobs []BatchJob) ([]B
 	var wg sync.WaitGroup
 	for i := 0; i < bp.workerCount; i++ {
 		wg.Add(1)
-		go bp.worker(ctx, i+1, jobChan, resultChan, &wg)
+		go bp.worker(ctx, i+1, budget, jobChan, resultChan, &wg)
 	}
 
 	// Send jobs to workers
@@ -157,6 +189,7 @@ func (bp *BatchProcessor) ProcessJobs(ctx context.Context, jobs []BatchJob) ([]B
 	// Finalize statistics
 	bp.mutex.Lock()
 	bp.stats.EndTime = time.Now()
+	bp.stats.RetryBudget = budget.Stats()
 	if bp.stats.TotalJobs > 0 {
 		bp.stats.AverageDuration = bp.stats.TotalDuration / time.Duration(bp.stats.TotalJobs)
 	}
@@ -166,11 +199,11 @@ func (bp *BatchProcessor) ProcessJobs(ctx context.Context, jobs []BatchJob) ([]B
 }
 
 // worker processes jobs from the job channel and sends results to the result channel
-func (bp *BatchProcessor) worker(ctx context.Context, workerID int, jobChan <-chan BatchJob, resultChan chan<- BatchResult, wg *sync.WaitGroup) {
+func (bp *BatchProcessor) worker(ctx context.Context, workerID int, budget *xollm.RetryBudget, jobChan <-chan BatchJob, resultChan chan<- BatchResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	// Create LLM client for this worker
-	client, err := xollm.GetClient(bp.config, false)
+	// Get the LLM client shared by all workers
+	client, err := bp.pool.Default()
 	if err != nil {
 		// Send error result for any jobs this worker would have processed
 		for job := range jobChan {
@@ -182,7 +215,6 @@ func (bp *BatchProcessor) worker(ctx context.Context, workerID int, jobChan <-ch
 		}
 		return
 	}
-	defer client.Close()
 
 	// Process jobs
 	for {
@@ -192,9 +224,21 @@ func (bp *BatchProcessor) worker(ctx context.Context, workerID int, jobChan <-ch
 				return // Channel closed, no more jobs
 			}
 
+			// Jobs not started before a shutdown are reported as failed
+			jobCtx, done, err := bp.drainer.Begin(ctx)
+			if err != nil {
+				select {
+				case resultChan <- BatchResult{Job: job, Error: err, Worker: workerID}:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
 			start := time.Now()
-			response, genErr := client.Generate(ctx, job.Prompt)
+			response, attempts, genErr := generateWithRetry(jobCtx, client, job.Prompt, budget)
 			duration := time.Since(start)
+			done()
 
 			result := BatchResult{
 				Job:      job,
@@ -202,6 +246,7 @@ func (bp *BatchProcessor) worker(ctx context.Context, workerID int, jobChan <-ch
 				Duration: duration,
 				Error:    genErr,
 				Worker:   workerID,
+				Attempts: attempts,
 			}
 
 			select {
@@ -216,10 +261,41 @@ func (bp *BatchProcessor) worker(ctx context.Context, workerID int, jobChan <-ch
 	}
 }
 
+// generateWithRetry calls Generate, retrying rate limits, timeouts and other
+// transient failures with a linear backoff while the batch's retry budget
+// lasts. Fatal and unclassified errors are returned immediately.
+func generateWithRetry(ctx context.Context, client xollm.Client, prompt string, budget *xollm.RetryBudget) (string, int, error) {
+	var err error
+	for attempt := 1; attempt <= maxJobAttempts; attempt++ {
+		var response string
+		response, err = client.Generate(ctx, prompt)
+		if err == nil || !xollm.IsRetryable(err) || attempt == maxJobAttempts {
+			return response, attempt, err
+		}
+		if !budget.Allow(xollm.EstimateTokensFor(client, prompt)) {
+			return "", attempt, fmt.Errorf("retry budget exhausted: %w", err)
+		}
+
+		select {
+		case <-time.After(time.Duration(attempt) * jobRetryDelay):
+		case <-ctx.Done():
+			return "", attempt, err
+		}
+	}
+	return "", maxJobAttempts, err
+}
+
+// Shutdown stops the processor from starting new jobs, waits for the jobs in
+// flight until ctx is done, then cancels the rest. Jobs that didn't start
+// are reported as failed with xollm.ErrShuttingDown, so a running
+// ProcessJobs returns promptly with every job accounted for.
+func (bp *BatchProcessor) Shutdown(ctx context.Context) error {
+	return bp.drainer.Shutdown(ctx)
+}
+
 // Close cleans up resources used by the batch processor
 func (bp *BatchProcessor) Close() error {
-	// Nothing to clean up for the processor itself
-	return nil
+	return bp.pool.Close()
 }
 
 // createJobsFromPrompts creates a slice of BatchJob from a slice of prompt strings
@@ -276,6 +352,7 @@ func generateReport(results []BatchResult, stats BatchStatistics) string {
 	report.WriteString(fmt.Sprintf("Failed: %d\n", stats.FailedJobs))
 	report.WriteString(fmt.Sprintf("Success rate: %.1f%%\n", float64(stats.CompletedJobs)/float64(stats.TotalJobs)*100))
 	report.WriteString(fmt.Sprintf("Workers: %d\n", stats.WorkerCount))
+	report.WriteString(fmt.Sprintf("Retries: %s\n", formatRetryBudget(stats.RetryBudget)))
 	report.WriteString("\n")
 
 	// Performance section
@@ -328,6 +405,21 @@ func generateReport(results []BatchResult, stats BatchStatistics) string {
 	return report.String()
 }
 
+// formatRetryBudget describes the retries spent against their limits
+func formatRetryBudget(b xollm.RetryBudgetStats) string {
+	limit := func(n int) string {
+		if n <= 0 {
+			return "unlimited"
+		}
+		return fmt.Sprint(n)
+	}
+	s := fmt.Sprintf("%d of %s (%d of %s tokens)", b.Retries, limit(b.MaxRetries), b.Tokens, limit(b.MaxTokens))
+	if b.Exhausted() {
+		s += fmt.Sprintf(", budget exhausted: %d retries denied", b.Denied)
+	}
+	return s
+}
+
 // saveResultsToFile saves batch results to a JSON file
 func saveResultsToFile(results []BatchResult, filename string) error {
 	file, err := os.Create(filename)
@@ -376,6 +468,8 @@ func demonstrateBatchProcessing() error {
 	reportFile := flag.String("report", "", "File to save human-readable report")
 	debug := flag.Bool("debug", false, "Enable debug mode")
 	showProgress := flag.Bool("progress", true, "Show progress during processing")
+	maxRetries := flag.Int("max-retries", 0, "Total retries allowed across the batch (0 for no limit)")
+	maxRetryTokens := flag.Int("max-retry-tokens", 0, "Total prompt tokens retries may resend across the batch (0 for no limit)")
 	flag.Parse()
 
 	// Create configuration
@@ -460,6 +554,7 @@ func demonstrateBatchProcessing() error {
 	// Create batch processor
 	processor := NewBatchProcessor(cfg, *workers)
 	defer processor.Close()
+	processor.SetRetryBudget(*maxRetries, *maxRetryTokens)
 
 	fmt.Printf("Processing %d jobs with %d workers using %s provider...\n",
 		len(jobs), *workers, cfg.DefaultProvider)
@@ -472,6 +567,20 @@ func demonstrateBatchProcessing() error {
 		defer cancel()
 	}
 
+	// On Ctrl-C, let the jobs in flight finish instead of abandoning them
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		<-interrupt
+		fmt.Println("\nInterrupted, waiting up to 10s for jobs in flight...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := processor.Shutdown(shutdownCtx); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}()
+
 	// Show progress if requested
 	var progressTicker *time.Ticker
 	if *showProgress {
@@ -513,6 +622,9 @@ func demonstrateBatchProcessing() error {
 	if stats.FailedJobs > 0 {
 		fmt.Printf("Failed: %d jobs\n", stats.FailedJobs)
 	}
+	if stats.RetryBudget.Retries > 0 || stats.RetryBudget.Exhausted() {
+		fmt.Printf("Retries: %s\n", formatRetryBudget(stats.RetryBudget))
+	}
 
 	// Save results to file if requested
 	if *outputFile != "" {
@@ -573,3 +573,163 @@ func writeStringToFile(filename, content string) error {
 	_, err = file.WriteString(content)
 	return err
 }
+
+func TestGenerateWithRetry(t *testing.T) {
+	originalDelay := jobRetryDelay
+	jobRetryDelay = time.Millisecond
+	defer func() { jobRetryDelay = originalDelay }()
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		calls := 0
+		client := &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			calls++
+			if calls < 3 {
+				return "", fmt.Errorf("busy: %w", xollm.ErrRateLimited)
+			}
+			return "done", nil
+		}}
+
+		response, attempts, err := generateWithRetry(context.Background(), client, "prompt", nil)
+		if err != nil || response != "done" {
+			t.Fatalf("Expected success after retries, got '%s' (%v)", response, err)
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("does not retry fatal errors", func(t *testing.T) {
+		calls := 0
+		client := &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			calls++
+			return "", fmt.Errorf("bad key: %w", xollm.ErrAuthentication)
+		}}
+
+		_, attempts, err := generateWithRetry(context.Background(), client, "prompt", nil)
+		if !errors.Is(err, xollm.ErrAuthentication) || attempts != 1 || calls != 1 {
+			t.Errorf("Expected a single failed attempt, got %d attempts (%v)", attempts, err)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		client := &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			return "", xollm.ErrTimeout
+		}}
+
+		_, attempts, err := generateWithRetry(context.Background(), client, "prompt", nil)
+		if !errors.Is(err, xollm.ErrTimeout) || attempts != maxJobAttempts {
+			t.Errorf("Expected %d attempts ending in timeout, got %d (%v)", maxJobAttempts, attempts, err)
+		}
+	})
+}
+
+func TestGenerateWithRetry_Budget(t *testing.T) {
+	originalDelay := jobRetryDelay
+	jobRetryDelay = time.Millisecond
+	defer func() { jobRetryDelay = originalDelay }()
+
+	calls := 0
+	client := &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+		calls++
+		return "", xollm.ErrTimeout
+	}}
+	budget := xollm.NewRetryBudget(1, 0)
+
+	_, attempts, err := generateWithRetry(context.Background(), client, "prompt", budget)
+	if !errors.Is(err, xollm.ErrTimeout) || attempts != 2 || calls != 2 {
+		t.Errorf("Expected the budget to stop after one retry, got %d attempts (%v)", attempts, err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "retry budget exhausted") {
+		t.Errorf("Expected a budget error, got %v", err)
+	}
+
+	_, attempts, _ = generateWithRetry(context.Background(), client, "prompt", budget)
+	if attempts != 1 {
+		t.Errorf("Expected no retries once the budget is spent, got %d attempts", attempts)
+	}
+	if stats := budget.Stats(); stats.Retries != 1 || stats.Denied != 2 || !stats.Exhausted() {
+		t.Errorf("Unexpected budget stats: %+v", stats)
+	}
+}
+
+func TestBatchProcessorRetryBudget(t *testing.T) {
+	originalDelay := jobRetryDelay
+	jobRetryDelay = time.Millisecond
+	defer func() { jobRetryDelay = originalDelay }()
+
+	xollm.GetClient = func(cfg config.Config, debugMode bool) (xollm.Client, error) {
+		return &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			return "", xollm.ErrRateLimited
+		}}, nil
+	}
+	defer func() { xollm.GetClient = originalGetClient }()
+
+	processor := NewBatchProcessor(config.NewConfig("ollama", 30, nil), 2)
+	defer processor.Close()
+	processor.SetRetryBudget(3, 0)
+
+	jobs := createJobsFromPrompts([]string{"a", "b", "c", "d"})
+	if _, err := processor.ProcessJobs(context.Background(), jobs); err != nil {
+		t.Fatalf("ProcessJobs failed: %v", err)
+	}
+
+	stats := processor.GetStatistics()
+	if stats.FailedJobs != 4 || stats.RetryBudget.Retries != 3 || stats.RetryBudget.MaxRetries != 3 || !stats.RetryBudget.Exhausted() {
+		t.Errorf("Expected 3 retries across the batch, got %+v", stats)
+	}
+	if report := generateReport(nil, stats); !strings.Contains(report, "Retries: 3 of 3") || !strings.Contains(report, "budget exhausted") {
+		t.Errorf("Expected the report to show the retry budget, got:\n%s", report)
+	}
+}
+
+func TestBatchProcessorShutdown(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	xollm.GetClient = func(cfg config.Config, debugMode bool) (xollm.Client, error) {
+		return &mockClient{generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			started <- struct{}{}
+			<-release
+			return "done: " + prompt, nil
+		}}, nil
+	}
+	defer func() { xollm.GetClient = originalGetClient }()
+
+	processor := NewBatchProcessor(config.NewConfig("ollama", 30, nil), 2)
+	defer processor.Close()
+
+	jobs := createJobsFromPrompts([]string{"a", "b", "c", "d", "e"})
+	resultsCh := make(chan []BatchResult, 1)
+	go func() {
+		results, _ := processor.ProcessJobs(context.Background(), jobs)
+		resultsCh <- results
+	}()
+	<-started
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- processor.Shutdown(context.Background())
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Expected in-flight jobs to drain, got: %v", err)
+	}
+	results := <-resultsCh
+	if len(results) != len(jobs) {
+		t.Fatalf("Expected every job to be accounted for, got %d results", len(results))
+	}
+	completed, refused := 0, 0
+	for _, r := range results {
+		switch {
+		case r.Error == nil:
+			completed++
+		case errors.Is(r.Error, xollm.ErrShuttingDown):
+			refused++
+		}
+	}
+	if completed != 2 || refused != 3 {
+		t.Errorf("Expected the 2 in-flight jobs to complete and 3 to be refused, got %d and %d", completed, refused)
+	}
+}
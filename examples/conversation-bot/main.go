@@ -8,243 +8,25 @@ import (
 	"log"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/xostack/xollm"
+	"github.com/xostack/xollm/chat"
 	"github.com/xostack/xollm/config"
 )
 
-// ConversationMessage represents a single message in a conversation
-type ConversationMessage struct {
-	Role      string    // "user", "assistant", or "system"
-	Content   string    // The message content
-	Timestamp time.Time // When the message was created
-}
-
-// ConversationStatistics holds statistics about a conversation
-type ConversationStatistics struct {
-	TotalMessages        int           // Total number of messages
-	UserMessages         int           // Number of user messages
-	AssistantMessages    int           // Number of assistant messages
-	AverageMessageLength float64       // Average length of all messages
-	ConversationDuration time.Duration // Duration since first message
-	StartTime            time.Time     // When the conversation started
-}
-
-// Conversation manages a stateful conversation with an LLM
-type Conversation struct {
-	config       config.Config         // LLM configuration
-	client       xollm.Client          // LLM client instance
-	botName      string                // Name of the bot
-	systemPrompt string                // System prompt for the bot
-	messages     []ConversationMessage // Conversation history
-	maxHistory   int                   // Maximum number of messages to keep (0 = unlimited)
-	startTime    time.Time             // When the conversation started
-	mutex        sync.RWMutex          // For thread safety
-}
-
-// NewConversation creates a new conversation with default settings
-func NewConversation(cfg config.Config, botName string) *Conversation {
-	return &Conversation{
-		config:     cfg,
-		botName:    botName,
-		messages:   make([]ConversationMessage, 0),
-		maxHistory: 0, // Unlimited by default
-		startTime:  time.Now(),
-	}
-}
-
-// NewConversationWithSystem creates a new conversation with a system prompt
-func NewConversationWithSystem(cfg config.Config, botName, systemPrompt string) *Conversation {
-	conv := NewConversation(cfg, botName)
-	conv.systemPrompt = systemPrompt
-	return conv
-}
-
-// NewConversationWithMaxHistory creates a new conversation with a maximum history limit
-func NewConversationWithMaxHistory(cfg config.Config, botName string, maxHistory int) *Conversation {
-	conv := NewConversation(cfg, botName)
-	conv.maxHistory = maxHistory
-	return conv
-}
-
-// GetBotName returns the bot's name
-func (c *Conversation) GetBotName() string {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	return c.botName
-}
-
-// GetSystemPrompt returns the system prompt
-func (c *Conversation) GetSystemPrompt() string {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	return c.systemPrompt
-}
-
-// GetMessageCount returns the total number of messages in the conversation
-func (c *Conversation) GetMessageCount() int {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	return len(c.messages)
-}
-
-// GetHistory returns a copy of the conversation history
-func (c *Conversation) GetHistory() []ConversationMessage {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
-	// Return a copy to prevent external modification
-	history := make([]ConversationMessage, len(c.messages))
-	copy(history, c.messages)
-	return history
-}
-
-// ClearHistory clears the conversation history
-func (c *Conversation) ClearHistory() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	c.messages = make([]ConversationMessage, 0)
-}
-
-// SendMessage sends a message to the LLM and returns the response
-func (c *Conversation) SendMessage(ctx context.Context, userMessage string) (string, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// Create client if not already created
-	if c.client == nil {
-		client, err := xollm.GetClient(c.config, false)
-		if err != nil {
-			return "", fmt.Errorf("failed to create LLM client: %w", err)
-		}
-		c.client = client
-	}
-
-	// Build the full prompt with conversation context
-	prompt := c.buildPrompt(userMessage)
-
-	// Generate response
-	response, err := c.client.Generate(ctx, prompt)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate response: %w", err)
-	}
-
-	// Add user message to history
-	userMsg := ConversationMessage{
-		Role:      "user",
-		Content:   userMessage,
-		Timestamp: time.Now(),
-	}
-	c.messages = append(c.messages, userMsg)
-
-	// Add assistant response to history
-	assistantMsg := ConversationMessage{
-		Role:      "assistant",
-		Content:   response,
-		Timestamp: time.Now(),
-	}
-	c.messages = append(c.messages, assistantMsg)
-
-	// Trim history if needed
-	c.trimHistoryIfNeeded()
-
-	return response, nil
-}
-
-// buildPrompt constructs the full prompt including system prompt and conversation history
-func (c *Conversation) buildPrompt(userMessage string) string {
-	var prompt strings.Builder
-
-	// Add system prompt if present
-	if c.systemPrompt != "" {
-		prompt.WriteString("System: ")
-		prompt.WriteString(c.systemPrompt)
-		prompt.WriteString("\n\n")
-	}
-
-	// Add conversation history if present
-	if len(c.messages) > 0 {
-		prompt.WriteString("Previous conversation:\n")
-		prompt.WriteString(formatConversationHistory(c.messages))
-		prompt.WriteString("\n")
-	}
-
-	// Add current user message
-	prompt.WriteString("User: ")
-	prompt.WriteString(userMessage)
-	prompt.WriteString("\nAssistant:")
-
-	return prompt.String()
-}
-
-// trimHistoryIfNeeded removes old messages if the history exceeds the maximum limit
-func (c *Conversation) trimHistoryIfNeeded() {
-	if c.maxHistory <= 0 || len(c.messages) <= c.maxHistory {
-		return
-	}
-
-	// Remove oldest messages, keeping the most recent ones
-	toRemove := len(c.messages) - c.maxHistory
-	c.messages = c.messages[toRemove:]
-}
-
-// GetStatistics returns statistics about the conversation
-func (c *Conversation) GetStatistics() ConversationStatistics {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
-	stats := ConversationStatistics{
-		TotalMessages:        len(c.messages),
-		ConversationDuration: time.Since(c.startTime),
-		StartTime:            c.startTime,
-	}
-
-	if len(c.messages) == 0 {
-		return stats
-	}
-
-	var totalLength int
-	for _, msg := range c.messages {
-		totalLength += len(msg.Content)
-		switch msg.Role {
-		case "user":
-			stats.UserMessages++
-		case "assistant":
-			stats.AssistantMessages++
-		}
-	}
-
-	stats.AverageMessageLength = float64(totalLength) / float64(len(c.messages))
-	return stats
-}
-
-// Close cleans up the conversation resources
-func (c *Conversation) Close() error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	if c.client != nil {
-		err := c.client.Close()
-		c.client = nil
-		return err
-	}
-	return nil
-}
-
 // formatConversationHistory formats a list of messages into a readable string
-func formatConversationHistory(messages []ConversationMessage) string {
+func formatConversationHistory(messages []chat.Message) string {
 	var formatted strings.Builder
 
 	for _, msg := range messages {
 		switch msg.Role {
-		case "user":
+		case xollm.RoleUser:
 			formatted.WriteString("User: ")
-		case "assistant":
+		case xollm.RoleAssistant:
 			formatted.WriteString("Assistant: ")
-		case "system":
-			continue // Skip system messages in history
+		default:
+			continue // Only user and assistant turns are shown
 		}
 		formatted.WriteString(msg.Content)
 		formatted.WriteString("\n")
@@ -274,8 +56,8 @@ func createBotPersonality(personalityType string) string {
 }
 
 // Interactive conversation loop
-func runInteractiveConversation(conv *Conversation) error {
-	fmt.Printf("Starting conversation with %s\n", conv.GetBotName())
+func runInteractiveConversation(conv *chat.Conversation, botName string) error {
+	fmt.Printf("Starting conversation with %s\n", botName)
 	fmt.Println("Type 'quit', 'exit', or 'bye' to end the conversation")
 	fmt.Println("Type '/help' for available commands")
 	fmt.Println(strings.Repeat("-", 50))
@@ -306,7 +88,7 @@ func runInteractiveConversation(conv *Conversation) error {
 			printConversationStats(conv)
 			continue
 		case "/history":
-			printConversationHistory(conv)
+			printConversationHistory(conv, botName)
 			continue
 		case "/clear":
 			conv.ClearHistory()
@@ -315,7 +97,7 @@ func runInteractiveConversation(conv *Conversation) error {
 		}
 
 		// Send message to bot
-		fmt.Printf("%s: ", conv.GetBotName())
+		fmt.Printf("%s: ", botName)
 		response, err := conv.SendMessage(ctx, input)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -339,8 +121,8 @@ func printHelpCommands() {
 }
 
 // printConversationStats prints conversation statistics
-func printConversationStats(conv *Conversation) {
-	stats := conv.GetStatistics()
+func printConversationStats(conv *chat.Conversation) {
+	stats := conv.Statistics()
 	fmt.Printf("\nConversation Statistics:\n")
 	fmt.Printf("  Total messages: %d\n", stats.TotalMessages)
 	fmt.Printf("  Your messages: %d\n", stats.UserMessages)
@@ -348,11 +130,21 @@ func printConversationStats(conv *Conversation) {
 	fmt.Printf("  Average message length: %.1f characters\n", stats.AverageMessageLength)
 	fmt.Printf("  Conversation duration: %v\n", stats.ConversationDuration.Round(time.Second))
 	fmt.Printf("  Started at: %s\n", stats.StartTime.Format("15:04:05"))
+	tokensNote := ""
+	if stats.TokensEstimated {
+		tokensNote = " (estimated)"
+	}
+	fmt.Printf("  Tokens: %d prompt, %d completion%s\n", stats.PromptTokens, stats.CompletionTokens, tokensNote)
+	if stats.CostKnown {
+		fmt.Printf("  Estimated cost: $%.6f\n", stats.EstimatedCostUSD)
+	} else {
+		fmt.Printf("  Estimated cost: unknown (no pricing for this model)\n")
+	}
 }
 
 // printConversationHistory prints the conversation history
-func printConversationHistory(conv *Conversation) {
-	history := conv.GetHistory()
+func printConversationHistory(conv *chat.Conversation, botName string) {
+	history := conv.History()
 	if len(history) == 0 {
 		fmt.Println("\nNo conversation history.")
 		return
@@ -364,10 +156,10 @@ func printConversationHistory(conv *Conversation) {
 	for _, msg := range history {
 		timestamp := msg.Timestamp.Format("15:04:05")
 		switch msg.Role {
-		case "user":
+		case xollm.RoleUser:
 			fmt.Printf("[%s] You: %s\n", timestamp, msg.Content)
-		case "assistant":
-			fmt.Printf("[%s] %s: %s\n", timestamp, conv.GetBotName(), msg.Content)
+		case xollm.RoleAssistant:
+			fmt.Printf("[%s] %s: %s\n", timestamp, botName, msg.Content)
 		}
 	}
 }
@@ -421,16 +213,15 @@ func demonstrateConversationBot() error {
 		return fmt.Errorf("unsupported provider: %s", *provider)
 	}
 
+	client, err := xollm.GetClient(cfg, *debug)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	defer client.Close()
+
 	// Create conversation with system prompt based on personality
 	systemPrompt := createBotPersonality(*personality)
-	var conv *Conversation
-	if *maxHistory > 0 {
-		conv = NewConversationWithMaxHistory(cfg, *botName, *maxHistory)
-	} else {
-		conv = NewConversation(cfg, *botName)
-	}
-	conv.systemPrompt = systemPrompt
-	defer conv.Close()
+	conv := chat.New(client, chat.Options{SystemPrompt: systemPrompt, MaxHistory: *maxHistory})
 
 	if *debug {
 		fmt.Printf("Configuration:\n")
@@ -443,11 +234,11 @@ func demonstrateConversationBot() error {
 	}
 
 	if *testMode {
-		return runTestConversation(conv)
+		return runTestConversation(conv, *botName)
 	}
 
 	if *interactive {
-		return runInteractiveConversation(conv)
+		return runInteractiveConversation(conv, *botName)
 	}
 
 	// Single message mode
@@ -464,12 +255,12 @@ func demonstrateConversationBot() error {
 		return fmt.Errorf("conversation failed: %w", err)
 	}
 
-	fmt.Printf("%s: %s\n", conv.GetBotName(), response)
+	fmt.Printf("%s: %s\n", *botName, response)
 	return nil
 }
 
 // runTestConversation runs a predefined test conversation
-func runTestConversation(conv *Conversation) error {
+func runTestConversation(conv *chat.Conversation, botName string) error {
 	testMessages := []string{
 		"Hello, what's your name?",
 		"Can you remember what I just asked you?",
@@ -478,7 +269,7 @@ func runTestConversation(conv *Conversation) error {
 	}
 
 	ctx := context.Background()
-	fmt.Printf("Running test conversation with %s...\n\n", conv.GetBotName())
+	fmt.Printf("Running test conversation with %s...\n\n", botName)
 
 	for i, message := range testMessages {
 		fmt.Printf("Turn %d\n", i+1)
@@ -489,7 +280,7 @@ func runTestConversation(conv *Conversation) error {
 			return fmt.Errorf("failed at turn %d: %w", i+1, err)
 		}
 
-		fmt.Printf("%s: %s\n\n", conv.GetBotName(), response)
+		fmt.Printf("%s: %s\n\n", botName, response)
 
 		// Brief pause between messages
 		time.Sleep(500 * time.Millisecond)
@@ -8,7 +8,6 @@ import (
 	"os"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/xostack/xollm"
@@ -44,64 +43,49 @@ func compareProviders(providers []string, configs map[string]config.Config, prom
 }
 
 // compareProvidersWithContext is like compareProviders but allows specifying a context for timeout/cancellation.
+// Clients are created up front; xollm.GenerateAll then queries them concurrently.
 func compareProvidersWithContext(ctx context.Context, providers []string, configs map[string]config.Config, prompt string) (map[string]ProviderResult, error) {
 	results := make(map[string]ProviderResult)
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	clients := make(map[string]xollm.Client)
 
-	// Process providers concurrently for better performance
-	for _, provider := range providers {
-		wg.Add(1)
-		go func(providerName string) {
-			defer wg.Done()
-
-			result := ProviderResult{
+	for _, providerName := range providers {
+		// Get the configuration for this provider
+		cfg, exists := configs[providerName]
+		if !exists {
+			results[providerName] = ProviderResult{
 				Provider: providerName,
+				Error:    fmt.Errorf("configuration not found for provider: %s", providerName),
 			}
+			continue
+		}
 
-			// Get the configuration for this provider
-			cfg, exists := configs[providerName]
-			if !exists {
-				result.Error = fmt.Errorf("configuration not found for provider: %s", providerName)
-				mu.Lock()
-				results[providerName] = result
-				mu.Unlock()
-				return
-			}
-
-			// Measure the time taken for the entire operation
-			start := time.Now()
-
-			// Create client for this provider
-			client, err := xollm.GetClient(cfg, false)
-			if err != nil {
-				result.Error = fmt.Errorf("failed to create client for %s: %w", providerName, err)
-				result.Duration = time.Since(start)
-				mu.Lock()
-				results[providerName] = result
-				mu.Unlock()
-				return
-			}
-			defer client.Close()
-
-			// Generate response
-			response, err := client.Generate(ctx, prompt)
-			result.Duration = time.Since(start)
-
-			if err != nil {
-				result.Error = fmt.Errorf("generation failed for %s: %w", providerName, err)
-			} else {
-				result.Response = response
+		// Create client for this provider
+		start := time.Now()
+		client, err := xollm.GetClient(cfg, false)
+		if err != nil {
+			results[providerName] = ProviderResult{
+				Provider: providerName,
+				Duration: time.Since(start),
+				Error:    fmt.Errorf("failed to create client for %s: %w", providerName, err),
 			}
-
-			mu.Lock()
-			results[providerName] = result
-			mu.Unlock()
-		}(provider)
+			continue
+		}
+		defer client.Close()
+		clients[providerName] = client
 	}
 
-	// Wait for all providers to complete
-	wg.Wait()
+	// Generate responses from every client concurrently
+	for providerName, r := range xollm.GenerateAll(ctx, clients, prompt) {
+		result := ProviderResult{
+			Provider: providerName,
+			Response: r.Text,
+			Duration: r.Latency,
+		}
+		if r.Err != nil {
+			result.Error = fmt.Errorf("generation failed for %s: %w", providerName, r.Err)
+		}
+		results[providerName] = result
+	}
 
 	return results, nil
 }
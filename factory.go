@@ -20,7 +20,9 @@ import (
 //
 // Parameters:
 //   - cfg: Configuration containing provider settings and credentials
-//   - debugMode: Whether to enable verbose logging for debugging
+//   - debugMode: Deprecated, pass false. True logs debug messages to
+//     standard error; use SetDefaultLogger to choose where the clients
+//     log instead
 //
 // Returns:
 //   - Client: A provider-specific client implementing the Client interface
@@ -29,7 +31,7 @@ import (
 // Supported providers:
 //   - "gemini": Google Gemini (requires APIKey)
 //   - "groq": Groq (requires APIKey)
-//   - "ollama": Ollama (requires BaseURL)
+//   - "ollama": Ollama (requires BaseURL or BaseURLs)
 //
 // Example:
 //
@@ -64,27 +66,78 @@ var GetClient func(cfg config.Config, debugMode bool) (Client, error) = func(cfg
 		return nil, fmt.Errorf("configuration for provider '%s' not found", providerName)
 	}
 
+	model := resolveModel(cfg, providerName, llmCfg.Model)
+
 	requestTimeout := cfg.RequestTimeoutSeconds
 	if requestTimeout <= 0 {
 		requestTimeout = 60 // Default to 60 seconds if not set or invalid
 	}
 
+	var opts []ClientOption
+	if cfg.PreflightTokenCheck {
+		opts = append(opts, WithPreflightTokenCheck())
+	}
+	if cfg.LazyInit {
+		opts = append(opts, WithLazyInit())
+	}
+	if llmCfg.SystemPrompt != "" {
+		opts = append(opts, WithSystemPrompt(llmCfg.SystemPrompt))
+	}
+	if llmCfg.EmbeddingModel != "" {
+		opts = append(opts, WithEmbeddingModel(llmCfg.EmbeddingModel))
+	}
+	if llmCfg.TranscriptionModel != "" {
+		opts = append(opts, WithTranscriptionModel(llmCfg.TranscriptionModel))
+	}
+	if llmCfg.ImageModel != "" {
+		opts = append(opts, WithImageModel(llmCfg.ImageModel))
+	}
+	if llmCfg.Temperature != nil || llmCfg.MaxTokens != nil || llmCfg.TopP != nil || llmCfg.Stop != nil || llmCfg.Seed != nil ||
+		llmCfg.PresencePenalty != nil || llmCfg.FrequencyPenalty != nil {
+		opts = append(opts, WithSampling(Sampling{
+			Temperature:      llmCfg.Temperature,
+			MaxTokens:        llmCfg.MaxTokens,
+			TopP:             llmCfg.TopP,
+			Stop:             llmCfg.Stop,
+			Seed:             llmCfg.Seed,
+			PresencePenalty:  llmCfg.PresencePenalty,
+			FrequencyPenalty: llmCfg.FrequencyPenalty,
+		}))
+	}
+
+	if llmCfg.Options != (RuntimeOptions{}) {
+		opts = append(opts, WithRuntimeOptions(llmCfg.Options))
+	}
+	if llmCfg.Organization != "" || llmCfg.Project != "" {
+		opts = append(opts, WithOrganization(llmCfg.Organization, llmCfg.Project))
+	}
+	if llmCfg.StickySessions {
+		opts = append(opts, WithStickySessions())
+	}
+	if hook := requestHook(providerName); hook != nil {
+		opts = append(opts, WithRequestHook(hook))
+	}
+
 	switch providerName {
 	case "gemini":
 		if llmCfg.APIKey == "" {
 			return nil, fmt.Errorf("API key for Gemini not found in configuration")
 		}
-		return gemini.NewClient(context.Background(), llmCfg.APIKey, llmCfg.Model, requestTimeout, debugMode)
+		return gemini.NewClient(context.Background(), llmCfg.APIKey, model, requestTimeout, debugMode, opts...)
 	case "ollama":
-		if llmCfg.BaseURL == "" {
+		baseURLs := llmCfg.BaseURLs
+		if llmCfg.BaseURL != "" {
+			baseURLs = append([]string{llmCfg.BaseURL}, baseURLs...)
+		}
+		if len(baseURLs) == 0 {
 			return nil, fmt.Errorf("base URL for Ollama not found in configuration")
 		}
-		return ollama.NewClient(context.Background(), llmCfg.BaseURL, llmCfg.Model, requestTimeout, debugMode)
+		return ollama.NewBalancedClient(context.Background(), baseURLs, model, requestTimeout, debugMode, opts...)
 	case "groq":
 		if llmCfg.APIKey == "" {
 			return nil, fmt.Errorf("API key for Groq not found in configuration")
 		}
-		return groq.NewClient(context.Background(), llmCfg.APIKey, llmCfg.Model, requestTimeout, debugMode)
+		return groq.NewClient(context.Background(), llmCfg.APIKey, model, requestTimeout, debugMode, opts...)
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", providerName)
 	}
@@ -1,9 +1,16 @@
 package xollm
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/xostack/xollm/config"
+	"github.com/xostack/xollm/ollama"
 )
 
 func TestGetClient_Gemini(t *testing.T) {
@@ -393,3 +400,101 @@ func TestClient_CloseIsIdempotent(t *testing.T) {
 		t.Errorf("Expected second Close() to succeed (idempotent), got error: %v", err)
 	}
 }
+
+func TestGetClient_PreflightTokenCheck(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig("ollama", 30, map[string]config.LLMConfig{
+		"ollama": {BaseURL: server.URL, Model: "gemma:2b"},
+	})
+	cfg.PreflightTokenCheck = true
+
+	client, err := GetClient(cfg, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer client.Close()
+
+	_, err = client.Generate(context.Background(), strings.Repeat("word ", 10000))
+	var clErr *ContextLengthError
+	if !errors.As(err, &clErr) || !clErr.Estimated {
+		t.Errorf("Expected estimated *ContextLengthError, got: %v", err)
+	}
+	if requests != 0 {
+		t.Error("Expected the oversized prompt not to be sent")
+	}
+
+	if _, err := client.Generate(context.Background(), "short"); err != nil || requests != 1 {
+		t.Errorf("Expected short prompt to be sent, got %v after %d requests", err, requests)
+	}
+}
+
+func TestGetClient_Sampling(t *testing.T) {
+	var sent struct {
+		Options map[string]interface{} `json:"options"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig("ollama", 30, map[string]config.LLMConfig{
+		"ollama": {
+			BaseURL:         server.URL,
+			Temperature:     Float64(0.2),
+			Stop:            []string{"\n\nUser:", "###"},
+			PresencePenalty: Float64(0.3),
+			Options:         RuntimeOptions{RepeatPenalty: Float64(1.15)},
+		},
+	})
+	client, err := GetClient(cfg, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Generate(context.Background(), "Hi"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent.Options["temperature"] != 0.2 {
+		t.Errorf("Expected the configured temperature, got %v", sent.Options["temperature"])
+	}
+	if stop, ok := sent.Options["stop"].([]interface{}); !ok || len(stop) != 2 || stop[0] != "\n\nUser:" || stop[1] != "###" {
+		t.Errorf("Expected the configured stop sequences, got %v", sent.Options["stop"])
+	}
+	if sent.Options["presence_penalty"] != 0.3 || sent.Options["repeat_penalty"] != 1.15 {
+		t.Errorf("Expected the configured penalties, got %v", sent.Options)
+	}
+}
+
+func TestGetClient_OllamaBaseURLs(t *testing.T) {
+	cfg := config.Config{
+		DefaultProvider: "ollama",
+		LLMs: map[string]config.LLMConfig{
+			"ollama": {
+				BaseURL:        "http://gpu-1:11434",
+				BaseURLs:       []string{"http://gpu-2:11434"},
+				StickySessions: true,
+			},
+		},
+	}
+	client, err := GetClient(cfg, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	hosts := client.(*ollama.Client).Hosts()
+	if len(hosts) != 2 || hosts[0].BaseURL != "http://gpu-1:11434" || hosts[1].BaseURL != "http://gpu-2:11434" {
+		t.Errorf("Expected both servers, got %+v", hosts)
+	}
+
+	cfg.LLMs["ollama"] = config.LLMConfig{BaseURLs: []string{"http://gpu-2:11434"}}
+	if _, err := GetClient(cfg, false); err != nil {
+		t.Errorf("Expected base_urls alone to suffice, got: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package xollm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of a generation: the text with its metadata and
+// latency, or the error.
+type Result struct {
+	// Provider is the provider name of the client that generated it.
+	Provider string
+	// Text is the generated text.
+	Text string
+	// Metadata holds the model, finish reason and token usage, estimated
+	// for clients that don't report them. It is nil if the call failed.
+	Metadata *ResponseMetadata
+	// Latency is how long the call took.
+	Latency time.Duration
+	// Err is the call's error, if it failed.
+	Err error
+}
+
+// FanOutOption configures GenerateAll.
+type FanOutOption func(*fanOutOptions)
+
+// fanOutOptions holds the settings of GenerateAll.
+type fanOutOptions struct {
+	timeout  time.Duration
+	timeouts map[string]time.Duration
+}
+
+// WithClientTimeout bounds each client's call to d, so one slow provider
+// doesn't hold up the others' results.
+func WithClientTimeout(d time.Duration) FanOutOption {
+	return func(o *fanOutOptions) {
+		o.timeout = d
+	}
+}
+
+// WithClientTimeouts bounds the calls of the clients named in timeouts,
+// overriding WithClientTimeout for them.
+func WithClientTimeouts(timeouts map[string]time.Duration) FanOutOption {
+	return func(o *fanOutOptions) {
+		o.timeouts = timeouts
+	}
+}
+
+// GenerateAll sends prompt to every client concurrently and returns their
+// results keyed like clients, to compare or cross-check answers. Failures
+// are reported in each Result's Err; GenerateAll itself doesn't fail.
+//
+//	results := xollm.GenerateAll(ctx, map[string]xollm.Client{"gemini": gemini, "groq": groq}, prompt,
+//		xollm.WithClientTimeout(20*time.Second))
+//	for name, r := range results {
+//		fmt.Println(name, r.Latency, r.Text, r.Err)
+//	}
+func GenerateAll(ctx context.Context, clients map[string]Client, prompt string, opts ...FanOutOption) map[string]Result {
+	var o fanOutOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	results := make(map[string]Result, len(clients))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, client := range clients {
+		wg.Add(1)
+		go func(name string, client Client) {
+			defer wg.Done()
+			callCtx := ctx
+			timeout, ok := o.timeouts[name]
+			if !ok {
+				timeout = o.timeout
+			}
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				callCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			result, _ := GenerateWithResult(callCtx, client, prompt)
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, client)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,68 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// slowClient answers after a delay unless its context ends first
+type slowClient struct {
+	stubClient
+	delay time.Duration
+}
+
+func (c *slowClient) Generate(ctx context.Context, prompt string) (string, error) {
+	select {
+	case <-time.After(c.delay):
+		return c.response, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestGenerateAll(t *testing.T) {
+	failure := errors.New("boom")
+	clients := map[string]Client{
+		"fast":   &stubClient{response: "fast answer"},
+		"failed": &stubClient{err: failure},
+		"slow":   &slowClient{stubClient: stubClient{response: "slow answer"}, delay: time.Second},
+	}
+
+	start := time.Now()
+	results := GenerateAll(context.Background(), clients, "prompt", WithClientTimeout(20*time.Millisecond))
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the timeout to bound the slow client, took %v", elapsed)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if r := results["fast"]; r.Err != nil || r.Text != "fast answer" || r.Provider != "stub" || r.Metadata == nil || !r.Metadata.Usage.Estimated {
+		t.Errorf("Unexpected fast result: %+v", r)
+	}
+	if r := results["failed"]; !errors.Is(r.Err, failure) || r.Metadata != nil {
+		t.Errorf("Expected the client's error, got %+v", r)
+	}
+	if r := results["slow"]; !errors.Is(r.Err, context.DeadlineExceeded) {
+		t.Errorf("Expected the slow client to time out, got %+v", r)
+	}
+}
+
+func TestGenerateAll_PerClientTimeouts(t *testing.T) {
+	clients := map[string]Client{
+		"patient": &slowClient{stubClient: stubClient{response: "done"}, delay: 30 * time.Millisecond},
+		"strict":  &slowClient{stubClient: stubClient{response: "done"}, delay: 30 * time.Millisecond},
+	}
+	results := GenerateAll(context.Background(), clients, "prompt",
+		WithClientTimeout(5*time.Millisecond),
+		WithClientTimeouts(map[string]time.Duration{"patient": time.Second}),
+	)
+	if r := results["patient"]; r.Err != nil || r.Text != "done" {
+		t.Errorf("Expected the override to give the client time, got %+v", r)
+	}
+	if r := results["strict"]; r.Err == nil {
+		t.Errorf("Expected the default timeout to apply, got %+v", r)
+	}
+}
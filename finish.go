@@ -0,0 +1,24 @@
+package xollm
+
+import "github.com/xostack/xollm/llm"
+
+// FinishKind is a provider-neutral reason for the end of a generation. See
+// llm.FinishKind.
+type FinishKind = llm.FinishKind
+
+const (
+	FinishUnknown       = llm.FinishUnknown
+	FinishStop          = llm.FinishStop
+	FinishLength        = llm.FinishLength
+	FinishContentFilter = llm.FinishContentFilter
+	FinishToolCalls     = llm.FinishToolCalls
+	FinishOther         = llm.FinishOther
+)
+
+// NormalizeFinishReason maps a provider's finish reason, as reported in
+// ResponseMetadata, ToolResponse and the Done chunk of a stream, to a
+// FinishKind, so callers can detect truncated or filtered responses the
+// same way for every provider.
+func NormalizeFinishReason(reason string) FinishKind {
+	return llm.NormalizeFinishReason(reason)
+}
@@ -0,0 +1,364 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+	"google.golang.org/api/googleapi"
+)
+
+// Cache operations, the Error.Op of their failures.
+const (
+	opCreateCache = "create cache"
+	opOpenCache   = "open cache"
+	opDeleteCache = "delete cache"
+
+	cachesEndpoint = "cachedContents"
+
+	// promptCacheTTL is the lifetime of caches created by GenerateCached;
+	// they are recreated when less than promptCacheRenewal remains.
+	promptCacheTTL     = 15 * time.Minute
+	promptCacheRenewal = time.Minute
+)
+
+// Cache is content stored on Gemini's servers with Gemini context caching.
+// Prompts sent through a Cache are answered as if they followed the cached
+// content, which is billed at a reduced rate and doesn't count towards
+// request latency, so workloads that send the same large context (a
+// document, a codebase, long instructions) with many prompts save on every
+// call.
+//
+// A Cache implements the same Client interface as the client it came
+// from. It expires after its TTL; Delete removes it earlier.
+type Cache struct {
+	client     *Client
+	name       string
+	expireTime time.Time
+	model      *genai.GenerativeModel
+}
+
+// cachedContent is the cachedContents resource of the Gemini REST API.
+type cachedContent struct {
+	Name              string         `json:"name,omitempty"`
+	Model             string         `json:"model,omitempty"`
+	Contents          []cacheContent `json:"contents,omitempty"`
+	SystemInstruction *cacheContent  `json:"systemInstruction,omitempty"`
+	TTL               string         `json:"ttl,omitempty"`
+	ExpireTime        *time.Time     `json:"expireTime,omitempty"`
+}
+
+// cacheContent is text content of a cachedContents resource.
+type cacheContent struct {
+	Role  string      `json:"role,omitempty"`
+	Parts []cachePart `json:"parts"`
+}
+
+// cachePart is a text part of cacheContent.
+type cachePart struct {
+	Text string `json:"text"`
+}
+
+// CreateCache stores content as a cached prefix for the client's model,
+// kept for ttl (the server's default, one hour, if ttl is zero). The
+// client's system prompt, if any, is cached along with it, since Gemini
+// doesn't accept a system instruction on requests that use a cache.
+//
+// Gemini requires a minimum amount of content, in the order of thousands
+// of tokens, for a cache to be created.
+//
+// Caches are managed over the REST API with the client's HTTP client, like
+// generation requests, so the client's API key, transport, dialer and
+// request hooks apply. (The SDK's own cache client would bypass them.)
+func (c *Client) CreateCache(ctx context.Context, content string, ttl time.Duration) (*Cache, error) {
+	sdk, err := c.sdkClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cc := cachedContent{
+		Model:    c.endpoint(),
+		Contents: []cacheContent{{Role: "user", Parts: []cachePart{{Text: content}}}},
+	}
+	if ttl > 0 {
+		cc.TTL = strconv.FormatFloat(ttl.Seconds(), 'f', -1, 64) + "s"
+	}
+	if c.options.SystemPrompt != "" {
+		cc.SystemInstruction = &cacheContent{Parts: []cachePart{{Text: c.options.SystemPrompt}}}
+	}
+	var created cachedContent
+	if err := c.cacheRequest(ctx, opCreateCache, http.MethodPost, cachesEndpoint, cc, &created); err != nil {
+		return nil, err
+	}
+	return c.newCache(sdk, &created), nil
+}
+
+// OpenCache returns the cache with the given name, e.g. one created by
+// another process and shared through configuration.
+func (c *Client) OpenCache(ctx context.Context, name string) (*Cache, error) {
+	sdk, err := c.sdkClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(name, cachesEndpoint+"/") {
+		name = cachesEndpoint + "/" + name
+	}
+	var found cachedContent
+	if err := c.cacheRequest(ctx, opOpenCache, http.MethodGet, name, nil, &found); err != nil {
+		return nil, err
+	}
+	return c.newCache(sdk, &found), nil
+}
+
+// cacheRequest sends a request for the cachedContents resource at path,
+// with payload as its JSON body unless nil, and decodes the response into
+// out unless nil. The client's request timeout applies. Failures are
+// returned as *llm.Error describing op.
+func (c *Client) cacheRequest(ctx context.Context, op, method, path string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return llm.NewError(providerName, op, path, fmt.Errorf("failed to marshal request: %w", err))
+		}
+		body = bytes.NewReader(encoded)
+	}
+	if timeout := c.requestTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, restURL(path), body)
+	if err != nil {
+		return llm.NewError(providerName, op, path, fmt.Errorf("failed to create request: %w", err))
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.apiHTTPClient().Do(req)
+	if err != nil {
+		return c.wrapOpError(op, path, fmt.Errorf("failed to send request: %w", err))
+	}
+	defer resp.Body.Close()
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return c.wrapOpError(op, path, err)
+	}
+	if out == nil {
+		return nil
+	}
+	if respBody, err := llm.DecodeJSON(resp.Body, out); err != nil {
+		decodeErr := llm.NewError(providerName, op, path, fmt.Errorf("failed to decode response: %w", err))
+		decodeErr.StatusCode, decodeErr.Body = resp.StatusCode, respBody
+		return decodeErr
+	}
+	return nil
+}
+
+// newCache returns a Cache for cc, generating with a model handle that
+// references it and the client's sampling parameters, like newModel.
+func (c *Client) newCache(sdk *genai.Client, cc *cachedContent) *Cache {
+	name := cc.Model
+	if name == "" {
+		name = c.endpoint()
+	}
+	model := sdk.GenerativeModelFromCachedContent(&genai.CachedContent{Name: cc.Name, Model: name})
+	setSampling(&model.GenerationConfig, c.options.Sampling)
+	cache := &Cache{
+		client: c,
+		name:   cc.Name,
+		model:  model,
+	}
+	if cc.ExpireTime != nil {
+		cache.expireTime = *cc.ExpireTime
+	}
+	return cache
+}
+
+// GenerateCached implements xollm.PromptCacher with context caching. The
+// leading cacheable parts are stored in a cache on first use and reused by
+// later calls with the same prefix until the cache nears expiry. Prefixes
+// Gemini won't cache, typically because they are below its minimum size,
+// are sent uncached.
+func (c *Client) GenerateCached(ctx context.Context, parts []llm.PromptPart) (string, llm.Usage, error) {
+	prefix, rest := llm.SplitCacheable(parts)
+	text, md, err := c.generateWithPrefix(ctx, prefix, rest)
+	if err != nil {
+		return "", llm.Usage{}, err
+	}
+	return text, md.Usage, nil
+}
+
+// generateWithPrefix sends prefix from its prompt cache, if Gemini accepts
+// caching it, followed by rest. The pre-flight check runs on the whole
+// prompt before any cache is created.
+func (c *Client) generateWithPrefix(ctx context.Context, prefix, rest string) (string, *llm.ResponseMetadata, error) {
+	if prefix == "" {
+		return c.generateUncached(ctx, rest)
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prefix+rest); err != nil {
+			return "", nil, err
+		}
+	}
+	cache, err := c.promptCache(ctx, prefix)
+	if err != nil {
+		return "", nil, err
+	}
+	if cache == nil {
+		return c.generateUncached(ctx, prefix+rest)
+	}
+	return c.generateWith(ctx, cache.model, rest)
+}
+
+// generateUncached is Generate, returning the metadata as well.
+func (c *Client) generateUncached(ctx context.Context, prompt string) (string, *llm.ResponseMetadata, error) {
+	model, err := c.generativeModel(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prompt); err != nil {
+			return "", nil, err
+		}
+	}
+	return c.generateWith(ctx, model, prompt)
+}
+
+// promptCacheEntry is a prompt cache created, or being created, by
+// GenerateCached.
+type promptCacheEntry struct {
+	ready chan struct{} // Closed once cache and err are set
+	cache *Cache        // nil if Gemini declined to cache the prefix
+	err   error
+}
+
+// renewable reports whether the entry's cache was created and is about to
+// expire.
+func (e *promptCacheEntry) renewable() bool {
+	select {
+	case <-e.ready:
+		return e.cache != nil && time.Until(e.cache.ExpireTime()) <= promptCacheRenewal
+	default:
+		return false
+	}
+}
+
+// promptCache returns the live cache for prefix, creating it if needed. It
+// returns nil if Gemini rejected caching the prefix. Concurrent calls for
+// the same prefix wait for a single creation instead of each creating a
+// cache, while calls for other prefixes don't wait at all.
+func (c *Client) promptCache(ctx context.Context, prefix string) (*Cache, error) {
+	sum := sha256.Sum256([]byte(prefix))
+	key := hex.EncodeToString(sum[:])
+
+	for {
+		c.cacheMu.Lock()
+		entry := c.promptCaches[key]
+		if entry == nil || entry.renewable() {
+			entry = &promptCacheEntry{ready: make(chan struct{})}
+			if c.promptCaches == nil {
+				c.promptCaches = make(map[string]*promptCacheEntry)
+			}
+			c.promptCaches[key] = entry
+			c.cacheMu.Unlock()
+			return c.createPromptCache(ctx, key, prefix, entry)
+		}
+		c.cacheMu.Unlock()
+
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.err == nil {
+			return entry.cache, nil
+		}
+		// The creation failed, possibly only for its caller's context, and
+		// was forgotten; try again
+	}
+}
+
+// createPromptCache creates the cache of entry and publishes the outcome.
+// A failed creation is removed so that later calls retry it.
+func (c *Client) createPromptCache(ctx context.Context, key, prefix string, entry *promptCacheEntry) (*Cache, error) {
+	defer close(entry.ready)
+	cache, err := c.CreateCache(ctx, prefix, promptCacheTTL)
+	var apiErr *llm.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		llm.Logger(ctx, c.logger).Debug("Gemini declined to cache prompt prefix, sending it uncached", "error", err)
+		cache, err = nil, nil
+	}
+	entry.cache, entry.err = cache, err
+	if err != nil {
+		c.cacheMu.Lock()
+		if c.promptCaches[key] == entry {
+			delete(c.promptCaches, key)
+		}
+		c.cacheMu.Unlock()
+		return nil, err
+	}
+	return cache, nil
+}
+
+// sdkClient returns the SDK client, initializing it first if needed.
+func (c *Client) sdkClient(ctx context.Context) (*genai.Client, error) {
+	if _, err := c.generativeModel(ctx); err != nil {
+		return nil, err
+	}
+	c.initMu.Lock()
+	defer c.initMu.Unlock()
+	if c.genaiClient == nil {
+		return nil, fmt.Errorf("Gemini client not initialized")
+	}
+	return c.genaiClient, nil
+}
+
+// Name returns the cache's resource name, "cachedContents/<id>", for use
+// with OpenCache.
+func (cc *Cache) Name() string {
+	return cc.name
+}
+
+// ExpireTime returns when the server will delete the cache.
+func (cc *Cache) ExpireTime() time.Time {
+	return cc.expireTime
+}
+
+// Generate sends the prompt following the cached content and returns the
+// text response.
+func (cc *Cache) Generate(ctx context.Context, prompt string) (string, error) {
+	if _, err := cc.client.sdkClient(ctx); err != nil {
+		return "", err
+	}
+	text, _, err := cc.client.generateWith(ctx, cc.model, prompt)
+	return text, err
+}
+
+// Delete removes the cache from the server before it expires.
+func (cc *Cache) Delete(ctx context.Context) error {
+	if _, err := cc.client.sdkClient(ctx); err != nil {
+		return err
+	}
+	return cc.client.cacheRequest(ctx, opDeleteCache, http.MethodDelete, cc.name, nil, nil)
+}
+
+// ProviderName returns the name of this provider.
+func (cc *Cache) ProviderName() string {
+	return providerName
+}
+
+// Close is a no-op; it neither deletes the cache nor closes the client.
+func (cc *Cache) Close() error {
+	return nil
+}
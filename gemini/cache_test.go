@@ -0,0 +1,349 @@
+package gemini
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+	"google.golang.org/api/googleapi"
+)
+
+func TestNewCache(t *testing.T) {
+	client, err := NewClient(context.Background(), "test-api-key", "gemini-1.5-flash-001", 30, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer client.Close()
+
+	expires := time.Now().Add(time.Hour)
+	cache := client.newCache(client.genaiClient, &cachedContent{
+		Name:       "cachedContents/abc123",
+		Model:      "models/gemini-1.5-flash-001",
+		ExpireTime: &expires,
+	})
+	if cache.Name() != "cachedContents/abc123" || !cache.ExpireTime().Equal(expires) {
+		t.Errorf("Unexpected cache metadata: %s, %v", cache.Name(), cache.ExpireTime())
+	}
+	if cache.model.CachedContentName != "cachedContents/abc123" {
+		t.Errorf("Expected the model to reference the cache, got %q", cache.model.CachedContentName)
+	}
+	if cache.ProviderName() != "gemini" {
+		t.Errorf("Expected provider 'gemini', got '%s'", cache.ProviderName())
+	}
+}
+
+func TestCache_ClosedClient(t *testing.T) {
+	client, err := NewClient(context.Background(), "test-api-key", "", 30, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	cache := client.newCache(client.genaiClient, &cachedContent{Name: "cachedContents/abc123"})
+	client.Close()
+
+	if _, err := client.CreateCache(context.Background(), "content", time.Hour); err == nil || err.Error() != "Gemini client not initialized" {
+		t.Errorf("Expected CreateCache on a closed client to fail, got: %v", err)
+	}
+	if _, err := cache.Generate(context.Background(), "hi"); err == nil || err.Error() != "Gemini client not initialized" {
+		t.Errorf("Expected Generate through a cache of a closed client to fail, got: %v", err)
+	}
+}
+
+func TestGeminiClient_CacheOverREST(t *testing.T) {
+	var calls []string
+	var created cachedContent
+	var cachedContentName string
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+r.Header.Get("x-goog-api-key"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1beta/cachedContents" && r.Method == http.MethodPost:
+			json.NewDecoder(r.Body).Decode(&created)
+			fallthrough
+		case r.URL.Path == "/v1beta/cachedContents/c1" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"name": "cachedContents/c1", "model": "models/gemini-1.5-flash", "expireTime": "2030-01-01T00:00:00Z"}`))
+		case r.URL.Path == "/v1beta/cachedContents/c1" && r.Method == http.MethodDelete:
+			w.Write([]byte(`{}`))
+		case strings.HasSuffix(r.URL.Path, ":generateContent"):
+			var sent struct {
+				CachedContent string `json:"cachedContent"`
+			}
+			json.NewDecoder(r.Body).Decode(&sent)
+			cachedContentName = sent.CachedContent
+			w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, llm.WithSystemPrompt("Be terse."))
+
+	cache, err := client.CreateCache(context.Background(), "A long document", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("CreateCache failed: %v", err)
+	}
+	if created.Model != "models/gemini-1.5-flash" || created.TTL != "900s" || created.Contents[0].Parts[0].Text != "A long document" ||
+		created.SystemInstruction == nil || created.SystemInstruction.Parts[0].Text != "Be terse." {
+		t.Errorf("Unexpected cache request %+v", created)
+	}
+	if cache.Name() != "cachedContents/c1" || cache.ExpireTime().Year() != 2030 {
+		t.Errorf("Unexpected cache %s expiring %v", cache.Name(), cache.ExpireTime())
+	}
+
+	// Every cache request uses the current key, so rotating it keeps the
+	// cache usable after the old key is revoked
+	if err := client.SetCredentials("rotated-key"); err != nil {
+		t.Fatalf("SetCredentials failed: %v", err)
+	}
+	if _, err := cache.Generate(context.Background(), "Summarize"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if cachedContentName != "cachedContents/c1" {
+		t.Errorf("Expected generation to reference the cache, got %q", cachedContentName)
+	}
+	opened, err := client.OpenCache(context.Background(), "c1")
+	if err != nil || opened.Name() != "cachedContents/c1" {
+		t.Fatalf("OpenCache failed: %v", err)
+	}
+	if err := opened.Delete(context.Background()); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	expected := []string{
+		"POST /v1beta/cachedContents test-api-key",
+		"POST /v1beta/models/gemini-1.5-flash:generateContent rotated-key",
+		"GET /v1beta/cachedContents/c1 rotated-key",
+		"DELETE /v1beta/cachedContents/c1 rotated-key",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected requests %v, got %v", expected, calls)
+	}
+}
+
+func TestGeminiClient_CacheSampling(t *testing.T) {
+	var sent struct {
+		CachedContent    string `json:"cachedContent"`
+		GenerationConfig struct {
+			Temperature     *float64 `json:"temperature"`
+			MaxOutputTokens *int     `json:"maxOutputTokens"`
+			TopP            *float64 `json:"topP"`
+			StopSequences   []string `json:"stopSequences"`
+		} `json:"generationConfig"`
+	}
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, ":generateContent") {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+			return
+		}
+		w.Write([]byte(`{"name": "cachedContents/c1", "model": "models/gemini-1.5-flash"}`))
+	}, llm.WithSampling(llm.Sampling{Temperature: llm.Float64(0.5), MaxTokens: llm.Int(128), TopP: llm.Float64(0.9), Stop: []string{"END"}}))
+
+	cache, err := client.OpenCache(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("OpenCache failed: %v", err)
+	}
+	if _, err := cache.Generate(context.Background(), "Summarize"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	config := sent.GenerationConfig
+	if sent.CachedContent != "cachedContents/c1" || config.Temperature == nil || *config.Temperature != 0.5 ||
+		config.MaxOutputTokens == nil || *config.MaxOutputTokens != 128 || config.TopP == nil || *config.TopP != 0.9 ||
+		!reflect.DeepEqual(config.StopSequences, []string{"END"}) {
+		t.Errorf("Expected the client's sampling on cached requests, got %+v", sent)
+	}
+}
+
+func TestGeminiClient_CacheUsesClientTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signed") != "yes" || !strings.Contains(r.Header.Get("User-Agent"), "xollm") {
+			t.Errorf("Expected a hooked request with the xollm User-Agent, got %v", r.Header)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "cachedContents/c1", "model": "models/gemini-1.5-flash"}`))
+	}))
+	defer server.Close()
+	original := apiEndpoint
+	apiEndpoint = "http://gemini.internal"
+	defer func() { apiEndpoint = original }()
+
+	// Egress goes through the dialer, here one reaching a name no DNS
+	// server knows
+	var dialed []string
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	client, err := NewClient(context.Background(), "test-api-key", "gemini-1.5-flash", 30, false, llm.WithDialer(dial),
+		llm.WithRequestHook(func(req *http.Request) error {
+			req.Header.Set("X-Signed", "yes")
+			return nil
+		}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	cache, err := client.CreateCache(context.Background(), "A long document", 0)
+	if err != nil {
+		t.Fatalf("CreateCache failed: %v", err)
+	}
+	if _, err := client.OpenCache(context.Background(), cache.Name()); err != nil {
+		t.Fatalf("OpenCache failed: %v", err)
+	}
+	if len(dialed) == 0 || dialed[0] != "gemini.internal:80" {
+		t.Errorf("Expected cache requests to be dialed by the client's dialer, got %v", dialed)
+	}
+
+	_, err = client.CreateCache(llm.WithDryRun(context.Background()), "A long document", time.Hour)
+	var dryRun *llm.DryRunError
+	if !errors.As(err, &dryRun) || !strings.HasSuffix(dryRun.Request.URL, "/v1beta/cachedContents") {
+		t.Errorf("Expected a dry run of the cache request, got %v", err)
+	}
+}
+
+func TestGeminiClient_GenerateCached_Declined(t *testing.T) {
+	var paths []string
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1beta/cachedContents" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": 400, "message": "Cached content is too small", "status": "INVALID_ARGUMENT"}}`))
+			return
+		}
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+	})
+
+	parts := []llm.PromptPart{{Text: "Short instructions.", Cacheable: true}, {Text: "Question"}}
+	for i := 0; i < 2; i++ {
+		if text, _, err := client.GenerateCached(context.Background(), parts); err != nil || text != "ok" {
+			t.Fatalf("Expected an uncached answer, got %q, %v", text, err)
+		}
+	}
+	// The declined prefix isn't offered again
+	if len(paths) != 3 || paths[0] != "/v1beta/cachedContents" || paths[2] == "/v1beta/cachedContents" {
+		t.Errorf("Expected one cache attempt and two generations, got %v", paths)
+	}
+}
+
+func TestWrapOpError(t *testing.T) {
+	c := &Client{modelName: "gemini-1.5-flash-001"}
+	err := c.wrapOpError(opCreateCache, cachesEndpoint, &googleapi.Error{Code: http.StatusBadRequest, Message: "Cached content is too small"})
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.Op != "create cache" || apiErr.Endpoint != "cachedContents" || apiErr.StatusCode != 400 {
+		t.Errorf("Unexpected error: %#v", err)
+	}
+}
+
+func TestGeminiClient_GenerateCached_Preflight(t *testing.T) {
+	llm.RegisterModel(llm.ModelInfo{Name: "gemini-test-tiny", Provider: "gemini", ContextWindow: 100})
+	client, err := NewClient(context.Background(), "test-api-key", "gemini-test-tiny", 30, false, llm.WithPreflightTokenCheck())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer client.Close()
+
+	parts := []llm.PromptPart{{Text: strings.Repeat("word ", 500), Cacheable: true}, {Text: "Summarize."}}
+	_, _, err = client.GenerateCached(context.Background(), parts)
+	var clErr *llm.ContextLengthError
+	if !errors.As(err, &clErr) || clErr.MaxTokens != 100 {
+		t.Errorf("Expected pre-flight rejection before caching, got: %v", err)
+	}
+	if len(client.promptCaches) != 0 {
+		t.Error("Expected no cache to be created for an oversized prompt")
+	}
+}
+
+func TestPromptCache_ReusesLiveCaches(t *testing.T) {
+	client, err := NewClient(context.Background(), "test-api-key", "", 30, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	key := func(prefix string) string {
+		sum := sha256.Sum256([]byte(prefix))
+		return hex.EncodeToString(sum[:])
+	}
+	live := &Cache{name: "cachedContents/live", expireTime: time.Now().Add(10 * time.Minute)}
+	expiring := &Cache{name: "cachedContents/old", expireTime: time.Now().Add(10 * time.Second)}
+	created := func(cache *Cache) *promptCacheEntry {
+		entry := &promptCacheEntry{ready: make(chan struct{}), cache: cache}
+		close(entry.ready)
+		return entry
+	}
+	client.promptCaches = map[string]*promptCacheEntry{
+		key("live"):      created(live),
+		key("too small"): created(nil),
+		key("expiring"):  created(expiring),
+	}
+
+	if cache, err := client.promptCache(context.Background(), "live"); err != nil || cache != live {
+		t.Errorf("Expected the live cache to be reused, got %v, %v", cache, err)
+	}
+	if cache, err := client.promptCache(context.Background(), "too small"); err != nil || cache != nil {
+		t.Errorf("Expected a declined prefix not to be retried, got %v, %v", cache, err)
+	}
+
+	// Renewing needs the API, which a closed client can't reach
+	client.Close()
+	if _, err := client.promptCache(context.Background(), "expiring"); err == nil || err.Error() != "Gemini client not initialized" {
+		t.Errorf("Expected an expiring cache to be recreated, got: %v", err)
+	}
+	if _, ok := client.promptCaches[key("expiring")]; ok {
+		t.Error("Expected the failed creation to be forgotten")
+	}
+}
+
+func TestPromptCache_ConcurrentCallsShareCreation(t *testing.T) {
+	client := &Client{}
+	sum := sha256.Sum256([]byte("shared document"))
+	pending := &promptCacheEntry{ready: make(chan struct{})}
+	client.promptCaches = map[string]*promptCacheEntry{hex.EncodeToString(sum[:]): pending}
+
+	var wg sync.WaitGroup
+	results := make(chan *Cache, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache, err := client.promptCache(context.Background(), "shared document")
+			if err != nil {
+				t.Errorf("promptCache failed: %v", err)
+			}
+			results <- cache
+		}()
+	}
+
+	// Callers for other prefixes aren't held up by the pending creation
+	if _, err := client.promptCache(context.Background(), "other"); err == nil {
+		t.Error("Expected creating a cache without an SDK client to fail")
+	}
+
+	created := &Cache{name: "cachedContents/abc", expireTime: time.Now().Add(time.Hour)}
+	pending.cache = created
+	close(pending.ready)
+	wg.Wait()
+	close(results)
+	for cache := range results {
+		if cache != created {
+			t.Errorf("Expected every caller to get the pending cache, got %v", cache)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sum = sha256.Sum256([]byte("waiting"))
+	client.promptCaches[hex.EncodeToString(sum[:])] = &promptCacheEntry{ready: make(chan struct{})}
+	if _, err := client.promptCache(ctx, "waiting"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected waiting to end with the context, got: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// GenerateCandidates implements xollm.CandidateGenerator by setting
+// Gemini's candidate count, so the prompt is sent and billed once. Gemini
+// accepts up to 8 candidates; older models only 1. A candidate without
+// text, e.g. one stopped by the token limit before any output, is returned
+// as "".
+func (c *Client) GenerateCandidates(ctx context.Context, prompt string, n int) ([]string, *llm.ResponseMetadata, error) {
+	if n < 1 {
+		return nil, nil, fmt.Errorf("candidate count must be at least 1, got %d", n)
+	}
+	model, err := c.generativeModel(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prompt); err != nil {
+			return nil, nil, err
+		}
+	}
+	// The handle is shared by all calls, so the count goes on a copy
+	copied := *model
+	copied.SetCandidateCount(int32(n))
+	resp, err := c.generateContent(ctx, &copied, prompt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(resp.Candidates) == 0 {
+		if blocked := blockedError(resp); blocked != nil {
+			return nil, nil, blocked
+		}
+		return nil, nil, c.opError(fmt.Errorf("response was empty or malformed"))
+	}
+
+	texts := make([]string, len(resp.Candidates))
+	for i, candidate := range resp.Candidates {
+		texts[i] = c.candidateText(ctx, candidate)
+	}
+	md := &llm.ResponseMetadata{
+		Model:        c.modelName,
+		FinishReason: finishReasonOf(resp.Candidates[0].FinishReason),
+		Usage:        usageOf(resp),
+	}
+	return texts, md.WithEstimatedCost(providerName), nil
+}
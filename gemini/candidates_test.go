@@ -0,0 +1,55 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGeminiClient_GenerateCandidates(t *testing.T) {
+	var sent struct {
+		GenerationConfig struct {
+			CandidateCount int `json:"candidateCount"`
+		} `json:"generationConfig"`
+	}
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		sent.GenerationConfig.CandidateCount = 0
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"candidates": [
+				{"index": 0, "content": {"role": "model", "parts": [{"text": "Red"}]}, "finishReason": "STOP"},
+				{"index": 1, "content": {"role": "model", "parts": [{"text": "Blue"}]}, "finishReason": "STOP"},
+				{"index": 2, "finishReason": "MAX_TOKENS"}
+			],
+			"usageMetadata": {"promptTokenCount": 5, "candidatesTokenCount": 4}
+		}`))
+	})
+
+	texts, md, err := client.GenerateCandidates(context.Background(), "Name a colour", 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent.GenerationConfig.CandidateCount != 3 {
+		t.Errorf("Expected a candidate count of 3, got %d", sent.GenerationConfig.CandidateCount)
+	}
+	if expected := []string{"Red", "Blue", ""}; !reflect.DeepEqual(texts, expected) {
+		t.Errorf("Expected %q, got %q", expected, texts)
+	}
+	if md.FinishReason != "Stop" || md.Usage.PromptTokens != 5 || md.Usage.CompletionTokens != 4 {
+		t.Errorf("Unexpected metadata: %+v", md)
+	}
+
+	if _, err := client.Generate(context.Background(), "Name a colour"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent.GenerationConfig.CandidateCount != 0 {
+		t.Errorf("Expected the candidate count not to outlive the call, got %d", sent.GenerationConfig.CandidateCount)
+	}
+
+	if _, _, err := client.GenerateCandidates(context.Background(), "Name a colour", 0); err == nil {
+		t.Error("Expected an error for a count of 0")
+	}
+}
@@ -0,0 +1,172 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+)
+
+// Chat implements xollm.Chatter. The messages before the last are sent as
+// the conversation's history, with assistant messages in the model role;
+// system messages are sent as the system instruction, after the client's
+// system prompt, if any.
+func (c *Client) Chat(ctx context.Context, messages []llm.Message) (string, *llm.ResponseMetadata, error) {
+	resp, err := c.sendChat(ctx, messages, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.responseText(ctx, resp)
+}
+
+// GenerateWithImages implements xollm.VisionGenerator, sending the images
+// as inline data after the prompt. Gemini only accepts image data, so
+// images given by URL are downloaded first.
+func (c *Client) GenerateWithImages(ctx context.Context, prompt string, images []llm.Image) (string, error) {
+	resp, err := c.sendChat(ctx, []llm.Message{{Role: llm.RoleUser, Content: prompt, Images: images}}, nil)
+	if err != nil {
+		return "", err
+	}
+	text, _, err := c.responseText(ctx, resp)
+	return text, err
+}
+
+// sendChat sends messages to the client's model, or to a copy changed by
+// configure if it is not nil, and returns the merged response.
+func (c *Client) sendChat(ctx context.Context, messages []llm.Message, configure func(*genai.GenerativeModel)) (*genai.GenerateContentResponse, error) {
+	model, err := c.generativeModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := llm.CheckMessages(messages); err != nil {
+		return nil, err
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, llm.FlattenMessages(messages)); err != nil {
+			return nil, err
+		}
+	}
+
+	system, turns := llm.SystemText(messages)
+	if system != "" || configure != nil {
+		// The handle is shared by all calls, so changes go on a copy
+		copied := *model
+		if system != "" {
+			if c.options.SystemPrompt != "" {
+				system = c.options.SystemPrompt + "\n\n" + system
+			}
+			copied.SystemInstruction = genai.NewUserContent(genai.Text(system))
+		}
+		if configure != nil {
+			configure(&copied)
+		}
+		model = &copied
+	}
+	if len(turns) == 0 {
+		// Gemini needs a user turn to respond to
+		turns = []llm.Message{{Role: llm.RoleUser}}
+	}
+	if turns, err = llm.LoadImages(ctx, turns); err != nil {
+		return nil, err
+	}
+
+	if timeout := c.requestTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	contents := chatContents(turns)
+	session := model.StartChat()
+	session.History = contents[:len(contents)-1]
+	llm.Logger(ctx, c.logger).Debug("sending Gemini chat request", "model", c.modelName, "messages", len(turns))
+	resp, err := collectStream(ctx, session.SendMessageStream(ctx, contents[len(contents)-1].Parts...))
+	if err != nil {
+		return nil, llm.WrapContextLength(c.modelName, c.wrapError(err))
+	}
+	return resp, nil
+}
+
+// collectStream reads the responses of iter and returns them merged into
+// one, with the usage reported last. The SDK's SendMessage reads the same
+// stream, but without readStream's end-of-stream handling.
+func collectStream(ctx context.Context, iter *genai.GenerateContentResponseIterator) (*genai.GenerateContentResponse, error) {
+	var usage *genai.UsageMetadata
+	finished := false
+	for {
+		resp, err := iter.Next()
+		if streamEnded(ctx, err, finished) {
+			merged := iter.MergedResponse()
+			if merged == nil {
+				merged = &genai.GenerateContentResponse{}
+			}
+			merged.UsageMetadata = usage
+			return merged, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
+		if len(resp.Candidates) > 0 {
+			finished = resp.Candidates[0].FinishReason != genai.FinishReasonUnspecified
+		}
+	}
+}
+
+// chatContents converts user, assistant and tool messages to Gemini
+// contents, with the images of user messages, which must be loaded, as
+// inline data. Assistant messages are sent in the model role, with their tool
+// calls as function calls; tool results are sent as function responses,
+// those answering the same turn together in one user content, as Gemini
+// expects.
+func chatContents(messages []llm.Message) []*genai.Content {
+	names := llm.ToolNames(messages)
+	var contents []*genai.Content
+	for i, m := range messages {
+		switch {
+		case m.Role == llm.RoleTool:
+			part := genai.FunctionResponse{Name: names[m.ToolCallID], Response: toolResult(m.Content)}
+			if i > 0 && messages[i-1].Role == llm.RoleTool {
+				last := contents[len(contents)-1]
+				last.Parts = append(last.Parts, part)
+				continue
+			}
+			contents = append(contents, &genai.Content{Role: "user", Parts: []genai.Part{part}})
+		case m.Role == llm.RoleAssistant:
+			content := &genai.Content{Role: "model"}
+			if m.Content != "" || len(m.ToolCalls) == 0 {
+				content.Parts = append(content.Parts, genai.Text(m.Content))
+			}
+			for _, call := range m.ToolCalls {
+				var args map[string]any
+				json.Unmarshal(call.Arguments, &args) // Arguments the model generated are objects
+				content.Parts = append(content.Parts, genai.FunctionCall{Name: call.Name, Args: args})
+			}
+			contents = append(contents, content)
+		default:
+			content := &genai.Content{Role: "user", Parts: []genai.Part{genai.Text(m.Content)}}
+			for _, img := range m.Images {
+				content.Parts = append(content.Parts, genai.Blob{MIMEType: img.MIMEType, Data: img.Data})
+			}
+			contents = append(contents, content)
+		}
+	}
+	return contents
+}
+
+// toolResult converts the result of a tool call to a function response.
+// Gemini takes an object: results that aren't JSON objects are wrapped in
+// {"result": ...}, as a JSON value if they are one and as text otherwise.
+func toolResult(result string) map[string]any {
+	var value any
+	if err := json.Unmarshal([]byte(result), &value); err != nil {
+		return map[string]any{"result": result}
+	}
+	if obj, ok := value.(map[string]any); ok {
+		return obj
+	}
+	return map[string]any{"result": value}
+}
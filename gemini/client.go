@@ -3,11 +3,17 @@ package gemini
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log" // For logging initialization errors if needed
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -16,109 +22,519 @@ const (
 	providerName       = "gemini"
 )
 
-// Client implements the llm.Client interface for Gemini.
+// apiEndpoint overrides the Gemini API address when set, e.g. to point
+// tests at a local server.
+var apiEndpoint = ""
+
+// Client implements the llm.Client interface for Gemini. A Client is safe
+// for concurrent use: the SDK client and model handle are created once and
+// never modified afterwards, so all calls share them, while the API key,
+// prompt caches and rate limit state are guarded by locks. Share one client
+// between goroutines rather than creating one per worker.
 type Client struct {
+	keyMu  sync.RWMutex // Guards apiKey, which SetCredentials replaces
+	apiKey string
+
+	modelName string
+	options   llm.ClientOptions
+	logger    *slog.Logger // nil for llm.DefaultLogger
+
+	// The SDK client and model handle are created by Init, either in
+	// NewClient or, with llm.WithLazyInit, on first use.
+	initMu      sync.Mutex
 	genaiClient *genai.Client
-	modelName   string
+	model       *genai.GenerativeModel // Created once, read-only, shared by all calls
+
+	// Caches created by GenerateCached, keyed by prefix hash. An entry
+	// without a cache marks a prefix Gemini refused to cache.
+	cacheMu      sync.Mutex
+	promptCaches map[string]*promptCacheEntry
+
+	rateLimits llm.RateLimitTracker
+
+	// timeout limits each generation request, 0 for none. It is set by
+	// WithTimeout.
+	timeout time.Duration
+
+	// parent is the client this one was derived from with WithModel or
+	// WithTimeout, whose SDK client, API key and rate limit state it uses.
+	// It is nil for clients created with NewClient.
+	parent *Client
 }
 
 // NewClient creates a new Gemini client.
 // It requires a context for initialization (can be context.Background()),
 // the API key, an optional model name (defaults to gemma-3-27b-it),
 // a requestTimeoutSeconds parameter for consistency with other providers,
-// and a debugMode flag.
-func NewClient(ctx context.Context, apiKey string, modelOverride string, requestTimeoutSeconds int, debugMode bool) (*Client, error) {
+// a debugMode flag, and options enabling optional behaviour such as
+// llm.WithPreflightTokenCheck.
+//
+// debugMode is deprecated: it only remains so existing callers compile.
+// True is shorthand for llm.WithLogger(llm.DebugLogger()) and is ignored
+// when a WithLogger option is given; new code should pass false.
+//
+// With llm.WithLazyInit the SDK client is not created here but by Init or
+// the first Generate call.
+func NewClient(ctx context.Context, apiKey string, modelOverride string, requestTimeoutSeconds int, debugMode bool, opts ...llm.ClientOption) (*Client, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("Gemini API key is required")
 	}
 
+	options := llm.ApplyOptions(opts)
+	clientLogger := options.ClientLogger(debugMode)
+	logger := llm.Logger(ctx, clientLogger)
+
+	modelToUse := defaultGeminiModel
+	if modelOverride != "" {
+		modelToUse = modelOverride
+	}
+	logger.Debug("using Gemini model", "model", modelToUse, "overridden", modelOverride != "")
+
+	c := &Client{
+		apiKey:    apiKey,
+		modelName: modelToUse,
+		options:   options,
+		logger:    clientLogger,
+	}
+	if c.options.LazyInit {
+		logger.Debug("deferring Gemini client initialization until first use")
+		return c, nil
+	}
+
 	// Apply timeout to context if specified
 	if requestTimeoutSeconds > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, time.Duration(requestTimeoutSeconds)*time.Second)
 		defer cancel()
-		if debugMode {
-			log.Printf("Using timeout for Gemini client: %d seconds", requestTimeoutSeconds)
+		logger.Debug("using timeout for Gemini client initialization", "timeout", time.Duration(requestTimeoutSeconds)*time.Second)
+	}
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Init creates the SDK client if that hasn't happened yet. NewClient calls
+// it unless llm.WithLazyInit was given; lazily initialized clients can call
+// it at startup to surface configuration errors early. It is safe to call
+// repeatedly and concurrently.
+func (c *Client) Init(ctx context.Context) error {
+	_, err := c.generativeModel(ctx)
+	return err
+}
+
+// generativeModel returns the model handle, initializing the client first
+// if needed.
+func (c *Client) generativeModel(ctx context.Context) (*genai.GenerativeModel, error) {
+	c.initMu.Lock()
+	defer c.initMu.Unlock()
+	if c.parent != nil && c.key() == "" {
+		// The client this one was derived from was closed
+		return nil, fmt.Errorf("Gemini client not initialized")
+	}
+	if c.model != nil {
+		return c.model, nil
+	}
+	if c.parent != nil {
+		// Derived clients only need a model handle on the shared SDK client
+		sdk, err := c.parent.sdkClient(ctx)
+		if err != nil {
+			return nil, err
 		}
+		c.genaiClient = sdk
+		c.model = c.newModel(sdk)
+		return c.model, nil
+	}
+	apiKey := c.key()
+	if apiKey == "" {
+		return nil, fmt.Errorf("Gemini client not initialized")
 	}
 
-	genaiClient, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	// The SDK ignores its own auth options when given an HTTP client, so
+	// the key is added per request, which also lets SetCredentials rotate it.
+	// The SDK's cache client drops the HTTP client for gRPC with this key,
+	// so caches are managed over REST instead, see CreateCache.
+	clientOpts := []option.ClientOption{option.WithAPIKey(apiKey), option.WithHTTPClient(c.apiHTTPClient())}
+	if apiEndpoint != "" {
+		clientOpts = append(clientOpts, option.WithEndpoint(apiEndpoint))
+	}
+	genaiClient, err := genai.NewClient(ctx, clientOpts...)
 	if err != nil {
-		// This log is more of a system/developer error, so keep it for now, or make it debug conditional too.
-		// For now, let's assume it's important enough to always show if client creation fails.
-		log.Printf("Error initializing Google GenAI client: %v. Make sure your API key is valid and has permissions.", err)
+		llm.Logger(ctx, c.logger).Error("failed to initialize Google GenAI client; make sure the API key is valid and has permissions", "error", err)
 		return nil, fmt.Errorf("failed to create genai client: %w", err)
 	}
+	c.genaiClient = genaiClient
+	c.model = c.newModel(genaiClient)
+	return c.model, nil
+}
 
-	modelToUse := defaultGeminiModel
-	if modelOverride != "" {
-		modelToUse = modelOverride
-		if debugMode {
-			log.Printf("Using overridden Gemini model: %s", modelToUse)
-		}
-	} else {
-		if debugMode {
-			log.Printf("Using default Gemini model: %s", modelToUse)
-		}
+// newModel returns a handle for the client's model on sdk.
+func (c *Client) newModel(sdk *genai.Client) *genai.GenerativeModel {
+	model := sdk.GenerativeModel(c.modelName)
+	if c.options.SystemPrompt != "" {
+		// Gemini follows instructions more reliably from system_instruction
+		// than from text prepended to the prompt
+		model.SystemInstruction = genai.NewUserContent(genai.Text(c.options.SystemPrompt))
+	}
+	setSampling(&model.GenerationConfig, c.options.Sampling)
+	return model
+}
+
+// apiHTTPClient returns an HTTP client that sends requests through the
+// shared transport, authenticated with the client's API key, see
+// apiKeyTransport.
+func (c *Client) apiHTTPClient() *http.Client {
+	root := c.root()
+	return &http.Client{Transport: &apiKeyTransport{key: c.key, project: c.options.Project, app: c.options.AppIdentifier, rateLimits: &root.rateLimits, base: c.options.Transport(llm.SharedTransport())}}
+}
+
+// restURL returns the URL of path, e.g. "cachedContents", in the Gemini
+// REST API.
+func restURL(path string) string {
+	base := apiEndpoint
+	if base == "" {
+		base = defaultAPIEndpoint
+	}
+	return strings.TrimSuffix(base, "/") + "/v1beta/" + path
+}
+
+// setSampling copies the fields set in s to config.
+func setSampling(config *genai.GenerationConfig, s llm.Sampling) {
+	if s.Temperature != nil {
+		config.SetTemperature(float32(*s.Temperature))
+	}
+	if s.MaxTokens != nil {
+		config.SetMaxOutputTokens(int32(*s.MaxTokens))
+	}
+	if s.TopP != nil {
+		config.SetTopP(float32(*s.TopP))
+	}
+	if s.Stop != nil {
+		config.StopSequences = s.Stop
 	}
+}
 
-	return &Client{
-		genaiClient: genaiClient,
-		modelName:   modelToUse,
-	}, nil
+// apiKeyTransport authenticates requests with an API key header, bills
+// them to the configured project, if any, identifies xollm in the
+// User-Agent, and records the rate limit
+// headers of the responses. In dry-run mode it renders the requests of the
+// SDK instead of sending them.
+type apiKeyTransport struct {
+	key        func() string
+	project    string // Google Cloud project billed for the requests
+	app        string // Application identifier for the User-Agent
+	rateLimits *llm.RateLimitTracker
+	base       http.RoundTripper
+}
+
+// RoundTrip adds the API key to a copy of req and sends it.
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("x-goog-api-key", t.key())
+	if t.project != "" {
+		req.Header.Set("x-goog-user-project", t.project)
+	}
+	llm.SetUserAgent(req, t.app)
+	if err := llm.InterceptDryRun(providerName, req); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && t.rateLimits != nil {
+		t.rateLimits.Update(resp.Header)
+	}
+	if err == nil {
+		llm.CaptureRawResponse(resp)
+	}
+	return resp, err
 }
 
 // Generate sends the prompt to the Gemini model and returns the text response.
 func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
-	if c.genaiClient == nil {
-		return "", fmt.Errorf("Gemini client not initialized")
+	text, _, err := c.generateUncached(ctx, prompt)
+	return text, err
+}
+
+// GenerateWithMetadata is Generate, also returning the response's finish
+// reason and token usage.
+func (c *Client) GenerateWithMetadata(ctx context.Context, prompt string) (string, *llm.ResponseMetadata, error) {
+	return c.generateUncached(ctx, prompt)
+}
+
+// GenerateWithSampling implements xollm.SamplingGenerator: the fields set
+// in sampling override the client's default generation config for this
+// call.
+func (c *Client) GenerateWithSampling(ctx context.Context, prompt string, sampling llm.Sampling) (string, error) {
+	model, err := c.generativeModel(ctx)
+	if err != nil {
+		return "", err
 	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prompt); err != nil {
+			return "", err
+		}
+	}
+	// The handle is shared by all calls, so the overrides go on a copy
+	copied := *model
+	setSampling(&copied.GenerationConfig, sampling)
+	text, _, err := c.generateWith(ctx, &copied, prompt)
+	return text, err
+}
+
+// generateWith sends the prompt to model, which is either the client's
+// model or one that references cached content, and returns the text
+// response and its metadata.
+func (c *Client) generateWith(ctx context.Context, model *genai.GenerativeModel, prompt string) (string, *llm.ResponseMetadata, error) {
+	resp, err := c.generateContent(ctx, model, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.responseText(ctx, resp)
+}
 
-	model := c.genaiClient.GenerativeModel(c.modelName)
-	if model == nil {
-		return "", fmt.Errorf("failed to get generative model: %s", c.modelName)
+// generateContent sends the prompt to model and returns the response.
+func (c *Client) generateContent(ctx context.Context, model *genai.GenerativeModel, prompt string) (*genai.GenerateContentResponse, error) {
+	if timeout := c.requestTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
 	// Simple text generation
+	llm.Logger(ctx, c.logger).Debug("sending Gemini request", "model", c.modelName)
 	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
-		return "", fmt.Errorf("failed to generate content from Gemini: %w", err)
+		return nil, llm.WrapContextLength(c.modelName, c.wrapError(err))
 	}
+	return resp, nil
+}
 
+// responseText returns the text of the first candidate of resp and the
+// response's metadata.
+func (c *Client) responseText(ctx context.Context, resp *genai.GenerateContentResponse) (string, *llm.ResponseMetadata, error) {
 	// Extract text from the response.
 	// The response can have multiple candidates, we'll use the first one.
 	// Each candidate can have multiple parts, we'll concatenate text parts.
 	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
 		// Check for blocked prompt/response
-		if len(resp.Candidates) > 0 && resp.Candidates[0].FinishReason == genai.FinishReasonSafety {
-			// You could inspect resp.Candidates[0].SafetyRatings for more details
-			return "", fmt.Errorf("Gemini content generation blocked due to safety settings")
-		}
-		if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
-			return "", fmt.Errorf("Gemini prompt blocked: %s", resp.PromptFeedback.BlockReason.String())
+		if blocked := blockedError(resp); blocked != nil {
+			return "", nil, blocked
 		}
-		return "", fmt.Errorf("Gemini response was empty or malformed")
+		return "", nil, c.opError(fmt.Errorf("response was empty or malformed"))
+	}
+
+	resultText := c.candidateText(ctx, resp.Candidates[0])
+	if resultText == "" {
+		// This might happen if the response only contained non-text parts or was genuinely empty.
+		return "", nil, c.opError(fmt.Errorf("response contained no usable text content"))
 	}
 
-	var resultText string
-	for _, part := range resp.Candidates[0].Content.Parts {
+	md := &llm.ResponseMetadata{
+		Model:        c.modelName,
+		FinishReason: finishReasonOf(resp.Candidates[0].FinishReason),
+		Usage:        usageOf(resp),
+	}
+	return resultText, md.WithEstimatedCost(providerName), nil
+}
+
+// finishReasonOf returns the name of r without its prefix, e.g. "Stop" or
+// "MaxTokens", or "" if it is unspecified, as llm.NormalizeFinishReason
+// expects.
+func finishReasonOf(r genai.FinishReason) string {
+	if r == genai.FinishReasonUnspecified {
+		return ""
+	}
+	return strings.TrimPrefix(r.String(), "FinishReason")
+}
+
+// candidateText returns the concatenated text parts of candidate.
+func (c *Client) candidateText(ctx context.Context, candidate *genai.Candidate) string {
+	if candidate.Content == nil {
+		return ""
+	}
+	var text string
+	for _, part := range candidate.Content.Parts {
 		if txt, ok := part.(genai.Text); ok {
-			resultText += string(txt)
+			text += string(txt)
 		} else {
 			// This library expects text output from the LLM.
 			// If other parts are returned (e.g. function calls, blobs), we ignore them for now.
-			// This log can be noisy, consider making it debug conditional if it becomes an issue.
-			// For now, keeping it as it indicates unexpected parts.
-			log.Printf("Gemini client received non-text part: %T. Ignoring.", part)
+			llm.Logger(ctx, c.logger).Debug("ignoring non-text part of Gemini response", "type", fmt.Sprintf("%T", part))
 		}
 	}
+	return text
+}
+
+// usageOf returns the token usage reported with resp.
+func usageOf(resp *genai.GenerateContentResponse) llm.Usage {
+	if resp.UsageMetadata == nil {
+		return llm.Usage{}
+	}
+	return llm.Usage{
+		PromptTokens:     int(resp.UsageMetadata.PromptTokenCount),
+		CompletionTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+		CachedTokens:     int(resp.UsageMetadata.CachedContentTokenCount),
+	}
+}
 
-	if resultText == "" {
-		// This might happen if the response only contained non-text parts or was genuinely empty.
-		return "", fmt.Errorf("Gemini response contained no usable text content")
+// blockedError returns a *llm.ContentFilteredError with the safety ratings
+// if Gemini blocked the prompt or the first candidate, for safety or
+// recitation, and nil otherwise.
+func blockedError(resp *genai.GenerateContentResponse) error {
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+		return &llm.ContentFilteredError{
+			Provider: providerName,
+			Stage:    llm.StagePrompt,
+			Reason:   strings.TrimPrefix(resp.PromptFeedback.BlockReason.String(), "BlockReason"),
+			Ratings:  convertRatings(resp.PromptFeedback.SafetyRatings),
+		}
+	}
+	if len(resp.Candidates) > 0 && (resp.Candidates[0].FinishReason == genai.FinishReasonSafety || resp.Candidates[0].FinishReason == genai.FinishReasonRecitation) {
+		return &llm.ContentFilteredError{
+			Provider: providerName,
+			Stage:    llm.StageResponse,
+			Reason:   finishReasonOf(resp.Candidates[0].FinishReason),
+			Ratings:  convertRatings(resp.Candidates[0].SafetyRatings),
+		}
 	}
+	return nil
+}
 
-	return resultText, nil
+// convertRatings converts genai safety ratings to provider-neutral ones,
+// e.g. HarmCategoryHarassment/HarmProbabilityHigh to Harassment/High.
+func convertRatings(ratings []*genai.SafetyRating) []llm.SafetyRating {
+	converted := make([]llm.SafetyRating, 0, len(ratings))
+	for _, r := range ratings {
+		if r == nil {
+			continue
+		}
+		converted = append(converted, llm.SafetyRating{
+			Category:    strings.TrimPrefix(r.Category.String(), "HarmCategory"),
+			Probability: strings.TrimPrefix(r.Probability.String(), "HarmProbability"),
+			Blocked:     r.Blocked,
+		})
+	}
+	return converted
+}
+
+// wrapError converts an error from the genai client into an *llm.Error.
+// API failures carry the HTTP status and details; other failures carry just
+// the cause and its transport sentinel. The SDK reports blocked prompts and
+// responses as a *genai.BlockedError, which becomes a
+// *llm.ContentFilteredError like the blocks found in responses.
+func (c *Client) wrapError(err error) error {
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		resp := &genai.GenerateContentResponse{PromptFeedback: blocked.PromptFeedback}
+		if blocked.Candidate != nil {
+			resp.Candidates = []*genai.Candidate{blocked.Candidate}
+		}
+		if filtered := blockedError(resp); filtered != nil {
+			return filtered
+		}
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		err = fmt.Errorf("failed to generate content: %w", err)
+	}
+	return c.wrapOpError(llm.OpGenerate, c.endpoint(), err)
+}
+
+// wrapOpError converts an error from the genai client into an *llm.Error
+// describing op on endpoint.
+func (c *Client) wrapOpError(op, endpoint string, err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return llm.NewError(providerName, op, endpoint, err)
+	}
+	e := &llm.Error{
+		Provider:   providerName,
+		Op:         op,
+		Endpoint:   endpoint,
+		StatusCode: apiErr.Code,
+		Message:    apiErr.Message,
+		Body:       llm.CapBody([]byte(apiErr.Body)),
+		Kind:       classifyError(err),
+		Err:        err,
+	}
+	if len(apiErr.Errors) > 0 {
+		e.Code = apiErr.Errors[0].Reason
+	}
+	if apiErr.Header != nil {
+		e.RequestID = apiErr.Header.Get("X-Request-Id")
+	}
+	return e
+}
+
+// endpoint names the model resource generate calls are sent to.
+func (c *Client) endpoint() string {
+	return "models/" + strings.TrimPrefix(c.modelName, "models/")
+}
+
+// opError describes a generate call that failed without an API error
+// response, e.g. because the request could not be sent or the response
+// had no usable content.
+func (c *Client) opError(err error) *llm.Error {
+	return llm.NewError(providerName, llm.OpGenerate, c.endpoint(), err)
+}
+
+// classifyError picks the sentinel error for a failed Gemini API call.
+// The REST transport reports API failures as *googleapi.Error.
+func classifyError(err error) error {
+	if sentinel := llm.TransportError(err); sentinel != nil {
+		return sentinel
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+	switch {
+	case apiErr.Code == http.StatusBadRequest && llm.IsContextLengthMessage(apiErr.Message):
+		return llm.ErrContextTooLong
+	case apiErr.Code == http.StatusBadRequest && strings.Contains(apiErr.Message, "API key not valid"):
+		return llm.ErrAuthentication
+	}
+	return llm.StatusError(apiErr.Code)
+}
+
+// RateLimitState implements xollm.RateLimitReporter. Gemini reports its
+// limits only in Retry-After headers of rejected requests, if at all, so
+// ok is often false.
+func (c *Client) RateLimitState() (llm.RateLimitState, bool) {
+	return c.root().rateLimits.State()
+}
+
+// SetCredentials implements xollm.CredentialSetter, replacing the API key
+// used by requests sent from now on, including those creating, opening,
+// deleting and generating through caches. Requests already in flight
+// finish with the old key.
+//
+// Clients derived from the same client share their API key, so setting it
+// on any of them sets it for all.
+func (c *Client) SetCredentials(apiKey string) error {
+	if apiKey == "" {
+		return fmt.Errorf("Gemini API key is required")
+	}
+	c = c.root()
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	if c.apiKey == "" {
+		return fmt.Errorf("Gemini client is closed")
+	}
+	c.apiKey = apiKey
+	return nil
+}
+
+// key returns the current API key, or "" once the client is closed.
+func (c *Client) key() string {
+	c = c.root()
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+	return c.apiKey
+}
+
+// ModelName returns the name of the model the client sends requests to.
+func (c *Client) ModelName() string {
+	return c.modelName
 }
 
 // ProviderName returns the name of this provider.
@@ -128,9 +544,22 @@ func (c *Client) ProviderName() string {
 
 // Close cleans up the genaiClient.
 // It's good practice to offer a Close method if the underlying client has one.
+// A closed client is not initialized again. Closing a client also closes
+// the clients derived from it; closing a derived client has no effect.
 func (c *Client) Close() error {
+	if c.parent != nil {
+		return nil
+	}
+	c.initMu.Lock()
+	defer c.initMu.Unlock()
+	c.keyMu.Lock()
+	c.apiKey = ""
+	c.keyMu.Unlock()
+	c.model = nil
 	if c.genaiClient != nil {
-		return c.genaiClient.Close()
+		genaiClient := c.genaiClient
+		c.genaiClient = nil
+		return genaiClient.Close()
 	}
 	return nil
 }
@@ -2,8 +2,20 @@ package gemini
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+	"google.golang.org/api/googleapi"
 )
 
 func TestNewClient_Success(t *testing.T) {
@@ -68,6 +80,11 @@ func TestNewClient_WithCustomModel(t *testing.T) {
 		if client.modelName != "gemini-1.5-pro" {
 			t.Errorf("Expected model name 'gemini-1.5-pro', got '%s'", client.modelName)
 		}
+
+		// The model handle is created once and reused by every Generate call
+		if client.model == nil {
+			t.Error("Expected model handle to be created with the client")
+		}
 	}
 }
 
@@ -90,6 +107,85 @@ func TestNewClient_DefaultModel(t *testing.T) {
 }
 
 // Mock tests - these test the logic without making actual API calls
+func TestNewClient_LazyInit(t *testing.T) {
+	client, err := NewClient(context.Background(), "test-api-key", "", 30, false, llm.WithLazyInit())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if client.genaiClient != nil {
+		t.Fatal("Expected SDK client creation to be deferred")
+	}
+
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("Expected Init to succeed, got: %v", err)
+	}
+	first := client.genaiClient
+	if first == nil || client.model == nil {
+		t.Fatal("Expected Init to create the SDK client and model handle")
+	}
+	if err := client.Init(context.Background()); err != nil || client.genaiClient != first {
+		t.Error("Expected repeated Init to keep the same SDK client")
+	}
+
+	client.Close()
+	if _, err := client.Generate(context.Background(), "hi"); err == nil || err.Error() != "Gemini client not initialized" {
+		t.Errorf("Expected closed client not to initialize again, got: %v", err)
+	}
+}
+
+func TestNewClient_SystemPrompt(t *testing.T) {
+	client, err := NewClient(context.Background(), "test-api-key", "", 30, false, llm.WithSystemPrompt("Answer in French."))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer client.Close()
+
+	instruction := client.model.SystemInstruction
+	if instruction == nil || len(instruction.Parts) != 1 || instruction.Parts[0] != genai.Text("Answer in French.") {
+		t.Errorf("Expected system prompt as the model's system instruction, got %+v", instruction)
+	}
+
+	plain, err := NewClient(context.Background(), "test-api-key", "", 30, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer plain.Close()
+	if plain.model.SystemInstruction != nil {
+		t.Error("Expected no system instruction without a system prompt")
+	}
+}
+
+func TestAPIKeyTransport(t *testing.T) {
+	var gotKey, gotProject, gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("x-goog-api-key")
+		gotProject = r.Header.Get("x-goog-user-project")
+		gotUserAgent = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &apiKeyTransport{key: func() string { return "secret" }, project: "billing-project", base: llm.SharedTransport()}}
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotKey != "secret" {
+		t.Errorf("Expected API key header, got '%s'", gotKey)
+	}
+	if gotUserAgent != llm.UserAgent("") {
+		t.Errorf("Expected the xollm User-Agent, got '%s'", gotUserAgent)
+	}
+	if gotProject != "billing-project" {
+		t.Errorf("Expected the billing project header, got '%s'", gotProject)
+	}
+	if req.Header.Get("x-goog-api-key") != "" {
+		t.Error("Expected the caller's request to be left unchanged")
+	}
+}
+
 func TestMockGeminiClient_Generate_EmptyPrompt(t *testing.T) {
 	// Create a mock client for testing logic without network calls
 	client := &Client{
@@ -187,3 +283,571 @@ func TestGeminiClient_Generate_Integration_Mock(t *testing.T) {
 	//    - API errors
 	// 3. Test error handling for each scenario
 }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "rate limited", err: &googleapi.Error{Code: http.StatusTooManyRequests}, expected: llm.ErrRateLimited},
+		{name: "forbidden", err: &googleapi.Error{Code: http.StatusForbidden}, expected: llm.ErrAuthentication},
+		{name: "invalid key", err: &googleapi.Error{Code: http.StatusBadRequest, Message: "API key not valid. Please pass a valid API key."}, expected: llm.ErrAuthentication},
+		{name: "model not found", err: fmt.Errorf("rpc: %w", &googleapi.Error{Code: http.StatusNotFound}), expected: llm.ErrModelNotFound},
+		{name: "context length", err: &googleapi.Error{Code: http.StatusBadRequest, Message: "The input token count (40000) exceeds the maximum number of tokens allowed (32768)."}, expected: llm.ErrContextTooLong},
+		{name: "deadline", err: context.DeadlineExceeded, expected: llm.ErrTimeout},
+		{name: "other", err: errors.New("boom"), expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Request-Id", "req_1")
+	cause := &googleapi.Error{
+		Code:    http.StatusTooManyRequests,
+		Message: "Resource has been exhausted",
+		Body:    `{"error": {"code": 429}}`,
+		Header:  header,
+		Errors:  []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}},
+	}
+
+	c := &Client{modelName: "gemini-1.5-flash-latest"}
+	err := c.wrapError(fmt.Errorf("rpc: %w", cause))
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *llm.Error, got %T", err)
+	}
+	if apiErr.Provider != "gemini" || apiErr.Op != llm.OpGenerate || apiErr.Endpoint != "models/gemini-1.5-flash-latest" || apiErr.StatusCode != 429 || apiErr.Code != "rateLimitExceeded" || apiErr.RequestID != "req_1" {
+		t.Errorf("Unexpected error details: %+v", apiErr)
+	}
+	if !errors.Is(err, llm.ErrRateLimited) {
+		t.Error("Expected error to wrap ErrRateLimited")
+	}
+	var sdkErr *googleapi.Error
+	if !errors.As(err, &sdkErr) {
+		t.Error("Expected SDK error to remain reachable")
+	}
+
+	plain := c.wrapError(context.DeadlineExceeded)
+	if !errors.As(plain, &apiErr) || !errors.Is(plain, llm.ErrTimeout) {
+		t.Errorf("Expected non-API error to be an *llm.Error matching ErrTimeout, got: %v", plain)
+	}
+	if expected := "gemini: generate models/gemini-1.5-flash-latest: failed to generate content: context deadline exceeded"; plain.Error() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, plain.Error())
+	}
+}
+
+func TestBlockedError(t *testing.T) {
+	t.Run("prompt blocked", func(t *testing.T) {
+		resp := &genai.GenerateContentResponse{
+			PromptFeedback: &genai.PromptFeedback{
+				BlockReason: genai.BlockReasonSafety,
+				SafetyRatings: []*genai.SafetyRating{
+					{Category: genai.HarmCategoryHarassment, Probability: genai.HarmProbabilityHigh, Blocked: true},
+					{Category: genai.HarmCategoryHateSpeech, Probability: genai.HarmProbabilityNegligible},
+				},
+			},
+		}
+
+		err := blockedError(resp)
+		var filtered *llm.ContentFilteredError
+		if !errors.As(err, &filtered) {
+			t.Fatalf("Expected *llm.ContentFilteredError, got %T", err)
+		}
+		if filtered.Stage != llm.StagePrompt || filtered.Reason != "Safety" {
+			t.Errorf("Unexpected stage/reason: %+v", filtered)
+		}
+		expected := []llm.SafetyRating{
+			{Category: "Harassment", Probability: "High", Blocked: true},
+			{Category: "HateSpeech", Probability: "Negligible"},
+		}
+		if !reflect.DeepEqual(filtered.Ratings, expected) {
+			t.Errorf("Expected ratings %v, got %v", expected, filtered.Ratings)
+		}
+		if !errors.Is(err, llm.ErrContentFiltered) {
+			t.Error("Expected ErrContentFiltered to match")
+		}
+	})
+
+	t.Run("response blocked", func(t *testing.T) {
+		resp := &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{{
+				FinishReason:  genai.FinishReasonSafety,
+				SafetyRatings: []*genai.SafetyRating{{Category: genai.HarmCategoryDangerousContent, Probability: genai.HarmProbabilityMedium, Blocked: true}},
+			}},
+		}
+
+		var filtered *llm.ContentFilteredError
+		if !errors.As(blockedError(resp), &filtered) || filtered.Stage != llm.StageResponse {
+			t.Fatalf("Expected response-stage block, got %+v", filtered)
+		}
+		if got := filtered.BlockedCategories(); len(got) != 1 || got[0] != "DangerousContent" {
+			t.Errorf("Unexpected blocked categories: %v", got)
+		}
+	})
+
+	t.Run("not blocked", func(t *testing.T) {
+		resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonStop}}}
+		if err := blockedError(resp); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+}
+
+func TestGeminiClient_GenerateBlocked(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"promptFeedback": {"blockReason": "SAFETY", "safetyRatings": [{"category": "HARM_CATEGORY_HARASSMENT", "probability": "HIGH", "blocked": true}]}}`))
+	})
+
+	_, err := client.Generate(context.Background(), "Hi")
+	if !errors.Is(err, llm.ErrContentFiltered) {
+		t.Fatalf("Expected ErrContentFiltered, got: %v", err)
+	}
+	var filtered *llm.ContentFilteredError
+	if !errors.As(err, &filtered) || filtered.Stage != llm.StagePrompt {
+		t.Fatalf("Expected a prompt-stage *llm.ContentFilteredError, got %T: %v", err, err)
+	}
+	if got := filtered.BlockedCategories(); len(got) != 1 || got[0] != "Harassment" {
+		t.Errorf("Unexpected blocked categories: %v", got)
+	}
+}
+
+// newTestServerClient returns a client whose API requests are served by
+// handler.
+func newTestServerClient(t *testing.T, handler http.HandlerFunc, opts ...llm.ClientOption) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	original := apiEndpoint
+	apiEndpoint = server.URL
+	t.Cleanup(func() { apiEndpoint = original })
+
+	client, err := NewClient(context.Background(), "test-api-key", "gemini-1.5-flash", 30, false, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestGeminiClient_GenerateWithSampling(t *testing.T) {
+	var sent struct {
+		GenerationConfig map[string]json.RawMessage `json:"generationConfig"`
+	}
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		sent.GenerationConfig = nil
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+	}, llm.WithSampling(llm.Sampling{Temperature: llm.Float64(0.5), MaxTokens: llm.Int(128)}))
+
+	_, err := client.GenerateWithSampling(context.Background(), "Hi", llm.Sampling{Temperature: llm.Float64(0), Stop: []string{"END"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	config := sent.GenerationConfig
+	if string(config["temperature"]) != "0" || string(config["maxOutputTokens"]) != "128" || string(config["stopSequences"]) != `["END"]` {
+		t.Errorf("Expected per-call overrides on top of the defaults, got %v", config)
+	}
+
+	if _, err := client.Generate(context.Background(), "Hi"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	config = sent.GenerationConfig
+	if string(config["temperature"]) != "0.5" || config["stopSequences"] != nil {
+		t.Errorf("Expected the overrides not to outlive the call, got %v", config)
+	}
+}
+
+func TestGeminiClient_GenerateWithMetadata(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-1.5-flash:generateContent") {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"candidates": [{"content": {"role": "model", "parts": [{"text": "Hello"}]}, "finishReason": "MAX_TOKENS"}],
+			"usageMetadata": {"promptTokenCount": 7, "candidatesTokenCount": 3, "totalTokenCount": 10}
+		}`))
+	})
+
+	text, md, err := client.GenerateWithMetadata(context.Background(), "Hi")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != "Hello" {
+		t.Errorf("Expected 'Hello', got %q", text)
+	}
+	if md.Model != "gemini-1.5-flash" || md.FinishReason != "MaxTokens" {
+		t.Errorf("Unexpected metadata: %+v", md)
+	}
+	if md.Usage.PromptTokens != 7 || md.Usage.CompletionTokens != 3 {
+		t.Errorf("Unexpected usage: %+v", md.Usage)
+	}
+}
+
+func TestGeminiClient_UnspecifiedFinishReason(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "Hello"}]}, "finishReason": "FINISH_REASON_UNSPECIFIED"}]}`))
+	})
+
+	_, md, err := client.GenerateWithMetadata(context.Background(), "Hi")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if md.FinishReason != "" || md.Finish() != llm.FinishUnknown {
+		t.Errorf("Expected an unknown finish reason, got %q (%q)", md.FinishReason, md.Finish())
+	}
+	if got := finishReasonOf(genai.FinishReasonSafety); got != "Safety" {
+		t.Errorf("Expected Safety, got %q", got)
+	}
+}
+
+func TestGeminiClient_RawResponse(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "Hello"}]}, "finishReason": "STOP"}], "modelVersion": "gemini-1.5-flash-002"}`))
+	})
+
+	ctx, raw := llm.WithRawResponse(context.Background())
+	if _, err := client.Generate(ctx, "Hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(string(raw.Body()), `"modelVersion": "gemini-1.5-flash-002"`) {
+		t.Errorf("Expected the raw body, got %q", raw.Body())
+	}
+}
+
+func TestGeminiClient_SetCredentials(t *testing.T) {
+	var keys []string
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("x-goog-api-key"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"totalTokens": 1}`))
+	})
+
+	client.CountTokens(context.Background(), "a")
+	if err := client.SetCredentials("rotated-key"); err != nil {
+		t.Fatalf("SetCredentials failed: %v", err)
+	}
+	client.CountTokens(context.Background(), "b")
+
+	if len(keys) != 2 || keys[0] != "test-api-key" || keys[1] != "rotated-key" {
+		t.Errorf("Expected the rotated key on the second request, got %v", keys)
+	}
+	if err := client.SetCredentials(""); err == nil {
+		t.Error("Expected an error for an empty key")
+	}
+
+	client.Close()
+	if err := client.SetCredentials("another-key"); err == nil {
+		t.Error("Expected an error for a closed client")
+	}
+}
+
+func TestGeminiClient_RateLimitState(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"code": 429, "message": "Resource has been exhausted", "status": "RESOURCE_EXHAUSTED"}}`))
+	})
+
+	if _, ok := client.RateLimitState(); ok {
+		t.Error("Expected no state before the first response")
+	}
+	client.Generate(context.Background(), "hi")
+	state, ok := client.RateLimitState()
+	if !ok || state.RetryAfter != 30*time.Second || state.RemainingRequests != -1 {
+		t.Errorf("Expected a 30s Retry-After and unknown counts, got %+v, %v", state, ok)
+	}
+}
+
+func TestGeminiClient_DryRun(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request to be sent in dry-run mode")
+	})
+
+	_, err := client.Generate(llm.WithDryRun(context.Background()), "Hello")
+	var dryRun *llm.DryRunError
+	if !errors.As(err, &dryRun) {
+		t.Fatalf("Expected a *llm.DryRunError, got %v", err)
+	}
+	r := dryRun.Request
+	if !strings.Contains(r.URL, "gemini-1.5-flash:generateContent") || r.Header.Get("X-Goog-Api-Key") != "REDACTED" {
+		t.Errorf("Unexpected rendered request: %s %v", r.URL, r.Header)
+	}
+	if !strings.Contains(string(r.Body), "Hello") {
+		t.Errorf("Expected the prompt in the body, got %s", r.Body)
+	}
+}
+
+func TestGeminiClient_WithModel(t *testing.T) {
+	var paths []string
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+	})
+
+	derived := client.WithModel("gemini-2.0-flash")
+	if _, err := derived.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if derived.genaiClient != client.genaiClient {
+		t.Error("Expected the derived client to share the SDK client")
+	}
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(paths) != 2 || !strings.HasSuffix(paths[0], "/models/gemini-2.0-flash:generateContent") || !strings.HasSuffix(paths[1], "/models/gemini-1.5-flash:generateContent") {
+		t.Errorf("Expected each client to use its own model, got %v", paths)
+	}
+
+	derived.Close()
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Errorf("Expected closing a derived client to leave the original open, got: %v", err)
+	}
+	client.Close()
+	if _, err := derived.Generate(context.Background(), "hi"); err == nil {
+		t.Error("Expected closing the original to close the derived client")
+	}
+}
+
+func TestGeminiClient_WithModel_LazyInit(t *testing.T) {
+	client, err := NewClient(context.Background(), "test-api-key", "", 0, false, llm.WithLazyInit())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	derived := client.WithModel("gemini-2.0-flash")
+	if err := derived.Init(context.Background()); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if client.genaiClient == nil || derived.genaiClient != client.genaiClient {
+		t.Error("Expected initializing the derived client to initialize and share the original's SDK client")
+	}
+}
+
+func TestGeminiClient_WithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	defer close(release)
+
+	derived := client.WithTimeout(50 * time.Millisecond)
+	if derived.modelName != client.modelName || client.timeout != 0 {
+		t.Errorf("Expected only the derived client's timeout to change")
+	}
+	if _, err := derived.Generate(context.Background(), "hi"); !errors.Is(err, llm.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got: %v", err)
+	}
+}
+
+func TestGeminiClient_WithRequestTimeout(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+	})
+
+	ctx := llm.WithRequestTimeout(context.Background(), 20*time.Millisecond)
+	if _, err := client.Generate(ctx, "hi"); !errors.Is(err, llm.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got: %v", err)
+	}
+
+	short := client.WithTimeout(20 * time.Millisecond)
+	ctx = llm.WithRequestTimeout(context.Background(), 5*time.Second)
+	if _, err := short.Generate(ctx, "hi"); err != nil {
+		t.Errorf("Expected the request timeout to extend the client's, got: %v", err)
+	}
+}
+
+func TestGeminiClient_ConcurrentUse(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, ":countTokens") {
+			w.Write([]byte(`{"totalTokens": 1}`))
+			return
+		}
+		w.Header().Set("Retry-After", "1")
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+	})
+	derived := client.WithModel("gemini-2.0-flash")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.Background()
+			if _, err := client.Generate(ctx, "hi"); err != nil {
+				errs <- err
+			}
+			if _, err := derived.Generate(ctx, "hi"); err != nil {
+				errs <- err
+			}
+			if _, err := client.CountTokens(ctx, "hi"); err != nil {
+				errs <- err
+			}
+			if i%5 == 0 {
+				if err := derived.SetCredentials(fmt.Sprint("key-", i)); err != nil {
+					errs <- err
+				}
+			}
+			client.RateLimitState()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent call failed: %v", err)
+	}
+}
+
+func TestGeminiClient_RequestHook(t *testing.T) {
+	var keyAtHook string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signed") != "yes" {
+			t.Error("Expected the hook to run on the request")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"totalTokens": 1}`))
+	}))
+	defer server.Close()
+	original := apiEndpoint
+	apiEndpoint = server.URL
+	defer func() { apiEndpoint = original }()
+
+	client, err := NewClient(context.Background(), "test-api-key", "", 30, false, llm.WithRequestHook(func(req *http.Request) error {
+		keyAtHook = req.Header.Get("x-goog-api-key")
+		req.Header.Set("X-Signed", "yes")
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	if _, err := client.CountTokens(context.Background(), "hi"); err != nil {
+		t.Fatalf("CountTokens failed: %v", err)
+	}
+	if keyAtHook != "test-api-key" {
+		t.Errorf("Expected the hook to see the API key header, got %q", keyAtHook)
+	}
+}
+
+func TestGeminiClient_GenerateStream(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-1.5-flash:streamGenerateContent") {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"candidates": [{"content": {"role": "model", "parts": [{"text": "Hel"}]}}]},` +
+			`{"candidates": [{"content": {"role": "model", "parts": [{"text": "lo"}]}, "finishReason": 1}],` +
+			`"usageMetadata": {"promptTokenCount": 7, "candidatesTokenCount": 3, "totalTokenCount": 10}}]`))
+	})
+
+	chunks, err := client.GenerateStream(context.Background(), "Hi")
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	var texts []string
+	var last llm.StreamChunk
+	for chunk := range chunks {
+		if chunk.Text != "" {
+			texts = append(texts, chunk.Text)
+		}
+		last = chunk
+	}
+	if strings.Join(texts, "|") != "Hel|lo" {
+		t.Errorf("Expected two text chunks, got %q", texts)
+	}
+	if !last.Done || last.Err != nil || last.Usage == nil || last.Usage.PromptTokens != 7 || last.Usage.CompletionTokens != 3 {
+		t.Errorf("Unexpected final chunk: %+v", last)
+	}
+	if last.FinishReason != "Stop" {
+		t.Errorf("Expected finish reason \"Stop\" on the final chunk, got %q", last.FinishReason)
+	}
+}
+
+func TestGeminiClient_GenerateStream_Error(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"code": 429, "message": "Resource has been exhausted", "status": "RESOURCE_EXHAUSTED"}}`))
+	})
+
+	chunks, err := client.GenerateStream(context.Background(), "Hi")
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	var last llm.StreamChunk
+	for chunk := range chunks {
+		last = chunk
+	}
+	if !errors.Is(last.Err, llm.ErrRateLimited) {
+		t.Errorf("Expected a rate limit error chunk, got %+v", last)
+	}
+}
+
+func TestGeminiClient_Chat(t *testing.T) {
+	var sent struct {
+		Contents []struct {
+			Role  string `json:"role"`
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"contents"`
+		SystemInstruction *struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"systemInstruction"`
+	}
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"candidates": [{"content": {"role": "model", "parts": [{"text": "Stock"}]}}]},` +
+			`{"candidates": [{"content": {"role": "model", "parts": [{"text": "holm."}]}, "finishReason": 1}],` +
+			`"usageMetadata": {"promptTokenCount": 20, "candidatesTokenCount": 3, "totalTokenCount": 23}}]`))
+	})
+
+	text, md, err := client.Chat(context.Background(), []llm.Message{
+		{Role: llm.RoleSystem, Content: "Be terse."},
+		{Role: llm.RoleUser, Content: "Capital of Norway?"},
+		{Role: llm.RoleAssistant, Content: "Oslo."},
+		{Role: llm.RoleUser, Content: "And of Sweden?"},
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if text != "Stockholm." || md.Usage.PromptTokens != 20 {
+		t.Errorf("Unexpected reply %q, %+v", text, md)
+	}
+	var roles []string
+	for _, c := range sent.Contents {
+		roles = append(roles, c.Role+":"+c.Parts[0].Text)
+	}
+	if strings.Join(roles, "|") != "user:Capital of Norway?|model:Oslo.|user:And of Sweden?" {
+		t.Errorf("Unexpected contents %q", roles)
+	}
+	if sent.SystemInstruction == nil || sent.SystemInstruction.Parts[0].Text != "Be terse." {
+		t.Errorf("Expected the system message as the system instruction, got %+v", sent.SystemInstruction)
+	}
+	if client.model.SystemInstruction != nil {
+		t.Error("Expected the shared model handle to be left unchanged")
+	}
+}
@@ -0,0 +1,58 @@
+package gemini
+
+import (
+	"context"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// WithModel returns a client that sends its requests to model. It shares
+// c's SDK client, API key and rate limit state, so switching models costs
+// a model handle rather than a new SDK client. "" keeps c's model. Prompt
+// caches are per model and are not shared.
+func (c *Client) WithModel(model string) *Client {
+	d := c.derive()
+	if model != "" {
+		d.modelName = model
+	}
+	return d
+}
+
+// WithTimeout returns a client whose generation requests time out after d.
+// It shares c's SDK client, API key and rate limit state. A d of 0 or less
+// leaves only the deadline of the request's context.
+func (c *Client) WithTimeout(d time.Duration) *Client {
+	derived := c.derive()
+	derived.timeout = max(d, 0)
+	return derived
+}
+
+// requestTimeout returns the timeout of a request sent with ctx: the one
+// set with llm.WithRequestTimeout, or the client's.
+func (c *Client) requestTimeout(ctx context.Context) time.Duration {
+	if d, ok := llm.RequestTimeoutFromContext(ctx); ok {
+		return d
+	}
+	return c.timeout
+}
+
+// derive returns a client with c's settings that shares its SDK client,
+// API key and rate limit state.
+func (c *Client) derive() *Client {
+	return &Client{
+		modelName: c.modelName,
+		options:   c.options,
+		logger:    c.logger,
+		timeout:   c.timeout,
+		parent:    c.root(),
+	}
+}
+
+// root returns the client c was derived from, or c itself.
+func (c *Client) root() *Client {
+	if c.parent != nil {
+		return c.parent
+	}
+	return c
+}
@@ -0,0 +1,69 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+)
+
+const (
+	defaultEmbeddingModel = "text-embedding-004"
+
+	opEmbed = "embed" // Error.Op of failed Embed calls
+
+	// maxEmbedBatch is the most texts Gemini embeds in one request.
+	maxEmbedBatch = 100
+)
+
+// Embed implements xollm.Embedder with the batch embeddings API, sending
+// up to 100 texts per request. The embedding model is the one set with
+// llm.WithEmbeddingModel, or text-embedding-004. The client's request
+// timeout applies to each request.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	sdk, err := c.sdkClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := c.options.EmbeddingModel
+	if name == "" {
+		name = defaultEmbeddingModel
+	}
+	model := sdk.EmbeddingModel(name)
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxEmbedBatch {
+		end := min(start+maxEmbedBatch, len(texts))
+		batch := model.NewBatch()
+		for _, text := range texts[start:end] {
+			batch.AddContent(genai.Text(text))
+		}
+		resp, err := c.embedBatch(ctx, model, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Embeddings) != end-start {
+			return nil, llm.NewError(providerName, opEmbed, model.Name(), fmt.Errorf("expected %d embeddings, got %d", end-start, len(resp.Embeddings)))
+		}
+		for _, e := range resp.Embeddings {
+			embeddings = append(embeddings, e.Values)
+		}
+	}
+	return embeddings, nil
+}
+
+// embedBatch sends one batch embeddings request within the client's
+// request timeout.
+func (c *Client) embedBatch(ctx context.Context, model *genai.EmbeddingModel, batch *genai.EmbeddingBatch) (*genai.BatchEmbedContentsResponse, error) {
+	if timeout := c.requestTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	resp, err := model.BatchEmbedContents(ctx, batch)
+	if err != nil {
+		return nil, c.wrapOpError(opEmbed, model.Name(), err)
+	}
+	return resp, nil
+}
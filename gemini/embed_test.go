@@ -0,0 +1,66 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestGeminiClient_Embed(t *testing.T) {
+	var batches []int
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/models/text-embedding-004:batchEmbedContents") {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		var req struct {
+			Requests []json.RawMessage `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		batches = append(batches, len(req.Requests))
+
+		embeddings := make([]string, len(req.Requests))
+		for i := range embeddings {
+			embeddings[i] = fmt.Sprintf(`{"values": [%d, 0.5]}`, i)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"embeddings": [` + strings.Join(embeddings, ",") + `]}`))
+	})
+
+	texts := make([]string, maxEmbedBatch+2)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+	vectors, err := client.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(batches) != 2 || batches[0] != maxEmbedBatch || batches[1] != 2 {
+		t.Errorf("Expected texts split into batches of %d, got %v", maxEmbedBatch, batches)
+	}
+	if len(vectors) != len(texts) || vectors[1][0] != 1 || vectors[maxEmbedBatch+1][0] != 1 || vectors[0][1] != 0.5 {
+		t.Errorf("Unexpected embeddings: %d vectors, first %v", len(vectors), vectors[0])
+	}
+}
+
+func TestGeminiClient_Embed_Error(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/models/embedding-001:batchEmbedContents") {
+			t.Errorf("Expected the configured embedding model, got path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"code": 400, "message": "Request contains an invalid argument.", "status": "INVALID_ARGUMENT"}}`))
+	}, llm.WithEmbeddingModel("embedding-001"))
+
+	_, err := client.Embed(context.Background(), []string{"text"})
+	var llmErr *llm.Error
+	if !errors.As(err, &llmErr) || llmErr.Op != opEmbed || llmErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected an embed *llm.Error with the status, got: %v", err)
+	}
+}
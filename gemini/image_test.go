@@ -0,0 +1,47 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestGeminiClient_GenerateWithImages(t *testing.T) {
+	var sent struct {
+		Contents []struct {
+			Parts []struct {
+				Text       string `json:"text"`
+				InlineData *struct {
+					MIMEType string `json:"mimeType"`
+					Data     string `json:"data"`
+				} `json:"inlineData"`
+			} `json:"parts"`
+		} `json:"contents"`
+	}
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"candidates": [{"content": {"role": "model", "parts": [{"text": "A cat."}]}, "finishReason": 1}]}]`))
+	})
+
+	text, err := client.GenerateWithImages(context.Background(), "What is this?", []llm.Image{llm.ImageFromURL("data:image/png;base64,cG5n")})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != "A cat." {
+		t.Errorf("Unexpected text %q", text)
+	}
+	if len(sent.Contents) != 1 || len(sent.Contents[0].Parts) != 2 {
+		t.Fatalf("Expected the prompt and the image in one content, got %+v", sent.Contents)
+	}
+	parts := sent.Contents[0].Parts
+	if parts[0].Text != "What is this?" {
+		t.Errorf("Expected the prompt first, got %+v", parts[0])
+	}
+	if data := parts[1].InlineData; data == nil || data.MIMEType != "image/png" || data.Data != "cG5n" {
+		t.Errorf("Expected the image as inline data, got %+v", data)
+	}
+}
@@ -0,0 +1,133 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/xostack/xollm/llm"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	defaultAPIEndpoint = "https://generativelanguage.googleapis.com"
+	defaultImageModel  = "imagen-3.0-generate-002"
+	opGenerateImages   = "generate images"
+)
+
+// imagenRequest is the body of an Imagen predict request.
+type imagenRequest struct {
+	Instances  []imagenInstance `json:"instances"`
+	Parameters imagenParameters `json:"parameters"`
+}
+
+// imagenInstance holds the prompt of an Imagen request.
+type imagenInstance struct {
+	Prompt string `json:"prompt"`
+}
+
+// imagenParameters tune an Imagen request.
+type imagenParameters struct {
+	SampleCount int    `json:"sampleCount"`
+	AspectRatio string `json:"aspectRatio,omitempty"`
+	// IncludeRaiReason reports why images were filtered out
+	IncludeRaiReason bool `json:"includeRaiReason"`
+}
+
+// imagenResponse is the response of an Imagen predict request. Filtered
+// images are replaced by a prediction with only a reason.
+type imagenResponse struct {
+	Predictions []struct {
+		BytesBase64Encoded string `json:"bytesBase64Encoded"`
+		MIMEType           string `json:"mimeType"`
+		RaiFilteredReason  string `json:"raiFilteredReason"`
+	} `json:"predictions"`
+}
+
+// GenerateImages implements xollm.ImageGenerator with Google's Imagen
+// models, through the predict method of the Gemini API, which the SDK
+// doesn't cover. The model is the one set with llm.WithImageModel, or
+// imagen-3.0-generate-002. Images removed by the safety filters are left
+// out; if all are, a *llm.ContentFilteredError is returned. The client's
+// request timeout applies.
+func (c *Client) GenerateImages(ctx context.Context, prompt string, opts llm.ImageOptions) ([]llm.Image, error) {
+	if c.key() == "" {
+		return nil, fmt.Errorf("Gemini client not initialized")
+	}
+	model := c.options.ImageModel
+	if model == "" {
+		model = defaultImageModel
+	}
+	model = "models/" + strings.TrimPrefix(model, "models/")
+
+	count := opts.Count
+	if count <= 0 {
+		count = 1
+	}
+	payload, err := json.Marshal(imagenRequest{
+		Instances:  []imagenInstance{{Prompt: prompt}},
+		Parameters: imagenParameters{SampleCount: count, AspectRatio: opts.AspectRatio, IncludeRaiReason: true},
+	})
+	if err != nil {
+		return nil, llm.NewError(providerName, opGenerateImages, model, fmt.Errorf("failed to marshal request: %w", err))
+	}
+
+	if timeout := c.requestTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, restURL(model+":predict"), bytes.NewReader(payload))
+	if err != nil {
+		return nil, llm.NewError(providerName, opGenerateImages, model, fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	llm.Logger(ctx, c.logger).Debug("sending Imagen request", "model", model, "count", count)
+	resp, err := c.apiHTTPClient().Do(req)
+	if err != nil {
+		return nil, c.wrapOpError(opGenerateImages, model, fmt.Errorf("failed to send request: %w", err))
+	}
+	defer resp.Body.Close()
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return nil, c.wrapOpError(opGenerateImages, model, err)
+	}
+
+	var decoded imagenResponse
+	body, err := llm.DecodeJSON(resp.Body, &decoded)
+	if err != nil {
+		decodeErr := llm.NewError(providerName, opGenerateImages, model, fmt.Errorf("failed to decode response: %w", err))
+		decodeErr.StatusCode, decodeErr.Body = resp.StatusCode, body
+		return nil, decodeErr
+	}
+
+	var images []llm.Image
+	var filtered string
+	for _, p := range decoded.Predictions {
+		if p.BytesBase64Encoded == "" {
+			if p.RaiFilteredReason != "" {
+				filtered = p.RaiFilteredReason
+			}
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(p.BytesBase64Encoded)
+		if err != nil {
+			return nil, llm.NewError(providerName, opGenerateImages, model, fmt.Errorf("failed to decode image: %w", err))
+		}
+		img := llm.ImageFromBytes(data)
+		if p.MIMEType != "" {
+			img.MIMEType = p.MIMEType
+		}
+		images = append(images, img)
+	}
+	if len(images) == 0 {
+		// Imagen answers with no predictions at all when every image was
+		// filtered and no reason was given
+		return nil, &llm.ContentFilteredError{Provider: providerName, Stage: llm.StageResponse, Reason: filtered}
+	}
+	return images, nil
+}
@@ -0,0 +1,101 @@
+package gemini
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestGeminiClient_GenerateImages(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage data")
+	var sent imagenRequest
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/imagen-3.0-generate-002:predict" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "test-api-key" {
+			t.Errorf("Expected the API key header, got %q", got)
+		}
+		sent = imagenRequest{}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"predictions": []map[string]string{
+			{"bytesBase64Encoded": base64.StdEncoding.EncodeToString(png), "mimeType": "image/png"},
+			{"raiFilteredReason": "Filtered by the safety filter"},
+			{"bytesBase64Encoded": base64.StdEncoding.EncodeToString(png)},
+		}})
+	})
+
+	images, err := client.GenerateImages(context.Background(), "A lighthouse at dusk", llm.ImageOptions{Count: 3, AspectRatio: "16:9"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(sent.Instances) != 1 || sent.Instances[0].Prompt != "A lighthouse at dusk" {
+		t.Errorf("Unexpected instances %+v", sent.Instances)
+	}
+	if sent.Parameters.SampleCount != 3 || sent.Parameters.AspectRatio != "16:9" {
+		t.Errorf("Unexpected parameters %+v", sent.Parameters)
+	}
+	if len(images) != 2 {
+		t.Fatalf("Expected the filtered image to be left out, got %d images", len(images))
+	}
+	for _, img := range images {
+		if img.MIMEType != "image/png" || string(img.Data) != string(png) {
+			t.Errorf("Unexpected image %q of type %s", img.Data, img.MIMEType)
+		}
+	}
+}
+
+func TestGeminiClient_GenerateImagesModelAndDefaults(t *testing.T) {
+	var path string
+	var sent imagenRequest
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		sent = imagenRequest{}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"predictions": [{"bytesBase64Encoded": "aW1n", "mimeType": "image/jpeg"}]}`))
+	}, llm.WithImageModel("imagen-4.0-generate-001"))
+
+	if _, err := client.GenerateImages(context.Background(), "A cat", llm.ImageOptions{}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if path != "/v1beta/models/imagen-4.0-generate-001:predict" {
+		t.Errorf("Expected the configured image model, got path %s", path)
+	}
+	if sent.Parameters.SampleCount != 1 || sent.Parameters.AspectRatio != "" {
+		t.Errorf("Expected one image in the default aspect ratio, got %+v", sent.Parameters)
+	}
+}
+
+func TestGeminiClient_GenerateImagesErrors(t *testing.T) {
+	status := http.StatusOK
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(`{"error": {"code": 404, "message": "models/imagen-9 is not found", "status": "NOT_FOUND"}}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	_, err := client.GenerateImages(context.Background(), "Something filtered", llm.ImageOptions{})
+	if !errors.Is(err, llm.ErrContentFiltered) {
+		t.Errorf("Expected ErrContentFiltered when no image is returned, got: %v", err)
+	}
+
+	status = http.StatusNotFound
+	_, err = client.GenerateImages(context.Background(), "A cat", llm.ImageOptions{})
+	var llmErr *llm.Error
+	if !errors.As(err, &llmErr) || llmErr.Op != opGenerateImages || llmErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected an API error describing the image generation, got: %#v", err)
+	}
+	if !errors.Is(err, llm.ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// GenerateJSON implements xollm.JSONGenerator by setting the response MIME
+// type to application/json and schema, converted to Gemini's schema
+// format, as the response schema, which Gemini enforces while generating.
+func (c *Client) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	model, err := c.generativeModel(ctx)
+	if err != nil {
+		return "", err
+	}
+	responseSchema, err := convertSchema(schema)
+	if err != nil {
+		return "", fmt.Errorf("invalid response schema: %w", err)
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prompt); err != nil {
+			return "", err
+		}
+	}
+	// The handle is shared by all calls, so the settings go on a copy
+	copied := *model
+	copied.ResponseMIMEType = "application/json"
+	copied.ResponseSchema = responseSchema
+	text, _, err := c.generateWith(ctx, &copied, prompt)
+	return text, err
+}
@@ -0,0 +1,47 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGeminiClient_GenerateJSON(t *testing.T) {
+	var sent struct {
+		GenerationConfig struct {
+			ResponseMIMEType string `json:"responseMimeType"`
+			ResponseSchema   *struct {
+				Type       json.RawMessage            `json:"type"`
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"responseSchema"`
+		} `json:"generationConfig"`
+	}
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "{\"name\": \"Ada\"}"}]}, "finishReason": "STOP"}]}`))
+	})
+
+	text, err := client.GenerateJSON(context.Background(), "Who?", json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"}}}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != `{"name": "Ada"}` {
+		t.Errorf("Unexpected text %q", text)
+	}
+	config := sent.GenerationConfig
+	if config.ResponseMIMEType != "application/json" {
+		t.Errorf("Expected the JSON MIME type, got %q", config.ResponseMIMEType)
+	}
+	if config.ResponseSchema == nil || config.ResponseSchema.Properties["name"] == nil {
+		t.Errorf("Expected the converted response schema, got %+v", config.ResponseSchema)
+	}
+	if client.model.ResponseSchema != nil {
+		t.Error("Expected the shared model handle to be left unchanged")
+	}
+
+	if _, err := client.GenerateJSON(context.Background(), "Who?", json.RawMessage(`{"type":"tuple"}`)); err == nil {
+		t.Error("Expected an error for an unsupported schema type")
+	}
+}
@@ -0,0 +1,76 @@
+package gemini
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+)
+
+// Model operations, the Error.Op of their failures.
+const (
+	opModelInfo   = "model info"
+	opCountTokens = "count tokens"
+	opPing        = "ping"
+)
+
+// ModelInfo implements xollm.ModelInfoProvider using the Gemini models
+// API, which reports the input and output token limits. Modality flags
+// come from the bundled registry.
+func (c *Client) ModelInfo(ctx context.Context, model string) (llm.ModelInfo, error) {
+	sdk, err := c.sdkClient(ctx)
+	if err != nil {
+		return llm.ModelInfo{}, err
+	}
+	if model == "" {
+		model = c.modelName
+	}
+	model = strings.TrimPrefix(model, "models/")
+
+	described, err := sdk.GenerativeModel(model).Info(ctx)
+	if err != nil {
+		return llm.ModelInfo{}, c.wrapOpError(opModelInfo, "models/"+model, err)
+	}
+
+	info, _ := llm.LookupModel(model)
+	info.Name, info.Provider = model, providerName
+	if described.InputTokenLimit > 0 {
+		info.ContextWindow = int(described.InputTokenLimit)
+	}
+	if described.OutputTokenLimit > 0 {
+		info.MaxOutputTokens = int(described.OutputTokenLimit)
+	}
+	llm.RegisterModel(info)
+	return info, nil
+}
+
+// Ping implements xollm.Pinger by describing the client's model, which
+// costs no tokens but fails with ErrAuthentication for a bad API key and
+// ErrModelNotFound for a model Gemini doesn't serve.
+func (c *Client) Ping(ctx context.Context) error {
+	sdk, err := c.sdkClient(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := sdk.GenerativeModel(c.modelName).Info(ctx); err != nil {
+		return c.wrapOpError(opPing, c.endpoint(), err)
+	}
+	return nil
+}
+
+// CountTokens implements xollm.TokenCounter with Gemini's token counting
+// endpoint, so budgets for Gemini models are exact rather than estimated.
+// The count includes the client's system prompt, which is billed with
+// every request.
+func (c *Client) CountTokens(ctx context.Context, text string) (int, error) {
+	model, err := c.generativeModel(ctx)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := model.CountTokens(ctx, genai.Text(text))
+	if err != nil {
+		return 0, c.wrapOpError(opCountTokens, c.endpoint(), err)
+	}
+	return int(resp.TotalTokens), nil
+}
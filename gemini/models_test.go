@@ -0,0 +1,84 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestClient_CountTokens(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-1.5-flash:countTokens") {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"totalTokens": 42}`))
+	})
+
+	n, err := client.CountTokens(context.Background(), "How many tokens is this?")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if n != 42 {
+		t.Errorf("Expected 42 tokens, got %d", n)
+	}
+}
+
+func TestClient_CountTokens_Error(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"code": 429, "message": "Resource has been exhausted", "status": "RESOURCE_EXHAUSTED"}}`))
+	})
+
+	_, err := client.CountTokens(context.Background(), "text")
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.Op != "count tokens" || !errors.Is(err, llm.ErrRateLimited) {
+		t.Errorf("Expected count tokens *llm.Error matching ErrRateLimited, got: %v", err)
+	}
+}
+
+func TestClient_ModelInfo(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "models/test-gemini-model", "inputTokenLimit": 32768, "outputTokenLimit": 2048}`))
+	})
+
+	info, err := client.ModelInfo(context.Background(), "models/test-gemini-model")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if info.Name != "test-gemini-model" || info.ContextWindow != 32768 || info.MaxOutputTokens != 2048 {
+		t.Errorf("Unexpected model info: %+v", info)
+	}
+	if registered, ok := llm.LookupModel("test-gemini-model"); !ok || registered.ContextWindow != 32768 {
+		t.Errorf("Expected the model to be registered, got %+v", registered)
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-1.5-flash") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "models/missing is not found", "status": "NOT_FOUND"}}`))
+			return
+		}
+		w.Write([]byte(`{"name": "models/gemini-1.5-flash", "inputTokenLimit": 1048576}`))
+	})
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	client.modelName = "missing"
+	err := client.Ping(context.Background())
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.Op != "ping" || !errors.Is(err, llm.ErrModelNotFound) {
+		t.Errorf("Expected ping *llm.Error matching ErrModelNotFound, got: %v", err)
+	}
+}
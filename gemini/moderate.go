@@ -0,0 +1,100 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+)
+
+// moderationInstruction makes the model echo the text, so its safety
+// ratings describe the text rather than an answer to it.
+const moderationInstruction = "Repeat the user's message exactly as written, without any other text."
+
+// moderatedCategories are the harm categories Gemini rates.
+var moderatedCategories = []genai.HarmCategory{
+	genai.HarmCategoryHarassment,
+	genai.HarmCategoryHateSpeech,
+	genai.HarmCategorySexuallyExplicit,
+	genai.HarmCategoryDangerousContent,
+}
+
+// moderationNames maps the rating names of convertRatings to OpenAI-style
+// moderation names.
+var moderationNames = map[string]string{
+	"Harassment":       "harassment",
+	"HateSpeech":       "hate",
+	"SexuallyExplicit": "sexual",
+	"DangerousContent": "dangerous",
+}
+
+// Moderate implements xollm.ContentModerator with Gemini's safety ratings.
+// Gemini has no moderation endpoint, so the text is sent to the client's
+// model to be repeated with blocking turned off, and the ratings of the
+// prompt and of the repeated text are combined, taking the higher
+// probability per category. A category is flagged at a Medium or High
+// probability, Gemini's default blocking threshold; text Gemini blocks
+// regardless is flagged in every category it blocked. Each check costs
+// about twice the text's tokens.
+func (c *Client) Moderate(ctx context.Context, text string) (*llm.ModerationResult, error) {
+	model, err := c.generativeModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The handle is shared by all calls, so the settings go on a copy
+	copied := *model
+	copied.SystemInstruction = genai.NewUserContent(genai.Text(moderationInstruction))
+	copied.SetTemperature(0)
+	copied.SafetySettings = nil
+	for _, category := range moderatedCategories {
+		copied.SafetySettings = append(copied.SafetySettings, &genai.SafetySetting{Category: category, Threshold: genai.HarmBlockNone})
+	}
+
+	resp, err := c.generateContent(ctx, &copied, text)
+	var filtered *llm.ContentFilteredError
+	if errors.As(err, &filtered) {
+		return moderationResult(filtered.Ratings, true), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ratings []*genai.SafetyRating
+	if resp.PromptFeedback != nil {
+		ratings = append(ratings, resp.PromptFeedback.SafetyRatings...)
+	}
+	if len(resp.Candidates) > 0 {
+		ratings = append(ratings, resp.Candidates[0].SafetyRatings...)
+	}
+	return moderationResult(convertRatings(ratings), false), nil
+}
+
+// moderationResult combines ratings into a result, keeping the highest
+// score per category. A category is flagged if a rating was blocked or,
+// unless blocked is set, scores Medium or above. With blocked the result
+// is flagged even if no rating says why.
+func moderationResult(ratings []llm.SafetyRating, blocked bool) *llm.ModerationResult {
+	result := &llm.ModerationResult{Flagged: blocked, Scores: map[string]float64{}, Ratings: ratings}
+	flagged := map[string]bool{}
+	for _, r := range ratings {
+		name, ok := moderationNames[r.Category]
+		if !ok {
+			continue
+		}
+		score := llm.ProbabilityScore(r.Probability)
+		if current, seen := result.Scores[name]; !seen || score > current {
+			result.Scores[name] = score
+		}
+		if r.Blocked || (!blocked && score >= llm.ProbabilityScore("Medium")) {
+			flagged[name] = true
+		}
+	}
+	for name := range flagged {
+		result.Categories = append(result.Categories, name)
+	}
+	sort.Strings(result.Categories)
+	result.Flagged = result.Flagged || len(result.Categories) > 0
+	return result
+}
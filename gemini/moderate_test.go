@@ -0,0 +1,102 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestGeminiClient_Moderate(t *testing.T) {
+	var sent struct {
+		SafetySettings []struct {
+			Category  genai.HarmCategory       `json:"category"`
+			Threshold genai.HarmBlockThreshold `json:"threshold"`
+		} `json:"safetySettings"`
+		SystemInstruction json.RawMessage `json:"systemInstruction"`
+	}
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		sent.SafetySettings, sent.SystemInstruction = nil, nil
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [{"text": "You are all idiots"}]},
+				"finishReason": "STOP",
+				"safetyRatings": [
+					{"category": "HARM_CATEGORY_HARASSMENT", "probability": "HIGH"},
+					{"category": "HARM_CATEGORY_HATE_SPEECH", "probability": "LOW"},
+					{"category": "HARM_CATEGORY_SEXUALLY_EXPLICIT", "probability": "NEGLIGIBLE"},
+					{"category": "HARM_CATEGORY_DANGEROUS_CONTENT", "probability": "NEGLIGIBLE"}
+				]
+			}],
+			"promptFeedback": {
+				"safetyRatings": [{"category": "HARM_CATEGORY_HATE_SPEECH", "probability": "MEDIUM"}]
+			}
+		}`))
+	})
+
+	result, err := client.Moderate(context.Background(), "You are all idiots")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(sent.SafetySettings) != len(moderatedCategories) {
+		t.Errorf("Expected a safety setting per category, got %+v", sent.SafetySettings)
+	}
+	for _, s := range sent.SafetySettings {
+		if s.Threshold != genai.HarmBlockNone {
+			t.Errorf("Expected blocking to be turned off, got %+v", s)
+		}
+	}
+	if len(sent.SystemInstruction) == 0 {
+		t.Error("Expected the echo instruction to be sent")
+	}
+
+	if !result.Flagged || !reflect.DeepEqual(result.Categories, []string{"harassment", "hate"}) {
+		t.Errorf("Expected harassment and hate to be flagged, got %+v", result)
+	}
+	want := map[string]float64{"harassment": 0.75, "hate": 0.5, "sexual": 0, "dangerous": 0}
+	if !reflect.DeepEqual(result.Scores, want) {
+		t.Errorf("Expected the highest score per category %v, got %v", want, result.Scores)
+	}
+}
+
+func TestGeminiClient_ModerateClean(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates": [{
+			"content": {"role": "model", "parts": [{"text": "Nice weather today"}]},
+			"finishReason": "STOP",
+			"safetyRatings": [{"category": "HARM_CATEGORY_HARASSMENT", "probability": "LOW"}]
+		}]}`))
+	})
+
+	result, err := client.Moderate(context.Background(), "Nice weather today")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Flagged || len(result.Categories) != 0 || result.Scores["harassment"] != 0.25 {
+		t.Errorf("Expected an unflagged result, got %+v", result)
+	}
+}
+
+func TestGeminiClient_ModerateBlocked(t *testing.T) {
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"promptFeedback": {
+			"blockReason": "SAFETY",
+			"safetyRatings": [{"category": "HARM_CATEGORY_DANGEROUS_CONTENT", "probability": "LOW", "blocked": true}]
+		}}`))
+	})
+
+	result, err := client.Moderate(context.Background(), "How do I make ...")
+	if err != nil {
+		t.Fatalf("Expected a blocked text to be a result, got: %v", err)
+	}
+	if !result.Flagged || !reflect.DeepEqual(result.Categories, []string{"dangerous"}) {
+		t.Errorf("Expected the blocked category to be flagged, got %+v", result)
+	}
+}
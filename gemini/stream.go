@@ -0,0 +1,107 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+	"google.golang.org/api/iterator"
+)
+
+// GenerateStream implements xollm.Streamer. Gemini streams the response as
+// a series of partial responses, each carrying the next piece of text; the
+// token usage of the last one is delivered as the Usage of the Done chunk.
+// A prompt or response blocked by the safety settings ends the stream with
+// a *llm.ContentFilteredError. The client's request timeout covers the
+// whole stream.
+func (c *Client) GenerateStream(ctx context.Context, prompt string) (<-chan llm.StreamChunk, error) {
+	model, err := c.generativeModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prompt); err != nil {
+			return nil, err
+		}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if timeout := c.requestTimeout(ctx); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	llm.Logger(ctx, c.logger).Debug("sending Gemini streaming request", "model", c.modelName)
+	iter := model.GenerateContentStream(ctx, genai.Text(prompt))
+
+	chunks := make(chan llm.StreamChunk)
+	go func() {
+		defer close(chunks)
+		defer cancel()
+		c.readStream(ctx, iter, chunks)
+	}()
+	return chunks, nil
+}
+
+// streamEnded reports whether err, returned by a response iterator, ends
+// the stream normally. Some JSON decoders fail on the closing bracket of
+// the REST stream instead of ending it, so an error after the last
+// candidate came with its finish reason ends the stream too.
+func streamEnded(ctx context.Context, err error, finished bool) bool {
+	return errors.Is(err, iterator.Done) || (err != nil && finished && ctx.Err() == nil)
+}
+
+// readStream sends the text of the responses from iter as chunks until the
+// last response or an error. It stops early if ctx is cancelled while the
+// consumer is not reading.
+func (c *Client) readStream(ctx context.Context, iter *genai.GenerateContentResponseIterator, chunks chan<- llm.StreamChunk) {
+	emit := func(chunk llm.StreamChunk) bool {
+		select {
+		case chunks <- chunk:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var usage *llm.Usage
+	var finishReason string
+	finished := false
+	for {
+		resp, err := iter.Next()
+		if streamEnded(ctx, err, finished) {
+			emit(llm.StreamChunk{Done: true, Usage: usage, FinishReason: finishReason})
+			return
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("stream interrupted: %w", ctx.Err())
+			}
+			emit(llm.StreamChunk{Err: llm.WrapContextLength(c.modelName, c.wrapError(err))})
+			return
+		}
+		if blocked := blockedError(resp); blocked != nil {
+			emit(llm.StreamChunk{Err: blocked})
+			return
+		}
+		if resp.UsageMetadata != nil {
+			u := usageOf(resp)
+			usage = &u
+		}
+		if len(resp.Candidates) == 0 {
+			continue
+		}
+		finished = resp.Candidates[0].FinishReason != genai.FinishReasonUnspecified
+		if finished {
+			finishReason = finishReasonOf(resp.Candidates[0].FinishReason)
+		}
+		if resp.Candidates[0].Content == nil {
+			continue
+		}
+		for _, part := range resp.Candidates[0].Content.Parts {
+			if txt, ok := part.(genai.Text); ok && txt != "" && !emit(llm.StreamChunk{Text: string(txt)}) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+)
+
+// GenerateWithTools sends the prompt along with tools the model may call
+// and returns its text and function calls. An empty choice lets the model
+// decide; the name of one of the tools forces a call to it.
+func (c *Client) GenerateWithTools(ctx context.Context, prompt string, tools []llm.Tool, choice llm.ToolChoice) (*llm.ToolResponse, error) {
+	return c.ChatWithTools(ctx, []llm.Message{{Role: llm.RoleUser, Content: prompt}}, tools, choice)
+}
+
+// ChatWithTools is GenerateWithTools for a conversation, which may include
+// earlier tool calls and their results. Gemini doesn't identify function
+// calls, so each call gets an ID made of the function name and its
+// position in the response, which tool results refer to.
+func (c *Client) ChatWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool, choice llm.ToolChoice) (*llm.ToolResponse, error) {
+	declarations := make([]*genai.FunctionDeclaration, len(tools))
+	for i, tool := range tools {
+		params, err := convertSchema(tool.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameters of tool %s: %w", tool.Name, err)
+		}
+		declarations[i] = &genai.FunctionDeclaration{Name: tool.Name, Description: tool.Description, Parameters: params}
+	}
+
+	resp, err := c.sendChat(ctx, messages, func(model *genai.GenerativeModel) {
+		if len(declarations) > 0 {
+			model.Tools = []*genai.Tool{{FunctionDeclarations: declarations}}
+			model.ToolConfig = toolConfig(choice)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if blocked := blockedError(resp); blocked != nil {
+		return nil, blocked
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return nil, c.opError(fmt.Errorf("response was empty or malformed"))
+	}
+
+	candidate := resp.Candidates[0]
+	result := &llm.ToolResponse{FinishReason: finishReasonOf(candidate.FinishReason)}
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		switch p := part.(type) {
+		case genai.Text:
+			text.WriteString(string(p))
+		case genai.FunctionCall:
+			args, err := json.Marshal(p.Args)
+			if err != nil || p.Args == nil {
+				args = json.RawMessage("{}")
+			}
+			result.ToolCalls = append(result.ToolCalls, llm.ToolCall{
+				ID:        fmt.Sprintf("%s-%d", p.Name, len(result.ToolCalls)+1),
+				Name:      p.Name,
+				Arguments: args,
+			})
+		}
+	}
+	result.Text = strings.TrimSpace(text.String())
+	return result, nil
+}
+
+// toolConfig converts choice to Gemini's function calling mode, which
+// restricts the calls to one function to force it.
+func toolConfig(choice llm.ToolChoice) *genai.ToolConfig {
+	config := &genai.FunctionCallingConfig{}
+	switch choice {
+	case "", llm.ToolChoiceAuto:
+		config.Mode = genai.FunctionCallingAuto
+	case llm.ToolChoiceNone:
+		config.Mode = genai.FunctionCallingNone
+	case llm.ToolChoiceRequired:
+		config.Mode = genai.FunctionCallingAny
+	default:
+		config.Mode = genai.FunctionCallingAny
+		config.AllowedFunctionNames = []string{string(choice)}
+	}
+	return &genai.ToolConfig{FunctionCallingConfig: config}
+}
+
+// jsonSchema is the subset of JSON Schema that Gemini's OpenAPI-style
+// schemas can express.
+type jsonSchema struct {
+	Type        json.RawMessage            `json:"type"`
+	Format      string                     `json:"format"`
+	Description string                     `json:"description"`
+	Enum        []string                   `json:"enum"`
+	Items       json.RawMessage            `json:"items"`
+	Properties  map[string]json.RawMessage `json:"properties"`
+	Required    []string                   `json:"required"`
+}
+
+// schemaTypes maps JSON Schema type names to Gemini's.
+var schemaTypes = map[string]genai.Type{
+	"string":  genai.TypeString,
+	"number":  genai.TypeNumber,
+	"integer": genai.TypeInteger,
+	"boolean": genai.TypeBoolean,
+	"array":   genai.TypeArray,
+	"object":  genai.TypeObject,
+}
+
+// convertSchema converts a JSON Schema to Gemini's schema type. A list of
+// types is accepted if it is one type and "null", which makes the schema
+// nullable, and a schema with properties but no type is an object.
+// Keywords Gemini has no equivalent for are dropped. A nil schema converts
+// to nil.
+func convertSchema(raw json.RawMessage) (*genai.Schema, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var s jsonSchema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	converted := &genai.Schema{Format: s.Format, Description: s.Description, Enum: s.Enum, Required: s.Required}
+	var names []string
+	if err := json.Unmarshal(s.Type, &names); err != nil {
+		var name string
+		if len(s.Type) > 0 {
+			if err := json.Unmarshal(s.Type, &name); err != nil {
+				return nil, fmt.Errorf("invalid schema type %s", s.Type)
+			}
+		}
+		names = []string{name}
+	}
+	for _, name := range names {
+		switch name {
+		case "":
+			continue
+		case "null":
+			converted.Nullable = true
+			continue
+		}
+		if converted.Type != genai.TypeUnspecified {
+			return nil, fmt.Errorf("schema types %q are not supported", names)
+		}
+		converted.Type = schemaTypes[name]
+		if converted.Type == genai.TypeUnspecified {
+			return nil, fmt.Errorf("schema type %q is not supported", name)
+		}
+	}
+
+	if converted.Type == genai.TypeUnspecified && len(s.Properties) > 0 {
+		converted.Type = genai.TypeObject
+	}
+
+	var err error
+	if converted.Items, err = convertSchema(s.Items); err != nil {
+		return nil, err
+	}
+	if len(s.Properties) > 0 {
+		converted.Properties = make(map[string]*genai.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			if converted.Properties[name], err = convertSchema(prop); err != nil {
+				return nil, fmt.Errorf("property %s: %w", name, err)
+			}
+		}
+	}
+	return converted, nil
+}
@@ -0,0 +1,154 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/xostack/xollm/llm"
+)
+
+func TestGeminiClient_ChatWithTools(t *testing.T) {
+	var sent struct {
+		Contents []struct {
+			Role  string `json:"role"`
+			Parts []struct {
+				FunctionCall     *genai.FunctionCall     `json:"functionCall"`
+				FunctionResponse *genai.FunctionResponse `json:"functionResponse"`
+			} `json:"parts"`
+		} `json:"contents"`
+		Tools []struct {
+			FunctionDeclarations []struct {
+				Name string `json:"name"`
+			} `json:"functionDeclarations"`
+		} `json:"tools"`
+		ToolConfig struct {
+			FunctionCallingConfig struct {
+				Mode int `json:"mode"`
+			} `json:"functionCallingConfig"`
+		} `json:"toolConfig"`
+	}
+	client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"candidates": [{"content": {"role": "model", "parts": [` +
+			`{"functionCall": {"name": "get_weather", "args": {"city": "Bergen"}}},` +
+			`{"functionCall": {"name": "get_weather", "args": {"city": "Tromsø"}}}]}, "finishReason": 1}]}]`))
+	})
+
+	call := llm.ToolCall{ID: "get_weather-1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Oslo"}`)}
+	tools := []llm.Tool{{
+		Name:        "get_weather",
+		Description: "Get the current weather",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+	}}
+	resp, err := client.ChatWithTools(context.Background(), []llm.Message{
+		{Role: llm.RoleUser, Content: "Weather in Oslo, Bergen and Tromsø?"},
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{call}},
+		llm.ToolResultMessage(call, `{"celsius":12}`),
+	}, tools, llm.ToolChoiceRequired)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(resp.ToolCalls) != 2 || resp.FinishReason != "Stop" {
+		t.Fatalf("Expected two tool calls, got %+v", resp)
+	}
+	if got := resp.ToolCalls[1]; got.ID != "get_weather-2" || got.Name != "get_weather" || string(got.Arguments) != `{"city":"Tromsø"}` {
+		t.Errorf("Unexpected tool call: %+v", got)
+	}
+
+	if len(sent.Contents) != 3 {
+		t.Fatalf("Expected three contents, got %+v", sent.Contents)
+	}
+	if fc := sent.Contents[1].Parts[0].FunctionCall; sent.Contents[1].Role != "model" || fc == nil || fc.Name != "get_weather" || fc.Args["city"] != "Oslo" {
+		t.Errorf("Expected the earlier tool call as a function call, got %+v", sent.Contents[1])
+	}
+	if fr := sent.Contents[2].Parts[0].FunctionResponse; fr == nil || fr.Name != "get_weather" || fr.Response["celsius"] != float64(12) {
+		t.Errorf("Expected the tool result as a function response, got %+v", sent.Contents[2])
+	}
+	if len(sent.Tools) != 1 || len(sent.Tools[0].FunctionDeclarations) != 1 || sent.Tools[0].FunctionDeclarations[0].Name != "get_weather" {
+		t.Errorf("Expected the tool as a function declaration, got %+v", sent.Tools)
+	}
+	if mode := genai.FunctionCallingMode(sent.ToolConfig.FunctionCallingConfig.Mode); mode != genai.FunctionCallingAny {
+		t.Errorf("Expected a required tool call as mode Any, got %v", mode)
+	}
+	if client.model.Tools != nil {
+		t.Error("Expected the shared model handle to be left unchanged")
+	}
+}
+
+func TestChatContents_ToolResults(t *testing.T) {
+	calls := []llm.ToolCall{{ID: "a-1", Name: "a"}, {ID: "b-2", Name: "b"}}
+	contents := chatContents([]llm.Message{
+		{Role: llm.RoleUser, Content: "Run a and b"},
+		{Role: llm.RoleAssistant, ToolCalls: calls},
+		llm.ToolResultMessage(calls[0], "done"),
+		llm.ToolResultMessage(calls[1], "[1, 2]"),
+	})
+	if len(contents) != 3 {
+		t.Fatalf("Expected the results of one turn in one content, got %d contents", len(contents))
+	}
+	parts := contents[2].Parts
+	if len(parts) != 2 {
+		t.Fatalf("Expected two function responses, got %+v", parts)
+	}
+	first, second := parts[0].(genai.FunctionResponse), parts[1].(genai.FunctionResponse)
+	if first.Name != "a" || first.Response["result"] != "done" {
+		t.Errorf("Expected a text result wrapped in an object, got %+v", first)
+	}
+	if values, ok := second.Response["result"].([]any); second.Name != "b" || !ok || len(values) != 2 {
+		t.Errorf("Expected a JSON array result wrapped in an object, got %+v", second)
+	}
+}
+
+func TestConvertSchema(t *testing.T) {
+	schema, err := convertSchema(json.RawMessage(`{
+		"properties": {
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["a", "b"]}},
+			"count": {"type": ["integer", "null"], "description": "How many"}
+		},
+		"required": ["tags"]
+	}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if schema.Type != genai.TypeObject || len(schema.Required) != 1 {
+		t.Errorf("Expected an object with required properties, got %+v", schema)
+	}
+	if tags := schema.Properties["tags"]; tags.Type != genai.TypeArray || tags.Items.Type != genai.TypeString || len(tags.Items.Enum) != 2 {
+		t.Errorf("Unexpected array property %+v", tags)
+	}
+	if count := schema.Properties["count"]; count.Type != genai.TypeInteger || !count.Nullable || count.Description != "How many" {
+		t.Errorf("Unexpected nullable property %+v", count)
+	}
+
+	if _, err := convertSchema(json.RawMessage(`{"type": ["string", "integer"]}`)); err == nil {
+		t.Error("Expected an error for a union of types")
+	}
+	if schema, err := convertSchema(nil); schema != nil || err != nil {
+		t.Errorf("Expected no schema for no parameters, got %+v, %v", schema, err)
+	}
+}
+
+func TestToolConfig(t *testing.T) {
+	tests := []struct {
+		choice  llm.ToolChoice
+		mode    genai.FunctionCallingMode
+		allowed []string
+	}{
+		{"", genai.FunctionCallingAuto, nil},
+		{llm.ToolChoiceNone, genai.FunctionCallingNone, nil},
+		{llm.ToolChoiceRequired, genai.FunctionCallingAny, nil},
+		{"get_weather", genai.FunctionCallingAny, []string{"get_weather"}},
+	}
+	for _, tt := range tests {
+		config := toolConfig(tt.choice).FunctionCallingConfig
+		if config.Mode != tt.mode || strings.Join(config.AllowedFunctionNames, ",") != strings.Join(tt.allowed, ",") {
+			t.Errorf("toolConfig(%q) = %+v", tt.choice, config)
+		}
+	}
+}
@@ -7,6 +7,7 @@ toolchain go1.23.11
 require (
 	github.com/BurntSushi/toml v1.5.0
 	github.com/google/generative-ai-go v0.20.1
+	github.com/mattn/go-sqlite3 v1.14.33
 	google.golang.org/api v0.242.0
 )
 
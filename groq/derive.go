@@ -0,0 +1,66 @@
+package groq
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// WithModel returns a client that sends its requests to model. It shares
+// c's HTTP client, API key and rate limit state, so deriving a client per
+// model is cheap. "" keeps c's model.
+func (c *Client) WithModel(model string) *Client {
+	d := c.derive()
+	if model != "" {
+		d.modelName = model
+	}
+	return d
+}
+
+// WithTimeout returns a client whose requests, including reading their
+// responses, time out after d. It shares c's transport, API key and rate
+// limit state. A d of 0 or less disables the client timeout, leaving only
+// the context's deadline.
+func (c *Client) WithTimeout(d time.Duration) *Client {
+	derived := c.derive()
+	if c.httpClient != nil {
+		httpClient := *c.httpClient
+		httpClient.Timeout = max(d, 0)
+		derived.httpClient = &httpClient
+	}
+	return derived
+}
+
+// requestClient returns the HTTP client for a request sent with ctx: c's,
+// or a copy timing out after the timeout set with llm.WithRequestTimeout.
+func (c *Client) requestClient(ctx context.Context) *http.Client {
+	d, ok := llm.RequestTimeoutFromContext(ctx)
+	if !ok {
+		return c.httpClient
+	}
+	httpClient := *c.httpClient
+	httpClient.Timeout = d
+	return &httpClient
+}
+
+// derive returns a client with c's settings that shares its HTTP client,
+// API key and rate limit state.
+func (c *Client) derive() *Client {
+	return &Client{
+		httpClient: c.httpClient,
+		modelName:  c.modelName,
+		options:    c.options,
+		logger:     c.logger,
+		parent:     c.root(),
+	}
+}
+
+// root returns the client c was derived from, or c itself.
+func (c *Client) root() *Client {
+	if c.parent != nil {
+		return c.parent
+	}
+	return c
+}
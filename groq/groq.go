@@ -6,11 +6,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/xostack/xollm/llm"
 )
 
 const (
@@ -21,17 +23,66 @@ const (
 	retryDelay       = 1 * time.Second
 )
 
-// Client implements the llm.Client interface for Groq.
+// Client implements the llm.Client interface for Groq. A Client is safe for
+// concurrent use: its settings don't change after NewClient, and the API
+// key and rate limit state, which do, are guarded by locks. Share one
+// client between goroutines rather than creating one per worker.
 type Client struct {
 	httpClient *http.Client
-	apiKey     string
 	modelName  string
+	options    llm.ClientOptions
+	logger     *slog.Logger // nil for llm.DefaultLogger
+
+	keyMu  sync.RWMutex // Guards apiKey, which SetCredentials replaces
+	apiKey string
+
+	rateLimits llm.RateLimitTracker
+
+	// parent is the client this one was derived from with WithModel or
+	// WithTimeout, whose API key and rate limit state it uses. It is nil
+	// for clients created with NewClient.
+	parent *Client
 }
 
 // groqChatMessage represents a single message in the chat completion request.
 type groqChatMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// ToolCalls are the calls made by an earlier assistant message
+	ToolCalls []groqToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID is the call a tool message answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Images are sent as content parts, see MarshalJSON
+	Images []llm.Image `json:"-"`
+}
+
+// groqContentPart is a text or image part of a message's content.
+type groqContentPart struct {
+	Type     string        `json:"type"` // "text" or "image_url"
+	Text     string        `json:"text,omitempty"`
+	ImageURL *groqImageURL `json:"image_url,omitempty"`
+}
+
+// groqImageURL locates an image by URL or as a data: URL.
+type groqImageURL struct {
+	URL string `json:"url"`
+}
+
+// MarshalJSON sends the content of messages with images as a list of text
+// and image parts, which vision models accept in user messages.
+func (m groqChatMessage) MarshalJSON() ([]byte, error) {
+	type plain groqChatMessage
+	if len(m.Images) == 0 {
+		return json.Marshal(plain(m))
+	}
+	parts := []groqContentPart{{Type: "text", Text: m.Content}}
+	for _, img := range m.Images {
+		parts = append(parts, groqContentPart{Type: "image_url", ImageURL: &groqImageURL{URL: img.DataURL()}})
+	}
+	return json.Marshal(struct {
+		plain
+		Content []groqContentPart `json:"content"`
+	}{plain(m), parts})
 }
 
 // groqChatCompletionRequest is the structure for the request body to Groq's API.
@@ -41,14 +92,71 @@ type groqChatCompletionRequest struct {
 	Temperature *float64          `json:"temperature,omitempty"` // Pointer to allow omitting if zero value is desired
 	MaxTokens   *int              `json:"max_tokens,omitempty"`
 	TopP        *float64          `json:"top_p,omitempty"`
-	Stream      bool              `json:"stream"` // We'll use false
-	// Stop        []string          `json:"stop,omitempty"` // Not used for now
+	Stop        []string          `json:"stop,omitempty"`
+	Seed        *int              `json:"seed,omitempty"`
+	Stream      bool              `json:"stream"`
+	// StreamOptions asks for the usage in the last streamed chunk
+	StreamOptions *groqStreamOptions `json:"stream_options,omitempty"`
+	Tools         []groqTool         `json:"tools,omitempty"`
+	ToolChoice    interface{}        `json:"tool_choice,omitempty"` // A mode string or groqNamedToolChoice
+	// ResponseFormat constrains the response, e.g. to a JSON object
+	ResponseFormat *groqResponseFormat `json:"response_format,omitempty"`
+	// Logprobs asks for the log probabilities of the generated tokens, and
+	// TopLogprobs for that many alternatives at each position
+	Logprobs    bool `json:"logprobs,omitempty"`
+	TopLogprobs *int `json:"top_logprobs,omitempty"`
+	// PresencePenalty and FrequencyPenalty discourage repeated tokens
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+}
+
+// setSampling fills the request's sampling parameters; nil fields are
+// omitted so Groq applies its defaults.
+func (r *groqChatCompletionRequest) setSampling(s llm.Sampling) {
+	r.Temperature, r.MaxTokens, r.TopP, r.Stop, r.Seed = s.Temperature, s.MaxTokens, s.TopP, s.Stop, s.Seed
+	r.PresencePenalty, r.FrequencyPenalty = s.PresencePenalty, s.FrequencyPenalty
+}
+
+// groqTool is a function the model may call.
+type groqTool struct {
+	Type     string       `json:"type"` // Always "function"
+	Function groqFunction `json:"function"`
+}
+
+// groqFunction describes a callable function and its JSON Schema
+// parameters.
+type groqFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// groqNamedToolChoice forces a call to one function.
+type groqNamedToolChoice struct {
+	Type     string           `json:"type"` // Always "function"
+	Function groqFunctionName `json:"function"`
+}
+
+// groqFunctionName names a function in a tool choice.
+type groqFunctionName struct {
+	Name string `json:"name"`
+}
+
+// groqToolCall is a function call requested by the model.
+type groqToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // A JSON object encoded as a string
+	} `json:"function"`
 }
 
 // groqChatCompletionResponseChoiceMessage is the message part of a choice.
 type groqChatCompletionResponseChoiceMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role      string         `json:"role"`
+	Content   string         `json:"content"`
+	ToolCalls []groqToolCall `json:"tool_calls,omitempty"`
 }
 
 // groqChatCompletionResponseChoice is a single choice in the response.
@@ -56,14 +164,23 @@ type groqChatCompletionResponseChoice struct {
 	Index        int                                     `json:"index"`
 	Message      groqChatCompletionResponseChoiceMessage `json:"message"`
 	FinishReason string                                  `json:"finish_reason"`
-	// LogProbs     interface{}                           `json:"logprobs,omitempty"` // Not used for now
+	Logprobs     *groqLogprobs                           `json:"logprobs,omitempty"`
 }
 
-// groqUsage tracks token usage.
+// groqUsage tracks token usage and Groq's processing times in seconds.
 type groqUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	QueueTime        float64 `json:"queue_time,omitempty"`
+	PromptTime       float64 `json:"prompt_time,omitempty"`
+	CompletionTime   float64 `json:"completion_time,omitempty"`
+	TotalTime        float64 `json:"total_time,omitempty"`
+}
+
+// groqExtensions holds Groq's non-OpenAI response fields.
+type groqExtensions struct {
+	ID string `json:"id"` // The request ID, e.g. "req_01j..."
 }
 
 // groqChatCompletionResponse is the structure for the response from Groq's API.
@@ -74,34 +191,70 @@ type groqChatCompletionResponse struct {
 	Model   string                             `json:"model"`
 	Choices []groqChatCompletionResponseChoice `json:"choices"`
 	Usage   groqUsage                          `json:"usage"`
+	XGroq   *groqExtensions                    `json:"x_groq,omitempty"`
 	// SystemFingerprint string                             `json:"system_fingerprint,omitempty"` // Not used for now
-	Error *struct { // Groq might return an error object directly
-		Message string `json:"message"`
-		Type    string `json:"type"`
-		Param   string `json:"param,omitempty"`
-		Code    string `json:"code,omitempty"`
-	} `json:"error,omitempty"`
+	Error *groqAPIError `json:"error,omitempty"` // Groq might return an error object directly
+
+	requestID string // From the X-Request-Id header, set by complete
+}
+
+// metadata returns the response's model, request ID, finish reason, usage,
+// estimated cost and timing.
+func (r *groqChatCompletionResponse) metadata() *llm.ResponseMetadata {
+	meta := &llm.ResponseMetadata{
+		Model:     r.Model,
+		RequestID: r.requestID,
+		Usage: llm.Usage{
+			PromptTokens:     r.Usage.PromptTokens,
+			CompletionTokens: r.Usage.CompletionTokens,
+		},
+	}
+	if meta.RequestID == "" && r.XGroq != nil {
+		meta.RequestID = r.XGroq.ID
+	}
+	if len(r.Choices) > 0 {
+		meta.FinishReason = r.Choices[0].FinishReason
+		meta.Logprobs = r.Choices[0].Logprobs.convert()
+	}
+	if u := r.Usage; u.QueueTime > 0 || u.TotalTime > 0 {
+		meta.Timing = &llm.Timing{
+			Queue:      llm.Seconds(u.QueueTime),
+			Prompt:     llm.Seconds(u.PromptTime),
+			Completion: llm.Seconds(u.CompletionTime),
+			Total:      llm.Seconds(u.TotalTime),
+		}
+	}
+	return meta.WithEstimatedCost(providerName)
+}
+
+// groqAPIError is the error object in a failed Groq response.
+type groqAPIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Param   string `json:"param,omitempty"`
+	Code    string `json:"code,omitempty"`
 }
 
 // NewClient creates a new Groq client.
 // ctx is used for timeout configuration and cancellation.
-// debugMode controls verbose logging.
-func NewClient(ctx context.Context, apiKey string, modelOverride string, requestTimeoutSeconds int, debugMode bool) (*Client, error) {
+// debugMode is deprecated and should be false; true still logs debug
+// messages to standard error when no llm.WithLogger option is given, like
+// llm.WithLogger(llm.DebugLogger()).
+// opts enable optional behaviour such as llm.WithPreflightTokenCheck.
+func NewClient(ctx context.Context, apiKey string, modelOverride string, requestTimeoutSeconds int, debugMode bool, opts ...llm.ClientOption) (*Client, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("groq API key is required")
 	}
 
+	options := llm.ApplyOptions(opts)
+	clientLogger := options.ClientLogger(debugMode)
+	logger := llm.Logger(ctx, clientLogger)
+
 	modelToUse := defaultGroqModel
 	if modelOverride != "" {
 		modelToUse = modelOverride
-		if debugMode {
-			log.Printf("Using overridden Groq model: %s", modelToUse)
-		}
-	} else {
-		if debugMode {
-			log.Printf("Using default Groq model: %s", modelToUse)
-		}
 	}
+	logger.Debug("using Groq model", "model", modelToUse, "overridden", modelOverride != "")
 
 	// Use context timeout if requestTimeoutSeconds is 0
 	timeout := time.Duration(requestTimeoutSeconds) * time.Second
@@ -109,59 +262,221 @@ func NewClient(ctx context.Context, apiKey string, modelOverride string, request
 		// Check if context has a deadline
 		if deadline, ok := ctx.Deadline(); ok {
 			timeout = time.Until(deadline)
-			if debugMode {
-				log.Printf("Using context deadline for timeout: %v", timeout)
-			}
+			logger.Debug("using context deadline for timeout", "timeout", timeout)
 		} else {
 			timeout = 60 * time.Second // Default fallback
-			if debugMode {
-				log.Printf("Using default timeout: %v", timeout)
-			}
+			logger.Debug("using default timeout", "timeout", timeout)
 		}
 	}
 
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: options.Transport(llm.SharedTransport()), // Pool connections across clients
 		},
 		apiKey:    apiKey,
 		modelName: modelToUse,
+		options:   options,
+		logger:    clientLogger,
 	}, nil
 }
 
 // Generate sends the prompt to the Groq model and returns the text response.
 // For Groq's chat completion, we need to adapt our single prompt into a user message.
 func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
-	if c.httpClient == nil {
-		return "", fmt.Errorf("groq client not initialized")
+	return c.GenerateWithSampling(ctx, prompt, llm.Sampling{})
+}
+
+// GenerateWithSampling is like Generate, but the fields set in sampling
+// override the client's default sampling parameters for this call.
+func (c *Client) GenerateWithSampling(ctx context.Context, prompt string, sampling llm.Sampling) (string, error) {
+	text, _, err := c.generate(ctx, prompt, sampling)
+	return text, err
+}
+
+// GenerateWithMetadata is like Generate, but also returns the response's
+// metadata, including Groq's timing breakdown: how long the request queued
+// and how long the prompt and completion took, which separates provider
+// queuing from network latency.
+func (c *Client) GenerateWithMetadata(ctx context.Context, prompt string) (string, *llm.ResponseMetadata, error) {
+	text, resp, err := c.generate(ctx, prompt, llm.Sampling{})
+	if err != nil {
+		return "", nil, err
 	}
+	return text, resp.metadata(), nil
+}
 
-	// Groq's chat completion API expects a list of messages.
-	// We'll create a simple conversation with the system prompt (agent) and user prompt (task + input).
-	// The LLM agent prompt follows a standard format for command line filtering:
-	// "You are a Unix command line filter, you will follow the instructions below to transform, translate, convert, edit or modify the input provided below to the desired outcome."
-	// The `prompt` variable here is the fully constructed prompt from `prompt.Build`
-	// which already includes the agent prompt, user task, and input data.
-	// For OpenAI-compatible APIs, it's common to send the "system" part as a separate message.
-	// However, our `prompt.Build` combines everything. For simplicity with the current
-	// prompt structure, we'll send the entire combined prompt as a single "user" message.
-	// If better results are achieved by separating system/user roles, `prompt.Build` and this section
-	// would need adjustment.
+// generate sends the prompt as a user message and returns the trimmed text
+// and the full response.
+func (c *Client) generate(ctx context.Context, prompt string, sampling llm.Sampling) (string, *groqChatCompletionResponse, error) {
+	// The prompt, which may combine instructions, task and input, is sent
+	// as a single user message; Chat sends conversations with their roles.
+	return c.chat(ctx, []groqChatMessage{{Role: "user", Content: prompt}}, prompt, sampling)
+}
 
-	messages := []groqChatMessage{
-		{Role: "user", Content: prompt},
+// Chat implements xollm.Chatter, sending the messages with their roles to
+// the chat completions API.
+func (c *Client) Chat(ctx context.Context, messages []llm.Message) (string, *llm.ResponseMetadata, error) {
+	if err := llm.CheckMessages(messages); err != nil {
+		return "", nil, err
+	}
+	text, resp, err := c.chat(ctx, convertMessages(messages), llm.FlattenMessages(messages), llm.Sampling{})
+	if err != nil {
+		return "", nil, err
+	}
+	return text, resp.metadata(), nil
+}
+
+// GenerateWithImages implements xollm.VisionGenerator, sending the prompt
+// and images as the parts of a user message. Images with data are sent as
+// data: URLs; Groq fetches the others itself.
+func (c *Client) GenerateWithImages(ctx context.Context, prompt string, images []llm.Image) (string, error) {
+	text, _, err := c.chat(ctx, []groqChatMessage{{Role: "user", Content: prompt, Images: images}}, prompt, llm.Sampling{})
+	return text, err
+}
+
+// chat sends messages and returns the trimmed text and the full response.
+// prompt is the messages' text, for the pre-flight check.
+func (c *Client) chat(ctx context.Context, messages []groqChatMessage, prompt string, sampling llm.Sampling) (string, *groqChatCompletionResponse, error) {
+	if c.httpClient == nil {
+		return "", nil, fmt.Errorf("groq client not initialized")
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prompt); err != nil {
+			return "", nil, err
+		}
 	}
 
 	payload := groqChatCompletionRequest{
-		Messages: messages,
+		Messages: c.withSystemPrompt(messages),
 		Model:    c.modelName,
 		Stream:   false, // Expects full response
-		// Temperature: &temp, // Example: can be configurable later
+	}
+	payload.setSampling(c.options.Sampling.Override(sampling))
+
+	resp, err := c.complete(ctx, payload)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), resp, nil
+}
+
+// GenerateWithTools sends the prompt along with tools the model may call
+// and returns its text and tool calls. An empty choice lets the model
+// decide; the name of one of the tools forces a call to it.
+func (c *Client) GenerateWithTools(ctx context.Context, prompt string, tools []llm.Tool, choice llm.ToolChoice) (*llm.ToolResponse, error) {
+	return c.ChatWithTools(ctx, []llm.Message{{Role: llm.RoleUser, Content: prompt}}, tools, choice)
+}
+
+// ChatWithTools is GenerateWithTools for a conversation, which may include
+// earlier tool calls and their results.
+func (c *Client) ChatWithTools(ctx context.Context, messages []llm.Message, tools []llm.Tool, choice llm.ToolChoice) (*llm.ToolResponse, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("groq client not initialized")
+	}
+	if err := llm.CheckMessages(messages); err != nil {
+		return nil, err
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, llm.FlattenMessages(messages)); err != nil {
+			return nil, err
+		}
+	}
+
+	payload := groqChatCompletionRequest{
+		Messages: c.withSystemPrompt(convertMessages(messages)),
+		Model:    c.modelName,
+		Tools:    convertTools(tools),
+	}
+	payload.setSampling(c.options.Sampling)
+	if len(tools) > 0 {
+		payload.ToolChoice = convertToolChoice(choice)
 	}
 
+	completion, err := c.complete(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	result := completion.Choices[0]
+	resp := &llm.ToolResponse{
+		Text:         strings.TrimSpace(result.Message.Content),
+		FinishReason: result.FinishReason,
+	}
+	for _, call := range result.Message.ToolCalls {
+		args := json.RawMessage(call.Function.Arguments)
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		resp.ToolCalls = append(resp.ToolCalls, llm.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: args,
+		})
+	}
+	return resp, nil
+}
+
+// complete sends a chat completion request, retrying transient network
+// failures, and returns the response, which has at least one choice. A
+// response without content or tool calls is an error.
+func (c *Client) complete(ctx context.Context, payload groqChatCompletionRequest) (*groqChatCompletionResponse, error) {
+	resp, err := c.post(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Decode the response straight from the body, keeping its start for
+	// error reports
+	var groqResp groqChatCompletionResponse
+	responseBody, err := llm.DecodeJSON(resp.Body, &groqResp)
+	if err != nil {
+		if resp.StatusCode != http.StatusOK {
+			// Error responses from proxies and gateways are often not JSON
+			return nil, llm.WrapContextLength(c.modelName, newAPIError(resp, responseBody, nil))
+		}
+		// Keep the raw response for debugging if JSON parsing fails
+		decodeErr := c.opError(fmt.Errorf("failed to decode response: %w", err))
+		decodeErr.StatusCode, decodeErr.Body = resp.StatusCode, responseBody
+		return nil, decodeErr
+	}
+
+	// Check for API-level errors returned in the JSON body, then the HTTP status
+	if groqResp.Error != nil || resp.StatusCode != http.StatusOK {
+		return nil, llm.WrapContextLength(c.modelName, newAPIError(resp, responseBody, groqResp.Error))
+	}
+
+	if len(groqResp.Choices) == 0 || (groqResp.Choices[0].Message.Content == "" && len(groqResp.Choices[0].Message.ToolCalls) == 0) {
+		// This could also indicate a content filter or other issue.
+		llm.Logger(ctx, c.logger).Debug("Groq returned an empty response",
+			"id", groqResp.ID, "model", groqResp.Model,
+			"finish_reason", func() string {
+				if len(groqResp.Choices) > 0 {
+					return groqResp.Choices[0].FinishReason
+				}
+				return "N/A"
+			}(),
+			"usage", groqResp.Usage)
+		emptyErr := newAPIError(resp, responseBody, nil)
+		emptyErr.Message = "response contained no choices or empty message content"
+		emptyErr.Kind = nil
+		if len(groqResp.Choices) > 0 && groqResp.Choices[0].FinishReason == "content_filter" {
+			emptyErr.Kind = llm.ErrContentFiltered
+		}
+		return nil, emptyErr
+	}
+
+	groqResp.requestID = resp.Header.Get("X-Request-Id")
+	return &groqResp, nil
+}
+
+// post sends a chat completion request, retrying transient network
+// failures, and returns the response, whose body the caller must close.
+// The response's rate limit headers are recorded.
+func (c *Client) post(ctx context.Context, payload groqChatCompletionRequest) (*http.Response, error) {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal Groq request payload: %w", err)
+		return nil, c.opError(fmt.Errorf("failed to marshal request: %w", err))
 	}
 
 	var resp *http.Response
@@ -170,70 +485,213 @@ func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
 	for i := 0; i <= maxRetries; i++ {
 		req, reqErr := http.NewRequestWithContext(ctx, "POST", groqAPIEndpoint, bytes.NewBuffer(payloadBytes))
 		if reqErr != nil {
-			return "", fmt.Errorf("failed to create Groq request: %w", reqErr)
+			return nil, c.opError(fmt.Errorf("failed to create request: %w", reqErr))
 		}
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		c.authorize(req)
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Accept", "application/json")
+		if payload.Stream {
+			req.Header.Set("Accept", "text/event-stream")
+		} else {
+			req.Header.Set("Accept", "application/json")
+		}
+		if err := llm.InterceptDryRun(providerName, req); err != nil {
+			return nil, err
+		}
 
+		llm.Logger(ctx, c.logger).Debug("sending Groq request", "model", payload.Model, "attempt", i+1)
 		respErr := func() error {
 			var err error
-			resp, err = c.httpClient.Do(req)
+			resp, err = c.requestClient(ctx).Do(req)
 			return err
 		}()
 		if respErr != nil {
-			lastErr = fmt.Errorf("failed to send request to Groq API: %w", respErr)
-			if ctx.Err() == context.Canceled || ctx.Err() == context.DeadlineExceeded {
-				return "", lastErr // Don't retry on context errors
+			lastErr = c.opError(fmt.Errorf("failed to send request: %w", respErr))
+			if ctx.Err() != nil || !llm.IsRetryable(lastErr) || i == maxRetries {
+				return nil, lastErr // Don't retry on context errors or failures that won't go away
 			}
-			log.Printf("Groq request attempt %d failed: %v. Retrying in %v...", i+1, respErr, retryDelay)
+			llm.Logger(ctx, c.logger).Warn("Groq request failed, retrying", "attempt", i+1, "error", respErr, "delay", retryDelay)
 			time.Sleep(retryDelay)
 			continue
 		}
 		// If request was successful (even if API returned an error status), break retry loop
+		lastErr = nil
 		break
 	}
 	if lastErr != nil { // This means all retries failed
-		return "", lastErr
+		return nil, lastErr
 	}
-	defer resp.Body.Close()
+	c.root().rateLimits.Update(resp.Header)
+	llm.CaptureRawResponse(resp)
+	return resp, nil
+}
 
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read Groq response body: %w", err)
+// withSystemPrompt returns messages preceded by the client's system
+// prompt, if any, as a system message.
+func (c *Client) withSystemPrompt(messages []groqChatMessage) []groqChatMessage {
+	if c.options.SystemPrompt == "" {
+		return messages
 	}
+	return append([]groqChatMessage{{Role: string(llm.RoleSystem), Content: c.options.SystemPrompt}}, messages...)
+}
 
-	var groqResp groqChatCompletionResponse
-	if err := json.Unmarshal(responseBody, &groqResp); err != nil {
-		// Include raw response for debugging if JSON parsing fails
-		return "", fmt.Errorf("failed to unmarshal Groq response JSON: %w. Status: %s, Body: %s", err, resp.Status, string(responseBody))
+// convertMessages converts messages to chat completion messages, with the
+// tool calls of assistant messages and the call IDs of tool results.
+func convertMessages(messages []llm.Message) []groqChatMessage {
+	converted := make([]groqChatMessage, len(messages))
+	for i, m := range messages {
+		converted[i] = groqChatMessage{Role: string(m.Role), Content: m.Content, ToolCallID: m.ToolCallID, Images: m.Images}
+		for _, call := range m.ToolCalls {
+			gc := groqToolCall{ID: call.ID, Type: "function"}
+			gc.Function.Name = call.Name
+			gc.Function.Arguments = string(call.Arguments)
+			converted[i].ToolCalls = append(converted[i].ToolCalls, gc)
+		}
+	}
+	return converted
+}
+
+// convertTools converts tools to Groq's function tool definitions.
+func convertTools(tools []llm.Tool) []groqTool {
+	if len(tools) == 0 {
+		return nil
+	}
+	converted := make([]groqTool, len(tools))
+	for i, tool := range tools {
+		params := tool.Parameters
+		if len(params) == 0 {
+			params = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		converted[i] = groqTool{
+			Type: "function",
+			Function: groqFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  params,
+			},
+		}
 	}
+	return converted
+}
 
-	// Check for API-level errors returned in the JSON body
-	if groqResp.Error != nil {
-		return "", fmt.Errorf("groq API error: %s (Type: %s, Code: %s). HTTP Status: %s", groqResp.Error.Message, groqResp.Error.Type, groqResp.Error.Code, resp.Status)
+// convertToolChoice converts choice to Groq's tool_choice, which is either
+// a mode string or an object naming the function to call.
+func convertToolChoice(choice llm.ToolChoice) interface{} {
+	switch choice {
+	case "":
+		return string(llm.ToolChoiceAuto)
+	case llm.ToolChoiceAuto, llm.ToolChoiceNone, llm.ToolChoiceRequired:
+		return string(choice)
+	}
+	return groqNamedToolChoice{
+		Type:     "function",
+		Function: groqFunctionName{Name: string(choice)},
 	}
+}
 
-	// Check HTTP status code after checking for JSON error, as JSON error might be more specific
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("groq API request failed with status %s. Body: %s", resp.Status, string(responseBody))
+// newAPIError describes a failed Groq response, including the error object
+// from the body when there is one.
+func newAPIError(resp *http.Response, body string, apiErr *groqAPIError) *llm.Error {
+	e := &llm.Error{
+		Provider:   providerName,
+		Op:         llm.OpGenerate,
+		Endpoint:   groqAPIEndpoint,
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Body:       body,
+	}
+	if apiErr != nil {
+		e.Message, e.Type, e.Code = apiErr.Message, apiErr.Type, apiErr.Code
+	}
+	detail := e.Message
+	if detail == "" {
+		detail = e.Body
 	}
+	e.Kind = classifyAPIError(e.StatusCode, e.Code, detail)
+	return e
+}
 
-	if len(groqResp.Choices) == 0 || groqResp.Choices[0].Message.Content == "" {
-		// This could also indicate a content filter or other issue.
-		log.Printf("Groq response details: ID=%s, Model=%s, FinishReason=%s, Usage=%+v",
-			groqResp.ID, groqResp.Model,
-			func() string {
-				if len(groqResp.Choices) > 0 {
-					return groqResp.Choices[0].FinishReason
-				}
-				return "N/A"
-			}(),
-			groqResp.Usage)
-		return "", fmt.Errorf("groq response contained no choices or empty message content. HTTP Status: %s", resp.Status)
+// opError describes a generate call that failed without an API error
+// response, e.g. because the request could not be sent or decoded.
+func (c *Client) opError(err error) *llm.Error {
+	return llm.NewError(providerName, llm.OpGenerate, groqAPIEndpoint, err)
+}
+
+// classifyAPIError picks the sentinel error for a failed Groq API call from
+// the HTTP status and the error code and message in the response body.
+func classifyAPIError(status int, code, message string) error {
+	switch code {
+	case "model_not_found", "model_decommissioned":
+		return llm.ErrModelNotFound
+	case "context_length_exceeded":
+		return llm.ErrContextTooLong
+	case "rate_limit_exceeded":
+		return llm.ErrRateLimited
+	case "invalid_api_key":
+		return llm.ErrAuthentication
+	}
+	if status == http.StatusBadRequest && llm.IsContextLengthMessage(message) {
+		return llm.ErrContextTooLong
 	}
+	return llm.StatusError(status)
+}
+
+// Init implements xollm.Initializer. The client has no expensive setup to
+// defer, so Init only checks that it was created with NewClient.
+func (c *Client) Init(ctx context.Context) error {
+	if c.httpClient == nil {
+		return fmt.Errorf("groq client not initialized")
+	}
+	return nil
+}
+
+// SetCredentials implements xollm.CredentialSetter, replacing the API key
+// used by requests sent from now on. Requests already in flight finish with
+// the old key.
+//
+// Clients derived from the same client share their API key, so setting it
+// on any of them sets it for all.
+func (c *Client) SetCredentials(apiKey string) error {
+	if apiKey == "" {
+		return fmt.Errorf("groq API key is required")
+	}
+	c = c.root()
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	c.apiKey = apiKey
+	return nil
+}
+
+// authorize sets the API key, the organization and project headers and
+// the User-Agent of req.
+func (c *Client) authorize(req *http.Request) {
+	llm.SetUserAgent(req, c.options.AppIdentifier)
+	req.Header.Set("Authorization", "Bearer "+c.key())
+	if c.options.Organization != "" {
+		req.Header.Set("OpenAI-Organization", c.options.Organization)
+	}
+	if c.options.Project != "" {
+		req.Header.Set("OpenAI-Project", c.options.Project)
+	}
+}
+
+// key returns the current API key.
+func (c *Client) key() string {
+	c = c.root()
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+	return c.apiKey
+}
+
+// RateLimitState implements xollm.RateLimitReporter with the
+// x-ratelimit-* headers of the latest chat completion response, including
+// rejected ones.
+func (c *Client) RateLimitState() (llm.RateLimitState, bool) {
+	return c.root().rateLimits.State()
+}
 
-	return strings.TrimSpace(groqResp.Choices[0].Message.Content), nil
+// ModelName returns the name of the model the client sends requests to.
+func (c *Client) ModelName() string {
+	return c.modelName
 }
 
 // ProviderName returns the name of this provider.
@@ -2,11 +2,19 @@ package groq
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/xostack/xollm/llm"
 )
 
 func TestNewClient_Success(t *testing.T) {
@@ -32,6 +40,27 @@ func TestNewClient_Success(t *testing.T) {
 	}
 }
 
+func TestClient_Init(t *testing.T) {
+	client, _ := NewClient(context.Background(), "test-api-key", "", 30, false)
+	if err := client.Init(context.Background()); err != nil {
+		t.Errorf("Expected constructed client to be ready, got: %v", err)
+	}
+	if err := (&Client{}).Init(context.Background()); err == nil || err.Error() != "groq client not initialized" {
+		t.Errorf("Expected not initialized error, got: %v", err)
+	}
+}
+
+func TestNewClient_SharesTransport(t *testing.T) {
+	a, _ := NewClient(context.Background(), "test-api-key", "", 30, false)
+	b, _ := NewClient(context.Background(), "test-api-key", "", 10, false)
+	if a.httpClient.Transport != llm.SharedTransport() || b.httpClient.Transport != a.httpClient.Transport {
+		t.Error("Expected clients to pool connections through the shared transport")
+	}
+	if a.httpClient.Timeout == b.httpClient.Timeout {
+		t.Error("Expected each client to keep its own timeout")
+	}
+}
+
 func TestNewClient_EmptyAPIKey(t *testing.T) {
 	client, err := NewClient(context.Background(), "", "", 30, false)
 	if err == nil {
@@ -294,3 +323,868 @@ func TestGroqResponseParsing(t *testing.T) {
 		t.Errorf("Expected total tokens 15, got %d", response.Usage.TotalTokens)
 	}
 }
+
+func TestClassifyAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		code     string
+		message  string
+		expected error
+	}{
+		{name: "rate limit status", status: http.StatusTooManyRequests, expected: llm.ErrRateLimited},
+		{name: "rate limit code", status: http.StatusBadRequest, code: "rate_limit_exceeded", expected: llm.ErrRateLimited},
+		{name: "invalid key", status: http.StatusUnauthorized, code: "invalid_api_key", expected: llm.ErrAuthentication},
+		{name: "model not found", status: http.StatusNotFound, code: "model_not_found", expected: llm.ErrModelNotFound},
+		{name: "context code", status: http.StatusBadRequest, code: "context_length_exceeded", expected: llm.ErrContextTooLong},
+		{name: "context message", status: http.StatusBadRequest, message: "Please reduce the length of the messages; maximum context length is 8192 tokens", expected: llm.ErrContextTooLong},
+		{name: "gateway timeout", status: http.StatusGatewayTimeout, expected: llm.ErrTimeout},
+		{name: "server error", status: http.StatusInternalServerError, expected: llm.ErrUnavailable},
+		{name: "bad request", status: http.StatusBadRequest, message: "invalid field", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyAPIError(tt.status, tt.code, tt.message); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("X-Request-Id", "req_abc")
+	body := `{"error": {"message": "Rate limit reached", "type": "tokens", "code": "rate_limit_exceeded"}}`
+
+	err := newAPIError(resp, body, &groqAPIError{Message: "Rate limit reached", Type: "tokens", Code: "rate_limit_exceeded"})
+	if err.Provider != "groq" || err.Op != llm.OpGenerate || err.Endpoint != groqAPIEndpoint || err.StatusCode != 429 || err.RequestID != "req_abc" || err.Code != "rate_limit_exceeded" || err.Type != "tokens" {
+		t.Errorf("Unexpected error details: %+v", err)
+	}
+	if err.Body != body {
+		t.Errorf("Expected raw body to be kept, got '%s'", err.Body)
+	}
+	if !errors.Is(err, llm.ErrRateLimited) {
+		t.Error("Expected error to wrap ErrRateLimited")
+	}
+
+	// Non-JSON gateway errors are classified from the status and body
+	gateway := newAPIError(&http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}, "upstream down", nil)
+	if !errors.Is(gateway, llm.ErrUnavailable) || !strings.Contains(gateway.Error(), "upstream down") {
+		t.Errorf("Unexpected gateway error: %v", gateway)
+	}
+}
+
+// redirectTransport sends every request to a test server instead of the
+// Groq API.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newMockClient returns a client whose requests are served by handler.
+func newMockClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: &redirectTransport{target: target}},
+		apiKey:     "test-api-key",
+		modelName:  "llama-3.3-70b-versatile",
+	}
+}
+
+func TestGroqClient_GenerateWithTools(t *testing.T) {
+	var sent map[string]json.RawMessage
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{
+			"choices": [{
+				"index": 0,
+				"message": {
+					"role": "assistant",
+					"content": null,
+					"tool_calls": [{
+						"id": "call_1",
+						"type": "function",
+						"function": {"name": "get_weather", "arguments": "{\"city\":\"Oslo\"}"}
+					}]
+				},
+				"finish_reason": "tool_calls"
+			}]
+		}`))
+	})
+
+	tools := []llm.Tool{{
+		Name:        "get_weather",
+		Description: "Get the current weather",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+	}}
+	resp, err := client.GenerateWithTools(context.Background(), "Weather in Oslo?", tools, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expectedTools := `[{"type":"function","function":{"name":"get_weather","description":"Get the current weather","parameters":{"type":"object","properties":{"city":{"type":"string"}}}}}]`
+	if string(sent["tools"]) != expectedTools {
+		t.Errorf("Expected tools %s, got %s", expectedTools, sent["tools"])
+	}
+	if string(sent["tool_choice"]) != `"auto"` {
+		t.Errorf("Expected tool_choice auto, got %s", sent["tool_choice"])
+	}
+
+	if resp.FinishReason != "tool_calls" || resp.Text != "" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("Expected one tool call, got %+v", resp)
+	}
+	call := resp.ToolCalls[0]
+	if call.ID != "call_1" || call.Name != "get_weather" || string(call.Arguments) != `{"city":"Oslo"}` {
+		t.Errorf("Unexpected tool call: %+v", call)
+	}
+}
+
+func TestGroqClient_ChatWithTools(t *testing.T) {
+	var sent map[string]json.RawMessage
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "It is 12 degrees in Oslo."}, "finish_reason": "stop"}]}`))
+	})
+
+	call := llm.ToolCall{ID: "call_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Oslo"}`)}
+	first := &llm.ToolResponse{ToolCalls: []llm.ToolCall{call}}
+	resp, err := client.ChatWithTools(context.Background(), []llm.Message{
+		{Role: llm.RoleUser, Content: "Weather in Oslo?"},
+		first.Message(),
+		llm.ToolResultMessage(call, `{"celsius":12}`),
+	}, []llm.Tool{{Name: "get_weather"}}, llm.ToolChoiceAuto)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Text != "It is 12 degrees in Oslo." || len(resp.ToolCalls) != 0 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	expected := `[{"role":"user","content":"Weather in Oslo?"},` +
+		`{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Oslo\"}"}}]},` +
+		`{"role":"tool","content":"{\"celsius\":12}","tool_call_id":"call_1"}]`
+	if string(sent["messages"]) != expected {
+		t.Errorf("Expected messages %s, got %s", expected, sent["messages"])
+	}
+}
+
+func TestConvertToolChoice(t *testing.T) {
+	tests := []struct {
+		choice   llm.ToolChoice
+		expected string
+	}{
+		{"", `"auto"`},
+		{llm.ToolChoiceNone, `"none"`},
+		{llm.ToolChoiceRequired, `"required"`},
+		{"get_weather", `{"type":"function","function":{"name":"get_weather"}}`},
+	}
+	for _, tt := range tests {
+		encoded, _ := json.Marshal(convertToolChoice(tt.choice))
+		if string(encoded) != tt.expected {
+			t.Errorf("convertToolChoice(%q) = %s, expected %s", tt.choice, encoded, tt.expected)
+		}
+	}
+}
+
+func TestGroqClient_Generate_WithoutTools(t *testing.T) {
+	var sent map[string]json.RawMessage
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": " Hi "}, "finish_reason": "stop"}]}`))
+	})
+
+	text, err := client.Generate(context.Background(), "Hello")
+	if err != nil || text != "Hi" {
+		t.Fatalf("Expected \"Hi\", got %q, %v", text, err)
+	}
+	if _, ok := sent["tools"]; ok {
+		t.Error("Expected no tools in a plain request")
+	}
+	if _, ok := sent["tool_choice"]; ok {
+		t.Error("Expected no tool_choice in a plain request")
+	}
+}
+
+func TestGroqClient_Sampling(t *testing.T) {
+	var sent map[string]json.RawMessage
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		sent = nil
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	})
+	client.options = llm.ApplyOptions([]llm.ClientOption{
+		llm.WithSampling(llm.Sampling{Temperature: llm.Float64(0.2), MaxTokens: llm.Int(256)}),
+	})
+
+	if _, err := client.Generate(context.Background(), "Hi"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(sent["temperature"]) != "0.2" || string(sent["max_tokens"]) != "256" {
+		t.Errorf("Expected configured sampling, got temperature=%s max_tokens=%s", sent["temperature"], sent["max_tokens"])
+	}
+	if _, ok := sent["top_p"]; ok {
+		t.Error("Expected unset top_p to be omitted")
+	}
+
+	if _, err := client.GenerateWithSampling(context.Background(), "Hi", llm.Sampling{Temperature: llm.Float64(1.5), TopP: llm.Float64(0.9)}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(sent["temperature"]) != "1.5" || string(sent["top_p"]) != "0.9" || string(sent["max_tokens"]) != "256" {
+		t.Errorf("Expected per-call overrides on top of the defaults, got temperature=%s top_p=%s max_tokens=%s",
+			sent["temperature"], sent["top_p"], sent["max_tokens"])
+	}
+	if _, ok := sent["stop"]; ok {
+		t.Error("Expected unset stop to be omitted")
+	}
+
+	if _, err := client.GenerateWithSampling(context.Background(), "Hi", llm.Sampling{Stop: []string{"END"}}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(sent["stop"]) != `["END"]` {
+		t.Errorf("Expected stop sequences to be sent, got %s", sent["stop"])
+	}
+	if _, ok := sent["seed"]; ok {
+		t.Error("Expected unset seed to be omitted")
+	}
+
+	if _, err := client.GenerateWithSampling(context.Background(), "Hi", llm.Sampling{Seed: llm.Int(42)}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(sent["seed"]) != "42" {
+		t.Errorf("Expected the seed to be sent, got %s", sent["seed"])
+	}
+	if _, ok := sent["presence_penalty"]; ok {
+		t.Error("Expected unset presence_penalty to be omitted")
+	}
+
+	_, err := client.GenerateWithSampling(context.Background(), "Hi", llm.Sampling{PresencePenalty: llm.Float64(0.6), FrequencyPenalty: llm.Float64(-0.5)})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(sent["presence_penalty"]) != "0.6" || string(sent["frequency_penalty"]) != "-0.5" {
+		t.Errorf("Expected the penalties to be sent, got presence_penalty=%s frequency_penalty=%s", sent["presence_penalty"], sent["frequency_penalty"])
+	}
+}
+
+func TestGroqClient_ModelInfo(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openai/v1/models/test-groq-model":
+			w.Write([]byte(`{"id": "test-groq-model", "object": "model", "owned_by": "Meta", "active": true, "context_window": 131072, "max_completion_tokens": 32768}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"message": "The model does not exist", "type": "invalid_request_error", "code": "model_not_found"}}`))
+		}
+	})
+
+	info, err := client.ModelInfo(context.Background(), "test-groq-model")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if info.ContextWindow != 131072 || info.MaxOutputTokens != 32768 || info.Provider != "groq" {
+		t.Errorf("Unexpected model info: %+v", info)
+	}
+	if registered, ok := llm.LookupModel("test-groq-model"); !ok || registered.MaxOutputTokens != 32768 {
+		t.Errorf("Expected the model to be registered, got %+v", registered)
+	}
+
+	_, err = client.ModelInfo(context.Background(), "missing")
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.Op != "model info" || !errors.Is(err, llm.ErrModelNotFound) {
+		t.Errorf("Expected model info *llm.Error matching ErrModelNotFound, got: %v", err)
+	}
+}
+
+func TestGroqClient_Ping(t *testing.T) {
+	var auth string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path != "/openai/v1/models/llama-3.3-70b-versatile" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if auth != "Bearer test-api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "Invalid API Key", "type": "invalid_request_error", "code": "invalid_api_key"}}`))
+			return
+		}
+		w.Write([]byte(`{"id": "llama-3.3-70b-versatile", "object": "model", "active": true}`))
+	})
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if auth != "Bearer test-api-key" {
+		t.Errorf("Expected the ping to be authenticated, got %q", auth)
+	}
+
+	client.SetCredentials("wrong")
+	err := client.Ping(context.Background())
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.Op != "ping" || !errors.Is(err, llm.ErrAuthentication) {
+		t.Errorf("Expected ping *llm.Error matching ErrAuthentication, got: %v", err)
+	}
+
+	if err := (&Client{}).Ping(context.Background()); err == nil {
+		t.Error("Expected error for uninitialized client")
+	}
+}
+
+func TestGroqClient_GenerateWithLogprobs(t *testing.T) {
+	var sent map[string]json.RawMessage
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		sent = nil
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{
+			"model": "llama-3.3-70b-versatile",
+			"choices": [{
+				"index": 0,
+				"message": {"role": "assistant", "content": "Yes"},
+				"logprobs": {"content": [{
+					"token": "Yes",
+					"logprob": -0.1,
+					"top_logprobs": [{"token": "Yes", "logprob": -0.1}, {"token": "No", "logprob": -2.4}]
+				}]},
+				"finish_reason": "stop"
+			}]
+		}`))
+	})
+
+	text, md, err := client.GenerateWithLogprobs(context.Background(), "Is the sky blue?", 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(sent["logprobs"]) != "true" || string(sent["top_logprobs"]) != "2" {
+		t.Errorf("Expected logprobs with 2 alternatives to be requested, got %s and %s", sent["logprobs"], sent["top_logprobs"])
+	}
+	expected := []llm.TokenLogprob{{
+		Token:       "Yes",
+		Logprob:     -0.1,
+		TopLogprobs: []llm.TokenLogprob{{Token: "Yes", Logprob: -0.1}, {Token: "No", Logprob: -2.4}},
+	}}
+	if text != "Yes" || !reflect.DeepEqual(md.Logprobs, expected) {
+		t.Errorf("Unexpected response %q with logprobs %+v", text, md.Logprobs)
+	}
+
+	if _, err := client.Generate(context.Background(), "Is the sky blue?"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent["logprobs"] != nil || sent["top_logprobs"] != nil {
+		t.Errorf("Expected no logprobs unless requested, got %s", sent["logprobs"])
+	}
+
+	if _, _, err := client.GenerateWithLogprobs(context.Background(), "Is the sky blue?", 21); err == nil {
+		t.Error("Expected an error for too many alternatives")
+	}
+}
+
+func TestGroqClient_GenerateWithMetadata(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"model": "llama-3.3-70b-versatile",
+			"choices": [{"message": {"role": "assistant", "content": "Hi"}, "finish_reason": "stop"}],
+			"usage": {"queue_time": 0.25, "prompt_tokens": 10, "prompt_time": 0.002, "completion_tokens": 3, "completion_time": 0.015, "total_tokens": 13, "total_time": 0.017},
+			"x_groq": {"id": "req_01abc"}
+		}`))
+	})
+
+	text, meta, err := client.GenerateWithMetadata(context.Background(), "Hello")
+	if err != nil || text != "Hi" {
+		t.Fatalf("Expected \"Hi\", got %q, %v", text, err)
+	}
+	if meta.Model != "llama-3.3-70b-versatile" || meta.RequestID != "req_01abc" || meta.FinishReason != "stop" {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+	if meta.Usage.PromptTokens != 10 || meta.Usage.CompletionTokens != 3 {
+		t.Errorf("Unexpected usage: %+v", meta.Usage)
+	}
+	if cost, _ := llm.EstimateCost("groq", meta.Model, meta.Usage); cost == 0 || meta.EstimatedCostUSD != cost {
+		t.Errorf("Expected an estimated cost of %v, got %v", cost, meta.EstimatedCostUSD)
+	}
+	expected := llm.Timing{
+		Queue:      250 * time.Millisecond,
+		Prompt:     2 * time.Millisecond,
+		Completion: 15 * time.Millisecond,
+		Total:      17 * time.Millisecond,
+	}
+	if meta.Timing == nil || *meta.Timing != expected {
+		t.Errorf("Expected timing %+v, got %+v", expected, meta.Timing)
+	}
+}
+
+func TestGroqClient_RawResponse(t *testing.T) {
+	body := `{"id": "chatcmpl-1", "system_fingerprint": "fp_123", "choices": [{"message": {"role": "assistant", "content": "Hi"}}]}`
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+
+	ctx, raw := llm.WithRawResponse(context.Background())
+	if _, err := client.Generate(ctx, "Hello"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var extra struct {
+		SystemFingerprint string `json:"system_fingerprint"`
+	}
+	if err := json.Unmarshal(raw.Body(), &extra); err != nil || extra.SystemFingerprint != "fp_123" {
+		t.Errorf("Expected the raw body with unmodeled fields, got %q, %v", raw.Body(), err)
+	}
+	if raw.StatusCode() != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", raw.StatusCode())
+	}
+}
+
+func TestGroqResponseMetadata_NoTiming(t *testing.T) {
+	resp := &groqChatCompletionResponse{requestID: "from-header", XGroq: &groqExtensions{ID: "req_1"}}
+	meta := resp.metadata()
+	if meta.Timing != nil {
+		t.Errorf("Expected no timing when Groq reports none, got %+v", meta.Timing)
+	}
+	if meta.RequestID != "from-header" {
+		t.Errorf("Expected the header request ID to take precedence, got %q", meta.RequestID)
+	}
+}
+
+func TestGroqClient_SetCredentials(t *testing.T) {
+	var authHeaders []string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	})
+
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if err := client.SetCredentials("rotated-key"); err != nil {
+		t.Fatalf("SetCredentials failed: %v", err)
+	}
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	want := []string{"Bearer test-api-key", "Bearer rotated-key"}
+	if len(authHeaders) != 2 || authHeaders[0] != want[0] || authHeaders[1] != want[1] {
+		t.Errorf("Expected Authorization headers %v, got %v", want, authHeaders)
+	}
+	if err := client.SetCredentials(""); err == nil {
+		t.Error("Expected an error for an empty key")
+	}
+}
+
+func TestGroqClient_RateLimitState(t *testing.T) {
+	limited := false
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-limit-requests", "14400")
+		w.Header().Set("x-ratelimit-remaining-requests", "14370")
+		w.Header().Set("x-ratelimit-limit-tokens", "18000")
+		w.Header().Set("x-ratelimit-remaining-tokens", "17997")
+		w.Header().Set("x-ratelimit-reset-requests", "2m59.56s")
+		w.Header().Set("x-ratelimit-reset-tokens", "7.66s")
+		if limited {
+			w.Header().Set("retry-after", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"message": "Rate limit reached", "type": "tokens", "code": "rate_limit_exceeded"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	})
+
+	if _, ok := client.RateLimitState(); ok {
+		t.Error("Expected no state before the first response")
+	}
+	before := time.Now()
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	state, ok := client.RateLimitState()
+	if !ok || state.LimitRequests != 14400 || state.RemainingRequests != 14370 || state.LimitTokens != 18000 || state.RemainingTokens != 17997 {
+		t.Fatalf("Unexpected rate limit state: %+v, %v", state, ok)
+	}
+	if d := state.ResetTokens.Sub(before); d < 7*time.Second || d > 8*time.Second {
+		t.Errorf("Expected tokens to reset in about 7.66s, got %v", d)
+	}
+
+	limited = true
+	if _, err := client.Generate(context.Background(), "hi"); !errors.Is(err, llm.ErrRateLimited) {
+		t.Fatalf("Expected a rate limit error, got %v", err)
+	}
+	if state, _ := client.RateLimitState(); state.RetryAfter != 2*time.Second {
+		t.Errorf("Expected the state of the rejected request to be recorded, got %+v", state)
+	}
+}
+
+func TestGroqClient_DryRun(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request to be sent in dry-run mode")
+	})
+
+	_, err := client.Generate(llm.WithDryRun(context.Background()), "Hello")
+	var dryRun *llm.DryRunError
+	if !errors.As(err, &dryRun) {
+		t.Fatalf("Expected a *llm.DryRunError, got %v", err)
+	}
+	if dryRun.Request.URL != groqAPIEndpoint || dryRun.Request.Header.Get("Authorization") != "REDACTED" {
+		t.Errorf("Unexpected rendered request: %+v", dryRun.Request)
+	}
+	if !strings.Contains(string(dryRun.Request.Body), `"content":"Hello"`) {
+		t.Errorf("Expected the prompt in the body, got %s", dryRun.Request.Body)
+	}
+}
+
+func TestGroqClient_WithModel(t *testing.T) {
+	var models, authHeaders []string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req groqChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Header().Set("x-ratelimit-remaining-requests", "99")
+		w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	})
+
+	derived := client.WithModel("llama-3.1-8b-instant")
+	if derived.httpClient != client.httpClient {
+		t.Error("Expected the derived client to share the HTTP client")
+	}
+	if _, err := derived.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if state, ok := client.RateLimitState(); !ok || state.RemainingRequests != 99 {
+		t.Errorf("Expected the derived client's rate limits to be shared, got %+v, %v", state, ok)
+	}
+
+	// Rotating the key of either client rotates it for both
+	if err := derived.SetCredentials("rotated-key"); err != nil {
+		t.Fatalf("SetCredentials failed: %v", err)
+	}
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(models) != 2 || models[0] != "llama-3.1-8b-instant" || models[1] != "llama-3.3-70b-versatile" {
+		t.Errorf("Expected each client to use its own model, got %v", models)
+	}
+	if authHeaders[1] != "Bearer rotated-key" {
+		t.Errorf("Expected the rotated key, got %v", authHeaders)
+	}
+}
+
+func TestGroqClient_WithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	defer close(release)
+
+	derived := client.WithTimeout(50 * time.Millisecond).WithModel("llama-3.1-8b-instant")
+	if derived.httpClient.Timeout != 50*time.Millisecond || client.httpClient.Timeout != 10*time.Second {
+		t.Errorf("Expected only the derived client's timeout to change, got %v and %v", derived.httpClient.Timeout, client.httpClient.Timeout)
+	}
+	if derived.parent != client {
+		t.Error("Expected clients derived from derived clients to share the original's state")
+	}
+	if _, err := derived.Generate(context.Background(), "hi"); !errors.Is(err, llm.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got: %v", err)
+	}
+}
+
+func TestGroqClient_WithRequestTimeout(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	})
+
+	ctx := llm.WithRequestTimeout(context.Background(), 20*time.Millisecond)
+	if _, err := client.Generate(ctx, "hi"); !errors.Is(err, llm.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got: %v", err)
+	}
+	if client.httpClient.Timeout != 10*time.Second {
+		t.Errorf("Expected the client's timeout to be unchanged, got %v", client.httpClient.Timeout)
+	}
+
+	short := client.WithTimeout(20 * time.Millisecond)
+	ctx = llm.WithRequestTimeout(context.Background(), 5*time.Second)
+	if _, err := short.Generate(ctx, "hi"); err != nil {
+		t.Errorf("Expected the request timeout to extend the client's, got: %v", err)
+	}
+}
+
+func TestGroqClient_ConcurrentUse(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-remaining-requests", "99")
+		w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	})
+	derived := client.WithModel("llama-3.1-8b-instant")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.Background()
+			if _, err := client.Generate(ctx, "hi"); err != nil {
+				errs <- err
+			}
+			if _, _, err := derived.GenerateWithMetadata(ctx, "hi"); err != nil {
+				errs <- err
+			}
+			if i%5 == 0 {
+				if err := client.SetCredentials(fmt.Sprint("key-", i)); err != nil {
+					errs <- err
+				}
+			}
+			derived.RateLimitState()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent call failed: %v", err)
+	}
+}
+
+func TestGroqClient_Organization(t *testing.T) {
+	var headers []http.Header
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+		if strings.Contains(r.URL.Path, "/models/") {
+			w.Write([]byte(`{"id": "llama-3.3-70b-versatile", "context_window": 131072}`))
+			return
+		}
+		w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`))
+	})
+	client.options = llm.ApplyOptions([]llm.ClientOption{llm.WithOrganization("org-123", "proj_456")})
+
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if _, err := client.ModelInfo(context.Background(), ""); err != nil {
+		t.Fatalf("ModelInfo failed: %v", err)
+	}
+	for _, h := range headers {
+		if h.Get("OpenAI-Organization") != "org-123" || h.Get("OpenAI-Project") != "proj_456" || !strings.HasPrefix(h.Get("User-Agent"), "xollm/") {
+			t.Errorf("Expected the organization, project and User-Agent headers, got %v", h)
+		}
+	}
+
+	client.options = llm.ClientOptions{}
+	headers = nil
+	client.Generate(context.Background(), "hi")
+	if _, ok := headers[0]["Openai-Organization"]; ok {
+		t.Error("Expected no organization header when none is set")
+	}
+}
+
+func TestNewClient_WithTransport(t *testing.T) {
+	custom := &http.Transport{}
+	client, err := NewClient(context.Background(), "test-api-key", "", 30, false, llm.WithTransport(custom))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if client.httpClient.Transport != custom {
+		t.Error("Expected the client to use the custom transport")
+	}
+}
+
+func TestGroqClient_GenerateStream(t *testing.T) {
+	var sent groqChatCompletionRequest
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Expected an event stream to be requested, got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(`data: {"id":"c1","choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"content":"Hel"}}]}
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}],"x_groq":{"usage":{"prompt_tokens":7,"completion_tokens":2}}}
+
+data: [DONE]
+
+`))
+	})
+
+	chunks, err := client.GenerateStream(context.Background(), "Hi")
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	var texts []string
+	var last llm.StreamChunk
+	for chunk := range chunks {
+		if chunk.Text != "" {
+			texts = append(texts, chunk.Text)
+		}
+		last = chunk
+	}
+	if !sent.Stream || sent.StreamOptions == nil || !sent.StreamOptions.IncludeUsage {
+		t.Errorf("Expected a streaming request with usage, got %+v", sent)
+	}
+	if strings.Join(texts, "|") != "Hel|lo" {
+		t.Errorf("Expected two text chunks, got %q", texts)
+	}
+	if !last.Done || last.Usage == nil || last.Usage.PromptTokens != 7 || last.Usage.CompletionTokens != 2 {
+		t.Errorf("Unexpected final chunk: %+v", last)
+	}
+	if last.FinishReason != "stop" {
+		t.Errorf("Expected finish reason \"stop\" on the final chunk, got %q", last.FinishReason)
+	}
+}
+
+func TestGroqClient_GenerateStream_Errors(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"tokens","code":"rate_limit_exceeded"}}`))
+	})
+	if _, err := client.GenerateStream(context.Background(), "Hi"); !errors.Is(err, llm.ErrRateLimited) {
+		t.Errorf("Expected a rate limit error, got: %v", err)
+	}
+
+	client = newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n"))
+	})
+	chunks, err := client.GenerateStream(context.Background(), "Hi")
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	var last llm.StreamChunk
+	for chunk := range chunks {
+		last = chunk
+	}
+	if last.Err == nil || !strings.Contains(last.Err.Error(), "stream ended") {
+		t.Errorf("Expected an error for a truncated stream, got %+v", last)
+	}
+}
+
+func TestGroqClient_Chat(t *testing.T) {
+	var sent groqChatCompletionRequest
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{
+			"model": "llama-3.3-70b-versatile",
+			"choices": [{"message": {"role": "assistant", "content": "Stockholm."}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 20, "completion_tokens": 3, "total_tokens": 23}
+		}`))
+	})
+
+	text, md, err := client.Chat(context.Background(), []llm.Message{
+		{Role: llm.RoleSystem, Content: "Be terse."},
+		{Role: llm.RoleUser, Content: "Capital of Norway?"},
+		{Role: llm.RoleAssistant, Content: "Oslo."},
+		{Role: llm.RoleUser, Content: "And of Sweden?"},
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if text != "Stockholm." || md.FinishReason != "stop" || md.Usage.PromptTokens != 20 {
+		t.Errorf("Unexpected reply %q, %+v", text, md)
+	}
+	expected := []groqChatMessage{
+		{Role: "system", Content: "Be terse."},
+		{Role: "user", Content: "Capital of Norway?"},
+		{Role: "assistant", Content: "Oslo."},
+		{Role: "user", Content: "And of Sweden?"},
+	}
+	if !reflect.DeepEqual(sent.Messages, expected) {
+		t.Errorf("Expected messages %+v, got %+v", expected, sent.Messages)
+	}
+
+	if _, _, err := client.Chat(context.Background(), []llm.Message{{Role: "bot"}}); err == nil {
+		t.Error("Expected an error for an unknown role")
+	}
+}
+
+func TestGroqClient_GenerateJSON(t *testing.T) {
+	var sent groqChatCompletionRequest
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": " {\"name\": \"Ada\"} "}, "finish_reason": "stop"}]}`))
+	})
+
+	schema := json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"}}}`)
+	text, err := client.GenerateJSON(context.Background(), "Who wrote the first program?", schema)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != `{"name": "Ada"}` {
+		t.Errorf("Expected the trimmed JSON, got %q", text)
+	}
+	if sent.ResponseFormat == nil || sent.ResponseFormat.Type != "json_object" {
+		t.Errorf("Expected JSON mode, got %+v", sent.ResponseFormat)
+	}
+	if len(sent.Messages) != 2 || sent.Messages[0].Role != "system" || !strings.Contains(sent.Messages[0].Content, string(schema)) {
+		t.Errorf("Expected the schema in a system message, got %+v", sent.Messages)
+	}
+}
+
+func TestGroqClient_GenerateWithImages(t *testing.T) {
+	var sent struct {
+		Messages []struct {
+			Role    string            `json:"role"`
+			Content []groqContentPart `json:"content"`
+		} `json:"messages"`
+	}
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "A cat."}, "finish_reason": "stop"}]}`))
+	})
+
+	text, err := client.GenerateWithImages(context.Background(), "What is this?", []llm.Image{
+		{MIMEType: "image/png", Data: []byte("png")},
+		llm.ImageFromURL("https://example.com/cat.jpg"),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != "A cat." {
+		t.Errorf("Unexpected text %q", text)
+	}
+	if len(sent.Messages) != 1 || len(sent.Messages[0].Content) != 3 {
+		t.Fatalf("Expected one message with text and two images, got %+v", sent.Messages)
+	}
+	parts := sent.Messages[0].Content
+	if parts[0].Type != "text" || parts[0].Text != "What is this?" {
+		t.Errorf("Expected the prompt first, got %+v", parts[0])
+	}
+	if parts[1].ImageURL == nil || parts[1].ImageURL.URL != "data:image/png;base64,cG5n" {
+		t.Errorf("Expected image data as a data URL, got %+v", parts[1])
+	}
+	if parts[2].ImageURL == nil || parts[2].ImageURL.URL != "https://example.com/cat.jpg" {
+		t.Errorf("Expected the image URL passed through, got %+v", parts[2])
+	}
+}
+
+func TestGroqClient_SystemPrompt(t *testing.T) {
+	var sent groqChatCompletionRequest
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		sent = groqChatCompletionRequest{}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Bonjour"}, "finish_reason": "stop"}]}`))
+	})
+	client.options.SystemPrompt = "Answer in French."
+
+	if _, err := client.Generate(context.Background(), "Hello"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := []groqChatMessage{{Role: "system", Content: "Answer in French."}, {Role: "user", Content: "Hello"}}
+	if !reflect.DeepEqual(sent.Messages, expected) {
+		t.Errorf("Expected the system prompt as the first message, got %+v", sent.Messages)
+	}
+
+	if _, _, err := client.Chat(context.Background(), []llm.Message{{Role: llm.RoleSystem, Content: "Be brief."}, {Role: llm.RoleUser, Content: "Hello"}}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(sent.Messages) != 3 || sent.Messages[0].Content != "Answer in French." || sent.Messages[1].Content != "Be brief." {
+		t.Errorf("Expected the system prompt before the chat's own system messages, got %+v", sent.Messages)
+	}
+}
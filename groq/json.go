@@ -0,0 +1,45 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// groqResponseFormat is the response_format of a chat completion.
+type groqResponseFormat struct {
+	Type string `json:"type"` // "text" or "json_object"
+}
+
+// GenerateJSON implements xollm.JSONGenerator with JSON mode, which makes
+// Groq return a valid JSON object. JSON mode doesn't take a schema, so the
+// schema is sent in a system message for the model to follow.
+func (c *Client) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	if c.httpClient == nil {
+		return "", fmt.Errorf("groq client not initialized")
+	}
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: llm.JSONInstruction(schema)},
+		{Role: llm.RoleUser, Content: prompt},
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, llm.FlattenMessages(messages)); err != nil {
+			return "", err
+		}
+	}
+
+	payload := groqChatCompletionRequest{
+		Messages:       c.withSystemPrompt(convertMessages(messages)),
+		Model:          c.modelName,
+		ResponseFormat: &groqResponseFormat{Type: "json_object"},
+	}
+	payload.setSampling(c.options.Sampling)
+	resp, err := c.complete(ctx, payload)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
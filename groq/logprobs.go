@@ -0,0 +1,71 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// maxTopLogprobs is the most alternatives Groq reports per token.
+const maxTopLogprobs = 20
+
+// groqLogprobs holds the log probabilities of a choice's tokens.
+type groqLogprobs struct {
+	Content []groqTokenLogprob `json:"content"`
+}
+
+// groqTokenLogprob is a token and its log probability.
+type groqTokenLogprob struct {
+	Token       string             `json:"token"`
+	Logprob     float64            `json:"logprob"`
+	TopLogprobs []groqTokenLogprob `json:"top_logprobs,omitempty"`
+}
+
+// convert returns the tokens' log probabilities, nil if l is nil.
+func (l *groqLogprobs) convert() []llm.TokenLogprob {
+	if l == nil {
+		return nil
+	}
+	tokens := make([]llm.TokenLogprob, len(l.Content))
+	for i, t := range l.Content {
+		tokens[i] = llm.TokenLogprob{Token: t.Token, Logprob: t.Logprob}
+		for _, top := range t.TopLogprobs {
+			tokens[i].TopLogprobs = append(tokens[i].TopLogprobs, llm.TokenLogprob{Token: top.Token, Logprob: top.Logprob})
+		}
+	}
+	return tokens
+}
+
+// GenerateWithLogprobs implements xollm.LogprobGenerator with the logprobs
+// and top_logprobs parameters of the chat completions API. topLogprobs can
+// be at most 20.
+func (c *Client) GenerateWithLogprobs(ctx context.Context, prompt string, topLogprobs int) (string, *llm.ResponseMetadata, error) {
+	if c.httpClient == nil {
+		return "", nil, fmt.Errorf("groq client not initialized")
+	}
+	if topLogprobs < 0 || topLogprobs > maxTopLogprobs {
+		return "", nil, fmt.Errorf("top logprobs must be between 0 and %d, got %d", maxTopLogprobs, topLogprobs)
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prompt); err != nil {
+			return "", nil, err
+		}
+	}
+
+	payload := groqChatCompletionRequest{
+		Messages: c.withSystemPrompt([]groqChatMessage{{Role: "user", Content: prompt}}),
+		Model:    c.modelName,
+		Logprobs: true,
+	}
+	if topLogprobs > 0 {
+		payload.TopLogprobs = &topLogprobs
+	}
+	payload.setSampling(c.options.Sampling)
+	resp, err := c.complete(ctx, payload)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), resp.metadata(), nil
+}
@@ -0,0 +1,95 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/xostack/xollm/llm"
+)
+
+const (
+	groqModelsEndpoint = "https://api.groq.com/openai/v1/models"
+	opModelInfo        = "model info"
+	opPing             = "ping"
+)
+
+// groqModel is a model as described by Groq's models endpoint.
+type groqModel struct {
+	ID                  string        `json:"id"`
+	OwnedBy             string        `json:"owned_by"`
+	Active              bool          `json:"active"`
+	ContextWindow       int           `json:"context_window"`
+	MaxCompletionTokens int           `json:"max_completion_tokens"`
+	Error               *groqAPIError `json:"error,omitempty"`
+}
+
+// ModelInfo implements xollm.ModelInfoProvider using Groq's models
+// endpoint, which reports the context window and output limit. Modality
+// flags come from the bundled registry.
+func (c *Client) ModelInfo(ctx context.Context, model string) (llm.ModelInfo, error) {
+	if model == "" {
+		model = c.modelName
+	}
+	described, err := c.describeModel(ctx, opModelInfo, model)
+	if err != nil {
+		return llm.ModelInfo{}, err
+	}
+
+	info, _ := llm.LookupModel(model)
+	info.Name, info.Provider = model, providerName
+	if described.ContextWindow > 0 {
+		info.ContextWindow = described.ContextWindow
+	}
+	if described.MaxCompletionTokens > 0 {
+		info.MaxOutputTokens = described.MaxCompletionTokens
+	}
+	llm.RegisterModel(info)
+	return info, nil
+}
+
+// Ping implements xollm.Pinger by describing the client's model, which
+// costs no tokens but fails with ErrAuthentication for a bad API key and
+// ErrModelNotFound for a model Groq doesn't serve.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.describeModel(ctx, opPing, c.modelName)
+	return err
+}
+
+// describeModel asks the models endpoint about model. Failures are
+// returned as *llm.Error describing op.
+func (c *Client) describeModel(ctx context.Context, op, model string) (*groqModel, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("groq client not initialized")
+	}
+	endpoint := groqModelsEndpoint + "/" + url.PathEscape(model)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, llm.NewError(providerName, op, endpoint, fmt.Errorf("failed to create request: %w", err))
+	}
+	c.authorize(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.requestClient(ctx).Do(req)
+	if err != nil {
+		return nil, llm.NewError(providerName, op, endpoint, fmt.Errorf("failed to send request: %w", err))
+	}
+	llm.CaptureRawResponse(resp)
+	defer resp.Body.Close()
+
+	var described groqModel
+	body, err := llm.DecodeJSON(resp.Body, &described)
+	if err != nil || described.Error != nil || resp.StatusCode != http.StatusOK {
+		if err != nil && resp.StatusCode == http.StatusOK {
+			decodeErr := llm.NewError(providerName, op, endpoint, fmt.Errorf("failed to decode response: %w", err))
+			decodeErr.StatusCode, decodeErr.Body = resp.StatusCode, body
+			return nil, decodeErr
+		}
+		apiErr := newAPIError(resp, body, described.Error)
+		apiErr.Op, apiErr.Endpoint = op, endpoint
+		return nil, apiErr
+	}
+	return &described, nil
+}
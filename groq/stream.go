@@ -0,0 +1,152 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// groqStreamOptions configures a streamed chat completion.
+type groqStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// groqStreamChunk is one server-sent event of a streamed chat completion.
+type groqStreamChunk struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		// FinishReason is set on the choice's last chunk
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	// Usage is set on the last chunk when include_usage is requested
+	Usage *groqUsage `json:"usage,omitempty"`
+	// XGroq carries Groq's own usage report on the last chunk
+	XGroq *struct {
+		Usage *groqUsage `json:"usage,omitempty"`
+	} `json:"x_groq,omitempty"`
+	Error *groqAPIError `json:"error,omitempty"`
+}
+
+// usage returns the usage reported in the chunk, if any.
+func (c *groqStreamChunk) usage() *groqUsage {
+	if c.Usage != nil {
+		return c.Usage
+	}
+	if c.XGroq != nil {
+		return c.XGroq.Usage
+	}
+	return nil
+}
+
+// GenerateStream implements xollm.Streamer. Groq streams the completion as
+// server-sent events, each carrying the next piece of text, and reports the
+// token usage in the last one, which is delivered as the Usage of the Done
+// chunk. Unlike Generate, the text is not trimmed. The client's request
+// timeout covers the whole stream.
+func (c *Client) GenerateStream(ctx context.Context, prompt string) (<-chan llm.StreamChunk, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("groq client not initialized")
+	}
+	if c.options.PreflightTokenCheck {
+		if err := llm.PreflightCheck(c.modelName, prompt); err != nil {
+			return nil, err
+		}
+	}
+
+	payload := groqChatCompletionRequest{
+		Messages:      c.withSystemPrompt([]groqChatMessage{{Role: "user", Content: prompt}}),
+		Model:         c.modelName,
+		Stream:        true,
+		StreamOptions: &groqStreamOptions{IncludeUsage: true},
+	}
+	payload.setSampling(c.options.Sampling)
+
+	resp, err := c.post(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var errResp groqChatCompletionResponse
+		body, _ := llm.DecodeJSON(resp.Body, &errResp)
+		return nil, llm.WrapContextLength(c.modelName, newAPIError(resp, body, errResp.Error))
+	}
+
+	chunks := make(chan llm.StreamChunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+		c.readStream(ctx, resp, chunks)
+	}()
+	return chunks, nil
+}
+
+// readStream decodes the events in resp and sends them as chunks until
+// the [DONE] event, an error or the end of the body. It stops early if ctx
+// is cancelled while the consumer is not reading.
+func (c *Client) readStream(ctx context.Context, resp *http.Response, chunks chan<- llm.StreamChunk) {
+	emit := func(chunk llm.StreamChunk) bool {
+		select {
+		case chunks <- chunk:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	fail := func(err error) {
+		emit(llm.StreamChunk{Err: llm.WrapContextLength(c.modelName, err)})
+	}
+
+	var usage *llm.Usage
+	var finishReason string
+	dec := llm.NewSSEDecoder(resp.Body)
+	for {
+		event, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			fail(c.opError(fmt.Errorf("stream ended before the response was done")))
+			return
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				fail(c.opError(fmt.Errorf("stream interrupted: %w", ctx.Err())))
+				return
+			}
+			fail(c.opError(fmt.Errorf("failed to read stream: %w", err)))
+			return
+		}
+		if event.Data == "[DONE]" {
+			emit(llm.StreamChunk{Done: true, Usage: usage, FinishReason: finishReason})
+			return
+		}
+
+		var part groqStreamChunk
+		if err := json.Unmarshal([]byte(event.Data), &part); err != nil {
+			fail(c.opError(fmt.Errorf("failed to decode stream: %w", err)))
+			return
+		}
+		if part.Error != nil {
+			fail(newAPIError(resp, llm.CapBody([]byte(event.Data)), part.Error))
+			return
+		}
+		if u := part.usage(); u != nil {
+			usage = &llm.Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens}
+		}
+		for _, choice := range part.Choices {
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+			if choice.Delta.Content != "" && !emit(llm.StreamChunk{Text: choice.Delta.Content}) {
+				return
+			}
+		}
+	}
+}
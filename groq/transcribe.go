@@ -0,0 +1,139 @@
+package groq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xostack/xollm/llm"
+)
+
+const (
+	groqTranscriptionsEndpoint = "https://api.groq.com/openai/v1/audio/transcriptions"
+	defaultTranscriptionModel  = "whisper-large-v3-turbo"
+	opTranscribe               = "transcribe"
+)
+
+// groqTranscription is a verbose_json response of the transcriptions
+// endpoint; durations are in seconds.
+type groqTranscription struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language"`
+	Duration float64 `json:"duration"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+	Error *groqAPIError `json:"error,omitempty"`
+}
+
+// Transcribe implements xollm.Transcriber with Groq's Whisper models. The
+// model is the one set with llm.WithTranscriptionModel, or
+// whisper-large-v3-turbo; whisper-large-v3 is slower but more accurate.
+// The response includes the detected language, the recording's duration
+// and timed segments; Whisper's leading spaces are trimmed from the text.
+func (c *Client) Transcribe(ctx context.Context, audio llm.Audio, opts llm.TranscriptionOptions) (*llm.Transcription, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("groq client not initialized")
+	}
+	if len(audio.Data) == 0 {
+		return nil, fmt.Errorf("audio to transcribe is empty")
+	}
+	model := c.options.TranscriptionModel
+	if model == "" {
+		model = defaultTranscriptionModel
+	}
+	filename := audio.Filename
+	if filename == "" {
+		filename = "audio"
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := [][2]string{
+		{"model", model},
+		{"response_format", "verbose_json"},
+		{"language", opts.Language},
+		{"prompt", opts.Prompt},
+	}
+	if opts.Temperature != nil {
+		fields = append(fields, [2]string{"temperature", strconv.FormatFloat(*opts.Temperature, 'f', -1, 64)})
+	}
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return nil, c.transcribeError(fmt.Errorf("failed to build request: %w", err))
+		}
+	}
+	part, err := form.CreateFormFile("file", filename)
+	if err == nil {
+		_, err = part.Write(audio.Data)
+	}
+	if err == nil {
+		err = form.Close()
+	}
+	if err != nil {
+		return nil, c.transcribeError(fmt.Errorf("failed to build request: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, groqTranscriptionsEndpoint, &body)
+	if err != nil {
+		return nil, c.transcribeError(fmt.Errorf("failed to create request: %w", err))
+	}
+	c.authorize(req)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	if err := llm.InterceptDryRun(providerName, req); err != nil {
+		return nil, err
+	}
+
+	llm.Logger(ctx, c.logger).Debug("sending Groq transcription request", "model", model, "bytes", len(audio.Data))
+	resp, err := c.requestClient(ctx).Do(req)
+	if err != nil {
+		return nil, c.transcribeError(fmt.Errorf("failed to send request: %w", err))
+	}
+	defer resp.Body.Close()
+	c.root().rateLimits.Update(resp.Header)
+	llm.CaptureRawResponse(resp)
+
+	var transcription groqTranscription
+	respBody, err := llm.DecodeJSON(resp.Body, &transcription)
+	if err != nil || transcription.Error != nil || resp.StatusCode != http.StatusOK {
+		if err != nil && resp.StatusCode == http.StatusOK {
+			decodeErr := c.transcribeError(fmt.Errorf("failed to decode response: %w", err))
+			decodeErr.StatusCode, decodeErr.Body = resp.StatusCode, respBody
+			return nil, decodeErr
+		}
+		apiErr := newAPIError(resp, respBody, transcription.Error)
+		apiErr.Op, apiErr.Endpoint = opTranscribe, groqTranscriptionsEndpoint
+		return nil, apiErr
+	}
+
+	result := &llm.Transcription{
+		Text:     strings.TrimSpace(transcription.Text),
+		Language: transcription.Language,
+		Duration: llm.Seconds(transcription.Duration),
+		Model:    model,
+	}
+	for _, s := range transcription.Segments {
+		result.Segments = append(result.Segments, llm.TranscriptionSegment{
+			Start: llm.Seconds(s.Start),
+			End:   llm.Seconds(s.End),
+			Text:  strings.TrimSpace(s.Text),
+		})
+	}
+	return result, nil
+}
+
+// transcribeError describes a transcription that failed without an API
+// error response.
+func (c *Client) transcribeError(err error) *llm.Error {
+	return llm.NewError(providerName, opTranscribe, groqTranscriptionsEndpoint, err)
+}
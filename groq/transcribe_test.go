@@ -0,0 +1,128 @@
+package groq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestGroqClient_Transcribe(t *testing.T) {
+	var fields map[string]string
+	var file []byte
+	var filename string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/v1/audio/transcriptions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-api-key" {
+			t.Errorf("Unexpected authorization header %q", got)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		fields = map[string]string{}
+		for name, values := range r.MultipartForm.Value {
+			fields[name] = values[0]
+		}
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("Expected a file part: %v", err)
+		}
+		defer f.Close()
+		file, _ = io.ReadAll(f)
+		filename = header.Filename
+
+		w.Write([]byte(`{
+			"task": "transcribe",
+			"language": "English",
+			"duration": 4.5,
+			"text": " Hello there. General Kenobi.",
+			"segments": [
+				{"id": 0, "start": 0, "end": 1.5, "text": " Hello there."},
+				{"id": 1, "start": 1.5, "end": 4.5, "text": " General Kenobi."}
+			]
+		}`))
+	})
+
+	temperature := 0.2
+	got, err := client.Transcribe(context.Background(), llm.Audio{Filename: "clip.m4a", Data: []byte("audio bytes")},
+		llm.TranscriptionOptions{Language: "en", Prompt: "Star Wars", Temperature: &temperature})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	want := map[string]string{
+		"model":           defaultTranscriptionModel,
+		"response_format": "verbose_json",
+		"language":        "en",
+		"prompt":          "Star Wars",
+		"temperature":     "0.2",
+	}
+	for name, value := range want {
+		if fields[name] != value {
+			t.Errorf("Expected form field %s=%q, got %q", name, value, fields[name])
+		}
+	}
+	if string(file) != "audio bytes" || filename != "clip.m4a" {
+		t.Errorf("Unexpected file part %q named %q", file, filename)
+	}
+
+	if got.Text != "Hello there. General Kenobi." {
+		t.Errorf("Unexpected text %q", got.Text)
+	}
+	if got.Language != "English" || got.Duration != 4500*time.Millisecond || got.Model != defaultTranscriptionModel {
+		t.Errorf("Unexpected transcription details: %+v", got)
+	}
+	if len(got.Segments) != 2 || got.Segments[1].Start != 1500*time.Millisecond || got.Segments[1].Text != "General Kenobi." {
+		t.Errorf("Unexpected segments: %+v", got.Segments)
+	}
+}
+
+func TestGroqClient_TranscribeModelAndOptionalFields(t *testing.T) {
+	var fields map[string][]string
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		fields = r.MultipartForm.Value
+		w.Write([]byte(`{"text": "ok"}`))
+	})
+	client.options.TranscriptionModel = "whisper-large-v3"
+
+	if _, err := client.Transcribe(context.Background(), llm.Audio{Data: []byte("audio")}, llm.TranscriptionOptions{}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if fields["model"][0] != "whisper-large-v3" {
+		t.Errorf("Expected the configured model, got %v", fields["model"])
+	}
+	for _, name := range []string{"language", "prompt", "temperature"} {
+		if _, ok := fields[name]; ok {
+			t.Errorf("Expected unset field %s to be omitted", name)
+		}
+	}
+}
+
+func TestGroqClient_TranscribeErrors(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"message": "The model does not exist", "type": "invalid_request_error", "code": "model_not_found"}}`))
+	})
+
+	if _, err := client.Transcribe(context.Background(), llm.Audio{}, llm.TranscriptionOptions{}); err == nil {
+		t.Error("Expected an error for empty audio")
+	}
+
+	_, err := client.Transcribe(context.Background(), llm.Audio{Data: []byte("audio")}, llm.TranscriptionOptions{})
+	if !errors.Is(err, llm.ErrModelNotFound) {
+		t.Fatalf("Expected ErrModelNotFound, got: %v", err)
+	}
+	var llmErr *llm.Error
+	if !errors.As(err, &llmErr) || llmErr.Op != opTranscribe || llmErr.Endpoint != groqTranscriptionsEndpoint {
+		t.Errorf("Expected the error to describe the transcription, got: %#v", err)
+	}
+}
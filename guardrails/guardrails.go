@@ -0,0 +1,243 @@
+// Package guardrails evaluates policies on the prompts sent to a model and
+// the responses it returns.
+//
+// A Policy looks at the content of one stage, input or output, and allows
+// it, denies it, or transforms it (e.g. redacting an email address). An
+// Engine runs its policies in order: a denial stops the evaluation, a
+// transformation hands the changed content to the next policy. Every
+// denial and transformation is reported as an audit Event.
+//
+// Policies can be built in code or declared in a Spec, e.g. from a TOML
+// file:
+//
+//	engine, err := guardrails.NewFromSpec(guardrails.Spec{
+//		MaxPromptLength: 8000,
+//		BannedTopics:    []string{"medical advice"},
+//		Redact:          []guardrails.RedactRule{{Pattern: `[\w.+-]+@[\w-]+\.[\w.]+`, Replacement: "[email]"}},
+//		ForbiddenTools:  []string{"delete_account"},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	engine.OnAudit(func(e guardrails.Event) { log.Printf("guardrail %s: %s %s: %s", e.Policy, e.Stage, e.Action, e.Reason) })
+//
+//	client = guardrails.Wrap(client, engine)
+package guardrails
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// Stage is the point at which content is evaluated.
+type Stage string
+
+const (
+	// StageInput is the prompt and tools sent to the model.
+	StageInput Stage = "input"
+	// StageOutput is the text and tool calls returned by the model.
+	StageOutput Stage = "output"
+)
+
+// Action is a policy's verdict on content.
+type Action int
+
+const (
+	// Allow passes the content on unchanged.
+	Allow Action = iota
+	// Deny rejects the content.
+	Deny
+	// Transform passes on the content in Decision.Content instead.
+	Transform
+)
+
+// String returns "allow", "deny" or "transform".
+func (a Action) String() string {
+	switch a {
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	case Transform:
+		return "transform"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// Content is what policies evaluate.
+type Content struct {
+	// Text is the prompt at StageInput and the response at StageOutput.
+	Text string
+	// Tools are the tools offered to the model, at StageInput.
+	Tools []xollm.Tool
+	// ToolCalls are the tool calls requested by the model, at StageOutput.
+	ToolCalls []xollm.ToolCall
+}
+
+// Decision is a policy's verdict on content.
+type Decision struct {
+	Action Action
+	// Reason explains a denial or transformation.
+	Reason string
+	// Content replaces the evaluated content when Action is Transform.
+	Content Content
+}
+
+// Policy evaluates content at a stage.
+type Policy interface {
+	// Name identifies the policy in audit events and errors.
+	Name() string
+	// Evaluate returns the policy's decision on content. Policies return
+	// Allow for stages they don't apply to. An error means the content
+	// could not be evaluated; the engine treats it as a denial.
+	Evaluate(ctx context.Context, stage Stage, content Content) (Decision, error)
+}
+
+// Event records a denial or transformation, for audit logs.
+type Event struct {
+	Time   time.Time
+	Stage  Stage
+	Policy string
+	Action Action
+	Reason string
+}
+
+// DeniedError reports content denied by a policy. It matches
+// xollm.ErrContentFiltered with errors.Is, so it is never retried.
+type DeniedError struct {
+	Stage  Stage
+	Policy string
+	Reason string
+}
+
+// Error names the policy and its reason.
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("guardrail %s denied the %s: %s", e.Policy, e.Stage, e.Reason)
+}
+
+// Is reports whether target is xollm.ErrContentFiltered.
+func (e *DeniedError) Is(target error) bool {
+	return target == xollm.ErrContentFiltered
+}
+
+// Engine evaluates content against a list of policies. It is safe for
+// concurrent use once configured.
+type Engine struct {
+	policies []Policy
+	audit    func(Event)
+}
+
+// New returns an engine evaluating policies in order.
+func New(policies ...Policy) *Engine {
+	return &Engine{policies: policies}
+}
+
+// OnAudit sets the function that receives an Event for every denial and
+// transformation. Set it before the engine is used.
+func (e *Engine) OnAudit(fn func(Event)) {
+	e.audit = fn
+}
+
+// Evaluate runs the policies on content at stage and returns the content
+// to use, transformed by any Transform decisions. A denial, or a policy
+// failing to evaluate, is returned as a *DeniedError.
+func (e *Engine) Evaluate(ctx context.Context, stage Stage, content Content) (Content, error) {
+	for _, p := range e.policies {
+		decision, err := p.Evaluate(ctx, stage, content)
+		if err != nil {
+			decision = Decision{Action: Deny, Reason: fmt.Sprintf("evaluation failed: %v", err)}
+		}
+		switch decision.Action {
+		case Allow:
+			continue
+		case Deny:
+			e.record(stage, p.Name(), decision)
+			return Content{}, &DeniedError{Stage: stage, Policy: p.Name(), Reason: decision.Reason}
+		case Transform:
+			e.record(stage, p.Name(), decision)
+			content = decision.Content
+		default:
+			return Content{}, fmt.Errorf("guardrail %s returned unknown action %v", p.Name(), decision.Action)
+		}
+	}
+	return content, nil
+}
+
+// record sends an audit event for decision.
+func (e *Engine) record(stage Stage, policy string, decision Decision) {
+	if e.audit == nil {
+		return
+	}
+	e.audit(Event{
+		Time:   time.Now(),
+		Stage:  stage,
+		Policy: policy,
+		Action: decision.Action,
+		Reason: decision.Reason,
+	})
+}
+
+// Client is an xollm.Client whose prompts and responses pass through an
+// Engine.
+type Client struct {
+	client xollm.Client
+	engine *Engine
+}
+
+// Wrap returns client with every prompt evaluated at StageInput before it
+// is sent and every response evaluated at StageOutput before it is
+// returned.
+func Wrap(client xollm.Client, engine *Engine) *Client {
+	return &Client{client: client, engine: engine}
+}
+
+// Generate evaluates prompt, generates the response and evaluates it.
+func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
+	in, err := c.engine.Evaluate(ctx, StageInput, Content{Text: prompt})
+	if err != nil {
+		return "", err
+	}
+	text, err := c.client.Generate(ctx, in.Text)
+	if err != nil {
+		return "", err
+	}
+	out, err := c.engine.Evaluate(ctx, StageOutput, Content{Text: text})
+	if err != nil {
+		return "", err
+	}
+	return out.Text, nil
+}
+
+// GenerateWithTools implements xollm.ToolCaller, evaluating the prompt and
+// offered tools, then the response text and tool calls. It fails if the
+// wrapped client doesn't support tool calling.
+func (c *Client) GenerateWithTools(ctx context.Context, prompt string, tools []xollm.Tool, choice xollm.ToolChoice) (*xollm.ToolResponse, error) {
+	in, err := c.engine.Evaluate(ctx, StageInput, Content{Text: prompt, Tools: tools})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := xollm.GenerateWithTools(ctx, c.client, in.Text, in.Tools, choice)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.engine.Evaluate(ctx, StageOutput, Content{Text: resp.Text, ToolCalls: resp.ToolCalls})
+	if err != nil {
+		return nil, err
+	}
+	checked := *resp
+	checked.Text, checked.ToolCalls = out.Text, out.ToolCalls
+	return &checked, nil
+}
+
+// ProviderName returns the wrapped client's provider name.
+func (c *Client) ProviderName() string {
+	return c.client.ProviderName()
+}
+
+// Close closes the wrapped client.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,141 @@
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient implements xollm.Client and xollm.ToolCaller for testing
+type mockClient struct {
+	prompt    string
+	tools     []xollm.Tool
+	response  string
+	toolCalls []xollm.ToolCall
+	closed    bool
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	m.prompt = prompt
+	return m.response, nil
+}
+
+func (m *mockClient) GenerateWithTools(ctx context.Context, prompt string, tools []xollm.Tool, choice xollm.ToolChoice) (*xollm.ToolResponse, error) {
+	m.prompt, m.tools = prompt, tools
+	return &xollm.ToolResponse{Text: m.response, ToolCalls: m.toolCalls, FinishReason: "tool_calls"}, nil
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error {
+	m.closed = true
+	return nil
+}
+
+// errorPolicy fails to evaluate
+type errorPolicy struct{}
+
+func (errorPolicy) Name() string { return "broken" }
+
+func (errorPolicy) Evaluate(ctx context.Context, stage Stage, content Content) (Decision, error) {
+	return Decision{}, errors.New("backend down")
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	var events []Event
+	engine := New(
+		Redact(regexp.MustCompile(`\d{4}`), "####"),
+		BannedTopics("weapons"),
+	)
+	engine.OnAudit(func(e Event) { events = append(events, e) })
+
+	out, err := engine.Evaluate(context.Background(), StageInput, Content{Text: "PIN 1234"})
+	if err != nil || out.Text != "PIN ####" {
+		t.Fatalf("Expected redacted text, got %q, %v", out.Text, err)
+	}
+	if len(events) != 1 || events[0].Action != Transform || events[0].Policy != "redact" || events[0].Stage != StageInput {
+		t.Errorf("Expected one transform event, got %+v", events)
+	}
+
+	_, err = engine.Evaluate(context.Background(), StageOutput, Content{Text: "Let's talk about Weapons"})
+	var denied *DeniedError
+	if !errors.As(err, &denied) || denied.Policy != "banned_topics" || denied.Stage != StageOutput {
+		t.Fatalf("Expected denial by banned_topics, got %v", err)
+	}
+	if !errors.Is(err, xollm.ErrContentFiltered) || !xollm.IsFatal(err) {
+		t.Error("Expected the denial to match ErrContentFiltered")
+	}
+	if len(events) != 2 || events[1].Action != Deny {
+		t.Errorf("Expected a deny event, got %+v", events)
+	}
+}
+
+func TestEngine_PolicyErrorDenies(t *testing.T) {
+	_, err := New(errorPolicy{}).Evaluate(context.Background(), StageInput, Content{Text: "hi"})
+	var denied *DeniedError
+	if !errors.As(err, &denied) || !strings.Contains(denied.Reason, "backend down") {
+		t.Errorf("Expected a denial for the failed policy, got %v", err)
+	}
+}
+
+func TestWrap_Generate(t *testing.T) {
+	client := &mockClient{response: "Call me at 555-1234"}
+	engine := New(MaxPromptLength(20), Redact(regexp.MustCompile(`\d{3}-\d{4}`), "[phone]"))
+	wrapped := Wrap(client, engine)
+
+	text, err := wrapped.Generate(context.Background(), "My number is 555-9876")
+	if err == nil {
+		t.Fatalf("Expected the long prompt to be denied, got %q", text)
+	}
+	if client.prompt != "" {
+		t.Error("Expected the denied prompt not to be sent")
+	}
+
+	text, err = wrapped.Generate(context.Background(), "Mine is 555-9876")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if client.prompt != "Mine is [phone]" || text != "Call me at [phone]" {
+		t.Errorf("Expected input and output to be redacted, got %q and %q", client.prompt, text)
+	}
+	if wrapped.ProviderName() != "mock" {
+		t.Errorf("Unexpected provider name %q", wrapped.ProviderName())
+	}
+	if err := wrapped.Close(); err != nil || !client.closed {
+		t.Error("Expected the wrapped client to be closed")
+	}
+}
+
+func TestWrap_GenerateWithTools(t *testing.T) {
+	tools := []xollm.Tool{{Name: "search"}, {Name: "delete_account"}}
+	client := &mockClient{toolCalls: []xollm.ToolCall{{Name: "search"}}}
+	wrapped := Wrap(client, New(ForbiddenTools("delete_account")))
+
+	resp, err := xollm.GenerateWithTools(context.Background(), wrapped, "Find it", tools, xollm.ToolChoiceAuto)
+	if err != nil {
+		t.Fatalf("GenerateWithTools failed: %v", err)
+	}
+	if len(client.tools) != 1 || client.tools[0].Name != "search" {
+		t.Errorf("Expected the forbidden tool to be removed, got %+v", client.tools)
+	}
+	if len(resp.ToolCalls) != 1 || resp.FinishReason != "tool_calls" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	client.toolCalls = []xollm.ToolCall{{Name: "delete_account"}}
+	if _, err := xollm.GenerateWithTools(context.Background(), wrapped, "Delete it", tools, xollm.ToolChoiceAuto); !errors.Is(err, xollm.ErrContentFiltered) {
+		t.Errorf("Expected the forbidden call to be denied, got %v", err)
+	}
+}
+
+func TestAction_String(t *testing.T) {
+	for action, want := range map[Action]string{Allow: "allow", Deny: "deny", Transform: "transform", Action(9): "Action(9)"} {
+		if got := action.String(); got != want {
+			t.Errorf("Action(%d).String() = %q, want %q", int(action), got, want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package guardrails
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xostack/xollm"
+)
+
+// policyFunc is a Policy that applies to some stages only.
+type policyFunc struct {
+	name   string
+	stages []Stage
+	fn     func(content Content) (Decision, error)
+}
+
+func (p *policyFunc) Name() string { return p.name }
+
+func (p *policyFunc) Evaluate(ctx context.Context, stage Stage, content Content) (Decision, error) {
+	for _, s := range p.stages {
+		if s == stage {
+			return p.fn(content)
+		}
+	}
+	return Decision{Action: Allow}, nil
+}
+
+// bothStages is the stages of policies that apply to input and output.
+var bothStages = []Stage{StageInput, StageOutput}
+
+// MaxPromptLength denies prompts longer than n characters.
+func MaxPromptLength(n int) Policy {
+	return &policyFunc{
+		name:   "max_prompt_length",
+		stages: []Stage{StageInput},
+		fn: func(content Content) (Decision, error) {
+			if length := utf8.RuneCountInString(content.Text); length > n {
+				return Decision{Action: Deny, Reason: fmt.Sprintf("prompt is %d characters, the limit is %d", length, n)}, nil
+			}
+			return Decision{Action: Allow}, nil
+		},
+	}
+}
+
+// BannedPatterns denies input and output text matching any of patterns.
+func BannedPatterns(patterns ...*regexp.Regexp) Policy {
+	return &policyFunc{
+		name:   "banned_patterns",
+		stages: bothStages,
+		fn: func(content Content) (Decision, error) {
+			for _, re := range patterns {
+				if re.MatchString(content.Text) {
+					return Decision{Action: Deny, Reason: fmt.Sprintf("text matches banned pattern %q", re.String())}, nil
+				}
+			}
+			return Decision{Action: Allow}, nil
+		},
+	}
+}
+
+// BannedTopics denies input and output text mentioning any of topics.
+// Topics are matched as whole words or phrases, ignoring case.
+func BannedTopics(topics ...string) Policy {
+	patterns := make([]*regexp.Regexp, len(topics))
+	for i, topic := range topics {
+		patterns[i] = topicPattern(topic)
+	}
+	return &policyFunc{
+		name:   "banned_topics",
+		stages: bothStages,
+		fn: func(content Content) (Decision, error) {
+			for i, re := range patterns {
+				if re.MatchString(content.Text) {
+					return Decision{Action: Deny, Reason: fmt.Sprintf("text mentions banned topic %q", topics[i])}, nil
+				}
+			}
+			return Decision{Action: Allow}, nil
+		},
+	}
+}
+
+// wordChar matches a character that \b treats as part of a word.
+var wordChar = regexp.MustCompile(`^\w$`)
+
+// topicPattern matches topic as a phrase, ignoring case and allowing any
+// whitespace between its words. Word boundaries are only required next to
+// word characters, so topics such as "c++" still match.
+func topicPattern(topic string) *regexp.Regexp {
+	trimmed := strings.TrimSpace(topic)
+	if trimmed == "" {
+		return regexp.MustCompile(`$^`) // Never matches
+	}
+	words := strings.Fields(trimmed)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	pattern := strings.Join(words, `\s+`)
+	if wordChar.MatchString(trimmed[:1]) {
+		pattern = `\b` + pattern
+	}
+	if wordChar.MatchString(trimmed[len(trimmed)-1:]) {
+		pattern += `\b`
+	}
+	return regexp.MustCompile(`(?i)` + pattern)
+}
+
+// Redact replaces every match of pattern in input and output text with
+// replacement, which may refer to submatches as in
+// regexp.Regexp.ReplaceAllString.
+func Redact(pattern *regexp.Regexp, replacement string) Policy {
+	return &policyFunc{
+		name:   "redact",
+		stages: bothStages,
+		fn: func(content Content) (Decision, error) {
+			if !pattern.MatchString(content.Text) {
+				return Decision{Action: Allow}, nil
+			}
+			content.Text = pattern.ReplaceAllString(content.Text, replacement)
+			return Decision{Action: Transform, Reason: fmt.Sprintf("redacted matches of %q", pattern.String()), Content: content}, nil
+		},
+	}
+}
+
+// ForbiddenTools keeps the named tools away from the model: they are
+// removed from the tools offered at input, and a response calling one of
+// them anyway is denied.
+func ForbiddenTools(names ...string) Policy {
+	forbidden := make(map[string]bool, len(names))
+	for _, name := range names {
+		forbidden[name] = true
+	}
+	return &policyFunc{
+		name:   "forbidden_tools",
+		stages: bothStages,
+		fn: func(content Content) (Decision, error) {
+			for _, call := range content.ToolCalls {
+				if forbidden[call.Name] {
+					return Decision{Action: Deny, Reason: fmt.Sprintf("model called forbidden tool %q", call.Name)}, nil
+				}
+			}
+			var kept []xollm.Tool
+			var removed []string
+			for _, tool := range content.Tools {
+				if forbidden[tool.Name] {
+					removed = append(removed, tool.Name)
+				} else {
+					kept = append(kept, tool)
+				}
+			}
+			if len(removed) == 0 {
+				return Decision{Action: Allow}, nil
+			}
+			content.Tools = kept
+			return Decision{Action: Transform, Reason: "removed forbidden tools " + strings.Join(removed, ", "), Content: content}, nil
+		},
+	}
+}
+
+// OutputSchema denies responses that aren't JSON matching schema. A
+// markdown code fence or a sentence around the JSON is removed, as a
+// transformation, with xollm.ExtractJSON. It
+// doesn't apply to responses that only call tools.
+func OutputSchema(schema json.RawMessage) Policy {
+	return &policyFunc{
+		name:   "output_schema",
+		stages: []Stage{StageOutput},
+		fn: func(content Content) (Decision, error) {
+			if content.Text == "" && len(content.ToolCalls) > 0 {
+				return Decision{Action: Allow}, nil
+			}
+			text := xollm.ExtractJSON(content.Text)
+			if err := xollm.ValidateJSON(schema, []byte(text)); err != nil {
+				var schemaErr *xollm.SchemaError
+				if !errors.As(err, &schemaErr) {
+					return Decision{}, err // The schema itself is broken
+				}
+				return Decision{Action: Deny, Reason: "output does not match the schema: " + err.Error()}, nil
+			}
+			if text == content.Text {
+				return Decision{Action: Allow}, nil
+			}
+			content.Text = text
+			return Decision{Action: Transform, Reason: "removed text around the JSON output", Content: content}, nil
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package guardrails
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+func evaluate(t *testing.T, p Policy, stage Stage, content Content) Decision {
+	t.Helper()
+	d, err := p.Evaluate(context.Background(), stage, content)
+	if err != nil {
+		t.Fatalf("%s: Evaluate failed: %v", p.Name(), err)
+	}
+	return d
+}
+
+func TestMaxPromptLength(t *testing.T) {
+	p := MaxPromptLength(5)
+	if d := evaluate(t, p, StageInput, Content{Text: "héllo"}); d.Action != Allow {
+		t.Errorf("Expected 5 characters to be allowed, got %v", d.Action)
+	}
+	if d := evaluate(t, p, StageInput, Content{Text: "hello!"}); d.Action != Deny {
+		t.Errorf("Expected 6 characters to be denied, got %v", d.Action)
+	}
+	if d := evaluate(t, p, StageOutput, Content{Text: "a long response"}); d.Action != Allow {
+		t.Errorf("Expected output not to be checked, got %v", d.Action)
+	}
+}
+
+func TestBannedPatterns(t *testing.T) {
+	p := BannedPatterns(regexp.MustCompile(`(?i)api[_-]?key`))
+	if d := evaluate(t, p, StageOutput, Content{Text: "your API_KEY is"}); d.Action != Deny {
+		t.Errorf("Expected denial, got %v", d.Action)
+	}
+	if d := evaluate(t, p, StageInput, Content{Text: "hello"}); d.Action != Allow {
+		t.Errorf("Expected allow, got %v", d.Action)
+	}
+}
+
+func TestBannedTopics(t *testing.T) {
+	p := BannedTopics("medical advice", "c++")
+	tests := map[string]Action{
+		"I need MEDICAL\nadvice":      Deny,
+		"Is c++ faster?":              Deny,
+		"biomedical advice columns":   Allow,
+		"a medical question, advice?": Allow,
+	}
+	for text, want := range tests {
+		if d := evaluate(t, p, StageInput, Content{Text: text}); d.Action != want {
+			t.Errorf("%q: expected %v, got %v", text, want, d.Action)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	p := Redact(regexp.MustCompile(`(\w+)@example\.com`), "$1@[redacted]")
+	d := evaluate(t, p, StageOutput, Content{Text: "mail ada@example.com"})
+	if d.Action != Transform || d.Content.Text != "mail ada@[redacted]" {
+		t.Errorf("Unexpected decision: %+v", d)
+	}
+	if d := evaluate(t, p, StageInput, Content{Text: "nothing here"}); d.Action != Allow {
+		t.Errorf("Expected allow without matches, got %v", d.Action)
+	}
+}
+
+func TestOutputSchema(t *testing.T) {
+	p := OutputSchema([]byte(`{"type": "object", "required": ["answer"]}`))
+	tests := []struct {
+		text   string
+		action Action
+		out    string
+	}{
+		{`{"answer": 42}`, Allow, ""},
+		{"```json\n{\"answer\": 42}\n```", Transform, `{"answer": 42}`},
+		{`{"result": 42}`, Deny, ""},
+		{`The answer is 42`, Deny, ""},
+		{`Here it is: {"answer": 42}`, Transform, `{"answer": 42}`},
+	}
+	for _, tt := range tests {
+		d := evaluate(t, p, StageOutput, Content{Text: tt.text})
+		if d.Action != tt.action || (tt.action == Transform && d.Content.Text != tt.out) {
+			t.Errorf("%q: unexpected decision %+v", tt.text, d)
+		}
+	}
+	if d := evaluate(t, p, StageInput, Content{Text: "not json"}); d.Action != Allow {
+		t.Errorf("Expected input not to be checked, got %v", d.Action)
+	}
+	if d := evaluate(t, p, StageOutput, Content{ToolCalls: []xollm.ToolCall{{Name: "search"}}}); d.Action != Allow {
+		t.Errorf("Expected tool-only responses to be allowed, got %v", d.Action)
+	}
+
+	broken := OutputSchema([]byte(`{`))
+	if _, err := broken.Evaluate(context.Background(), StageOutput, Content{Text: `{}`}); err == nil {
+		t.Error("Expected an error for a broken schema")
+	}
+}
+
+func TestForbiddenTools(t *testing.T) {
+	p := ForbiddenTools("rm")
+	d := evaluate(t, p, StageInput, Content{Tools: []xollm.Tool{{Name: "ls"}, {Name: "rm"}}})
+	if d.Action != Transform || len(d.Content.Tools) != 1 || d.Content.Tools[0].Name != "ls" {
+		t.Errorf("Expected rm to be removed, got %+v", d)
+	}
+	if d := evaluate(t, p, StageInput, Content{Tools: []xollm.Tool{{Name: "ls"}}}); d.Action != Allow {
+		t.Errorf("Expected allow, got %v", d.Action)
+	}
+	if d := evaluate(t, p, StageOutput, Content{ToolCalls: []xollm.ToolCall{{Name: "rm"}}}); d.Action != Deny {
+		t.Errorf("Expected the call to be denied, got %v", d.Action)
+	}
+}
@@ -0,0 +1,93 @@
+package guardrails
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Spec declares a set of policies, for loading guardrails from
+// configuration files. Empty fields add no policy.
+//
+// In TOML:
+//
+//	max_prompt_length = 8000
+//	banned_topics = ["medical advice"]
+//	banned_patterns = ['(?i)\bpassword\s*[:=]']
+//	forbidden_tools = ["delete_account"]
+//	output_schema = '{"type": "object", "required": ["answer"]}'
+//
+//	[[redact]]
+//	pattern = '\b\d{3}-\d{2}-\d{4}\b'
+//	replacement = "[ssn]"
+type Spec struct {
+	// MaxPromptLength adds MaxPromptLength when positive.
+	MaxPromptLength int `toml:"max_prompt_length" json:"max_prompt_length,omitempty"`
+	// BannedTopics adds BannedTopics.
+	BannedTopics []string `toml:"banned_topics" json:"banned_topics,omitempty"`
+	// BannedPatterns adds BannedPatterns, with regular expressions in Go
+	// syntax.
+	BannedPatterns []string `toml:"banned_patterns" json:"banned_patterns,omitempty"`
+	// ForbiddenTools adds ForbiddenTools.
+	ForbiddenTools []string `toml:"forbidden_tools" json:"forbidden_tools,omitempty"`
+	// Redact adds a Redact policy per rule.
+	Redact []RedactRule `toml:"redact" json:"redact,omitempty"`
+	// OutputSchema adds OutputSchema with this JSON Schema.
+	OutputSchema string `toml:"output_schema" json:"output_schema,omitempty"`
+}
+
+// RedactRule declares a Redact policy.
+type RedactRule struct {
+	Pattern     string `toml:"pattern" json:"pattern"`
+	Replacement string `toml:"replacement" json:"replacement"`
+}
+
+// Policies builds the declared policies. Denying policies come first, then
+// redactions, then the output schema, so that banned content is caught
+// before it is rewritten.
+func (s Spec) Policies() ([]Policy, error) {
+	var policies []Policy
+	if s.MaxPromptLength > 0 {
+		policies = append(policies, MaxPromptLength(s.MaxPromptLength))
+	}
+	if len(s.BannedTopics) > 0 {
+		policies = append(policies, BannedTopics(s.BannedTopics...))
+	}
+	if len(s.BannedPatterns) > 0 {
+		patterns := make([]*regexp.Regexp, len(s.BannedPatterns))
+		for i, p := range s.BannedPatterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid banned pattern %q: %w", p, err)
+			}
+			patterns[i] = re
+		}
+		policies = append(policies, BannedPatterns(patterns...))
+	}
+	if len(s.ForbiddenTools) > 0 {
+		policies = append(policies, ForbiddenTools(s.ForbiddenTools...))
+	}
+	for _, rule := range s.Redact {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", rule.Pattern, err)
+		}
+		policies = append(policies, Redact(re, rule.Replacement))
+	}
+	if s.OutputSchema != "" {
+		if !json.Valid([]byte(s.OutputSchema)) {
+			return nil, fmt.Errorf("output schema is not valid JSON")
+		}
+		policies = append(policies, OutputSchema(json.RawMessage(s.OutputSchema)))
+	}
+	return policies, nil
+}
+
+// NewFromSpec returns an engine evaluating the policies declared in spec.
+func NewFromSpec(spec Spec) (*Engine, error) {
+	policies, err := spec.Policies()
+	if err != nil {
+		return nil, err
+	}
+	return New(policies...), nil
+}
@@ -0,0 +1,76 @@
+package guardrails
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestSpec_FromTOML(t *testing.T) {
+	data := `
+max_prompt_length = 100
+banned_topics = ["medical advice"]
+banned_patterns = ['(?i)password\s*[:=]']
+forbidden_tools = ["delete_account"]
+output_schema = '{"type": "object"}'
+
+[[redact]]
+pattern = '\b\d{3}-\d{2}-\d{4}\b'
+replacement = "[ssn]"
+`
+	var spec Spec
+	if _, err := toml.Decode(data, &spec); err != nil {
+		t.Fatalf("Failed to decode spec: %v", err)
+	}
+
+	policies, err := spec.Policies()
+	if err != nil {
+		t.Fatalf("Policies failed: %v", err)
+	}
+	var names []string
+	for _, p := range policies {
+		names = append(names, p.Name())
+	}
+	want := []string{"max_prompt_length", "banned_topics", "banned_patterns", "forbidden_tools", "redact", "output_schema"}
+	if len(names) != len(want) {
+		t.Fatalf("Expected policies %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Policy %d: expected %s, got %s", i, want[i], names[i])
+		}
+	}
+
+	engine := New(policies...)
+	out, err := engine.Evaluate(context.Background(), StageInput, Content{Text: "SSN 123-45-6789"})
+	if err != nil || out.Text != "SSN [ssn]" {
+		t.Errorf("Expected redaction, got %q, %v", out.Text, err)
+	}
+	_, err = engine.Evaluate(context.Background(), StageInput, Content{Text: "my Password: hunter2"})
+	var denied *DeniedError
+	if !errors.As(err, &denied) || denied.Policy != "banned_patterns" {
+		t.Errorf("Expected denial by banned_patterns, got %v", err)
+	}
+}
+
+func TestSpec_Invalid(t *testing.T) {
+	tests := map[string]Spec{
+		"banned pattern": {BannedPatterns: []string{"("}},
+		"redact pattern": {Redact: []RedactRule{{Pattern: "["}}},
+		"output schema":  {OutputSchema: "{"},
+	}
+	for name, spec := range tests {
+		if _, err := NewFromSpec(spec); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSpec_Empty(t *testing.T) {
+	policies, err := Spec{}.Policies()
+	if err != nil || len(policies) != 0 {
+		t.Errorf("Expected no policies, got %d, %v", len(policies), err)
+	}
+}
@@ -0,0 +1,27 @@
+package xollm
+
+import (
+	"context"
+)
+
+// Pinger is implemented by clients that can perform a lightweight
+// reachability and authentication check against their provider. All
+// bundled providers implement it.
+type Pinger interface {
+	// Ping returns nil if the provider is reachable and the client is
+	// correctly configured. It should be cheap enough to call from
+	// readiness probes.
+	Ping(ctx context.Context) error
+}
+
+// Ping checks the health of client.
+//
+// Clients that implement Pinger are asked to ping their provider. Clients
+// that do not are assumed healthy, since there is no cheap way to check
+// them without spending tokens on a real generation.
+func Ping(ctx context.Context, client Client) error {
+	if pinger, ok := client.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,43 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// pingingClient adds Ping to stubClient
+type pingingClient struct {
+	stubClient
+	pingErr error
+}
+
+func (p *pingingClient) Ping(ctx context.Context) error {
+	return p.pingErr
+}
+
+func TestPing_NonPingerIsHealthy(t *testing.T) {
+	if err := Ping(context.Background(), &stubClient{}); err != nil {
+		t.Errorf("Expected no error for client without Ping, got: %v", err)
+	}
+}
+
+func TestPing_NonPingerCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := Ping(ctx, &stubClient{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestPing_UsesPinger(t *testing.T) {
+	if err := Ping(context.Background(), &pingingClient{}); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	pingErr := errors.New("unreachable")
+	if err := Ping(context.Background(), &pingingClient{pingErr: pingErr}); !errors.Is(err, pingErr) {
+		t.Errorf("Expected ping error, got: %v", err)
+	}
+}
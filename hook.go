@@ -0,0 +1,47 @@
+package xollm
+
+import (
+	"sync"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// RequestHook modifies each outgoing request of a client just before it is
+// sent, e.g. to sign it for a gateway. See llm.RequestHook.
+type RequestHook = llm.RequestHook
+
+// WithRequestHook adds a hook run on every HTTP request of the client,
+// after the client has set its own headers.
+//
+//	client, err := groq.NewClient(ctx, key, "", 60, false, xollm.WithRequestHook(func(req *http.Request) error {
+//		return signer.Sign(req)
+//	}))
+func WithRequestHook(hook RequestHook) ClientOption {
+	return llm.WithRequestHook(hook)
+}
+
+var (
+	requestHooksMu sync.RWMutex
+	requestHooks   = map[string]RequestHook{}
+)
+
+// SetRequestHook sets the hook that GetClient, and so Pool, installs on the
+// clients it creates for provider, for configuration-driven applications
+// that don't call NewClient themselves. A nil hook removes it. Clients
+// created earlier are not affected.
+func SetRequestHook(provider string, hook RequestHook) {
+	requestHooksMu.Lock()
+	defer requestHooksMu.Unlock()
+	if hook == nil {
+		delete(requestHooks, provider)
+		return
+	}
+	requestHooks[provider] = hook
+}
+
+// requestHook returns the hook set for provider, or nil.
+func requestHook(provider string) RequestHook {
+	requestHooksMu.RLock()
+	defer requestHooksMu.RUnlock()
+	return requestHooks[provider]
+}
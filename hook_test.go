@@ -0,0 +1,44 @@
+package xollm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xostack/xollm/config"
+)
+
+func TestSetRequestHook(t *testing.T) {
+	var signed string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signed = r.Header.Get("X-Gateway-Signature")
+		w.Write([]byte(`{"model": "gemma:2b", "response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	SetRequestHook("ollama", func(req *http.Request) error {
+		req.Header.Set("X-Gateway-Signature", "sig:"+req.URL.Path)
+		return nil
+	})
+	defer SetRequestHook("ollama", nil)
+
+	client, err := GetClient(config.Config{
+		DefaultProvider: "ollama",
+		LLMs:            map[string]config.LLMConfig{"ollama": {BaseURL: server.URL}},
+	}, false)
+	if err != nil {
+		t.Fatalf("GetClient failed: %v", err)
+	}
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if signed != "sig:/api/generate" {
+		t.Errorf("Expected the registered hook to sign the request, got %q", signed)
+	}
+
+	SetRequestHook("ollama", nil)
+	if requestHook("ollama") != nil {
+		t.Error("Expected a nil hook to remove the registered one")
+	}
+}
@@ -0,0 +1,49 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// Image is an image attached to a prompt or chat message, by its bytes or
+// a URL. See llm.Image.
+type Image = llm.Image
+
+// ImageFromBytes returns an image of data, with its MIME type detected
+// from the content.
+func ImageFromBytes(data []byte) Image {
+	return llm.ImageFromBytes(data)
+}
+
+// ImageFromURL returns an image fetched from url, by the provider if it
+// accepts URLs and by the client otherwise.
+func ImageFromURL(url string) Image {
+	return llm.ImageFromURL(url)
+}
+
+// ImageFromFile reads an image from the file at path.
+func ImageFromFile(path string) (Image, error) {
+	return llm.ImageFromFile(path)
+}
+
+// VisionGenerator is implemented by clients that send images with the
+// prompt. See llm.VisionGenerator.
+type VisionGenerator = llm.VisionGenerator
+
+// GenerateWithImages sends prompt with images attached to a vision model,
+// e.g. a Gemini model, a Groq Llama vision model or llava on Ollama. It
+// fails for clients that don't implement VisionGenerator. Chat messages
+// carry images in their Images field.
+//
+//	img, err := xollm.ImageFromFile("receipt.jpg")
+//	...
+//	text, err := xollm.GenerateWithImages(ctx, client, "What is the total?", img)
+func GenerateWithImages(ctx context.Context, client Client, prompt string, images ...Image) (string, error) {
+	vg, ok := client.(VisionGenerator)
+	if !ok {
+		return "", fmt.Errorf("%s client does not support images", client.ProviderName())
+	}
+	return vg.GenerateWithImages(ctx, prompt, images)
+}
@@ -0,0 +1,48 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// visionClient describes the images it is given.
+type visionClient struct {
+	stubClient
+}
+
+func (c *visionClient) GenerateWithImages(ctx context.Context, prompt string, images []Image) (string, error) {
+	var types []string
+	for _, img := range images {
+		types = append(types, img.MIMEType)
+	}
+	return prompt + " " + strings.Join(types, ","), nil
+}
+
+func TestGenerateWithImages(t *testing.T) {
+	text, err := GenerateWithImages(context.Background(), &visionClient{}, "Describe", ImageFromBytes([]byte("GIF89a")))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != "Describe image/gif" {
+		t.Errorf("Expected the image passed to the client, got %q", text)
+	}
+
+	_, err = GenerateWithImages(context.Background(), &stubClient{}, "Describe", ImageFromURL("https://example.com/cat.png"))
+	if err == nil || !strings.Contains(err.Error(), "does not support images") {
+		t.Errorf("Expected unsupported error, got: %v", err)
+	}
+}
+
+func TestChat_FlattenedRejectsImages(t *testing.T) {
+	client := &promptRecorder{}
+	_, _, err := Chat(context.Background(), client, []Message{
+		{Role: RoleUser, Content: "Describe", Images: []Image{ImageFromURL("https://example.com/cat.png")}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not support images") {
+		t.Errorf("Expected images to be rejected rather than dropped, got: %v", err)
+	}
+	if client.prompt != "" {
+		t.Errorf("Expected no request, got prompt %q", client.prompt)
+	}
+}
@@ -0,0 +1,40 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// ImageGenerator is implemented by clients that generate images from a
+// text prompt. The Gemini client implements it with Imagen. See
+// llm.ImageGenerator.
+type ImageGenerator = llm.ImageGenerator
+
+// ImageOptions tune image generation. See llm.ImageOptions.
+type ImageOptions = llm.ImageOptions
+
+// WithImageModel sets the model that generates the client's images.
+// Gemini clients default to imagen-3.0-generate-002.
+func WithImageModel(model string) ClientOption {
+	return llm.WithImageModel(model)
+}
+
+// GenerateImages returns the images generated for prompt, each with its
+// bytes and MIME type. It fails for clients that don't implement
+// ImageGenerator, such as Groq and Ollama.
+//
+//	images, err := xollm.GenerateImages(ctx, client, "A lighthouse at dusk, watercolor", xollm.ImageOptions{AspectRatio: "16:9"})
+//	...
+//	err = os.WriteFile("lighthouse.png", images[0].Data, 0o644)
+func GenerateImages(ctx context.Context, client Client, prompt string, opts ImageOptions) ([]Image, error) {
+	g, ok := client.(ImageGenerator)
+	if !ok {
+		return nil, fmt.Errorf("%s client does not support image generation", client.ProviderName())
+	}
+	if prompt == "" {
+		return nil, fmt.Errorf("image prompt cannot be empty")
+	}
+	return g.GenerateImages(ctx, prompt, opts)
+}
@@ -0,0 +1,43 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// paintingClient returns one image holding the prompt
+type paintingClient struct {
+	stubClient
+	opts ImageOptions
+}
+
+func (c *paintingClient) GenerateImages(ctx context.Context, prompt string, opts ImageOptions) ([]Image, error) {
+	c.opts = opts
+	return []Image{{MIMEType: "image/png", Data: []byte(prompt)}}, nil
+}
+
+func TestGenerateImages(t *testing.T) {
+	client := &paintingClient{}
+	images, err := GenerateImages(context.Background(), client, "A lighthouse", ImageOptions{AspectRatio: "16:9"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(images) != 1 || string(images[0].Data) != "A lighthouse" {
+		t.Errorf("Unexpected images %+v", images)
+	}
+	if client.opts.AspectRatio != "16:9" {
+		t.Errorf("Expected the options to be passed on, got %+v", client.opts)
+	}
+
+	if _, err := GenerateImages(context.Background(), client, "", ImageOptions{}); err == nil {
+		t.Error("Expected an error for an empty prompt")
+	}
+}
+
+func TestGenerateImages_Unsupported(t *testing.T) {
+	_, err := GenerateImages(context.Background(), &stubClient{}, "A lighthouse", ImageOptions{})
+	if err == nil || !strings.Contains(err.Error(), "does not support image generation") {
+		t.Errorf("Expected an unsupported error, got: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"strings"
+	"sync"
+)
+
+// knownAliases maps logical model names to the identifiers each provider
+// serves the model under.
+var knownAliases = map[string]map[string]string{
+	"llama3-8b":    {"groq": "llama3-8b-8192", "ollama": "llama3"},
+	"llama3-70b":   {"groq": "llama3-70b-8192", "ollama": "llama3:70b"},
+	"llama3.1-8b":  {"groq": "llama-3.1-8b-instant", "ollama": "llama3.1"},
+	"llama3.3-70b": {"groq": "llama-3.3-70b-versatile", "ollama": "llama3.3"},
+	"gemma2-9b":    {"groq": "gemma2-9b-it", "ollama": "gemma2"},
+	"gemma3-27b":   {"gemini": "gemma-3-27b-it", "ollama": "gemma3:27b"},
+	"mixtral-8x7b": {"groq": "mixtral-8x7b-32768", "ollama": "mixtral"},
+}
+
+var (
+	aliasMu           sync.RWMutex
+	registeredAliases = map[string]map[string]string{}
+)
+
+// RegisterModelAlias makes the logical model name alias resolve to model
+// on provider, replacing any bundled or registered mapping.
+func RegisterModelAlias(alias, provider, model string) {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if alias == "" || provider == "" || model == "" {
+		return
+	}
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	if registeredAliases[alias] == nil {
+		registeredAliases[alias] = map[string]string{}
+	}
+	registeredAliases[alias][provider] = model
+}
+
+// ResolveModelAlias returns the identifier provider serves the logical
+// model name under, from RegisterModelAlias or the bundled aliases, so the
+// same name can be configured for every provider of a fallback chain or
+// ensemble. Names that aren't aliases for provider, including provider
+// identifiers, are returned unchanged with false.
+func ResolveModelAlias(provider, name string) (string, bool) {
+	alias := strings.ToLower(strings.TrimSpace(name))
+	aliasMu.RLock()
+	model, ok := registeredAliases[alias][provider]
+	aliasMu.RUnlock()
+	if ok {
+		return model, true
+	}
+	if model, ok := knownAliases[alias][provider]; ok {
+		return model, true
+	}
+	return name, false
+}
@@ -0,0 +1,44 @@
+package llm
+
+import "testing"
+
+func TestResolveModelAlias(t *testing.T) {
+	tests := []struct {
+		provider, name string
+		want           string
+		ok             bool
+	}{
+		{"groq", "llama3-8b", "llama3-8b-8192", true},
+		{"ollama", "Llama3-8B", "llama3", true},
+		{"gemini", "llama3-8b", "llama3-8b", false},
+		{"groq", "llama-3.3-70b-versatile", "llama-3.3-70b-versatile", false},
+		{"groq", "", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ResolveModelAlias(tt.provider, tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ResolveModelAlias(%q, %q) = %q, %v; want %q, %v", tt.provider, tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRegisterModelAlias(t *testing.T) {
+	RegisterModelAlias("test-fast", "groq", "llama-3.1-8b-instant")
+	RegisterModelAlias("llama3-8b", "gemini", "gemma-3-27b-it")
+	defer func() {
+		aliasMu.Lock()
+		delete(registeredAliases, "test-fast")
+		delete(registeredAliases, "llama3-8b")
+		aliasMu.Unlock()
+	}()
+
+	if got, ok := ResolveModelAlias("groq", "test-fast"); !ok || got != "llama-3.1-8b-instant" {
+		t.Errorf("Expected the registered alias, got %q, %v", got, ok)
+	}
+	if got, ok := ResolveModelAlias("gemini", "llama3-8b"); !ok || got != "gemma-3-27b-it" {
+		t.Errorf("Expected a registered provider mapping, got %q, %v", got, ok)
+	}
+	if got, _ := ResolveModelAlias("groq", "llama3-8b"); got != "llama3-8b-8192" {
+		t.Errorf("Expected the bundled mapping to remain for other providers, got %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// PromptPart is a piece of a prompt. Parts are concatenated in order to
+// form the prompt text.
+type PromptPart struct {
+	// Text is the part's text.
+	Text string
+	// Cacheable marks text that is sent unchanged with many prompts, such
+	// as instructions or a reference document, so providers with prompt
+	// caching can store it once. Only the leading run of cacheable parts
+	// is cached; put the varying parts last.
+	Cacheable bool
+}
+
+// SplitCacheable returns the concatenated leading cacheable parts and the
+// concatenated rest.
+func SplitCacheable(parts []PromptPart) (prefix, rest string) {
+	var p, r strings.Builder
+	cached := true
+	for _, part := range parts {
+		if !part.Cacheable {
+			cached = false
+		}
+		if cached {
+			p.WriteString(part.Text)
+		} else {
+			r.WriteString(part.Text)
+		}
+	}
+	return p.String(), r.String()
+}
+
+// JoinParts returns the full prompt text of parts.
+func JoinParts(parts []PromptPart) string {
+	prefix, rest := SplitCacheable(parts)
+	return prefix + rest
+}
+
+// PromptCacher is implemented by clients that map cacheable prompt parts
+// to a provider caching mechanism.
+type PromptCacher interface {
+	// GenerateCached generates a response to the prompt made of parts,
+	// caching its cacheable prefix. Usage.CachedTokens reports how much of
+	// the prompt was served from the cache.
+	GenerateCached(ctx context.Context, parts []PromptPart) (string, Usage, error)
+}
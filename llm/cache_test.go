@@ -0,0 +1,28 @@
+package llm
+
+import "testing"
+
+func TestSplitCacheable(t *testing.T) {
+	tests := []struct {
+		name           string
+		parts          []PromptPart
+		prefix, suffix string
+	}{
+		{"empty", nil, "", ""},
+		{"all cacheable", []PromptPart{{Text: "a", Cacheable: true}, {Text: "b", Cacheable: true}}, "ab", ""},
+		{"prefix", []PromptPart{{Text: "doc ", Cacheable: true}, {Text: "question"}}, "doc ", "question"},
+		{"nothing cacheable", []PromptPart{{Text: "a"}, {Text: "b"}}, "", "ab"},
+		{"cacheable after varying text", []PromptPart{{Text: "a", Cacheable: true}, {Text: "b"}, {Text: "c", Cacheable: true}}, "a", "bc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, rest := SplitCacheable(tt.parts)
+			if prefix != tt.prefix || rest != tt.suffix {
+				t.Errorf("Expected (%q, %q), got (%q, %q)", tt.prefix, tt.suffix, prefix, rest)
+			}
+			if JoinParts(tt.parts) != tt.prefix+tt.suffix {
+				t.Errorf("Expected JoinParts to keep every part in order, got %q", JoinParts(tt.parts))
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package llm
+
+import "context"
+
+// CandidateGenerator is implemented by clients whose provider can return
+// several completions of one prompt from a single request, such as Gemini
+// with its candidate count. The prompt is billed once.
+type CandidateGenerator interface {
+	// GenerateCandidates returns n completions of prompt, in the order the
+	// provider returned them, and the metadata of the request, whose usage
+	// covers all of them and whose finish reason is the first one's.
+	GenerateCandidates(ctx context.Context, prompt string, n int) ([]string, *ResponseMetadata, error)
+}
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Role is the author of a chat message.
+type Role string
+
+const (
+	// RoleSystem messages instruct the model how to behave. Providers
+	// without system messages in the conversation, such as Gemini, send
+	// them as the system instruction.
+	RoleSystem Role = "system"
+	// RoleUser messages are written by the user.
+	RoleUser Role = "user"
+	// RoleAssistant messages are earlier responses of the model.
+	RoleAssistant Role = "assistant"
+	// RoleTool messages return the result of a tool call to the model, see
+	// ToolResultMessage.
+	RoleTool Role = "tool"
+)
+
+// Message is one turn of a chat.
+type Message struct {
+	Role    Role
+	Content string
+	// Images are attached to a user message for vision models. Clients
+	// without image support reject messages with images.
+	Images []Image
+	// ToolCalls are the tool calls an assistant message made, see
+	// ToolResponse.Message.
+	ToolCalls []ToolCall
+	// ToolCallID is the ID of the call a tool message answers.
+	ToolCallID string
+}
+
+// Chatter is implemented by clients that send conversations to the
+// provider's chat API natively, with each message's role, rather than as
+// one flattened prompt. All bundled providers implement it.
+type Chatter interface {
+	// Chat sends messages, oldest first, and returns the model's reply to
+	// the last one with its metadata.
+	Chat(ctx context.Context, messages []Message) (string, *ResponseMetadata, error)
+}
+
+// CheckMessages returns an error if messages is empty, has a message of an
+// unknown role or a tool message that doesn't say which call it answers.
+func CheckMessages(messages []Message) error {
+	if len(messages) == 0 {
+		return fmt.Errorf("chat has no messages")
+	}
+	for i, m := range messages {
+		switch m.Role {
+		case RoleSystem, RoleUser, RoleAssistant:
+		case RoleTool:
+			if m.ToolCallID == "" {
+				return fmt.Errorf("chat message %d is a tool result without a tool call ID", i+1)
+			}
+		default:
+			return fmt.Errorf("chat message %d has unknown role %q", i+1, m.Role)
+		}
+	}
+	return nil
+}
+
+// FlattenMessages writes messages as a single prompt, one "Role: content"
+// paragraph per message, ending with "Assistant:" for the model to
+// continue. It is how chats are sent to clients that don't implement
+// Chatter, and what the pre-flight check measures.
+func FlattenMessages(messages []Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		role := string(m.Role)
+		if role != "" {
+			role = strings.ToUpper(role[:1]) + role[1:]
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", role, m.Content)
+	}
+	b.WriteString("Assistant:")
+	return b.String()
+}
+
+// SystemText returns the content of the system messages, joined by blank
+// lines, and the other messages, for providers that take the system
+// instruction separately.
+func SystemText(messages []Message) (string, []Message) {
+	var system []string
+	rest := make([]Message, 0, len(messages))
+	for _, m := range messages {
+		if m.Role == RoleSystem {
+			system = append(system, m.Content)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+	return strings.Join(system, "\n\n"), rest
+}
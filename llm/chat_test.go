@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"testing"
+)
+
+func TestCheckMessages(t *testing.T) {
+	if err := CheckMessages(nil); err == nil {
+		t.Error("Expected an error for no messages")
+	}
+	if err := CheckMessages([]Message{{Role: RoleUser, Content: "Hi"}, {Role: "bot", Content: "Hello"}}); err == nil {
+		t.Error("Expected an error for an unknown role")
+	}
+	if err := CheckMessages([]Message{{Role: RoleUser}, {Role: RoleTool}}); err == nil {
+		t.Error("Expected an error for a tool result without a call ID")
+	}
+	if err := CheckMessages([]Message{{Role: RoleSystem}, {Role: RoleUser}, {Role: RoleAssistant}, {Role: RoleTool, ToolCallID: "1"}}); err != nil {
+		t.Errorf("Expected valid messages to pass, got: %v", err)
+	}
+}
+
+func TestFlattenMessages(t *testing.T) {
+	got := FlattenMessages([]Message{{Role: RoleSystem, Content: "Be brief."}, {Role: RoleUser, Content: "Hi"}})
+	if want := "System: Be brief.\n\nUser: Hi\n\nAssistant:"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestSystemText(t *testing.T) {
+	system, rest := SystemText([]Message{
+		{Role: RoleSystem, Content: "Be brief."},
+		{Role: RoleUser, Content: "Hi"},
+		{Role: RoleSystem, Content: "Answer in French."},
+		{Role: RoleAssistant, Content: "Salut"},
+	})
+	if system != "Be brief.\n\nAnswer in French." {
+		t.Errorf("Unexpected system text %q", system)
+	}
+	if len(rest) != 2 || rest[0].Role != RoleUser || rest[1].Role != RoleAssistant {
+		t.Errorf("Unexpected other messages %+v", rest)
+	}
+}
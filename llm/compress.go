@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// gzipTransport compresses request bodies of at least minBytes before
+// handing the request to base.
+type gzipTransport struct {
+	base     http.RoundTripper
+	minBytes int
+}
+
+// RoundTrip sends req, gzipping its body first if it is large enough and
+// not already encoded. The caller's request is not modified.
+func (t *gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength < int64(t.minBytes) || req.Header.Get("Content-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body for compression: %w", err)
+	}
+	compressed, err := gzipBytes(body)
+	if err != nil {
+		return nil, err
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(compressed))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	out.ContentLength = int64(len(compressed))
+	out.Header.Set("Content-Encoding", "gzip")
+	out.Header.Set("Content-Length", strconv.Itoa(len(compressed)))
+	return t.base.RoundTrip(out)
+}
+
+// gzipBytes returns data compressed with gzip.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipTransport(t *testing.T) {
+	var gotEncoding, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if gotEncoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Invalid gzip body: %v", err)
+				return
+			}
+			body = zr
+		}
+		data, _ := io.ReadAll(body)
+		gotBody = string(data)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRoundTripper(TransportOptions{CompressRequestsOver: 100})}
+	send := func(payload string) {
+		t.Helper()
+		req, _ := http.NewRequest("POST", server.URL, bytes.NewBufferString(payload))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		resp.Body.Close()
+		if req.Header.Get("Content-Encoding") != "" {
+			t.Error("Expected the caller's request to be left unchanged")
+		}
+	}
+
+	large := strings.Repeat("prompt ", 100)
+	send(large)
+	if gotEncoding != "gzip" || gotBody != large {
+		t.Errorf("Expected large body to arrive gzipped and intact, got encoding '%s'", gotEncoding)
+	}
+
+	send("small")
+	if gotEncoding != "" || gotBody != "small" {
+		t.Errorf("Expected small body to be sent as is, got encoding '%s'", gotEncoding)
+	}
+}
+
+func TestNewTransport_ResponseCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			w.Write([]byte("plain"))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte("compressed"))
+		zw.Close()
+	}))
+	defer server.Close()
+
+	get := func(opts TransportOptions) string {
+		resp, err := (&http.Client{Transport: NewTransport(opts)}).Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return string(data)
+	}
+
+	if got := get(TransportOptions{}); got != "compressed" {
+		t.Errorf("Expected gzip response to be decompressed, got '%s'", got)
+	}
+	if got := get(TransportOptions{DisableCompression: true}); got != "plain" {
+		t.Errorf("Expected uncompressed response, got '%s'", got)
+	}
+}
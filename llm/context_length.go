@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ContextLengthError reports a request rejected because it does not fit in
+// the model's context window, with the token counts the provider reported.
+// It matches ErrContextTooLong with errors.Is.
+//
+// Counts the provider did not report are 0. MaxTokens falls back to the
+// bundled model registry when the message doesn't include the limit.
+type ContextLengthError struct {
+	// Model is the model the request was sent to.
+	Model string
+	// PromptTokens is the size of the prompt in tokens.
+	PromptTokens int
+	// RequestedTokens is the prompt plus the requested completion length,
+	// for providers that count both against the window.
+	RequestedTokens int
+	// MaxTokens is the model's context window.
+	MaxTokens int
+	// Estimated is true when the error comes from a pre-flight check: the
+	// request was never sent and PromptTokens is an estimate.
+	Estimated bool
+	// Err is the provider error, or nil for pre-flight checks.
+	Err error
+}
+
+// Error describes the overflow, including the counts that are known.
+func (e *ContextLengthError) Error() string {
+	used := e.RequestedTokens
+	if used == 0 {
+		used = e.PromptTokens
+	}
+	var msg string
+	switch {
+	case used > 0 && e.MaxTokens > 0 && e.Estimated:
+		msg = fmt.Sprintf("context too long: an estimated %d tokens requested, model '%s' allows %d", used, e.Model, e.MaxTokens)
+	case used > 0 && e.MaxTokens > 0:
+		msg = fmt.Sprintf("context too long: %d tokens requested, model '%s' allows %d", used, e.Model, e.MaxTokens)
+	case e.MaxTokens > 0:
+		msg = fmt.Sprintf("context too long: model '%s' allows %d tokens", e.Model, e.MaxTokens)
+	default:
+		msg = "context too long"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Is reports whether target is ErrContextTooLong.
+func (e *ContextLengthError) Is(target error) bool {
+	return target == ErrContextTooLong
+}
+
+// Unwrap returns the provider error.
+func (e *ContextLengthError) Unwrap() error {
+	return e.Err
+}
+
+// Excess returns how many tokens must be removed for the request to fit,
+// or 0 if the counts are unknown.
+func (e *ContextLengthError) Excess() int {
+	used := e.RequestedTokens
+	if used == 0 {
+		used = e.PromptTokens
+	}
+	if used == 0 || e.MaxTokens == 0 || used <= e.MaxTokens {
+		return 0
+	}
+	return used - e.MaxTokens
+}
+
+// Patterns for the token counts in provider context-length messages.
+var (
+	// OpenAI-compatible (Groq): "This model's maximum context length is 8192
+	// tokens. However, you requested 9000 tokens (8000 in the messages, 1000
+	// in the completion)."
+	maxContextPattern = regexp.MustCompile(`(?i)maximum context length is (\d+) tokens`)
+	requestedPattern  = regexp.MustCompile(`(?i)requested (\d+) tokens`)
+	messagesPattern   = regexp.MustCompile(`(?i)\((\d+) in the messages`)
+
+	// Gemini: "The input token count (40000) exceeds the maximum number of
+	// tokens allowed (32768)."
+	inputCountPattern = regexp.MustCompile(`(?i)input token count \((\d+)\) exceeds the maximum number of tokens allowed \((\d+)\)`)
+
+	// Generic: "prompt is too long: 210000 tokens > 200000 maximum"
+	tooLongPattern = regexp.MustCompile(`(?i)(\d+) tokens > (\d+) maximum`)
+)
+
+// WrapContextLength converts a provider error classified as
+// ErrContextTooLong into a *ContextLengthError with the token counts parsed
+// from its message. Other errors are returned unchanged.
+func WrapContextLength(model string, err error) error {
+	if err == nil || !errors.Is(err, ErrContextTooLong) {
+		return err
+	}
+	var existing *ContextLengthError
+	if errors.As(err, &existing) {
+		return err
+	}
+
+	e := &ContextLengthError{Model: model, Err: err}
+	message := err.Error()
+	if m := inputCountPattern.FindStringSubmatch(message); m != nil {
+		e.PromptTokens, e.MaxTokens = atoi(m[1]), atoi(m[2])
+	} else if m := tooLongPattern.FindStringSubmatch(message); m != nil {
+		e.PromptTokens, e.MaxTokens = atoi(m[1]), atoi(m[2])
+	} else {
+		if m := maxContextPattern.FindStringSubmatch(message); m != nil {
+			e.MaxTokens = atoi(m[1])
+		}
+		if m := requestedPattern.FindStringSubmatch(message); m != nil {
+			e.RequestedTokens = atoi(m[1])
+		}
+		if m := messagesPattern.FindStringSubmatch(message); m != nil {
+			e.PromptTokens = atoi(m[1])
+		}
+	}
+
+	if e.MaxTokens == 0 {
+		if info, ok := LookupModel(model); ok {
+			e.MaxTokens = info.ContextWindow
+		}
+	}
+	return e
+}
+
+// PreflightCheck estimates the tokens in prompt with model's tokenizer (see
+// TokenizerFor) and returns a *ContextLengthError with Estimated set if they
+// exceed model's context window, so a request that is bound to be rejected
+// is never sent. Models missing from the registry always pass.
+func PreflightCheck(model, prompt string) error {
+	info, ok := LookupModel(model)
+	if !ok {
+		return nil
+	}
+	return PreflightCheckWindow(model, prompt, info.ContextWindow)
+}
+
+// PreflightCheckWindow is PreflightCheck against an explicit context
+// window, e.g. one configured for a self-hosted model. A window of zero or
+// less always passes.
+func PreflightCheckWindow(model, prompt string, window int) error {
+	if window <= 0 {
+		return nil
+	}
+	tokens := CountTokensFor(model, prompt)
+	if tokens <= window {
+		return nil
+	}
+	return &ContextLengthError{
+		Model:        model,
+		PromptTokens: tokens,
+		MaxTokens:    window,
+		Estimated:    true,
+	}
+}
+
+// atoi parses a regexp-matched digit string.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWrapContextLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		message   string
+		prompt    int
+		requested int
+		max       int
+		excess    int
+	}{
+		{
+			name:      "openai compatible",
+			model:     "llama-3.1-8b-instant",
+			message:   "This model's maximum context length is 8192 tokens. However, you requested 9000 tokens (8000 in the messages, 1000 in the completion).",
+			prompt:    8000,
+			requested: 9000,
+			max:       8192,
+			excess:    808,
+		},
+		{
+			name:    "gemini",
+			model:   "gemini-1.5-flash",
+			message: "The input token count (40000) exceeds the maximum number of tokens allowed (32768).",
+			prompt:  40000,
+			max:     32768,
+			excess:  7232,
+		},
+		{
+			name:    "generic",
+			message: "prompt is too long: 210000 tokens > 200000 maximum",
+			prompt:  210000,
+			max:     200000,
+			excess:  10000,
+		},
+		{
+			name:    "limit from registry",
+			model:   "gemma:2b",
+			message: "input length exceeds maximum context length",
+			max:     8192,
+		},
+		{
+			name:    "no counts",
+			model:   "unknown",
+			message: "context length exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cause := WrapError(ErrContextTooLong, errors.New(tt.message))
+			err := WrapContextLength(tt.model, cause)
+
+			var cle *ContextLengthError
+			if !errors.As(err, &cle) {
+				t.Fatalf("Expected *ContextLengthError, got %T", err)
+			}
+			if cle.PromptTokens != tt.prompt || cle.RequestedTokens != tt.requested || cle.MaxTokens != tt.max {
+				t.Errorf("Expected prompt=%d requested=%d max=%d, got %+v", tt.prompt, tt.requested, tt.max, cle)
+			}
+			if cle.Excess() != tt.excess {
+				t.Errorf("Expected excess %d, got %d", tt.excess, cle.Excess())
+			}
+			if !errors.Is(err, ErrContextTooLong) || !errors.Is(err, cause) {
+				t.Error("Expected error to match ErrContextTooLong and wrap the cause")
+			}
+		})
+	}
+}
+
+func TestWrapContextLength_OtherErrors(t *testing.T) {
+	other := WrapError(ErrRateLimited, errors.New("slow down"))
+	if WrapContextLength("m", other) != other {
+		t.Error("Expected unrelated errors to be returned unchanged")
+	}
+	if WrapContextLength("m", nil) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+
+	wrapped := WrapContextLength("m", ErrContextTooLong)
+	if again := WrapContextLength("m", fmt.Errorf("outer: %w", wrapped)); !errors.Is(again, wrapped) {
+		t.Error("Expected already structured errors to be left alone")
+	}
+}
+
+func TestContextLengthError_Message(t *testing.T) {
+	err := &ContextLengthError{Model: "m", PromptTokens: 100, MaxTokens: 80, Err: errors.New("rejected")}
+	if err.Error() != "context too long: 100 tokens requested, model 'm' allows 80: rejected" {
+		t.Errorf("Unexpected message: %s", err.Error())
+	}
+}
+
+func TestPreflightCheck(t *testing.T) {
+	long := strings.Repeat("word ", 10000) // ~10000 estimated tokens
+	err := PreflightCheck("gemma2-9b-it", long)
+
+	var clErr *ContextLengthError
+	if !errors.As(err, &clErr) {
+		t.Fatalf("Expected *ContextLengthError, got %v", err)
+	}
+	if !clErr.Estimated || clErr.MaxTokens != 8192 || clErr.PromptTokens != CountTokensFor("gemma2-9b-it", long) {
+		t.Errorf("Unexpected error details: %+v", clErr)
+	}
+	if !errors.Is(err, ErrContextTooLong) {
+		t.Error("Expected error to match ErrContextTooLong")
+	}
+	expected := fmt.Sprintf("context too long: an estimated %d tokens requested, model 'gemma2-9b-it' allows 8192", clErr.PromptTokens)
+	if err.Error() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, err.Error())
+	}
+
+	if err := PreflightCheck("gemma2-9b-it", "short prompt"); err != nil {
+		t.Errorf("Expected short prompt to pass, got: %v", err)
+	}
+	if err := PreflightCheck("unknown-model", long); err != nil {
+		t.Errorf("Expected unknown model to pass, got: %v", err)
+	}
+}
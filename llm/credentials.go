@@ -0,0 +1,9 @@
+package llm
+
+// CredentialSetter is implemented by clients whose API key can be replaced
+// while they are in use, so keys can be rotated without recreating clients.
+type CredentialSetter interface {
+	// SetCredentials replaces the API key used by requests sent from now
+	// on. Requests already in flight finish with the old key.
+	SetCredentials(apiKey string) error
+}
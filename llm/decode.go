@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// bodyRecorder keeps the first MaxErrorBodyBytes written to it, so that a
+// response can be decoded straight from the network while its start stays
+// available for error reports.
+type bodyRecorder struct {
+	buf       []byte
+	truncated bool
+}
+
+func (r *bodyRecorder) Write(p []byte) (int, error) {
+	room := MaxErrorBodyBytes - len(r.buf)
+	if len(p) > room {
+		r.buf = append(r.buf, p[:room]...)
+		r.truncated = true
+	} else {
+		r.buf = append(r.buf, p...)
+	}
+	return len(p), nil
+}
+
+func (r *bodyRecorder) String() string {
+	if r.truncated {
+		return string(r.buf) + "...(truncated)"
+	}
+	return string(r.buf)
+}
+
+// DecodeJSON decodes one JSON value from body into v without reading the
+// whole body into memory first. It returns the start of the body, capped
+// like CapBody, for use in Error.Body; when decoding fails the rest of the
+// body is read into the capture, so non-JSON error pages are reported whole.
+func DecodeJSON(body io.Reader, v interface{}) (string, error) {
+	rec := &bodyRecorder{}
+	err := json.NewDecoder(io.TeeReader(body, rec)).Decode(v)
+	if err != nil {
+		_, _ = io.Copy(rec, body)
+	}
+	return rec.String(), err
+}
+
+// NDJSONDecoder reads newline-delimited JSON, as streamed by Ollama, one
+// value at a time.
+type NDJSONDecoder struct {
+	dec *json.Decoder
+}
+
+// NewNDJSONDecoder returns a decoder reading from r.
+func NewNDJSONDecoder(r io.Reader) *NDJSONDecoder {
+	return &NDJSONDecoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next value into v. It returns io.EOF at the end of the
+// stream.
+func (d *NDJSONDecoder) Decode(v interface{}) error {
+	return d.dec.Decode(v)
+}
+
+// SSEEvent is one server-sent event.
+type SSEEvent struct {
+	// Event is the event type, empty for the default "message" type.
+	Event string
+	// Data is the event payload. Multiple data lines are joined with "\n".
+	Data string
+	// ID is the event ID, if the server sent one.
+	ID string
+}
+
+// SSEDecoder reads server-sent events, as streamed by OpenAI-compatible
+// APIs such as Groq, one event at a time. Lines may be of any length.
+type SSEDecoder struct {
+	r *bufio.Reader
+}
+
+// NewSSEDecoder returns a decoder reading from r.
+func NewSSEDecoder(r io.Reader) *SSEDecoder {
+	return &SSEDecoder{r: bufio.NewReader(r)}
+}
+
+// Next returns the next event that carries data. Comments and events
+// without data are skipped. It returns io.EOF at the end of the stream; an
+// event cut off by the end of the stream is still returned.
+func (d *SSEDecoder) Next() (SSEEvent, error) {
+	var event SSEEvent
+	var data []string
+	hasData := false
+	for {
+		line, err := d.r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return SSEEvent{}, err
+		}
+		eof := err != nil
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if hasData {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			if eof {
+				return SSEEvent{}, io.EOF
+			}
+			event = SSEEvent{}
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "":
+			// Comment line, e.g. a keep-alive
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "event":
+			event.Event = value
+		case "id":
+			event.ID = value
+		}
+
+		if eof {
+			if hasData {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			return SSEEvent{}, io.EOF
+		}
+	}
+}
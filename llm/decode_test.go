@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	var v struct{ Response string }
+	raw, err := DecodeJSON(strings.NewReader(`{"response": "hi"}`), &v)
+	if err != nil || v.Response != "hi" {
+		t.Fatalf("Expected decoded value, got %+v (%v)", v, err)
+	}
+	if raw != `{"response": "hi"}` {
+		t.Errorf("Expected raw body to be captured, got '%s'", raw)
+	}
+
+	raw, err = DecodeJSON(strings.NewReader("<html>Bad Gateway</html>"), &v)
+	if err == nil {
+		t.Fatal("Expected error for non-JSON body")
+	}
+	if raw != "<html>Bad Gateway</html>" {
+		t.Errorf("Expected whole error page to be captured, got '%s'", raw)
+	}
+
+	long := `{"response": "` + strings.Repeat("x", MaxErrorBodyBytes) + `"}`
+	raw, err = DecodeJSON(strings.NewReader(long), &v)
+	if err != nil || len(v.Response) != MaxErrorBodyBytes {
+		t.Fatalf("Expected long value to decode, got %d bytes (%v)", len(v.Response), err)
+	}
+	if raw != CapBody([]byte(long)) {
+		t.Error("Expected capture to be capped like CapBody")
+	}
+}
+
+func TestNDJSONDecoder(t *testing.T) {
+	dec := NewNDJSONDecoder(strings.NewReader("{\"response\":\"a\"}\n{\"response\":\"b\",\"done\":true}\n"))
+	var got []string
+	for {
+		var chunk struct {
+			Response string
+			Done     bool
+		}
+		err := dec.Decode(&chunk)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, chunk.Response)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Unexpected values: %v", got)
+	}
+}
+
+func TestSSEDecoder(t *testing.T) {
+	stream := ": keep-alive\n\n" +
+		"data: {\"a\":1}\n\n" +
+		"event: update\r\nid: 7\r\ndata: line1\r\ndata: line2\r\n\r\n" +
+		"data: " + strings.Repeat("x", 100000) + "\n\n" +
+		"data: [DONE]"
+
+	dec := NewSSEDecoder(strings.NewReader(stream))
+	var events []SSEEvent
+	for {
+		event, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %d", len(events))
+	}
+	if events[0].Data != `{"a":1}` {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[1] != (SSEEvent{Event: "update", ID: "7", Data: "line1\nline2"}) {
+		t.Errorf("Unexpected multi-line event: %+v", events[1])
+	}
+	if len(events[2].Data) != 100000 {
+		t.Errorf("Expected long line to be read whole, got %d bytes", len(events[2].Data))
+	}
+	if events[3].Data != "[DONE]" {
+		t.Errorf("Expected unterminated final event, got %+v", events[3])
+	}
+}
@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrDryRun is matched by the error a provider returns instead of sending
+// a request in dry-run mode.
+var ErrDryRun = errors.New("dry run")
+
+// redactedHeaders are the request headers that carry credentials.
+var redactedHeaders = []string{"Authorization", "X-Goog-Api-Key", "X-Api-Key", "Api-Key", "Cookie", "Proxy-Authorization"}
+
+// RenderedRequest is a provider request as it would have been sent.
+type RenderedRequest struct {
+	// Provider is the provider name, e.g. "groq".
+	Provider string
+	// Method and URL are the HTTP method and URL of the request.
+	Method string
+	URL    string
+	// Header holds the request headers, with credentials replaced by
+	// "REDACTED".
+	Header http.Header
+	// Body is the request body, usually JSON.
+	Body []byte
+}
+
+// String formats the request like an HTTP request, with JSON bodies
+// indented.
+func (r *RenderedRequest) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", r.Method, r.URL)
+	r.Header.Write(&b)
+	if len(r.Body) > 0 {
+		b.WriteString("\n")
+		var indented bytes.Buffer
+		if json.Indent(&indented, r.Body, "", "  ") == nil {
+			b.Write(indented.Bytes())
+		} else {
+			b.Write(r.Body)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// DryRunError carries the request a provider would have sent. It matches
+// ErrDryRun with errors.Is.
+type DryRunError struct {
+	Request *RenderedRequest
+}
+
+// Error implements error.
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("%s: dry run: %s %s not sent", e.Request.Provider, e.Request.Method, e.Request.URL)
+}
+
+// Is reports whether target is ErrDryRun.
+func (e *DryRunError) Is(target error) bool {
+	return target == ErrDryRun
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a copy of ctx in which providers render their
+// requests and return them in a *DryRunError instead of sending them.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx is in dry-run mode.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// InterceptDryRun returns a *DryRunError rendering req if its context is in
+// dry-run mode, and nil otherwise. Providers call it just before sending a
+// request. The body of req stays readable.
+func InterceptDryRun(provider string, req *http.Request) error {
+	if !IsDryRun(req.Context()) {
+		return nil
+	}
+	rendered := &RenderedRequest{
+		Provider: provider,
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Header:   req.Header.Clone(),
+	}
+	if rendered.Header == nil {
+		rendered.Header = http.Header{}
+	}
+	for _, h := range redactedHeaders {
+		if rendered.Header.Get(h) != "" {
+			rendered.Header.Set(h, "REDACTED")
+		}
+	}
+	if q := req.URL.Query(); q.Has("key") {
+		// Some Google endpoints take the API key as a query parameter
+		q.Set("key", "REDACTED")
+		u := *req.URL
+		u.RawQuery = q.Encode()
+		rendered.URL = u.String()
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		rendered.Body = body
+	}
+	return &DryRunError{Request: rendered}
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestInterceptDryRun(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com/v1/chat?key=secret&alt=json", strings.NewReader(`{"model":"m"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := InterceptDryRun("groq", req); err != nil {
+		t.Fatalf("Expected requests outside dry-run mode to pass, got %v", err)
+	}
+
+	req = req.WithContext(WithDryRun(context.Background()))
+	err := InterceptDryRun("groq", req)
+	var dryRun *DryRunError
+	if !errors.As(err, &dryRun) || !errors.Is(err, ErrDryRun) {
+		t.Fatalf("Expected a *DryRunError, got %v", err)
+	}
+	r := dryRun.Request
+	if r.Provider != "groq" || r.Method != http.MethodPost || string(r.Body) != `{"model":"m"}` {
+		t.Errorf("Unexpected rendered request: %+v", r)
+	}
+	if r.Header.Get("Authorization") != "REDACTED" || r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected only credentials to be redacted, got %v", r.Header)
+	}
+	if strings.Contains(r.URL, "secret") || !strings.Contains(r.URL, "alt=json") {
+		t.Errorf("Expected the key parameter to be redacted, got %s", r.URL)
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		t.Error("Expected the original request to be left alone")
+	}
+	if body, _ := io.ReadAll(req.Body); string(body) != `{"model":"m"}` {
+		t.Errorf("Expected the body to stay readable, got %q", body)
+	}
+}
+
+func TestRenderedRequest_String(t *testing.T) {
+	r := &RenderedRequest{Method: "POST", URL: "https://example.com", Header: http.Header{"Accept": {"application/json"}}, Body: []byte(`{"a":1}`)}
+	want := "POST https://example.com\nAccept: application/json\r\n\n{\n  \"a\": 1\n}\n"
+	if got := r.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
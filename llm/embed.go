@@ -0,0 +1,19 @@
+package llm
+
+import "context"
+
+// Embedder is implemented by clients that compute text embeddings. The
+// Ollama and Gemini clients implement it; Groq has no embeddings API.
+type Embedder interface {
+	// Embed returns one embedding vector per text, in the same order,
+	// computed by the client's embedding model.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// WithEmbeddingModel sets the model that computes embeddings, which is
+// usually not the model that generates text.
+func WithEmbeddingModel(model string) ClientOption {
+	return func(o *ClientOptions) {
+		o.EmbeddingModel = model
+	}
+}
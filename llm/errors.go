@@ -0,0 +1,267 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors wrapped by provider clients so callers can classify
+// failures with errors.Is instead of matching on error strings.
+var (
+	// ErrRateLimited means the provider rejected the request because a rate
+	// limit or quota was exceeded.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrAuthentication means the credentials were missing, invalid or lack
+	// permission for the request.
+	ErrAuthentication = errors.New("authentication failed")
+
+	// ErrContentFiltered means the prompt or response was blocked by the
+	// provider's safety or moderation filters.
+	ErrContentFiltered = errors.New("content filtered")
+
+	// ErrModelNotFound means the requested model does not exist or is not
+	// available to the caller.
+	ErrModelNotFound = errors.New("model not found")
+
+	// ErrContextTooLong means the prompt (plus requested output) does not
+	// fit in the model's context window.
+	ErrContextTooLong = errors.New("context too long")
+
+	// ErrTimeout means the request did not complete in time.
+	ErrTimeout = errors.New("request timed out")
+
+	// ErrUnavailable means the provider could not be reached or failed with
+	// a server error, and the request may succeed later.
+	ErrUnavailable = errors.New("provider unavailable")
+)
+
+// sentinelError attaches a sentinel to an error without changing its message.
+type sentinelError struct {
+	sentinel error
+	err      error
+}
+
+func (e *sentinelError) Error() string { return e.err.Error() }
+
+func (e *sentinelError) Unwrap() []error { return []error{e.sentinel, e.err} }
+
+// WrapError returns err marked with sentinel, so that errors.Is(result,
+// sentinel) holds while the message stays that of err. A nil sentinel
+// returns err unchanged.
+func WrapError(sentinel, err error) error {
+	if sentinel == nil || err == nil {
+		return err
+	}
+	return &sentinelError{sentinel: sentinel, err: err}
+}
+
+// StatusError maps an HTTP status code returned by a provider API to the
+// matching sentinel, or nil if the status has no specific meaning.
+func StatusError(status int) error {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuthentication
+	case http.StatusNotFound:
+		return ErrModelNotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrTimeout
+	case http.StatusRequestEntityTooLarge:
+		return ErrContextTooLong
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// contextLengthPhrases appear in provider messages about oversized prompts.
+var contextLengthPhrases = []string{
+	"context length",
+	"context_length",
+	"context window",
+	"maximum context",
+	"too many tokens",
+	"prompt is too long",
+	"input is too long",
+	"exceeds the maximum number of tokens",
+}
+
+// IsContextLengthMessage reports whether a provider error message describes
+// a prompt that does not fit in the model's context window.
+func IsContextLengthMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, phrase := range contextLengthPhrases {
+		if strings.Contains(message, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// TransportError classifies an error returned while sending a request:
+// ErrTimeout for deadlines and network timeouts, ErrUnavailable for other
+// network failures such as refused connections, and nil otherwise.
+func TransportError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// IsRetryable reports whether sending the same request again later may
+// succeed: rate limits, timeouts and temporary provider failures. Errors
+// caused by the caller cancelling the context are never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrUnavailable)
+}
+
+// IsFatal reports whether the request can never succeed as sent, so that
+// retrying it is pointless: bad credentials, a missing model, filtered
+// content, an oversized prompt, or a cancelled context.
+func IsFatal(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, ErrAuthentication) ||
+		errors.Is(err, ErrModelNotFound) ||
+		errors.Is(err, ErrContentFiltered) ||
+		errors.Is(err, ErrContextTooLong) ||
+		errors.Is(err, context.Canceled)
+}
+
+// MaxErrorBodyBytes caps the raw response body kept in Error.Body.
+const MaxErrorBodyBytes = 4096
+
+// OpGenerate is the Error.Op of failed text generation calls.
+const OpGenerate = "generate"
+
+// Error describes a failed provider call. Every provider returns it for
+// network, decode and API failures alike, so the same fields are always
+// available: the operation and endpoint, plus what the provider reported
+// (HTTP status, error code and type, request ID and the raw response body).
+// It wraps a sentinel such as ErrRateLimited and the underlying cause, so
+// callers can use errors.Is for classification and errors.As for the details:
+//
+//	var apiErr *xollm.Error
+//	if errors.As(err, &apiErr) {
+//		log.Printf("%s request %s failed: %d", apiErr.Provider, apiErr.RequestID, apiErr.StatusCode)
+//	}
+type Error struct {
+	// Provider is the provider name, e.g. "groq".
+	Provider string
+	// Op is the operation that failed, e.g. OpGenerate.
+	Op string
+	// Endpoint is the URL or resource the request was sent to.
+	Endpoint string
+	// StatusCode is the HTTP status of the response, or 0 if unknown.
+	StatusCode int
+	// Code is the provider's error code, e.g. "rate_limit_exceeded".
+	Code string
+	// Type is the provider's error type, e.g. "invalid_request_error".
+	Type string
+	// RequestID identifies the request in the provider's logs, if reported.
+	RequestID string
+	// Message is the provider's human-readable error message.
+	Message string
+	// Body is the raw response body, capped at MaxErrorBodyBytes.
+	Body string
+	// Kind is the sentinel classifying the error, or nil if unclassified.
+	Kind error
+	// Err is the underlying cause, if any, e.g. the SDK's own error type.
+	Err error
+}
+
+// Error formats the failure on one line as
+//
+//	<provider>: <op> <endpoint>: status <code>: <message> [<details>]
+//
+// leaving out the parts that are unknown. The message falls back to the
+// cause and then the body when the provider gave none.
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Provider)
+	if e.Op != "" || e.Endpoint != "" {
+		b.WriteString(": ")
+		b.WriteString(strings.TrimSpace(e.Op + " " + e.Endpoint))
+	}
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, ": status %d", e.StatusCode)
+	}
+	switch {
+	case e.Message != "":
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	case e.Err != nil:
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	case e.Body != "":
+		b.WriteString(": ")
+		b.WriteString(e.Body)
+	}
+	var details []string
+	if e.Type != "" {
+		details = append(details, "type: "+e.Type)
+	}
+	if e.Code != "" {
+		details = append(details, "code: "+e.Code)
+	}
+	if e.RequestID != "" {
+		details = append(details, "request ID: "+e.RequestID)
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " [%s]", strings.Join(details, ", "))
+	}
+	return b.String()
+}
+
+// Unwrap returns the sentinel and the underlying cause.
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// NewError describes a failure that happened before the provider answered,
+// or while reading its answer, such as a refused connection or a response
+// that can't be decoded. The sentinel is derived from err with
+// TransportError.
+func NewError(provider, op, endpoint string, err error) *Error {
+	return &Error{
+		Provider: provider,
+		Op:       op,
+		Endpoint: endpoint,
+		Kind:     TransportError(err),
+		Err:      err,
+	}
+}
+
+// CapBody converts a raw response body for Error.Body, truncating it to
+// MaxErrorBodyBytes.
+func CapBody(body []byte) string {
+	if len(body) > MaxErrorBodyBytes {
+		return string(body[:MaxErrorBodyBytes]) + "...(truncated)"
+	}
+	return string(body)
+}
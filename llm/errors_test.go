@@ -0,0 +1,186 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestWrapError(t *testing.T) {
+	base := errors.New("groq API request failed with status 429")
+	err := WrapError(ErrRateLimited, base)
+
+	if !errors.Is(err, ErrRateLimited) {
+		t.Error("Expected wrapped error to match sentinel")
+	}
+	if !errors.Is(err, base) {
+		t.Error("Expected wrapped error to match original error")
+	}
+	if err.Error() != base.Error() {
+		t.Errorf("Expected message to be preserved, got '%s'", err.Error())
+	}
+
+	if WrapError(nil, base) != base {
+		t.Error("Expected nil sentinel to return error unchanged")
+	}
+	if WrapError(ErrTimeout, nil) != nil {
+		t.Error("Expected nil error to stay nil")
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := map[int]error{
+		http.StatusUnauthorized:          ErrAuthentication,
+		http.StatusForbidden:             ErrAuthentication,
+		http.StatusNotFound:              ErrModelNotFound,
+		http.StatusRequestTimeout:        ErrTimeout,
+		http.StatusGatewayTimeout:        ErrTimeout,
+		http.StatusRequestEntityTooLarge: ErrContextTooLong,
+		http.StatusTooManyRequests:       ErrRateLimited,
+		http.StatusInternalServerError:   ErrUnavailable,
+		http.StatusServiceUnavailable:    ErrUnavailable,
+		http.StatusNotImplemented:        nil,
+		http.StatusBadRequest:            nil,
+	}
+	for status, expected := range tests {
+		if got := StatusError(status); got != expected {
+			t.Errorf("Status %d: expected %v, got %v", status, expected, got)
+		}
+	}
+}
+
+func TestIsContextLengthMessage(t *testing.T) {
+	positives := []string{
+		"This model's maximum context length is 8192 tokens",
+		"Please reduce the length of the messages or completion. context_length_exceeded",
+		"The input token count exceeds the maximum number of tokens allowed",
+	}
+	for _, msg := range positives {
+		if !IsContextLengthMessage(msg) {
+			t.Errorf("Expected '%s' to be recognised", msg)
+		}
+	}
+	if IsContextLengthMessage("invalid api key") {
+		t.Error("Expected unrelated message not to match")
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	if TransportError(fmt.Errorf("send: %w", context.DeadlineExceeded)) != ErrTimeout {
+		t.Error("Expected deadline to map to ErrTimeout")
+	}
+	if TransportError(context.Canceled) != nil {
+		t.Error("Expected cancellation not to be a timeout")
+	}
+	if TransportError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}) != ErrUnavailable {
+		t.Error("Expected network failures to map to ErrUnavailable")
+	}
+	if TransportError(errors.New("tls: bad certificate")) != nil {
+		t.Error("Expected other errors to map to nil")
+	}
+}
+
+func TestIsRetryableAndIsFatal(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		fatal     bool
+	}{
+		{name: "nil"},
+		{name: "rate limited", err: WrapError(ErrRateLimited, errors.New("429")), retryable: true},
+		{name: "timeout", err: fmt.Errorf("call: %w", ErrTimeout), retryable: true},
+		{name: "unavailable", err: ErrUnavailable, retryable: true},
+		{name: "authentication", err: ErrAuthentication, fatal: true},
+		{name: "model not found", err: ErrModelNotFound, fatal: true},
+		{name: "content filtered", err: ErrContentFiltered, fatal: true},
+		{name: "context too long", err: ErrContextTooLong, fatal: true},
+		{name: "canceled", err: fmt.Errorf("send: %w", context.Canceled), fatal: true},
+		{name: "canceled while rate limited", err: WrapError(ErrRateLimited, context.Canceled), fatal: true},
+		{name: "unclassified", err: errors.New("boom")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable: expected %v, got %v", tt.retryable, got)
+			}
+			if got := IsFatal(tt.err); got != tt.fatal {
+				t.Errorf("IsFatal: expected %v, got %v", tt.fatal, got)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New("sdk failure")
+	err := &Error{
+		Provider:   "groq",
+		Op:         OpGenerate,
+		Endpoint:   "https://api.groq.com/openai/v1/chat/completions",
+		StatusCode: http.StatusTooManyRequests,
+		Code:       "rate_limit_exceeded",
+		Type:       "tokens",
+		RequestID:  "req_123",
+		Message:    "Rate limit reached",
+		Kind:       ErrRateLimited,
+		Err:        cause,
+	}
+
+	expected := "groq: generate https://api.groq.com/openai/v1/chat/completions: status 429: Rate limit reached [type: tokens, code: rate_limit_exceeded, request ID: req_123]"
+	if err.Error() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, err.Error())
+	}
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, cause) {
+		t.Error("Expected error to wrap both sentinel and cause")
+	}
+
+	var apiErr *Error
+	if !errors.As(fmt.Errorf("generate: %w", err), &apiErr) || apiErr.RequestID != "req_123" {
+		t.Error("Expected errors.As to recover the details")
+	}
+}
+
+func TestError_MessageFallbacks(t *testing.T) {
+	withBody := &Error{Provider: "ollama", StatusCode: 502, Body: "<html>Bad Gateway</html>"}
+	if withBody.Error() != "ollama: status 502: <html>Bad Gateway</html>" {
+		t.Errorf("Expected body fallback, got '%s'", withBody.Error())
+	}
+
+	withCause := &Error{Provider: "gemini", Body: "ignored", Err: errors.New("cause")}
+	if withCause.Error() != "gemini: cause" {
+		t.Errorf("Expected cause fallback, got '%s'", withCause.Error())
+	}
+
+	if len((&Error{}).Unwrap()) != 0 {
+		t.Error("Expected nothing to unwrap from an empty error")
+	}
+}
+
+func TestNewError(t *testing.T) {
+	cause := fmt.Errorf("failed to send request: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")})
+	err := NewError("ollama", OpGenerate, "http://localhost:11434/api/generate", cause)
+
+	expected := "ollama: generate http://localhost:11434/api/generate: failed to send request: dial: connection refused"
+	if err.Error() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, err.Error())
+	}
+	if !errors.Is(err, ErrUnavailable) || !errors.Is(err, cause) {
+		t.Error("Expected error to wrap the transport sentinel and cause")
+	}
+	if NewError("groq", OpGenerate, "", errors.New("decode")).Kind != nil {
+		t.Error("Expected non-transport failures to stay unclassified")
+	}
+}
+
+func TestCapBody(t *testing.T) {
+	if CapBody([]byte("short")) != "short" {
+		t.Error("Expected short body unchanged")
+	}
+	long := make([]byte, MaxErrorBodyBytes+100)
+	if got := CapBody(long); len(got) != MaxErrorBodyBytes+len("...(truncated)") {
+		t.Errorf("Expected body capped at %d bytes, got %d", MaxErrorBodyBytes, len(got))
+	}
+}
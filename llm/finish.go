@@ -0,0 +1,58 @@
+package llm
+
+import "strings"
+
+// FinishKind is a provider-neutral reason for the end of a generation, so
+// callers can tell a complete answer from a truncated or filtered one
+// without knowing each provider's names.
+type FinishKind string
+
+const (
+	// FinishUnknown means the provider reported no finish reason.
+	FinishUnknown FinishKind = ""
+	// FinishStop means the model ended the answer or produced a stop
+	// sequence.
+	FinishStop FinishKind = "stop"
+	// FinishLength means the output token limit cut the answer off, see
+	// xollm.GenerateContinued.
+	FinishLength FinishKind = "length"
+	// FinishContentFilter means a safety or recitation filter withheld or
+	// cut the answer.
+	FinishContentFilter FinishKind = "content_filter"
+	// FinishToolCalls means the model stopped to call tools.
+	FinishToolCalls FinishKind = "tool_calls"
+	// FinishOther covers the remaining reasons, e.g. a malformed function
+	// call.
+	FinishOther FinishKind = "other"
+)
+
+// NormalizeFinishReason maps a provider's finish reason to a FinishKind:
+// Groq's OpenAI-style names ("stop", "length", "content_filter",
+// "tool_calls"), Gemini's ("Stop", "MaxTokens", "Safety", "Recitation",
+// ...) and Ollama's done reasons ("stop", "length").
+func NormalizeFinishReason(reason string) FinishKind {
+	switch strings.ToLower(strings.ReplaceAll(reason, "_", "")) {
+	case "":
+		return FinishUnknown
+	case "stop", "endturn", "stopsequence", "eos":
+		return FinishStop
+	case "length", "maxtokens":
+		return FinishLength
+	case "contentfilter", "safety", "recitation", "blocklist", "prohibitedcontent", "spii":
+		return FinishContentFilter
+	case "toolcalls", "functioncall", "tooluse":
+		return FinishToolCalls
+	default:
+		return FinishOther
+	}
+}
+
+// Finish returns md's finish reason as a FinishKind.
+func (md *ResponseMetadata) Finish() FinishKind {
+	return NormalizeFinishReason(md.FinishReason)
+}
+
+// Finish returns r's finish reason as a FinishKind.
+func (r *ToolResponse) Finish() FinishKind {
+	return NormalizeFinishReason(r.FinishReason)
+}
@@ -0,0 +1,35 @@
+package llm
+
+import "testing"
+
+func TestNormalizeFinishReason(t *testing.T) {
+	tests := map[string]FinishKind{
+		"":                      FinishUnknown,
+		"stop":                  FinishStop,
+		"Stop":                  FinishStop,
+		"STOP":                  FinishStop,
+		"length":                FinishLength,
+		"MaxTokens":             FinishLength,
+		"MAX_TOKENS":            FinishLength,
+		"content_filter":        FinishContentFilter,
+		"Safety":                FinishContentFilter,
+		"Recitation":            FinishContentFilter,
+		"tool_calls":            FinishToolCalls,
+		"Other":                 FinishOther,
+		"MalformedFunctionCall": FinishOther,
+	}
+	for reason, want := range tests {
+		if got := NormalizeFinishReason(reason); got != want {
+			t.Errorf("NormalizeFinishReason(%q) = %q, want %q", reason, got, want)
+		}
+	}
+}
+
+func TestFinish(t *testing.T) {
+	if got := (&ResponseMetadata{FinishReason: "MaxTokens"}).Finish(); got != FinishLength {
+		t.Errorf("Expected FinishLength for metadata, got %q", got)
+	}
+	if got := (&ToolResponse{FinishReason: "tool_calls"}).Finish(); got != FinishToolCalls {
+		t.Errorf("Expected FinishToolCalls for a tool response, got %q", got)
+	}
+}
@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// RequestHook modifies each outgoing request of a client just before it is
+// sent, after the client has set its own headers, for authentication
+// schemes that header injection doesn't cover: request signing such as AWS
+// SigV4 in front of a proxy, HMAC gateways, or OAuth tokens refreshed on
+// demand. The request is a copy the hook may change freely; to sign the
+// body, read it with req.GetBody, which leaves req.Body unread. An error
+// fails the request without sending it.
+//
+// Hooks run before request compression (TransportOptions.CompressRequestsOver),
+// so schemes that sign the body must not be combined with it. Hooks run
+// concurrently for concurrent requests.
+type RequestHook func(req *http.Request) error
+
+// WithRequestHook adds a hook run on every request of the client. Hooks
+// run in the order they were added.
+func WithRequestHook(hook RequestHook) ClientOption {
+	return func(o *ClientOptions) {
+		if hook != nil {
+			o.RequestHooks = append(o.RequestHooks, hook)
+		}
+	}
+}
+
+// hookTransport runs request hooks before sending requests through base.
+type hookTransport struct {
+	hooks []RequestHook
+	base  http.RoundTripper
+}
+
+// RoundTrip runs the hooks on a copy of req and sends it.
+func (t *hookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for _, hook := range t.hooks {
+		if err := hook(req); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, fmt.Errorf("request hook failed: %w", err)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
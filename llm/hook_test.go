@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientOptions_Transport(t *testing.T) {
+	base := http.DefaultTransport
+	if (ClientOptions{}).Transport(base) != base {
+		t.Error("Expected the base transport without hooks")
+	}
+
+	var gotSignature, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get("X-Signature")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+	}))
+	defer server.Close()
+
+	sign := func(req *http.Request) error {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		data, _ := io.ReadAll(body)
+		req.Header.Set("X-Signature", req.Method+" "+string(data))
+		return nil
+	}
+	stamp := func(req *http.Request) error {
+		req.Header.Set("X-Signature", req.Header.Get("X-Signature")+" signed")
+		return nil
+	}
+	client := &http.Client{Transport: ApplyOptions([]ClientOption{WithRequestHook(sign), WithRequestHook(stamp), WithRequestHook(nil)}).Transport(base)}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if gotSignature != "POST payload signed" || gotBody != "payload" {
+		t.Errorf("Expected the hooks to run in order and leave the body intact, got %q, %q", gotSignature, gotBody)
+	}
+	if req.Header.Get("X-Signature") != "" {
+		t.Error("Expected the caller's request not to be modified")
+	}
+}
+
+func TestClientOptions_Transport_HookError(t *testing.T) {
+	errNoToken := errors.New("no token")
+	transport := ApplyOptions([]ClientOption{WithRequestHook(func(*http.Request) error { return errNoToken })}).Transport(http.DefaultTransport)
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:1", nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, errNoToken) {
+		t.Errorf("Expected the hook's error, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// maxImageSize is the largest image LoadImage accepts, above the limits of
+// the providers' inline image parts.
+const maxImageSize = 20 << 20
+
+// Image is an image attached to a prompt or chat message, for vision
+// models. It holds either the image's bytes or a URL the provider or
+// client fetches it from.
+type Image struct {
+	// MIMEType is the image's media type, e.g. "image/png". It is detected
+	// from Data if empty.
+	MIMEType string
+	// Data is the encoded image.
+	Data []byte
+	// URL locates the image when Data is empty: an http(s) URL or a data:
+	// URL with base64 content.
+	URL string
+}
+
+// ImageFromBytes returns an image of data, with its MIME type detected
+// from the content.
+func ImageFromBytes(data []byte) Image {
+	return Image{MIMEType: http.DetectContentType(data), Data: data}
+}
+
+// ImageFromURL returns an image the provider fetches from url.
+func ImageFromURL(url string) Image {
+	return Image{URL: url}
+}
+
+// ImageFromFile reads an image from the file at path.
+func ImageFromFile(path string) (Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	return ImageFromBytes(data), nil
+}
+
+// mimeType returns the image's MIME type, detecting it if unset.
+func (img Image) mimeType() string {
+	if img.MIMEType != "" {
+		return img.MIMEType
+	}
+	return http.DetectContentType(img.Data)
+}
+
+// DataURL returns the image as a data: URL, or its URL if it has no data.
+func (img Image) DataURL() string {
+	if len(img.Data) == 0 {
+		return img.URL
+	}
+	return "data:" + img.mimeType() + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+}
+
+// imageClient fetches images for providers that only accept image bytes.
+var imageClient = &http.Client{Transport: SharedTransport()}
+
+// LoadImage returns img with its Data and MIMEType filled, decoding a
+// data: URL or downloading an http(s) URL, for providers that only accept
+// image bytes. Images with data are returned as they are.
+func LoadImage(ctx context.Context, img Image) (Image, error) {
+	if len(img.Data) > 0 {
+		img.MIMEType = img.mimeType()
+		return img, nil
+	}
+	if rest, ok := strings.CutPrefix(img.URL, "data:"); ok {
+		meta, encoded, ok := strings.Cut(rest, ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return Image{}, fmt.Errorf("image data URL must be base64-encoded")
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return Image{}, fmt.Errorf("invalid image data URL: %w", err)
+		}
+		return Image{MIMEType: strings.TrimSuffix(meta, ";base64"), Data: data}, nil
+	}
+	if img.URL == "" {
+		return Image{}, fmt.Errorf("image has neither data nor a URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, img.URL, nil)
+	if err != nil {
+		return Image{}, fmt.Errorf("invalid image URL: %w", err)
+	}
+	resp, err := imageClient.Do(req)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Image{}, fmt.Errorf("failed to fetch image %s: %s", img.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return Image{}, fmt.Errorf("image %s is larger than %d MB", img.URL, maxImageSize>>20)
+	}
+	loaded := Image{MIMEType: img.MIMEType, Data: data}
+	if ct := resp.Header.Get("Content-Type"); loaded.MIMEType == "" && strings.HasPrefix(ct, "image/") {
+		loaded.MIMEType = ct
+	}
+	loaded.MIMEType = loaded.mimeType()
+	return loaded, nil
+}
+
+// LoadImages returns messages with their images loaded by LoadImage. The
+// messages are copied if any has images, so the caller's are left as
+// they are.
+func LoadImages(ctx context.Context, messages []Message) ([]Message, error) {
+	loaded, copied := messages, false
+	for i, m := range messages {
+		if len(m.Images) == 0 {
+			continue
+		}
+		images := make([]Image, len(m.Images))
+		for j, img := range m.Images {
+			full, err := LoadImage(ctx, img)
+			if err != nil {
+				return nil, fmt.Errorf("image %d of message %d: %w", j+1, i+1, err)
+			}
+			images[j] = full
+		}
+		if !copied {
+			loaded, copied = append([]Message(nil), messages...), true
+		}
+		loaded[i].Images = images
+	}
+	return loaded, nil
+}
+
+// VisionGenerator is implemented by clients that send images with the
+// prompt to vision models. All bundled providers implement it.
+type VisionGenerator interface {
+	// GenerateWithImages sends prompt with images attached, in order.
+	GenerateWithImages(ctx context.Context, prompt string, images []Image) (string, error)
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// pngHeader is the signature of a PNG file, enough for type detection.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestImageFromBytes(t *testing.T) {
+	img := ImageFromBytes(pngHeader)
+	if img.MIMEType != "image/png" {
+		t.Errorf("Expected the PNG type detected, got %q", img.MIMEType)
+	}
+	if url := img.DataURL(); url != "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg==" {
+		t.Errorf("Unexpected data URL %q", url)
+	}
+	if url := ImageFromURL("https://example.com/cat.png").DataURL(); url != "https://example.com/cat.png" {
+		t.Errorf("Expected the URL of an image without data, got %q", url)
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cat.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(pngHeader)
+	}))
+	defer server.Close()
+
+	img, err := LoadImage(context.Background(), ImageFromURL(server.URL+"/cat.png"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if img.MIMEType != "image/png" || !bytes.Equal(img.Data, pngHeader) {
+		t.Errorf("Unexpected image %q with %d bytes", img.MIMEType, len(img.Data))
+	}
+
+	if _, err := LoadImage(context.Background(), ImageFromURL(server.URL+"/dog.png")); err == nil {
+		t.Error("Expected an error for a missing image")
+	}
+
+	img, err = LoadImage(context.Background(), ImageFromURL("data:image/jpeg;base64,/9j/4A=="))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if img.MIMEType != "image/jpeg" || len(img.Data) != 4 {
+		t.Errorf("Unexpected image %q with %d bytes from a data URL", img.MIMEType, len(img.Data))
+	}
+}
+
+func TestLoadImages(t *testing.T) {
+	messages := []Message{
+		{Role: RoleUser, Content: "What is this?", Images: []Image{ImageFromURL("data:image/png;base64,iVBORw0KGgo=")}},
+	}
+	loaded, err := LoadImages(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(loaded[0].Images[0].Data) != 8 {
+		t.Errorf("Expected the image loaded, got %+v", loaded[0].Images[0])
+	}
+	if len(messages[0].Images[0].Data) != 0 {
+		t.Error("Expected the caller's messages to be left unchanged")
+	}
+
+	plain := []Message{{Role: RoleUser, Content: "Hi"}}
+	if loaded, _ := LoadImages(context.Background(), plain); &loaded[0] != &plain[0] {
+		t.Error("Expected messages without images not to be copied")
+	}
+}
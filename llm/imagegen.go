@@ -0,0 +1,31 @@
+package llm
+
+import "context"
+
+// ImageOptions tune image generation. The zero value asks for one image in
+// the provider's default aspect ratio.
+type ImageOptions struct {
+	// Count is the number of images to generate, 1 if zero. Providers
+	// limit it, e.g. to 4 for Imagen.
+	Count int
+	// AspectRatio is the images' width to height, e.g. "1:1", "16:9" or
+	// "9:16". The provider's default is used if empty.
+	AspectRatio string
+}
+
+// ImageGenerator is implemented by clients that generate images from a
+// text prompt. The Gemini client implements it with Google's Imagen
+// models.
+type ImageGenerator interface {
+	// GenerateImages returns the images generated for prompt by the
+	// client's image model, each with its Data and MIMEType.
+	GenerateImages(ctx context.Context, prompt string, opts ImageOptions) ([]Image, error)
+}
+
+// WithImageModel sets the model that generates images, which is not the
+// model that generates text.
+func WithImageModel(model string) ClientOption {
+	return func(o *ClientOptions) {
+		o.ImageModel = model
+	}
+}
@@ -0,0 +1,9 @@
+// Package llm defines the provider-neutral types shared by xollm and its
+// provider packages.
+//
+// Provider packages (gemini, groq, ollama) cannot import the root xollm
+// package because the factory there imports them. Types that appear in
+// provider method signatures therefore live in this package and are
+// re-exported by xollm as aliases, so applications normally never need to
+// import llm directly.
+package llm
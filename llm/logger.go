@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+// discardHandler drops every record, so clients are silent by default.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// defaultLogger is the logger of clients without their own.
+var defaultLogger atomic.Pointer[slog.Logger]
+
+// discardLogger is the initial default logger.
+var discardLogger = slog.New(discardHandler{})
+
+// SetDefaultLogger sets the logger of clients created without WithLogger
+// or debugMode, including clients created before the call, so verbosity
+// can change at runtime. Nil restores the default, which discards
+// everything.
+func SetDefaultLogger(l *slog.Logger) {
+	defaultLogger.Store(l)
+}
+
+// DefaultLogger returns the logger set with SetDefaultLogger, or a logger
+// that discards everything.
+func DefaultLogger() *slog.Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	return discardLogger
+}
+
+// DebugLogger returns a logger writing debug and higher records to
+// standard error. It is what the deprecated NewClient debugMode of true
+// selects; pass it to WithLogger instead.
+func DebugLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// WithLogger makes the client log to l instead of the default logger.
+// Control its verbosity with the handler's level, e.g. a slog.LevelVar.
+func WithLogger(l *slog.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.Logger = l
+	}
+}
+
+// ClientLogger returns the logger a client created with these options and
+// debugMode logs to: Logger if set, else DebugLogger if debugMode, else nil
+// for the default logger at the time of each call. debugMode is the
+// deprecated argument of the NewClient functions, kept for compatibility.
+func (o ClientOptions) ClientLogger(debugMode bool) *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	if debugMode {
+		return DebugLogger()
+	}
+	return nil
+}
+
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx whose requests log to l instead
+// of the client's logger, e.g. to debug a single request or to tag its
+// records with a request ID.
+func ContextWithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// Logger returns the logger for a request: the one in ctx, else
+// clientLogger, else the default logger.
+func Logger(ctx context.Context, clientLogger *slog.Logger) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && l != nil {
+			return l
+		}
+	}
+	if clientLogger != nil {
+		return clientLogger
+	}
+	return DefaultLogger()
+}
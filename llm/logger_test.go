@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Resolution(t *testing.T) {
+	defer SetDefaultLogger(nil)
+
+	if DefaultLogger().Enabled(context.Background(), slog.LevelError) {
+		t.Error("Expected the initial default logger to discard everything")
+	}
+
+	var defaultOut, clientOut, ctxOut bytes.Buffer
+	SetDefaultLogger(slog.New(slog.NewTextHandler(&defaultOut, nil)))
+	clientLogger := slog.New(slog.NewTextHandler(&clientOut, nil))
+	ctxLogger := slog.New(slog.NewTextHandler(&ctxOut, nil))
+
+	Logger(context.Background(), nil).Info("to default")
+	Logger(context.Background(), clientLogger).Info("to client")
+	Logger(ContextWithLogger(context.Background(), ctxLogger), clientLogger).Info("to context")
+
+	for out, want := range map[*bytes.Buffer]string{&defaultOut: "to default", &clientOut: "to client", &ctxOut: "to context"} {
+		if !strings.Contains(out.String(), want) || strings.Count(out.String(), "msg=") != 1 {
+			t.Errorf("Expected exactly %q, got %q", want, out.String())
+		}
+	}
+}
+
+func TestClientOptions_ClientLogger(t *testing.T) {
+	custom := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if l := ApplyOptions([]ClientOption{WithLogger(custom)}).ClientLogger(true); l != custom {
+		t.Error("Expected WithLogger to take precedence over debugMode")
+	}
+	if l := (ClientOptions{}).ClientLogger(true); l == nil || !l.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Expected debugMode to select a debug-level logger")
+	}
+	if l := (ClientOptions{}).ClientLogger(false); l != nil {
+		t.Error("Expected no client logger without WithLogger or debugMode")
+	}
+}
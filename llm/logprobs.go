@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"math"
+)
+
+// TokenLogprob is a generated token and the natural log of the
+// probability the model gave it.
+type TokenLogprob struct {
+	Token   string
+	Logprob float64
+	// TopLogprobs are the most likely tokens at this position, the
+	// generated one included, most likely first. They are only reported if
+	// requested, and have no TopLogprobs of their own.
+	TopLogprobs []TokenLogprob
+}
+
+// Probability returns the probability of the token, between 0 and 1.
+func (t TokenLogprob) Probability() float64 {
+	return math.Exp(t.Logprob)
+}
+
+// MeanLogprob returns the average log probability of tokens, a simple
+// confidence score for a response that doesn't depend on its length. It
+// returns 0 for no tokens.
+func MeanLogprob(tokens []TokenLogprob) float64 {
+	if len(tokens) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, t := range tokens {
+		sum += t.Logprob
+	}
+	return sum / float64(len(tokens))
+}
+
+// LogprobGenerator is implemented by clients whose provider reports the
+// log probabilities of the generated tokens, such as Groq.
+type LogprobGenerator interface {
+	// GenerateWithLogprobs is GenerateWithMetadata, also returning the log
+	// probability of each generated token in the metadata's Logprobs,
+	// along with the topLogprobs most likely alternatives at each position
+	// if it is more than 0.
+	GenerateWithLogprobs(ctx context.Context, prompt string, topLogprobs int) (string, *ResponseMetadata, error)
+}
@@ -0,0 +1,19 @@
+package llm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeanLogprob(t *testing.T) {
+	tokens := []TokenLogprob{{Token: "a", Logprob: -0.5}, {Token: "b", Logprob: -1.5}}
+	if got := MeanLogprob(tokens); got != -1 {
+		t.Errorf("Expected -1, got %v", got)
+	}
+	if got := MeanLogprob(nil); got != 0 {
+		t.Errorf("Expected 0 for no tokens, got %v", got)
+	}
+	if got := (TokenLogprob{Logprob: math.Log(0.25)}).Probability(); math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("Expected probability 0.25, got %v", got)
+	}
+}
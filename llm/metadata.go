@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"time"
+)
+
+// Timing breaks down the time a provider spent on a request, as reported
+// by the provider. Comparing Total with the latency measured by the
+// caller separates provider processing from network overhead.
+type Timing struct {
+	// Queue is the time the request waited before processing started.
+	Queue time.Duration
+	// Prompt is the time spent processing the prompt.
+	Prompt time.Duration
+	// Completion is the time spent generating the response.
+	Completion time.Duration
+	// Total is the provider's total processing time, excluding the queue.
+	Total time.Duration
+}
+
+// ResponseMetadata describes a completed generation.
+type ResponseMetadata struct {
+	// Model is the model that generated the response, as reported by the
+	// provider.
+	Model string
+	// RequestID is the provider's ID for the request, for support tickets.
+	RequestID string
+	// FinishReason is the provider's reason for ending the generation,
+	// e.g. "stop" or "length".
+	FinishReason string
+	// Usage is the token usage of the request.
+	Usage Usage
+	// EstimatedCostUSD is the price of Usage in US dollars, from the
+	// pricing of the model (see LookupPricing). It is 0 for free models
+	// and for models whose pricing is unknown; use EstimateCost to tell
+	// them apart.
+	EstimatedCostUSD float64
+	// Timing is the provider's timing breakdown, nil if it reports none.
+	Timing *Timing
+	// Route is the name of the route a router client chose for the
+	// request, empty for other clients.
+	Route string
+	// Continuations is the number of follow-up requests that continued a
+	// response truncated by the token limit, see xollm.GenerateContinued.
+	// Usage and EstimatedCostUSD then cover every request.
+	Continuations int
+	// Logprobs are the log probabilities of the generated tokens, in
+	// order. They are only reported when requested, see LogprobGenerator.
+	Logprobs []TokenLogprob
+}
+
+// WithEstimatedCost sets md's EstimatedCostUSD from its Usage and the
+// pricing of its Model on provider, and returns md.
+func (md *ResponseMetadata) WithEstimatedCost(provider string) *ResponseMetadata {
+	md.EstimatedCostUSD, _ = EstimateCost(provider, md.Model, md.Usage)
+	return md
+}
+
+// MetadataGenerator is implemented by clients that report the metadata of
+// their generations.
+type MetadataGenerator interface {
+	// GenerateWithMetadata is Generate, also returning the response's
+	// metadata.
+	GenerateWithMetadata(ctx context.Context, prompt string) (string, *ResponseMetadata, error)
+}
+
+// Seconds converts a duration in fractional seconds, as reported in JSON
+// by several providers, to a time.Duration.
+func Seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
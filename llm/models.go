@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ModelInfo describes the limits of a model.
+type ModelInfo struct {
+	// Name is the provider-specific model identifier.
+	Name string
+	// Provider is the provider that serves the model ("gemini", "groq", "ollama").
+	Provider string
+	// ContextWindow is the maximum number of tokens (prompt plus output) the model accepts.
+	ContextWindow int
+	// MaxOutputTokens is the maximum number of tokens the model generates per response.
+	MaxOutputTokens int
+	// Vision reports whether the model accepts image input.
+	Vision bool
+	// Audio reports whether the model accepts audio input.
+	Audio bool
+}
+
+// ModelInfoProvider is implemented by clients that look up model limits
+// from the provider's API.
+type ModelInfoProvider interface {
+	// ModelInfo returns the limits of model, or of the client's own model
+	// if model is empty. The result is also registered with RegisterModel.
+	ModelInfo(ctx context.Context, model string) (ModelInfo, error)
+}
+
+// ContextWindowProvider is implemented by clients that run their model with
+// a context window other than the registry's, such as Ollama, which uses
+// its num_ctx setting whatever the model was trained with.
+type ContextWindowProvider interface {
+	// ContextWindow returns the context window in tokens the client's
+	// requests are served with, or 0 if it is unknown.
+	ContextWindow() int
+}
+
+// knownModels is the bundled registry of model limits.
+// Values come from the providers' published documentation.
+var knownModels = []ModelInfo{
+	// Gemini
+	{Name: "gemma-3-27b-it", Provider: "gemini", ContextWindow: 131072, MaxOutputTokens: 8192, Vision: true},
+	{Name: "gemini-1.5-pro", Provider: "gemini", ContextWindow: 2097152, MaxOutputTokens: 8192, Vision: true, Audio: true},
+	{Name: "gemini-1.5-flash", Provider: "gemini", ContextWindow: 1048576, MaxOutputTokens: 8192, Vision: true, Audio: true},
+	{Name: "gemini-2.0-flash", Provider: "gemini", ContextWindow: 1048576, MaxOutputTokens: 8192, Vision: true, Audio: true},
+	{Name: "gemini-2.5-flash", Provider: "gemini", ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Audio: true},
+	{Name: "gemini-2.5-pro", Provider: "gemini", ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Audio: true},
+
+	// Groq
+	{Name: "gemma2-9b-it", Provider: "groq", ContextWindow: 8192, MaxOutputTokens: 8192},
+	{Name: "llama-3.1-8b-instant", Provider: "groq", ContextWindow: 131072, MaxOutputTokens: 131072},
+	{Name: "llama-3.3-70b-versatile", Provider: "groq", ContextWindow: 131072, MaxOutputTokens: 32768},
+	{Name: "llama3-8b-8192", Provider: "groq", ContextWindow: 8192, MaxOutputTokens: 8192},
+	{Name: "llama3-70b-8192", Provider: "groq", ContextWindow: 8192, MaxOutputTokens: 8192},
+	{Name: "mixtral-8x7b-32768", Provider: "groq", ContextWindow: 32768, MaxOutputTokens: 32768},
+
+	// Ollama (defaults of the published model files)
+	{Name: "gemma:2b", Provider: "ollama", ContextWindow: 8192, MaxOutputTokens: 8192},
+	{Name: "gemma:7b", Provider: "ollama", ContextWindow: 8192, MaxOutputTokens: 8192},
+	{Name: "gemma2", Provider: "ollama", ContextWindow: 8192, MaxOutputTokens: 8192},
+	{Name: "gemma3", Provider: "ollama", ContextWindow: 131072, MaxOutputTokens: 8192, Vision: true},
+	{Name: "llama3", Provider: "ollama", ContextWindow: 8192, MaxOutputTokens: 8192},
+	{Name: "llama3.1", Provider: "ollama", ContextWindow: 131072, MaxOutputTokens: 131072},
+	{Name: "llama3.2", Provider: "ollama", ContextWindow: 131072, MaxOutputTokens: 131072},
+	{Name: "mistral", Provider: "ollama", ContextWindow: 32768, MaxOutputTokens: 32768},
+	{Name: "codellama", Provider: "ollama", ContextWindow: 16384, MaxOutputTokens: 16384},
+	{Name: "phi3", Provider: "ollama", ContextWindow: 131072, MaxOutputTokens: 131072},
+}
+
+var (
+	registryMu sync.RWMutex
+	registered = map[string]ModelInfo{}
+)
+
+// RegisterModel adds info to the registry, replacing any entry with the
+// same name, so LookupModel and the pre-flight check and prompt trimming
+// built on it use it. Clients register what their ModelInfo method
+// fetches; applications can register models the bundled registry lacks.
+func RegisterModel(info ModelInfo) {
+	name := normalizeModelName(info.Name)
+	if name == "" {
+		return
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registered[name] = info
+}
+
+// LookupModel returns the limits for a model name, from RegisterModel or
+// the bundled registry.
+//
+// Gemini's "models/" prefix is ignored, and for Ollama-style names with a tag
+// ("llama3:8b") the untagged name is tried when the exact name is unknown.
+func LookupModel(name string) (ModelInfo, bool) {
+	name = normalizeModelName(name)
+	if name == "" {
+		return ModelInfo{}, false
+	}
+	if info, ok := findModel(name); ok {
+		return info, true
+	}
+	if base, _, found := strings.Cut(name, ":"); found {
+		return findModel(base)
+	}
+	return ModelInfo{}, false
+}
+
+// KnownModels returns a copy of the bundled model registry.
+func KnownModels() []ModelInfo {
+	models := make([]ModelInfo, len(knownModels))
+	copy(models, knownModels)
+	return models
+}
+
+// normalizeModelName lowercases name and drops Gemini's "models/" prefix.
+func normalizeModelName(name string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "models/")
+}
+
+// findModel returns the registry entry with exactly the given name,
+// preferring registered entries over bundled ones.
+func findModel(name string) (ModelInfo, bool) {
+	registryMu.RLock()
+	info, ok := registered[name]
+	registryMu.RUnlock()
+	if ok {
+		return info, true
+	}
+	for _, info := range knownModels {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return ModelInfo{}, false
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		lookup   string
+		found    bool
+		expected string
+	}{
+		{name: "exact", lookup: "gemma-3-27b-it", found: true, expected: "gemma-3-27b-it"},
+		{name: "gemini prefix", lookup: "models/gemini-1.5-pro", found: true, expected: "gemini-1.5-pro"},
+		{name: "case and space", lookup: " Mixtral-8x7b-32768 ", found: true, expected: "mixtral-8x7b-32768"},
+		{name: "ollama tag exact", lookup: "gemma:2b", found: true, expected: "gemma:2b"},
+		{name: "ollama tag fallback", lookup: "llama3:8b", found: true, expected: "llama3"},
+		{name: "unknown", lookup: "no-such-model", found: false},
+		{name: "empty", lookup: "", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, ok := LookupModel(tt.lookup)
+			if ok != tt.found {
+				t.Fatalf("Expected found=%v, got %v", tt.found, ok)
+			}
+			if ok && info.Name != tt.expected {
+				t.Errorf("Expected model '%s', got '%s'", tt.expected, info.Name)
+			}
+		})
+	}
+}
+
+func TestKnownModels_Valid(t *testing.T) {
+	seen := map[string]bool{}
+	for _, info := range KnownModels() {
+		if seen[info.Name] {
+			t.Errorf("Duplicate model '%s' in registry", info.Name)
+		}
+		seen[info.Name] = true
+
+		if info.ContextWindow <= 0 || info.MaxOutputTokens <= 0 {
+			t.Errorf("Model '%s' has invalid limits: %+v", info.Name, info)
+		}
+		if info.MaxOutputTokens > info.ContextWindow {
+			t.Errorf("Model '%s' max output exceeds context window", info.Name)
+		}
+		if info.Provider == "" {
+			t.Errorf("Model '%s' has no provider", info.Name)
+		}
+	}
+
+	// Provider defaults must be present
+	for _, name := range []string{"gemma-3-27b-it", "gemma2-9b-it", "gemma:2b"} {
+		if !seen[name] {
+			t.Errorf("Default model '%s' missing from registry", name)
+		}
+	}
+}
+
+func TestKnownModels_ReturnsCopy(t *testing.T) {
+	models := KnownModels()
+	models[0].ContextWindow = -1
+
+	if KnownModels()[0].ContextWindow == -1 {
+		t.Error("Expected KnownModels to return a copy")
+	}
+}
+
+func TestRegisterModel(t *testing.T) {
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registered, "custom-model")
+		delete(registered, "gemma:2b")
+		registryMu.Unlock()
+	})
+
+	RegisterModel(ModelInfo{Name: "Custom-Model", Provider: "ollama", ContextWindow: 4096})
+	if info, ok := LookupModel("models/custom-model"); !ok || info.ContextWindow != 4096 {
+		t.Errorf("Expected registered model to be found, got %+v, %v", info, ok)
+	}
+
+	// Registered limits replace bundled ones
+	RegisterModel(ModelInfo{Name: "gemma:2b", Provider: "ollama", ContextWindow: 2048})
+	if info, _ := LookupModel("gemma:2b"); info.ContextWindow != 2048 {
+		t.Errorf("Expected registered limits to take precedence, got %d", info.ContextWindow)
+	}
+	if err := PreflightCheck("gemma:2b", strings.Repeat("word ", 3000)); err == nil {
+		t.Error("Expected the pre-flight check to use the registered limit")
+	}
+
+	RegisterModel(ModelInfo{Name: " "})
+	if _, ok := LookupModel(" "); ok {
+		t.Error("Expected unnamed models to be ignored")
+	}
+}
@@ -0,0 +1,41 @@
+package llm
+
+import "context"
+
+// ModerationResult is a provider's safety assessment of a text.
+type ModerationResult struct {
+	// Flagged is true if the text violates the provider's policy.
+	Flagged bool
+	// Categories lists the categories the text was flagged for, sorted,
+	// named like OpenAI's moderation categories, e.g. "harassment".
+	Categories []string
+	// Scores holds the likelihood of harm per category, from 0 to 1.
+	Scores map[string]float64
+	// Ratings are the provider's ratings as it reported them.
+	Ratings []SafetyRating
+}
+
+// ContentModerator is implemented by clients that check text against the
+// provider's safety policies without generating a response for it. The
+// Gemini client implements it with its safety ratings.
+type ContentModerator interface {
+	// Moderate rates text. A flagged result is not an error; errors mean
+	// the text could not be checked.
+	Moderate(ctx context.Context, text string) (*ModerationResult, error)
+}
+
+// ProbabilityScore converts a safety rating's probability name to a score
+// from 0 to 1: "Negligible" is 0, "Low" 0.25, "Medium" 0.5 and "High"
+// 0.75, the lower bound of each band. Unknown names score 0.
+func ProbabilityScore(probability string) float64 {
+	switch probability {
+	case "Low":
+		return 0.25
+	case "Medium":
+		return 0.5
+	case "High":
+		return 0.75
+	default:
+		return 0
+	}
+}
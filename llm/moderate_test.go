@@ -0,0 +1,18 @@
+package llm
+
+import "testing"
+
+func TestProbabilityScore(t *testing.T) {
+	tests := map[string]float64{
+		"Negligible":  0,
+		"Low":         0.25,
+		"Medium":      0.5,
+		"High":        0.75,
+		"Unspecified": 0,
+	}
+	for probability, want := range tests {
+		if got := ProbabilityScore(probability); got != want {
+			t.Errorf("ProbabilityScore(%q) = %v, want %v", probability, got, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package llm
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// ClientOptions holds the optional settings shared by all provider
+// clients. Providers accept them as trailing ClientOption arguments to
+// NewClient.
+type ClientOptions struct {
+	// PreflightTokenCheck makes Generate estimate the prompt size and fail
+	// with a *ContextLengthError before sending prompts that can't fit in
+	// the model's context window. See PreflightCheck.
+	PreflightTokenCheck bool
+	// LazyInit defers expensive client setup, such as creating the Gemini
+	// SDK client, from NewClient to the first call or an explicit Init.
+	LazyInit bool
+	// SystemPrompt is sent with every request as the provider's system
+	// instruction, separately from the user prompt: the system_instruction
+	// on Gemini, a system message on Groq and the system field on Ollama.
+	SystemPrompt string
+	// Sampling holds the default sampling parameters of every request.
+	// Calls may override them, see SamplingGenerator.
+	Sampling Sampling
+	// EmbeddingModel is the model that computes embeddings, see Embedder.
+	// If empty, each provider picks its default.
+	EmbeddingModel string
+	// TranscriptionModel is the model that transcribes speech, see
+	// Transcriber. If empty, each provider picks its default.
+	TranscriptionModel string
+	// ImageModel is the model that generates images, see ImageGenerator.
+	// If empty, each provider picks its default.
+	ImageModel string
+	// Runtime holds model runtime settings for self-hosted servers. Ollama
+	// sends them in the request's options.
+	Runtime RuntimeOptions
+	// Logger receives the client's log records. If nil, they go to the
+	// default logger, see ClientLogger.
+	Logger *slog.Logger
+	// Organization and Project attribute requests to an organization and
+	// project of the account, for billing. Groq sends them as the
+	// OpenAI-Organization and OpenAI-Project headers, Gemini sends Project
+	// as x-goog-user-project.
+	Organization string
+	Project      string
+	// AppIdentifier names the application in the User-Agent of the
+	// client's requests. If empty, the one set with SetAppIdentifier is
+	// used. See UserAgent.
+	AppIdentifier string
+	// HTTPTransport, if set, replaces the shared transport for the
+	// client's requests; TransportOptions, if set, give the client a
+	// transport of its own tuned by them; Dialer, if set, replaces only how
+	// connections are opened. See ClientOptions.Transport.
+	HTTPTransport    http.RoundTripper
+	TransportOptions *TransportOptions
+	Dialer           DialFunc
+	// RequestHooks modify every outgoing request, e.g. to sign it. See
+	// RequestHook.
+	RequestHooks []RequestHook
+	// StickySessions makes clients balancing across several hosts send the
+	// requests of one session to the same host. See WithSessionKey.
+	StickySessions bool
+}
+
+// RuntimeOptions configure how a self-hosted server runs the model. Nil
+// fields leave the server's default in place. The TOML names match
+// Ollama's option names.
+type RuntimeOptions struct {
+	// NumCtx is the context window in tokens. Ollama defaults to a small
+	// window regardless of what the model supports, silently truncating
+	// longer prompts, so large-context models need it raised. The
+	// pre-flight check and conversation trimming use it as the window.
+	NumCtx *int `toml:"num_ctx,omitempty"`
+	// NumGPU is the number of layers offloaded to the GPU; 0 runs on the
+	// CPU only.
+	NumGPU *int `toml:"num_gpu,omitempty"`
+	// NumThread is the number of CPU threads used for generation.
+	NumThread *int `toml:"num_thread,omitempty"`
+	// Mirostat enables Mirostat sampling: 0 off, 1 Mirostat, 2 Mirostat 2.0.
+	Mirostat *int `toml:"mirostat,omitempty"`
+	// MirostatEta is Mirostat's learning rate.
+	MirostatEta *float64 `toml:"mirostat_eta,omitempty"`
+	// MirostatTau balances coherence (lower) and diversity (higher) under
+	// Mirostat.
+	MirostatTau *float64 `toml:"mirostat_tau,omitempty"`
+	// RepeatPenalty scales down the likelihood of tokens repeated within
+	// the last 64 tokens; 1 turns it off, Ollama's default is 1.1. Unlike
+	// the presence and frequency penalties of Sampling it multiplies rather
+	// than subtracts.
+	RepeatPenalty *float64 `toml:"repeat_penalty,omitempty"`
+}
+
+// ClientOption sets an optional client setting.
+type ClientOption func(*ClientOptions)
+
+// WithPreflightTokenCheck enables the pre-flight context window check.
+func WithPreflightTokenCheck() ClientOption {
+	return func(o *ClientOptions) {
+		o.PreflightTokenCheck = true
+	}
+}
+
+// WithLazyInit defers expensive client setup until the client is first
+// used or Init is called.
+func WithLazyInit() ClientOption {
+	return func(o *ClientOptions) {
+		o.LazyInit = true
+	}
+}
+
+// WithSystemPrompt sends prompt as the system instruction of every
+// request.
+func WithSystemPrompt(prompt string) ClientOption {
+	return func(o *ClientOptions) {
+		o.SystemPrompt = prompt
+	}
+}
+
+// WithSampling sets the default sampling parameters. Fields that are nil
+// in s keep their earlier value.
+func WithSampling(s Sampling) ClientOption {
+	return func(o *ClientOptions) {
+		o.Sampling = o.Sampling.Override(s)
+	}
+}
+
+// WithOrganization attributes requests to an organization and project of
+// the account. Empty values are not sent.
+func WithOrganization(organization, project string) ClientOption {
+	return func(o *ClientOptions) {
+		o.Organization = organization
+		o.Project = project
+	}
+}
+
+// WithRuntimeOptions sets model runtime settings for self-hosted servers.
+// Fields that are nil in r keep their earlier value.
+func WithRuntimeOptions(r RuntimeOptions) ClientOption {
+	return func(o *ClientOptions) {
+		if r.NumCtx != nil {
+			o.Runtime.NumCtx = r.NumCtx
+		}
+		if r.NumGPU != nil {
+			o.Runtime.NumGPU = r.NumGPU
+		}
+		if r.NumThread != nil {
+			o.Runtime.NumThread = r.NumThread
+		}
+		if r.Mirostat != nil {
+			o.Runtime.Mirostat = r.Mirostat
+		}
+		if r.MirostatEta != nil {
+			o.Runtime.MirostatEta = r.MirostatEta
+		}
+		if r.MirostatTau != nil {
+			o.Runtime.MirostatTau = r.MirostatTau
+		}
+		if r.RepeatPenalty != nil {
+			o.Runtime.RepeatPenalty = r.RepeatPenalty
+		}
+	}
+}
+
+// ApplyOptions returns the settings resulting from opts, applied in order.
+func ApplyOptions(opts []ClientOption) ClientOptions {
+	var o ClientOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithStickySessions enables sticky sessions, see
+// ClientOptions.StickySessions.
+func WithStickySessions() ClientOption {
+	return func(o *ClientOptions) {
+		o.StickySessions = true
+	}
+}
@@ -0,0 +1,101 @@
+package llm
+
+import "sync"
+
+// Pricing is the price of a model's tokens in US dollars per million
+// tokens.
+type Pricing struct {
+	// Input is the price of prompt tokens.
+	Input float64
+	// Output is the price of generated tokens.
+	Output float64
+	// CachedInput is the price of prompt tokens served from a prompt
+	// cache. Zero means cached tokens are billed as Input.
+	CachedInput float64
+}
+
+// Cost returns the price of usage in US dollars.
+func (p Pricing) Cost(usage Usage) float64 {
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+	uncached := usage.PromptTokens - usage.CachedTokens
+	cost := float64(uncached)*p.Input + float64(usage.CachedTokens)*cachedPrice + float64(usage.CompletionTokens)*p.Output
+	return cost / 1e6
+}
+
+// selfHostedProviders serve models at no per-token price.
+var selfHostedProviders = map[string]bool{
+	"ollama": true,
+}
+
+// knownPricing is the bundled pricing table, keyed by model name. Prices
+// come from the providers' published price lists, for prompts within the
+// base context tier, and change over time; use RegisterPricing to keep
+// them current or to add negotiated rates.
+var knownPricing = map[string]Pricing{
+	// Gemini
+	"gemma-3-27b-it":   {},
+	"gemini-1.5-pro":   {Input: 1.25, Output: 5.00, CachedInput: 0.3125},
+	"gemini-1.5-flash": {Input: 0.075, Output: 0.30, CachedInput: 0.01875},
+	"gemini-2.0-flash": {Input: 0.10, Output: 0.40, CachedInput: 0.025},
+	"gemini-2.5-flash": {Input: 0.30, Output: 2.50, CachedInput: 0.075},
+	"gemini-2.5-pro":   {Input: 1.25, Output: 10.00, CachedInput: 0.31},
+
+	// Groq
+	"gemma2-9b-it":            {Input: 0.20, Output: 0.20},
+	"llama-3.1-8b-instant":    {Input: 0.05, Output: 0.08},
+	"llama-3.3-70b-versatile": {Input: 0.59, Output: 0.79},
+	"llama3-8b-8192":          {Input: 0.05, Output: 0.08},
+	"llama3-70b-8192":         {Input: 0.59, Output: 0.79},
+	"mixtral-8x7b-32768":      {Input: 0.24, Output: 0.24},
+}
+
+var (
+	pricingMu         sync.RWMutex
+	registeredPricing = map[string]Pricing{}
+)
+
+// RegisterPricing sets the pricing of a model, replacing any bundled or
+// earlier registered pricing.
+func RegisterPricing(model string, pricing Pricing) {
+	name := normalizeModelName(model)
+	if name == "" {
+		return
+	}
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	registeredPricing[name] = pricing
+}
+
+// LookupPricing returns the pricing of a model served by provider, from
+// RegisterPricing or the bundled table. Models of self-hosted providers
+// such as Ollama are free. Model names are matched like LookupModel.
+func LookupPricing(provider, model string) (Pricing, bool) {
+	if selfHostedProviders[provider] {
+		return Pricing{}, true
+	}
+	name := normalizeModelName(model)
+	if name == "" {
+		return Pricing{}, false
+	}
+	pricingMu.RLock()
+	p, ok := registeredPricing[name]
+	pricingMu.RUnlock()
+	if ok {
+		return p, true
+	}
+	p, ok = knownPricing[name]
+	return p, ok
+}
+
+// EstimateCost returns the price of usage on a model in US dollars, and
+// false if the model's pricing is unknown.
+func EstimateCost(provider, model string, usage Usage) (float64, bool) {
+	p, ok := LookupPricing(provider, model)
+	if !ok {
+		return 0, false
+	}
+	return p.Cost(usage), true
+}
@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPricing_Cost(t *testing.T) {
+	p := Pricing{Input: 1.00, Output: 4.00, CachedInput: 0.25}
+	usage := Usage{PromptTokens: 1_000_000, CachedTokens: 400_000, CompletionTokens: 500_000}
+	// 600k * 1.00 + 400k * 0.25 + 500k * 4.00
+	if got := p.Cost(usage); math.Abs(got-2.70) > 1e-9 {
+		t.Errorf("Expected $2.70, got %f", got)
+	}
+
+	noCachePrice := Pricing{Input: 1.00, Output: 4.00}
+	if got := noCachePrice.Cost(usage); math.Abs(got-3.00) > 1e-9 {
+		t.Errorf("Expected cached tokens at the input price, $3.00, got %f", got)
+	}
+}
+
+func TestLookupPricing(t *testing.T) {
+	if p, ok := LookupPricing("gemini", "models/Gemini-1.5-Flash"); !ok || p.Input != 0.075 {
+		t.Errorf("Expected bundled gemini-1.5-flash pricing, got %+v, %v", p, ok)
+	}
+	if p, ok := LookupPricing("ollama", "some-local-model"); !ok || p != (Pricing{}) {
+		t.Errorf("Expected Ollama models to be free, got %+v, %v", p, ok)
+	}
+	if _, ok := LookupPricing("groq", "unknown-model"); ok {
+		t.Error("Expected unknown model to have no pricing")
+	}
+}
+
+func TestRegisterPricing(t *testing.T) {
+	RegisterPricing("pricing-test-model", Pricing{Input: 2, Output: 6})
+	defer func() {
+		pricingMu.Lock()
+		delete(registeredPricing, "pricing-test-model")
+		pricingMu.Unlock()
+	}()
+
+	cost, ok := EstimateCost("groq", "Pricing-Test-Model", Usage{PromptTokens: 500_000, CompletionTokens: 100_000})
+	if !ok || math.Abs(cost-1.6) > 1e-9 {
+		t.Errorf("Expected $1.60 from registered pricing, got %f, %v", cost, ok)
+	}
+}
+
+func TestResponseMetadata_WithEstimatedCost(t *testing.T) {
+	md := (&ResponseMetadata{Model: "gemini-1.5-flash", Usage: Usage{PromptTokens: 1_000_000}}).WithEstimatedCost("gemini")
+	if math.Abs(md.EstimatedCostUSD-0.075) > 1e-9 {
+		t.Errorf("Expected $0.075, got %f", md.EstimatedCostUSD)
+	}
+	md = (&ResponseMetadata{Model: "unknown-model", Usage: Usage{PromptTokens: 1000}}).WithEstimatedCost("groq")
+	if md.EstimatedCostUSD != 0 {
+		t.Errorf("Expected no cost for unknown pricing, got %f", md.EstimatedCostUSD)
+	}
+}
@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitState is a provider's rate limit status as reported in the
+// headers of its most recent response. Counts the provider didn't report
+// are -1 and reset times it didn't report are zero.
+type RateLimitState struct {
+	// LimitRequests and RemainingRequests are the request allowance of the
+	// current window and what is left of it.
+	LimitRequests     int
+	RemainingRequests int
+	// LimitTokens and RemainingTokens are the token allowance of the
+	// current window and what is left of it.
+	LimitTokens     int
+	RemainingTokens int
+	// ResetRequests and ResetTokens are when the allowances are restored.
+	ResetRequests time.Time
+	ResetTokens   time.Time
+	// RetryAfter is how long the provider asked to wait after rejecting a
+	// request, or zero.
+	RetryAfter time.Duration
+	// UpdatedAt is when the response carrying the state was received.
+	UpdatedAt time.Time
+}
+
+// RateLimitReporter is implemented by clients that track the rate limit
+// headers of their responses. ok is false until a response has reported a
+// rate limit.
+type RateLimitReporter interface {
+	RateLimitState() (state RateLimitState, ok bool)
+}
+
+// ParseRateLimitHeaders reads the OpenAI-style x-ratelimit-* headers used
+// by Groq and others, plus Retry-After, from a response received at now. It
+// returns false if h has none of them.
+func ParseRateLimitHeaders(h http.Header, now time.Time) (RateLimitState, bool) {
+	state := RateLimitState{
+		LimitRequests:     parseCount(h.Get("X-Ratelimit-Limit-Requests")),
+		RemainingRequests: parseCount(h.Get("X-Ratelimit-Remaining-Requests")),
+		LimitTokens:       parseCount(h.Get("X-Ratelimit-Limit-Tokens")),
+		RemainingTokens:   parseCount(h.Get("X-Ratelimit-Remaining-Tokens")),
+		ResetRequests:     parseReset(h.Get("X-Ratelimit-Reset-Requests"), now),
+		ResetTokens:       parseReset(h.Get("X-Ratelimit-Reset-Tokens"), now),
+		RetryAfter:        parseRetryAfter(h.Get("Retry-After"), now),
+		UpdatedAt:         now,
+	}
+	found := state.LimitRequests >= 0 || state.RemainingRequests >= 0 ||
+		state.LimitTokens >= 0 || state.RemainingTokens >= 0 ||
+		!state.ResetRequests.IsZero() || !state.ResetTokens.IsZero() || state.RetryAfter > 0
+	return state, found
+}
+
+// parseCount parses a count header, returning -1 if it is missing or
+// malformed.
+func parseCount(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
+
+// parseReset parses a reset header, either a duration such as "2m59.56s"
+// or "120ms", or a number of seconds, into the time it refers to.
+func parseReset(value string, now time.Time) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d)
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil && secs >= 0 {
+		return now.Add(time.Duration(secs * float64(time.Second)))
+	}
+	return time.Time{}
+}
+
+// parseRetryAfter parses a Retry-After header, either a number of seconds
+// or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// RateLimitTracker keeps the latest rate limit state reported by a
+// provider's responses. The zero value is ready to use and it is safe for
+// concurrent use.
+type RateLimitTracker struct {
+	mu    sync.Mutex
+	state RateLimitState
+	ok    bool
+}
+
+// Update records the rate limit headers of a response, if it has any.
+// Responses older than the recorded state are ignored, so responses of
+// concurrent requests arriving out of order don't roll it back.
+func (t *RateLimitTracker) Update(h http.Header) {
+	state, ok := ParseRateLimitHeaders(h, time.Now())
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ok && state.UpdatedAt.Before(t.state.UpdatedAt) {
+		return
+	}
+	t.state, t.ok = state, true
+}
+
+// State returns the latest recorded state; ok is false if no response has
+// reported one yet.
+func (t *RateLimitTracker) State() (RateLimitState, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state, t.ok
+}
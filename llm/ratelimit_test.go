@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	h := http.Header{}
+	h.Set("X-Ratelimit-Limit-Requests", "100")
+	h.Set("X-Ratelimit-Remaining-Requests", "99")
+	h.Set("X-Ratelimit-Reset-Requests", "1m30s")
+	h.Set("X-Ratelimit-Reset-Tokens", "12")
+
+	state, ok := ParseRateLimitHeaders(h, now)
+	if !ok {
+		t.Fatal("Expected rate limit headers to be found")
+	}
+	if state.LimitRequests != 100 || state.RemainingRequests != 99 {
+		t.Errorf("Unexpected request counts: %+v", state)
+	}
+	if state.LimitTokens != -1 || state.RemainingTokens != -1 {
+		t.Errorf("Expected unreported token counts to be -1, got %+v", state)
+	}
+	if !state.ResetRequests.Equal(now.Add(90*time.Second)) || !state.ResetTokens.Equal(now.Add(12*time.Second)) {
+		t.Errorf("Unexpected reset times: %v, %v", state.ResetRequests, state.ResetTokens)
+	}
+
+	if _, ok := ParseRateLimitHeaders(http.Header{"Content-Type": {"application/json"}}, now); ok {
+		t.Error("Expected no rate limit state without the headers")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"5":                             5 * time.Second,
+		"0.5":                           500 * time.Millisecond,
+		"Wed, 01 May 2024 12:00:10 GMT": 10 * time.Second,
+		"Wed, 01 May 2024 11:00:00 GMT": 0,
+		"soon":                          0,
+	}
+	for value, want := range tests {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestRateLimitTracker(t *testing.T) {
+	var tracker RateLimitTracker
+	if _, ok := tracker.State(); ok {
+		t.Error("Expected no state initially")
+	}
+	tracker.Update(http.Header{})
+	if _, ok := tracker.State(); ok {
+		t.Error("Expected responses without headers to be ignored")
+	}
+	h := http.Header{}
+	h.Set("X-Ratelimit-Remaining-Tokens", "500")
+	tracker.Update(h)
+	if state, ok := tracker.State(); !ok || state.RemainingTokens != 500 {
+		t.Errorf("Expected 500 remaining tokens, got %+v, %v", state, ok)
+	}
+}
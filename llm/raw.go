@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// RawResponse holds the undecoded HTTP response of a provider request, for
+// reading provider-specific fields xollm doesn't model. It is filled in by
+// requests made with a context from WithRawResponse, and is safe to read
+// once the request returned.
+type RawResponse struct {
+	mu         sync.Mutex
+	statusCode int
+	header     http.Header
+	body       bytes.Buffer
+}
+
+// StatusCode returns the HTTP status of the response, or 0 if none was
+// received.
+func (r *RawResponse) StatusCode() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.statusCode
+}
+
+// Header returns the response headers.
+func (r *RawResponse) Header() http.Header {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header
+}
+
+// Body returns the response body as the provider sent it: a JSON document,
+// or the server-sent events or newline-delimited JSON of a stream, as far
+// as it was read.
+func (r *RawResponse) Body() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return bytes.Clone(r.body.Bytes())
+}
+
+// reset starts capturing resp.
+func (r *RawResponse) reset(resp *http.Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statusCode, r.header = resp.StatusCode, resp.Header.Clone()
+	r.body.Reset()
+}
+
+// Write appends to the captured body.
+func (r *RawResponse) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body.Write(p)
+}
+
+type rawResponseKey struct{}
+
+// WithRawResponse returns a copy of ctx whose provider requests capture
+// their undecoded response in the returned RawResponse, alongside the
+// normalized result. If a call makes several requests, e.g. retries, the
+// last response is kept.
+//
+//	ctx, raw := llm.WithRawResponse(ctx)
+//	text, md, err := client.GenerateWithMetadata(ctx, prompt)
+//	var extra struct{ SystemFingerprint string `json:"system_fingerprint"` }
+//	json.Unmarshal(raw.Body(), &extra)
+func WithRawResponse(ctx context.Context) (context.Context, *RawResponse) {
+	raw := &RawResponse{}
+	return context.WithValue(ctx, rawResponseKey{}, raw), raw
+}
+
+// CaptureRawResponse records resp in the RawResponse of its request's
+// context, if any, by copying its body as the provider reads it. Providers
+// call it on every response they receive.
+func CaptureRawResponse(resp *http.Response) {
+	if resp == nil || resp.Request == nil {
+		return
+	}
+	raw, ok := resp.Request.Context().Value(rawResponseKey{}).(*RawResponse)
+	if !ok {
+		return
+	}
+	raw.reset(resp)
+	resp.Body = &teeReadCloser{Reader: io.TeeReader(resp.Body, raw), Closer: resp.Body}
+}
+
+// teeReadCloser reads through a tee and closes the original body.
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCaptureRawResponse(t *testing.T) {
+	newResponse := func(ctx context.Context, body string) *http.Response {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://example.com", nil)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"X-Request-Id": {"req-1"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}
+	}
+
+	// Without a capture the body is left alone
+	resp := newResponse(context.Background(), "{}")
+	body := resp.Body
+	CaptureRawResponse(resp)
+	if resp.Body != body {
+		t.Error("Expected the body not to be wrapped without WithRawResponse")
+	}
+
+	ctx, raw := WithRawResponse(context.Background())
+	CaptureRawResponse(newResponse(ctx, `{"retry":true}`))
+	resp = newResponse(ctx, `{"id":"x","system_fingerprint":"fp_1"}`)
+	CaptureRawResponse(resp)
+	read, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(read) != `{"id":"x","system_fingerprint":"fp_1"}` {
+		t.Errorf("Expected the provider to read the body unchanged, got %q", read)
+	}
+	if string(raw.Body()) != string(read) || raw.StatusCode() != http.StatusOK || raw.Header().Get("X-Request-Id") != "req-1" {
+		t.Errorf("Expected the last response to be captured, got %d %v %q", raw.StatusCode(), raw.Header(), raw.Body())
+	}
+}
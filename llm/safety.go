@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stages at which a provider can block content, reported in
+// ContentFilteredError.Stage.
+const (
+	StagePrompt   = "prompt"
+	StageResponse = "response"
+)
+
+// SafetyRating is a provider's assessment of one harm category.
+type SafetyRating struct {
+	// Category is the harm category, e.g. "Harassment" or "DangerousContent".
+	Category string
+	// Probability is the likelihood of harm, e.g. "Negligible", "Low",
+	// "Medium" or "High".
+	Probability string
+	// Blocked is true if this rating caused the content to be blocked.
+	Blocked bool
+}
+
+// ContentFilteredError reports a prompt or response blocked by a
+// provider's safety filters, with the ratings that led to the block. It
+// matches ErrContentFiltered with errors.Is.
+type ContentFilteredError struct {
+	// Provider is the provider name, e.g. "gemini".
+	Provider string
+	// Stage is StagePrompt or StageResponse.
+	Stage string
+	// Reason is the provider's block or finish reason, e.g. "Safety".
+	Reason string
+	// Ratings are the safety ratings for the blocked content.
+	Ratings []SafetyRating
+}
+
+// Error describes what was blocked and why.
+func (e *ContentFilteredError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s blocked the %s", e.Provider, e.Stage)
+	if e.Reason != "" {
+		fmt.Fprintf(&b, " (reason: %s)", e.Reason)
+	}
+	if categories := e.BlockedCategories(); len(categories) > 0 {
+		fmt.Fprintf(&b, ": %s", strings.Join(categories, ", "))
+	}
+	return b.String()
+}
+
+// Is reports whether target is ErrContentFiltered.
+func (e *ContentFilteredError) Is(target error) bool {
+	return target == ErrContentFiltered
+}
+
+// BlockedCategories returns the categories of the ratings that caused the
+// block, in the order the provider reported them.
+func (e *ContentFilteredError) BlockedCategories() []string {
+	var categories []string
+	for _, r := range e.Ratings {
+		if r.Blocked {
+			categories = append(categories, r.Category)
+		}
+	}
+	return categories
+}
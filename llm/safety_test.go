@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestContentFilteredError(t *testing.T) {
+	err := &ContentFilteredError{
+		Provider: "gemini",
+		Stage:    StagePrompt,
+		Reason:   "Safety",
+		Ratings: []SafetyRating{
+			{Category: "Harassment", Probability: "High", Blocked: true},
+			{Category: "HateSpeech", Probability: "Low"},
+			{Category: "DangerousContent", Probability: "Medium", Blocked: true},
+		},
+	}
+
+	if err.Error() != "gemini blocked the prompt (reason: Safety): Harassment, DangerousContent" {
+		t.Errorf("Unexpected message: %s", err.Error())
+	}
+	if !errors.Is(fmt.Errorf("generate: %w", err), ErrContentFiltered) {
+		t.Error("Expected ContentFilteredError to match ErrContentFiltered")
+	}
+	if !reflect.DeepEqual(err.BlockedCategories(), []string{"Harassment", "DangerousContent"}) {
+		t.Errorf("Unexpected blocked categories: %v", err.BlockedCategories())
+	}
+
+	bare := &ContentFilteredError{Provider: "gemini", Stage: StageResponse}
+	if bare.Error() != "gemini blocked the response" {
+		t.Errorf("Unexpected message: %s", bare.Error())
+	}
+}
@@ -0,0 +1,76 @@
+package llm
+
+import "context"
+
+// Sampling holds the parameters that shape generated text. Nil fields
+// leave the provider's default in place.
+type Sampling struct {
+	// Temperature controls randomness; 0 is nearly deterministic.
+	Temperature *float64
+	// MaxTokens caps the number of generated tokens.
+	MaxTokens *int
+	// TopP restricts sampling to the most likely tokens whose probabilities
+	// add up to TopP.
+	TopP *float64
+	// Stop lists sequences that end the generation when produced. The
+	// sequence itself is not included in the response. Providers limit
+	// their number: Groq and Gemini accept up to 4 and 5.
+	Stop []string
+	// Seed makes sampling repeatable: requests with the same seed, prompt
+	// and parameters return the same text, which golden tests rely on.
+	// Ollama and Groq support it; Groq only makes a best effort. Gemini
+	// ignores it.
+	Seed *int
+	// PresencePenalty, from -2 to 2, penalizes tokens that already appeared
+	// in the text, making the model more likely to move to new topics.
+	// FrequencyPenalty, from -2 to 2, penalizes tokens by how often they
+	// appeared, reducing verbatim repetition. Groq and Ollama support them;
+	// Gemini ignores them.
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+}
+
+// SamplingGenerator is implemented by clients that accept sampling
+// parameters per call. All bundled providers implement it.
+type SamplingGenerator interface {
+	// GenerateWithSampling is Generate with the fields set in sampling
+	// overriding the client's defaults for this call.
+	GenerateWithSampling(ctx context.Context, prompt string, sampling Sampling) (string, error)
+}
+
+// Override returns s with the fields that are set in o replaced. An empty
+// but non-nil Stop clears the stop sequences.
+func (s Sampling) Override(o Sampling) Sampling {
+	if o.Temperature != nil {
+		s.Temperature = o.Temperature
+	}
+	if o.MaxTokens != nil {
+		s.MaxTokens = o.MaxTokens
+	}
+	if o.TopP != nil {
+		s.TopP = o.TopP
+	}
+	if o.Stop != nil {
+		s.Stop = o.Stop
+	}
+	if o.Seed != nil {
+		s.Seed = o.Seed
+	}
+	if o.PresencePenalty != nil {
+		s.PresencePenalty = o.PresencePenalty
+	}
+	if o.FrequencyPenalty != nil {
+		s.FrequencyPenalty = o.FrequencyPenalty
+	}
+	return s
+}
+
+// Float64 returns a pointer to v, for filling Sampling literals.
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// Int returns a pointer to v, for filling Sampling literals.
+func Int(v int) *int {
+	return &v
+}
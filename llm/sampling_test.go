@@ -0,0 +1,65 @@
+package llm
+
+import "testing"
+
+func TestSampling_Override(t *testing.T) {
+	base := Sampling{Temperature: Float64(0.7), MaxTokens: Int(100)}
+	got := base.Override(Sampling{MaxTokens: Int(10), TopP: Float64(0.9)})
+
+	if got.Temperature == nil || *got.Temperature != 0.7 {
+		t.Errorf("Expected unset fields to keep the base value, got %v", got.Temperature)
+	}
+	if got.MaxTokens == nil || *got.MaxTokens != 10 {
+		t.Errorf("Expected MaxTokens overridden to 10, got %v", got.MaxTokens)
+	}
+	if got.TopP == nil || *got.TopP != 0.9 {
+		t.Errorf("Expected TopP set to 0.9, got %v", got.TopP)
+	}
+	if *base.MaxTokens != 100 {
+		t.Error("Expected Override not to modify the receiver")
+	}
+}
+
+func TestWithSampling_Merges(t *testing.T) {
+	opts := ApplyOptions([]ClientOption{
+		WithSampling(Sampling{Temperature: Float64(0.2)}),
+		WithSampling(Sampling{TopP: Float64(0.5)}),
+	})
+	if opts.Sampling.Temperature == nil || opts.Sampling.TopP == nil || opts.Sampling.MaxTokens != nil {
+		t.Errorf("Expected both options to apply, got %+v", opts.Sampling)
+	}
+}
+
+func TestSampling_OverrideSeed(t *testing.T) {
+	base := Sampling{Seed: Int(1)}
+	if got := base.Override(Sampling{Temperature: Float64(0)}); got.Seed == nil || *got.Seed != 1 {
+		t.Errorf("Expected unset Seed to keep the base seed, got %v", got.Seed)
+	}
+	if got := base.Override(Sampling{Seed: Int(42)}); got.Seed == nil || *got.Seed != 42 {
+		t.Errorf("Expected Seed overridden to 42, got %v", got.Seed)
+	}
+}
+
+func TestSampling_OverridePenalties(t *testing.T) {
+	base := Sampling{PresencePenalty: Float64(0.5), FrequencyPenalty: Float64(0.5)}
+	got := base.Override(Sampling{FrequencyPenalty: Float64(1.2)})
+	if got.PresencePenalty == nil || *got.PresencePenalty != 0.5 {
+		t.Errorf("Expected unset PresencePenalty to keep the base value, got %v", got.PresencePenalty)
+	}
+	if got.FrequencyPenalty == nil || *got.FrequencyPenalty != 1.2 {
+		t.Errorf("Expected FrequencyPenalty overridden to 1.2, got %v", got.FrequencyPenalty)
+	}
+}
+
+func TestSampling_OverrideStop(t *testing.T) {
+	base := Sampling{Stop: []string{"END"}}
+	if got := base.Override(Sampling{}); len(got.Stop) != 1 || got.Stop[0] != "END" {
+		t.Errorf("Expected unset Stop to keep the base sequences, got %q", got.Stop)
+	}
+	if got := base.Override(Sampling{Stop: []string{"\n\n", "###"}}); len(got.Stop) != 2 || got.Stop[1] != "###" {
+		t.Errorf("Expected Stop replaced, got %q", got.Stop)
+	}
+	if got := base.Override(Sampling{Stop: []string{}}); len(got.Stop) != 0 {
+		t.Errorf("Expected an empty Stop to clear the sequences, got %q", got.Stop)
+	}
+}
@@ -0,0 +1,223 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaError reports JSON that doesn't match a schema.
+type SchemaError struct {
+	// Path locates the mismatch, e.g. "$.items[2].name".
+	Path string
+	// Message describes the mismatch.
+	Message string
+}
+
+// Error returns the path and message.
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// JSONGenerator is implemented by clients that can constrain the response
+// to JSON with the provider's structured output mode. All bundled
+// providers implement it.
+type JSONGenerator interface {
+	// GenerateJSON returns the model's response to prompt as JSON
+	// matching schema, or any JSON object if schema is nil. Providers
+	// enforce the schema to different degrees, so callers should check
+	// the result with ValidateJSON.
+	GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error)
+}
+
+// JSONInstruction returns the instruction for models without native
+// schema support to respond with JSON matching schema, or with a JSON
+// object if schema is nil.
+func JSONInstruction(schema json.RawMessage) string {
+	if len(schema) == 0 {
+		return "Respond with a single JSON object and nothing else."
+	}
+	return "Respond with a single JSON value matching this JSON Schema and nothing else:\n" + string(schema)
+}
+
+// jsonSchema is the subset of JSON Schema understood by ValidateJSON.
+type jsonSchema struct {
+	Type                 json.RawMessage        `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+}
+
+// ValidateJSON checks that data is a JSON value matching schema. It
+// supports the subset of JSON Schema that structured output APIs accept:
+// type (a name or a list of names), properties, required,
+// additionalProperties set to false, items and enum. Other keywords are
+// ignored. A mismatch is returned as a *SchemaError.
+func ValidateJSON(schema json.RawMessage, data []byte) error {
+	var s jsonSchema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return &SchemaError{Path: "$", Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if dec.More() {
+		return &SchemaError{Path: "$", Message: "unexpected data after the JSON value"}
+	}
+	return s.validate("$", value)
+}
+
+// ExtractJSON returns the JSON value in a model's reply, removing a
+// markdown code fence or a sentence around it, as models often add. It
+// returns text from the first '{' or '[' to the last '}' or ']' if the
+// reply isn't JSON as it is, and the trimmed reply if it has neither.
+func ExtractJSON(text string) string {
+	text = strings.TrimSpace(text)
+	if json.Valid([]byte(text)) {
+		return text
+	}
+	if strings.HasPrefix(text, "```") {
+		fenced := strings.TrimPrefix(text, "```")
+		if i := strings.Index(fenced, "\n"); i >= 0 {
+			fenced = fenced[i+1:]
+		}
+		fenced = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(fenced), "```"))
+		if json.Valid([]byte(fenced)) {
+			return fenced
+		}
+	}
+	start := strings.IndexAny(text, "{[")
+	end := strings.LastIndexAny(text, "}]")
+	if start < 0 || end < start {
+		return text
+	}
+	return text[start : end+1]
+}
+
+// validate checks value at path against s.
+func (s *jsonSchema) validate(path string, value interface{}) error {
+	if err := s.checkType(path, value); err != nil {
+		return err
+	}
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("value %s is not one of the allowed values", compactJSON(value))}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &SchemaError{Path: path, Message: fmt.Sprintf("missing required property %q", name)}
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names) // Report the same mismatch on every run
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if bytes.Equal(bytes.TrimSpace(s.AdditionalProperties), []byte("false")) {
+					return &SchemaError{Path: path, Message: fmt.Sprintf("unexpected property %q", name)}
+				}
+				continue
+			}
+			if err := prop.validate(path+"."+name, v[name]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkType checks value against the schema's type keyword, if any.
+func (s *jsonSchema) checkType(path string, value interface{}) error {
+	if len(s.Type) == 0 {
+		return nil
+	}
+	var types []string
+	if err := json.Unmarshal(s.Type, &types); err != nil {
+		var single string
+		if err := json.Unmarshal(s.Type, &single); err != nil {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("invalid type keyword %s", s.Type)}
+		}
+		types = []string{single}
+	}
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	return &SchemaError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), actual)}
+}
+
+// inEnum reports whether value equals one of the enum values.
+func (s *jsonSchema) inEnum(value interface{}) bool {
+	want := compactJSON(value)
+	for _, allowed := range s.Enum {
+		if compactJSON(allowed) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a value decoded with
+// UseNumber.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		if f, err := v.Float64(); err == nil && f == float64(int64(f)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// compactJSON encodes value for comparison and error messages. Numbers are
+// normalized so that 1 and 1.0 compare equal.
+func compactJSON(value interface{}) string {
+	if n, ok := value.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			value = f
+		}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
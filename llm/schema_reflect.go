@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaFor returns the JSON Schema of the values of t as encoding/json
+// marshals them, for structured output. Struct fields are named by their
+// json tags and are required unless tagged omitempty or pointers; a
+// description tag describes the field to the model:
+//
+//	type Person struct {
+//		Name string `json:"name" description:"full name"`
+//		Age  int    `json:"age,omitempty"`
+//	}
+//
+// Strings, booleans, numbers, slices, arrays, maps with string keys,
+// structs, time.Time and types implementing encoding.TextMarshaler are
+// supported; interfaces and json.RawMessage accept any value. Channels,
+// functions and recursive types are errors.
+func SchemaFor(t reflect.Type) (json.RawMessage, error) {
+	s, err := schemaOf(t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(s)
+}
+
+// schemaOf returns the schema of t. visiting holds the struct types being
+// converted, to detect recursion.
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) (*jsonSchema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	typed := func(name string) *jsonSchema {
+		return &jsonSchema{Type: json.RawMessage(`"` + name + `"`)}
+	}
+	switch {
+	case t == timeType:
+		return typed("string"), nil
+	case t == rawMessageType:
+		return &jsonSchema{}, nil
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return typed("string"), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return typed("string"), nil
+	case reflect.Bool:
+		return typed("boolean"), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typed("integer"), nil
+	case reflect.Float32, reflect.Float64:
+		return typed("number"), nil
+	case reflect.Interface:
+		return &jsonSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return typed("string"), nil // base64, as encoding/json writes []byte
+		}
+		items, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		s := typed("array")
+		s.Items = items
+		return s, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		return typed("object"), nil
+	case reflect.Struct:
+		if visiting[t] {
+			return nil, fmt.Errorf("recursive type %s", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		s := typed("object")
+		s.Properties = map[string]*jsonSchema{}
+		if err := addFields(s, t, visiting); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// addFields adds the JSON fields of struct type t to s, including those of
+// embedded structs without a json name, as encoding/json promotes them.
+func addFields(s *jsonSchema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := addFields(s, embedded, visiting); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		prop, err := schemaOf(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		prop.Description = field.Tag.Get("description")
+		s.Properties[name] = prop
+		if !strings.Contains(","+opts+",", ",omitempty,") && field.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
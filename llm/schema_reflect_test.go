@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	City string `json:"city"`
+}
+
+type schemaPerson struct {
+	schemaAddress
+	Name     string            `json:"name" description:"full name"`
+	Age      int               `json:"age,omitempty"`
+	Score    float64           `json:"score"`
+	Tags     []string          `json:"tags"`
+	Manager  *schemaAddress    `json:"manager"`
+	Born     time.Time         `json:"born"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+type schemaNode struct {
+	Children []schemaNode `json:"children"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	raw, err := SchemaFor(reflect.TypeOf(schemaPerson{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	var schema struct {
+		Type       string `json:"type"`
+		Properties map[string]struct {
+			Type        string          `json:"type"`
+			Description string          `json:"description"`
+			Items       json.RawMessage `json:"items"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("Invalid schema %s: %v", raw, err)
+	}
+
+	if schema.Type != "object" || len(schema.Properties) != 8 {
+		t.Errorf("Expected an object with 8 properties, got %s", raw)
+	}
+	types := map[string]string{"city": "string", "name": "string", "age": "integer", "score": "number",
+		"tags": "array", "manager": "object", "born": "string", "extra": "object"}
+	for name, typ := range types {
+		if schema.Properties[name].Type != typ {
+			t.Errorf("Expected %s to be %s, got %q", name, typ, schema.Properties[name].Type)
+		}
+	}
+	if schema.Properties["name"].Description != "full name" || string(schema.Properties["tags"].Items) != `{"type":"string"}` {
+		t.Errorf("Unexpected name or tags schema: %s", raw)
+	}
+	if expected := []string{"city", "name", "score", "tags", "born"}; !reflect.DeepEqual(schema.Required, expected) {
+		t.Errorf("Expected required %q, got %q", expected, schema.Required)
+	}
+
+	valid := `{"city": "Oslo", "name": "Ada", "score": 1.5, "tags": [], "born": "1815-12-10T00:00:00Z"}`
+	if err := ValidateJSON(raw, []byte(valid)); err != nil {
+		t.Errorf("Expected a valid person, got: %v", err)
+	}
+	if err := ValidateJSON(raw, []byte(`{"name": "Ada"}`)); err == nil {
+		t.Error("Expected missing required fields to fail validation")
+	}
+}
+
+func TestSchemaFor_Unsupported(t *testing.T) {
+	if _, err := SchemaFor(reflect.TypeOf(schemaNode{})); err == nil {
+		t.Error("Expected an error for a recursive type")
+	}
+	if _, err := SchemaFor(reflect.TypeOf(struct{ C chan int }{})); err == nil {
+		t.Error("Expected an error for a channel field")
+	}
+	if _, err := SchemaFor(reflect.TypeOf(map[int]string{})); err == nil {
+		t.Error("Expected an error for a map with integer keys")
+	}
+}
@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const personSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer"},
+		"score": {"type": "number"},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"manager": {"type": ["object", "null"], "properties": {"name": {"type": "string"}}, "required": ["name"]}
+	},
+	"required": ["name", "age"],
+	"additionalProperties": false
+}`
+
+func TestValidateJSON_Valid(t *testing.T) {
+	valid := []string{
+		`{"name": "Ada", "age": 36}`,
+		`{"name": "Ada", "age": 36.0, "score": 9.5, "role": "admin", "tags": ["a", "b"], "manager": null}`,
+		`{"name": "Ada", "age": 1e2, "manager": {"name": "Charles"}}`,
+	}
+	for _, data := range valid {
+		if err := ValidateJSON([]byte(personSchema), []byte(data)); err != nil {
+			t.Errorf("ValidateJSON(%s) failed: %v", data, err)
+		}
+	}
+}
+
+func TestValidateJSON_Invalid(t *testing.T) {
+	tests := []struct {
+		data string
+		path string
+		msg  string
+	}{
+		{`[]`, "$", "expected object, got array"},
+		{`{"name": "Ada"}`, "$", `missing required property "age"`},
+		{`{"name": "Ada", "age": 3.5}`, "$.age", "expected integer, got number"},
+		{`{"name": "Ada", "age": 3, "role": "root"}`, "$.role", "not one of the allowed values"},
+		{`{"name": "Ada", "age": 3, "tags": ["a", 1]}`, "$.tags[1]", "expected string, got integer"},
+		{`{"name": "Ada", "age": 3, "manager": {}}`, "$.manager", `missing required property "name"`},
+		{`{"name": "Ada", "age": 3, "email": "x"}`, "$", `unexpected property "email"`},
+		{`{"name": "Ada",`, "$", "invalid JSON"},
+		{`{"name": "Ada", "age": 3} {}`, "$", "unexpected data"},
+	}
+	for _, tt := range tests {
+		err := ValidateJSON([]byte(personSchema), []byte(tt.data))
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) {
+			t.Errorf("ValidateJSON(%s): expected *SchemaError, got %v", tt.data, err)
+			continue
+		}
+		if schemaErr.Path != tt.path || !strings.Contains(schemaErr.Message, tt.msg) {
+			t.Errorf("ValidateJSON(%s) = %v, want %s: ...%s...", tt.data, err, tt.path, tt.msg)
+		}
+	}
+}
+
+func TestValidateJSON_InvalidSchema(t *testing.T) {
+	err := ValidateJSON([]byte(`{"type": `), []byte(`{}`))
+	var schemaErr *SchemaError
+	if err == nil || errors.As(err, &schemaErr) {
+		t.Errorf("Expected an invalid schema error, got %v", err)
+	}
+}
+
+func TestJSONInstruction(t *testing.T) {
+	if got := JSONInstruction(nil); !strings.Contains(got, "JSON object") {
+		t.Errorf("Expected an instruction for any object, got %q", got)
+	}
+	schema := json.RawMessage(`{"type":"array"}`)
+	if got := JSONInstruction(schema); !strings.HasSuffix(got, string(schema)) {
+		t.Errorf("Expected the schema in the instruction, got %q", got)
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{" [1, 2]\n", `[1, 2]`},
+		{"```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"```\n\"yes\"\n```", `"yes"`},
+		{`Here is the result: {"a": {"b": 2}}. Hope it helps!`, `{"a": {"b": 2}}`},
+		{"Sure!\n```json\n[{\"a\": 1}]\n```", `[{"a": 1}]`},
+		{`no JSON here`, `no JSON here`},
+	}
+	for _, tt := range tests {
+		if got := ExtractJSON(tt.text); got != tt.want {
+			t.Errorf("ExtractJSON(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
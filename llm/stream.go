@@ -0,0 +1,64 @@
+package llm
+
+import "fmt"
+
+// StreamChunk is a single incremental piece of a streamed generation.
+//
+// Streams deliver zero or more chunks carrying Text, followed by exactly one
+// terminal chunk that has either Done set or Err non-nil, after which the
+// channel is closed.
+type StreamChunk struct {
+	// Text is the newly generated text since the previous chunk.
+	Text string
+	// Done is true on the final chunk of a successful stream.
+	Done bool
+	// Err is set on the final chunk if the stream failed. If text was
+	// already delivered, xollm.GenerateStream reports it as a *PartialError.
+	Err error
+	// Usage is set on the Done chunk when the provider reports the tokens
+	// the generation consumed.
+	Usage *Usage
+	// FinishReason is set on the Done chunk to the provider's reason for
+	// ending the generation, as in ResponseMetadata.
+	FinishReason string
+}
+
+// Usage reports the tokens a generation consumed.
+type Usage struct {
+	// PromptTokens is the number of tokens in the prompt.
+	PromptTokens int
+	// CompletionTokens is the number of tokens generated.
+	CompletionTokens int
+	// CachedTokens is the part of PromptTokens served from a prompt cache,
+	// i.e. the cache hit. Zero if nothing was cached or the provider
+	// doesn't report it.
+	CachedTokens int
+	// Estimated is true if the counts were estimated locally because the
+	// provider didn't report them.
+	Estimated bool
+}
+
+// TotalTokens returns the sum of the prompt and completion tokens.
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// PartialError is the error of a stream that failed after producing some
+// text. Text holds everything generated before the failure so that, for
+// example, a UI can keep showing it.
+type PartialError struct {
+	// Text is the concatenated text of all chunks received before the error.
+	Text string
+	// Err is the error that ended the stream.
+	Err error
+}
+
+// Error reports the failure and how much text was received before it.
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("stream failed after %d bytes of output: %v", len(e.Text), e.Err)
+}
+
+// Unwrap returns the error that ended the stream.
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
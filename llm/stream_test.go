@@ -0,0 +1,18 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPartialError(t *testing.T) {
+	cause := errors.New("connection reset")
+	err := &PartialError{Text: "Hello", Err: cause}
+
+	if err.Error() != "stream failed after 5 bytes of output: connection reset" {
+		t.Errorf("Unexpected message: %s", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected PartialError to unwrap to its cause")
+	}
+}
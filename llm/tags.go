@@ -0,0 +1,60 @@
+package llm
+
+import "context"
+
+// tagsKey is the context key of request tags.
+type tagsKey struct{}
+
+// WithTags returns a copy of ctx carrying tags in addition to any tags it
+// already carries; tags replace existing ones with the same key. Tags label
+// a request, e.g. with the tenant or feature it belongs to, for quotas,
+// logs and cost attribution.
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string, len(tags))
+	for k, v := range TagsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, tagsKey{}, merged)
+}
+
+// TagsFromContext returns a copy of the tags carried by ctx, or nil if
+// there are none.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+	if tags == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(tags))
+	for k, v := range tags {
+		copied[k] = v
+	}
+	return copied
+}
+
+// TagFromContext returns the value of the tag key carried by ctx, or "" if
+// it is not set.
+func TagFromContext(ctx context.Context, key string) string {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+	return tags[key]
+}
+
+// sessionKeyKey is the context key of the session key.
+type sessionKeyKey struct{}
+
+// WithSessionKey returns a copy of ctx whose requests belong to the
+// conversation or session key, e.g. a chat ID. Clients balancing requests
+// across several hosts with sticky sessions (see WithStickySessions) send
+// requests with the same key to the same host, so the host's cached
+// conversation state is reused.
+func WithSessionKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionKeyKey{}, key)
+}
+
+// SessionKeyFromContext returns the session key carried by ctx, or "".
+func SessionKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(sessionKeyKey{}).(string)
+	return key
+}
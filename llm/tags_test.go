@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithTags(t *testing.T) {
+	ctx := WithTags(context.Background(), map[string]string{"tenant": "acme", "feature": "chat"})
+	ctx = WithTags(ctx, map[string]string{"feature": "search"})
+
+	if got := TagFromContext(ctx, "tenant"); got != "acme" {
+		t.Errorf("Expected tenant acme, got %q", got)
+	}
+	if got := TagFromContext(ctx, "feature"); got != "search" {
+		t.Errorf("Expected the later tag to win, got %q", got)
+	}
+
+	tags := TagsFromContext(ctx)
+	tags["tenant"] = "changed"
+	if TagFromContext(ctx, "tenant") != "acme" {
+		t.Error("Expected TagsFromContext to return a copy")
+	}
+}
+
+func TestTagsFromContext_None(t *testing.T) {
+	if tags := TagsFromContext(context.Background()); tags != nil {
+		t.Errorf("Expected nil tags, got %v", tags)
+	}
+	if got := TagFromContext(context.Background(), "tenant"); got != "" {
+		t.Errorf("Expected empty tag, got %q", got)
+	}
+}
+
+func TestSessionKey(t *testing.T) {
+	if got := SessionKeyFromContext(context.Background()); got != "" {
+		t.Errorf("Expected no session key, got %q", got)
+	}
+	ctx := WithSessionKey(context.Background(), "chat-1")
+	if got := SessionKeyFromContext(WithTags(ctx, map[string]string{"tenant": "acme"})); got != "chat-1" {
+		t.Errorf("Expected the session key, got %q", got)
+	}
+}
@@ -0,0 +1,26 @@
+package llm
+
+import (
+	"context"
+	"time"
+)
+
+// requestTimeoutKey is the context key of the request timeout override.
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout returns a copy of ctx whose requests time out after d
+// instead of after the client's request timeout, so one client can serve
+// both quick classifications and long generations. Unlike a context
+// deadline, d may be longer than the client's timeout. A d of 0 or less
+// disables the client timeout for these requests, leaving only ctx's
+// deadline. Streaming requests are timed for the whole stream.
+func WithRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, max(d, 0))
+}
+
+// RequestTimeoutFromContext returns the request timeout set on ctx with
+// WithRequestTimeout, and whether one is set.
+func RequestTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	return d, ok
+}
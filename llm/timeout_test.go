@@ -0,0 +1,23 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithRequestTimeout(t *testing.T) {
+	if _, ok := RequestTimeoutFromContext(context.Background()); ok {
+		t.Error("Expected no timeout on a plain context")
+	}
+
+	ctx := WithRequestTimeout(context.Background(), 5*time.Second)
+	if d, ok := RequestTimeoutFromContext(ctx); !ok || d != 5*time.Second {
+		t.Errorf("Expected 5s, got %v (set: %v)", d, ok)
+	}
+
+	ctx = WithRequestTimeout(ctx, -time.Second)
+	if d, ok := RequestTimeoutFromContext(ctx); !ok || d != 0 {
+		t.Errorf("Expected a negative timeout to disable the client timeout, got %v (set: %v)", d, ok)
+	}
+}
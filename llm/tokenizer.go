@@ -0,0 +1,239 @@
+package llm
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens of text for a family of models, locally.
+// Implementations must be safe for concurrent use.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to Tokenizer.
+type TokenizerFunc func(text string) int
+
+// CountTokens calls f.
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// ModelNamer is implemented by clients that report the model they send
+// requests to. All bundled providers implement it.
+type ModelNamer interface {
+	ModelName() string
+}
+
+// pretokenizePattern splits text into the pieces BPE tokenizers merge
+// within: contractions, words with their leading space or punctuation,
+// runs of up to three digits, punctuation runs and whitespace. It follows
+// OpenAI's cl100k pattern without its lookahead, which RE2 lacks, so a run
+// of spaces before a word is one piece rather than two.
+var pretokenizePattern = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// BPEEstimator estimates the tokens of tiktoken-style byte-level BPE
+// tokenizers with large vocabularies, as used by OpenAI-compatible models:
+// common words with their leading space are one token, longer words are
+// split, and digits are grouped by three. Its zero value is ready to use.
+// For exact counts, load the model's vocabulary with LoadTiktoken.
+type BPEEstimator struct{}
+
+// CountTokens implements Tokenizer.
+func (BPEEstimator) CountTokens(text string) int {
+	tokens := 0
+	for _, piece := range pretokenizePattern.FindAllString(text, -1) {
+		tokens += estimatePiece(piece, 8, false)
+	}
+	return tokens
+}
+
+// SentencePieceEstimator estimates the tokens of SentencePiece tokenizers
+// with smaller vocabularies, as used by Llama, Gemma and Mistral models:
+// words are split more often, and digits and line breaks are single
+// tokens. Its zero value is ready to use.
+type SentencePieceEstimator struct{}
+
+// CountTokens implements Tokenizer.
+func (SentencePieceEstimator) CountTokens(text string) int {
+	tokens := 0
+	for _, piece := range pretokenizePattern.FindAllString(text, -1) {
+		tokens += estimatePiece(piece, 5, true)
+	}
+	return tokens
+}
+
+// estimatePiece estimates the tokens of one pretokenized piece. ASCII
+// words take a token per wordLen letters after the first; other letters,
+// such as CJK characters, take a token each. With splitDigits every digit
+// and line break is a token of its own.
+func estimatePiece(piece string, wordLen int, splitDigits bool) int {
+	first, _ := utf8.DecodeRuneInString(piece)
+	switch {
+	case unicode.IsDigit(first):
+		if splitDigits {
+			return utf8.RuneCountInString(piece)
+		}
+		return 1
+	case strings.TrimSpace(piece) == "":
+		if splitDigits {
+			return max(strings.Count(piece, "\n"), 1)
+		}
+		return 1
+	}
+
+	ascii, other := 0, 0
+	for _, r := range piece {
+		switch {
+		case r == ' ':
+		case r < utf8.RuneSelf:
+			ascii++
+		default:
+			other++
+		}
+	}
+	tokens := other
+	if ascii > 0 {
+		tokens += 1 + (ascii-1)/wordLen
+	}
+	return max(tokens, 1)
+}
+
+// BPE is an exact byte-level BPE tokenizer built from a tiktoken
+// vocabulary. Pieces are split with an approximation of OpenAI's cl100k
+// pattern, so counts may differ from tiktoken's by a token around runs of
+// whitespace.
+type BPE struct {
+	ranks map[string]int
+}
+
+// NewBPE returns a tokenizer merging bytes by ranks, which maps each token
+// of the vocabulary to its merge priority, lowest first.
+func NewBPE(ranks map[string]int) *BPE {
+	return &BPE{ranks: ranks}
+}
+
+// LoadTiktoken reads a vocabulary in tiktoken's format, one base64-encoded
+// token and its rank per line, such as cl100k_base.tiktoken or
+// o200k_base.tiktoken.
+func LoadTiktoken(r io.Reader) (*BPE, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid tiktoken vocabulary line %d", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid token on tiktoken vocabulary line %d: %w", line, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank on tiktoken vocabulary line %d: %w", line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tiktoken vocabulary: %w", err)
+	}
+	return NewBPE(ranks), nil
+}
+
+// CountTokens implements Tokenizer.
+func (b *BPE) CountTokens(text string) int {
+	tokens := 0
+	for _, piece := range pretokenizePattern.FindAllString(text, -1) {
+		tokens += len(b.encode(piece))
+	}
+	return tokens
+}
+
+// encode splits piece into the tokens of the vocabulary, by repeatedly
+// merging the adjacent pair with the lowest rank, as tiktoken does.
+func (b *BPE) encode(piece string) []string {
+	if _, ok := b.ranks[piece]; ok {
+		return []string{piece}
+	}
+	parts := make([]string, len(piece))
+	for i := range parts {
+		parts[i] = piece[i : i+1]
+	}
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i < len(parts)-1; i++ {
+			if rank, ok := b.ranks[parts[i]+parts[i+1]]; ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return parts
+}
+
+// defaultTokenizer is used for models no registered prefix matches.
+var defaultTokenizer Tokenizer = TokenizerFunc(EstimateTokens)
+
+var (
+	tokenizersMu sync.RWMutex
+	// tokenizers maps model name prefixes to tokenizers.
+	tokenizers = map[string]Tokenizer{
+		"gpt":      BPEEstimator{},
+		"openai/":  BPEEstimator{},
+		"qwen":     BPEEstimator{},
+		"deepseek": BPEEstimator{},
+		"llama":    SentencePieceEstimator{},
+		"gemma":    SentencePieceEstimator{},
+		"gemini":   SentencePieceEstimator{},
+		"mistral":  SentencePieceEstimator{},
+		"mixtral":  SentencePieceEstimator{},
+	}
+)
+
+// RegisterTokenizer makes t count the tokens of models whose name starts
+// with prefix, ignoring case, or whose name after an organisation such as
+// "meta-llama/" does. The longest matching prefix wins, so a model's own
+// tokenizer can be registered under its full name.
+func RegisterTokenizer(prefix string, t Tokenizer) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	tokenizers[strings.ToLower(prefix)] = t
+}
+
+// TokenizerFor returns the tokenizer registered for model, or a
+// provider-independent one based on EstimateTokens if none matches.
+func TokenizerFor(model string) Tokenizer {
+	name := normalizeModelName(model)
+	base := name[strings.LastIndex(name, "/")+1:]
+
+	tokenizersMu.RLock()
+	defer tokenizersMu.RUnlock()
+	best, found := "", defaultTokenizer
+	for prefix, t := range tokenizers {
+		if len(prefix) > len(best) && (strings.HasPrefix(name, prefix) || strings.HasPrefix(base, prefix)) {
+			best, found = prefix, t
+		}
+	}
+	return found
+}
+
+// CountTokensFor counts the tokens of text with model's tokenizer, without
+// asking the provider. Unknown models fall back to EstimateTokens.
+func CountTokensFor(model, text string) int {
+	return TokenizerFor(model).CountTokens(text)
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBPEEstimator(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"hello", 1},
+		{"Hello world", 2},
+		{"internationalization", 3},
+		{"1234567", 3},
+		{"Hello, world!", 4},
+		{"日本語", 3},
+	}
+	for _, tt := range tests {
+		if got := (BPEEstimator{}).CountTokens(tt.text); got != tt.expected {
+			t.Errorf("BPEEstimator.CountTokens(%q) = %d, expected %d", tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestSentencePieceEstimator(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"hello", 1},
+		{"Hello world", 2},
+		{"internationalization", 4},
+		{"1234567", 7},
+		{"one\n\ntwo", 4},
+	}
+	for _, tt := range tests {
+		if got := (SentencePieceEstimator{}).CountTokens(tt.text); got != tt.expected {
+			t.Errorf("SentencePieceEstimator.CountTokens(%q) = %d, expected %d", tt.text, got, tt.expected)
+		}
+	}
+}
+
+// tiktokenVocabulary encodes tokens in tiktoken's format, ranked in order
+func tiktokenVocabulary(tokens ...string) string {
+	var b strings.Builder
+	for rank, token := range tokens {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+	}
+	return b.String()
+}
+
+func TestLoadTiktoken(t *testing.T) {
+	vocab := tiktokenVocabulary("l", "o", "w", "e", "r", " ", "lo", "low", "er", " low")
+	bpe, err := LoadTiktoken(strings.NewReader(vocab))
+	if err != nil {
+		t.Fatalf("LoadTiktoken failed: %v", err)
+	}
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"low", []string{"low"}},
+		{"lower", []string{"low", "er"}},
+		{" lower", []string{" low", "er"}},
+		{"owl", []string{"o", "w", "l"}},
+	}
+	for _, tt := range tests {
+		if got := bpe.encode(tt.text); strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+			t.Errorf("encode(%q) = %q, expected %q", tt.text, got, tt.expected)
+		}
+	}
+	if n := bpe.CountTokens("low lower"); n != 3 {
+		t.Errorf("Expected 3 tokens, got %d", n)
+	}
+
+	if _, err := LoadTiktoken(strings.NewReader("bG93\n")); err == nil {
+		t.Error("Expected an error for a line without rank")
+	}
+	if _, err := LoadTiktoken(strings.NewReader("!!! 1\n")); err == nil {
+		t.Error("Expected an error for an invalid token")
+	}
+}
+
+func TestTokenizerFor(t *testing.T) {
+	tests := []struct {
+		model    string
+		expected Tokenizer
+	}{
+		{"gpt-4o", BPEEstimator{}},
+		{"openai/gpt-oss-120b", BPEEstimator{}},
+		{"qwen/qwen3-32b", BPEEstimator{}},
+		{"llama-3.3-70b-versatile", SentencePieceEstimator{}},
+		{"meta-llama/llama-4-scout-17b-16e-instruct", SentencePieceEstimator{}},
+		{"gemma:2b", SentencePieceEstimator{}},
+		{"models/gemini-1.5-pro", SentencePieceEstimator{}},
+	}
+	for _, tt := range tests {
+		if got := TokenizerFor(tt.model); got != tt.expected {
+			t.Errorf("TokenizerFor(%q) = %T, expected %T", tt.model, got, tt.expected)
+		}
+	}
+	if got := CountTokensFor("unknown-model", "abcdefgh"); got != EstimateTokens("abcdefgh") {
+		t.Errorf("Expected unknown models to use EstimateTokens, got %d", got)
+	}
+}
+
+func TestRegisterTokenizer(t *testing.T) {
+	exact := TokenizerFunc(func(text string) int { return len(text) })
+	RegisterTokenizer("Llama-3.3-70B", exact)
+	defer func() {
+		tokenizersMu.Lock()
+		delete(tokenizers, "llama-3.3-70b")
+		tokenizersMu.Unlock()
+	}()
+
+	if got := CountTokensFor("llama-3.3-70b-versatile", "abc"); got != 3 {
+		t.Errorf("Expected the longest registered prefix to win, got %d", got)
+	}
+	if got := TokenizerFor("llama-3.1-8b-instant"); got != (SentencePieceEstimator{}) {
+		t.Errorf("Expected other Llama models to keep the estimator, got %T", got)
+	}
+}
@@ -0,0 +1,20 @@
+package llm
+
+import (
+	"unicode/utf8"
+)
+
+// charsPerToken is the average number of characters per token for English
+// text across common BPE and SentencePiece tokenizers.
+const charsPerToken = 4
+
+// EstimateTokens returns a rough, provider-independent estimate of the
+// number of tokens in text. It is intended for budgeting when a provider
+// offers no exact counting endpoint and deliberately errs on the high side.
+func EstimateTokens(text string) int {
+	runes := utf8.RuneCountInString(text)
+	if runes == 0 {
+		return 0
+	}
+	return (runes + charsPerToken - 1) / charsPerToken
+}
@@ -0,0 +1,24 @@
+package llm
+
+import (
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"héllo wörld", 3}, // 11 runes, not 13 bytes
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.expected {
+			t.Errorf("EstimateTokens(%q) = %d, expected %d", tt.text, got, tt.expected)
+		}
+	}
+}
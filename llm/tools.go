@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Tool describes a function the model may ask the caller to run.
+type Tool struct {
+	// Name identifies the function in tool calls, e.g. "get_weather".
+	Name string
+	// Description tells the model what the function does and when to use
+	// it.
+	Description string
+	// Parameters is the JSON Schema of the function's arguments object.
+	// Nil means the function takes no arguments.
+	Parameters json.RawMessage
+}
+
+// ToolCall is a model's request to run one of the offered tools.
+type ToolCall struct {
+	// ID identifies the call, for matching its result in a later turn.
+	ID string
+	// Name is the name of the tool to run.
+	Name string
+	// Arguments is the JSON object of arguments, as generated by the
+	// model. It is not validated against the tool's schema.
+	Arguments json.RawMessage
+}
+
+// ToolChoice controls whether the model calls tools. Besides the constants
+// below, the name of one of the offered tools forces a call to that tool.
+type ToolChoice string
+
+const (
+	// ToolChoiceAuto lets the model decide whether to call tools. It is the
+	// default when ToolChoice is empty.
+	ToolChoiceAuto ToolChoice = "auto"
+	// ToolChoiceNone prevents the model from calling tools.
+	ToolChoiceNone ToolChoice = "none"
+	// ToolChoiceRequired makes the model call at least one tool.
+	ToolChoiceRequired ToolChoice = "required"
+)
+
+// ToolResponse is the result of a generation with tools: text, tool calls
+// or both.
+type ToolResponse struct {
+	// Text is the generated text, often empty when tools are called.
+	Text string
+	// ToolCalls are the tool calls the model made, in order.
+	ToolCalls []ToolCall
+	// FinishReason is the provider's reason for ending the generation,
+	// e.g. "stop" or "tool_calls".
+	FinishReason string
+}
+
+// Message returns the response as an assistant message, to append to the
+// conversation before the results of its tool calls.
+func (r *ToolResponse) Message() Message {
+	return Message{Role: RoleAssistant, Content: r.Text, ToolCalls: r.ToolCalls}
+}
+
+// ToolResultMessage returns the message sending result, the output of
+// running call, back to the model. result is usually JSON.
+func ToolResultMessage(call ToolCall, result string) Message {
+	return Message{Role: RoleTool, Content: result, ToolCallID: call.ID}
+}
+
+// ToolCaller is implemented by clients that support tool calling. The
+// Gemini and Groq clients implement it.
+type ToolCaller interface {
+	// GenerateWithTools sends prompt with tools the model may call.
+	GenerateWithTools(ctx context.Context, prompt string, tools []Tool, choice ToolChoice) (*ToolResponse, error)
+}
+
+// ToolChatter is implemented by clients that support tool calling in
+// conversations, so the model can use the results of its tool calls in its
+// next turn. The Gemini and Groq clients implement it.
+type ToolChatter interface {
+	// ChatWithTools sends messages with tools the model may call. The
+	// messages may include earlier tool calls, as assistant messages, and
+	// their results, as tool messages.
+	ChatWithTools(ctx context.Context, messages []Message, tools []Tool, choice ToolChoice) (*ToolResponse, error)
+}
+
+// ToolNames maps the IDs of the tool calls in messages to the names of the
+// tools called, for providers whose tool results name the tool rather than
+// the call.
+func ToolNames(messages []Message) map[string]string {
+	names := make(map[string]string)
+	for _, m := range messages {
+		for _, call := range m.ToolCalls {
+			names[call.ID] = call.Name
+		}
+	}
+	return names
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Audio is a recording to transcribe.
+type Audio struct {
+	// Filename names the recording, e.g. "meeting.m4a". Providers tell the
+	// format from its extension.
+	Filename string
+	// Data is the encoded recording.
+	Data []byte
+}
+
+// AudioFromFile reads a recording from the file at path.
+func AudioFromFile(path string) (Audio, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Audio{}, fmt.Errorf("failed to read audio: %w", err)
+	}
+	return Audio{Filename: filepath.Base(path), Data: data}, nil
+}
+
+// TranscriptionOptions tune a transcription. The zero value lets the
+// provider detect the language.
+type TranscriptionOptions struct {
+	// Language is the ISO-639-1 code of the spoken language, e.g. "en",
+	// which improves accuracy and latency.
+	Language string
+	// Prompt guides the transcription's style or spelling of names and
+	// terms, or continues an earlier segment.
+	Prompt string
+	// Temperature is the sampling temperature, between 0 and 1.
+	Temperature *float64
+}
+
+// TranscriptionSegment is a stretch of the transcript with its position
+// in the recording.
+type TranscriptionSegment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Transcription is the text of a recording.
+type Transcription struct {
+	Text string
+	// Language is the spoken language, as given or detected, if the
+	// provider reports it.
+	Language string
+	// Duration is the length of the recording, if the provider reports it.
+	Duration time.Duration
+	// Segments split the text by time, if the provider reports them.
+	Segments []TranscriptionSegment
+	// Model is the model that transcribed the recording.
+	Model string
+}
+
+// Transcriber is implemented by clients that transcribe speech. The Groq
+// client implements it with its Whisper models.
+type Transcriber interface {
+	// Transcribe returns the text spoken in audio, using the client's
+	// transcription model.
+	Transcribe(ctx context.Context, audio Audio, opts TranscriptionOptions) (*Transcription, error)
+}
+
+// WithTranscriptionModel sets the model that transcribes speech, which is
+// not the model that generates text.
+func WithTranscriptionModel(model string) ClientOption {
+	return func(o *ClientOptions) {
+		o.TranscriptionModel = model
+	}
+}
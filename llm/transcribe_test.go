@@ -0,0 +1,26 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAudioFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "standup.mp3")
+	if err := os.WriteFile(path, []byte("ID3 audio"), 0o600); err != nil {
+		t.Fatalf("Failed to write audio: %v", err)
+	}
+
+	audio, err := AudioFromFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if audio.Filename != "standup.mp3" || string(audio.Data) != "ID3 audio" {
+		t.Errorf("Unexpected audio %q with data %q", audio.Filename, audio.Data)
+	}
+
+	if _, err := AudioFromFile(filepath.Join(t.TempDir(), "missing.mp3")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,223 @@
+package llm
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportOptions tune the HTTP transport that provider clients share.
+// Zero fields take the value from DefaultTransportOptions.
+type TransportOptions struct {
+	// MaxIdleConns caps idle keep-alive connections across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps idle keep-alive connections to one host.
+	// net/http defaults this to 2, which forces concurrent workers talking
+	// to the same provider to open and discard connections.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an idle connection stays in the pool.
+	IdleConnTimeout time.Duration
+
+	// DialContext, if set, opens the connections instead of a TCP dialer,
+	// e.g. to route traffic through a SOCKS5 proxy or a WireGuard
+	// interface, or to resolve names with a custom resolver. DialTimeout
+	// and KeepAlive are then up to it.
+	DialContext DialFunc
+	// DialTimeout limits how long establishing a TCP connection may take.
+	DialTimeout time.Duration
+	// KeepAlive is the interval between TCP keep-alive probes on open
+	// connections. Negative disables TCP keep-alives.
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout limits how long the TLS handshake may take.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout limits how long to wait for the response
+	// headers after the request was written. Zero means no limit beyond the
+	// client's overall request timeout.
+	ResponseHeaderTimeout time.Duration
+	// DisableKeepAlives closes each connection after one request instead
+	// of reusing it.
+	DisableKeepAlives bool
+	// DisableHTTP2 restricts the transport to HTTP/1.1. By default HTTP/2 is
+	// always attempted over TLS, even though the transport uses a custom
+	// dialer, which would otherwise turn it off.
+	DisableHTTP2 bool
+
+	// DisableCompression stops the transport from asking for gzip-compressed
+	// responses. By default responses are requested with
+	// "Accept-Encoding: gzip" and decompressed transparently.
+	DisableCompression bool
+	// CompressRequestsOver gzips request bodies of at least this many bytes
+	// and sends them with "Content-Encoding: gzip". Zero leaves requests
+	// uncompressed. Only enable it for endpoints that accept compressed
+	// request bodies.
+	CompressRequestsOver int
+}
+
+// DialFunc opens a network connection, like net.Dialer.DialContext.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// DefaultTransportOptions are used for the shared transport unless
+// ConfigureSharedTransport is called.
+var DefaultTransportOptions = TransportOptions{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 32,
+	IdleConnTimeout:     90 * time.Second,
+	DialTimeout:         30 * time.Second,
+	KeepAlive:           30 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
+}
+
+// withDefaults fills zero fields from DefaultTransportOptions.
+func (o TransportOptions) withDefaults() TransportOptions {
+	if o.MaxIdleConns <= 0 {
+		o.MaxIdleConns = DefaultTransportOptions.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = DefaultTransportOptions.MaxIdleConnsPerHost
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = DefaultTransportOptions.IdleConnTimeout
+	}
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = DefaultTransportOptions.DialTimeout
+	}
+	if o.KeepAlive == 0 {
+		o.KeepAlive = DefaultTransportOptions.KeepAlive
+	}
+	if o.TLSHandshakeTimeout <= 0 {
+		o.TLSHandshakeTimeout = DefaultTransportOptions.TLSHandshakeTimeout
+	}
+	return o
+}
+
+// NewTransport returns a transport based on http.DefaultTransport (so
+// proxies from the environment and TLS settings are kept) with the
+// connection pool, timeouts and protocol tuned by opts.
+func NewTransport(opts TransportOptions) *http.Transport {
+	opts = opts.withDefaults()
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = opts.MaxIdleConns
+	t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	t.IdleConnTimeout = opts.IdleConnTimeout
+	t.DialContext = (&net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: opts.KeepAlive,
+	}).DialContext
+	if opts.DialContext != nil {
+		t.DialContext = opts.DialContext
+	}
+	t.TLSHandshakeTimeout = opts.TLSHandshakeTimeout
+	t.ResponseHeaderTimeout = opts.ResponseHeaderTimeout
+	t.DisableKeepAlives = opts.DisableKeepAlives
+	t.DisableCompression = opts.DisableCompression
+	t.ForceAttemptHTTP2 = !opts.DisableHTTP2
+	if opts.DisableHTTP2 {
+		// A non-nil empty map turns off the transport's HTTP/2 support
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return t
+}
+
+// NewRoundTripper returns NewTransport(opts), wrapped to compress request
+// bodies when opts.CompressRequestsOver is set.
+func NewRoundTripper(opts TransportOptions) http.RoundTripper {
+	t := NewTransport(opts)
+	if opts.CompressRequestsOver > 0 {
+		return &gzipTransport{base: t, minBytes: opts.CompressRequestsOver}
+	}
+	return t
+}
+
+var (
+	sharedMu        sync.Mutex
+	sharedTransport http.RoundTripper
+	sharedOptions   = DefaultTransportOptions // What sharedTransport was built with
+)
+
+// SharedTransport returns the transport provider clients use for their
+// HTTP requests. Clients keep their own http.Client, for their own timeout,
+// but pool connections through this one transport, so creating many
+// clients doesn't multiply open connections.
+func SharedTransport() http.RoundTripper {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if sharedTransport == nil {
+		sharedTransport = NewRoundTripper(DefaultTransportOptions)
+	}
+	return sharedTransport
+}
+
+// ConfigureSharedTransport replaces the shared transport with one tuned by
+// opts. Clients created afterwards use the new transport; existing clients
+// keep the old one until they are discarded.
+func ConfigureSharedTransport(opts TransportOptions) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	sharedTransport = NewRoundTripper(opts)
+	sharedOptions = opts
+}
+
+// SharedTransportOptions returns the options the shared transport was
+// built with.
+func SharedTransportOptions() TransportOptions {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	return sharedOptions
+}
+
+// WithTransport makes the client send its requests through rt instead of
+// the shared transport, for deployments that need full control over the
+// connection, such as custom TLS or an instrumented RoundTripper.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(o *ClientOptions) {
+		o.HTTPTransport = rt
+	}
+}
+
+// WithTransportOptions gives the client a transport of its own tuned by
+// opts, e.g. a shorter dial or response header timeout for a latency
+// sensitive client, instead of the shared transport. Zero fields take the
+// value from DefaultTransportOptions.
+func WithTransportOptions(opts TransportOptions) ClientOption {
+	return func(o *ClientOptions) {
+		o.TransportOptions = &opts
+	}
+}
+
+// WithDialer makes the client open its connections with dial, e.g. through
+// a SOCKS5 proxy, a WireGuard interface or a custom DNS resolver, while
+// keeping the other settings of the shared transport. The client gets a
+// connection pool of its own.
+func WithDialer(dial DialFunc) ClientOption {
+	return func(o *ClientOptions) {
+		o.Dialer = dial
+	}
+}
+
+// Transport returns the transport of a client with options o: HTTPTransport
+// if set, else a new transport built from TransportOptions, or the shared
+// transport's options, and dialing with Dialer if either is set, else base,
+// which providers pass as SharedTransport(). It is wrapped to run the
+// request hooks, if any. Providers build their HTTP clients on it.
+func (o ClientOptions) Transport(base http.RoundTripper) http.RoundTripper {
+	switch {
+	case o.HTTPTransport != nil:
+		base = o.HTTPTransport
+	case o.TransportOptions != nil || o.Dialer != nil:
+		opts := SharedTransportOptions()
+		if o.TransportOptions != nil {
+			opts = *o.TransportOptions
+		}
+		if o.Dialer != nil {
+			opts.DialContext = o.Dialer
+		}
+		base = NewRoundTripper(opts)
+	}
+	if len(o.RequestHooks) == 0 {
+		return base
+	}
+	return &hookTransport{hooks: o.RequestHooks, base: base}
+}
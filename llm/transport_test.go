@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewTransport_Defaults(t *testing.T) {
+	tr := NewTransport(TransportOptions{MaxIdleConnsPerHost: 8})
+	if tr.MaxIdleConnsPerHost != 8 {
+		t.Errorf("Expected MaxIdleConnsPerHost 8, got %d", tr.MaxIdleConnsPerHost)
+	}
+	if tr.MaxIdleConns != DefaultTransportOptions.MaxIdleConns || tr.IdleConnTimeout != DefaultTransportOptions.IdleConnTimeout {
+		t.Errorf("Expected unset fields to take defaults, got %d, %v", tr.MaxIdleConns, tr.IdleConnTimeout)
+	}
+	if tr.Proxy == nil {
+		t.Error("Expected proxy settings from http.DefaultTransport to be kept")
+	}
+}
+
+func TestNewTransport_Tuning(t *testing.T) {
+	tr := NewTransport(TransportOptions{
+		ResponseHeaderTimeout: 5 * time.Second,
+		TLSHandshakeTimeout:   time.Second,
+		DisableKeepAlives:     true,
+	})
+	if tr.ResponseHeaderTimeout != 5*time.Second || tr.TLSHandshakeTimeout != time.Second || !tr.DisableKeepAlives {
+		t.Errorf("Expected timeouts and keep-alive setting to be applied, got %+v", tr)
+	}
+	if tr.DialContext == nil {
+		t.Error("Expected a dialer with the configured timeouts")
+	}
+	if !tr.ForceAttemptHTTP2 || tr.TLSNextProto != nil {
+		t.Error("Expected HTTP/2 to be attempted by default")
+	}
+
+	h1 := NewTransport(TransportOptions{DisableHTTP2: true})
+	if h1.ForceAttemptHTTP2 || h1.TLSNextProto == nil || len(h1.TLSNextProto) != 0 {
+		t.Error("Expected HTTP/2 to be disabled")
+	}
+}
+
+func TestSharedTransport(t *testing.T) {
+	first := SharedTransport()
+	if SharedTransport() != first {
+		t.Error("Expected the same transport on every call")
+	}
+
+	ConfigureSharedTransport(TransportOptions{IdleConnTimeout: time.Minute})
+	defer ConfigureSharedTransport(DefaultTransportOptions)
+
+	configured, ok := SharedTransport().(*http.Transport)
+	if !ok || configured == first {
+		t.Fatal("Expected a new transport after configuring")
+	}
+	if configured.IdleConnTimeout != time.Minute {
+		t.Errorf("Expected idle timeout of 1m, got %v", configured.IdleConnTimeout)
+	}
+
+	ConfigureSharedTransport(TransportOptions{CompressRequestsOver: 1024})
+	if _, ok := SharedTransport().(*gzipTransport); !ok {
+		t.Error("Expected request compression to wrap the shared transport")
+	}
+}
+
+func TestClientOptions_Transport_Dialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Resolve a name no DNS server knows, as a custom resolver would
+	var dialed []string
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	ConfigureSharedTransport(TransportOptions{MaxIdleConnsPerHost: 5})
+	defer ConfigureSharedTransport(DefaultTransportOptions)
+
+	transport := ApplyOptions([]ClientOption{WithDialer(dial)}).Transport(SharedTransport())
+	tr, ok := transport.(*http.Transport)
+	if !ok || tr == SharedTransport() || tr.MaxIdleConnsPerHost != 5 {
+		t.Fatalf("Expected a transport of its own with the shared settings, got %v", transport)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get("http://llm.internal:11434/")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if len(dialed) != 1 || dialed[0] != "llm.internal:11434" {
+		t.Errorf("Expected the connection to be opened by the dialer, got %v", dialed)
+	}
+}
+
+func TestClientOptions_Transport_Options(t *testing.T) {
+	ConfigureSharedTransport(TransportOptions{MaxIdleConnsPerHost: 5})
+	defer ConfigureSharedTransport(DefaultTransportOptions)
+
+	opts := TransportOptions{DialTimeout: time.Second, ResponseHeaderTimeout: 5 * time.Second}
+	transport := ApplyOptions([]ClientOption{WithTransportOptions(opts)}).Transport(SharedTransport())
+	tr, ok := transport.(*http.Transport)
+	if !ok || tr == SharedTransport() {
+		t.Fatalf("Expected a transport of its own, got %v", transport)
+	}
+	if tr.ResponseHeaderTimeout != 5*time.Second {
+		t.Errorf("Expected the client's response header timeout, got %v", tr.ResponseHeaderTimeout)
+	}
+	if tr.MaxIdleConnsPerHost != DefaultTransportOptions.MaxIdleConnsPerHost {
+		t.Errorf("Expected unset options to take the defaults, got %d idle conns per host", tr.MaxIdleConnsPerHost)
+	}
+	if SharedTransport().(*http.Transport).ResponseHeaderTimeout != 0 {
+		t.Error("Expected the shared transport to be left alone")
+	}
+}
+
+func TestClientOptions_Transport_Custom(t *testing.T) {
+	custom := &http.Transport{}
+	if got := ApplyOptions([]ClientOption{WithTransport(custom), WithDialer(nil)}).Transport(SharedTransport()); got != custom {
+		t.Errorf("Expected the custom transport, got %v", got)
+	}
+	hooked := ApplyOptions([]ClientOption{WithTransport(custom), WithRequestHook(func(*http.Request) error { return nil })}).Transport(SharedTransport())
+	if ht, ok := hooked.(*hookTransport); !ok || ht.base != custom {
+		t.Errorf("Expected the hooks to wrap the custom transport, got %v", hooked)
+	}
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// modulePath is the import path of the xollm module.
+const modulePath = "github.com/xostack/xollm"
+
+// Version is the xollm version sent in the User-Agent header of provider
+// requests: the module version the binary was built with, or "dev" when it
+// is unknown, e.g. in a checkout of xollm itself.
+var Version = buildVersion()
+
+// buildVersion returns the version of the xollm module in the binary's
+// build information.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	if info.Main.Path == modulePath && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath && dep.Version != "" {
+			return dep.Version
+		}
+	}
+	return "dev"
+}
+
+// appIdentifier is the process-wide application identifier.
+var appIdentifier atomic.Pointer[string]
+
+// SetAppIdentifier sets the application identifier appended to the
+// User-Agent of every request, e.g. "support-bot/2.1", so providers and
+// gateways can attribute the traffic. Clients with their own identifier,
+// see WithAppIdentifier, keep it. "" removes it.
+func SetAppIdentifier(app string) {
+	appIdentifier.Store(&app)
+}
+
+// WithAppIdentifier sets the application identifier of the client's
+// requests instead of the one set with SetAppIdentifier.
+func WithAppIdentifier(app string) ClientOption {
+	return func(o *ClientOptions) {
+		o.AppIdentifier = app
+	}
+}
+
+// UserAgent returns the User-Agent of requests from application app, or
+// from the application set with SetAppIdentifier if app is "":
+// "xollm/<version>", followed by " (<app>)" if there is one.
+func UserAgent(app string) string {
+	if app == "" {
+		if p := appIdentifier.Load(); p != nil {
+			app = *p
+		}
+	}
+	ua := "xollm/" + Version
+	if app != "" {
+		ua += " (" + app + ")"
+	}
+	return ua
+}
+
+// SetUserAgent sets the User-Agent of req to UserAgent(app). A User-Agent
+// already set, such as an SDK's, is kept after it.
+func SetUserAgent(req *http.Request, app string) {
+	ua := UserAgent(app)
+	if existing := req.Header.Get("User-Agent"); existing != "" {
+		ua += " " + existing
+	}
+	req.Header.Set("User-Agent", ua)
+}
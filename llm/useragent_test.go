@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	defer SetAppIdentifier("")
+
+	if got := UserAgent(""); got != "xollm/"+Version {
+		t.Errorf("Expected the bare xollm product, got %q", got)
+	}
+	SetAppIdentifier("support-bot/2.1")
+	if got := UserAgent(""); got != "xollm/"+Version+" (support-bot/2.1)" {
+		t.Errorf("Expected the application identifier, got %q", got)
+	}
+	if got := UserAgent("batch/1.0"); got != "xollm/"+Version+" (batch/1.0)" {
+		t.Errorf("Expected a client's identifier to take precedence, got %q", got)
+	}
+}
+
+func TestSetUserAgent(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	SetUserAgent(req, "app")
+	if got := req.Header.Get("User-Agent"); got != "xollm/"+Version+" (app)" {
+		t.Errorf("Unexpected User-Agent %q", got)
+	}
+
+	req.Header.Set("User-Agent", "genai-go/0.1")
+	SetUserAgent(req, "app")
+	if got := req.Header.Get("User-Agent"); got != "xollm/"+Version+" (app) genai-go/0.1" {
+		t.Errorf("Expected an SDK's User-Agent to be kept, got %q", got)
+	}
+}
+
+func TestBuildVersion(t *testing.T) {
+	if Version == "" {
+		t.Error("Expected a version, at least dev")
+	}
+}
@@ -0,0 +1,57 @@
+package xollm
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// WithLogger makes a client log to l instead of the default logger.
+// Clients log nothing unless given a logger, so applications embedding
+// xollm keep their output clean. Control verbosity with the handler's
+// level; a slog.LevelVar changes it at runtime:
+//
+//	var level slog.LevelVar // Info by default
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: &level}))
+//	client, err := groq.NewClient(ctx, key, "", 60, false, xollm.WithLogger(logger))
+//	// later, e.g. from an admin endpoint
+//	level.Set(slog.LevelDebug)
+//
+// The debugMode argument of NewClient, GetClient and NewPool is
+// deprecated and only kept for compatibility: true is shorthand for
+// WithLogger(DebugLogger()), used when no WithLogger option is given. New
+// code should pass false.
+func WithLogger(l *slog.Logger) ClientOption {
+	return llm.WithLogger(l)
+}
+
+// DebugLogger returns a logger writing debug and higher records to
+// standard error, the replacement for a debugMode of true:
+//
+//	client, err := groq.NewClient(ctx, key, "", 60, false, xollm.WithLogger(xollm.DebugLogger()))
+func DebugLogger() *slog.Logger {
+	return llm.DebugLogger()
+}
+
+// SetDefaultLogger sets the logger of clients created without WithLogger
+// or debugMode, including those already created, e.g. the clients of
+// GetClient and Pool. Nil restores the default, which discards
+// everything.
+func SetDefaultLogger(l *slog.Logger) {
+	llm.SetDefaultLogger(l)
+}
+
+// ContextWithLogger returns a copy of ctx whose requests log to l instead
+// of the client's logger, e.g. to debug a single request or to tag its
+// records with a request ID.
+func ContextWithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return llm.ContextWithLogger(ctx, l)
+}
+
+// Logger returns the logger for work done with ctx: the one set with
+// ContextWithLogger, else the default logger. Packages built on xollm log
+// through it.
+func Logger(ctx context.Context) *slog.Logger {
+	return llm.Logger(ctx, nil)
+}
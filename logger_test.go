@@ -0,0 +1,28 @@
+package xollm
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	defer SetDefaultLogger(nil)
+
+	var out bytes.Buffer
+	SetDefaultLogger(slog.New(slog.NewTextHandler(&out, nil)))
+	Logger(context.Background()).Info("default")
+
+	var requestOut bytes.Buffer
+	ctx := ContextWithLogger(context.Background(), slog.New(slog.NewTextHandler(&requestOut, nil)))
+	Logger(ctx).Info("request")
+
+	if !strings.Contains(out.String(), "msg=default") || strings.Contains(out.String(), "request") {
+		t.Errorf("Unexpected default logger output: %s", out.String())
+	}
+	if !strings.Contains(requestOut.String(), "msg=request") {
+		t.Errorf("Expected the context's logger to be used, got: %s", requestOut.String())
+	}
+}
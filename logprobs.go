@@ -0,0 +1,38 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// TokenLogprob is a generated token and its log probability. See
+// llm.TokenLogprob.
+type TokenLogprob = llm.TokenLogprob
+
+// LogprobGenerator is implemented by clients that report the log
+// probabilities of generated tokens. The Groq client implements it. See
+// llm.LogprobGenerator.
+type LogprobGenerator = llm.LogprobGenerator
+
+// MeanLogprob returns the average log probability of tokens, a simple
+// confidence score for a response.
+func MeanLogprob(tokens []TokenLogprob) float64 {
+	return llm.MeanLogprob(tokens)
+}
+
+// GenerateWithLogprobs is GenerateWithMetadata with the log probability of
+// each generated token in the metadata's Logprobs, and the topLogprobs
+// most likely alternatives at each position if it is more than 0. It
+// fails for clients that don't implement LogprobGenerator.
+//
+//	text, md, err := xollm.GenerateWithLogprobs(ctx, client, "Answer yes or no: ...", 2)
+//	confidence := math.Exp(xollm.MeanLogprob(md.Logprobs))
+func GenerateWithLogprobs(ctx context.Context, client Client, prompt string, topLogprobs int) (string, *ResponseMetadata, error) {
+	lg, ok := client.(LogprobGenerator)
+	if !ok {
+		return "", nil, fmt.Errorf("%s client does not support log probabilities", client.ProviderName())
+	}
+	return lg.GenerateWithLogprobs(ctx, prompt, topLogprobs)
+}
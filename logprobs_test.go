@@ -0,0 +1,36 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// logprobClient reports a log probability for its one token.
+type logprobClient struct {
+	stubClient
+	topLogprobs int
+}
+
+func (c *logprobClient) GenerateWithLogprobs(ctx context.Context, prompt string, topLogprobs int) (string, *llm.ResponseMetadata, error) {
+	c.topLogprobs = topLogprobs
+	return "yes", &llm.ResponseMetadata{Logprobs: []llm.TokenLogprob{{Token: "yes", Logprob: -0.25}}}, nil
+}
+
+func TestGenerateWithLogprobs(t *testing.T) {
+	client := &logprobClient{}
+	text, md, err := GenerateWithLogprobs(context.Background(), client, "prompt", 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != "yes" || client.topLogprobs != 3 || MeanLogprob(md.Logprobs) != -0.25 {
+		t.Errorf("Unexpected response %q with logprobs %+v", text, md.Logprobs)
+	}
+
+	_, _, err = GenerateWithLogprobs(context.Background(), &stubClient{}, "prompt", 0)
+	if err == nil || !strings.Contains(err.Error(), "does not support log probabilities") {
+		t.Errorf("Expected an unsupported error, got: %v", err)
+	}
+}
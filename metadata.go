@@ -0,0 +1,57 @@
+package xollm
+
+import (
+	"context"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// ResponseMetadata describes a completed generation: the model, request
+// ID, finish reason, token usage and, where the provider reports it, a
+// timing breakdown. See llm.ResponseMetadata.
+type ResponseMetadata = llm.ResponseMetadata
+
+// Timing is a provider's breakdown of queue, prompt and completion time
+// for a request. See llm.Timing.
+type Timing = llm.Timing
+
+// MetadataGenerator is implemented by clients that report the metadata of
+// their generations. All bundled providers implement it.
+type MetadataGenerator = llm.MetadataGenerator
+
+// GenerateWithMetadata sends prompt to client and returns the response with
+// its metadata. For clients that don't implement MetadataGenerator the
+// token usage is estimated locally and marked Usage.Estimated.
+func GenerateWithMetadata(ctx context.Context, client Client, prompt string) (string, *ResponseMetadata, error) {
+	if mg, ok := client.(MetadataGenerator); ok {
+		return mg.GenerateWithMetadata(ctx, prompt)
+	}
+	text, err := client.Generate(ctx, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	return text, &ResponseMetadata{
+		Usage: Usage{
+			PromptTokens:     EstimateTokens(prompt),
+			CompletionTokens: EstimateTokens(text),
+			Estimated:        true,
+		},
+	}, nil
+}
+
+// GenerateWithResult is GenerateWithMetadata returning a Result, which adds
+// the provider name and the latency of the call to the text and metadata.
+// The Result is filled in on failure too, with Err set to the returned
+// error, so that failed calls can be logged and timed alike.
+//
+//	result, err := xollm.GenerateWithResult(ctx, client, prompt)
+//	log.Printf("%s %s: %d+%d tokens in %v", result.Provider, result.Metadata.Model,
+//		result.Metadata.Usage.PromptTokens, result.Metadata.Usage.CompletionTokens, result.Latency)
+func GenerateWithResult(ctx context.Context, client Client, prompt string) (Result, error) {
+	result := Result{Provider: client.ProviderName()}
+	start := time.Now()
+	result.Text, result.Metadata, result.Err = GenerateWithMetadata(ctx, client, prompt)
+	result.Latency = time.Since(start)
+	return result, result.Err
+}
@@ -0,0 +1,60 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// metadataClient adds GenerateWithMetadata to stubClient
+type metadataClient struct {
+	stubClient
+	md *ResponseMetadata
+}
+
+func (m *metadataClient) GenerateWithMetadata(ctx context.Context, prompt string) (string, *ResponseMetadata, error) {
+	return m.response, m.md, m.err
+}
+
+func TestGenerateWithMetadata_Native(t *testing.T) {
+	md := &ResponseMetadata{Model: "m", Usage: Usage{PromptTokens: 3, CompletionTokens: 4}}
+	client := &metadataClient{stubClient: stubClient{response: "hi"}, md: md}
+
+	text, got, err := GenerateWithMetadata(context.Background(), client, "prompt")
+	if err != nil || text != "hi" || got != md {
+		t.Errorf("Expected the client's metadata, got %q, %+v, %v", text, got, err)
+	}
+}
+
+func TestGenerateWithMetadata_Estimated(t *testing.T) {
+	client := &stubClient{response: "a response of some length"}
+
+	_, md, err := GenerateWithMetadata(context.Background(), client, "a prompt")
+	if err != nil {
+		t.Fatalf("GenerateWithMetadata failed: %v", err)
+	}
+	if !md.Usage.Estimated || md.Usage.PromptTokens != EstimateTokens("a prompt") || md.Usage.CompletionTokens != EstimateTokens("a response of some length") {
+		t.Errorf("Expected estimated usage, got %+v", md.Usage)
+	}
+
+	failure := errors.New("boom")
+	if _, _, err := GenerateWithMetadata(context.Background(), &stubClient{err: failure}, "p"); !errors.Is(err, failure) {
+		t.Errorf("Expected the generation error, got %v", err)
+	}
+}
+
+func TestGenerateWithResult(t *testing.T) {
+	md := &ResponseMetadata{Model: "m", FinishReason: "stop", Usage: Usage{PromptTokens: 3, CompletionTokens: 4}}
+	client := &metadataClient{stubClient: stubClient{response: "hi"}, md: md}
+
+	result, err := GenerateWithResult(context.Background(), client, "prompt")
+	if err != nil || result.Text != "hi" || result.Metadata != md || result.Provider != "stub" || result.Err != nil {
+		t.Errorf("Unexpected result %+v, %v", result, err)
+	}
+
+	genErr := errors.New("down")
+	result, err = GenerateWithResult(context.Background(), &stubClient{err: genErr}, "prompt")
+	if !errors.Is(err, genErr) || !errors.Is(result.Err, genErr) || result.Provider != "stub" || result.Metadata != nil {
+		t.Errorf("Expected the error in the result too, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,35 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// ContentModerator is implemented by clients that check text against the
+// provider's safety policies. The Gemini client implements it. See
+// llm.ContentModerator.
+type ContentModerator = llm.ContentModerator
+
+// ModerationResult is a provider's safety assessment of a text. See
+// llm.ModerationResult.
+type ModerationResult = llm.ModerationResult
+
+// Moderate rates text with the provider's safety policies, returning the
+// flagged categories and a score per category, so applications can reject
+// user content before accepting it. It fails for clients that don't
+// implement ContentModerator; the moderation package offers moderators for
+// any client, and can put one in front of a client.
+//
+//	result, err := xollm.Moderate(ctx, client, userInput)
+//	if err == nil && result.Flagged {
+//		return fmt.Errorf("message rejected: %v", result.Categories)
+//	}
+func Moderate(ctx context.Context, client Client, text string) (*ModerationResult, error) {
+	m, ok := client.(ContentModerator)
+	if !ok {
+		return nil, fmt.Errorf("%s client does not support moderation", client.ProviderName())
+	}
+	return m.Moderate(ctx, text)
+}
@@ -0,0 +1,42 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// moderatingClient flags texts containing "spam"
+type moderatingClient struct {
+	stubClient
+}
+
+func (c *moderatingClient) Moderate(ctx context.Context, text string) (*ModerationResult, error) {
+	if strings.Contains(text, "spam") {
+		return &ModerationResult{Flagged: true, Categories: []string{"spam"}, Scores: map[string]float64{"spam": 0.75}}, nil
+	}
+	return &ModerationResult{Scores: map[string]float64{"spam": 0}}, nil
+}
+
+func TestModerate(t *testing.T) {
+	client := &moderatingClient{}
+	result, err := Moderate(context.Background(), client, "buy spam now")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !result.Flagged || result.Categories[0] != "spam" {
+		t.Errorf("Expected a flagged result, got %+v", result)
+	}
+
+	result, err = Moderate(context.Background(), client, "hello")
+	if err != nil || result.Flagged {
+		t.Errorf("Expected an unflagged result, got %+v, %v", result, err)
+	}
+}
+
+func TestModerate_Unsupported(t *testing.T) {
+	_, err := Moderate(context.Background(), &stubClient{}, "hello")
+	if err == nil || !strings.Contains(err.Error(), "does not support moderation") {
+		t.Errorf("Expected an unsupported error, got: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLLMModerator_Check(t *testing.T) {
+	client := &mockClient{response: "```json\n" +
+		`{"flagged": true, "categories": ["Violence", "made-up"], "reason": "Describes a fight"}` + "\n```"}
+
+	verdict, err := NewLLM(client).Check(context.Background(), "They fought")
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !verdict.Flagged || verdict.Reason != "Describes a fight" {
+		t.Errorf("Unexpected verdict: %+v", verdict)
+	}
+	if !reflect.DeepEqual(verdict.Categories, []string{"violence"}) {
+		t.Errorf("Expected only known categories, got %v", verdict.Categories)
+	}
+
+	prompt := client.prompts[0]
+	for _, want := range append([]string{"<text>\nThey fought\n</text>"}, DefaultCategories...) {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q", want)
+		}
+	}
+}
+
+func TestLLMModerator_CustomCategories(t *testing.T) {
+	client := &mockClient{response: `{"flagged": false, "categories": []}`}
+	if _, err := NewLLM(client, "spam").Check(context.Background(), "Buy now"); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !strings.Contains(client.prompts[0], "- spam\n") || strings.Contains(client.prompts[0], "violence") {
+		t.Errorf("Expected only the custom categories in the prompt: %s", client.prompts[0])
+	}
+}
+
+func TestLLMModerator_Errors(t *testing.T) {
+	failure := errors.New("model down")
+	if _, err := NewLLM(&mockClient{err: failure}).Check(context.Background(), "x"); !errors.Is(err, failure) {
+		t.Errorf("Expected generation error, got %v", err)
+	}
+	for _, reply := range []string{"It looks fine to me.", `{"flagged": maybe}`} {
+		if _, err := NewLLM(&mockClient{response: reply}).Check(context.Background(), "x"); err == nil {
+			t.Errorf("Expected error for reply %q", reply)
+		}
+	}
+}
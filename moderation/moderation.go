@@ -0,0 +1,154 @@
+// Package moderation checks text against content policies before it reaches
+// a model or a user.
+//
+// A Moderator classifies text and returns a Verdict. OpenAIModerator uses
+// an OpenAI-compatible /moderations endpoint; ProviderModerator uses a
+// client's own safety ratings, such as Gemini's; LLMModerator asks any
+// xollm.Client to classify the text, as a fallback where no moderation
+// endpoint is available. Wrap puts a moderator in front of a client, so the
+// prompt and the response of every call are checked:
+//
+//	moderator := moderation.NewOpenAI("", os.Getenv("OPENAI_API_KEY"), "")
+//	client = moderation.Wrap(client, moderator)
+//
+//	text, err := client.Generate(ctx, userInput)
+//	if errors.Is(err, xollm.ErrContentFiltered) {
+//		// tell the user their message was rejected
+//	}
+//
+// Because the wrapped client is an xollm.Client, it can be handed to
+// conversations, pipelines and the server package's handlers unchanged.
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/xostack/xollm"
+	"github.com/xostack/xollm/llm"
+)
+
+// Verdict is the outcome of a moderation check.
+type Verdict struct {
+	// Flagged is true if the text violates the policy.
+	Flagged bool
+	// Categories lists the categories the text was flagged for, sorted,
+	// e.g. "harassment" or "violence".
+	Categories []string
+	// Scores holds the moderator's confidence per category, from 0 to 1,
+	// where it reports one.
+	Scores map[string]float64
+	// Reason is the moderator's explanation, where it gives one.
+	Reason string
+}
+
+// Moderator checks text against a content policy.
+type Moderator interface {
+	// Check classifies text. A flagged verdict is not an error; errors mean
+	// the text could not be checked.
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+// Client is an xollm.Client whose prompts and responses pass through a
+// Moderator.
+type Client struct {
+	client    xollm.Client
+	moderator Moderator
+}
+
+// Wrap returns client with every prompt checked by moderator before it is
+// sent and every response checked before it is returned. Flagged text is
+// rejected with a *xollm.ContentFilteredError, which matches
+// xollm.ErrContentFiltered, with Stage "prompt" or "response".
+//
+// The wrapped client does not stream: the full response must be checked
+// before any of it is released.
+func Wrap(client xollm.Client, moderator Moderator) *Client {
+	return &Client{client: client, moderator: moderator}
+}
+
+// Generate checks prompt, generates the response and checks it.
+func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
+	if err := c.check(ctx, llm.StagePrompt, prompt); err != nil {
+		return "", err
+	}
+	text, err := c.client.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	if err := c.check(ctx, llm.StageResponse, text); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// ProviderName returns the wrapped client's provider name.
+func (c *Client) ProviderName() string {
+	return c.client.ProviderName()
+}
+
+// Close closes the wrapped client.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// check moderates text at stage, returning an error if it is flagged or
+// can't be checked.
+func (c *Client) check(ctx context.Context, stage, text string) error {
+	verdict, err := c.moderator.Check(ctx, text)
+	if err != nil {
+		return fmt.Errorf("moderation of the %s failed: %w", stage, err)
+	}
+	if verdict.Flagged {
+		return verdict.filteredError(stage)
+	}
+	return nil
+}
+
+// filteredError describes the flagged verdict as a ContentFilteredError
+// for the given stage.
+func (v Verdict) filteredError(stage string) *xollm.ContentFilteredError {
+	ratings := make([]xollm.SafetyRating, 0, len(v.Categories))
+	for _, category := range v.Categories {
+		rating := xollm.SafetyRating{Category: category, Blocked: true}
+		if score, ok := v.Scores[category]; ok {
+			rating.Probability = probability(score)
+		}
+		ratings = append(ratings, rating)
+	}
+	return &xollm.ContentFilteredError{
+		Provider: providerName,
+		Stage:    stage,
+		Reason:   v.Reason,
+		Ratings:  ratings,
+	}
+}
+
+// probability converts a category score to the probability names used in
+// safety ratings.
+func probability(score float64) string {
+	switch {
+	case score >= 0.75:
+		return "High"
+	case score >= 0.5:
+		return "Medium"
+	case score >= 0.25:
+		return "Low"
+	default:
+		return "Negligible"
+	}
+}
+
+// flaggedCategories returns the names of the true entries in categories,
+// sorted.
+func flaggedCategories(categories map[string]bool) []string {
+	var flagged []string
+	for name, ok := range categories {
+		if ok {
+			flagged = append(flagged, name)
+		}
+	}
+	sort.Strings(flagged)
+	return flagged
+}
@@ -0,0 +1,113 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	prompts  []string
+	response string
+	err      error
+	closed   bool
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	return m.response, m.err
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error {
+	m.closed = true
+	return nil
+}
+
+// keywordModerator flags text containing a keyword
+type keywordModerator struct {
+	keyword string
+	err     error
+}
+
+func (k keywordModerator) Check(ctx context.Context, text string) (Verdict, error) {
+	if k.err != nil {
+		return Verdict{}, k.err
+	}
+	if strings.Contains(text, k.keyword) {
+		return Verdict{
+			Flagged:    true,
+			Categories: []string{"violence"},
+			Scores:     map[string]float64{"violence": 0.9},
+			Reason:     "mentions " + k.keyword,
+		}, nil
+	}
+	return Verdict{}, nil
+}
+
+func TestWrap_Allowed(t *testing.T) {
+	client := &mockClient{response: "Hello there"}
+	wrapped := Wrap(client, keywordModerator{keyword: "attack"})
+
+	text, err := wrapped.Generate(context.Background(), "Say hello")
+	if err != nil || text != "Hello there" {
+		t.Errorf("Expected the response, got %q, %v", text, err)
+	}
+	if wrapped.ProviderName() != "mock" {
+		t.Errorf("Expected the wrapped provider name, got %q", wrapped.ProviderName())
+	}
+	if err := wrapped.Close(); err != nil || !client.closed {
+		t.Errorf("Expected the wrapped client to be closed, got %v", err)
+	}
+}
+
+func TestWrap_FlaggedPrompt(t *testing.T) {
+	client := &mockClient{response: "ok"}
+	_, err := Wrap(client, keywordModerator{keyword: "attack"}).Generate(context.Background(), "Plan an attack")
+
+	var filtered *xollm.ContentFilteredError
+	if !errors.As(err, &filtered) || !errors.Is(err, xollm.ErrContentFiltered) {
+		t.Fatalf("Expected ContentFilteredError, got %v", err)
+	}
+	if filtered.Stage != "prompt" || filtered.Reason != "mentions attack" {
+		t.Errorf("Unexpected filter details: %+v", filtered)
+	}
+	if len(filtered.Ratings) != 1 || filtered.Ratings[0].Probability != "High" || !filtered.Ratings[0].Blocked {
+		t.Errorf("Unexpected ratings: %+v", filtered.Ratings)
+	}
+	if len(client.prompts) != 0 {
+		t.Error("Expected the flagged prompt not to be sent")
+	}
+}
+
+func TestWrap_FlaggedResponse(t *testing.T) {
+	client := &mockClient{response: "Here is how to attack"}
+	_, err := Wrap(client, keywordModerator{keyword: "attack"}).Generate(context.Background(), "Tell me a story")
+
+	var filtered *xollm.ContentFilteredError
+	if !errors.As(err, &filtered) || filtered.Stage != "response" {
+		t.Errorf("Expected the response to be filtered, got %v", err)
+	}
+}
+
+func TestWrap_ModeratorError(t *testing.T) {
+	failure := errors.New("moderator down")
+	_, err := Wrap(&mockClient{}, keywordModerator{err: failure}).Generate(context.Background(), "Hi")
+	if !errors.Is(err, failure) || errors.Is(err, xollm.ErrContentFiltered) {
+		t.Errorf("Expected the moderator error, got %v", err)
+	}
+}
+
+func TestProbability(t *testing.T) {
+	tests := map[float64]string{0: "Negligible", 0.3: "Low", 0.5: "Medium", 0.99: "High"}
+	for score, want := range tests {
+		if got := probability(score); got != want {
+			t.Errorf("probability(%g) = %q, want %q", score, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// DefaultOpenAIEndpoint is OpenAI's moderation endpoint.
+const DefaultOpenAIEndpoint = "https://api.openai.com/v1/moderations"
+
+// providerName is the Error.Provider of failed moderation requests.
+const providerName = "moderation"
+
+// opModerate is the Error.Op of failed moderation requests.
+const opModerate = "moderate"
+
+// openAITimeout bounds a moderation request.
+const openAITimeout = 30 * time.Second
+
+// OpenAIModerator checks text with an OpenAI-compatible moderation
+// endpoint. It is safe for concurrent use.
+type OpenAIModerator struct {
+	endpoint   string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAI returns a moderator using the moderation endpoint at endpoint,
+// or DefaultOpenAIEndpoint if empty. An empty apiKey sends no
+// Authorization header, for self-hosted endpoints; an empty model uses the
+// endpoint's default model.
+func NewOpenAI(endpoint, apiKey, model string) *OpenAIModerator {
+	if endpoint == "" {
+		endpoint = DefaultOpenAIEndpoint
+	}
+	return &OpenAIModerator{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		model:    model,
+		httpClient: &http.Client{
+			Timeout:   openAITimeout,
+			Transport: llm.SharedTransport(), // Pool connections with the provider clients
+		},
+	}
+}
+
+// openAIModerationRequest is the request body for the moderation endpoint.
+type openAIModerationRequest struct {
+	Input string `json:"input"`
+	Model string `json:"model,omitempty"`
+}
+
+// openAIModerationResponse is the response body of the moderation
+// endpoint.
+type openAIModerationResponse struct {
+	Model   string `json:"model"`
+	Results []struct {
+		Flagged        bool               `json:"flagged"`
+		Categories     map[string]bool    `json:"categories"`
+		CategoryScores map[string]float64 `json:"category_scores"`
+	} `json:"results"`
+	Error *openAIError `json:"error,omitempty"`
+}
+
+// openAIError is the error object of a failed request.
+type openAIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+}
+
+// Check implements Moderator.
+func (m *OpenAIModerator) Check(ctx context.Context, text string) (Verdict, error) {
+	payload, err := json.Marshal(openAIModerationRequest{Input: text, Model: m.model})
+	if err != nil {
+		return Verdict{}, m.opError(fmt.Errorf("failed to marshal request: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return Verdict{}, m.opError(fmt.Errorf("failed to create request: %w", err))
+	}
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	llm.SetUserAgent(req, "")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return Verdict{}, m.opError(fmt.Errorf("failed to send request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	// Decode the response straight from the body, keeping its start for
+	// error reports
+	var modResp openAIModerationResponse
+	body, err := llm.DecodeJSON(resp.Body, &modResp)
+	if err != nil {
+		if resp.StatusCode != http.StatusOK {
+			// Error responses from proxies and gateways are often not JSON
+			return Verdict{}, m.newAPIError(resp, body, nil)
+		}
+		decodeErr := m.opError(fmt.Errorf("failed to decode response: %w", err))
+		decodeErr.StatusCode, decodeErr.Body = resp.StatusCode, body
+		return Verdict{}, decodeErr
+	}
+	if modResp.Error != nil || resp.StatusCode != http.StatusOK {
+		return Verdict{}, m.newAPIError(resp, body, modResp.Error)
+	}
+	if len(modResp.Results) == 0 {
+		e := m.opError(fmt.Errorf("response contained no results"))
+		e.StatusCode, e.Body = resp.StatusCode, body
+		return Verdict{}, e
+	}
+
+	result := modResp.Results[0]
+	return Verdict{
+		Flagged:    result.Flagged,
+		Categories: flaggedCategories(result.Categories),
+		Scores:     result.CategoryScores,
+	}, nil
+}
+
+// newAPIError describes a failed moderation response, including the error
+// object from the body when there is one.
+func (m *OpenAIModerator) newAPIError(resp *http.Response, body string, apiErr *openAIError) *llm.Error {
+	e := &llm.Error{
+		Provider:   providerName,
+		Op:         opModerate,
+		Endpoint:   m.endpoint,
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Body:       body,
+		Kind:       llm.StatusError(resp.StatusCode),
+	}
+	if apiErr != nil {
+		e.Message, e.Type, e.Code = apiErr.Message, apiErr.Type, apiErr.Code
+	}
+	return e
+}
+
+// opError describes a moderation request that failed without an API error
+// response.
+func (m *OpenAIModerator) opError(err error) *llm.Error {
+	return llm.NewError(providerName, opModerate, m.endpoint, err)
+}
@@ -0,0 +1,104 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+func TestOpenAIModerator_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("Unexpected Authorization header: %q", r.Header.Get("Authorization"))
+		}
+		var req openAIModerationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Input != "some text" || req.Model != "omni-moderation-latest" {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+		w.Write([]byte(`{"model": "omni-moderation-latest", "results": [{
+			"flagged": true,
+			"categories": {"violence": true, "hate": false, "harassment": true},
+			"category_scores": {"violence": 0.91, "hate": 0.01, "harassment": 0.6}
+		}]}`))
+	}))
+	defer server.Close()
+
+	verdict, err := NewOpenAI(server.URL, "key", "omni-moderation-latest").Check(context.Background(), "some text")
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !verdict.Flagged {
+		t.Error("Expected the text to be flagged")
+	}
+	if !reflect.DeepEqual(verdict.Categories, []string{"harassment", "violence"}) {
+		t.Errorf("Unexpected categories: %v", verdict.Categories)
+	}
+	if verdict.Scores["violence"] != 0.91 {
+		t.Errorf("Unexpected scores: %v", verdict.Scores)
+	}
+}
+
+func TestOpenAIModerator_NoAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header["Authorization"]; ok {
+			t.Error("Expected no Authorization header")
+		}
+		w.Write([]byte(`{"results": [{"flagged": false, "categories": {}, "category_scores": {}}]}`))
+	}))
+	defer server.Close()
+
+	verdict, err := NewOpenAI(server.URL, "", "").Check(context.Background(), "hi")
+	if err != nil || verdict.Flagged {
+		t.Errorf("Expected an unflagged verdict, got %+v, %v", verdict, err)
+	}
+}
+
+func TestOpenAIModerator_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{"api error", http.StatusUnauthorized, `{"error": {"message": "Incorrect API key", "type": "invalid_request_error", "code": "invalid_api_key"}}`, xollm.ErrAuthentication},
+		{"not json", http.StatusBadGateway, `<html>Bad Gateway</html>`, xollm.ErrUnavailable},
+		{"rate limited", http.StatusTooManyRequests, `{"error": {"message": "slow down"}}`, xollm.ErrRateLimited},
+		{"no results", http.StatusOK, `{"results": []}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewOpenAI(server.URL, "key", "").Check(context.Background(), "hi")
+			var apiErr *xollm.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *xollm.Error, got %v", err)
+			}
+			if apiErr.Provider != "moderation" || apiErr.Op != "moderate" || apiErr.StatusCode != tt.status {
+				t.Errorf("Unexpected error details: %+v", apiErr)
+			}
+			if tt.kind != nil && !errors.Is(err, tt.kind) {
+				t.Errorf("Expected %v, got %v", tt.kind, err)
+			}
+		})
+	}
+}
+
+func TestNewOpenAI_DefaultEndpoint(t *testing.T) {
+	if m := NewOpenAI("", "key", ""); m.endpoint != DefaultOpenAIEndpoint {
+		t.Errorf("Expected default endpoint, got %q", m.endpoint)
+	}
+}
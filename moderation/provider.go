@@ -0,0 +1,36 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm"
+)
+
+// ProviderModerator checks text with a client's own safety ratings, such as
+// Gemini's, see xollm.Moderate.
+type ProviderModerator struct {
+	client xollm.Client
+}
+
+// NewProvider returns a moderator using the native moderation of client,
+// which must implement xollm.ContentModerator.
+func NewProvider(client xollm.Client) (*ProviderModerator, error) {
+	if _, ok := client.(xollm.ContentModerator); !ok {
+		return nil, fmt.Errorf("%s client does not support moderation", client.ProviderName())
+	}
+	return &ProviderModerator{client: client}, nil
+}
+
+// Check implements Moderator.
+func (m *ProviderModerator) Check(ctx context.Context, text string) (Verdict, error) {
+	result, err := xollm.Moderate(ctx, m.client, text)
+	if err != nil {
+		return Verdict{}, err
+	}
+	return Verdict{
+		Flagged:    result.Flagged,
+		Categories: result.Categories,
+		Scores:     result.Scores,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+// ratingClient reports fixed native moderation results
+type ratingClient struct {
+	mockClient
+	result *xollm.ModerationResult
+	err    error
+}
+
+func (c *ratingClient) Moderate(ctx context.Context, text string) (*xollm.ModerationResult, error) {
+	return c.result, c.err
+}
+
+func TestProviderModerator_Check(t *testing.T) {
+	client := &ratingClient{result: &xollm.ModerationResult{
+		Flagged:    true,
+		Categories: []string{"harassment"},
+		Scores:     map[string]float64{"harassment": 0.75, "hate": 0.25},
+	}}
+	moderator, err := NewProvider(client)
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	verdict, err := moderator.Check(context.Background(), "You are all idiots")
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !verdict.Flagged || !reflect.DeepEqual(verdict.Categories, []string{"harassment"}) || verdict.Scores["hate"] != 0.25 {
+		t.Errorf("Unexpected verdict: %+v", verdict)
+	}
+
+	// The verdict feeds Wrap like any other moderator's
+	_, err = Wrap(&mockClient{response: "ok"}, moderator).Generate(context.Background(), "You are all idiots")
+	if !errors.Is(err, xollm.ErrContentFiltered) {
+		t.Errorf("Expected the wrapped client to reject the prompt, got %v", err)
+	}
+}
+
+func TestProviderModerator_Errors(t *testing.T) {
+	if _, err := NewProvider(&mockClient{}); err == nil {
+		t.Error("Expected an error for a client without native moderation")
+	}
+
+	failure := errors.New("quota exceeded")
+	moderator, err := NewProvider(&ratingClient{err: failure})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if _, err := moderator.Check(context.Background(), "x"); !errors.Is(err, failure) {
+		t.Errorf("Expected the provider's error, got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package ollama
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+const (
+	// minHostBackoff and maxHostBackoff bound how long a failing host of a
+	// balanced client is skipped. The back-off doubles with each
+	// consecutive failure.
+	minHostBackoff = time.Second
+	maxHostBackoff = 30 * time.Second
+)
+
+// hostPool balances the requests of a client created with several base
+// URLs. Requests go round-robin over the healthy hosts, or with sticky
+// sessions to the host their session key hashes to. A host that can't be
+// reached or answers with a server error is skipped until its back-off
+// ends; if every host is backing off, they are tried in the order they
+// recover.
+type hostPool struct {
+	hosts []*poolHost
+	next  atomic.Uint64
+	now   func() time.Time
+
+	mu sync.Mutex // guards the hosts' health
+}
+
+// poolHost is one server of a pool and its health.
+type poolHost struct {
+	url       string
+	failures  int // consecutive failures
+	downUntil time.Time
+}
+
+// HostStatus is the health of one host of a balanced client.
+type HostStatus struct {
+	BaseURL string
+	// Healthy is false while the host is skipped after failing.
+	Healthy bool
+	// Failures is the number of consecutive failed requests.
+	Failures int
+	// RetryAt is when a host that isn't healthy is tried again.
+	RetryAt time.Time
+}
+
+// newHostPool returns a pool of the hosts at urls, all healthy.
+func newHostPool(urls []string) *hostPool {
+	p := &hostPool{now: time.Now}
+	for _, u := range urls {
+		p.hosts = append(p.hosts, &poolHost{url: u})
+	}
+	return p
+}
+
+// order returns the base URLs in the order a request should try them:
+// healthy hosts first, then the others by when their back-off ends.
+// Without a key, healthy hosts take turns being first; with a key they are
+// ranked by rendezvous hashing, so the key stays on one host while it is
+// healthy and only the keys of a failed host move.
+func (p *hostPool) order(key string) []string {
+	n := len(p.hosts)
+	ranked := make([]*poolHost, n)
+	if key == "" {
+		start := int(p.next.Add(1)-1) % n
+		for i := range ranked {
+			ranked[i] = p.hosts[(start+i)%n]
+		}
+	} else {
+		copy(ranked, p.hosts)
+		weights := make(map[*poolHost]uint64, n)
+		for _, h := range ranked {
+			hash := fnv.New64a()
+			hash.Write([]byte(key))
+			hash.Write([]byte{0})
+			hash.Write([]byte(h.url))
+			weights[h] = hash.Sum64()
+		}
+		slices.SortFunc(ranked, func(a, b *poolHost) int {
+			return compareDesc(weights[a], weights[b])
+		})
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	slices.SortStableFunc(ranked, func(a, b *poolHost) int {
+		aDown, bDown := now.Before(a.downUntil), now.Before(b.downUntil)
+		switch {
+		case aDown && bDown:
+			return a.downUntil.Compare(b.downUntil)
+		case aDown:
+			return 1
+		case bDown:
+			return -1
+		}
+		return 0
+	})
+	urls := make([]string, n)
+	for i, h := range ranked {
+		urls[i] = h.url
+	}
+	return urls
+}
+
+// compareDesc orders a before b if it is larger.
+func compareDesc(a, b uint64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
+
+// report records the outcome of a request to the host at url.
+func (p *hostPool) report(url string, healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, h := range p.hosts {
+		if h.url != url {
+			continue
+		}
+		if healthy {
+			h.failures, h.downUntil = 0, time.Time{}
+			return
+		}
+		h.failures++
+		backoff := min(minHostBackoff<<min(h.failures-1, 16), maxHostBackoff)
+		h.downUntil = p.now().Add(backoff)
+		return
+	}
+}
+
+// status returns the health of every host, in configuration order.
+func (p *hostPool) status() []HostStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	statuses := make([]HostStatus, len(p.hosts))
+	for i, h := range p.hosts {
+		statuses[i] = HostStatus{BaseURL: h.url, Healthy: !now.Before(h.downUntil), Failures: h.failures}
+		if !statuses[i].Healthy {
+			statuses[i].RetryAt = h.downUntil
+		}
+	}
+	return statuses
+}
+
+// candidates returns the clients to try a request with, one per host in
+// the order to try them. A client with a single host returns itself.
+func (c *Client) candidates(ctx context.Context) []*Client {
+	if c.hosts == nil || c.pinned {
+		return []*Client{c}
+	}
+	key := ""
+	if c.options.StickySessions {
+		key = llm.SessionKeyFromContext(ctx)
+	}
+	urls := c.hosts.order(key)
+	clients := make([]*Client, len(urls))
+	for i, u := range urls {
+		clients[i] = c.onHost(u)
+	}
+	return clients
+}
+
+// onHost returns a copy of c sending to the host at baseURL. The copy
+// still reports to c's pool, if any.
+func (c *Client) onHost(baseURL string) *Client {
+	d := *c
+	d.baseURL = baseURL
+	return &d
+}
+
+// Hosts returns the health of each server of a client created with
+// NewBalancedClient, in the order the base URLs were given. A client with
+// a single server reports it as healthy.
+func (c *Client) Hosts() []HostStatus {
+	if c.hosts == nil {
+		return []HostStatus{{BaseURL: c.baseURL, Healthy: true}}
+	}
+	return c.hosts.status()
+}
+
+// WithHost returns a client sending every request to the server at
+// baseURL, one of the client's Hosts, e.g. to pull a model onto each server
+// of a balanced client. Its requests still update the host's health.
+func (c *Client) WithHost(baseURL string) *Client {
+	d := c.onHost(baseURL)
+	if c.hosts != nil && !slices.ContainsFunc(c.hosts.hosts, func(h *poolHost) bool { return h.url == baseURL }) {
+		d.hosts = nil
+	}
+	d.pinned = true
+	return d
+}
@@ -0,0 +1,207 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// countingServer answers generate requests with its name and counts them
+type countingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests int
+}
+
+func newCountingServer(t *testing.T, name string, status int) *countingServer {
+	s := &countingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		s.mu.Unlock()
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":"overloaded"}`))
+			return
+		}
+		w.Write([]byte(`{"model":"test-model","response":"` + name + `","done":true,"context":[1,2]}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *countingServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// deadURL returns the address of a server that refuses connections
+func deadURL(t *testing.T) string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestNewBalancedClient(t *testing.T) {
+	client, err := NewBalancedClient(context.Background(), []string{"http://a:11434/", "http://b:11434", "http://a:11434"}, "", 30, false)
+	if err != nil {
+		t.Fatalf("NewBalancedClient failed: %v", err)
+	}
+	hosts := client.Hosts()
+	if len(hosts) != 2 || hosts[0].BaseURL != "http://a:11434" || hosts[1].BaseURL != "http://b:11434" || !hosts[0].Healthy {
+		t.Errorf("Expected two deduplicated healthy hosts, got %+v", hosts)
+	}
+
+	if _, err := NewBalancedClient(context.Background(), nil, "", 30, false); err == nil {
+		t.Error("Expected an error without base URLs")
+	}
+	if _, err := NewBalancedClient(context.Background(), []string{"http://a:11434", "ftp://b"}, "", 30, false); err == nil {
+		t.Error("Expected an error for an invalid base URL")
+	}
+
+	single, _ := NewClient(context.Background(), "http://localhost:11434", "", 30, false)
+	if hosts := single.Hosts(); len(hosts) != 1 || !hosts[0].Healthy {
+		t.Errorf("Expected a single healthy host, got %+v", hosts)
+	}
+}
+
+func TestBalancedClient_RoundRobin(t *testing.T) {
+	a := newCountingServer(t, "a", http.StatusOK)
+	b := newCountingServer(t, "b", http.StatusOK)
+	client, err := NewBalancedClient(context.Background(), []string{a.URL, b.URL}, "test-model", 30, false)
+	if err != nil {
+		t.Fatalf("NewBalancedClient failed: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := client.Generate(context.Background(), "hi"); err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+	}
+	if a.count() != 2 || b.count() != 2 {
+		t.Errorf("Expected the requests to alternate, got %d and %d", a.count(), b.count())
+	}
+}
+
+func TestBalancedClient_SkipsFailedHosts(t *testing.T) {
+	dead := deadURL(t)
+	failing := newCountingServer(t, "failing", http.StatusServiceUnavailable)
+	healthy := newCountingServer(t, "healthy", http.StatusOK)
+	client, err := NewBalancedClient(context.Background(), []string{dead, failing.URL, healthy.URL}, "test-model", 30, false)
+	if err != nil {
+		t.Fatalf("NewBalancedClient failed: %v", err)
+	}
+
+	// An unreachable server is retried on the next; a server error is
+	// returned, but the server is skipped afterwards
+	for i := 0; i < 3; i++ {
+		client.Generate(context.Background(), "hi")
+	}
+	if failing.count() != 1 {
+		t.Errorf("Expected the failing server to be tried once, got %d", failing.count())
+	}
+	for i := 0; i < 5; i++ {
+		text, err := client.Generate(context.Background(), "hi")
+		if err != nil || text != "healthy" {
+			t.Fatalf("Expected the healthy server to answer, got %q, %v", text, err)
+		}
+	}
+	if failing.count() != 1 {
+		t.Errorf("Expected the failing server to be skipped, got %d requests", failing.count())
+	}
+
+	hosts := client.Hosts()
+	if hosts[0].Healthy || hosts[0].Failures == 0 || hosts[0].RetryAt.IsZero() || hosts[1].Healthy || !hosts[2].Healthy {
+		t.Errorf("Unexpected host health: %+v", hosts)
+	}
+}
+
+func TestBalancedClient_AllHostsDown(t *testing.T) {
+	client, err := NewBalancedClient(context.Background(), []string{deadURL(t), deadURL(t)}, "test-model", 30, false)
+	if err != nil {
+		t.Fatalf("NewBalancedClient failed: %v", err)
+	}
+	_, err = client.Generate(context.Background(), "hi")
+	if !errors.Is(err, llm.ErrUnavailable) {
+		t.Errorf("Expected an unavailable error, got: %v", err)
+	}
+}
+
+func TestBalancedClient_StickySessions(t *testing.T) {
+	servers := []*countingServer{
+		newCountingServer(t, "a", http.StatusOK),
+		newCountingServer(t, "b", http.StatusOK),
+		newCountingServer(t, "c", http.StatusOK),
+	}
+	client, err := NewBalancedClient(context.Background(), []string{servers[0].URL, servers[1].URL, servers[2].URL}, "test-model", 30, false, llm.WithStickySessions())
+	if err != nil {
+		t.Fatalf("NewBalancedClient failed: %v", err)
+	}
+
+	ctx := llm.WithSessionKey(context.Background(), "chat-42")
+	first, _ := client.Generate(ctx, "hi")
+	for i := 0; i < 5; i++ {
+		if text, _ := client.Generate(ctx, "hi"); text != first {
+			t.Fatalf("Expected requests of one session to stay on %q, got %q", first, text)
+		}
+	}
+
+	session := client.NewSession()
+	turn, _ := session.Generate(context.Background(), "hi")
+	for i := 0; i < 5; i++ {
+		if text, _ := session.Generate(context.Background(), "hi"); text != turn {
+			t.Fatalf("Expected the session to stay on %q, got %q", turn, text)
+		}
+	}
+}
+
+func TestBalancedClient_WithHost(t *testing.T) {
+	a := newCountingServer(t, "a", http.StatusOK)
+	b := newCountingServer(t, "b", http.StatusOK)
+	client, err := NewBalancedClient(context.Background(), []string{a.URL, b.URL}, "test-model", 30, false)
+	if err != nil {
+		t.Fatalf("NewBalancedClient failed: %v", err)
+	}
+	pinned := client.WithHost(b.URL)
+	for i := 0; i < 3; i++ {
+		if text, err := pinned.Generate(context.Background(), "hi"); err != nil || text != "b" {
+			t.Fatalf("Expected the pinned server to answer, got %q, %v", text, err)
+		}
+	}
+}
+
+func TestHostPool_Backoff(t *testing.T) {
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, time.UTC)
+	pool := newHostPool([]string{"http://a", "http://b"})
+	pool.now = func() time.Time { return now }
+
+	pool.report("http://a", false)
+	pool.report("http://a", false)
+	if order := pool.order(""); order[0] != "http://b" {
+		t.Errorf("Expected the failed host last, got %v", order)
+	}
+	if status := pool.status(); status[0].RetryAt != now.Add(2*minHostBackoff) {
+		t.Errorf("Expected the back-off to double, got %+v", status[0])
+	}
+
+	// Once the back-off ends the host takes turns again
+	now = now.Add(3 * time.Second)
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		seen[pool.order("")[0]] = true
+	}
+	if !seen["http://a"] || !seen["http://b"] {
+		t.Errorf("Expected both hosts to take turns, got %v", seen)
+	}
+
+	pool.report("http://a", true)
+	if status := pool.status(); !status[0].Healthy || status[0].Failures != 0 {
+		t.Errorf("Expected a success to reset the host, got %+v", status[0])
+	}
+}
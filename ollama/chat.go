@@ -0,0 +1,79 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// ollamaChatMessage is a message of a request to or response from
+// Ollama's /api/chat.
+type ollamaChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Images are base64-encoded images for vision models
+	Images []string `json:"images,omitempty"`
+}
+
+// ollamaChatRequest is the structure for the request body to Ollama's /api/chat.
+type ollamaChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []ollamaChatMessage    `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+// ollamaChatResponse is the structure for the response from Ollama's
+// /api/chat. Apart from the message replacing the response text, it
+// matches the response of /api/generate.
+type ollamaChatResponse struct {
+	ollamaGenerateResponse
+	Message ollamaChatMessage `json:"message"`
+}
+
+// Chat implements xollm.Chatter, sending the messages with their roles to
+// /api/chat, which applies the model's chat template. The client's system
+// prompt, if any, is sent as the first message.
+func (c *Client) Chat(ctx context.Context, messages []llm.Message) (string, *llm.ResponseMetadata, error) {
+	if c.httpClient == nil {
+		return "", nil, fmt.Errorf("Ollama client not initialized")
+	}
+	if err := llm.CheckMessages(messages); err != nil {
+		return "", nil, err
+	}
+	if err := c.preflight(llm.FlattenMessages(messages)); err != nil {
+		return "", nil, err
+	}
+	messages, err := llm.LoadImages(ctx, messages)
+	if err != nil {
+		return "", nil, err
+	}
+
+	payload := ollamaChatRequest{
+		Model:   c.modelName,
+		Stream:  false,
+		Options: c.requestOptions(llm.Sampling{}),
+	}
+	if c.options.SystemPrompt != "" {
+		payload.Messages = append(payload.Messages, ollamaChatMessage{Role: string(llm.RoleSystem), Content: c.options.SystemPrompt})
+	}
+	for _, m := range messages {
+		payload.Messages = append(payload.Messages, ollamaChatMessage{Role: string(m.Role), Content: m.Content, Images: encodeImages(m.Images)})
+	}
+
+	var ollamaResp ollamaChatResponse
+	responseBody, hc, err := c.doJSON(ctx, http.MethodPost, llm.OpGenerate, chatAPIPath, payload, &ollamaResp)
+	if err != nil {
+		return "", nil, llm.WrapContextLength(c.modelName, err)
+	}
+	if ollamaResp.Error != "" {
+		return "", nil, llm.WrapContextLength(c.modelName, hc.newAPIError(llm.OpGenerate, chatAPIPath, http.StatusOK, ollamaResp.Error, responseBody))
+	}
+	if !ollamaResp.Done && ollamaResp.Message.Content == "" {
+		return "", nil, hc.newAPIError(llm.OpGenerate, chatAPIPath, http.StatusOK, "response indicates not done but no message was returned", responseBody)
+	}
+	return strings.TrimSpace(ollamaResp.Message.Content), ollamaResp.metadata(), nil
+}
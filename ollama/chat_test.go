@@ -0,0 +1,94 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestOllamaClient_Chat(t *testing.T) {
+	var sent ollamaChatRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != chatAPIPath {
+			t.Errorf("Expected path %s, got %s", chatAPIPath, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"model": "gemma:2b",
+			"message": {"role": "assistant", "content": " Stockholm. "},
+			"done": true,
+			"done_reason": "stop",
+			"prompt_eval_count": 20,
+			"eval_count": 3
+		}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	text, md, err := client.Chat(context.Background(), []llm.Message{
+		{Role: llm.RoleSystem, Content: "Be terse."},
+		{Role: llm.RoleUser, Content: "Capital of Norway?"},
+		{Role: llm.RoleAssistant, Content: "Oslo."},
+		{Role: llm.RoleUser, Content: "And of Sweden?"},
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if text != "Stockholm." || md.FinishReason != "stop" || md.Usage.PromptTokens != 20 || md.Usage.CompletionTokens != 3 {
+		t.Errorf("Unexpected reply %q, %+v", text, md)
+	}
+	if sent.Stream || len(sent.Messages) != 4 || sent.Messages[0].Role != "system" || sent.Messages[2].Role != "assistant" || sent.Messages[3].Content != "And of Sweden?" {
+		t.Errorf("Unexpected request: %+v", sent)
+	}
+}
+
+func TestOllamaClient_Chat_Errors(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "model 'missing' not found"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "missing", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, _, err := client.Chat(context.Background(), []llm.Message{{Role: llm.RoleUser, Content: "Hi"}}); !errors.Is(err, llm.ErrModelNotFound) {
+		t.Errorf("Expected a model not found error, got: %v", err)
+	}
+	if _, _, err := client.Chat(context.Background(), nil); err == nil {
+		t.Error("Expected an error for an empty chat")
+	}
+}
+
+func TestOllamaClient_ChatImages(t *testing.T) {
+	var sent ollamaChatRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model": "llava", "message": {"role": "assistant", "content": "A cat."}, "done": true}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "llava", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	image := llm.ImageFromURL("data:image/png;base64,cG5n")
+	if _, _, err := client.Chat(context.Background(), []llm.Message{{Role: llm.RoleUser, Content: "What is this?", Images: []llm.Image{image}}}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(sent.Messages) != 1 || len(sent.Messages[0].Images) != 1 || sent.Messages[0].Images[0] != "cG5n" {
+		t.Errorf("Expected the image as base64 data, got %+v", sent.Messages)
+	}
+}
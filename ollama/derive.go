@@ -0,0 +1,46 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// WithModel returns a client that sends its requests to model on the same
+// server. It shares c's HTTP client, so deriving a client per model is
+// cheap. "" keeps c's model.
+func (c *Client) WithModel(model string) *Client {
+	d := *c
+	if model != "" {
+		d.modelName = model
+	}
+	return &d
+}
+
+// WithTimeout returns a client whose requests, including reading their
+// responses, time out after d. It shares c's transport. A d of 0 or less
+// disables the client timeout, leaving only the context's deadline, which
+// suits long generations on slow hardware.
+func (c *Client) WithTimeout(d time.Duration) *Client {
+	derived := *c
+	if c.httpClient != nil {
+		httpClient := *c.httpClient
+		httpClient.Timeout = max(d, 0)
+		derived.httpClient = &httpClient
+	}
+	return &derived
+}
+
+// requestClient returns the HTTP client for a request sent with ctx: c's,
+// or a copy timing out after the timeout set with llm.WithRequestTimeout.
+func (c *Client) requestClient(ctx context.Context) *http.Client {
+	d, ok := llm.RequestTimeoutFromContext(ctx)
+	if !ok {
+		return c.httpClient
+	}
+	httpClient := *c.httpClient
+	httpClient.Timeout = d
+	return &httpClient
+}
@@ -0,0 +1,61 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const (
+	embeddingsAPIPath = "/api/embeddings"
+
+	opEmbed = "embed" // Error.Op of failed Embed calls
+)
+
+// ollamaEmbeddingsRequest is the request body of /api/embeddings.
+type ollamaEmbeddingsRequest struct {
+	Model   string                 `json:"model"`
+	Prompt  string                 `json:"prompt"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// ollamaEmbeddingsResponse is the response of /api/embeddings.
+type ollamaEmbeddingsResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// Embed implements xollm.Embedder with /api/embeddings, which embeds one
+// text per request, so the texts are sent one after the other. The
+// embedding model is the one set with llm.WithEmbeddingModel, or the
+// client's model.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("Ollama client not initialized")
+	}
+	model := c.options.EmbeddingModel
+	if model == "" {
+		model = c.modelName
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for i, text := range texts {
+		payload := ollamaEmbeddingsRequest{
+			Model:   model,
+			Prompt:  text,
+			Options: runtimeOptions(c.options.Runtime),
+		}
+		var resp ollamaEmbeddingsResponse
+		body, hc, err := c.doJSON(ctx, http.MethodPost, opEmbed, embeddingsAPIPath, payload, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Embedding) == 0 {
+			// Models that can't embed return an empty vector rather than an error
+			embedErr := hc.opError(opEmbed, embeddingsAPIPath, fmt.Errorf("model %s returned no embedding for text %d", model, i+1))
+			embedErr.Body = body
+			return nil, embedErr
+		}
+		embeddings = append(embeddings, resp.Embedding)
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,73 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestOllamaClient_Embed(t *testing.T) {
+	var prompts []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != embeddingsAPIPath {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		var req ollamaEmbeddingsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" {
+			t.Errorf("Expected the embedding model, got %q", req.Model)
+		}
+		prompts = append(prompts, req.Prompt)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"embedding": [0.5, -1, %d]}`, len(prompts))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false, llm.WithEmbeddingModel("nomic-embed-text"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	vectors, err := client.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(prompts) != 2 || prompts[0] != "first" || prompts[1] != "second" {
+		t.Errorf("Expected one request per text in order, got %q", prompts)
+	}
+	if len(vectors) != 2 || len(vectors[0]) != 3 || vectors[0][0] != 0.5 || vectors[1][2] != 2 {
+		t.Errorf("Unexpected embeddings: %v", vectors)
+	}
+}
+
+func TestOllamaClient_Embed_NoEmbedding(t *testing.T) {
+	var model string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaEmbeddingsRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		model = req.Model
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"embedding": []}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "gemma:2b", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.Embed(context.Background(), []string{"text"})
+	var llmErr *llm.Error
+	if !errors.As(err, &llmErr) || llmErr.Op != opEmbed {
+		t.Errorf("Expected an embed *llm.Error, got: %v", err)
+	}
+	if model != "gemma:2b" {
+		t.Errorf("Expected the client's model without an embedding model, got %q", model)
+	}
+}
@@ -0,0 +1,43 @@
+package ollama
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// GenerateWithImages implements xollm.VisionGenerator with the images
+// field of /api/generate, for vision models such as llava. Ollama only
+// accepts image data, so images given by URL are downloaded first.
+func (c *Client) GenerateWithImages(ctx context.Context, prompt string, images []llm.Image) (string, error) {
+	if c.httpClient == nil {
+		return "", fmt.Errorf("Ollama client not initialized")
+	}
+	if err := c.preflight(prompt); err != nil {
+		return "", err
+	}
+	loaded, err := llm.LoadImages(ctx, []llm.Message{{Role: llm.RoleUser, Images: images}})
+	if err != nil {
+		return "", err
+	}
+
+	payload := c.generateRequest(prompt, llm.Sampling{})
+	payload.Images = encodeImages(loaded[0].Images)
+	text, _, err := c.sendGenerate(ctx, payload)
+	return text, err
+}
+
+// encodeImages returns the data of images, which must be loaded, encoded
+// in base64 as Ollama expects.
+func encodeImages(images []llm.Image) []string {
+	if len(images) == 0 {
+		return nil
+	}
+	encoded := make([]string, len(images))
+	for i, img := range images {
+		encoded[i] = base64.StdEncoding.EncodeToString(img.Data)
+	}
+	return encoded
+}
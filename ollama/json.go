@@ -0,0 +1,30 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// GenerateJSON implements xollm.JSONGenerator with the format parameter:
+// the schema itself, which makes Ollama constrain the output to it, or
+// "json" for any JSON value if schema is nil.
+func (c *Client) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	if c.httpClient == nil {
+		return "", fmt.Errorf("Ollama client not initialized")
+	}
+	if err := c.preflight(prompt); err != nil {
+		return "", err
+	}
+
+	format := schema
+	if len(format) == 0 {
+		format = json.RawMessage(`"json"`)
+	}
+	payload := c.generateRequest(prompt, llm.Sampling{})
+	payload.Format = format
+	text, _, err := c.sendGenerate(ctx, payload)
+	return text, err
+}
@@ -0,0 +1,235 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// Model management API paths and the Error.Op of their failures.
+const (
+	tagsAPIPath   = "/api/tags"
+	pullAPIPath   = "/api/pull"
+	deleteAPIPath = "/api/delete"
+	showAPIPath   = "/api/show"
+
+	opListModels  = "list models"
+	opPullModel   = "pull model"
+	opDeleteModel = "delete model"
+	opShowModel   = "show model"
+)
+
+// ModelDetails describes the format and size class of a model.
+type ModelDetails struct {
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families,omitempty"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
+}
+
+// LocalModel is a model available on the Ollama server.
+type LocalModel struct {
+	Name       string       `json:"name"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// ModelDescription is the full description of a model returned by
+// ShowModel.
+type ModelDescription struct {
+	// Modelfile is the Modelfile the model was built from.
+	Modelfile string `json:"modelfile"`
+	// Parameters are the model's default parameters, one per line.
+	Parameters string `json:"parameters"`
+	// Template is the prompt template.
+	Template string       `json:"template"`
+	Details  ModelDetails `json:"details"`
+	// ModelInfo holds architecture metadata such as the context length,
+	// keyed like "llama.context_length".
+	ModelInfo map[string]interface{} `json:"model_info,omitempty"`
+	// Capabilities lists what the model supports, such as "completion",
+	// "vision" or "tools". Older servers don't report it.
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// ContextLength returns the context window the server runs the model with:
+// the num_ctx parameter of its Modelfile if set, otherwise Ollama's
+// DefaultContextLength, capped by the length the model was trained with.
+// A request's num_ctx option overrides it, see llm.RuntimeOptions.
+func (d *ModelDescription) ContextLength() int {
+	for _, line := range strings.Split(d.Parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "num_ctx" {
+			if n, err := strconv.Atoi(fields[1]); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	if trained := d.TrainedContextLength(); trained > 0 {
+		return min(trained, DefaultContextLength)
+	}
+	return DefaultContextLength
+}
+
+// TrainedContextLength returns the context length the model was trained
+// with, the most num_ctx can usefully be raised to, or 0 if the server
+// doesn't report it.
+func (d *ModelDescription) TrainedContextLength() int {
+	for key, value := range d.ModelInfo {
+		if n, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+// hasCapability reports whether the server lists capability for the model.
+func (d *ModelDescription) hasCapability(capability string) bool {
+	for _, c := range d.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// PullProgress reports the progress of a pull. Total and Completed are
+// byte counts for the layer identified by Digest while it downloads.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+}
+
+// ListModels returns the models available on the server.
+func (c *Client) ListModels(ctx context.Context) ([]LocalModel, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("Ollama client not initialized")
+	}
+	var resp struct {
+		Models []LocalModel `json:"models"`
+	}
+	if _, _, err := c.doJSON(ctx, http.MethodGet, opListModels, tagsAPIPath, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Models, nil
+}
+
+// ShowModel returns the description of the named model.
+func (c *Client) ShowModel(ctx context.Context, name string) (*ModelDescription, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("Ollama client not initialized")
+	}
+	var desc ModelDescription
+	if _, _, err := c.doJSON(ctx, http.MethodPost, opShowModel, showAPIPath, modelRequest{Model: name}, &desc); err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
+
+// DeleteModel removes the named model from the server.
+func (c *Client) DeleteModel(ctx context.Context, name string) error {
+	if c.httpClient == nil {
+		return fmt.Errorf("Ollama client not initialized")
+	}
+	_, _, err := c.doJSON(ctx, http.MethodDelete, opDeleteModel, deleteAPIPath, modelRequest{Model: name}, nil)
+	return err
+}
+
+// ModelInfo implements xollm.ModelInfoProvider using ShowModel. The context
+// window is the one the server runs the model with (see ContextLength),
+// or a configured num_ctx for the client's own model, not the model's
+// trained length. Ollama has no separate output limit, so MaxOutputTokens
+// equals the context window. Fields the server doesn't report keep their
+// value from the bundled registry.
+func (c *Client) ModelInfo(ctx context.Context, model string) (llm.ModelInfo, error) {
+	if model == "" {
+		model = c.modelName
+	}
+	desc, err := c.ShowModel(ctx, model)
+	if err != nil {
+		return llm.ModelInfo{}, err
+	}
+
+	info, _ := llm.LookupModel(model)
+	info.Name, info.Provider = model, providerName
+	n := desc.ContextLength()
+	if numCtx := c.options.Runtime.NumCtx; numCtx != nil && model == c.modelName {
+		n = *numCtx
+	}
+	info.ContextWindow, info.MaxOutputTokens = n, n
+	if desc.hasCapability("vision") {
+		info.Vision = true
+	}
+	llm.RegisterModel(info)
+	return info, nil
+}
+
+// PullModel downloads the named model to the server, calling progress (if
+// not nil) for every status update. Pulls can take many minutes, so the
+// client's request timeout does not apply; use ctx to bound or cancel it.
+func (c *Client) PullModel(ctx context.Context, name string, progress func(PullProgress)) error {
+	if c.httpClient == nil {
+		return fmt.Errorf("Ollama client not initialized")
+	}
+	untimed := *c.httpClient
+	untimed.Timeout = 0
+
+	resp, hc, err := c.send(ctx, &untimed, http.MethodPost, opPullModel, pullAPIPath, pullRequest{Model: name, Stream: true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The stream ends with a "success" status once the model is ready
+	dec := llm.NewNDJSONDecoder(resp.Body)
+	lastStatus := ""
+	for {
+		var update struct {
+			PullProgress
+			Error string `json:"error,omitempty"`
+		}
+		err := dec.Decode(&update)
+		if errors.Is(err, io.EOF) {
+			if lastStatus != "success" {
+				return hc.opError(opPullModel, pullAPIPath, fmt.Errorf("pull ended before completing, last status: %q", lastStatus))
+			}
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return hc.opError(opPullModel, pullAPIPath, fmt.Errorf("pull interrupted: %w", ctx.Err()))
+			}
+			return hc.opError(opPullModel, pullAPIPath, fmt.Errorf("failed to decode progress: %w", err))
+		}
+		if update.Error != "" {
+			return hc.newAPIError(opPullModel, pullAPIPath, resp.StatusCode, update.Error, "")
+		}
+		lastStatus = update.Status
+		if progress != nil {
+			progress(update.PullProgress)
+		}
+	}
+}
+
+// modelRequest names the model for the show and delete endpoints.
+type modelRequest struct {
+	Model string `json:"model"`
+}
+
+// pullRequest is the request body for /api/pull.
+type pullRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
@@ -0,0 +1,185 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func newModelsTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewClient(context.Background(), server.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestListModels(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/tags" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"models": [{"name": "gemma:2b", "size": 1678447520, "digest": "b50d", "details": {"family": "gemma", "parameter_size": "3B", "quantization_level": "Q4_0"}}]}`))
+	})
+
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(models) != 1 || models[0].Name != "gemma:2b" || models[0].Details.ParameterSize != "3B" {
+		t.Errorf("Unexpected models: %+v", models)
+	}
+}
+
+func TestShowModel(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req modelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/api/show" || req.Model != "gemma:2b" {
+			t.Errorf("Unexpected request %s for %q", r.URL.Path, req.Model)
+		}
+		w.Write([]byte(`{"modelfile": "FROM gemma", "parameters": "stop \"<end>\"", "template": "{{ .Prompt }}", "details": {"family": "gemma"}, "model_info": {"gemma.context_length": 8192}}`))
+	})
+
+	desc, err := client.ShowModel(context.Background(), "gemma:2b")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if desc.Details.Family != "gemma" || desc.ModelInfo["gemma.context_length"] != float64(8192) {
+		t.Errorf("Unexpected description: %+v", desc)
+	}
+	if desc.TrainedContextLength() != 8192 || desc.ContextLength() != DefaultContextLength {
+		t.Errorf("Expected a trained length of 8192 run with the default window, got %d and %d",
+			desc.TrainedContextLength(), desc.ContextLength())
+	}
+}
+
+func TestDeleteModel(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/api/delete" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req modelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "old" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "model 'x' not found"}`))
+		}
+	})
+
+	if err := client.DeleteModel(context.Background(), "old"); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	err := client.DeleteModel(context.Background(), "missing")
+	if !errors.Is(err, llm.ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got: %v", err)
+	}
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.Op != "delete model" {
+		t.Errorf("Expected delete model *llm.Error, got: %v", err)
+	}
+}
+
+func TestPullModel(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req pullRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/api/pull" || req.Model != "gemma:2b" || !req.Stream {
+			t.Errorf("Unexpected request %s %+v", r.URL.Path, req)
+		}
+		w.Write([]byte(`{"status": "pulling manifest"}
+{"status": "downloading", "digest": "sha256:1", "total": 100, "completed": 50}
+{"status": "downloading", "digest": "sha256:1", "total": 100, "completed": 100}
+{"status": "success"}
+`))
+	})
+
+	var updates []PullProgress
+	err := client.PullModel(context.Background(), "gemma:2b", func(p PullProgress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(updates) != 4 || updates[2].Completed != 100 || updates[3].Status != "success" {
+		t.Errorf("Unexpected progress updates: %+v", updates)
+	}
+}
+
+func TestPullModel_Failures(t *testing.T) {
+	streamError := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"status\": \"pulling manifest\"}\n{\"error\": \"pull model manifest: file does not exist\"}\n"))
+	})
+	if err := streamError.PullModel(context.Background(), "nope", nil); err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Errorf("Expected error from the stream, got: %v", err)
+	}
+
+	truncated := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"status\": \"downloading\"}\n"))
+	})
+	if err := truncated.PullModel(context.Background(), "gemma:2b", nil); err == nil || !strings.Contains(err.Error(), "before completing") {
+		t.Errorf("Expected error for incomplete pull, got: %v", err)
+	}
+}
+
+func TestModelManagement_NilClient(t *testing.T) {
+	c := &Client{}
+	if _, err := c.ListModels(context.Background()); err == nil {
+		t.Error("Expected error from ListModels")
+	}
+	if _, err := c.ShowModel(context.Background(), "m"); err == nil {
+		t.Error("Expected error from ShowModel")
+	}
+	if err := c.DeleteModel(context.Background(), "m"); err == nil {
+		t.Error("Expected error from DeleteModel")
+	}
+	if err := c.PullModel(context.Background(), "m", nil); err == nil {
+		t.Error("Expected error from PullModel")
+	}
+}
+
+func TestClient_ModelInfo(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req modelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Model {
+		case "test-vision:7b":
+			w.Write([]byte(`{"parameters": "stop \"<end>\"\nnum_ctx                        16384", "model_info": {"llama.context_length": 131072}, "capabilities": ["completion", "vision"]}`))
+		case "test-plain:1b":
+			w.Write([]byte(`{"model_info": {"general.architecture": "qwen2", "qwen2.context_length": 32768}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "model not found"}`))
+		}
+	})
+
+	info, err := client.ModelInfo(context.Background(), "test-vision:7b")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if info.ContextWindow != 16384 || info.MaxOutputTokens != 16384 || !info.Vision || info.Provider != "ollama" {
+		t.Errorf("Expected num_ctx to set the window and vision from capabilities, got %+v", info)
+	}
+	if registered, ok := llm.LookupModel("test-vision:7b"); !ok || registered.ContextWindow != 16384 {
+		t.Errorf("Expected the model to be registered, got %+v", registered)
+	}
+
+	// Without num_ctx, Ollama runs the model with its default window
+	info, err = client.ModelInfo(context.Background(), "test-plain:1b")
+	if err != nil || info.ContextWindow != DefaultContextLength || info.Vision {
+		t.Errorf("Expected Ollama's default window without vision, got %+v, %v", info, err)
+	}
+
+	if _, err := client.ModelInfo(context.Background(), "missing"); !errors.Is(err, llm.ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got: %v", err)
+	}
+}
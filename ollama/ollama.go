@@ -7,25 +7,47 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 	// No specific Ollama SDK is typically needed, use net/http.
+
+	"github.com/xostack/xollm/llm"
 )
 
 const (
 	defaultOllamaModel = "gemma:2b" // A common default, user can override in config
 	providerName       = "ollama"
 	generateAPIPath    = "/api/generate"
+	chatAPIPath        = "/api/chat"
+	versionAPIPath     = "/api/version"
+
+	opWarmup = "warmup" // Error.Op of failed Warmup calls
+	opPing   = "ping"   // Error.Op of failed Ping calls
 )
 
-// Client implements the llm.Client interface for Ollama.
+// DefaultContextLength is the context window Ollama runs a model with when
+// neither the request nor the Modelfile sets num_ctx, whatever the model
+// was trained with. Longer prompts are silently truncated. Servers may be
+// started with a larger default (OLLAMA_CONTEXT_LENGTH); configure num_ctx,
+// e.g. with llm.WithRuntimeOptions, to rely on a larger window.
+const DefaultContextLength = 2048
+
+// Client implements the llm.Client interface for Ollama. A Client is safe
+// for concurrent use: its settings don't change after NewClient, and each
+// call, streaming ones included, keeps its state to itself. Share one
+// client between goroutines rather than creating one per worker.
 type Client struct {
 	httpClient *http.Client
-	baseURL    string // e.g., "http://localhost:11434"
+	baseURL    string    // e.g., "http://localhost:11434"; the first of hosts
+	hosts      *hostPool // nil unless created with several base URLs
+	pinned     bool      // send to baseURL only, see WithHost
 	modelName  string
+	options    llm.ClientOptions
+	logger     *slog.Logger // nil for llm.DefaultLogger
 }
 
 // ollamaGenerateRequest is the structure for the request body to Ollama's /api/generate.
@@ -33,59 +55,86 @@ type ollamaGenerateRequest struct {
 	Model  string `json:"model"`
 	Prompt string `json:"prompt"`
 	Stream bool   `json:"stream"` // Non-streaming behavior for complete responses
-	// Add other options like System, Template, Context, Options if needed later
-	// System  string                 `json:"system,omitempty"`
-	// Options map[string]interface{} `json:"options,omitempty"`
+	// Context is the context returned by an earlier generation, continuing
+	// that conversation without resending it.
+	Context []int `json:"context,omitempty"`
+	// Options are model runtime settings such as num_ctx and sampling
+	// parameters, see requestOptions
+	Options map[string]interface{} `json:"options,omitempty"`
+	// Images are base64-encoded images for vision models
+	Images []string `json:"images,omitempty"`
+	// Format constrains the response to JSON: "json" for any JSON value,
+	// or a JSON Schema
+	Format json.RawMessage `json:"format,omitempty"`
+	// System is the system prompt, replacing the one in the model's
+	// Modelfile
+	System string `json:"system,omitempty"`
 }
 
 // ollamaGenerateResponse is the structure for the response from Ollama's /api/generate
-// when stream is false.
+// when stream is false, and for each streamed object when it is true.
 type ollamaGenerateResponse struct {
 	Model     string    `json:"model"`
 	CreatedAt time.Time `json:"created_at"`
 	Response  string    `json:"response"` // This is the generated text
 	Done      bool      `json:"done"`
-	// Context            []int                  `json:"context,omitempty"` // For subsequent requests
-	// TotalDuration      time.Duration          `json:"total_duration,omitempty"`
-	// LoadDuration       time.Duration          `json:"load_duration,omitempty"`
-	// PromptEvalCount    int                    `json:"prompt_eval_count,omitempty"`
-	// PromptEvalDuration time.Duration          `json:"prompt_eval_duration,omitempty"`
-	// EvalCount          int                    `json:"eval_count,omitempty"`
-	// EvalDuration       time.Duration          `json:"eval_duration,omitempty"`
-	Error string `json:"error,omitempty"` // Ollama might return an error field
+	// DoneReason is why generation stopped, e.g. "stop" or "length"
+	DoneReason string `json:"done_reason,omitempty"`
+	Context    []int  `json:"context,omitempty"` // For subsequent requests, see Session
+	// Metrics, reported on the final object only
+	TotalDuration      time.Duration `json:"total_duration,omitempty"` // Nanoseconds, as time.Duration
+	LoadDuration       time.Duration `json:"load_duration,omitempty"`
+	PromptEvalCount    int           `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration time.Duration `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int           `json:"eval_count,omitempty"`
+	EvalDuration       time.Duration `json:"eval_duration,omitempty"`
+	Error              string        `json:"error,omitempty"` // Ollama might return an error field
 }
 
 // NewClient creates a new Ollama client.
 // ctx is used for timeout configuration and cancellation.
 // baseURL is the address of the Ollama server (e.g., "http://localhost:11434").
 // modelOverride is an optional model name to use instead of the default.
-// debugMode controls verbose logging.
-func NewClient(ctx context.Context, baseURL string, modelOverride string, requestTimeoutSeconds int, debugMode bool) (*Client, error) {
-	if baseURL == "" {
+// debugMode is deprecated and should be false: pass
+// llm.WithLogger(llm.DebugLogger()) instead. True still selects that
+// logger when no llm.WithLogger option is given.
+// opts enable optional behaviour such as llm.WithPreflightTokenCheck.
+func NewClient(ctx context.Context, baseURL string, modelOverride string, requestTimeoutSeconds int, debugMode bool, opts ...llm.ClientOption) (*Client, error) {
+	return NewBalancedClient(ctx, []string{baseURL}, modelOverride, requestTimeoutSeconds, debugMode, opts...)
+}
+
+// NewBalancedClient creates an Ollama client balancing its requests across
+// several servers serving the same models. Requests go round-robin over
+// the servers that are healthy; a server that can't be reached or fails
+// with a server error is skipped for a back-off period, and requests that
+// couldn't reach their server are retried on the next. With
+// llm.WithStickySessions, requests with the same session key (see
+// llm.WithSessionKey) and the turns of a Session go to the same server
+// while it is healthy, so its cached conversation state is reused. The
+// other arguments are those of NewClient.
+func NewBalancedClient(ctx context.Context, baseURLs []string, modelOverride string, requestTimeoutSeconds int, debugMode bool, opts ...llm.ClientOption) (*Client, error) {
+	if len(baseURLs) == 0 {
 		return nil, fmt.Errorf("Ollama base URL is required")
 	}
-	// Validate and clean baseURL
-	parsedURL, err := url.Parse(baseURL)
-	if err != nil {
-		return nil, fmt.Errorf("invalid Ollama base URL '%s': %w", baseURL, err)
-	}
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return nil, fmt.Errorf("Ollama base URL scheme must be http or https, got '%s'", parsedURL.Scheme)
+	var cleanedBaseURLs []string
+	for _, baseURL := range baseURLs {
+		cleanedBaseURL, err := cleanBaseURL(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(cleanedBaseURLs, cleanedBaseURL) {
+			cleanedBaseURLs = append(cleanedBaseURLs, cleanedBaseURL)
+		}
 	}
-	// Remove any trailing slash from baseURL for consistency
-	cleanedBaseURL := strings.TrimSuffix(parsedURL.String(), "/")
+	options := llm.ApplyOptions(opts)
+	clientLogger := options.ClientLogger(debugMode)
+	logger := llm.Logger(ctx, clientLogger)
 
 	modelToUse := defaultOllamaModel
 	if modelOverride != "" {
 		modelToUse = modelOverride
-		if debugMode {
-			log.Printf("Using overridden Ollama model: %s", modelToUse)
-		}
-	} else {
-		if debugMode {
-			log.Printf("Using default Ollama model: %s", modelToUse)
-		}
 	}
+	logger.Debug("using Ollama model", "model", modelToUse, "overridden", modelOverride != "")
 
 	// Use context timeout if requestTimeoutSeconds is 0
 	timeout := time.Duration(requestTimeoutSeconds) * time.Second
@@ -93,101 +142,430 @@ func NewClient(ctx context.Context, baseURL string, modelOverride string, reques
 		// Check if context has a deadline
 		if deadline, ok := ctx.Deadline(); ok {
 			timeout = time.Until(deadline)
-			if debugMode {
-				log.Printf("Using context deadline for timeout: %v", timeout)
-			}
+			logger.Debug("using context deadline for timeout", "timeout", timeout)
 		} else {
 			timeout = 60 * time.Second // Default fallback
-			if debugMode {
-				log.Printf("Using default timeout: %v", timeout)
-			}
+			logger.Debug("using default timeout", "timeout", timeout)
 		}
 	}
 
-	return &Client{
+	client := &Client{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: options.Transport(llm.SharedTransport()), // Pool connections across clients
 		},
-		baseURL:   cleanedBaseURL,
+		baseURL:   cleanedBaseURLs[0],
 		modelName: modelToUse,
-	}, nil
+		options:   options,
+		logger:    clientLogger,
+	}
+	if len(cleanedBaseURLs) > 1 {
+		client.hosts = newHostPool(cleanedBaseURLs)
+		logger.Debug("balancing across Ollama servers", "servers", cleanedBaseURLs)
+	}
+	return client, nil
+}
+
+// cleanBaseURL validates an Ollama server address and removes any trailing
+// slash.
+func cleanBaseURL(baseURL string) (string, error) {
+	if baseURL == "" {
+		return "", fmt.Errorf("Ollama base URL is required")
+	}
+	// Validate and clean baseURL
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid Ollama base URL '%s': %w", baseURL, err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return "", fmt.Errorf("Ollama base URL scheme must be http or https, got '%s'", parsedURL.Scheme)
+	}
+	// Remove any trailing slash from baseURL for consistency
+	return strings.TrimSuffix(parsedURL.String(), "/"), nil
 }
 
 // Generate sends the prompt to the Ollama model and returns the text response.
 func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
+	text, _, err := c.generate(ctx, prompt, nil, llm.Sampling{})
+	return text, err
+}
+
+// GenerateWithSampling implements xollm.SamplingGenerator: the fields set
+// in sampling override the client's default sampling parameters for this
+// call. They are sent as the temperature, num_predict, top_p and stop
+// options.
+func (c *Client) GenerateWithSampling(ctx context.Context, prompt string, sampling llm.Sampling) (string, error) {
+	text, _, err := c.generate(ctx, prompt, nil, sampling)
+	return text, err
+}
+
+// GenerateWithMetadata is Generate, also returning the response's done
+// reason, token counts and the server's timing breakdown.
+func (c *Client) GenerateWithMetadata(ctx context.Context, prompt string) (string, *llm.ResponseMetadata, error) {
+	text, resp, err := c.generate(ctx, prompt, nil, llm.Sampling{})
+	if err != nil {
+		return "", nil, err
+	}
+	return text, resp.metadata(), nil
+}
+
+// metadata describes the final response object.
+func (r *ollamaGenerateResponse) metadata() *llm.ResponseMetadata {
+	return (&llm.ResponseMetadata{
+		Model:        r.Model,
+		FinishReason: r.DoneReason,
+		Usage: llm.Usage{
+			PromptTokens:     r.PromptEvalCount,
+			CompletionTokens: r.EvalCount,
+		},
+		Timing: &llm.Timing{
+			Prompt:     r.PromptEvalDuration,
+			Completion: r.EvalDuration,
+			Total:      r.TotalDuration,
+		},
+	}).WithEstimatedCost(providerName)
+}
+
+// generate sends the prompt along with the context tokens of earlier
+// generations, if any, and returns the text and the final response object,
+// whose Context continues the conversation. The fields set in sampling
+// override the client's defaults.
+func (c *Client) generate(ctx context.Context, prompt string, genContext []int, sampling llm.Sampling) (string, *ollamaGenerateResponse, error) {
 	if c.httpClient == nil {
-		return "", fmt.Errorf("Ollama client not initialized")
+		return "", nil, fmt.Errorf("Ollama client not initialized")
 	}
+	if err := c.preflight(prompt); err != nil {
+		return "", nil, err
+	}
+
+	payload := c.generateRequest(prompt, sampling)
+	payload.Context = genContext
+	return c.sendGenerate(ctx, payload)
+}
 
-	// Construct the request payload
-	payload := ollamaGenerateRequest{
-		Model:  c.modelName,
-		Prompt: prompt,
-		Stream: false, // Non-streaming response for complete output
+// generateRequest returns the non-streaming /api/generate request for
+// prompt, with the client's system prompt and options. The fields set in
+// sampling override the client's defaults.
+func (c *Client) generateRequest(prompt string, sampling llm.Sampling) ollamaGenerateRequest {
+	return ollamaGenerateRequest{
+		Model:   c.modelName,
+		Prompt:  prompt,
+		System:  c.options.SystemPrompt,
+		Options: c.requestOptions(sampling),
 	}
+}
 
-	payloadBytes, err := json.Marshal(payload)
+// sendGenerate sends a non-streaming generate request and returns the
+// trimmed text and the response object.
+func (c *Client) sendGenerate(ctx context.Context, payload ollamaGenerateRequest) (string, *ollamaGenerateResponse, error) {
+	var ollamaResp ollamaGenerateResponse
+	responseBody, hc, err := c.doJSON(ctx, http.MethodPost, llm.OpGenerate, generateAPIPath, payload, &ollamaResp)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal Ollama request payload: %w", err)
+		return "", nil, llm.WrapContextLength(c.modelName, err)
+	}
+
+	if ollamaResp.Error != "" {
+		return "", nil, llm.WrapContextLength(c.modelName, hc.newAPIError(llm.OpGenerate, generateAPIPath, http.StatusOK, ollamaResp.Error, responseBody))
+	}
+
+	// The main generated text is in the "response" field
+	if !ollamaResp.Done && ollamaResp.Response == "" {
+		// This might happen if 'done' is false but no response is given yet,
+		// which is unusual for stream=false.
+		return "", nil, hc.newAPIError(llm.OpGenerate, generateAPIPath, http.StatusOK, "response indicates not done but no text was returned", responseBody)
+	}
+
+	return strings.TrimSpace(ollamaResp.Response), &ollamaResp, nil
+}
+
+// Warmup loads the model into the server's memory ahead of the first real
+// call, so that call doesn't pay the multi-second cold-start penalty. It
+// sends a generate request without a prompt, which Ollama answers as soon
+// as the model is loaded. The server unloads the model again after its
+// keep-alive period.
+func (c *Client) Warmup(ctx context.Context) error {
+	if c.httpClient == nil {
+		return fmt.Errorf("Ollama client not initialized")
+	}
+	// Send the runtime options too: loading with a different num_ctx than
+	// later requests would make the server reload the model
+	payload := ollamaGenerateRequest{Model: c.modelName, Stream: false, Options: runtimeOptions(c.options.Runtime)}
+	var ollamaResp ollamaGenerateResponse
+	responseBody, hc, err := c.doJSON(ctx, http.MethodPost, opWarmup, generateAPIPath, payload, &ollamaResp)
+	if err != nil {
+		return err
+	}
+	if ollamaResp.Error != "" {
+		return hc.newAPIError(opWarmup, generateAPIPath, http.StatusOK, ollamaResp.Error, responseBody)
+	}
+	return nil
+}
+
+// Ping implements xollm.Pinger by asking the server for its version, which
+// needs no model to be loaded. Ollama has no authentication, so Ping only
+// checks the server is reachable; a balanced client succeeds if any of its
+// servers answers.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.httpClient == nil {
+		return fmt.Errorf("Ollama client not initialized")
+	}
+	var version struct {
+		Version string `json:"version"`
+	}
+	_, _, err := c.doJSON(ctx, http.MethodGet, opPing, versionAPIPath, nil, &version)
+	return err
+}
+
+// preflight runs the pre-flight context window check if it is enabled,
+// against the window the server runs the model with (see ContextWindow).
+func (c *Client) preflight(prompt string) error {
+	if !c.options.PreflightTokenCheck {
+		return nil
+	}
+	return llm.PreflightCheckWindow(c.modelName, prompt, c.ContextWindow())
+}
+
+// ContextWindow implements llm.ContextWindowProvider: the configured
+// num_ctx if there is one, otherwise DefaultContextLength, capped by the
+// model's registered window. A num_ctx set in the model's Modelfile isn't
+// known to the client; configure it with llm.WithRuntimeOptions too.
+func (c *Client) ContextWindow() int {
+	if numCtx := c.options.Runtime.NumCtx; numCtx != nil {
+		return *numCtx
+	}
+	if info, ok := llm.LookupModel(c.modelName); ok && info.ContextWindow > 0 {
+		return min(info.ContextWindow, DefaultContextLength)
+	}
+	return DefaultContextLength
+}
+
+// requestOptions returns the options object of a generation: the runtime
+// options and the client's sampling parameters with the fields set in
+// sampling overriding them. It is nil if nothing is set.
+func (c *Client) requestOptions(sampling llm.Sampling) map[string]interface{} {
+	options := runtimeOptions(c.options.Runtime)
+	s := c.options.Sampling.Override(sampling)
+	set := func(key string, value interface{}) {
+		if options == nil {
+			options = map[string]interface{}{}
+		}
+		options[key] = value
+	}
+	if s.Temperature != nil {
+		set("temperature", *s.Temperature)
+	}
+	if s.MaxTokens != nil {
+		set("num_predict", *s.MaxTokens)
+	}
+	if s.TopP != nil {
+		set("top_p", *s.TopP)
+	}
+	if len(s.Stop) > 0 {
+		set("stop", s.Stop)
+	}
+	if s.Seed != nil {
+		set("seed", *s.Seed)
+	}
+	if s.PresencePenalty != nil {
+		set("presence_penalty", *s.PresencePenalty)
+	}
+	if s.FrequencyPenalty != nil {
+		set("frequency_penalty", *s.FrequencyPenalty)
+	}
+	return options
+}
+
+// runtimeOptions converts r to the request's options object, or nil if
+// nothing is set.
+func runtimeOptions(r llm.RuntimeOptions) map[string]interface{} {
+	options := map[string]interface{}{}
+	if r.NumCtx != nil {
+		options["num_ctx"] = *r.NumCtx
+	}
+	if r.NumGPU != nil {
+		options["num_gpu"] = *r.NumGPU
+	}
+	if r.NumThread != nil {
+		options["num_thread"] = *r.NumThread
+	}
+	if r.Mirostat != nil {
+		options["mirostat"] = *r.Mirostat
+	}
+	if r.MirostatEta != nil {
+		options["mirostat_eta"] = *r.MirostatEta
+	}
+	if r.MirostatTau != nil {
+		options["mirostat_tau"] = *r.MirostatTau
+	}
+	if r.RepeatPenalty != nil {
+		options["repeat_penalty"] = *r.RepeatPenalty
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	return options
+}
+
+// doJSON sends payload (if not nil) as JSON to the API path and decodes the
+// JSON response into out (if not nil). Failures are returned as *llm.Error
+// describing op. It returns the start of the response body for error
+// reports, and the client bound to the server that answered, see send.
+func (c *Client) doJSON(ctx context.Context, method, op, path string, payload, out interface{}) (string, *Client, error) {
+	resp, hc, err := c.send(ctx, c.requestClient(ctx), method, op, path, payload)
+	if err != nil {
+		return "", hc, err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return "", hc, nil
+	}
+
+	// Decode the response straight from the body, keeping its start for
+	// error reports
+	responseBody, err := llm.DecodeJSON(resp.Body, out)
+	if err != nil {
+		decodeErr := hc.opError(op, path, fmt.Errorf("failed to decode response: %w", err))
+		decodeErr.StatusCode, decodeErr.Body = resp.StatusCode, responseBody
+		return responseBody, hc, decodeErr
+	}
+	return responseBody, hc, nil
+}
+
+// send sends payload (if not nil) as JSON to the API path with httpClient
+// and returns the response if its status is 200 OK. Other statuses are
+// returned as *llm.Error describing op, with the message from the body.
+// The caller must close the response body.
+//
+// A balanced client tries its servers in turn until one can be reached.
+// send also returns a copy of c bound to the server it last tried, so
+// that errors found in the response name that server.
+func (c *Client) send(ctx context.Context, httpClient *http.Client, method, op, path string, payload interface{}) (*http.Response, *Client, error) {
+	var payloadBytes []byte
+	if payload != nil {
+		var err error
+		payloadBytes, err = json.Marshal(payload)
+		if err != nil {
+			return nil, c, c.opError(op, path, fmt.Errorf("failed to marshal request: %w", err))
+		}
+	}
+
+	candidates := c.candidates(ctx)
+	for i, hc := range candidates {
+		resp, unreachable, err := hc.sendTo(ctx, httpClient, method, op, path, payloadBytes)
+		if unreachable && i < len(candidates)-1 {
+			llm.Logger(ctx, c.logger).Debug("Ollama server unreachable, trying the next", "server", hc.baseURL, "error", err)
+			continue
+		}
+		return resp, hc, err
+	}
+	panic("unreachable")
+}
+
+// sendTo sends the request to the client's server. unreachable reports a
+// request that failed without reaching the server, which may be retried
+// on another one. A balanced client records the server's health.
+func (c *Client) sendTo(ctx context.Context, httpClient *http.Client, method, op, path string, payload []byte) (resp *http.Response, unreachable bool, err error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
 	}
 
 	// Construct the request
-	requestURL := c.baseURL + generateAPIPath
-	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
-		return "", fmt.Errorf("failed to create Ollama request: %w", err)
+		return nil, false, c.opError(op, path, fmt.Errorf("failed to create request: %w", err))
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	llm.SetUserAgent(req, c.options.AppIdentifier)
+	if err := llm.InterceptDryRun(providerName, req); err != nil {
+		return nil, false, err
+	}
 
 	// Send the request
-	resp, err := c.httpClient.Do(req)
+	llm.Logger(ctx, c.logger).Debug("sending Ollama request", "method", method, "path", path)
+	resp, err = httpClient.Do(req)
 	if err != nil {
 		// Check if the error is due to context cancellation (e.g., timeout)
 		if ctx.Err() == context.Canceled {
-			return "", fmt.Errorf("Ollama request canceled: %w", ctx.Err())
+			return nil, false, c.opError(op, path, fmt.Errorf("request canceled: %w", ctx.Err()))
 		}
 		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("Ollama request timed out: %w", ctx.Err())
+			return nil, false, c.opError(op, path, fmt.Errorf("request timed out: %w", ctx.Err()))
 		}
-		return "", fmt.Errorf("failed to send request to Ollama server at %s: %w", requestURL, err)
-	}
-	defer resp.Body.Close()
-
-	// Read the response body
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read Ollama response body: %w", err)
+		c.reportHealth(false)
+		return nil, true, c.opError(op, path, fmt.Errorf("failed to send request: %w", err))
 	}
+	c.reportHealth(resp.StatusCode < http.StatusInternalServerError)
+	llm.CaptureRawResponse(resp)
 
-	// Check HTTP status code
+	// Check HTTP status code, taking more info from the body if possible
 	if resp.StatusCode != http.StatusOK {
-		// Attempt to get more info from the body if possible
-		var errResp ollamaGenerateResponse
-		if json.Unmarshal(responseBody, &errResp) == nil && errResp.Error != "" {
-			return "", fmt.Errorf("Ollama API error (status %d): %s. Raw: %s", resp.StatusCode, errResp.Error, string(responseBody))
+		defer resp.Body.Close()
+		var errResp struct {
+			Error string `json:"error"`
 		}
-		return "", fmt.Errorf("Ollama API request failed with status %s. Raw: %s", resp.Status, string(responseBody))
+		responseBody, _ := llm.DecodeJSON(resp.Body, &errResp)
+		return nil, false, c.newAPIError(op, path, resp.StatusCode, errResp.Error, responseBody)
 	}
+	return resp, false, nil
+}
 
-	// Parse the response
-	var ollamaResp ollamaGenerateResponse
-	if err := json.Unmarshal(responseBody, &ollamaResp); err != nil {
-		return "", fmt.Errorf("failed to unmarshal Ollama response JSON: %w. Raw response: %s", err, string(responseBody))
+// reportHealth records whether the client's server handled a request, if
+// the client balances across several servers.
+func (c *Client) reportHealth(healthy bool) {
+	if c.hosts != nil {
+		c.hosts.report(c.baseURL, healthy)
 	}
+}
 
-	if ollamaResp.Error != "" {
-		return "", fmt.Errorf("Ollama returned an error in response: %s", ollamaResp.Error)
+// newAPIError describes a failed Ollama response. Ollama reports failures
+// as {"error": "..."} without codes or request IDs.
+func (c *Client) newAPIError(op, path string, status int, message string, body string) *llm.Error {
+	return &llm.Error{
+		Provider:   providerName,
+		Op:         op,
+		Endpoint:   c.baseURL + path,
+		StatusCode: status,
+		Message:    message,
+		Body:       body,
+		Kind:       classifyAPIError(status, message),
 	}
+}
 
-	// The main generated text is in the "response" field
-	if !ollamaResp.Done && ollamaResp.Response == "" {
-		// This might happen if 'done' is false but no response is given yet,
-		// which is unusual for stream=false.
-		return "", fmt.Errorf("Ollama response indicates not done but no text was returned")
+// opError describes a call that failed without an API error response,
+// e.g. because the request could not be sent or decoded.
+func (c *Client) opError(op, path string, err error) *llm.Error {
+	return llm.NewError(providerName, op, c.baseURL+path, err)
+}
+
+// classifyAPIError picks the sentinel error for a failed Ollama API call from
+// the HTTP status and the error message in the response body.
+func classifyAPIError(status int, message string) error {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "not found") && strings.Contains(lower, "model"):
+		return llm.ErrModelNotFound
+	case llm.IsContextLengthMessage(message):
+		return llm.ErrContextTooLong
+	}
+	return llm.StatusError(status)
+}
+
+// Init implements xollm.Initializer. The client has no expensive setup to
+// defer, so Init only checks that it was created with NewClient.
+func (c *Client) Init(ctx context.Context) error {
+	if c.httpClient == nil {
+		return fmt.Errorf("Ollama client not initialized")
 	}
+	return nil
+}
 
-	return strings.TrimSpace(ollamaResp.Response), nil
+// ModelName returns the name of the model the client sends requests to.
+func (c *Client) ModelName() string {
+	return c.modelName
 }
 
 // ProviderName returns the name of this provider.
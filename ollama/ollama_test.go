@@ -1,12 +1,20 @@
 package ollama
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/xostack/xollm/llm"
 )
 
 func TestNewClient_Success(t *testing.T) {
@@ -32,6 +40,27 @@ func TestNewClient_Success(t *testing.T) {
 	}
 }
 
+func TestClient_Init(t *testing.T) {
+	client, _ := NewClient(context.Background(), "http://localhost:11434", "", 30, false)
+	if err := client.Init(context.Background()); err != nil {
+		t.Errorf("Expected constructed client to be ready, got: %v", err)
+	}
+	if err := (&Client{}).Init(context.Background()); err == nil || err.Error() != "Ollama client not initialized" {
+		t.Errorf("Expected not initialized error, got: %v", err)
+	}
+}
+
+func TestNewClient_SharesTransport(t *testing.T) {
+	a, _ := NewClient(context.Background(), "http://localhost:11434", "", 30, false)
+	b, _ := NewClient(context.Background(), "http://localhost:11434", "", 10, false)
+	if a.httpClient.Transport != llm.SharedTransport() || b.httpClient.Transport != a.httpClient.Transport {
+		t.Error("Expected clients to pool connections through the shared transport")
+	}
+	if a.httpClient.Timeout == b.httpClient.Timeout {
+		t.Error("Expected each client to keep its own timeout")
+	}
+}
+
 func TestNewClient_EmptyBaseURL(t *testing.T) {
 	client, err := NewClient(context.Background(), "", "", 30, false)
 	if err == nil {
@@ -210,6 +239,31 @@ func TestOllamaClient_Generate_MockServer_WithErrorField(t *testing.T) {
 	}
 }
 
+func TestOllamaClient_Generate_MockServer_InvalidJSON(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`not json`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.Generate(context.Background(), "Hello, world!")
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *llm.Error, got %T: %v", err, err)
+	}
+	if apiErr.Op != llm.OpGenerate || apiErr.Endpoint != mockServer.URL+generateAPIPath || apiErr.Body != "not json" {
+		t.Errorf("Unexpected error details: %+v", apiErr)
+	}
+	if !strings.HasPrefix(err.Error(), "ollama: generate "+mockServer.URL+"/api/generate: status 200: failed to decode response") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
 func TestOllamaClient_Generate_NilClient(t *testing.T) {
 	client := &Client{
 		httpClient: nil, // Nil HTTP client
@@ -338,3 +392,623 @@ func TestOllamaResponseParsing(t *testing.T) {
 		t.Errorf("Expected empty error, got '%s'", response.Error)
 	}
 }
+
+func TestOllamaClient_Generate_SentinelErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{name: "model not found", status: http.StatusNotFound, body: `{"error": "model 'llama9' not found, try pulling it first"}`, expected: llm.ErrModelNotFound},
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `too many requests`, expected: llm.ErrRateLimited},
+		{name: "unauthorized proxy", status: http.StatusUnauthorized, body: `unauthorized`, expected: llm.ErrAuthentication},
+		{name: "context length", status: http.StatusBadRequest, body: `{"error": "input length exceeds maximum context length"}`, expected: llm.ErrContextTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer mockServer.Close()
+
+			client, err := NewClient(context.Background(), mockServer.URL, "", 10, false)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			_, err = client.Generate(context.Background(), "Hello")
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected errors.Is(err, %v), got: %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestOllamaClient_Generate_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer mockServer.Close()
+	defer close(release)
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Generate(ctx, "Hello")
+	if !errors.Is(err, llm.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got: %v", err)
+	}
+}
+
+func TestOllamaClient_Generate_ErrorDetails(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "model 'llama9' not found"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.Generate(context.Background(), "Hello")
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *llm.Error, got %T: %v", err, err)
+	}
+	if apiErr.Provider != "ollama" || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "model 'llama9' not found" {
+		t.Errorf("Unexpected error details: %+v", apiErr)
+	}
+	if !strings.Contains(apiErr.Body, "llama9") {
+		t.Errorf("Expected raw body to be kept, got '%s'", apiErr.Body)
+	}
+}
+
+func TestOllamaClient_Generate_ContextLengthError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "input length exceeds maximum context length"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "gemma:2b", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.Generate(context.Background(), "Hello")
+	var cle *llm.ContextLengthError
+	if !errors.As(err, &cle) {
+		t.Fatalf("Expected *llm.ContextLengthError, got %T: %v", err, err)
+	}
+	if cle.Model != "gemma:2b" || cle.MaxTokens != 8192 {
+		t.Errorf("Expected model limit from registry, got %+v", cle)
+	}
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Error("Expected provider error details to remain reachable")
+	}
+}
+
+func TestOllamaClient_Warmup(t *testing.T) {
+	var got map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"model": "gemma:2b", "response": "", "done": true, "done_reason": "load"}`))
+	}))
+	defer mockServer.Close()
+
+	client, _ := NewClient(context.Background(), mockServer.URL, "", 10, false)
+	if err := client.Warmup(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got["model"] != "gemma:2b" || got["prompt"] != "" {
+		t.Errorf("Expected empty-prompt request for the model, got %v", got)
+	}
+}
+
+func TestOllamaClient_Warmup_ModelNotFound(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "model 'nope' not found, try pulling it first"}`))
+	}))
+	defer mockServer.Close()
+
+	client, _ := NewClient(context.Background(), mockServer.URL, "nope", 10, false)
+	err := client.Warmup(context.Background())
+	if !errors.Is(err, llm.ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got: %v", err)
+	}
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.Op != "warmup" {
+		t.Errorf("Expected warmup *llm.Error, got: %v", err)
+	}
+
+	if err := (&Client{}).Warmup(context.Background()); err == nil {
+		t.Error("Expected error for uninitialized client")
+	}
+}
+
+func TestOllamaClient_Ping(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/version" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"version": "0.5.7"}`))
+	}))
+	defer mockServer.Close()
+
+	client, _ := NewClient(context.Background(), mockServer.URL, "", 10, false)
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	mockServer.Close()
+	err := client.Ping(context.Background())
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) || apiErr.Op != "ping" || !errors.Is(err, llm.ErrUnavailable) {
+		t.Errorf("Expected unavailable ping *llm.Error, got: %v", err)
+	}
+
+	if err := (&Client{}).Ping(context.Background()); err == nil {
+		t.Error("Expected error for uninitialized client")
+	}
+}
+
+func TestOllamaClient_RuntimeOptions(t *testing.T) {
+	var sent []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaGenerateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req.Options)
+		w.Write([]byte(`{"response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, "llama3.1", 10, false,
+		llm.WithPreflightTokenCheck(),
+		llm.WithRuntimeOptions(llm.RuntimeOptions{NumCtx: llm.Int(1024), NumGPU: llm.Int(0), MirostatTau: llm.Float64(4.5), RepeatPenalty: llm.Float64(1.2)}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if err := client.Warmup(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := client.Generate(context.Background(), "Hi"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := map[string]interface{}{"num_ctx": 1024.0, "num_gpu": 0.0, "mirostat_tau": 4.5, "repeat_penalty": 1.2}
+	for i, options := range sent {
+		if !reflect.DeepEqual(options, expected) {
+			t.Errorf("Request %d: expected options %v, got %v", i, expected, options)
+		}
+	}
+
+	// num_ctx, not the model's 128k window, limits prompts
+	_, err = client.Generate(context.Background(), strings.Repeat("word ", 2000))
+	var clErr *llm.ContextLengthError
+	if !errors.As(err, &clErr) || clErr.MaxTokens != 1024 {
+		t.Errorf("Expected pre-flight rejection against num_ctx, got: %v", err)
+	}
+	if len(sent) != 2 {
+		t.Errorf("Expected the oversized prompt not to be sent, got %d requests", len(sent))
+	}
+
+	// Without num_ctx, Ollama's default window limits prompts
+	client, err = NewClient(context.Background(), server.URL, "llama3.1", 10, false, llm.WithPreflightTokenCheck())
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if client.ContextWindow() != DefaultContextLength {
+		t.Errorf("Expected the default window, got %d", client.ContextWindow())
+	}
+	_, err = client.Generate(context.Background(), strings.Repeat("word ", 3000))
+	if !errors.As(err, &clErr) || clErr.MaxTokens != DefaultContextLength {
+		t.Errorf("Expected pre-flight rejection against the default window, got: %v", err)
+	}
+}
+
+func TestRuntimeOptions_Unset(t *testing.T) {
+	if options := runtimeOptions(llm.RuntimeOptions{}); options != nil {
+		t.Errorf("Expected no options object, got %v", options)
+	}
+}
+
+func TestOllamaClient_Sampling(t *testing.T) {
+	var sent ollamaGenerateRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = ollamaGenerateRequest{}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model": "gemma:2b", "response": "ok", "done": true}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false,
+		llm.WithSampling(llm.Sampling{Temperature: llm.Float64(0.2), MaxTokens: llm.Int(64)}),
+		llm.WithRuntimeOptions(llm.RuntimeOptions{NumCtx: llm.Int(4096)}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.Generate(context.Background(), "Hi"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent.Options["temperature"] != 0.2 || sent.Options["num_predict"] != float64(64) || sent.Options["num_ctx"] != float64(4096) {
+		t.Errorf("Expected default sampling alongside the runtime options, got %v", sent.Options)
+	}
+
+	_, err = client.GenerateWithSampling(context.Background(), "Hi", llm.Sampling{Temperature: llm.Float64(0.9), TopP: llm.Float64(0.5), Stop: []string{"END"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent.Options["temperature"] != 0.9 || sent.Options["top_p"] != 0.5 || sent.Options["num_predict"] != float64(64) {
+		t.Errorf("Expected per-call overrides on top of the defaults, got %v", sent.Options)
+	}
+	if stop, ok := sent.Options["stop"].([]interface{}); !ok || len(stop) != 1 || stop[0] != "END" {
+		t.Errorf("Expected stop sequences to be sent, got %v", sent.Options["stop"])
+	}
+
+	if _, err := client.GenerateWithSampling(context.Background(), "Hi", llm.Sampling{Seed: llm.Int(42)}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent.Options["seed"] != float64(42) {
+		t.Errorf("Expected the seed to be sent, got %v", sent.Options["seed"])
+	}
+
+	_, err = client.GenerateWithSampling(context.Background(), "Hi", llm.Sampling{PresencePenalty: llm.Float64(0.6), FrequencyPenalty: llm.Float64(0.4)})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent.Options["presence_penalty"] != 0.6 || sent.Options["frequency_penalty"] != 0.4 {
+		t.Errorf("Expected the penalties to be sent, got %v", sent.Options)
+	}
+}
+
+func TestOllamaClient_GenerateWithMetadata(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"model": "gemma:2b",
+			"response": " Hi there ",
+			"done": true,
+			"done_reason": "length",
+			"total_duration": 5000000000,
+			"prompt_eval_count": 12,
+			"prompt_eval_duration": 1000000000,
+			"eval_count": 30,
+			"eval_duration": 3000000000
+		}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	text, md, err := client.GenerateWithMetadata(context.Background(), "Hello")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != "Hi there" {
+		t.Errorf("Expected trimmed text, got %q", text)
+	}
+	if md.Model != "gemma:2b" || md.FinishReason != "length" {
+		t.Errorf("Unexpected metadata: %+v", md)
+	}
+	if md.Usage.PromptTokens != 12 || md.Usage.CompletionTokens != 30 {
+		t.Errorf("Unexpected usage: %+v", md.Usage)
+	}
+	if md.Timing == nil || md.Timing.Total != 5*time.Second || md.Timing.Completion != 3*time.Second {
+		t.Errorf("Unexpected timing: %+v", md.Timing)
+	}
+}
+
+func TestOllamaClient_RawResponse(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model": "gemma:2b", "response": "Hi", "done": true, "load_duration": 42}`))
+	})
+
+	ctx, raw := llm.WithRawResponse(context.Background())
+	if _, err := client.Generate(ctx, "Hello"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(string(raw.Body()), `"load_duration": 42`) {
+		t.Errorf("Expected the raw body, got %q", raw.Body())
+	}
+}
+
+func TestOllamaClient_DryRun(t *testing.T) {
+	client, err := NewClient(context.Background(), "http://127.0.0.1:1", "gemma:2b", 5, false)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.Generate(llm.WithDryRun(context.Background()), "Hello")
+	var dryRun *llm.DryRunError
+	if !errors.As(err, &dryRun) {
+		t.Fatalf("Expected a *llm.DryRunError, got %v", err)
+	}
+	if dryRun.Request.URL != "http://127.0.0.1:1"+generateAPIPath || !strings.Contains(string(dryRun.Request.Body), `"prompt":"Hello"`) {
+		t.Errorf("Unexpected rendered request: %s", dryRun.Request)
+	}
+}
+
+func TestOllamaClient_Logger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model": "gemma:2b", "response": "hi", "done": true}`))
+	}))
+	defer server.Close()
+
+	var level slog.LevelVar
+	level.Set(slog.LevelInfo)
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: &level}))
+
+	client, err := NewClient(context.Background(), server.URL, "", 10, false, llm.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.Generate(context.Background(), "hello"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no debug output at info level, got: %s", out.String())
+	}
+
+	// Verbosity changes at runtime through the handler's level
+	level.Set(slog.LevelDebug)
+	if _, err := client.Generate(context.Background(), "hello"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "sending Ollama request") || !strings.Contains(out.String(), "path="+generateAPIPath) {
+		t.Errorf("Expected a debug record for the request, got: %s", out.String())
+	}
+}
+
+func TestOllamaClient_WithModel(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaGenerateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		w.Write([]byte(`{"response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, "gemma:2b", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	derived := client.WithModel("llama3.1")
+	if derived.httpClient != client.httpClient {
+		t.Error("Expected the derived client to share the HTTP client")
+	}
+	if client.WithModel("").modelName != "gemma:2b" {
+		t.Error("Expected an empty model to keep the client's model")
+	}
+
+	client.Generate(context.Background(), "hi")
+	derived.Generate(context.Background(), "hi")
+	if len(models) != 2 || models[0] != "gemma:2b" || models[1] != "llama3.1" {
+		t.Errorf("Expected each client to use its own model, got %v", models)
+	}
+}
+
+func TestOllamaClient_WithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewClient(context.Background(), server.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	derived := client.WithTimeout(50 * time.Millisecond)
+	if derived.httpClient.Transport != client.httpClient.Transport || client.httpClient.Timeout != 10*time.Second {
+		t.Error("Expected the derived client to share the transport without changing the original")
+	}
+	if _, err := derived.Generate(context.Background(), "Hello"); !errors.Is(err, llm.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got: %v", err)
+	}
+	if client.WithTimeout(-1).httpClient.Timeout != 0 {
+		t.Error("Expected a negative timeout to disable the client timeout")
+	}
+}
+
+func TestOllamaClient_WithRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := llm.WithRequestTimeout(context.Background(), 20*time.Millisecond)
+	if _, err := client.Generate(ctx, "Hello"); !errors.Is(err, llm.ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got: %v", err)
+	}
+
+	short := client.WithTimeout(20 * time.Millisecond)
+	ctx = llm.WithRequestTimeout(context.Background(), 5*time.Second)
+	if _, err := short.Generate(ctx, "Hello"); err != nil {
+		t.Errorf("Expected the request timeout to extend the client's, got: %v", err)
+	}
+}
+
+func TestOllamaClient_ConcurrentUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model": "gemma:2b", "response": "ok", "done": true, "context": [1, 2]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	session := client.NewSession()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+			if _, err := client.Generate(ctx, "hi"); err != nil {
+				errs <- err
+			}
+			if _, err := client.WithModel("llama3.1").Generate(ctx, "hi"); err != nil {
+				errs <- err
+			}
+			if _, err := session.Generate(ctx, "hi"); err != nil {
+				errs <- err
+			}
+			chunks, err := client.GenerateStream(ctx, "hi")
+			if err != nil {
+				errs <- err
+				return
+			}
+			for chunk := range chunks {
+				if chunk.Err != nil {
+					errs <- chunk.Err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent call failed: %v", err)
+	}
+}
+
+func TestOllamaClient_UserAgent(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"model": "gemma:2b", "response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(context.Background(), server.URL, "", 10, false, llm.WithAppIdentifier("notes-app/1.0"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.Generate(context.Background(), "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if userAgent != llm.UserAgent("notes-app/1.0") || !strings.HasPrefix(userAgent, "xollm/") {
+		t.Errorf("Unexpected User-Agent %q", userAgent)
+	}
+}
+
+func TestOllamaClient_GenerateJSON(t *testing.T) {
+	var sent ollamaGenerateRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model": "gemma:2b", "response": "{\"name\": \"Ada\"}", "done": true}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	schema := `{"type":"object","properties":{"name":{"type":"string"}}}`
+	text, err := client.GenerateJSON(context.Background(), "Who?", json.RawMessage(schema))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != `{"name": "Ada"}` || string(sent.Format) != schema {
+		t.Errorf("Expected the schema as the format, got %s and text %q", sent.Format, text)
+	}
+
+	if _, err := client.GenerateJSON(context.Background(), "Who?", nil); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(sent.Format) != `"json"` {
+		t.Errorf("Expected JSON mode without a schema, got %s", sent.Format)
+	}
+}
+
+func TestOllamaClient_GenerateWithImages(t *testing.T) {
+	var sent ollamaGenerateRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model": "llava", "response": "A cat.", "done": true}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "llava", 10, false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	text, err := client.GenerateWithImages(context.Background(), "What is this?", []llm.Image{{MIMEType: "image/png", Data: []byte("png")}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text != "A cat." || sent.Prompt != "What is this?" || len(sent.Images) != 1 || sent.Images[0] != "cG5n" {
+		t.Errorf("Expected the image as base64 data, got %+v and text %q", sent, text)
+	}
+}
+
+func TestOllamaClient_SystemPrompt(t *testing.T) {
+	var sentGenerate ollamaGenerateRequest
+	var sentChat ollamaChatRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == chatAPIPath {
+			json.NewDecoder(r.Body).Decode(&sentChat)
+			w.Write([]byte(`{"model": "gemma:2b", "message": {"role": "assistant", "content": "Bonjour"}, "done": true}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&sentGenerate)
+		w.Write([]byte(`{"model": "gemma:2b", "response": "Bonjour", "done": true}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := NewClient(context.Background(), mockServer.URL, "", 10, false, llm.WithSystemPrompt("Answer in French."))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.Generate(context.Background(), "Hello"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sentGenerate.System != "Answer in French." || sentGenerate.Prompt != "Hello" {
+		t.Errorf("Expected the system prompt in the system field, got %+v", sentGenerate)
+	}
+
+	if _, _, err := client.Chat(context.Background(), []llm.Message{{Role: llm.RoleUser, Content: "Hello"}}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(sentChat.Messages) != 2 || sentChat.Messages[0].Role != "system" || sentChat.Messages[0].Content != "Answer in French." {
+		t.Errorf("Expected the system prompt as the first chat message, got %+v", sentChat.Messages)
+	}
+}
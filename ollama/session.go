@@ -0,0 +1,86 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// Session is a multi-turn conversation on /api/generate. Ollama returns the
+// encoded conversation as context tokens with every response; the session
+// sends them back with the next prompt, so earlier turns don't have to be
+// re-sent and re-evaluated.
+//
+// A Session implements the same Client interface as the client it came
+// from, so it can be used wherever a client is expected. Calls on one
+// session are serialized to keep the turns in order; use one session per
+// conversation.
+//
+// With llm.WithStickySessions, the turns of a session on a balanced client
+// (see NewBalancedClient) go to the same server while it is healthy, so
+// the server keeps the conversation cached.
+type Session struct {
+	client *Client
+	key    string // session key of the turns, unless ctx has one
+
+	mu      sync.Mutex
+	context []int
+}
+
+// sessionCount numbers sessions for their session keys.
+var sessionCount atomic.Uint64
+
+// NewSession starts a conversation with no earlier turns.
+func (c *Client) NewSession() *Session {
+	return &Session{client: c, key: fmt.Sprintf("ollama-session-%d", sessionCount.Add(1))}
+}
+
+// Generate sends the prompt as the next turn of the conversation. The
+// session keeps its context unchanged if the call fails.
+func (s *Session) Generate(ctx context.Context, prompt string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if llm.SessionKeyFromContext(ctx) == "" {
+		ctx = llm.WithSessionKey(ctx, s.key)
+	}
+	text, resp, err := s.client.generate(ctx, prompt, s.context, llm.Sampling{})
+	if err != nil {
+		return "", err
+	}
+	s.context = resp.Context
+	return text, nil
+}
+
+// Context returns a copy of the context tokens from the latest turn, e.g. to
+// store the conversation and resume it later with SetContext.
+func (s *Session) Context() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.context...)
+}
+
+// SetContext replaces the conversation with context tokens returned
+// earlier by Context.
+func (s *Session) SetContext(tokens []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.context = append([]int(nil), tokens...)
+}
+
+// Reset forgets the conversation so the next prompt starts fresh.
+func (s *Session) Reset() {
+	s.SetContext(nil)
+}
+
+// ProviderName returns the name of this provider.
+func (s *Session) ProviderName() string {
+	return providerName
+}
+
+// Close is a no-op; the session does not own its client.
+func (s *Session) Close() error {
+	return nil
+}
@@ -0,0 +1,57 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSession_ReusesContext(t *testing.T) {
+	var sent [][]int
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaGenerateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req.Context)
+		if req.Prompt == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "boom"}`))
+			return
+		}
+		next := append(append([]int(nil), req.Context...), len(req.Context)+1)
+		json.NewEncoder(w).Encode(ollamaGenerateResponse{Response: "ok", Done: true, Context: next})
+	})
+
+	session := client.NewSession()
+	for _, prompt := range []string{"one", "two", "fail"} {
+		session.Generate(context.Background(), prompt)
+	}
+	expected := [][]int{nil, {1}, {1, 2}}
+	if !reflect.DeepEqual(sent, expected) {
+		t.Errorf("Expected contexts %v, got %v", expected, sent)
+	}
+	if !reflect.DeepEqual(session.Context(), []int{1, 2}) {
+		t.Errorf("Expected failed turn to keep the context, got %v", session.Context())
+	}
+
+	// A resumed session continues from the stored context
+	resumed := client.NewSession()
+	resumed.SetContext([]int{7})
+	resumed.Generate(context.Background(), "three")
+	if !reflect.DeepEqual(sent[3], []int{7}) {
+		t.Errorf("Expected stored context to be sent, got %v", sent[3])
+	}
+
+	session.Reset()
+	session.Generate(context.Background(), "four")
+	if sent[4] != nil {
+		t.Errorf("Expected no context after Reset, got %v", sent[4])
+	}
+
+	// Plain client calls never send a context
+	client.Generate(context.Background(), "five")
+	if sent[5] != nil {
+		t.Errorf("Expected plain Generate to send no context, got %v", sent[5])
+	}
+}
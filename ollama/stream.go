@@ -0,0 +1,93 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// GenerateStream implements xollm.Streamer. Ollama streams the generation
+// as newline-delimited JSON objects, each carrying the next piece of text;
+// the last one has done set and the token counts, which are delivered as
+// the Usage of the Done chunk. The client's request timeout covers the
+// whole stream.
+func (c *Client) GenerateStream(ctx context.Context, prompt string) (<-chan llm.StreamChunk, error) {
+	if c.httpClient == nil {
+		return nil, fmt.Errorf("Ollama client not initialized")
+	}
+	if err := c.preflight(prompt); err != nil {
+		return nil, err
+	}
+
+	payload := c.generateRequest(prompt, llm.Sampling{})
+	payload.Stream = true
+	resp, hc, err := c.send(ctx, c.requestClient(ctx), http.MethodPost, llm.OpGenerate, generateAPIPath, payload)
+	if err != nil {
+		return nil, llm.WrapContextLength(c.modelName, err)
+	}
+
+	chunks := make(chan llm.StreamChunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+		hc.readStream(ctx, resp, chunks)
+	}()
+	return chunks, nil
+}
+
+// readStream decodes the streamed objects in resp and sends them as chunks
+// until the final object, an error or the end of the body. It stops early
+// if ctx is cancelled while the consumer is not reading.
+func (c *Client) readStream(ctx context.Context, resp *http.Response, chunks chan<- llm.StreamChunk) {
+	emit := func(chunk llm.StreamChunk) bool {
+		select {
+		case chunks <- chunk:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	fail := func(err error) {
+		emit(llm.StreamChunk{Err: llm.WrapContextLength(c.modelName, err)})
+	}
+
+	dec := llm.NewNDJSONDecoder(resp.Body)
+	for {
+		var part ollamaGenerateResponse
+		err := dec.Decode(&part)
+		if errors.Is(err, io.EOF) {
+			fail(c.opError(llm.OpGenerate, generateAPIPath, fmt.Errorf("stream ended before the response was done")))
+			return
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				fail(c.opError(llm.OpGenerate, generateAPIPath, fmt.Errorf("stream interrupted: %w", ctx.Err())))
+				return
+			}
+			fail(c.opError(llm.OpGenerate, generateAPIPath, fmt.Errorf("failed to decode stream: %w", err)))
+			return
+		}
+		if part.Error != "" {
+			fail(c.newAPIError(llm.OpGenerate, generateAPIPath, resp.StatusCode, part.Error, ""))
+			return
+		}
+		if part.Response != "" && !emit(llm.StreamChunk{Text: part.Response}) {
+			return
+		}
+		if part.Done {
+			emit(llm.StreamChunk{
+				Done: true,
+				Usage: &llm.Usage{
+					PromptTokens:     part.PromptEvalCount,
+					CompletionTokens: part.EvalCount,
+				},
+				FinishReason: part.DoneReason,
+			})
+			return
+		}
+	}
+}
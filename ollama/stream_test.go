@@ -0,0 +1,118 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// collect reads a stream to the end.
+func collect(chunks <-chan llm.StreamChunk) (string, llm.StreamChunk) {
+	var text strings.Builder
+	var last llm.StreamChunk
+	for chunk := range chunks {
+		text.WriteString(chunk.Text)
+		last = chunk
+	}
+	return text.String(), last
+}
+
+func TestOllamaClient_GenerateStream(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaGenerateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("Expected a streaming request")
+		}
+		w.Write([]byte(`{"response": "Hel", "done": false}
+{"response": "lo!", "done": false}
+{"response": "", "done": true, "done_reason": "length", "prompt_eval_count": 5, "eval_count": 2, "total_duration": 1000000}
+`))
+	})
+
+	chunks, err := client.GenerateStream(context.Background(), "Hi")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text, last := collect(chunks)
+	if text != "Hello!" {
+		t.Errorf("Expected \"Hello!\", got %q", text)
+	}
+	if !last.Done || last.Err != nil {
+		t.Fatalf("Expected Done as the final chunk, got %+v", last)
+	}
+	if last.Usage == nil || last.Usage.PromptTokens != 5 || last.Usage.CompletionTokens != 2 {
+		t.Errorf("Expected usage from the final object, got %+v", last.Usage)
+	}
+	if last.FinishReason != "length" {
+		t.Errorf("Expected the done reason on the final chunk, got %q", last.FinishReason)
+	}
+}
+
+func TestOllamaClient_GenerateStream_Failures(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"error object", `{"response": "a", "done": false}` + "\n" + `{"error": "model crashed"}`, "model crashed"},
+		{"truncated", `{"response": "a", "done": false}` + "\n", "stream ended before the response was done"},
+		{"invalid JSON", `{"response": "a", "done": false}` + "\n" + `not json`, "failed to decode stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			})
+			chunks, err := client.GenerateStream(context.Background(), "Hi")
+			if err != nil {
+				t.Fatalf("Expected no error starting the stream, got: %v", err)
+			}
+			text, last := collect(chunks)
+			if text != "a" {
+				t.Errorf("Expected text before the failure, got %q", text)
+			}
+			var llmErr *llm.Error
+			if !errors.As(last.Err, &llmErr) || !strings.Contains(last.Err.Error(), tt.expected) {
+				t.Errorf("Expected *llm.Error containing %q, got: %v", tt.expected, last.Err)
+			}
+		})
+	}
+}
+
+func TestOllamaClient_GenerateStream_ModelNotFound(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "model 'nope' not found, try pulling it first"}`))
+	})
+	_, err := client.GenerateStream(context.Background(), "Hi")
+	if !errors.Is(err, llm.ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound before streaming, got: %v", err)
+	}
+}
+
+func TestOllamaClient_GenerateStream_Cancel(t *testing.T) {
+	client := newModelsTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 100; i++ {
+			w.Write([]byte(`{"response": "x", "done": false}` + "\n"))
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks, err := client.GenerateStream(ctx, "Hi")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	<-chunks
+	cancel()
+	for range chunks {
+		// The stream must still be closed after cancellation
+	}
+}
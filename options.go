@@ -0,0 +1,139 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// ClientOption sets an optional client setting. Pass options as trailing
+// arguments to a provider's NewClient. See llm.ClientOption.
+type ClientOption = llm.ClientOption
+
+// WithPreflightTokenCheck makes the client estimate each prompt's size with
+// EstimateTokens and fail with a *ContextLengthError, without sending the
+// request, when the prompt can't fit in the model's known context window.
+// The error has Estimated set and matches ErrContextTooLong.
+//
+// The estimate errs on the high side, so prompts very close to the limit
+// may be rejected even though they would fit.
+func WithPreflightTokenCheck() ClientOption {
+	return llm.WithPreflightTokenCheck()
+}
+
+// WithLazyInit defers expensive client setup, such as creating the Gemini
+// SDK client, from NewClient to the first call. Use Init to validate the
+// client eagerly, e.g. at startup.
+func WithLazyInit() ClientOption {
+	return llm.WithLazyInit()
+}
+
+// WithSystemPrompt sets a system prompt that the client sends with every
+// request as the provider's native system instruction, rather than as part
+// of the user text: Gemini clients set it as the model's
+// system_instruction, Groq clients send it as the first message, in the
+// system role, and Ollama clients as the system field, which replaces the
+// Modelfile's. For a system prompt per call, see GenerateWithSystem.
+func WithSystemPrompt(prompt string) ClientOption {
+	return llm.WithSystemPrompt(prompt)
+}
+
+// Sampling holds the temperature, max tokens, top_p, stop sequences, seed
+// and repetition penalties of requests. Nil fields leave the provider's
+// default in place. See llm.Sampling.
+type Sampling = llm.Sampling
+
+// WithSampling sets the default sampling parameters of the client's
+// requests. All bundled providers send them with every call; override them
+// for a single call with GenerateWithSampling.
+//
+//	client, err := groq.NewClient(ctx, apiKey, "", 30, false, xollm.WithSampling(xollm.Sampling{
+//		Temperature: xollm.Float64(0.2),
+//		MaxTokens:   xollm.Int(512),
+//	}))
+func WithSampling(s Sampling) ClientOption {
+	return llm.WithSampling(s)
+}
+
+// SamplingGenerator is implemented by clients that accept sampling
+// parameters per call. All bundled providers implement it.
+type SamplingGenerator = llm.SamplingGenerator
+
+// GenerateWithSampling is Generate with the fields set in sampling
+// overriding the client's default sampling parameters for this call only.
+// It fails for clients that don't implement SamplingGenerator.
+//
+//	text, err := xollm.GenerateWithSampling(ctx, client, prompt, xollm.Sampling{
+//		Temperature: xollm.Float64(0),
+//		Stop:        []string{"\n\n"},
+//	})
+func GenerateWithSampling(ctx context.Context, client Client, prompt string, sampling Sampling) (string, error) {
+	sg, ok := client.(SamplingGenerator)
+	if !ok {
+		return "", fmt.Errorf("%s client does not support per-call sampling", client.ProviderName())
+	}
+	return sg.GenerateWithSampling(ctx, prompt, sampling)
+}
+
+// RuntimeOptions configure how a self-hosted server runs the model:
+// context window, GPU offload, threads and Mirostat sampling. See
+// llm.RuntimeOptions.
+type RuntimeOptions = llm.RuntimeOptions
+
+// WithRuntimeOptions sets model runtime settings, sent by Ollama clients
+// as the request options. NumCtx also sets the window used by the
+// pre-flight check.
+//
+//	client, err := ollama.NewClient(ctx, baseURL, "llama3.1", 60, false, xollm.WithRuntimeOptions(xollm.RuntimeOptions{
+//		NumCtx: xollm.Int(32768),
+//	}))
+func WithRuntimeOptions(r RuntimeOptions) ClientOption {
+	return llm.WithRuntimeOptions(r)
+}
+
+// WithOrganization attributes the client's requests to an organization
+// and project of the provider account, for billing attribution. Groq
+// clients send them as the OpenAI-style OpenAI-Organization and
+// OpenAI-Project headers; Gemini clients send the project as the Google
+// Cloud billing project. Empty values are not sent.
+func WithOrganization(organization, project string) ClientOption {
+	return llm.WithOrganization(organization, project)
+}
+
+// WithStickySessions makes a client that balances requests across several
+// hosts, such as an Ollama client created with several base URLs, send the
+// requests of one session to the same host while it is healthy, so the
+// host's cached conversation state is reused. Sessions are identified with
+// WithSessionKey; Ollama sessions (ollama.Session) are sticky on their own.
+func WithStickySessions() ClientOption {
+	return llm.WithStickySessions()
+}
+
+// Float64 returns a pointer to v, for filling Sampling literals.
+func Float64(v float64) *float64 {
+	return llm.Float64(v)
+}
+
+// Int returns a pointer to v, for filling Sampling literals.
+func Int(v int) *int {
+	return llm.Int(v)
+}
+
+// Initializer is implemented by clients whose setup can be deferred with
+// WithLazyInit.
+type Initializer interface {
+	// Init completes the client's setup if that hasn't happened yet. It is
+	// safe to call repeatedly and concurrently.
+	Init(ctx context.Context) error
+}
+
+// Init completes client's setup now, returning any configuration error
+// that would otherwise surface on the first call. Clients that don't
+// implement Initializer are always ready.
+func Init(ctx context.Context, client Client) error {
+	if initializer, ok := client.(Initializer); ok {
+		return initializer.Init(ctx)
+	}
+	return nil
+}
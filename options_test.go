@@ -0,0 +1,39 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm/config"
+)
+
+func TestInit(t *testing.T) {
+	if err := Init(context.Background(), &stubClient{}); err != nil {
+		t.Errorf("Expected clients without Initializer to be ready, got: %v", err)
+	}
+
+	cfg := config.NewConfig("gemini", 30, map[string]config.LLMConfig{
+		"gemini": {APIKey: "test-api-key"},
+	})
+	cfg.LazyInit = true
+	client, err := GetClient(cfg, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	defer client.Close()
+
+	if _, ok := client.(Initializer); !ok {
+		t.Fatal("Expected gemini client to implement Initializer")
+	}
+	if err := Init(context.Background(), client); err != nil {
+		t.Errorf("Expected Init to succeed, got: %v", err)
+	}
+}
+
+func TestGenerateWithSampling_Unsupported(t *testing.T) {
+	_, err := GenerateWithSampling(context.Background(), &stubClient{}, "Hi", Sampling{Temperature: Float64(0)})
+	if err == nil || !strings.Contains(err.Error(), "does not support per-call sampling") {
+		t.Errorf("Expected an unsupported error, got: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/xostack/xollm/config"
+)
+
+// Pool hands out shared clients built from one configuration, creating
+// each provider's client once on first use. All provider clients are safe
+// for concurrent use, so worker pools and HTTP handlers can share them
+// instead of constructing a client per request.
+//
+// Clients returned by a Pool belong to it: don't Close them, Close the
+// Pool when done instead, or Shutdown it to let in-flight generations
+// registered with Begin finish first.
+//
+//	pool := xollm.NewPool(cfg, false)
+//	defer pool.Close()
+//
+//	http.HandleFunc("/ask", func(w http.ResponseWriter, r *http.Request) {
+//		ctx, done, err := pool.Begin(r.Context())
+//		if err != nil {
+//			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//			return
+//		}
+//		defer done()
+//		client, err := pool.Default()
+//		// ...
+//	})
+type Pool struct {
+	cfg       config.Config
+	debugMode bool
+	drainer   Drainer
+
+	mu      sync.Mutex
+	clients map[string]Client
+	closed  bool
+}
+
+// NewPool creates a pool for cfg. No clients are created until they are
+// first requested, so configuration errors surface from Get. debugMode is
+// deprecated and passed on to GetClient; pass false and set the logger
+// with SetDefaultLogger.
+func NewPool(cfg config.Config, debugMode bool) *Pool {
+	return &Pool{
+		cfg:       cfg,
+		debugMode: debugMode,
+		clients:   make(map[string]Client),
+	}
+}
+
+// Default returns the shared client for the configuration's default
+// provider.
+func (p *Pool) Default() (Client, error) {
+	return p.Get(p.cfg.DefaultProvider)
+}
+
+// Get returns the shared client for provider, creating it with GetClient
+// on first use. Failed creations are not cached, so a later call retries.
+func (p *Pool) Get(provider string) (Client, error) {
+	if provider == "" {
+		return nil, fmt.Errorf("no default LLM provider specified in configuration")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, fmt.Errorf("client pool is closed")
+	}
+	if client, ok := p.clients[provider]; ok {
+		return client, nil
+	}
+
+	cfg := p.cfg
+	cfg.DefaultProvider = provider
+	client, err := GetClient(cfg, p.debugMode)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[provider] = client
+	return client, nil
+}
+
+// Reload replaces the pool's configuration, e.g. after the configuration
+// file changed. API keys that changed are rotated on the existing clients
+// with SetCredentials, so in-flight requests are not interrupted. Other
+// settings apply to clients created from now on; existing clients keep
+// them until the pool is closed. Failed rotations are returned, and those
+// clients keep their old key.
+func (p *Pool) Reload(cfg config.Config) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return fmt.Errorf("client pool is closed")
+	}
+	old := p.cfg
+	p.cfg = cfg
+
+	var errs []error
+	for name, client := range p.clients {
+		key := cfg.LLMs[name].APIKey
+		if key == "" || key == old.LLMs[name].APIKey {
+			continue
+		}
+		if err := SetCredentials(client, key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to rotate %s credentials: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Providers returns the providers with a client in the pool, sorted.
+func (p *Pool) Providers() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.clients))
+	for name := range p.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Begin registers a generation with the pool so Shutdown waits for it. Use
+// the returned context for the generation and call done when it ends. It
+// fails with ErrShuttingDown once Shutdown was called. See Drainer.Begin.
+func (p *Pool) Begin(ctx context.Context) (context.Context, func(), error) {
+	return p.drainer.Begin(ctx)
+}
+
+// Shutdown stops the pool from accepting new generations, waits for those
+// registered with Begin until ctx is done, cancels any still running, and
+// then closes the pool's clients. Get keeps working until the clients are
+// closed, so in-flight work can still fall back to another provider.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	return errors.Join(p.drainer.Shutdown(ctx), p.Close())
+}
+
+// Close closes every client in the pool. Get fails afterwards.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+
+	var errs []error
+	for name, client := range p.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s client: %w", name, err))
+		}
+	}
+	p.clients = make(map[string]Client)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,180 @@
+package xollm
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/xostack/xollm/config"
+)
+
+// closeCountingClient records how often it was closed
+type closeCountingClient struct {
+	stubClient
+	closed int
+}
+
+func (c *closeCountingClient) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestPool_ReusesClients(t *testing.T) {
+	var mu sync.Mutex
+	created := map[string]int{}
+	originalGetClient := GetClient
+	GetClient = func(cfg config.Config, debugMode bool) (Client, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		created[cfg.DefaultProvider]++
+		return &closeCountingClient{}, nil
+	}
+	defer func() { GetClient = originalGetClient }()
+
+	pool := NewPool(config.NewConfig("ollama", 30, map[string]config.LLMConfig{
+		"ollama": {BaseURL: "http://localhost:11434"},
+		"groq":   {APIKey: "key"},
+	}), false)
+
+	var wg sync.WaitGroup
+	clients := make([]Client, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = pool.Default()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range clients {
+		if c != clients[0] {
+			t.Fatal("Expected every caller to get the same client")
+		}
+	}
+	if _, err := pool.Get("groq"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if created["ollama"] != 1 || created["groq"] != 1 {
+		t.Errorf("Expected one client per provider, got %v", created)
+	}
+	if !reflect.DeepEqual(pool.Providers(), []string{"groq", "ollama"}) {
+		t.Errorf("Unexpected providers: %v", pool.Providers())
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Expected no error closing, got: %v", err)
+	}
+	if clients[0].(*closeCountingClient).closed != 1 {
+		t.Error("Expected pooled client to be closed once")
+	}
+	if _, err := pool.Default(); err == nil {
+		t.Error("Expected error from a closed pool")
+	}
+}
+
+func TestPool_DoesNotCacheErrors(t *testing.T) {
+	calls := 0
+	originalGetClient := GetClient
+	GetClient = func(cfg config.Config, debugMode bool) (Client, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("not yet")
+		}
+		return &stubClient{}, nil
+	}
+	defer func() { GetClient = originalGetClient }()
+
+	pool := NewPool(config.Config{DefaultProvider: "ollama"}, false)
+	if _, err := pool.Default(); err == nil {
+		t.Fatal("Expected first creation to fail")
+	}
+	if _, err := pool.Default(); err != nil {
+		t.Errorf("Expected retry to succeed, got: %v", err)
+	}
+
+	if _, err := NewPool(config.Config{}, false).Default(); err == nil {
+		t.Error("Expected error without a default provider")
+	}
+}
+
+// credentialClient records the keys it was given
+type credentialClient struct {
+	stubClient
+	keys []string
+}
+
+func (c *credentialClient) SetCredentials(apiKey string) error {
+	c.keys = append(c.keys, apiKey)
+	return nil
+}
+
+func TestPool_Reload(t *testing.T) {
+	originalGetClient := GetClient
+	GetClient = func(cfg config.Config, debugMode bool) (Client, error) {
+		if cfg.DefaultProvider == "ollama" {
+			return &stubClient{}, nil
+		}
+		return &credentialClient{}, nil
+	}
+	defer func() { GetClient = originalGetClient }()
+
+	pool := NewPool(config.NewConfig("groq", 30, map[string]config.LLMConfig{
+		"groq":   {APIKey: "old-groq"},
+		"gemini": {APIKey: "old-gemini"},
+		"ollama": {BaseURL: "http://localhost:11434"},
+	}), false)
+	groq, _ := pool.Get("groq")
+	gemini, _ := pool.Get("gemini")
+	pool.Get("ollama")
+
+	err := pool.Reload(config.NewConfig("groq", 30, map[string]config.LLMConfig{
+		"groq":   {APIKey: "new-groq"},
+		"gemini": {APIKey: "old-gemini"},
+		"ollama": {BaseURL: "http://localhost:11434"},
+	}))
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if keys := groq.(*credentialClient).keys; !reflect.DeepEqual(keys, []string{"new-groq"}) {
+		t.Errorf("Expected the groq key to be rotated, got %v", keys)
+	}
+	if keys := gemini.(*credentialClient).keys; len(keys) != 0 {
+		t.Errorf("Expected the unchanged gemini key to be left alone, got %v", keys)
+	}
+	if again, _ := pool.Get("groq"); again != groq {
+		t.Error("Expected Reload to keep the existing client")
+	}
+}
+
+func TestPool_Reload_UnsupportedClient(t *testing.T) {
+	originalGetClient := GetClient
+	GetClient = func(cfg config.Config, debugMode bool) (Client, error) {
+		return &stubClient{}, nil
+	}
+	defer func() { GetClient = originalGetClient }()
+
+	pool := NewPool(config.NewConfig("groq", 30, map[string]config.LLMConfig{"groq": {APIKey: "a"}}), false)
+	pool.Default()
+
+	err := pool.Reload(config.NewConfig("groq", 30, map[string]config.LLMConfig{"groq": {APIKey: "b"}}))
+	if err == nil || !containsAll(err.Error(), "groq", "does not support credential rotation") {
+		t.Errorf("Expected a rotation error, got %v", err)
+	}
+
+	pool.Close()
+	if err := pool.Reload(config.Config{}); err == nil {
+		t.Error("Expected Reload to fail on a closed pool")
+	}
+}
+
+func containsAll(s string, parts ...string) bool {
+	for _, p := range parts {
+		if !strings.Contains(s, p) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,26 @@
+package xollm
+
+import "github.com/xostack/xollm/llm"
+
+// Pricing is the price of a model's input, output and cached input tokens
+// in US dollars per million tokens. See llm.Pricing.
+type Pricing = llm.Pricing
+
+// LookupPricing returns the pricing of a model served by provider, from
+// RegisterPricing or the bundled pricing table. Models of self-hosted
+// providers such as Ollama are free.
+func LookupPricing(provider, model string) (Pricing, bool) {
+	return llm.LookupPricing(provider, model)
+}
+
+// RegisterPricing sets the pricing of a model, for models the bundled table
+// lacks or negotiated rates.
+func RegisterPricing(model string, pricing Pricing) {
+	llm.RegisterPricing(model, pricing)
+}
+
+// EstimateCost returns the price of usage on a model in US dollars, and
+// false if the model's pricing is unknown.
+func EstimateCost(provider, model string, usage Usage) (float64, bool) {
+	return llm.EstimateCost(provider, model, usage)
+}
@@ -0,0 +1,227 @@
+package xollm
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultProbeInterval = 30 * time.Second
+	defaultProbeTimeout  = 5 * time.Second
+)
+
+// Availability reports whether a named backend is believed to be up.
+// HealthProber implements it; the router package skips the routes of
+// backends that are not available.
+type Availability interface {
+	Available(name string) bool
+}
+
+// ProberOptions configure a HealthProber. Zero values select the defaults.
+type ProberOptions struct {
+	// Interval is the time between probe rounds, 30s by default.
+	Interval time.Duration
+	// Timeout bounds each ping, 5s by default.
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failed pings that
+	// mark a backend unavailable, 1 by default. Raise it to ride out
+	// one-off blips.
+	FailureThreshold int
+	// SuccessThreshold is the number of consecutive successful pings that
+	// mark an unavailable backend available again, 1 by default.
+	SuccessThreshold int
+	// OnChange, if set, is called when a backend becomes available or
+	// unavailable, e.g. to log or alert. It is called from the probing
+	// goroutine and must not block.
+	OnChange func(name string, status ProbeStatus)
+}
+
+// ProbeStatus is what a HealthProber knows about one backend.
+type ProbeStatus struct {
+	// Available is false once FailureThreshold pings in a row failed,
+	// until SuccessThreshold pings in a row succeed. Backends start out
+	// available.
+	Available bool
+	// LastChecked is when the backend was last pinged, zero if never.
+	LastChecked time.Time
+	// LastError is the error of the latest ping, nil if it succeeded.
+	LastError error
+	// Failures and Successes count the latest run of failed or successful
+	// pings; one of them is always 0.
+	Failures  int
+	Successes int
+}
+
+// HealthProber pings backends in the background and keeps track of which
+// are available, so that routing and failover skip a dead backend before
+// user requests hit it. Backends are pinged with Ping, so clients that
+// don't implement Pinger always count as available.
+//
+//	prober := xollm.NewHealthProber(map[string]xollm.Client{"groq": groqClient, "ollama": ollamaClient},
+//		xollm.ProberOptions{Interval: 15 * time.Second, FailureThreshold: 2})
+//	prober.Start()
+//	defer prober.Stop()
+//	r.SetAvailability(prober) // see router.Router
+//
+// A HealthProber is safe for concurrent use.
+type HealthProber struct {
+	targets map[string]Client
+	names   []string
+	opts    ProberOptions
+
+	mu     sync.RWMutex
+	status map[string]ProbeStatus
+
+	lifecycle sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewHealthProber returns a prober of targets, keyed by the names callers
+// look their availability up by, such as provider or route names. It does
+// not probe until Start or ProbeNow is called.
+func NewHealthProber(targets map[string]Client, opts ProberOptions) *HealthProber {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultProbeInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultProbeTimeout
+	}
+	opts.FailureThreshold = max(opts.FailureThreshold, 1)
+	opts.SuccessThreshold = max(opts.SuccessThreshold, 1)
+
+	p := &HealthProber{
+		targets: make(map[string]Client, len(targets)),
+		opts:    opts,
+		status:  make(map[string]ProbeStatus, len(targets)),
+	}
+	for name, client := range targets {
+		p.targets[name] = client
+		p.names = append(p.names, name)
+		p.status[name] = ProbeStatus{Available: true}
+	}
+	sort.Strings(p.names)
+	return p
+}
+
+// Start probes every backend at once and then every Interval, in the
+// background, until Stop is called. Calling Start on a running prober has
+// no effect.
+func (p *HealthProber) Start() {
+	p.lifecycle.Lock()
+	defer p.lifecycle.Unlock()
+	if p.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel, p.done = cancel, make(chan struct{})
+	go p.run(ctx, p.done)
+}
+
+// run probes every Interval until ctx is cancelled.
+func (p *HealthProber) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(p.opts.Interval)
+	defer ticker.Stop()
+	for {
+		p.ProbeNow(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops probing and waits for a probe round in progress to end. The
+// last known statuses remain readable. The prober can be started again.
+func (p *HealthProber) Stop() {
+	p.lifecycle.Lock()
+	defer p.lifecycle.Unlock()
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+	p.cancel, p.done = nil, nil
+}
+
+// ProbeNow pings every backend concurrently and updates their statuses,
+// returning once all pings are done. Use it to learn the backends' state
+// at startup before serving requests.
+func (p *HealthProber) ProbeNow(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, name := range p.names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			pingCtx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+			defer cancel()
+			err := Ping(pingCtx, p.targets[name])
+			if ctx.Err() != nil {
+				return // stopped, not a verdict on the backend
+			}
+			p.record(name, err)
+		}(name)
+	}
+	wg.Wait()
+}
+
+// record updates the status of name with the outcome of a ping.
+func (p *HealthProber) record(name string, err error) {
+	p.mu.Lock()
+	s := p.status[name]
+	s.LastChecked, s.LastError = time.Now(), err
+	if err != nil {
+		s.Failures++
+		s.Successes = 0
+	} else {
+		s.Successes++
+		s.Failures = 0
+	}
+	changed := false
+	switch {
+	case s.Available && s.Failures >= p.opts.FailureThreshold:
+		s.Available, changed = false, true
+	case !s.Available && s.Successes >= p.opts.SuccessThreshold:
+		s.Available, changed = true, true
+	}
+	p.status[name] = s
+	p.mu.Unlock()
+
+	if changed && p.opts.OnChange != nil {
+		p.opts.OnChange(name, s)
+	}
+}
+
+// Available implements Availability. Names the prober doesn't know are
+// reported available, so a prober covering only some backends doesn't
+// take the others out of rotation.
+func (p *HealthProber) Available(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	s, ok := p.status[name]
+	return !ok || s.Available
+}
+
+// Status returns the status of the backend name, and false if the prober
+// doesn't know it.
+func (p *HealthProber) Status(name string) (ProbeStatus, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	s, ok := p.status[name]
+	return s, ok
+}
+
+// Statuses returns the status of every backend, keyed by name.
+func (p *HealthProber) Statuses() map[string]ProbeStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	statuses := make(map[string]ProbeStatus, len(p.status))
+	for name, s := range p.status {
+		statuses[name] = s
+	}
+	return statuses
+}
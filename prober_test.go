@@ -0,0 +1,112 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// switchPinger is a client whose Ping result can change while it is probed
+type switchPinger struct {
+	stubClient
+	mu    sync.Mutex
+	err   error
+	pings int
+}
+
+func (s *switchPinger) Ping(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pings++
+	return s.err
+}
+
+func (s *switchPinger) set(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *switchPinger) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pings
+}
+
+func TestHealthProber_Thresholds(t *testing.T) {
+	backend := &switchPinger{}
+	var changes []ProbeStatus
+	prober := NewHealthProber(map[string]Client{"groq": backend}, ProberOptions{
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+		OnChange:         func(name string, s ProbeStatus) { changes = append(changes, s) },
+	})
+	ctx := context.Background()
+
+	if !prober.Available("groq") {
+		t.Error("Expected backends to start out available")
+	}
+	if !prober.Available("unknown") {
+		t.Error("Expected unknown backends to be reported available")
+	}
+
+	downErr := errors.New("connection refused")
+	backend.set(downErr)
+	prober.ProbeNow(ctx)
+	if !prober.Available("groq") {
+		t.Error("Expected one failure to stay under the threshold")
+	}
+	prober.ProbeNow(ctx)
+	status, ok := prober.Status("groq")
+	if !ok || status.Available || status.Failures != 2 || !errors.Is(status.LastError, downErr) || status.LastChecked.IsZero() {
+		t.Errorf("Expected the backend to be unavailable, got %+v", status)
+	}
+
+	backend.set(nil)
+	prober.ProbeNow(ctx)
+	if prober.Available("groq") {
+		t.Error("Expected one success to stay under the recovery threshold")
+	}
+	prober.ProbeNow(ctx)
+	if !prober.Available("groq") {
+		t.Error("Expected the backend to recover")
+	}
+	if len(changes) != 2 || changes[0].Available || !changes[1].Available {
+		t.Errorf("Expected a down and an up change, got %+v", changes)
+	}
+	if statuses := prober.Statuses(); len(statuses) != 1 || !statuses["groq"].Available {
+		t.Errorf("Unexpected statuses: %+v", statuses)
+	}
+}
+
+func TestHealthProber_NonPingerAvailable(t *testing.T) {
+	prober := NewHealthProber(map[string]Client{"plain": &stubClient{}}, ProberOptions{})
+	prober.ProbeNow(context.Background())
+	if status, _ := prober.Status("plain"); !status.Available || status.LastChecked.IsZero() {
+		t.Errorf("Expected a client without Ping to count as available, got %+v", status)
+	}
+}
+
+func TestHealthProber_StartStop(t *testing.T) {
+	backend := &switchPinger{err: errors.New("down")}
+	prober := NewHealthProber(map[string]Client{"groq": backend}, ProberOptions{Interval: 5 * time.Millisecond})
+	prober.Start()
+	prober.Start() // no effect on a running prober
+
+	deadline := time.Now().Add(time.Second)
+	for prober.Available("groq") || backend.count() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected repeated probes to mark the backend down, got %d pings", backend.count())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	prober.Stop()
+	pings := backend.count()
+	time.Sleep(20 * time.Millisecond)
+	if backend.count() != pings {
+		t.Error("Expected probing to stop")
+	}
+	prober.Stop() // stopping twice is harmless
+}
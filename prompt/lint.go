@@ -0,0 +1,269 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/xostack/xollm"
+)
+
+// Severity classifies lint issues.
+type Severity int
+
+const (
+	// SeverityWarning marks issues that are likely, but not certainly, mistakes.
+	SeverityWarning Severity = iota
+	// SeverityError marks issues that will make the prompt fail or misbehave.
+	SeverityError
+)
+
+// String returns "warning" or "error".
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Lint rule identifiers reported in Issue.Rule.
+const (
+	RuleMissingVariable      = "missing-variable"
+	RuleUnreferencedVariable = "unreferenced-variable"
+	RuleContextLength        = "context-length"
+	RuleConflictingRules     = "conflicting-instructions"
+)
+
+// defaultMaxContextFraction is the share of a model's context window a
+// template's static text may use before it is reported as suspiciously long.
+const defaultMaxContextFraction = 0.5
+
+// Issue is a single problem found by the linter.
+type Issue struct {
+	Template string
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+// String formats the issue as "template: severity: message (rule)".
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", i.Template, i.Severity, i.Message, i.Rule)
+}
+
+// Issues is a list of lint issues.
+type Issues []Issue
+
+// HasErrors reports whether any issue has SeverityError.
+func (is Issues) HasErrors() bool {
+	for _, i := range is {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// LintOptions configures Lint.
+type LintOptions struct {
+	// Variables lists the top-level variables the application supplies when
+	// rendering. When nil, the missing and unreferenced variable checks are
+	// skipped.
+	Variables []string
+
+	// Model is the target model for the length check. When empty, the
+	// template's model hint is used; unknown models skip the check.
+	Model string
+
+	// MaxContextFraction is the share of the model's context window the
+	// template text may use before a warning is reported. Defaults to 0.5.
+	MaxContextFraction float64
+}
+
+// conflict is a pair of instructions that contradict each other.
+type conflict struct {
+	a, b        *regexp.Regexp
+	description string
+}
+
+// conflictingInstructions lists instruction pairs that should not appear in
+// the same prompt.
+var conflictingInstructions = []conflict{
+	{
+		a:           regexp.MustCompile(`(?i)\b(be (brief|concise)|keep it short|one sentence|briefly)\b`),
+		b:           regexp.MustCompile(`(?i)\b(in (great )?detail|be (thorough|comprehensive|detailed)|elaborate)\b`),
+		description: "asks for both a brief and a detailed answer",
+	},
+	{
+		a:           regexp.MustCompile(`(?i)\b(respond|answer|reply|output)( only)? (in|with|as) (valid )?json\b`),
+		b:           regexp.MustCompile(`(?i)\b(respond|answer|reply|output)( only)? (in|with|as) (plain text|markdown|prose)\b`),
+		description: "asks for JSON and for a non-JSON format",
+	},
+	{
+		a:           regexp.MustCompile(`(?i)\b(do not|don't|never) use markdown\b`),
+		b:           regexp.MustCompile(`(?i)\b(use|format (it |the answer )?(in|with|as)) markdown\b`),
+		description: "both forbids and requests markdown",
+	},
+	{
+		a:           regexp.MustCompile(`(?i)\b(do not|don't|never) (explain|include (an )?explanation)`),
+		b:           regexp.MustCompile(`(?i)\b(explain (your|the) reasoning|show your (work|reasoning)|step by step)\b`),
+		description: "both forbids and requests explanations",
+	},
+}
+
+// Lint checks a template for common mistakes.
+func Lint(t *Template, opts LintOptions) Issues {
+	var issues Issues
+	add := func(rule string, severity Severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{Template: t.Name, Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Variable checks
+	referenced := t.Variables()
+	if opts.Variables != nil {
+		supplied := make(map[string]bool, len(opts.Variables))
+		for _, v := range opts.Variables {
+			supplied[v] = true
+		}
+		for _, v := range referenced {
+			if !supplied[v] {
+				add(RuleMissingVariable, SeverityError, "template references .%s, which is not supplied", v)
+			}
+		}
+		used := make(map[string]bool, len(referenced))
+		for _, v := range referenced {
+			used[v] = true
+		}
+		for _, v := range opts.Variables {
+			if !used[v] {
+				add(RuleUnreferencedVariable, SeverityWarning, "variable .%s is supplied but never used", v)
+			}
+		}
+	}
+
+	// Length check against the target model
+	model := opts.Model
+	if model == "" {
+		model = t.Metadata.Model
+	}
+	if info, ok := xollm.LookupModel(model); ok {
+		fraction := opts.MaxContextFraction
+		if fraction <= 0 {
+			fraction = defaultMaxContextFraction
+		}
+		tokens := xollm.EstimateTokens(t.Body)
+		limit := int(float64(info.ContextWindow) * fraction)
+		switch {
+		case tokens >= info.ContextWindow:
+			add(RuleContextLength, SeverityError, "template text is ~%d tokens, exceeding the %d-token context window of %s", tokens, info.ContextWindow, info.Name)
+		case tokens > limit:
+			add(RuleContextLength, SeverityWarning, "template text is ~%d tokens, over %.0f%% of the %d-token context window of %s before any variables are filled in", tokens, fraction*100, info.ContextWindow, info.Name)
+		}
+	}
+
+	// Conflicting instructions
+	for _, c := range conflictingInstructions {
+		if c.a.MatchString(t.Body) && c.b.MatchString(t.Body) {
+			add(RuleConflictingRules, SeverityWarning, "prompt %s: %q vs %q", c.description, c.a.FindString(t.Body), c.b.FindString(t.Body))
+		}
+	}
+
+	return issues
+}
+
+// Lint lints every template in the set, in name order.
+func (s *Set) Lint(opts LintOptions) Issues {
+	var issues Issues
+	for _, name := range s.Names() {
+		issues = append(issues, Lint(s.templates[name], opts)...)
+	}
+	return issues
+}
+
+// Variables returns the sorted, de-duplicated top-level fields the template
+// references on its data, e.g. "Name" for {{.Name}} or {{.Name.First}}.
+// Fields referenced relative to a changed dot inside {{range}} or {{with}}
+// are not included; $.Field references are.
+func (t *Template) Variables() []string {
+	found := make(map[string]bool)
+	for _, tmpl := range t.tmpl.Templates() {
+		if tmpl.Tree != nil {
+			collectFields(tmpl.Tree.Root, true, found)
+		}
+	}
+	vars := make([]string, 0, len(found))
+	for v := range found {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// collectFields walks a template parse tree recording top-level field names.
+// dotIsRoot is false inside range/with bodies where dot no longer refers to
+// the template data.
+func collectFields(node parse.Node, dotIsRoot bool, found map[string]bool) {
+	switch n := node.(type) {
+	case nil:
+		return
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, dotIsRoot, found)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, dotIsRoot, found)
+	case *parse.IfNode:
+		collectBranch(&n.BranchNode, dotIsRoot, dotIsRoot, found)
+	case *parse.RangeNode:
+		collectBranch(&n.BranchNode, dotIsRoot, false, found)
+	case *parse.WithNode:
+		collectBranch(&n.BranchNode, dotIsRoot, false, found)
+	case *parse.TemplateNode:
+		collectFields(n.Pipe, dotIsRoot, found)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectFields(cmd, dotIsRoot, found)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectFields(arg, dotIsRoot, found)
+		}
+	case *parse.ChainNode:
+		collectFields(n.Node, dotIsRoot, found)
+	case *parse.FieldNode:
+		if dotIsRoot && len(n.Ident) > 0 {
+			found[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			found[n.Ident[1]] = true
+		}
+	}
+}
+
+// collectBranch walks an if/range/with node. The pipeline is evaluated with
+// the outer dot; the body uses bodyDotIsRoot; the else branch keeps the outer dot.
+func collectBranch(n *parse.BranchNode, dotIsRoot, bodyDotIsRoot bool, found map[string]bool) {
+	collectFields(n.Pipe, dotIsRoot, found)
+	collectFields(n.List, bodyDotIsRoot, found)
+	if n.ElseList != nil {
+		collectFields(n.ElseList, dotIsRoot, found)
+	}
+}
+
+// FormatIssues renders issues one per line.
+func FormatIssues(issues Issues) string {
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = issue.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,133 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func rulesOf(issues Issues) []string {
+	rules := make([]string, len(issues))
+	for i, issue := range issues {
+		rules[i] = issue.Rule
+	}
+	return rules
+}
+
+func TestTemplate_Variables(t *testing.T) {
+	tmpl := mustParse(t, "vars", `Hello {{.Name}} from {{.Org.Name}}.
+{{if .Admin}}Admin{{else}}{{.Fallback}}{{end}}
+{{range .Items}}{{.Title}} for {{$.Owner}}{{end}}
+{{with .Extra}}{{.Ignored}}{{end}}
+{{printf "%s" .Formatted}}`)
+
+	expected := []string{"Admin", "Extra", "Fallback", "Formatted", "Items", "Name", "Org", "Owner"}
+	if got := tmpl.Variables(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected variables %v, got %v", expected, got)
+	}
+}
+
+func TestLint_Variables(t *testing.T) {
+	tmpl := mustParse(t, "greet", "Hello {{.Name}}, welcome to {{.Place}}")
+
+	issues := Lint(tmpl, LintOptions{Variables: []string{"Name", "Unused"}})
+	expected := []string{RuleMissingVariable, RuleUnreferencedVariable}
+	if !reflect.DeepEqual(rulesOf(issues), expected) {
+		t.Fatalf("Expected rules %v, got %v", expected, issues)
+	}
+	if issues[0].Severity != SeverityError || !strings.Contains(issues[0].Message, ".Place") {
+		t.Errorf("Expected missing .Place error, got %+v", issues[0])
+	}
+	if issues[1].Severity != SeverityWarning || !strings.Contains(issues[1].Message, ".Unused") {
+		t.Errorf("Expected unreferenced .Unused warning, got %+v", issues[1])
+	}
+	if !issues.HasErrors() {
+		t.Error("Expected HasErrors to be true")
+	}
+}
+
+func TestLint_VariablesSkippedWhenNil(t *testing.T) {
+	tmpl := mustParse(t, "greet", "Hello {{.Name}}")
+	if issues := Lint(tmpl, LintOptions{}); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestLint_ContextLength(t *testing.T) {
+	// gemma2-9b-it has an 8192-token window; ~5000 estimated tokens is over half
+	long := strings.Repeat("abcd", 5000)
+	tmpl := mustParse(t, "long", long)
+
+	issues := Lint(tmpl, LintOptions{Model: "gemma2-9b-it"})
+	if len(issues) != 1 || issues[0].Rule != RuleContextLength || issues[0].Severity != SeverityWarning {
+		t.Fatalf("Expected one context-length warning, got %v", issues)
+	}
+
+	huge := mustParse(t, "huge", strings.Repeat("abcd", 9000))
+	issues = Lint(huge, LintOptions{Model: "gemma2-9b-it"})
+	if len(issues) != 1 || issues[0].Severity != SeverityError {
+		t.Fatalf("Expected one context-length error, got %v", issues)
+	}
+
+	// The template's model hint is used when no model is given
+	hinted := mustParse(t, "hinted", "---\nmodel = \"gemma2-9b-it\"\n---\n"+long)
+	if issues := Lint(hinted, LintOptions{}); len(issues) != 1 {
+		t.Errorf("Expected model hint to drive length check, got %v", issues)
+	}
+
+	// Unknown models skip the check
+	if issues := Lint(tmpl, LintOptions{Model: "unknown"}); len(issues) != 0 {
+		t.Errorf("Expected no issues for unknown model, got %v", issues)
+	}
+}
+
+func TestLint_ConflictingInstructions(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		conflict bool
+	}{
+		{name: "brief vs detailed", body: "Be concise. Explain everything in detail.", conflict: true},
+		{name: "json vs markdown", body: "Respond in JSON. Reply in markdown.", conflict: true},
+		{name: "markdown", body: "Do not use markdown. Use markdown tables.", conflict: true},
+		{name: "explanations", body: "Don't explain. Think step by step.", conflict: true},
+		{name: "consistent", body: "Be concise and respond in JSON.", conflict: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Lint(mustParse(t, "c", tt.body), LintOptions{})
+			if got := len(issues) > 0; got != tt.conflict {
+				t.Errorf("Expected conflict=%v, got issues %v", tt.conflict, issues)
+			}
+			for _, issue := range issues {
+				if issue.Rule != RuleConflictingRules {
+					t.Errorf("Unexpected rule %s", issue.Rule)
+				}
+			}
+		})
+	}
+}
+
+func TestSet_Lint(t *testing.T) {
+	set, err := NewSet(
+		mustParse(t, "b", "Hi {{.Name}}"),
+		mustParse(t, "a", "Hi {{.Other}}"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create set: %v", err)
+	}
+
+	issues := set.Lint(LintOptions{Variables: []string{"Name"}})
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %v", issues)
+	}
+	if issues[0].Template != "a" {
+		t.Errorf("Expected issues in template name order, got %v", issues)
+	}
+
+	formatted := FormatIssues(issues)
+	if !strings.Contains(formatted, "a: error: template references .Other") {
+		t.Errorf("Unexpected formatting: %s", formatted)
+	}
+}
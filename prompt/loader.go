@@ -0,0 +1,144 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// templateExtensions are the file extensions recognized by LoadDir.
+var templateExtensions = []string{".prompt", ".tmpl"}
+
+// Set is a collection of templates referenced by name.
+//
+// A Set is safe for concurrent reads once loaded.
+type Set struct {
+	templates map[string]*Template
+}
+
+// NewSet creates a set from already-parsed templates.
+// It returns an error if two templates share a name.
+func NewSet(templates ...*Template) (*Set, error) {
+	s := &Set{templates: make(map[string]*Template, len(templates))}
+	for _, t := range templates {
+		if existing, ok := s.templates[t.Name]; ok {
+			return nil, fmt.Errorf("duplicate prompt name '%s' (%s and %s)", t.Name, existing.Path, t.Path)
+		}
+		s.templates[t.Name] = t
+	}
+	return s, nil
+}
+
+// LoadDir loads and validates every .prompt and .tmpl file under dir.
+//
+// Templates are named by their path relative to dir, using forward slashes
+// and without the extension: "dir/summaries/short.prompt" is named
+// "summaries/short". Hidden files and directories are skipped.
+//
+// All invalid files are reported together in the returned error.
+func LoadDir(dir string) (*Set, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access prompt directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("prompt path %s is not a directory", dir)
+	}
+	return LoadFS(os.DirFS(dir), dir)
+}
+
+// LoadFS is like LoadDir but reads from fsys, e.g. an embed.FS.
+// root is only used to make file paths in errors and Template.Path readable.
+func LoadFS(fsys fs.FS, root string) (*Set, error) {
+	var templates []*Template
+	var errs []error
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isTemplateFile(p) {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", filepath.Join(root, p), err))
+			return nil
+		}
+
+		name := strings.TrimSuffix(p, path.Ext(p))
+		t, err := Parse(name, string(content))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Join(root, p), err))
+			return nil
+		}
+		t.Path = filepath.Join(root, filepath.FromSlash(p))
+		templates = append(templates, t)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk prompt directory %s: %w", root, err)
+	}
+
+	set, err := NewSet(templates...)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to load prompts from %s: %w", root, errors.Join(errs...))
+	}
+	return set, nil
+}
+
+// Get returns the template with the given name.
+func (s *Set) Get(name string) (*Template, bool) {
+	t, ok := s.templates[name]
+	return t, ok
+}
+
+// Render renders the named template with data.
+func (s *Set) Render(name string, data interface{}) (string, error) {
+	t, ok := s.Get(name)
+	if !ok {
+		return "", fmt.Errorf("prompt '%s' not found", name)
+	}
+	return t.Render(data)
+}
+
+// Names returns the names of all templates in the set, sorted.
+func (s *Set) Names() []string {
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Len returns the number of templates in the set.
+func (s *Set) Len() int {
+	return len(s.templates)
+}
+
+// isTemplateFile reports whether p has a recognized template extension.
+func isTemplateFile(p string) bool {
+	ext := path.Ext(p)
+	for _, e := range templateExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func TestLoadDir_Success(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "greet.prompt", "---\ndescription = \"Greeting\"\n---\nHello {{.Name}}")
+	writeFile(t, dir, "summaries/short.tmpl", "Summarize briefly: {{.Text}}")
+	writeFile(t, dir, "README.md", "not a template")
+	writeFile(t, dir, ".hidden/secret.prompt", "{{.Broken")
+
+	set, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expectedNames := []string{"greet", "summaries/short"}
+	if !reflect.DeepEqual(set.Names(), expectedNames) {
+		t.Errorf("Expected names %v, got %v", expectedNames, set.Names())
+	}
+
+	greet, ok := set.Get("greet")
+	if !ok {
+		t.Fatal("Expected 'greet' template to exist")
+	}
+	if greet.Metadata.Description != "Greeting" {
+		t.Errorf("Expected description 'Greeting', got '%s'", greet.Metadata.Description)
+	}
+	if greet.Path != filepath.Join(dir, "greet.prompt") {
+		t.Errorf("Expected path '%s', got '%s'", filepath.Join(dir, "greet.prompt"), greet.Path)
+	}
+
+	rendered, err := set.Render("summaries/short", map[string]string{"Text": "doc"})
+	if err != nil {
+		t.Fatalf("Expected no error rendering, got: %v", err)
+	}
+	if rendered != "Summarize briefly: doc" {
+		t.Errorf("Unexpected render result: '%s'", rendered)
+	}
+}
+
+func TestLoadDir_ReportsAllInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "one.prompt", "{{.Broken")
+	writeFile(t, dir, "two.prompt", "---\ntemperature = 9.0\n---\nHi")
+	writeFile(t, dir, "ok.prompt", "Fine")
+
+	_, err := LoadDir(dir)
+	if err == nil {
+		t.Fatal("Expected error for invalid templates")
+	}
+	if !strings.Contains(err.Error(), "one.prompt") || !strings.Contains(err.Error(), "two.prompt") {
+		t.Errorf("Expected error to mention both invalid files, got: %v", err)
+	}
+}
+
+func TestLoadDir_DuplicateNames(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "greet.prompt", "Hello")
+	writeFile(t, dir, "greet.tmpl", "Hi")
+
+	_, err := LoadDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "duplicate prompt name 'greet'") {
+		t.Errorf("Expected duplicate name error, got: %v", err)
+	}
+}
+
+func TestLoadDir_MissingDirectory(t *testing.T) {
+	if _, err := LoadDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
+
+func TestLoadDir_NotADirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "file.prompt", "Hello")
+
+	if _, err := LoadDir(filepath.Join(dir, "file.prompt")); err == nil {
+		t.Error("Expected error when path is a file")
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.prompt": {Data: []byte("A {{.X}}")},
+	}
+
+	set, err := LoadFS(fsys, "embedded")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if set.Len() != 1 {
+		t.Errorf("Expected 1 template, got %d", set.Len())
+	}
+}
+
+func TestSet_RenderUnknown(t *testing.T) {
+	set, err := NewSet()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := set.Render("missing", nil); err == nil {
+		t.Error("Expected error for unknown template")
+	}
+}
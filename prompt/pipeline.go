@@ -0,0 +1,236 @@
+package prompt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xostack/xollm"
+)
+
+// ErrorPolicy decides what a pipeline does when a step fails.
+type ErrorPolicy int
+
+const (
+	// ErrorAbort stops the pipeline and returns the step's error.
+	ErrorAbort ErrorPolicy = iota
+	// ErrorSkip records the failure, stores the step's Fallback value as its
+	// output and continues with the next step.
+	ErrorSkip
+	// ErrorRetry re-runs the step up to Retries more times, then aborts.
+	ErrorRetry
+)
+
+// State holds the pipeline input and the output of every completed step,
+// keyed by input name or step name. It is the template data for steps that
+// have no Input mapping, so a step can refer to earlier results as
+// {{.extract}}.
+type State map[string]interface{}
+
+// Step is one LLM call in a pipeline.
+type Step struct {
+	// Name identifies the step. Its output is stored in State under Name.
+	Name string
+	// Template is rendered to produce the prompt.
+	Template *Template
+	// Input maps the pipeline state to the template data. When nil, the
+	// state itself is used.
+	Input func(State) (interface{}, error)
+	// Parse converts the generated text into the step's output. When nil,
+	// the trimmed text is the output.
+	Parse func(text string) (interface{}, error)
+	// OnError selects what happens when rendering, generation or parsing fails.
+	OnError ErrorPolicy
+	// Retries is the number of extra attempts made under ErrorRetry.
+	Retries int
+	// Fallback is stored as the output of a step skipped under ErrorSkip.
+	Fallback interface{}
+	// Client overrides the pipeline's client for this step, e.g. to send a
+	// cheap extraction step to a smaller model.
+	Client xollm.Client
+}
+
+// StepResult records what happened in one step.
+type StepResult struct {
+	Name string
+	// Prompt is the last rendered prompt.
+	Prompt string
+	// Text is the last generated text.
+	Text string
+	// Output is the parsed output stored in the state.
+	Output interface{}
+	// Attempts is the number of times the step was run.
+	Attempts int
+	// Skipped is true when the step failed and ErrorSkip applied.
+	Skipped bool
+	// Err is the last error, if any.
+	Err error
+}
+
+// PipelineResult is the outcome of a pipeline run.
+type PipelineResult struct {
+	// State holds the input and every step output.
+	State State
+	// Steps has one entry per step that was started, in order.
+	Steps []StepResult
+}
+
+// Output returns the output of the last step.
+func (r *PipelineResult) Output() interface{} {
+	if len(r.Steps) == 0 {
+		return nil
+	}
+	return r.Steps[len(r.Steps)-1].Output
+}
+
+// Pipeline runs a fixed sequence of steps, feeding each step the outputs
+// of the steps before it.
+type Pipeline struct {
+	client xollm.Client
+	steps  []Step
+}
+
+// NewPipeline creates a pipeline that sends prompts to client, validating
+// that every step has a unique name and a template.
+func NewPipeline(client xollm.Client, steps ...Step) (*Pipeline, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("pipeline requires at least one step")
+	}
+	seen := make(map[string]bool, len(steps))
+	for i, step := range steps {
+		if step.Name == "" {
+			return nil, fmt.Errorf("pipeline step %d has no name", i)
+		}
+		if seen[step.Name] {
+			return nil, fmt.Errorf("pipeline has duplicate step '%s'", step.Name)
+		}
+		if step.Template == nil {
+			return nil, fmt.Errorf("pipeline step '%s' has no template", step.Name)
+		}
+		if step.Client == nil && client == nil {
+			return nil, fmt.Errorf("pipeline step '%s' has no client", step.Name)
+		}
+		if step.Retries < 0 {
+			return nil, fmt.Errorf("pipeline step '%s' has negative retries", step.Name)
+		}
+		seen[step.Name] = true
+	}
+	return &Pipeline{client: client, steps: steps}, nil
+}
+
+// Run executes the steps in order starting from input, which may be nil.
+// The returned result covers every step that was started, including the
+// one that aborted the run.
+func (p *Pipeline) Run(ctx context.Context, input map[string]interface{}) (*PipelineResult, error) {
+	state := make(State, len(input)+len(p.steps))
+	for k, v := range input {
+		state[k] = v
+	}
+	result := &PipelineResult{State: state}
+
+	for _, step := range p.steps {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		sr := p.runStep(ctx, step, state)
+		result.Steps = append(result.Steps, sr)
+		if sr.Err != nil && !sr.Skipped {
+			return result, fmt.Errorf("pipeline step '%s' failed: %w", step.Name, sr.Err)
+		}
+		state[step.Name] = sr.Output
+	}
+	return result, nil
+}
+
+// runStep runs a single step, applying its error policy.
+func (p *Pipeline) runStep(ctx context.Context, step Step, state State) StepResult {
+	sr := StepResult{Name: step.Name}
+	client := step.Client
+	if client == nil {
+		client = p.client
+	}
+
+	attempts := 1
+	if step.OnError == ErrorRetry {
+		attempts += step.Retries
+	}
+
+	for sr.Attempts < attempts {
+		sr.Attempts++
+		sr.Err = p.attempt(ctx, step, client, state, &sr)
+		if sr.Err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if sr.Err != nil && step.OnError == ErrorSkip {
+		sr.Skipped = true
+		sr.Output = step.Fallback
+	}
+	return sr
+}
+
+// attempt renders, generates and parses once, recording progress in sr.
+func (p *Pipeline) attempt(ctx context.Context, step Step, client xollm.Client, state State, sr *StepResult) error {
+	var data interface{} = state
+	if step.Input != nil {
+		mapped, err := step.Input(state)
+		if err != nil {
+			return fmt.Errorf("failed to map input: %w", err)
+		}
+		data = mapped
+	}
+
+	rendered, err := step.Template.Render(data)
+	if err != nil {
+		return err
+	}
+	sr.Prompt = rendered
+
+	text, err := client.Generate(ctx, rendered)
+	if err != nil {
+		return fmt.Errorf("generation failed: %w", err)
+	}
+	sr.Text = text
+
+	if step.Parse == nil {
+		sr.Output = strings.TrimSpace(text)
+		return nil
+	}
+	output, err := step.Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse output: %w", err)
+	}
+	sr.Output = output
+	return nil
+}
+
+// ParseJSON is a Step.Parse function that decodes a JSON value from the
+// generated text. A surrounding markdown code fence or sentence, as models
+// often add, is removed first with xollm.ExtractJSON.
+func ParseJSON(text string) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(xollm.ExtractJSON(text)), &v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return v, nil
+}
+
+// ParseLines is a Step.Parse function that splits the generated text into
+// its non-empty lines, dropping leading list markers such as "-", "*" or "1.".
+func ParseLines(text string) (interface{}, error) {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, "-*• ")
+		if i := strings.Index(line, ". "); i > 0 && i <= 3 && strings.Trim(line[:i], "0123456789") == "" {
+			line = line[i+2:]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
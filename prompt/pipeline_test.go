@@ -0,0 +1,202 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// scriptedClient answers prompts with respond and records what it was sent
+type scriptedClient struct {
+	respond func(prompt string, call int) (string, error)
+	prompts []string
+}
+
+func (s *scriptedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	s.prompts = append(s.prompts, prompt)
+	return s.respond(prompt, len(s.prompts))
+}
+
+func (s *scriptedClient) ProviderName() string { return "scripted" }
+
+func (s *scriptedClient) Close() error { return nil }
+
+func TestPipeline_Run(t *testing.T) {
+	client := &scriptedClient{respond: func(prompt string, call int) (string, error) {
+		switch {
+		case strings.HasPrefix(prompt, "Extract"):
+			return "```json\n{\"names\": [\"Ada\", \"Alan\"]}\n```", nil
+		case strings.HasPrefix(prompt, "List"):
+			return "- ADA\n- ALAN\n", nil
+		default:
+			return "  Two pioneers.  ", nil
+		}
+	}}
+
+	pipeline, err := NewPipeline(client,
+		Step{Name: "extract", Template: mustParse(t, "extract", "Extract names from: {{.doc}}"), Parse: ParseJSON},
+		Step{
+			Name:     "transform",
+			Template: mustParse(t, "transform", "List in upper case: {{.Names}}"),
+			Input: func(s State) (interface{}, error) {
+				return map[string]interface{}{"Names": s["extract"].(map[string]interface{})["names"]}, nil
+			},
+			Parse: ParseLines,
+		},
+		Step{Name: "summarize", Template: mustParse(t, "summarize", "Summarize: {{.transform}}")},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	result, err := pipeline.Run(context.Background(), map[string]interface{}{"doc": "Ada met Alan."})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if client.prompts[1] != "List in upper case: [Ada Alan]" {
+		t.Errorf("Expected mapped input in prompt, got '%s'", client.prompts[1])
+	}
+	if client.prompts[2] != "Summarize: [ADA ALAN]" {
+		t.Errorf("Expected parsed lines in prompt, got '%s'", client.prompts[2])
+	}
+	if got := result.State["transform"]; !reflect.DeepEqual(got, []string{"ADA", "ALAN"}) {
+		t.Errorf("Expected parsed lines in state, got %v", got)
+	}
+	if result.Output() != "Two pioneers." {
+		t.Errorf("Expected trimmed final output, got %v", result.Output())
+	}
+	if len(result.Steps) != 3 || result.Steps[0].Attempts != 1 {
+		t.Errorf("Unexpected step results: %+v", result.Steps)
+	}
+}
+
+func TestPipeline_ErrorPolicies(t *testing.T) {
+	genErr := errors.New("provider down")
+
+	t.Run("abort", func(t *testing.T) {
+		client := &scriptedClient{respond: func(string, int) (string, error) { return "", genErr }}
+		pipeline, _ := NewPipeline(client,
+			Step{Name: "first", Template: mustParse(t, "first", "one")},
+			Step{Name: "second", Template: mustParse(t, "second", "two")},
+		)
+		result, err := pipeline.Run(context.Background(), nil)
+		if !errors.Is(err, genErr) || !strings.Contains(err.Error(), "step 'first'") {
+			t.Fatalf("Expected wrapped step error, got: %v", err)
+		}
+		if len(result.Steps) != 1 {
+			t.Errorf("Expected pipeline to stop after first step, got %d steps", len(result.Steps))
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		client := &scriptedClient{respond: func(prompt string, _ int) (string, error) {
+			if prompt == "one" {
+				return "", genErr
+			}
+			return prompt, nil
+		}}
+		pipeline, _ := NewPipeline(client,
+			Step{Name: "first", Template: mustParse(t, "first", "one"), OnError: ErrorSkip, Fallback: "n/a"},
+			Step{Name: "second", Template: mustParse(t, "second", "got {{.first}}")},
+		)
+		result, err := pipeline.Run(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !result.Steps[0].Skipped || !errors.Is(result.Steps[0].Err, genErr) {
+			t.Errorf("Expected first step to be skipped with its error, got %+v", result.Steps[0])
+		}
+		if result.Output() != "got n/a" {
+			t.Errorf("Expected fallback to feed the next step, got %v", result.Output())
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		client := &scriptedClient{respond: func(_ string, call int) (string, error) {
+			if call < 3 {
+				return "not json", nil
+			}
+			return `{"ok": true}`, nil
+		}}
+		pipeline, _ := NewPipeline(client,
+			Step{Name: "json", Template: mustParse(t, "json", "json please"), Parse: ParseJSON, OnError: ErrorRetry, Retries: 2},
+		)
+		result, err := pipeline.Run(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Expected retry to succeed, got: %v", err)
+		}
+		if result.Steps[0].Attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", result.Steps[0].Attempts)
+		}
+	})
+
+	t.Run("retry exhausted", func(t *testing.T) {
+		client := &scriptedClient{respond: func(string, int) (string, error) { return "not json", nil }}
+		pipeline, _ := NewPipeline(client,
+			Step{Name: "json", Template: mustParse(t, "json", "json please"), Parse: ParseJSON, OnError: ErrorRetry, Retries: 1},
+		)
+		result, err := pipeline.Run(context.Background(), nil)
+		if err == nil || !strings.Contains(err.Error(), "failed to parse output") {
+			t.Fatalf("Expected parse error, got: %v", err)
+		}
+		if result.Steps[0].Attempts != 2 || result.Steps[0].Text != "not json" {
+			t.Errorf("Unexpected step result: %+v", result.Steps[0])
+		}
+	})
+}
+
+func TestPipeline_StepClientOverride(t *testing.T) {
+	main := &scriptedClient{respond: func(string, int) (string, error) { return "main", nil }}
+	small := &scriptedClient{respond: func(string, int) (string, error) { return "small", nil }}
+	pipeline, err := NewPipeline(main,
+		Step{Name: "a", Template: mustParse(t, "a", "x"), Client: small},
+		Step{Name: "b", Template: mustParse(t, "b", "{{.a}}")},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if _, err := pipeline.Run(context.Background(), nil); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(small.prompts) != 1 || len(main.prompts) != 1 || main.prompts[0] != "small" {
+		t.Errorf("Expected step client override, got small=%v main=%v", small.prompts, main.prompts)
+	}
+}
+
+func TestNewPipeline_Validation(t *testing.T) {
+	tmpl := mustParse(t, "t", "x")
+	client := &mockClient{}
+	tests := []struct {
+		name   string
+		client *mockClient
+		steps  []Step
+	}{
+		{name: "no steps", client: client},
+		{name: "no name", client: client, steps: []Step{{Template: tmpl}}},
+		{name: "duplicate", client: client, steps: []Step{{Name: "a", Template: tmpl}, {Name: "a", Template: tmpl}}},
+		{name: "no template", client: client, steps: []Step{{Name: "a"}}},
+		{name: "negative retries", client: client, steps: []Step{{Name: "a", Template: tmpl, Retries: -1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPipeline(tt.client, tt.steps...); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+
+	if _, err := NewPipeline(nil, Step{Name: "a", Template: tmpl}); err == nil {
+		t.Error("Expected error for step without any client")
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	out, _ := ParseLines("1. first\n\n* second\n- third\n10. tenth\nplain")
+	expected := []string{"first", "second", "third", "tenth", "plain"}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Expected %v, got %v", expected, out)
+	}
+}
@@ -0,0 +1,206 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xostack/xollm"
+)
+
+// Requirements describe what a prompt needs from the model it is sent to.
+// Empty fields impose no restriction.
+type Requirements struct {
+	// Providers lists the providers the prompt may be sent to.
+	Providers []string
+	// Models lists the models the prompt may be sent to.
+	Models []string
+	// MinContextTokens is the smallest context window the prompt works with.
+	MinContextTokens int
+}
+
+// Check reports whether a provider and model satisfy the requirements.
+// An empty model skips the model and context checks, since the model is
+// not always known to the caller.
+func (r Requirements) Check(provider, model string) error {
+	if len(r.Providers) > 0 && !containsFold(r.Providers, provider) {
+		return fmt.Errorf("provider '%s' is not one of %v", provider, r.Providers)
+	}
+	if model == "" {
+		return nil
+	}
+	if len(r.Models) > 0 && !containsFold(r.Models, model) {
+		return fmt.Errorf("model '%s' is not one of %v", model, r.Models)
+	}
+	if r.MinContextTokens > 0 {
+		info, ok := xollm.LookupModel(model)
+		if !ok {
+			return fmt.Errorf("context window of model '%s' is unknown, %d tokens required", model, r.MinContextTokens)
+		}
+		if info.ContextWindow < r.MinContextTokens {
+			return fmt.Errorf("model '%s' has a %d-token context window, %d required", model, info.ContextWindow, r.MinContextTokens)
+		}
+	}
+	return nil
+}
+
+// Entry is a prompt registered with governance metadata.
+type Entry struct {
+	// Name is the name the prompt is looked up by.
+	Name string
+	// Template is the prompt text.
+	Template *Template
+	// Owner is the team or person responsible for the prompt.
+	Owner string
+	// Purpose explains what the prompt is used for.
+	Purpose string
+	// Tags are free-form labels for searching, e.g. "pii" or "customer-facing".
+	Tags []string
+	// Requirements restrict where the prompt may be sent.
+	Requirements Requirements
+}
+
+// Registry is a central, concurrency-safe catalogue of the prompts an
+// application sends to LLMs.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]Entry)}
+}
+
+// Register adds a prompt. Every entry needs a name, a template and an
+// owner; registering a name twice is an error.
+func (r *Registry) Register(entry Entry) error {
+	if entry.Name == "" {
+		return fmt.Errorf("registry entry has no name")
+	}
+	if entry.Template == nil {
+		return fmt.Errorf("registry entry '%s' has no template", entry.Name)
+	}
+	if entry.Owner == "" {
+		return fmt.Errorf("registry entry '%s' has no owner", entry.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.entries[entry.Name]; exists {
+		return fmt.Errorf("prompt '%s' is already registered", entry.Name)
+	}
+	r.entries[entry.Name] = entry
+	return nil
+}
+
+// RegisterSet registers every template in set, taking the owner, purpose
+// and provider/model requirements from each template's front-matter.
+// defaultOwner is used for templates that don't declare an owner.
+func (r *Registry) RegisterSet(set *Set, defaultOwner string) error {
+	for _, name := range set.Names() {
+		tmpl := set.templates[name]
+		entry := Entry{
+			Name:     name,
+			Template: tmpl,
+			Owner:    tmpl.Metadata.Owner,
+			Purpose:  tmpl.Metadata.Description,
+		}
+		if entry.Owner == "" {
+			entry.Owner = defaultOwner
+		}
+		if tmpl.Metadata.Provider != "" {
+			entry.Requirements.Providers = []string{tmpl.Metadata.Provider}
+		}
+		if tmpl.Metadata.Model != "" {
+			entry.Requirements.Models = []string{tmpl.Metadata.Model}
+		}
+		if err := r.Register(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the entry registered under name.
+func (r *Registry) Lookup(name string) (Entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[name]
+	return entry, ok
+}
+
+// Names returns the registered prompt names in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find returns the entries for which match returns true, in name order.
+func (r *Registry) Find(match func(Entry) bool) []Entry {
+	var found []Entry
+	for _, name := range r.Names() {
+		if entry, ok := r.Lookup(name); ok && match(entry) {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// ByOwner returns the entries owned by owner, in name order.
+func (r *Registry) ByOwner(owner string) []Entry {
+	return r.Find(func(e Entry) bool { return strings.EqualFold(e.Owner, owner) })
+}
+
+// Render renders the registered prompt name with data.
+func (r *Registry) Render(name string, data interface{}) (string, error) {
+	entry, ok := r.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("prompt '%s' is not registered", name)
+	}
+	return entry.Template.Render(data)
+}
+
+// Generate renders the registered prompt name and sends it to client after
+// checking the entry's requirements against client.ProviderName() and, for
+// clients implementing xollm.ModelNamer, the client's model.
+func (r *Registry) Generate(ctx context.Context, client xollm.Client, name string, data interface{}) (string, error) {
+	entry, ok := r.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("prompt '%s' is not registered", name)
+	}
+	var model string
+	if namer, ok := client.(xollm.ModelNamer); ok {
+		model = namer.ModelName()
+	}
+	if err := entry.Requirements.Check(client.ProviderName(), model); err != nil {
+		return "", fmt.Errorf("prompt '%s' cannot be sent: %w", name, err)
+	}
+	rendered, err := entry.Template.Render(data)
+	if err != nil {
+		return "", err
+	}
+	text, err := client.Generate(ctx, rendered)
+	if err != nil {
+		return "", fmt.Errorf("prompt '%s' generation failed: %w", name, err)
+	}
+	return text, nil
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
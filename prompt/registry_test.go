@@ -0,0 +1,157 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_RegisterAndLookup(t *testing.T) {
+	r := NewRegistry()
+	entry := Entry{
+		Name:     "summarize",
+		Template: mustParse(t, "summarize", "Summarize {{.Doc}}"),
+		Owner:    "search-team",
+		Purpose:  "Result snippets",
+		Tags:     []string{"customer-facing"},
+	}
+	if err := r.Register(entry); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	got, ok := r.Lookup("summarize")
+	if !ok {
+		t.Fatal("Expected entry to be found")
+	}
+	if got.Owner != "search-team" || got.Purpose != "Result snippets" {
+		t.Errorf("Unexpected entry: %+v", got)
+	}
+	if _, ok := r.Lookup("missing"); ok {
+		t.Error("Expected missing entry not to be found")
+	}
+
+	if err := r.Register(entry); err == nil {
+		t.Error("Expected error registering a duplicate name")
+	}
+
+	text, err := r.Render("summarize", map[string]string{"Doc": "x"})
+	if err != nil || text != "Summarize x" {
+		t.Errorf("Expected rendered prompt, got '%s' (%v)", text, err)
+	}
+}
+
+func TestRegistry_RegisterValidation(t *testing.T) {
+	tmpl := mustParse(t, "t", "x")
+	r := NewRegistry()
+	for _, entry := range []Entry{
+		{Template: tmpl, Owner: "o"},
+		{Name: "a", Owner: "o"},
+		{Name: "a", Template: tmpl},
+	} {
+		if err := r.Register(entry); err == nil {
+			t.Errorf("Expected validation error for %+v", entry)
+		}
+	}
+}
+
+func TestRegistry_RegisterSet(t *testing.T) {
+	set, err := NewSet(
+		mustParse(t, "owned", "---\nowner = \"ml-platform\"\ndescription = \"Classify tickets\"\nprovider = \"groq\"\nmodel = \"gemma2-9b-it\"\n---\nClassify"),
+		mustParse(t, "plain", "Hello"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create set: %v", err)
+	}
+
+	r := NewRegistry()
+	if err := r.RegisterSet(set, "default-team"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(r.Names(), []string{"owned", "plain"}) {
+		t.Errorf("Unexpected names: %v", r.Names())
+	}
+
+	owned, _ := r.Lookup("owned")
+	if owned.Owner != "ml-platform" || owned.Purpose != "Classify tickets" {
+		t.Errorf("Expected metadata from front-matter, got %+v", owned)
+	}
+	if !reflect.DeepEqual(owned.Requirements.Providers, []string{"groq"}) || !reflect.DeepEqual(owned.Requirements.Models, []string{"gemma2-9b-it"}) {
+		t.Errorf("Expected requirements from hints, got %+v", owned.Requirements)
+	}
+
+	plain, _ := r.Lookup("plain")
+	if plain.Owner != "default-team" {
+		t.Errorf("Expected default owner, got '%s'", plain.Owner)
+	}
+
+	if entries := r.ByOwner("ML-Platform"); len(entries) != 1 || entries[0].Name != "owned" {
+		t.Errorf("Expected case-insensitive owner match, got %v", entries)
+	}
+}
+
+func TestRequirements_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Requirements
+		provider string
+		model    string
+		wantErr  string
+	}{
+		{name: "no requirements", provider: "ollama", model: "anything"},
+		{name: "provider ok", req: Requirements{Providers: []string{"Groq"}}, provider: "groq"},
+		{name: "provider mismatch", req: Requirements{Providers: []string{"groq"}}, provider: "ollama", wantErr: "provider 'ollama'"},
+		{name: "model mismatch", req: Requirements{Models: []string{"a"}}, provider: "groq", model: "b", wantErr: "model 'b'"},
+		{name: "unknown model skipped", req: Requirements{Models: []string{"a"}, MinContextTokens: 1}, provider: "groq"},
+		{name: "context ok", req: Requirements{MinContextTokens: 8000}, provider: "groq", model: "gemma2-9b-it"},
+		{name: "context too small", req: Requirements{MinContextTokens: 32000}, provider: "groq", model: "gemma2-9b-it", wantErr: "8192-token"},
+		{name: "context unknown", req: Requirements{MinContextTokens: 10}, provider: "groq", model: "mystery", wantErr: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Check(tt.provider, tt.model)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRegistry_Generate(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Entry{Name: "any", Template: mustParse(t, "any", "Hi {{.Name}}"), Owner: "o"})
+	r.Register(Entry{Name: "groq-only", Template: mustParse(t, "g", "Hi"), Owner: "o", Requirements: Requirements{Providers: []string{"groq"}}})
+
+	text, err := r.Generate(context.Background(), &mockClient{}, "any", map[string]string{"Name": "Ada"})
+	if err != nil || text != "echo: Hi Ada" {
+		t.Errorf("Expected generation, got '%s' (%v)", text, err)
+	}
+
+	if _, err := r.Generate(context.Background(), &mockClient{}, "groq-only", nil); err == nil || !strings.Contains(err.Error(), "cannot be sent") {
+		t.Errorf("Expected requirement error, got: %v", err)
+	}
+	if _, err := r.Generate(context.Background(), &mockClient{}, "missing", nil); err == nil {
+		t.Error("Expected error for unregistered prompt")
+	}
+
+	// The model of clients reporting one is checked too
+	r.Register(Entry{Name: "70b-only", Template: mustParse(t, "m", "Hi"), Owner: "o", Requirements: Requirements{Models: []string{"llama-3.3-70b-versatile"}}})
+	if _, err := r.Generate(context.Background(), &namedClient{model: "llama-3.1-8b-instant"}, "70b-only", nil); err == nil || !strings.Contains(err.Error(), "model 'llama-3.1-8b-instant'") {
+		t.Errorf("Expected a model requirement error, got: %v", err)
+	}
+	if _, err := r.Generate(context.Background(), &namedClient{model: "llama-3.3-70b-versatile"}, "70b-only", nil); err != nil {
+		t.Errorf("Expected the required model to be accepted, got: %v", err)
+	}
+
+	genErr := errors.New("boom")
+	if _, err := r.Generate(context.Background(), &mockClient{err: genErr}, "any", map[string]string{"Name": "x"}); !errors.Is(err, genErr) {
+		t.Errorf("Expected wrapped generation error, got: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Package prompt provides prompt templates for xollm applications.
+//
+// Templates are plain text files rendered with Go's text/template. They may
+// begin with a TOML front-matter block, delimited by lines containing only
+// "---", that carries metadata such as a description, a model hint and a
+// sampling temperature:
+//
+//	---
+//	description = "Summarize a document in three bullet points"
+//	owner = "search-team"
+//	model = "gemma-3-27b-it"
+//	temperature = 0.2
+//	---
+//	Summarize the following document in three bullet points:
+//
+//	{{.Document}}
+//
+// A directory of .prompt and .tmpl files can be loaded with LoadDir and the
+// templates referenced by name at runtime:
+//
+//	set, err := prompt.LoadDir("prompts")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	text, err := set.Render("summarize", map[string]string{"Document": doc})
+//
+// Templates named "<name>@<version>" (e.g. summarize@v2.prompt) can be
+// grouped into an Experiment that chooses a version per request, so prompt
+// changes can be rolled out gradually and measured.
+//
+// Multi-step workflows (extract, transform, summarize) are composed with a
+// Pipeline, where each Step's output becomes template data for later steps.
+//
+// A Registry catalogues prompts with their owner, purpose and model
+// requirements so applications can govern what gets sent to LLMs.
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+)
+
+const frontMatterDelimiter = "---"
+
+// Metadata is the front-matter of a prompt template.
+type Metadata struct {
+	// Description explains what the prompt is for.
+	Description string `toml:"description,omitempty"`
+
+	// Owner is the team or person responsible for the prompt.
+	Owner string `toml:"owner,omitempty"`
+
+	// Provider is an optional hint for which provider the prompt targets.
+	Provider string `toml:"provider,omitempty"`
+
+	// Model is an optional hint for which model the prompt was tuned on.
+	Model string `toml:"model,omitempty"`
+
+	// Temperature is an optional sampling temperature hint in the range [0, 2].
+	Temperature *float64 `toml:"temperature,omitempty"`
+
+	// MaxTokens is an optional hint for the maximum response length.
+	MaxTokens *int `toml:"max_tokens,omitempty"`
+}
+
+// Template is a parsed prompt template.
+//
+// Templates are immutable after parsing and safe for concurrent use.
+type Template struct {
+	// Name is the name the template is referenced by.
+	Name string
+	// Path is the file the template was loaded from, if any.
+	Path string
+	// Metadata holds the parsed front-matter.
+	Metadata Metadata
+	// Body is the raw template text without front-matter.
+	Body string
+
+	tmpl *template.Template
+}
+
+// Parse parses a prompt template, including optional front-matter, from content.
+func Parse(name, content string) (*Template, error) {
+	metadata, body, err := splitFrontMatter(content)
+	if err != nil {
+		return nil, fmt.Errorf("prompt '%s': %w", name, err)
+	}
+	if err := metadata.validate(); err != nil {
+		return nil, fmt.Errorf("prompt '%s': %w", name, err)
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("prompt '%s': template body is empty", name)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("prompt '%s': invalid template: %w", name, err)
+	}
+
+	return &Template{
+		Name:     name,
+		Metadata: metadata,
+		Body:     body,
+		tmpl:     tmpl,
+	}, nil
+}
+
+// Render executes the template with data and returns the rendered prompt.
+// Referencing a map key that is missing from data is an error.
+func (t *Template) Render(data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt '%s': %w", t.Name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// splitFrontMatter separates and decodes the optional TOML front-matter.
+func splitFrontMatter(content string) (Metadata, string, error) {
+	var metadata Metadata
+
+	content = strings.TrimPrefix(content, "\ufeff") // Strip UTF-8 BOM
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, frontMatterDelimiter+"\n") {
+		return metadata, content, nil
+	}
+
+	rest := normalized[len(frontMatterDelimiter)+1:]
+	end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n")
+	var header, body string
+	switch {
+	case strings.HasPrefix(rest, frontMatterDelimiter+"\n"):
+		header, body = "", rest[len(frontMatterDelimiter)+1:]
+	case end >= 0:
+		header, body = rest[:end], rest[end+len(frontMatterDelimiter)+2:]
+	case strings.HasSuffix(rest, "\n"+frontMatterDelimiter):
+		header, body = strings.TrimSuffix(rest, "\n"+frontMatterDelimiter), ""
+	default:
+		return metadata, "", fmt.Errorf("front-matter is not terminated by '%s'", frontMatterDelimiter)
+	}
+
+	meta, err := toml.Decode(header, &metadata)
+	if err != nil {
+		return metadata, "", fmt.Errorf("invalid front-matter: %w", err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return metadata, "", fmt.Errorf("unknown front-matter keys: %v", undecoded)
+	}
+
+	return metadata, body, nil
+}
+
+// validate checks metadata values for obvious mistakes.
+func (m Metadata) validate() error {
+	if m.Temperature != nil && (*m.Temperature < 0 || *m.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %v", *m.Temperature)
+	}
+	if m.MaxTokens != nil && *m.MaxTokens <= 0 {
+		return fmt.Errorf("max_tokens must be positive, got %d", *m.MaxTokens)
+	}
+	return nil
+}
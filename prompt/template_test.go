@@ -0,0 +1,99 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_WithFrontMatter(t *testing.T) {
+	content := "---\n" +
+		"description = \"Summarize a document\"\n" +
+		"model = \"gemma-3-27b-it\"\n" +
+		"temperature = 0.2\n" +
+		"---\n" +
+		"Summarize: {{.Document}}\n"
+
+	tmpl, err := Parse("summarize", content)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if tmpl.Metadata.Description != "Summarize a document" {
+		t.Errorf("Expected description 'Summarize a document', got '%s'", tmpl.Metadata.Description)
+	}
+	if tmpl.Metadata.Model != "gemma-3-27b-it" {
+		t.Errorf("Expected model 'gemma-3-27b-it', got '%s'", tmpl.Metadata.Model)
+	}
+	if tmpl.Metadata.Temperature == nil || *tmpl.Metadata.Temperature != 0.2 {
+		t.Errorf("Expected temperature 0.2, got %v", tmpl.Metadata.Temperature)
+	}
+	if tmpl.Body != "Summarize: {{.Document}}\n" {
+		t.Errorf("Unexpected body: %q", tmpl.Body)
+	}
+
+	rendered, err := tmpl.Render(map[string]string{"Document": "the report"})
+	if err != nil {
+		t.Fatalf("Expected no error rendering, got: %v", err)
+	}
+	if rendered != "Summarize: the report" {
+		t.Errorf("Expected 'Summarize: the report', got '%s'", rendered)
+	}
+}
+
+func TestParse_WithoutFrontMatter(t *testing.T) {
+	tmpl, err := Parse("plain", "Hello {{.Name}}")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if tmpl.Metadata.Description != "" || tmpl.Metadata.Temperature != nil {
+		t.Errorf("Expected empty metadata, got %+v", tmpl.Metadata)
+	}
+}
+
+func TestParse_CRLFFrontMatter(t *testing.T) {
+	tmpl, err := Parse("crlf", "---\r\ndescription = \"x\"\r\n---\r\nHello")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if tmpl.Metadata.Description != "x" {
+		t.Errorf("Expected description 'x', got '%s'", tmpl.Metadata.Description)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errPart string
+	}{
+		{name: "unterminated front-matter", content: "---\ndescription = \"x\"\nHello", errPart: "not terminated"},
+		{name: "invalid TOML", content: "---\ndescription = \n---\nHello", errPart: "invalid front-matter"},
+		{name: "unknown key", content: "---\ncolour = \"red\"\n---\nHello", errPart: "unknown front-matter keys"},
+		{name: "temperature out of range", content: "---\ntemperature = 3.5\n---\nHello", errPart: "temperature"},
+		{name: "empty body", content: "---\ndescription = \"x\"\n---\n  \n", errPart: "empty"},
+		{name: "invalid template", content: "Hello {{.Name", errPart: "invalid template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("bad", tt.content)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("Expected error to contain '%s', got: %v", tt.errPart, err)
+			}
+		})
+	}
+}
+
+func TestTemplate_RenderMissingKey(t *testing.T) {
+	tmpl, err := Parse("greet", "Hello {{.Name}}")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := tmpl.Render(map[string]string{}); err == nil {
+		t.Error("Expected error for missing template variable")
+	}
+}
@@ -0,0 +1,137 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"unicode"
+
+	"github.com/xostack/xollm"
+)
+
+// TruncateStrategy selects which part of a text is removed when it has to be
+// shortened to fit a token budget.
+type TruncateStrategy int
+
+const (
+	// TruncateEnd keeps the beginning of the text and drops the end.
+	TruncateEnd TruncateStrategy = iota
+	// TruncateStart keeps the end of the text and drops the beginning.
+	TruncateStart
+	// TruncateMiddle keeps the beginning and end and replaces the middle
+	// with TruncationMarker.
+	TruncateMiddle
+)
+
+// TruncationMarker is inserted where text was removed by TruncateMiddle.
+const TruncationMarker = "\n[...]\n"
+
+// wordBoundaryWindow is the fraction of kept text that may be given up to
+// avoid cutting through the middle of a word.
+const wordBoundaryWindow = 0.2
+
+// Truncate shortens text so that it fits within maxTokens according to
+// counter, removing content as selected by strategy. Text that already fits
+// is returned unchanged. Cuts are moved to a nearby word boundary when
+// possible.
+//
+// A nil counter uses xollm.EstimateCounter. Since exact counters may call a
+// provider API, Truncate keeps the number of counting calls logarithmic in
+// the text length.
+func Truncate(ctx context.Context, counter xollm.TokenCounter, text string, maxTokens int, strategy TruncateStrategy) (string, error) {
+	if maxTokens <= 0 {
+		return "", fmt.Errorf("token budget must be positive, got %d", maxTokens)
+	}
+	if counter == nil {
+		counter = xollm.EstimateCounter{}
+	}
+
+	total, err := counter.CountTokens(ctx, text)
+	if err != nil {
+		return "", fmt.Errorf("failed to count tokens: %w", err)
+	}
+	if total <= maxTokens {
+		return text, nil
+	}
+
+	runes := []rune(text)
+	var build func(n int) string
+	switch strategy {
+	case TruncateEnd:
+		build = func(n int) string { return string(runes[:snapHead(runes, n)]) }
+	case TruncateStart:
+		build = func(n int) string { return string(runes[snapTail(runes, len(runes)-n):]) }
+	case TruncateMiddle:
+		build = func(n int) string {
+			head := snapHead(runes, n/2)
+			tail := snapTail(runes, len(runes)-(n-n/2))
+			return string(runes[:head]) + TruncationMarker + string(runes[tail:])
+		}
+	default:
+		return "", fmt.Errorf("unknown truncation strategy %d", strategy)
+	}
+
+	// Binary search for the largest number of kept runes that fits
+	low, high := 0, len(runes)-1
+	best := ""
+	for low <= high {
+		mid := (low + high) / 2
+		candidate := build(mid)
+		n, err := counter.CountTokens(ctx, candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to count tokens: %w", err)
+		}
+		if n <= maxTokens {
+			best = candidate
+			low = mid + 1
+		} else {
+			high = mid - 1
+		}
+	}
+	return best, nil
+}
+
+// FitToModel truncates text so that it fits in model's context window while
+// leaving reserveTokens free for the response and any surrounding prompt.
+// The model's limits come from the bundled registry (xollm.LookupModel).
+func FitToModel(ctx context.Context, counter xollm.TokenCounter, model string, text string, reserveTokens int, strategy TruncateStrategy) (string, error) {
+	info, ok := xollm.LookupModel(model)
+	if !ok {
+		return "", fmt.Errorf("unknown context window for model '%s'", model)
+	}
+	budget := info.ContextWindow - reserveTokens
+	if budget <= 0 {
+		return "", fmt.Errorf("reserve of %d tokens leaves no room in the %d-token context window of model '%s'", reserveTokens, info.ContextWindow, model)
+	}
+	return Truncate(ctx, counter, text, budget, strategy)
+}
+
+// snapHead moves a head cut at n back to the preceding whitespace if one is
+// close enough, so the kept head does not end mid-word.
+func snapHead(runes []rune, n int) int {
+	if n <= 0 || n >= len(runes) || unicode.IsSpace(runes[n]) {
+		return n
+	}
+	limit := n - int(float64(n)*wordBoundaryWindow)
+	for i := n - 1; i >= limit && i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			return i
+		}
+	}
+	return n
+}
+
+// snapTail moves a tail cut at start forward to the next whitespace if one
+// is close enough, so the kept tail does not begin mid-word.
+func snapTail(runes []rune, start int) int {
+	if start <= 0 || start >= len(runes) || unicode.IsSpace(runes[start-1]) {
+		return start
+	}
+	kept := len(runes) - start
+	limit := start + int(float64(kept)*wordBoundaryWindow)
+	for i := start; i <= limit && i < len(runes); i++ {
+		if unicode.IsSpace(runes[i]) {
+			return i + 1
+		}
+	}
+	return start
+}
@@ -0,0 +1,146 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// wordCounter counts whitespace-separated words as tokens
+type wordCounter struct {
+	calls int
+	err   error
+}
+
+func (w *wordCounter) CountTokens(ctx context.Context, text string) (int, error) {
+	w.calls++
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(strings.Fields(strings.ReplaceAll(text, TruncationMarker, " "))), nil
+}
+
+const sampleText = "one two three four five six seven eight nine ten"
+
+func TestTruncate_FitsUnchanged(t *testing.T) {
+	out, err := Truncate(context.Background(), &wordCounter{}, sampleText, 10, TruncateEnd)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if out != sampleText {
+		t.Errorf("Expected text unchanged, got '%s'", out)
+	}
+}
+
+func TestTruncate_Strategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy TruncateStrategy
+		check    func(t *testing.T, out string)
+	}{
+		{
+			name:     "end",
+			strategy: TruncateEnd,
+			check: func(t *testing.T, out string) {
+				if !strings.HasPrefix(out, "one two three") || strings.Contains(out, "ten") {
+					t.Errorf("Expected head to be kept, got '%s'", out)
+				}
+			},
+		},
+		{
+			name:     "start",
+			strategy: TruncateStart,
+			check: func(t *testing.T, out string) {
+				if !strings.HasSuffix(out, "nine ten") || strings.Contains(out, "one") {
+					t.Errorf("Expected tail to be kept, got '%s'", out)
+				}
+			},
+		},
+		{
+			name:     "middle",
+			strategy: TruncateMiddle,
+			check: func(t *testing.T, out string) {
+				if !strings.HasPrefix(out, "one") || !strings.HasSuffix(out, "ten") || !strings.Contains(out, TruncationMarker) {
+					t.Errorf("Expected head and tail with marker, got '%s'", out)
+				}
+				if strings.Contains(out, "five six") {
+					t.Errorf("Expected middle to be dropped, got '%s'", out)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := &wordCounter{}
+			out, err := Truncate(context.Background(), counter, sampleText, 4, tt.strategy)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if n, _ := counter.CountTokens(context.Background(), out); n > 4 {
+				t.Errorf("Expected at most 4 tokens, got %d in '%s'", n, out)
+			}
+			if n, _ := counter.CountTokens(context.Background(), out); n < 3 {
+				t.Errorf("Expected close to 4 tokens kept, got %d in '%s'", n, out)
+			}
+			tt.check(t, out)
+		})
+	}
+}
+
+func TestTruncate_DoesNotCutWords(t *testing.T) {
+	out, err := Truncate(context.Background(), nil, strings.Repeat("abcdefgh ", 50), 20, TruncateEnd)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, word := range strings.Fields(out) {
+		if word != "abcdefgh" {
+			t.Errorf("Expected whole words only, found '%s' in '%s'", word, out)
+		}
+	}
+}
+
+func TestTruncate_LogarithmicCalls(t *testing.T) {
+	counter := &wordCounter{}
+	long := strings.Repeat("word ", 10000)
+	if _, err := Truncate(context.Background(), counter, long, 100, TruncateEnd); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if counter.calls > 30 {
+		t.Errorf("Expected a logarithmic number of count calls, got %d", counter.calls)
+	}
+}
+
+func TestTruncate_Errors(t *testing.T) {
+	if _, err := Truncate(context.Background(), nil, "text", 0, TruncateEnd); err == nil {
+		t.Error("Expected error for non-positive budget")
+	}
+	if _, err := Truncate(context.Background(), nil, strings.Repeat("x", 100), 1, TruncateStrategy(99)); err == nil {
+		t.Error("Expected error for unknown strategy")
+	}
+
+	countErr := errors.New("count failed")
+	if _, err := Truncate(context.Background(), &wordCounter{err: countErr}, "text", 5, TruncateEnd); !errors.Is(err, countErr) {
+		t.Errorf("Expected counter error to be wrapped, got: %v", err)
+	}
+}
+
+func TestFitToModel(t *testing.T) {
+	// gemma2-9b-it has an 8192-token context window
+	text := strings.Repeat("word ", 9000)
+	out, err := FitToModel(context.Background(), &wordCounter{}, "gemma2-9b-it", text, 1192, TruncateStart)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if n := len(strings.Fields(out)); n > 7000 || n < 6900 {
+		t.Errorf("Expected about 7000 words to be kept, got %d", n)
+	}
+
+	if _, err := FitToModel(context.Background(), nil, "unknown-model", text, 0, TruncateEnd); err == nil {
+		t.Error("Expected error for unknown model")
+	}
+	if _, err := FitToModel(context.Background(), nil, "gemma2-9b-it", text, 9000, TruncateEnd); err == nil {
+		t.Error("Expected error when reserve exceeds context window")
+	}
+}
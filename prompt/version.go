@@ -0,0 +1,233 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/xostack/xollm"
+)
+
+// versionSeparator separates a prompt's base name from its version in
+// template names, e.g. "summarize@v2".
+const versionSeparator = "@"
+
+// Version is one variant of a prompt taking part in an experiment.
+type Version struct {
+	// ID identifies the version, e.g. "v2". It is reported in results.
+	ID string
+	// Template is the prompt text for this version.
+	Template *Template
+	// Weight is the relative share of traffic for RolloutStrategy.
+	Weight int
+	// Tags select this version under TagStrategy.
+	Tags []string
+}
+
+// Selection carries the per-request inputs used to choose a version.
+type Selection struct {
+	// Key makes rollout selection sticky, e.g. a user or session ID.
+	// An empty key selects randomly on each request.
+	Key string
+	// Tags are matched against Version.Tags by TagStrategy.
+	Tags []string
+}
+
+// Strategy chooses which version of an experiment serves a request.
+type Strategy interface {
+	Choose(experiment *Experiment, selection Selection) (*Version, error)
+}
+
+// FixedStrategy always chooses the version with the given ID.
+type FixedStrategy struct {
+	VersionID string
+}
+
+// Choose implements Strategy.
+func (s FixedStrategy) Choose(e *Experiment, _ Selection) (*Version, error) {
+	if v, ok := e.Version(s.VersionID); ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("experiment '%s' has no version '%s'", e.Name, s.VersionID)
+}
+
+// RolloutStrategy splits traffic between versions in proportion to their
+// Weight. Requests with the same Selection.Key always get the same version
+// as long as the weights don't change.
+type RolloutStrategy struct{}
+
+// Choose implements Strategy.
+func (RolloutStrategy) Choose(e *Experiment, selection Selection) (*Version, error) {
+	total := 0
+	for _, v := range e.Versions {
+		if v.Weight < 0 {
+			return nil, fmt.Errorf("experiment '%s' version '%s' has negative weight", e.Name, v.ID)
+		}
+		total += v.Weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("experiment '%s' has no version with a positive weight", e.Name)
+	}
+
+	var point int
+	if selection.Key == "" {
+		point = rand.Intn(total)
+	} else {
+		h := fnv.New32a()
+		h.Write([]byte(e.Name + versionSeparator + selection.Key))
+		point = int(h.Sum32() % uint32(total))
+	}
+
+	for i := range e.Versions {
+		point -= e.Versions[i].Weight
+		if point < 0 {
+			return &e.Versions[i], nil
+		}
+	}
+	return &e.Versions[len(e.Versions)-1], nil
+}
+
+// TagStrategy chooses the first version that shares a tag with the request,
+// falling back to the version with ID Default when none match.
+type TagStrategy struct {
+	Default string
+}
+
+// Choose implements Strategy.
+func (s TagStrategy) Choose(e *Experiment, selection Selection) (*Version, error) {
+	for i := range e.Versions {
+		for _, tag := range e.Versions[i].Tags {
+			for _, want := range selection.Tags {
+				if tag == want {
+					return &e.Versions[i], nil
+				}
+			}
+		}
+	}
+	if s.Default == "" {
+		return nil, fmt.Errorf("experiment '%s' has no version matching tags %v", e.Name, selection.Tags)
+	}
+	return FixedStrategy{VersionID: s.Default}.Choose(e, selection)
+}
+
+// Experiment is a prompt with several versions and a strategy for choosing
+// between them.
+type Experiment struct {
+	Name     string
+	Versions []Version
+	Strategy Strategy
+}
+
+// ExperimentResult is the outcome of running an experiment, including which
+// version was served and the response's metadata, so results, tokens and
+// cost can be attributed to the version when measuring.
+type ExperimentResult struct {
+	// Text is the generated response.
+	Text string
+	// Experiment is the experiment name.
+	Experiment string
+	// VersionID is the chosen version.
+	VersionID string
+	// Prompt is the rendered prompt that was sent.
+	Prompt string
+	// ResponseMetadata describes the response: usage, cost, finish reason
+	// and latency. It is nil if generation failed.
+	*xollm.ResponseMetadata
+}
+
+// NewExperiment creates an experiment, validating that version IDs are
+// present and unique and that every version has a template.
+func NewExperiment(name string, strategy Strategy, versions ...Version) (*Experiment, error) {
+	if strategy == nil {
+		return nil, fmt.Errorf("experiment '%s' requires a strategy", name)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("experiment '%s' requires at least one version", name)
+	}
+	seen := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		if v.ID == "" {
+			return nil, fmt.Errorf("experiment '%s' has a version without an ID", name)
+		}
+		if seen[v.ID] {
+			return nil, fmt.Errorf("experiment '%s' has duplicate version '%s'", name, v.ID)
+		}
+		if v.Template == nil {
+			return nil, fmt.Errorf("experiment '%s' version '%s' has no template", name, v.ID)
+		}
+		seen[v.ID] = true
+	}
+	return &Experiment{Name: name, Versions: versions, Strategy: strategy}, nil
+}
+
+// Version returns the version with the given ID.
+func (e *Experiment) Version(id string) (*Version, bool) {
+	for i := range e.Versions {
+		if e.Versions[i].ID == id {
+			return &e.Versions[i], true
+		}
+	}
+	return nil, false
+}
+
+// Choose selects a version for a request using the experiment's strategy.
+func (e *Experiment) Choose(selection Selection) (*Version, error) {
+	return e.Strategy.Choose(e, selection)
+}
+
+// Generate chooses a version, renders it with data and sends it to client
+// with xollm.GenerateWithMetadata. The chosen version is reported in the
+// result even when generation fails.
+func (e *Experiment) Generate(ctx context.Context, client xollm.Client, data interface{}, selection Selection) (ExperimentResult, error) {
+	result := ExperimentResult{Experiment: e.Name}
+
+	version, err := e.Choose(selection)
+	if err != nil {
+		return result, err
+	}
+	result.VersionID = version.ID
+
+	rendered, err := version.Template.Render(data)
+	if err != nil {
+		return result, err
+	}
+	result.Prompt = rendered
+
+	text, md, err := xollm.GenerateWithMetadata(ctx, client, rendered)
+	if err != nil {
+		return result, fmt.Errorf("experiment '%s' version '%s' generation failed: %w", e.Name, version.ID, err)
+	}
+	result.Text, result.ResponseMetadata = text, md
+	return result, nil
+}
+
+// Experiment builds an experiment from the versioned templates in the set
+// named "<base>@<version>", e.g. "summarize@v1" and "summarize@v2".
+// Each version gets an equal rollout weight; adjust Versions as needed.
+func (s *Set) Experiment(base string, strategy Strategy) (*Experiment, error) {
+	var versions []Version
+	for _, name := range s.Names() {
+		b, id := SplitVersion(name)
+		if b == base && id != "" {
+			versions = append(versions, Version{ID: id, Template: s.templates[name], Weight: 1})
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions of prompt '%s' found", base)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID < versions[j].ID })
+	return NewExperiment(base, strategy, versions...)
+}
+
+// SplitVersion splits a template name such as "summarize@v2" into its base
+// name and version. The version is empty for unversioned names.
+func SplitVersion(name string) (base, version string) {
+	i := strings.LastIndex(name, versionSeparator)
+	if i < 0 {
+		return name, ""
+	}
+	return name[:i], name[i+len(versionSeparator):]
+}
@@ -0,0 +1,258 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	err error
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return "echo: " + prompt, nil
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error { return nil }
+
+// namedClient is a mockClient reporting its model.
+type namedClient struct {
+	mockClient
+	model string
+}
+
+func (n *namedClient) ModelName() string { return n.model }
+
+// usageClient is a mockClient reporting the usage of its responses.
+type usageClient struct {
+	mockClient
+	usage xollm.Usage
+}
+
+func (u *usageClient) GenerateWithMetadata(ctx context.Context, prompt string) (string, *xollm.ResponseMetadata, error) {
+	text, err := u.Generate(ctx, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	return text, &xollm.ResponseMetadata{Usage: u.usage, FinishReason: "stop"}, nil
+}
+
+func mustParse(t *testing.T, name, content string) *Template {
+	t.Helper()
+	tmpl, err := Parse(name, content)
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	return tmpl
+}
+
+func newTestExperiment(t *testing.T, strategy Strategy) *Experiment {
+	t.Helper()
+	e, err := NewExperiment("greet", strategy,
+		Version{ID: "v1", Template: mustParse(t, "greet@v1", "Hello {{.Name}}"), Weight: 90, Tags: []string{"stable"}},
+		Version{ID: "v2", Template: mustParse(t, "greet@v2", "Hi there {{.Name}}"), Weight: 10, Tags: []string{"beta"}},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	return e
+}
+
+func TestFixedStrategy(t *testing.T) {
+	e := newTestExperiment(t, FixedStrategy{VersionID: "v2"})
+
+	v, err := e.Choose(Selection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if v.ID != "v2" {
+		t.Errorf("Expected version 'v2', got '%s'", v.ID)
+	}
+
+	e.Strategy = FixedStrategy{VersionID: "v9"}
+	if _, err := e.Choose(Selection{}); err == nil {
+		t.Error("Expected error for unknown fixed version")
+	}
+}
+
+func TestRolloutStrategy_StickyAndProportional(t *testing.T) {
+	e := newTestExperiment(t, RolloutStrategy{})
+
+	// Same key always yields the same version
+	first, err := e.Choose(Selection{Key: "user-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		v, _ := e.Choose(Selection{Key: "user-1"})
+		if v.ID != first.ID {
+			t.Fatalf("Expected sticky version '%s', got '%s'", first.ID, v.ID)
+		}
+	}
+
+	// Distribution across many keys roughly follows weights
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		v, _ := e.Choose(Selection{Key: fmt.Sprintf("user-%d", i)})
+		counts[v.ID]++
+	}
+	if counts["v2"] < 100 || counts["v2"] > 320 {
+		t.Errorf("Expected roughly 10%% of traffic on v2, got %d/2000", counts["v2"])
+	}
+}
+
+func TestRolloutStrategy_InvalidWeights(t *testing.T) {
+	e := newTestExperiment(t, RolloutStrategy{})
+	e.Versions[0].Weight = 0
+	e.Versions[1].Weight = 0
+
+	if _, err := e.Choose(Selection{}); err == nil {
+		t.Error("Expected error when all weights are zero")
+	}
+}
+
+func TestTagStrategy(t *testing.T) {
+	e := newTestExperiment(t, TagStrategy{Default: "v1"})
+
+	v, err := e.Choose(Selection{Tags: []string{"beta"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if v.ID != "v2" {
+		t.Errorf("Expected tagged version 'v2', got '%s'", v.ID)
+	}
+
+	v, err = e.Choose(Selection{Tags: []string{"unknown"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if v.ID != "v1" {
+		t.Errorf("Expected default version 'v1', got '%s'", v.ID)
+	}
+
+	e.Strategy = TagStrategy{}
+	if _, err := e.Choose(Selection{Tags: []string{"unknown"}}); err == nil {
+		t.Error("Expected error when no tag matches and no default is set")
+	}
+}
+
+func TestNewExperiment_Validation(t *testing.T) {
+	tmpl := mustParse(t, "x", "x")
+	tests := []struct {
+		name     string
+		strategy Strategy
+		versions []Version
+	}{
+		{name: "nil strategy", strategy: nil, versions: []Version{{ID: "v1", Template: tmpl}}},
+		{name: "no versions", strategy: RolloutStrategy{}},
+		{name: "missing ID", strategy: RolloutStrategy{}, versions: []Version{{Template: tmpl}}},
+		{name: "duplicate ID", strategy: RolloutStrategy{}, versions: []Version{{ID: "v1", Template: tmpl}, {ID: "v1", Template: tmpl}}},
+		{name: "missing template", strategy: RolloutStrategy{}, versions: []Version{{ID: "v1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExperiment("e", tt.strategy, tt.versions...); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestExperiment_GenerateReportsVersion(t *testing.T) {
+	e := newTestExperiment(t, FixedStrategy{VersionID: "v2"})
+
+	result, err := e.Generate(context.Background(), &mockClient{}, map[string]string{"Name": "Ada"}, Selection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.VersionID != "v2" || result.Experiment != "greet" {
+		t.Errorf("Expected experiment 'greet' version 'v2', got %+v", result)
+	}
+	if result.Prompt != "Hi there Ada" {
+		t.Errorf("Expected rendered prompt 'Hi there Ada', got '%s'", result.Prompt)
+	}
+	if result.Text != "echo: Hi there Ada" {
+		t.Errorf("Unexpected text: '%s'", result.Text)
+	}
+}
+
+func TestExperiment_GenerateReportsMetadata(t *testing.T) {
+	e := newTestExperiment(t, FixedStrategy{VersionID: "v2"})
+	client := &usageClient{usage: xollm.Usage{PromptTokens: 12, CompletionTokens: 30}}
+
+	result, err := e.Generate(context.Background(), client, map[string]string{"Name": "Ada"}, Selection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.ResponseMetadata == nil || result.Usage.TotalTokens() != 42 || result.FinishReason != "stop" {
+		t.Errorf("Expected the response's usage and finish reason for version v2, got %+v", result.ResponseMetadata)
+	}
+
+	result, _ = e.Generate(context.Background(), &usageClient{mockClient: mockClient{err: errors.New("down")}}, map[string]string{"Name": "Ada"}, Selection{})
+	if result.ResponseMetadata != nil {
+		t.Errorf("Expected no metadata for a failed generation, got %+v", result.ResponseMetadata)
+	}
+}
+
+func TestExperiment_GenerateErrorKeepsVersion(t *testing.T) {
+	e := newTestExperiment(t, FixedStrategy{VersionID: "v1"})
+	genErr := errors.New("provider down")
+
+	result, err := e.Generate(context.Background(), &mockClient{err: genErr}, map[string]string{"Name": "Ada"}, Selection{})
+	if !errors.Is(err, genErr) {
+		t.Fatalf("Expected wrapped generation error, got: %v", err)
+	}
+	if result.VersionID != "v1" {
+		t.Errorf("Expected version to be reported on failure, got '%s'", result.VersionID)
+	}
+}
+
+func TestSet_Experiment(t *testing.T) {
+	set, err := NewSet(
+		mustParse(t, "greet@v1", "Hello"),
+		mustParse(t, "greet@v2", "Hi"),
+		mustParse(t, "other", "Other"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create set: %v", err)
+	}
+
+	e, err := set.Experiment("greet", RolloutStrategy{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(e.Versions) != 2 || e.Versions[0].ID != "v1" || e.Versions[1].ID != "v2" {
+		t.Errorf("Expected versions v1 and v2, got %+v", e.Versions)
+	}
+
+	if _, err := set.Experiment("missing", RolloutStrategy{}); err == nil {
+		t.Error("Expected error for prompt without versions")
+	}
+}
+
+func TestSplitVersion(t *testing.T) {
+	tests := []struct {
+		name, base, version string
+	}{
+		{"summarize@v2", "summarize", "v2"},
+		{"dir/summarize@2024-01", "dir/summarize", "2024-01"},
+		{"plain", "plain", ""},
+	}
+	for _, tt := range tests {
+		base, version := SplitVersion(tt.name)
+		if base != tt.base || version != tt.version {
+			t.Errorf("SplitVersion(%q) = (%q, %q), expected (%q, %q)", tt.name, base, version, tt.base, tt.version)
+		}
+	}
+}
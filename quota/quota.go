@@ -0,0 +1,240 @@
+// Package quota enforces per-tenant daily request and token limits.
+//
+// Requests are attributed to a tenant by a request tag (see
+// xollm.WithTags), so one client can serve every tenant of a multi-tenant
+// application. A Manager holds the limits and a Store holds the daily
+// counters; MemoryStore suits a single process, while a Store backed by
+// Redis or a database shares quotas across replicas.
+//
+//	manager := quota.New(quota.NewMemoryStore(), quota.Limits{RequestsPerDay: 100, TokensPerDay: 50000})
+//	manager.SetLimits("enterprise-customer", quota.Limits{RequestsPerDay: 10000})
+//	client = quota.Wrap(client, manager)
+//
+//	ctx = xollm.WithTags(ctx, map[string]string{quota.TenantTag: customerID})
+//	text, err := client.Generate(ctx, prompt)
+//	var exceeded *quota.ExceededError
+//	if errors.As(err, &exceeded) {
+//		// tell the customer to upgrade, or to come back at exceeded.ResetAt
+//	}
+//
+// Days run from midnight to midnight UTC. Token limits are checked before
+// each request against the tokens already used, and a request's tokens are
+// counted once it completes, so the last request of a day may overshoot the
+// limit.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// TenantTag is the default request tag identifying the tenant.
+const TenantTag = "tenant"
+
+// Limits are a tenant's daily allowances. Zero means unlimited.
+type Limits struct {
+	RequestsPerDay int
+	TokensPerDay   int
+}
+
+// Usage is a tenant's consumption on one day.
+type Usage struct {
+	Requests int
+	Tokens   int
+}
+
+// Store keeps the daily usage counters. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Add adds requests and tokens, either of which may be negative, to the
+	// tenant's usage on day and returns the new totals. The update must be
+	// atomic so that concurrent requests can't both take the last unit of
+	// quota.
+	Add(ctx context.Context, tenant, day string, requests, tokens int) (Usage, error)
+	// Get returns the tenant's usage on day.
+	Get(ctx context.Context, tenant, day string) (Usage, error)
+}
+
+// ExceededError reports a request rejected because the tenant used up a
+// daily allowance. It matches xollm.ErrRateLimited with errors.Is.
+type ExceededError struct {
+	Tenant string
+	// Limit names the exhausted allowance, "requests" or "tokens".
+	Limit string
+	// Used and Allowed are the day's usage and allowance for Limit.
+	Used, Allowed int
+	// ResetAt is when the allowance renews.
+	ResetAt time.Time
+}
+
+// Error describes the exhausted allowance.
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("tenant %q exceeded its daily %s quota (%d of %d), resets at %s",
+		e.Tenant, e.Limit, e.Used, e.Allowed, e.ResetAt.Format(time.RFC3339))
+}
+
+// Is reports whether target is xollm.ErrRateLimited.
+func (e *ExceededError) Is(target error) bool {
+	return target == xollm.ErrRateLimited
+}
+
+// Manager enforces limits per tenant. It is safe for concurrent use.
+type Manager struct {
+	store    Store
+	defaults Limits
+	tag      string
+	now      func() time.Time
+
+	mu     sync.RWMutex
+	limits map[string]Limits
+}
+
+// New returns a manager keeping counters in store and applying defaults to
+// tenants without limits of their own.
+func New(store Store, defaults Limits) *Manager {
+	return &Manager{
+		store:    store,
+		defaults: defaults,
+		tag:      TenantTag,
+		now:      time.Now,
+		limits:   make(map[string]Limits),
+	}
+}
+
+// SetTenantTag changes the request tag identifying the tenant from
+// TenantTag to tag. Set it before the manager is used.
+func (m *Manager) SetTenantTag(tag string) {
+	m.tag = tag
+}
+
+// SetLimits sets the limits of one tenant, e.g. from its plan.
+func (m *Manager) SetLimits(tenant string, limits Limits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits[tenant] = limits
+}
+
+// Limits returns the limits that apply to tenant.
+func (m *Manager) Limits(tenant string) Limits {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if limits, ok := m.limits[tenant]; ok {
+		return limits
+	}
+	return m.defaults
+}
+
+// Tenant returns the tenant a request belongs to, from its tags. Requests
+// without the tag belong to the tenant "", which has the default limits.
+func (m *Manager) Tenant(ctx context.Context) string {
+	return xollm.TagFromContext(ctx, m.tag)
+}
+
+// Usage returns the tenant's usage today.
+func (m *Manager) Usage(ctx context.Context, tenant string) (Usage, error) {
+	return m.store.Get(ctx, tenant, m.day())
+}
+
+// Reserve counts a request for tenant, failing with an *ExceededError if
+// the tenant has no requests or tokens left today. Callers report the
+// request's tokens with AddTokens once it completes.
+func (m *Manager) Reserve(ctx context.Context, tenant string) error {
+	limits := m.Limits(tenant)
+	day := m.day()
+	usage, err := m.store.Add(ctx, tenant, day, 1, 0)
+	if err != nil {
+		return fmt.Errorf("failed to update quota: %w", err)
+	}
+
+	var exceeded *ExceededError
+	switch {
+	case limits.RequestsPerDay > 0 && usage.Requests > limits.RequestsPerDay:
+		exceeded = &ExceededError{Tenant: tenant, Limit: "requests", Used: usage.Requests - 1, Allowed: limits.RequestsPerDay}
+	case limits.TokensPerDay > 0 && usage.Tokens >= limits.TokensPerDay:
+		exceeded = &ExceededError{Tenant: tenant, Limit: "tokens", Used: usage.Tokens, Allowed: limits.TokensPerDay}
+	default:
+		return nil
+	}
+
+	// Rejected requests don't use up quota
+	if _, err := m.store.Add(ctx, tenant, day, -1, 0); err != nil {
+		return fmt.Errorf("failed to update quota: %w", err)
+	}
+	exceeded.ResetAt = m.resetAt()
+	return exceeded
+}
+
+// AddTokens counts tokens used by a completed request of tenant.
+func (m *Manager) AddTokens(ctx context.Context, tenant string, tokens int) error {
+	if tokens == 0 {
+		return nil
+	}
+	if _, err := m.store.Add(ctx, tenant, m.day(), 0, tokens); err != nil {
+		return fmt.Errorf("failed to update quota: %w", err)
+	}
+	return nil
+}
+
+// day returns the current UTC date, the key of today's counters.
+func (m *Manager) day() string {
+	return m.now().UTC().Format(time.DateOnly)
+}
+
+// resetAt returns the next UTC midnight.
+func (m *Manager) resetAt() time.Time {
+	now := m.now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+// Client is an xollm.Client whose requests are metered by a Manager.
+type Client struct {
+	client  xollm.Client
+	manager *Manager
+}
+
+// Wrap returns client with every request checked against and counted in
+// the quota of the tenant named by the request's tags. Token usage comes
+// from the provider's response metadata, or a local estimate for clients
+// that don't report it. A failure to count it is logged rather than
+// returned, as the response has already arrived.
+func Wrap(client xollm.Client, manager *Manager) *Client {
+	return &Client{client: client, manager: manager}
+}
+
+// Generate implements xollm.Client.
+func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
+	text, _, err := c.GenerateWithMetadata(ctx, prompt)
+	return text, err
+}
+
+// GenerateWithMetadata implements xollm.MetadataGenerator.
+func (c *Client) GenerateWithMetadata(ctx context.Context, prompt string) (string, *xollm.ResponseMetadata, error) {
+	tenant := c.manager.Tenant(ctx)
+	if err := c.manager.Reserve(ctx, tenant); err != nil {
+		return "", nil, err
+	}
+	text, md, err := xollm.GenerateWithMetadata(ctx, c.client, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	// The response is paid for; failing to count it only lets the tenant
+	// go over its quota
+	if err := c.manager.AddTokens(ctx, tenant, md.Usage.TotalTokens()); err != nil {
+		xollm.Logger(ctx).Warn("failed to count tokens in quota", "tenant", tenant, "tokens", md.Usage.TotalTokens(), "error", err)
+	}
+	return text, md, nil
+}
+
+// ProviderName returns the wrapped client's provider name.
+func (c *Client) ProviderName() string {
+	return c.client.ProviderName()
+}
+
+// Close closes the wrapped client.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,179 @@
+package quota
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient implements xollm.Client and xollm.MetadataGenerator for testing
+type mockClient struct {
+	calls int
+	usage xollm.Usage
+	err   error
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	text, _, err := m.GenerateWithMetadata(ctx, prompt)
+	return text, err
+}
+
+func (m *mockClient) GenerateWithMetadata(ctx context.Context, prompt string) (string, *xollm.ResponseMetadata, error) {
+	m.calls++
+	if m.err != nil {
+		return "", nil, m.err
+	}
+	return "ok", &xollm.ResponseMetadata{Usage: m.usage}, nil
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error { return nil }
+
+func newTestManager(defaults Limits) *Manager {
+	m := New(NewMemoryStore(), defaults)
+	m.now = func() time.Time { return time.Date(2026, 3, 4, 15, 0, 0, 0, time.UTC) }
+	return m
+}
+
+func tenantCtx(tenant string) context.Context {
+	return xollm.WithTags(context.Background(), map[string]string{TenantTag: tenant})
+}
+
+func TestWrap_RequestLimit(t *testing.T) {
+	client := &mockClient{}
+	wrapped := Wrap(client, newTestManager(Limits{RequestsPerDay: 2}))
+	ctx := tenantCtx("acme")
+
+	for i := 0; i < 2; i++ {
+		if _, err := wrapped.Generate(ctx, "hi"); err != nil {
+			t.Fatalf("Request %d failed: %v", i+1, err)
+		}
+	}
+	_, err := wrapped.Generate(ctx, "hi")
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || !errors.Is(err, xollm.ErrRateLimited) {
+		t.Fatalf("Expected ExceededError, got %v", err)
+	}
+	if exceeded.Tenant != "acme" || exceeded.Limit != "requests" || exceeded.Used != 2 || exceeded.Allowed != 2 {
+		t.Errorf("Unexpected error details: %+v", exceeded)
+	}
+	if want := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC); !exceeded.ResetAt.Equal(want) {
+		t.Errorf("Expected reset at %v, got %v", want, exceeded.ResetAt)
+	}
+	if client.calls != 2 {
+		t.Errorf("Expected the rejected request not to be sent, got %d calls", client.calls)
+	}
+
+	// Other tenants have their own quota, and rejections don't count
+	if _, err := wrapped.Generate(tenantCtx("globex"), "hi"); err != nil {
+		t.Errorf("Expected another tenant to be allowed, got %v", err)
+	}
+	if usage, _ := wrapped.manager.Usage(context.Background(), "acme"); usage.Requests != 2 {
+		t.Errorf("Expected 2 counted requests, got %d", usage.Requests)
+	}
+}
+
+func TestWrap_TokenLimit(t *testing.T) {
+	client := &mockClient{usage: xollm.Usage{PromptTokens: 60, CompletionTokens: 40}}
+	manager := newTestManager(Limits{})
+	manager.SetLimits("acme", Limits{TokensPerDay: 150})
+	wrapped := Wrap(client, manager)
+	ctx := tenantCtx("acme")
+
+	// 100 tokens, then 200: the second request overshoots but is allowed
+	for i := 0; i < 2; i++ {
+		if _, err := wrapped.Generate(ctx, "hi"); err != nil {
+			t.Fatalf("Request %d failed: %v", i+1, err)
+		}
+	}
+	_, err := wrapped.Generate(ctx, "hi")
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || exceeded.Limit != "tokens" || exceeded.Used != 200 {
+		t.Errorf("Expected the token quota to be exceeded, got %v", err)
+	}
+
+	// The default limits are unlimited
+	if _, err := wrapped.Generate(tenantCtx("globex"), "hi"); err != nil {
+		t.Errorf("Expected unlimited default, got %v", err)
+	}
+}
+
+func TestWrap_CustomTagAndUntagged(t *testing.T) {
+	manager := newTestManager(Limits{RequestsPerDay: 1})
+	manager.SetTenantTag("user")
+	wrapped := Wrap(&mockClient{}, manager)
+
+	ctx := xollm.WithTags(context.Background(), map[string]string{"user": "u1"})
+	if _, err := wrapped.Generate(ctx, "hi"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if usage, _ := manager.Usage(context.Background(), "u1"); usage.Requests != 1 {
+		t.Errorf("Expected the request to count for u1, got %+v", usage)
+	}
+
+	// Untagged requests share the "" tenant
+	wrapped.Generate(context.Background(), "hi")
+	if _, err := wrapped.Generate(context.Background(), "hi"); !errors.Is(err, xollm.ErrRateLimited) {
+		t.Errorf("Expected untagged requests to share a quota, got %v", err)
+	}
+}
+
+func TestWrap_GenerationError(t *testing.T) {
+	failure := errors.New("boom")
+	wrapped := Wrap(&mockClient{err: failure}, newTestManager(Limits{}))
+	if _, err := wrapped.Generate(tenantCtx("acme"), "hi"); !errors.Is(err, failure) {
+		t.Errorf("Expected the generation error, got %v", err)
+	}
+}
+
+// flakyStore is a Store failing every Add after the first n
+type flakyStore struct {
+	n int
+}
+
+func (s *flakyStore) Add(ctx context.Context, tenant, day string, requests, tokens int) (Usage, error) {
+	if s.n == 0 {
+		return Usage{}, errors.New("redis down")
+	}
+	s.n--
+	return Usage{Requests: requests, Tokens: tokens}, nil
+}
+
+func (s *flakyStore) Get(ctx context.Context, tenant, day string) (Usage, error) {
+	return Usage{}, nil
+}
+
+func TestWrap_AccountingErrorKeepsResponse(t *testing.T) {
+	var out bytes.Buffer
+	ctx := xollm.ContextWithLogger(tenantCtx("acme"), slog.New(slog.NewTextHandler(&out, nil)))
+	wrapped := Wrap(&mockClient{usage: xollm.Usage{PromptTokens: 60, CompletionTokens: 40}}, New(&flakyStore{n: 1}, Limits{TokensPerDay: 1000}))
+	text, err := wrapped.Generate(ctx, "hi")
+	if err != nil || text != "ok" {
+		t.Fatalf("Expected the response despite the store failing, got %q, %v", text, err)
+	}
+	if !strings.Contains(out.String(), "redis down") || !strings.Contains(out.String(), "tenant=acme") {
+		t.Errorf("Expected the accounting error to be logged, got %q", out.String())
+	}
+}
+
+func TestManager_NewDay(t *testing.T) {
+	manager := newTestManager(Limits{RequestsPerDay: 1})
+	ctx := context.Background()
+	if err := manager.Reserve(ctx, "acme"); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	if err := manager.Reserve(ctx, "acme"); err == nil {
+		t.Fatal("Expected the quota to be exhausted")
+	}
+	manager.now = func() time.Time { return time.Date(2026, 3, 5, 0, 0, 1, 0, time.UTC) }
+	if err := manager.Reserve(ctx, "acme"); err != nil {
+		t.Errorf("Expected a fresh quota the next day, got %v", err)
+	}
+}
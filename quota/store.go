@@ -0,0 +1,55 @@
+package quota
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore is a Store keeping counters in memory, for single-process
+// deployments. Counters are lost on restart. Only the most recent day is
+// kept for each tenant.
+type MemoryStore struct {
+	mu   sync.Mutex
+	days map[string]memoryDay
+}
+
+// memoryDay is one tenant's counters for one day.
+type memoryDay struct {
+	day   string
+	usage Usage
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{days: make(map[string]memoryDay)}
+}
+
+// Add implements Store.
+func (s *MemoryStore) Add(ctx context.Context, tenant, day string, requests, tokens int) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.days[tenant]
+	if day < entry.day {
+		// A late update for an earlier day, e.g. the tokens of a request
+		// that finished after midnight; that day is no longer kept
+		return Usage{}, nil
+	}
+	if entry.day != day {
+		// A new day starts from zero
+		entry = memoryDay{day: day}
+	}
+	entry.usage.Requests += requests
+	entry.usage.Tokens += tokens
+	s.days[tenant] = entry
+	return entry.usage, nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(ctx context.Context, tenant, day string) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry := s.days[tenant]; entry.day == day {
+		return entry.usage, nil
+	}
+	return Usage{}, nil
+}
@@ -0,0 +1,47 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	s.Add(ctx, "a", "2026-01-01", 1, 10)
+	usage, _ := s.Add(ctx, "a", "2026-01-01", 1, 5)
+	if usage != (Usage{Requests: 2, Tokens: 15}) {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+	if got, _ := s.Get(ctx, "b", "2026-01-01"); got != (Usage{}) {
+		t.Errorf("Expected other tenants to be separate, got %+v", got)
+	}
+
+	// A new day starts from zero, and late updates for the old day are dropped
+	s.Add(ctx, "a", "2026-01-02", 1, 0)
+	s.Add(ctx, "a", "2026-01-01", 0, 100)
+	if got, _ := s.Get(ctx, "a", "2026-01-02"); got != (Usage{Requests: 1}) {
+		t.Errorf("Expected a fresh day, got %+v", got)
+	}
+	if got, _ := s.Get(ctx, "a", "2026-01-01"); got != (Usage{}) {
+		t.Errorf("Expected the old day to be gone, got %+v", got)
+	}
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	s := NewMemoryStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Add(context.Background(), "a", "d", 1, 2)
+		}()
+	}
+	wg.Wait()
+	if got, _ := s.Get(context.Background(), "a", "d"); got != (Usage{Requests: 50, Tokens: 100}) {
+		t.Errorf("Unexpected usage after concurrent adds: %+v", got)
+	}
+}
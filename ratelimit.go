@@ -0,0 +1,22 @@
+package xollm
+
+import "github.com/xostack/xollm/llm"
+
+// RateLimitState is a provider's rate limit status as reported by its most
+// recent response. See llm.RateLimitState.
+type RateLimitState = llm.RateLimitState
+
+// RateLimitReporter is implemented by clients that track the rate limit
+// headers of their responses. The Gemini and Groq clients implement it.
+type RateLimitReporter = llm.RateLimitReporter
+
+// RateLimit returns the latest rate limit state reported to client, so
+// schedulers can pace requests before the provider starts rejecting them.
+// ok is false if the client doesn't implement RateLimitReporter or no
+// response has reported a rate limit yet.
+func RateLimit(client Client) (state RateLimitState, ok bool) {
+	if r, ok := client.(RateLimitReporter); ok {
+		return r.RateLimitState()
+	}
+	return RateLimitState{}, false
+}
@@ -0,0 +1,184 @@
+// Package ratelimit paces requests to a provider with token buckets, so an
+// application stays under the provider's requests-per-minute and
+// tokens-per-minute quota instead of running into 429 responses.
+//
+// A Limiter holds the limits and a Store holds the buckets. MemoryStore
+// limits a single process; RedisStore keeps the buckets in Redis, so every
+// replica of a service draws from the same buckets and the replicas
+// collectively respect one quota instead of each limiting independently.
+//
+//	store := ratelimit.NewRedisStore(ratelimit.EvalFunc(func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return rdb.Eval(ctx, script, keys, args...).Result()
+//	}))
+//	limiter := ratelimit.New(store, "groq", ratelimit.Limits{RequestsPerMinute: 30, TokensPerMinute: 6000})
+//	client = ratelimit.Wrap(client, limiter)
+//
+// Requests wait for their turn rather than fail. Buckets may go into debt,
+// so waiting requests are served in the order they reserved, across all
+// replicas. A request whose wait would outlast its context's deadline fails
+// at once with an error matching xollm.ErrRateLimited.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// Limits are a provider quota. Zero means unlimited. Each limit is a bucket
+// holding one minute's allowance, so up to a minute's worth of requests can
+// be sent in a burst after a quiet period.
+type Limits struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// Store keeps token buckets. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	// Take takes n tokens, which may be negative to return tokens, from
+	// the bucket named key, which refills at rate tokens per second up to
+	// burst tokens and starts full. The bucket may go into debt; Take
+	// returns how long the caller must wait until the debt is repaid and
+	// its tokens are available. The update must be atomic so that
+	// concurrent callers can't take the same tokens.
+	Take(ctx context.Context, key string, n, rate, burst float64) (time.Duration, error)
+}
+
+// Limiter paces requests sharing one quota. It is safe for concurrent use.
+type Limiter struct {
+	store  Store
+	key    string
+	limits Limits
+}
+
+// New returns a limiter applying limits to the buckets named key in store.
+// Limiters in different processes with the same key and a shared store
+// share the quota.
+func New(store Store, key string, limits Limits) *Limiter {
+	return &Limiter{store: store, key: key, limits: limits}
+}
+
+// Limits returns the limiter's limits.
+func (l *Limiter) Limits() Limits {
+	return l.limits
+}
+
+// Wait blocks until a request of tokens tokens fits in the quota, and takes
+// it from the quota. It fails with an error matching xollm.ErrRateLimited
+// if the wait would outlast ctx's deadline, or with ctx's error if ctx is
+// done while waiting; either way the reservation is returned.
+func (l *Limiter) Wait(ctx context.Context, tokens int) error {
+	requestWait, err := l.take(ctx, "requests", 1, l.limits.RequestsPerMinute)
+	if err != nil {
+		return err
+	}
+	tokenWait, err := l.take(ctx, "tokens", tokens, l.limits.TokensPerMinute)
+	if err != nil {
+		l.refund(ctx, 1, 0)
+		return err
+	}
+	wait := max(requestWait, tokenWait)
+	if wait <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		l.refund(ctx, 1, tokens)
+		return fmt.Errorf("rate limit %q requires waiting %v, beyond the request deadline: %w", l.key, wait, xollm.ErrRateLimited)
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.refund(ctx, 1, tokens)
+		return ctx.Err()
+	}
+}
+
+// AddTokens takes tokens from the quota without waiting, e.g. for the
+// completion tokens of a request, which are only known once it completes.
+// Negative tokens return tokens taken for an overestimate.
+func (l *Limiter) AddTokens(ctx context.Context, tokens int) error {
+	if tokens == 0 {
+		return nil
+	}
+	_, err := l.take(ctx, "tokens", tokens, l.limits.TokensPerMinute)
+	return err
+}
+
+// take takes n tokens from the limiter's bucket named by suffix, for a
+// limit of perMinute tokens a minute.
+func (l *Limiter) take(ctx context.Context, suffix string, n, perMinute int) (time.Duration, error) {
+	if perMinute <= 0 || n == 0 {
+		return 0, nil
+	}
+	wait, err := l.store.Take(ctx, l.key+":"+suffix, float64(n), float64(perMinute)/60, float64(perMinute))
+	if err != nil {
+		return 0, fmt.Errorf("failed to update rate limit: %w", err)
+	}
+	return wait, nil
+}
+
+// refund returns a reservation the caller gave up on. Errors are ignored:
+// the tokens are lost, which only slows later requests down.
+func (l *Limiter) refund(ctx context.Context, requests, tokens int) {
+	ctx = context.WithoutCancel(ctx)
+	l.take(ctx, "requests", -requests, l.limits.RequestsPerMinute)
+	l.take(ctx, "tokens", -tokens, l.limits.TokensPerMinute)
+}
+
+// Client is an xollm.Client whose requests are paced by a Limiter.
+type Client struct {
+	client  xollm.Client
+	limiter *Limiter
+}
+
+// Wrap returns client with every request waiting for its turn in limiter's
+// quota. A request reserves its prompt tokens up front, as estimated by
+// xollm.EstimateTokensFor; once it completes, the difference to the tokens
+// the provider reports is taken from the quota as well. A failure to take
+// it is logged rather than returned, as the response has already arrived.
+func Wrap(client xollm.Client, limiter *Limiter) *Client {
+	return &Client{client: client, limiter: limiter}
+}
+
+// Generate implements xollm.Client.
+func (c *Client) Generate(ctx context.Context, prompt string) (string, error) {
+	text, _, err := c.GenerateWithMetadata(ctx, prompt)
+	return text, err
+}
+
+// GenerateWithMetadata implements xollm.MetadataGenerator.
+func (c *Client) GenerateWithMetadata(ctx context.Context, prompt string) (string, *xollm.ResponseMetadata, error) {
+	estimate := xollm.EstimateTokensFor(c.client, prompt)
+	if err := c.limiter.Wait(ctx, estimate); err != nil {
+		return "", nil, err
+	}
+	text, md, err := xollm.GenerateWithMetadata(ctx, c.client, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	if used := md.Usage.TotalTokens(); used > 0 {
+		// The response is paid for; failing to count it only lets later
+		// requests through sooner
+		if err := c.limiter.AddTokens(ctx, used-estimate); err != nil {
+			xollm.Logger(ctx).Warn("failed to count reported tokens", "provider", c.client.ProviderName(), "tokens", used, "error", err)
+		}
+	}
+	return text, md, nil
+}
+
+// ProviderName returns the wrapped client's provider name.
+func (c *Client) ProviderName() string {
+	return c.client.ProviderName()
+}
+
+// Close closes the wrapped client.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,186 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient implements xollm.Client and xollm.MetadataGenerator for testing
+type mockClient struct {
+	usage xollm.Usage
+	err   error
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	text, _, err := m.GenerateWithMetadata(ctx, prompt)
+	return text, err
+}
+
+func (m *mockClient) GenerateWithMetadata(ctx context.Context, prompt string) (string, *xollm.ResponseMetadata, error) {
+	if m.err != nil {
+		return "", nil, m.err
+	}
+	return "ok", &xollm.ResponseMetadata{Usage: m.usage}, nil
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error { return nil }
+
+// recordingStore is a Store recording the tokens taken from each bucket
+type recordingStore struct {
+	mu    sync.Mutex
+	taken map[string]float64
+	wait  time.Duration
+	err   error
+}
+
+func (s *recordingStore) Take(ctx context.Context, key string, n, rate, burst float64) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.taken == nil {
+		s.taken = make(map[string]float64)
+	}
+	s.taken[key] += n
+	return s.wait, nil
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	// 60 requests a minute refill one request a second
+	limiter := New(NewMemoryStore(), "groq", Limits{RequestsPerMinute: 60})
+	ctx := context.Background()
+	for i := 0; i < 60; i++ {
+		if err := limiter.Wait(ctx, 0); err != nil {
+			t.Fatalf("Request %d failed: %v", i+1, err)
+		}
+	}
+
+	start := time.Now()
+	shortCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(shortCtx, 0)
+	if !errors.Is(err, xollm.ErrRateLimited) || !strings.Contains(err.Error(), `"groq"`) {
+		t.Fatalf("Expected a rate limit error, got: %v", err)
+	}
+	if time.Since(start) > 50*time.Millisecond {
+		t.Error("Expected a wait beyond the deadline to fail at once")
+	}
+}
+
+func TestLimiter_SharedStore(t *testing.T) {
+	// Two replicas sharing a store share one quota
+	store := NewMemoryStore()
+	a := New(store, "groq", Limits{RequestsPerMinute: 60})
+	b := New(store, "groq", Limits{RequestsPerMinute: 60})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 30; i++ {
+		if err := a.Wait(ctx, 0); err != nil {
+			t.Fatalf("Replica a request %d failed: %v", i+1, err)
+		}
+		if err := b.Wait(ctx, 0); err != nil {
+			t.Fatalf("Replica b request %d failed: %v", i+1, err)
+		}
+	}
+	if err := b.Wait(ctx, 0); !errors.Is(err, xollm.ErrRateLimited) {
+		t.Errorf("Expected the shared quota to be used up, got: %v", err)
+	}
+}
+
+func TestLimiter_WaitsAndRefundsOnCancel(t *testing.T) {
+	store := &recordingStore{wait: time.Hour}
+	limiter := New(store, "k", Limits{RequestsPerMinute: 10, TokensPerMinute: 100})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := limiter.Wait(ctx, 25); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancellation, got: %v", err)
+	}
+	if store.taken["k:requests"] != 0 || store.taken["k:tokens"] != 0 {
+		t.Errorf("Expected the reservation to be returned, got %v", store.taken)
+	}
+
+	store.wait = 20 * time.Millisecond
+	start := time.Now()
+	if err := limiter.Wait(context.Background(), 25); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("Expected Wait to wait for the store's delay")
+	}
+	if store.taken["k:requests"] != 1 || store.taken["k:tokens"] != 25 {
+		t.Errorf("Expected the request to be taken, got %v", store.taken)
+	}
+}
+
+func TestLimiter_StoreError(t *testing.T) {
+	limiter := New(&recordingStore{err: errors.New("redis down")}, "k", Limits{RequestsPerMinute: 10})
+	if err := limiter.Wait(context.Background(), 1); err == nil || !strings.Contains(err.Error(), "redis down") {
+		t.Errorf("Expected the store error, got: %v", err)
+	}
+	// Unlimited dimensions never touch the store
+	if err := New(&recordingStore{err: errors.New("redis down")}, "k", Limits{}).Wait(context.Background(), 1); err != nil {
+		t.Errorf("Expected an unlimited limiter to pass, got: %v", err)
+	}
+}
+
+func TestWrap_CountsReportedTokens(t *testing.T) {
+	store := &recordingStore{}
+	client := Wrap(&mockClient{usage: xollm.Usage{PromptTokens: 40, CompletionTokens: 60}}, New(store, "k", Limits{TokensPerMinute: 1000}))
+	if _, err := client.Generate(context.Background(), strings.Repeat("word ", 10)); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if store.taken["k:tokens"] != 100 {
+		t.Errorf("Expected the reported 100 tokens to be counted, got %v", store.taken["k:tokens"])
+	}
+	if client.ProviderName() != "mock" {
+		t.Errorf("Expected the wrapped provider name, got %q", client.ProviderName())
+	}
+}
+
+// flakyStore is a Store failing every Take after the first n
+type flakyStore struct {
+	n int
+}
+
+func (s *flakyStore) Take(ctx context.Context, key string, n, rate, burst float64) (time.Duration, error) {
+	if s.n == 0 {
+		return 0, errors.New("redis down")
+	}
+	s.n--
+	return 0, nil
+}
+
+func TestWrap_AccountingErrorKeepsResponse(t *testing.T) {
+	var out bytes.Buffer
+	ctx := xollm.ContextWithLogger(context.Background(), slog.New(slog.NewTextHandler(&out, nil)))
+	client := Wrap(&mockClient{usage: xollm.Usage{PromptTokens: 40, CompletionTokens: 60}}, New(&flakyStore{n: 1}, "k", Limits{TokensPerMinute: 1000}))
+	text, err := client.Generate(ctx, "hi")
+	if err != nil || text != "ok" {
+		t.Fatalf("Expected the response despite the store failing, got %q, %v", text, err)
+	}
+	if !strings.Contains(out.String(), "redis down") {
+		t.Errorf("Expected the accounting error to be logged, got %q", out.String())
+	}
+}
+
+func TestWrap_GenerationError(t *testing.T) {
+	genErr := errors.New("boom")
+	client := Wrap(&mockClient{err: genErr}, New(NewMemoryStore(), "k", Limits{RequestsPerMinute: 10}))
+	if _, err := client.Generate(context.Background(), "hi"); !errors.Is(err, genErr) {
+		t.Errorf("Expected the generation error, got: %v", err)
+	}
+}
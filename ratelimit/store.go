@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store keeping buckets in memory, for single-process
+// deployments.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+}
+
+// memoryBucket is a bucket's level when it was last updated.
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{now: time.Now, buckets: make(map[string]memoryBucket)}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(ctx context.Context, key string, n, rate, burst float64) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = memoryBucket{tokens: burst, updated: now}
+	}
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*rate)
+	}
+	b.tokens = math.Min(burst, b.tokens-n)
+	b.updated = now
+	s.buckets[key] = b
+	return debtWait(b.tokens, rate), nil
+}
+
+// debtWait returns how long a bucket at tokens, refilling at rate tokens
+// per second, takes to get out of debt.
+func debtWait(tokens, rate float64) time.Duration {
+	if tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tokens / rate * float64(time.Second))
+}
+
+// Evaler runs a Lua script on a Redis server, like EVAL. Keys are passed as
+// KEYS and args as ARGV.
+type Evaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// EvalFunc adapts a function to Evaler, e.g. to run scripts with a
+// go-redis client:
+//
+//	ratelimit.EvalFunc(func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return rdb.Eval(ctx, script, keys, args...).Result()
+//	})
+type EvalFunc func(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
+// Eval calls f.
+func (f EvalFunc) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	return f(ctx, script, keys, args...)
+}
+
+// RedisKeyPrefix is the default prefix of the keys of a RedisStore.
+const RedisKeyPrefix = "xollm:ratelimit:"
+
+// redisTakeScript is Take as a Lua script, so the update is atomic on the
+// server. It uses the server's clock, so replicas with skewed clocks still
+// agree on how far buckets have refilled. Reading TIME makes the script
+// non-deterministic, so it switches to effects replication first, which
+// Redis before 5 requires for the writes that follow; from Redis 5 on it
+// is the default and the call does nothing. The wait is returned as a
+// string because Redis truncates Lua numbers to integers.
+const redisTakeScript = `
+redis.replicate_commands()
+local rate = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+if now > updated then
+	tokens = math.min(burst, tokens + (now - updated) * rate)
+end
+tokens = math.min(burst, tokens - tonumber(ARGV[1]))
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+if tokens >= 0 then
+	return '0'
+end
+return tostring(-tokens / rate)
+`
+
+// RedisStore is a Store keeping buckets in Redis, so that every process
+// using the same server and key shares the buckets. Each Take is a single
+// script call, atomic on the server. Buckets expire once they have refilled.
+type RedisStore struct {
+	redis  Evaler
+	prefix string
+}
+
+// NewRedisStore returns a store running its scripts with redis, under keys
+// starting with RedisKeyPrefix. The script needs Redis 4 or later.
+func NewRedisStore(redis Evaler) *RedisStore {
+	return &RedisStore{redis: redis, prefix: RedisKeyPrefix}
+}
+
+// SetKeyPrefix changes the prefix of the store's keys, e.g. to separate
+// environments sharing a server. Set it before the store is used.
+func (s *RedisStore) SetKeyPrefix(prefix string) {
+	s.prefix = prefix
+}
+
+// Take implements Store.
+func (s *RedisStore) Take(ctx context.Context, key string, n, rate, burst float64) (time.Duration, error) {
+	result, err := s.redis.Eval(ctx, redisTakeScript, []string{s.prefix + key}, n, rate, burst)
+	if err != nil {
+		return 0, fmt.Errorf("redis: %w", err)
+	}
+	text, ok := result.(string)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected script result %v (%T)", result, result)
+	}
+	seconds, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("redis: unexpected script result %q", text)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// A new bucket starts full
+	if wait, _ := store.Take(ctx, "k", 10, 1, 10); wait != 0 {
+		t.Errorf("Expected no wait from a full bucket, got %v", wait)
+	}
+	// Going into debt means waiting for the refill
+	if wait, _ := store.Take(ctx, "k", 2, 1, 10); wait != 2*time.Second {
+		t.Errorf("Expected a 2s wait, got %v", wait)
+	}
+	now = now.Add(5 * time.Second)
+	if wait, _ := store.Take(ctx, "k", 3, 1, 10); wait != 0 {
+		t.Errorf("Expected the refill to cover the request, got %v", wait)
+	}
+	// Returned tokens never overfill the bucket
+	now = now.Add(time.Hour)
+	store.Take(ctx, "k", -5, 1, 10)
+	if wait, _ := store.Take(ctx, "k", 11, 1, 10); wait != time.Second {
+		t.Errorf("Expected the bucket to be capped at its burst, got a wait of %v", wait)
+	}
+	// Buckets are independent
+	if wait, _ := store.Take(ctx, "other", 1, 1, 10); wait != 0 {
+		t.Errorf("Expected a separate bucket, got a wait of %v", wait)
+	}
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	immediate := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait, _ := store.Take(context.Background(), "k", 1, 1, 20)
+			if wait == 0 {
+				mu.Lock()
+				immediate++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if immediate != 20 {
+		t.Errorf("Expected exactly 20 takes without a wait, got %d", immediate)
+	}
+	if wait, _ := store.Take(context.Background(), "k", 0, 1, 20); wait != 80*time.Second {
+		t.Errorf("Expected 80 tokens of debt, got a wait of %v", wait)
+	}
+}
+
+type fakeRedis struct {
+	script string
+	keys   []string
+	args   []any
+	result any
+	err    error
+}
+
+func (f *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	f.script, f.keys, f.args = script, keys, args
+	return f.result, f.err
+}
+
+func TestRedisStore(t *testing.T) {
+	redis := &fakeRedis{result: "1.5"}
+	store := NewRedisStore(redis)
+	wait, err := store.Take(context.Background(), "groq:tokens", 3, 0.5, 30)
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if wait != 1500*time.Millisecond {
+		t.Errorf("Expected a 1.5s wait, got %v", wait)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(redis.script), "redis.replicate_commands()") {
+		t.Errorf("Expected the script to switch to effects replication before its writes")
+	}
+	if len(redis.keys) != 1 || redis.keys[0] != "xollm:ratelimit:groq:tokens" {
+		t.Errorf("Unexpected keys: %v", redis.keys)
+	}
+	if len(redis.args) != 3 || redis.args[0] != 3.0 || redis.args[1] != 0.5 || redis.args[2] != 30.0 {
+		t.Errorf("Unexpected args: %v", redis.args)
+	}
+
+	store.SetKeyPrefix("staging:")
+	redis.result = "0"
+	if wait, err := store.Take(context.Background(), "k", 1, 1, 1); err != nil || wait != 0 || redis.keys[0] != "staging:k" {
+		t.Errorf("Unexpected result %v, %v for key %v", wait, err, redis.keys)
+	}
+}
+
+func TestRedisStore_Errors(t *testing.T) {
+	connErr := errors.New("connection refused")
+	if _, err := NewRedisStore(&fakeRedis{err: connErr}).Take(context.Background(), "k", 1, 1, 1); !errors.Is(err, connErr) {
+		t.Errorf("Expected the connection error, got: %v", err)
+	}
+	if _, err := NewRedisStore(&fakeRedis{result: int64(0)}).Take(context.Background(), "k", 1, 1, 1); err == nil {
+		t.Error("Expected an error for a non-string result")
+	}
+	evalFunc := EvalFunc(func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+		return "nope", nil
+	})
+	if _, err := NewRedisStore(evalFunc).Take(context.Background(), "k", 1, 1, 1); err == nil {
+		t.Error("Expected an error for a malformed result")
+	}
+}
@@ -0,0 +1,23 @@
+package xollm
+
+import "testing"
+
+// rateLimitClient adds RateLimitState to stubClient
+type rateLimitClient struct {
+	stubClient
+	state RateLimitState
+}
+
+func (c *rateLimitClient) RateLimitState() (RateLimitState, bool) {
+	return c.state, true
+}
+
+func TestRateLimit(t *testing.T) {
+	client := &rateLimitClient{state: RateLimitState{RemainingRequests: 3}}
+	if state, ok := RateLimit(client); !ok || state.RemainingRequests != 3 {
+		t.Errorf("Expected the client's state, got %+v, %v", state, ok)
+	}
+	if _, ok := RateLimit(&stubClient{}); ok {
+		t.Error("Expected no state for a client without rate limit tracking")
+	}
+}
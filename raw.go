@@ -0,0 +1,24 @@
+package xollm
+
+import (
+	"context"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// RawResponse holds the undecoded HTTP response of a provider request. See
+// llm.RawResponse.
+type RawResponse = llm.RawResponse
+
+// WithRawResponse returns a copy of ctx whose provider requests capture
+// their undecoded response in the returned RawResponse, for reading fields
+// xollm doesn't model yet without forking a provider package. Every bundled
+// provider supports it; the capture stays empty for other clients.
+//
+//	ctx, raw := xollm.WithRawResponse(ctx)
+//	text, md, err := xollm.GenerateWithMetadata(ctx, client, prompt)
+//	var extra struct{ SystemFingerprint string `json:"system_fingerprint"` }
+//	err = json.Unmarshal(raw.Body(), &extra)
+func WithRawResponse(ctx context.Context) (context.Context, *RawResponse) {
+	return llm.WithRawResponse(ctx)
+}
@@ -0,0 +1,89 @@
+package replay
+
+import "strings"
+
+// maxDiffCells bounds the size of the LCS table, so very long responses
+// are compared with a coarse diff instead of exhausting memory.
+const maxDiffCells = 4 << 20
+
+// lcsTable returns the table of longest common subsequence lengths of the
+// suffixes of a and b, or nil if it would exceed maxDiffCells.
+func lcsTable(a, b []string) [][]int {
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		return nil
+	}
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table
+}
+
+// similarity returns 2*LCS/(len(a)+len(b)): 1 for identical word lists and
+// 0 for lists without common words. Lists too long to compare exactly
+// count the words at the same positions.
+func similarity(a, b []string) float64 {
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	common := 0
+	if table := lcsTable(a, b); table != nil {
+		common = table[0][0]
+	} else {
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if a[i] == b[i] {
+				common++
+			}
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+// diffLines returns a line diff from before to after, with removed lines
+// prefixed "- ", added lines "+ " and unchanged lines "  ".
+func diffLines(before, after string) string {
+	a, b := strings.Split(before, "\n"), strings.Split(after, "\n")
+	var out strings.Builder
+	table := lcsTable(a, b)
+	if table == nil {
+		for _, l := range a {
+			out.WriteString("- " + l + "\n")
+		}
+		for _, l := range b {
+			out.WriteString("+ " + l + "\n")
+		}
+		return out.String()
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out.WriteString("- " + a[i] + "\n")
+	}
+	for ; j < len(b); j++ {
+		out.WriteString("+ " + b[j] + "\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,39 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"a b c", "a b c", 1},
+		{"a b c", "x y z", 0},
+		{"a b c d", "a c", 2 * 2.0 / 6},
+	}
+	for _, tt := range tests {
+		if got := similarity(strings.Fields(tt.a), strings.Fields(tt.b)); got != tt.want {
+			t.Errorf("similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc", "a\nc\nd")
+	want := "  a\n- b\n  c\n+ d\n"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestDiffLines_TooLong(t *testing.T) {
+	long := strings.Repeat("x\n", 3000)
+	got := diffLines(long+"a", long+"b")
+	if !strings.HasSuffix(got, "+ b\n") || !strings.Contains(got, "- a\n") {
+		t.Errorf("Expected a coarse diff for long responses")
+	}
+}
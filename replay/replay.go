@@ -0,0 +1,212 @@
+// Package replay re-executes recorded prompts and reports how the
+// responses changed.
+//
+// Recordings are the JSONL files written by the dataset package. Each
+// prompt is sent again, either to the provider that originally answered
+// it or to another one, and the new response is compared with the
+// recorded one. The Report lists a similarity score and a line diff per
+// prompt, which makes it a quick way to evaluate a provider or model
+// migration:
+//
+//	examples, err := dataset.ReadFile("traffic.jsonl")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	report := replay.Run(ctx, examples, replay.To(candidateClient), replay.Options{Concurrency: 4})
+//	report.WriteText(os.Stdout)
+//
+// Only the prompts are replayed: system prompts and sampling settings are
+// those of the target clients.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xostack/xollm"
+	"github.com/xostack/xollm/dataset"
+)
+
+// Target returns the client an example is replayed against.
+type Target func(ex dataset.Example) (xollm.Client, error)
+
+// To replays every example against client.
+func To(client xollm.Client) Target {
+	return func(dataset.Example) (xollm.Client, error) {
+		return client, nil
+	}
+}
+
+// Original replays each example against the pool's client for the provider
+// that originally answered it, and examples without a recorded provider
+// against the pool's default.
+func Original(pool *xollm.Pool) Target {
+	return func(ex dataset.Example) (xollm.Client, error) {
+		if ex.Provider == "" {
+			return pool.Default()
+		}
+		return pool.Get(ex.Provider)
+	}
+}
+
+// Options configure a replay.
+type Options struct {
+	// Concurrency is the number of prompts replayed at once. If <= 0,
+	// prompts are replayed one at a time.
+	Concurrency int
+	// Normalize, if set, is applied to both responses before they are
+	// compared, e.g. to ignore case or whitespace differences.
+	Normalize func(string) string
+}
+
+// Entry is the outcome of replaying one example.
+type Entry struct {
+	// Example is the recorded example.
+	Example dataset.Example
+	// Provider is the provider the example was replayed against.
+	Provider string
+	// Response is the new response, empty if the replay failed.
+	Response string
+	// Error is the replay's failure, if any.
+	Error error
+	// Duration is how long the new response took.
+	Duration time.Duration
+	// Similarity is the share of words the two responses have in common,
+	// in order, from 0 to 1.
+	Similarity float64
+	// Diff is a line diff from the recorded to the new response, empty if
+	// they are identical.
+	Diff string
+}
+
+// Identical reports whether the new response equals the recorded one,
+// after Options.Normalize.
+func (e Entry) Identical() bool {
+	return e.Error == nil && e.Diff == ""
+}
+
+// Report is the outcome of a replay.
+type Report struct {
+	Entries []Entry
+}
+
+// Summary counts the outcomes of a replay.
+type Summary struct {
+	Total     int
+	Identical int
+	Changed   int
+	Failed    int
+	// MeanSimilarity is the mean similarity of the successful replays.
+	MeanSimilarity float64
+}
+
+// Summary returns the counts of the report's outcomes.
+func (r *Report) Summary() Summary {
+	s := Summary{Total: len(r.Entries)}
+	var similarity float64
+	for _, e := range r.Entries {
+		switch {
+		case e.Error != nil:
+			s.Failed++
+			continue
+		case e.Identical():
+			s.Identical++
+		default:
+			s.Changed++
+		}
+		similarity += e.Similarity
+	}
+	if n := s.Identical + s.Changed; n > 0 {
+		s.MeanSimilarity = similarity / float64(n)
+	}
+	return s
+}
+
+// Run replays examples against the clients target returns and compares the
+// responses. Failures are recorded in the entries, in example order.
+func Run(ctx context.Context, examples []dataset.Example, target Target, opts Options) *Report {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	normalize := opts.Normalize
+	if normalize == nil {
+		normalize = func(s string) string { return s }
+	}
+
+	entries := make([]Entry, len(examples))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, ex := range examples {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, ex dataset.Example) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			entries[i] = replayOne(ctx, ex, target, normalize)
+		}(i, ex)
+	}
+	wg.Wait()
+	return &Report{Entries: entries}
+}
+
+// replayOne replays a single example.
+func replayOne(ctx context.Context, ex dataset.Example, target Target, normalize func(string) string) Entry {
+	entry := Entry{Example: ex}
+	client, err := target(ex)
+	if err != nil {
+		entry.Error = fmt.Errorf("no client to replay against: %w", err)
+		return entry
+	}
+	entry.Provider = client.ProviderName()
+
+	start := time.Now()
+	entry.Response, entry.Error = client.Generate(ctx, ex.Prompt)
+	entry.Duration = time.Since(start)
+	if entry.Error != nil {
+		return entry
+	}
+
+	before, after := normalize(ex.Response), normalize(entry.Response)
+	entry.Similarity = similarity(strings.Fields(before), strings.Fields(after))
+	if before != after {
+		entry.Diff = diffLines(before, after)
+	}
+	return entry
+}
+
+// WriteText writes a human-readable report: a line per example with its
+// outcome, the diff of each changed response, and the summary.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for i, e := range r.Entries {
+		prompt := truncate(e.Example.Prompt, 60)
+		switch {
+		case e.Error != nil:
+			fmt.Fprintf(&b, "#%d FAILED %q: %v\n", i+1, prompt, e.Error)
+		case e.Identical():
+			fmt.Fprintf(&b, "#%d identical %q (%s, %v)\n", i+1, prompt, e.Provider, e.Duration.Round(time.Millisecond))
+		default:
+			fmt.Fprintf(&b, "#%d changed %q (%s, %v, similarity %.2f)\n", i+1, prompt, e.Provider, e.Duration.Round(time.Millisecond), e.Similarity)
+			b.WriteString(e.Diff)
+		}
+	}
+	s := r.Summary()
+	fmt.Fprintf(&b, "\n%d replayed: %d identical, %d changed, %d failed; mean similarity %.2f\n",
+		s.Total, s.Identical, s.Changed, s.Failed, s.MeanSimilarity)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// truncate shortens s to at most n runes on one line.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,116 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/xostack/xollm"
+	"github.com/xostack/xollm/config"
+	"github.com/xostack/xollm/dataset"
+)
+
+// mockClient answers prompts from a map
+type mockClient struct {
+	name      string
+	responses map[string]string
+	mu        sync.Mutex
+	calls     int
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	response, ok := m.responses[prompt]
+	if !ok {
+		return "", errors.New("unknown prompt")
+	}
+	return response, nil
+}
+
+func (m *mockClient) ProviderName() string { return m.name }
+
+func (m *mockClient) Close() error { return nil }
+
+var recorded = []dataset.Example{
+	{Prompt: "same", Response: "Paris"},
+	{Prompt: "changed", Response: "line one\nline two"},
+	{Prompt: "missing", Response: "x"},
+}
+
+func TestRun(t *testing.T) {
+	client := &mockClient{name: "groq", responses: map[string]string{
+		"same":    "Paris",
+		"changed": "line one\nline 2",
+	}}
+	report := Run(context.Background(), recorded, To(client), Options{Concurrency: 2})
+
+	if len(report.Entries) != 3 || client.calls != 3 {
+		t.Fatalf("Expected 3 entries and calls, got %d and %d", len(report.Entries), client.calls)
+	}
+	same, changed, missing := report.Entries[0], report.Entries[1], report.Entries[2]
+	if !same.Identical() || same.Similarity != 1 || same.Provider != "groq" {
+		t.Errorf("Expected an identical entry, got %+v", same)
+	}
+	if changed.Identical() || changed.Diff != "  line one\n- line two\n+ line 2\n" {
+		t.Errorf("Unexpected diff: %q", changed.Diff)
+	}
+	if changed.Similarity != 0.75 {
+		t.Errorf("Expected similarity 0.75, got %v", changed.Similarity)
+	}
+	if missing.Error == nil || missing.Identical() {
+		t.Errorf("Expected a failed entry, got %+v", missing)
+	}
+
+	s := report.Summary()
+	if s.Total != 3 || s.Identical != 1 || s.Changed != 1 || s.Failed != 1 || s.MeanSimilarity != 0.875 {
+		t.Errorf("Unexpected summary: %+v", s)
+	}
+}
+
+func TestRun_Normalize(t *testing.T) {
+	client := &mockClient{responses: map[string]string{"same": "  PARIS "}}
+	report := Run(context.Background(), recorded[:1], To(client), Options{
+		Normalize: func(s string) string { return strings.ToLower(strings.TrimSpace(s)) },
+	})
+	if !report.Entries[0].Identical() {
+		t.Errorf("Expected normalized responses to be identical, got %+v", report.Entries[0])
+	}
+}
+
+func TestOriginal(t *testing.T) {
+	original := xollm.GetClient
+	defer func() { xollm.GetClient = original }()
+	xollm.GetClient = func(cfg config.Config, debugMode bool) (xollm.Client, error) {
+		return &mockClient{name: cfg.DefaultProvider, responses: map[string]string{"same": "Paris"}}, nil
+	}
+	pool := xollm.NewPool(config.NewConfig("ollama", 30, map[string]config.LLMConfig{"ollama": {}, "groq": {}}), false)
+
+	examples := []dataset.Example{
+		{Prompt: "same", Response: "Paris", Provider: "groq"},
+		{Prompt: "same", Response: "Paris"},
+	}
+	report := Run(context.Background(), examples, Original(pool), Options{})
+	if report.Entries[0].Provider != "groq" || report.Entries[1].Provider != "ollama" {
+		t.Errorf("Expected the recorded and default providers, got %q and %q", report.Entries[0].Provider, report.Entries[1].Provider)
+	}
+}
+
+func TestReport_WriteText(t *testing.T) {
+	client := &mockClient{name: "groq", responses: map[string]string{"same": "Paris", "changed": "line one"}}
+	report := Run(context.Background(), recorded, To(client), Options{})
+
+	var b strings.Builder
+	if err := report.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{`#1 identical "same"`, `#2 changed "changed"`, "- line two\n", `#3 FAILED "missing": unknown prompt`, "3 replayed: 1 identical, 1 changed, 1 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package xollm
+
+import "sync"
+
+// RetryBudget caps the retries a batch of requests makes in total, so a
+// pathological failure, such as a provider timing out on every request,
+// costs at most the budget instead of multiplying the batch's cost by the
+// per-request attempt limit. It is safe for concurrent use and is shared by
+// all workers of a batch.
+//
+//	budget := xollm.NewRetryBudget(50, 100_000)
+//	for {
+//		text, err = client.Generate(ctx, prompt)
+//		if err == nil || !xollm.IsRetryable(err) || !budget.Allow(xollm.EstimateTokensFor(client, prompt)) {
+//			break
+//		}
+//	}
+//
+// A nil *RetryBudget allows every retry.
+type RetryBudget struct {
+	mu    sync.Mutex
+	stats RetryBudgetStats
+}
+
+// RetryBudgetStats reports a retry budget's limits and what has been spent.
+type RetryBudgetStats struct {
+	// MaxRetries and MaxTokens are the budget's limits, 0 if unlimited.
+	MaxRetries int
+	MaxTokens  int
+	// Retries is the number of retries allowed so far.
+	Retries int
+	// Tokens is the number of prompt tokens the allowed retries resent.
+	Tokens int
+	// Denied is the number of retries refused because the budget was
+	// spent.
+	Denied int
+}
+
+// Exhausted reports whether the budget has refused any retry.
+func (s RetryBudgetStats) Exhausted() bool {
+	return s.Denied > 0
+}
+
+// NewRetryBudget returns a budget allowing at most maxRetries retries that
+// resend at most maxTokens prompt tokens in total. A limit <= 0 leaves that
+// dimension unlimited.
+func NewRetryBudget(maxRetries, maxTokens int) *RetryBudget {
+	return &RetryBudget{stats: RetryBudgetStats{MaxRetries: max(maxRetries, 0), MaxTokens: max(maxTokens, 0)}}
+}
+
+// Allow reports whether a retry resending tokens prompt tokens fits in the
+// budget, and if so spends it. A refused retry is counted in Denied.
+func (b *RetryBudget) Allow(tokens int) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := &b.stats
+	if (s.MaxRetries > 0 && s.Retries+1 > s.MaxRetries) || (s.MaxTokens > 0 && s.Tokens+tokens > s.MaxTokens) {
+		s.Denied++
+		return false
+	}
+	s.Retries++
+	s.Tokens += tokens
+	return true
+}
+
+// Stats returns the budget's limits and spending so far.
+func (b *RetryBudget) Stats() RetryBudgetStats {
+	if b == nil {
+		return RetryBudgetStats{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
@@ -0,0 +1,78 @@
+package xollm
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRetryBudget_MaxRetries(t *testing.T) {
+	budget := NewRetryBudget(2, 0)
+	for i := 0; i < 2; i++ {
+		if !budget.Allow(100) {
+			t.Fatalf("Expected retry %d to be allowed", i+1)
+		}
+	}
+	if budget.Allow(1) {
+		t.Error("Expected the third retry to be denied")
+	}
+
+	stats := budget.Stats()
+	if stats.Retries != 2 || stats.Tokens != 200 || stats.Denied != 1 || !stats.Exhausted() {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestRetryBudget_MaxTokens(t *testing.T) {
+	budget := NewRetryBudget(0, 250)
+	if !budget.Allow(200) {
+		t.Fatal("Expected the first retry to be allowed")
+	}
+	if budget.Allow(100) {
+		t.Error("Expected a retry over the token limit to be denied")
+	}
+	if !budget.Allow(50) {
+		t.Error("Expected a retry within the remaining tokens to be allowed")
+	}
+	if stats := budget.Stats(); stats.Retries != 2 || stats.Tokens != 250 || stats.Denied != 1 || stats.MaxTokens != 250 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestRetryBudget_Unlimited(t *testing.T) {
+	var nilBudget *RetryBudget
+	if !nilBudget.Allow(1000) || nilBudget.Stats() != (RetryBudgetStats{}) {
+		t.Error("Expected a nil budget to allow every retry")
+	}
+
+	budget := NewRetryBudget(0, 0)
+	for i := 0; i < 100; i++ {
+		if !budget.Allow(1000) {
+			t.Fatal("Expected an unlimited budget to allow every retry")
+		}
+	}
+	if stats := budget.Stats(); stats.Retries != 100 || stats.Exhausted() {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestRetryBudget_Concurrent(t *testing.T) {
+	budget := NewRetryBudget(50, 0)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if budget.Allow(1) {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := budget.Stats(); allowed != 50 || stats.Retries != 50 || stats.Denied != 150 {
+		t.Errorf("Expected exactly 50 retries allowed, got %d (%+v)", allowed, stats)
+	}
+}
@@ -0,0 +1,288 @@
+// Package router sends each request to one of several LLM clients, chosen
+// by rules on the request.
+//
+// A Router holds an ordered list of routes and sends each request to the
+// first route whose Rule it matches and which provides the capabilities
+// the request requires. Ordering cheap routes with narrow rules first and
+// an unrestricted capable route last gives "cheap by default, smart when
+// needed":
+//
+//	r, err := router.New(
+//		router.Route{Name: "fast", Client: groqClient, Model: "llama-3.1-8b-instant",
+//			Rule: router.Rule{MaxPromptTokens: 2000, MaxLatency: 2 * time.Second}},
+//		router.Route{Name: "smart", Client: geminiClient, Model: "gemini-2.5-pro"},
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer r.Close()
+//
+//	ctx = router.Require(ctx, router.Vision)
+//	text, md, err := xollm.GenerateWithMetadata(ctx, r, prompt)
+//	log.Printf("served by route %s", md.Route)
+//
+// Rules can bound the prompt size, match request tags (see
+// xollm.WithTags), cap the estimated cost and enforce a latency SLO from
+// the route's observed latency. With SetAvailability, routes to backends
+// found dead by an xollm.HealthProber are skipped, so the ordered routes
+// double as a fallback chain that fails over before requests hit a dead
+// backend. Every decision is passed to the OnDecision hook and recorded in
+// the response metadata.
+//
+// A Router is itself an xollm.Client, so it can be used wherever a single
+// client is expected.
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// ProviderName is the provider name reported by a Router.
+const ProviderName = "router"
+
+// latencyWeight is the weight of the latest request in a route's moving
+// average latency.
+const latencyWeight = 0.2
+
+// latencyHalfLife is how long it takes a route's average latency to halve
+// without requests, so a route skipped for its latency gets requests again
+// once it may have recovered.
+const latencyHalfLife = 30 * time.Second
+
+// ErrNoRoute is returned when no route can serve a request.
+var ErrNoRoute = errors.New("no route matches the request")
+
+// Route is a client the router can send requests to.
+type Route struct {
+	// Name identifies the route in decisions and metadata. If empty, the
+	// client's provider name is used.
+	Name string
+	// Client serves the route's requests.
+	Client xollm.Client
+	// Model is the model the client uses, for looking up its pricing,
+	// context window and capabilities. Optional.
+	Model string
+	// Capabilities the route provides in addition to those detected from
+	// the client and model.
+	Capabilities []Capability
+	// Rule restricts the requests the route serves.
+	Rule Rule
+}
+
+// Rejection is a route the router skipped, and why.
+type Rejection struct {
+	Route  string
+	Reason string
+}
+
+// Decision is the router's choice for a request.
+type Decision struct {
+	// Route is the name of the chosen route.
+	Route string
+	// Provider is the provider name of the chosen route's client.
+	Provider string
+	// PromptTokens is the estimated size of the prompt.
+	PromptTokens int
+	// Rejected lists the routes skipped before the chosen one, in order.
+	Rejected []Rejection
+}
+
+// route is a Route with its observed latency.
+type route struct {
+	Route
+
+	mu         sync.Mutex
+	avgLatency time.Duration
+	observedAt time.Time // When avgLatency was last updated
+}
+
+// latency returns the route's moving average latency, decayed for the
+// time since its last request, 0 if unknown.
+func (r *route) latency() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.decayedLatency(time.Now())
+}
+
+// decayedLatency returns the average latency at now, halved for every
+// latencyHalfLife since the last request.
+func (r *route) decayedLatency(now time.Time) time.Duration {
+	if r.avgLatency == 0 {
+		return 0
+	}
+	halvings := float64(now.Sub(r.observedAt)) / float64(latencyHalfLife)
+	return time.Duration(float64(r.avgLatency) * math.Pow(0.5, halvings))
+}
+
+// observe adds the latency of a completed request to the average.
+func (r *route) observe(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.avgLatency == 0 {
+		r.avgLatency = d
+	} else {
+		avg := r.decayedLatency(now)
+		r.avgLatency = avg + time.Duration(latencyWeight*float64(d-avg))
+	}
+	r.observedAt = now
+}
+
+// Router sends each request to the first route that can serve it.
+type Router struct {
+	routes       []*route
+	onDecision   func(Decision)
+	availability xollm.Availability
+}
+
+// New creates a router over routes, tried in order. The router owns the
+// routes' clients: Close closes them.
+func New(routes ...Route) (*Router, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("router needs at least one route")
+	}
+	r := &Router{}
+	names := map[string]bool{}
+	for i, rt := range routes {
+		if rt.Client == nil {
+			return nil, fmt.Errorf("router route %d has no client", i)
+		}
+		if rt.Name == "" {
+			rt.Name = rt.Client.ProviderName()
+		}
+		if names[rt.Name] {
+			return nil, fmt.Errorf("duplicate router route %q", rt.Name)
+		}
+		names[rt.Name] = true
+		r.routes = append(r.routes, &route{Route: rt})
+	}
+	return r, nil
+}
+
+// OnDecision registers fn to be called with every routing decision, e.g.
+// to log or count them. It must be called before the router is used.
+func (r *Router) OnDecision(fn func(Decision)) {
+	r.onDecision = fn
+}
+
+// SetAvailability makes the router skip routes whose name a reports as
+// unavailable, e.g. those an xollm.HealthProber found dead, so requests
+// fail over to the next matching route without first waiting on a dead
+// backend. If every matching route is unavailable, the first of them is
+// used anyway, since the availability may be stale. It must be called
+// before the router is used.
+func (r *Router) SetAvailability(a xollm.Availability) {
+	r.availability = a
+}
+
+// Select returns the route that would serve prompt in ctx. It fails with
+// an error matching ErrNoRoute if no route can.
+func (r *Router) Select(ctx context.Context, prompt string) (Decision, error) {
+	_, decision, err := r.selectRoute(ctx, prompt)
+	return decision, err
+}
+
+// selectRoute picks the route for prompt and reports the decision.
+func (r *Router) selectRoute(ctx context.Context, prompt string) (*route, Decision, error) {
+	req := request{
+		promptTokens: xollm.EstimateTokens(prompt),
+		tags:         xollm.TagsFromContext(ctx),
+		capabilities: requiredCapabilities(ctx),
+	}
+	decision := Decision{PromptTokens: req.promptTokens}
+	var standby *route // first matching route that is unavailable
+	standbyAt := 0     // len(decision.Rejected) before standby was rejected
+	for _, rt := range r.routes {
+		if reason := rt.check(req); reason != "" {
+			decision.Rejected = append(decision.Rejected, Rejection{Route: rt.Name, Reason: reason})
+			continue
+		}
+		if r.availability != nil && !r.availability.Available(rt.Name) {
+			if standby == nil {
+				standby, standbyAt = rt, len(decision.Rejected)
+			}
+			decision.Rejected = append(decision.Rejected, Rejection{Route: rt.Name, Reason: "backend unavailable"})
+			continue
+		}
+		return rt, r.decide(rt, decision), nil
+	}
+	if standby != nil {
+		decision.Rejected = decision.Rejected[:standbyAt]
+		return standby, r.decide(standby, decision), nil
+	}
+	if r.onDecision != nil {
+		r.onDecision(decision)
+	}
+	return nil, decision, fmt.Errorf("%w (%d routes rejected it)", ErrNoRoute, len(decision.Rejected))
+}
+
+// decide completes decision with the chosen route and reports it.
+func (r *Router) decide(rt *route, decision Decision) Decision {
+	decision.Route, decision.Provider = rt.Name, rt.Client.ProviderName()
+	if r.onDecision != nil {
+		r.onDecision(decision)
+	}
+	return decision
+}
+
+// Generate implements xollm.Client, sending prompt to the selected route.
+func (r *Router) Generate(ctx context.Context, prompt string) (string, error) {
+	text, _, err := r.GenerateWithMetadata(ctx, prompt)
+	return text, err
+}
+
+// GenerateWithMetadata implements xollm.MetadataGenerator. The metadata
+// names the selected route in Route.
+func (r *Router) GenerateWithMetadata(ctx context.Context, prompt string) (string, *xollm.ResponseMetadata, error) {
+	rt, decision, err := r.selectRoute(ctx, prompt)
+	if err != nil {
+		return "", nil, err
+	}
+	start := time.Now()
+	text, md, err := xollm.GenerateWithMetadata(ctx, rt.Client, prompt)
+	if err != nil {
+		return "", nil, fmt.Errorf("route %s: %w", decision.Route, err)
+	}
+	rt.observe(time.Since(start))
+	md.Route = decision.Route
+	return text, md, nil
+}
+
+// GenerateWithTools implements xollm.ToolCaller. Only routes providing the
+// Tools capability are considered.
+func (r *Router) GenerateWithTools(ctx context.Context, prompt string, tools []xollm.Tool, choice xollm.ToolChoice) (*xollm.ToolResponse, error) {
+	rt, decision, err := r.selectRoute(Require(ctx, Tools), prompt)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := xollm.GenerateWithTools(ctx, rt.Client, prompt, tools, choice)
+	if err != nil {
+		return nil, fmt.Errorf("route %s: %w", decision.Route, err)
+	}
+	rt.observe(time.Since(start))
+	return resp, nil
+}
+
+// ProviderName returns "router".
+func (r *Router) ProviderName() string {
+	return ProviderName
+}
+
+// Close closes the client of every route.
+func (r *Router) Close() error {
+	var errs []error
+	for _, rt := range r.routes {
+		if err := rt.Client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s client: %w", rt.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
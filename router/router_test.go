@@ -0,0 +1,233 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	name     string
+	response string
+	err      error
+	delay    time.Duration
+	calls    int
+	closed   bool
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	m.calls++
+	time.Sleep(m.delay)
+	return m.response, m.err
+}
+
+func (m *mockClient) ProviderName() string { return m.name }
+
+func (m *mockClient) Close() error {
+	m.closed = true
+	return nil
+}
+
+// toolClient adds tool calling to mockClient
+type toolClient struct {
+	mockClient
+}
+
+func (m *toolClient) GenerateWithTools(ctx context.Context, prompt string, tools []xollm.Tool, choice xollm.ToolChoice) (*xollm.ToolResponse, error) {
+	m.calls++
+	return &xollm.ToolResponse{Text: m.response}, nil
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(); err == nil {
+		t.Error("Expected error for no routes")
+	}
+	if _, err := New(Route{Name: "a"}); err == nil {
+		t.Error("Expected error for a route without a client")
+	}
+	a, b := &mockClient{name: "groq"}, &mockClient{name: "groq"}
+	if _, err := New(Route{Client: a}, Route{Client: b}); err == nil {
+		t.Error("Expected error for duplicate route names")
+	}
+}
+
+func TestRouter_CheapByDefault(t *testing.T) {
+	cheap := &mockClient{name: "groq", response: "cheap"}
+	smart := &mockClient{name: "gemini", response: "smart"}
+	r, err := New(
+		Route{Name: "cheap", Client: cheap, Rule: Rule{MaxPromptTokens: 10}},
+		Route{Name: "smart", Client: smart},
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	var decisions []Decision
+	r.OnDecision(func(d Decision) { decisions = append(decisions, d) })
+
+	text, md, err := r.GenerateWithMetadata(context.Background(), "short")
+	if err != nil || text != "cheap" || md.Route != "cheap" {
+		t.Errorf("Expected the cheap route, got %q, %+v, %v", text, md, err)
+	}
+
+	text, md, err = r.GenerateWithMetadata(context.Background(), strings.Repeat("a long prompt ", 20))
+	if err != nil || text != "smart" || md.Route != "smart" {
+		t.Errorf("Expected the smart route, got %q, %+v, %v", text, md, err)
+	}
+
+	if len(decisions) != 2 || decisions[1].Route != "smart" || decisions[1].Provider != "gemini" ||
+		len(decisions[1].Rejected) != 1 || decisions[1].Rejected[0].Route != "cheap" {
+		t.Errorf("Unexpected decisions: %+v", decisions)
+	}
+}
+
+func TestRouter_NoRoute(t *testing.T) {
+	r, _ := New(Route{Client: &mockClient{name: "groq"}, Rule: Rule{Tags: map[string]string{"tier": "free"}}})
+
+	_, err := r.Generate(context.Background(), "hi")
+	if !errors.Is(err, ErrNoRoute) {
+		t.Errorf("Expected ErrNoRoute, got %v", err)
+	}
+	d, err := r.Select(xollm.WithTags(context.Background(), map[string]string{"tier": "free"}), "hi")
+	if err != nil || d.Route != "groq" {
+		t.Errorf("Expected the tagged request to match, got %+v, %v", d, err)
+	}
+}
+
+func TestRouter_LatencySLO(t *testing.T) {
+	slow := &mockClient{name: "slow", response: "slow", delay: 20 * time.Millisecond}
+	fallback := &mockClient{name: "fallback", response: "fallback"}
+	r, _ := New(
+		Route{Client: slow, Rule: Rule{MaxLatency: 5 * time.Millisecond}},
+		Route{Client: fallback},
+	)
+
+	// The first request has no latency data, so the slow route serves it
+	if text, _ := r.Generate(context.Background(), "hi"); text != "slow" {
+		t.Errorf("Expected the slow route before its latency is known, got %q", text)
+	}
+	if text, _ := r.Generate(context.Background(), "hi"); text != "fallback" {
+		t.Errorf("Expected the slow route to be skipped, got %q", text)
+	}
+}
+
+func TestRouter_LatencySLORecovers(t *testing.T) {
+	backend := &mockClient{name: "backend", response: "backend", delay: 20 * time.Millisecond}
+	fallback := &mockClient{name: "fallback", response: "fallback"}
+	r, _ := New(
+		Route{Client: backend, Rule: Rule{MaxLatency: 10 * time.Millisecond}},
+		Route{Client: fallback},
+	)
+
+	// One slow request gets the route skipped
+	r.Generate(context.Background(), "hi")
+	if text, _ := r.Generate(context.Background(), "hi"); text != "fallback" {
+		t.Fatalf("Expected the slow route to be skipped, got %q", text)
+	}
+
+	// Once idle long enough, the route is tried again, and being fast now
+	// keeps serving
+	backend.delay = 0
+	rt := r.routes[0]
+	rt.mu.Lock()
+	rt.observedAt = rt.observedAt.Add(-2 * latencyHalfLife)
+	rt.mu.Unlock()
+	for i := 0; i < 3; i++ {
+		if text, _ := r.Generate(context.Background(), "hi"); text != "backend" {
+			t.Errorf("Request %d: expected the recovered route, got %q", i, text)
+		}
+	}
+}
+
+func TestRouter_GenerateWithTools(t *testing.T) {
+	plain := &mockClient{name: "plain", response: "plain"}
+	tools := &toolClient{mockClient{name: "tools", response: "tools"}}
+	r, _ := New(Route{Client: plain}, Route{Client: tools})
+
+	resp, err := r.GenerateWithTools(context.Background(), "hi", nil, xollm.ToolChoiceAuto)
+	if err != nil || resp.Text != "tools" || plain.calls != 0 {
+		t.Errorf("Expected the tool-calling route, got %+v, %v", resp, err)
+	}
+	if text, _ := r.Generate(context.Background(), "hi"); text != "plain" {
+		t.Errorf("Expected plain requests to use the first route, got %q", text)
+	}
+}
+
+func TestRouter_ErrorNamesRoute(t *testing.T) {
+	r, _ := New(Route{Name: "primary", Client: &mockClient{name: "groq", err: xollm.ErrRateLimited}})
+	_, err := r.Generate(context.Background(), "hi")
+	if !errors.Is(err, xollm.ErrRateLimited) || !strings.Contains(err.Error(), "primary") {
+		t.Errorf("Expected the client error with the route name, got %v", err)
+	}
+}
+
+func TestRouter_Close(t *testing.T) {
+	a, b := &mockClient{name: "a"}, &mockClient{name: "b"}
+	r, _ := New(Route{Client: a}, Route{Client: b})
+	if r.ProviderName() != ProviderName {
+		t.Errorf("Expected provider name %q, got %q", ProviderName, r.ProviderName())
+	}
+	if err := r.Close(); err != nil || !a.closed || !b.closed {
+		t.Errorf("Expected every client to be closed, got %v", err)
+	}
+}
+
+// availability marks the named routes unavailable
+type availability map[string]bool
+
+func (a availability) Available(name string) bool { return !a[name] }
+
+func TestRouter_SkipsUnavailableRoutes(t *testing.T) {
+	primary := &mockClient{name: "groq", response: "primary"}
+	backup := &mockClient{name: "ollama", response: "backup"}
+	r, err := New(Route{Name: "primary", Client: primary}, Route{Name: "backup", Client: backup})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	down := availability{"primary": true}
+	r.SetAvailability(down)
+
+	decision, err := r.Select(context.Background(), "hi")
+	if err != nil || decision.Route != "backup" {
+		t.Fatalf("Expected the backup route, got %+v, %v", decision, err)
+	}
+	if len(decision.Rejected) != 1 || decision.Rejected[0].Reason != "backend unavailable" {
+		t.Errorf("Expected the primary to be rejected as unavailable, got %+v", decision.Rejected)
+	}
+	if text, _ := r.Generate(context.Background(), "hi"); text != "backup" || primary.calls != 0 {
+		t.Errorf("Expected the request to skip the dead backend, got %q with %d primary calls", text, primary.calls)
+	}
+
+	// With every route down, the first matching one is tried anyway
+	down["backup"] = true
+	decision, err = r.Select(context.Background(), "hi")
+	if err != nil || decision.Route != "primary" || len(decision.Rejected) != 0 {
+		t.Errorf("Expected the first route as a last resort, got %+v, %v", decision, err)
+	}
+}
+
+func TestRouter_HealthProber(t *testing.T) {
+	dead := &pingClient{mockClient: mockClient{name: "groq"}, err: errors.New("connection refused")}
+	r, err := New(Route{Name: "primary", Client: dead}, Route{Name: "backup", Client: &mockClient{name: "ollama", response: "backup"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	prober := xollm.NewHealthProber(map[string]xollm.Client{"primary": dead}, xollm.ProberOptions{})
+	prober.ProbeNow(context.Background())
+	r.SetAvailability(prober)
+	if text, err := r.Generate(context.Background(), "hi"); err != nil || text != "backup" {
+		t.Errorf("Expected the probed-dead route to be skipped, got %q, %v", text, err)
+	}
+}
+
+// pingClient adds a Ping failing with err to mockClient
+type pingClient struct {
+	mockClient
+	err error
+}
+
+func (m *pingClient) Ping(ctx context.Context) error { return m.err }
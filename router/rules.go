@@ -0,0 +1,151 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// Capability is a feature a request needs from the model serving it.
+type Capability string
+
+// Capabilities a route can provide.
+const (
+	// Tools means the client implements xollm.ToolCaller.
+	Tools Capability = "tools"
+	// Streaming means the client streams natively (xollm.Streamer).
+	Streaming Capability = "streaming"
+	// Vision means the route's model accepts image input.
+	Vision Capability = "vision"
+	// Audio means the route's model accepts audio input.
+	Audio Capability = "audio"
+)
+
+type requirementsKey struct{}
+
+// Require returns a copy of ctx whose requests may only be routed to
+// routes providing every capability in caps, in addition to any already
+// required by ctx.
+func Require(ctx context.Context, caps ...Capability) context.Context {
+	required := append(append([]Capability(nil), requiredCapabilities(ctx)...), caps...)
+	return context.WithValue(ctx, requirementsKey{}, required)
+}
+
+// requiredCapabilities returns the capabilities required by ctx.
+func requiredCapabilities(ctx context.Context) []Capability {
+	caps, _ := ctx.Value(requirementsKey{}).([]Capability)
+	return caps
+}
+
+// Rule restricts the requests a route serves. A request matches when it
+// satisfies every condition that is set; the zero Rule matches every
+// request.
+type Rule struct {
+	// MinPromptTokens and MaxPromptTokens bound the estimated size of the
+	// prompt. Zero means no bound.
+	MinPromptTokens int
+	MaxPromptTokens int
+
+	// Tags are request tags (see xollm.WithTags) the request must carry
+	// with the given values. An empty value only requires the tag to be
+	// present.
+	Tags map[string]string
+
+	// MaxCostUSD is the most a request may cost on the route, estimated
+	// from the prompt and ExpectedOutputTokens with the pricing of the
+	// route's model. Routes whose model has no known pricing don't satisfy
+	// a cost ceiling.
+	MaxCostUSD float64
+	// ExpectedOutputTokens is the response size assumed by the cost
+	// estimate. If 0, the estimate covers the prompt only.
+	ExpectedOutputTokens int
+
+	// MaxLatency is the latency SLO of the route: it is skipped while its
+	// average latency over recent requests exceeds MaxLatency. Routes
+	// without completed requests meet it. The average halves every 30
+	// seconds without requests, so a skipped route is sent requests again
+	// after a while, and recovers once they are fast.
+	MaxLatency time.Duration
+}
+
+// request is what rules are evaluated against.
+type request struct {
+	promptTokens int
+	tags         map[string]string
+	capabilities []Capability
+}
+
+// check returns why route can't serve req, or "" if it can.
+func (r *route) check(req request) string {
+	for _, c := range req.capabilities {
+		if !r.provides(c) {
+			return fmt.Sprintf("lacks capability %s", c)
+		}
+	}
+	if info, ok := xollm.LookupModel(r.Model); ok && info.ContextWindow > 0 && req.promptTokens > info.ContextWindow {
+		return fmt.Sprintf("prompt of ~%d tokens exceeds the %d token context window", req.promptTokens, info.ContextWindow)
+	}
+
+	rule := r.Rule
+	if rule.MinPromptTokens > 0 && req.promptTokens < rule.MinPromptTokens {
+		return fmt.Sprintf("prompt of ~%d tokens is below %d", req.promptTokens, rule.MinPromptTokens)
+	}
+	if rule.MaxPromptTokens > 0 && req.promptTokens > rule.MaxPromptTokens {
+		return fmt.Sprintf("prompt of ~%d tokens is above %d", req.promptTokens, rule.MaxPromptTokens)
+	}
+	for key, want := range rule.Tags {
+		got, ok := req.tags[key]
+		if !ok || (want != "" && got != want) {
+			return fmt.Sprintf("tag %s does not match", key)
+		}
+	}
+	if rule.MaxCostUSD > 0 {
+		cost, ok := r.estimateCost(req)
+		if !ok {
+			return "pricing unknown for the cost ceiling"
+		}
+		if cost > rule.MaxCostUSD {
+			return fmt.Sprintf("estimated cost $%.6f exceeds $%.6f", cost, rule.MaxCostUSD)
+		}
+	}
+	if rule.MaxLatency > 0 {
+		if latency := r.latency(); latency > rule.MaxLatency {
+			return fmt.Sprintf("average latency %v exceeds %v", latency.Round(time.Millisecond), rule.MaxLatency)
+		}
+	}
+	return ""
+}
+
+// provides reports whether the route provides capability c.
+func (r *route) provides(c Capability) bool {
+	for _, declared := range r.Capabilities {
+		if declared == c {
+			return true
+		}
+	}
+	switch c {
+	case Tools:
+		_, ok := r.Client.(xollm.ToolCaller)
+		return ok
+	case Streaming:
+		_, ok := r.Client.(xollm.Streamer)
+		return ok
+	case Vision:
+		info, ok := xollm.LookupModel(r.Model)
+		return ok && info.Vision
+	case Audio:
+		info, ok := xollm.LookupModel(r.Model)
+		return ok && info.Audio
+	}
+	return false
+}
+
+// estimateCost returns the estimated cost of req on the route.
+func (r *route) estimateCost(req request) (float64, bool) {
+	return xollm.EstimateCost(r.Client.ProviderName(), r.Model, xollm.Usage{
+		PromptTokens:     req.promptTokens,
+		CompletionTokens: r.Rule.ExpectedOutputTokens,
+	})
+}
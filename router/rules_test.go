@@ -0,0 +1,68 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+func TestRequire(t *testing.T) {
+	ctx := Require(context.Background(), Tools)
+	ctx = Require(ctx, Vision)
+	caps := requiredCapabilities(ctx)
+	if len(caps) != 2 || caps[0] != Tools || caps[1] != Vision {
+		t.Errorf("Expected both capabilities, got %v", caps)
+	}
+}
+
+func TestRoute_Check(t *testing.T) {
+	groq := &mockClient{name: "groq"}
+	tests := []struct {
+		name   string
+		route  Route
+		req    request
+		reason string
+	}{
+		{name: "zero rule", route: Route{Client: groq}, req: request{promptTokens: 100}},
+		{name: "min prompt", route: Route{Client: groq, Rule: Rule{MinPromptTokens: 500}}, req: request{promptTokens: 100}, reason: "below 500"},
+		{name: "context window", route: Route{Client: groq, Model: "llama3-8b-8192"}, req: request{promptTokens: 10000}, reason: "context window"},
+		{name: "tag value", route: Route{Client: groq, Rule: Rule{Tags: map[string]string{"tier": "paid"}}}, req: request{tags: map[string]string{"tier": "free"}}, reason: "tag tier"},
+		{name: "tag presence", route: Route{Client: groq, Rule: Rule{Tags: map[string]string{"tier": ""}}}, req: request{tags: map[string]string{"tier": "free"}}},
+		{name: "vision", route: Route{Client: groq, Model: "llama-3.3-70b-versatile"}, req: request{capabilities: []Capability{Vision}}, reason: "lacks capability vision"},
+		{name: "vision model", route: Route{Client: groq, Model: "gemini-2.5-pro"}, req: request{capabilities: []Capability{Vision}}},
+		{name: "declared capability", route: Route{Client: groq, Capabilities: []Capability{Streaming}}, req: request{capabilities: []Capability{Streaming}}},
+		{name: "unknown pricing", route: Route{Client: groq, Model: "unknown-model", Rule: Rule{MaxCostUSD: 1}}, reason: "pricing unknown"},
+		{name: "free model", route: Route{Client: &mockClient{name: "ollama"}, Model: "llama3", Rule: Rule{MaxCostUSD: 0.0001}}, req: request{promptTokens: 1000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &route{Route: tt.route}
+			got := rt.check(tt.req)
+			if tt.reason == "" && got != "" {
+				t.Errorf("Expected the route to match, got %q", got)
+			}
+			if tt.reason != "" && !strings.Contains(got, tt.reason) {
+				t.Errorf("Expected a rejection containing %q, got %q", tt.reason, got)
+			}
+		})
+	}
+}
+
+func TestRoute_CostCeiling(t *testing.T) {
+	xollm.RegisterPricing("router-test-model", xollm.Pricing{Input: 1, Output: 2})
+	rt := &route{Route: Route{
+		Client: &mockClient{name: "groq"},
+		Model:  "router-test-model",
+		Rule:   Rule{MaxCostUSD: 0.003, ExpectedOutputTokens: 1000},
+	}}
+
+	// 1000 prompt tokens cost $0.001 and 1000 output tokens $0.002
+	if reason := rt.check(request{promptTokens: 1000}); reason != "" {
+		t.Errorf("Expected a request within the ceiling to match, got %q", reason)
+	}
+	if reason := rt.check(request{promptTokens: 2000}); !strings.Contains(reason, "exceeds") {
+		t.Errorf("Expected a request over the ceiling to be rejected, got %q", reason)
+	}
+}
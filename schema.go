@@ -0,0 +1,60 @@
+package xollm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// SchemaError reports JSON that doesn't match a schema, with the path of
+// the mismatch. See llm.SchemaError.
+type SchemaError = llm.SchemaError
+
+// ValidateJSON checks that data is a JSON value matching schema, for the
+// subset of JSON Schema that structured output APIs accept. See
+// llm.ValidateJSON.
+func ValidateJSON(schema json.RawMessage, data []byte) error {
+	return llm.ValidateJSON(schema, data)
+}
+
+// ExtractJSON returns the JSON value in a model's reply, removing a
+// markdown code fence or a sentence around it. See llm.ExtractJSON.
+func ExtractJSON(text string) string {
+	return llm.ExtractJSON(text)
+}
+
+// JSONGenerator is implemented by clients with a structured output mode.
+// See llm.JSONGenerator.
+type JSONGenerator = llm.JSONGenerator
+
+// anyObject is the schema of GenerateJSON calls without one.
+var anyObject = json.RawMessage(`{"type":"object"}`)
+
+// GenerateJSON returns the model's response to prompt as JSON matching
+// schema, or any JSON object if schema is nil, using the provider's
+// structured output mode: a response schema on Gemini, format on Ollama
+// and JSON mode on Groq. The response is validated before it is returned;
+// a mismatch fails with a *SchemaError, returned along with the text. It
+// fails for clients that don't implement JSONGenerator.
+//
+//	text, err := xollm.GenerateJSON(ctx, client, "Extract the people mentioned: "+article,
+//		json.RawMessage(`{"type":"object","properties":{"names":{"type":"array","items":{"type":"string"}}},"required":["names"]}`))
+func GenerateJSON(ctx context.Context, client Client, prompt string, schema json.RawMessage) (string, error) {
+	gen, ok := client.(JSONGenerator)
+	if !ok {
+		return "", fmt.Errorf("%s client does not support structured output", client.ProviderName())
+	}
+	text, err := gen.GenerateJSON(ctx, prompt, schema)
+	if err != nil {
+		return "", err
+	}
+	if len(schema) == 0 {
+		schema = anyObject
+	}
+	if err := llm.ValidateJSON(schema, []byte(text)); err != nil {
+		return text, fmt.Errorf("%s response does not match the schema: %w", client.ProviderName(), err)
+	}
+	return text, nil
+}
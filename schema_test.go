@@ -0,0 +1,60 @@
+package xollm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// jsonClient returns its response as JSON and records the schema.
+type jsonClient struct {
+	stubClient
+	schema json.RawMessage
+}
+
+func (c *jsonClient) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	c.schema = schema
+	return c.response, c.err
+}
+
+func TestGenerateJSON(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`)
+	client := &jsonClient{stubClient: stubClient{response: `{"name": "Ada"}`}}
+	text, err := GenerateJSON(context.Background(), client, "Who?", schema)
+	if err != nil || text != `{"name": "Ada"}` {
+		t.Fatalf("Expected the JSON response, got %q, %v", text, err)
+	}
+	if string(client.schema) != string(schema) {
+		t.Errorf("Expected the schema passed to the client, got %s", client.schema)
+	}
+
+	client.response = `{"age": 36}`
+	text, err = GenerateJSON(context.Background(), client, "Who?", schema)
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || !strings.Contains(schemaErr.Message, "name") {
+		t.Errorf("Expected a *SchemaError for a missing property, got: %v", err)
+	}
+	if text != `{"age": 36}` {
+		t.Errorf("Expected the mismatching text along with the error, got %q", text)
+	}
+}
+
+func TestGenerateJSON_NoSchema(t *testing.T) {
+	client := &jsonClient{stubClient: stubClient{response: `["not", "an", "object"]`}}
+	if _, err := GenerateJSON(context.Background(), client, "List", nil); err == nil {
+		t.Error("Expected an error for a response that isn't an object")
+	}
+	client.response = `{"ok": true}`
+	if _, err := GenerateJSON(context.Background(), client, "List", nil); err != nil {
+		t.Errorf("Expected any object to pass, got: %v", err)
+	}
+}
+
+func TestGenerateJSON_Unsupported(t *testing.T) {
+	_, err := GenerateJSON(context.Background(), &stubClient{}, "hi", nil)
+	if err == nil || !strings.Contains(err.Error(), "does not support structured output") {
+		t.Errorf("Expected unsupported error, got: %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+const (
+	defaultShutdownTimeout = 30 * time.Second
+	defaultPingTimeout     = 5 * time.Second
+)
+
+// Server is a small HTTP gateway in front of an xollm client, suitable for
+// running in Kubernetes.
+//
+// It serves:
+//   - /generate: streaming generation (see SSEHandler)
+//   - /healthz: liveness, 200 while the process is serving
+//   - /readyz: readiness, 200 only when the client's Ping succeeds and the
+//     server is not shutting down
+//
+// Additional routes can be registered with Handle before calling
+// ListenAndServe. Requests to /generate and to added routes are tracked so
+// Shutdown can drain them; once the shutdown deadline passes, their
+// contexts are cancelled with cause xollm.ErrShuttingDown, letting
+// handlers report the failure before connections are closed.
+type Server struct {
+	// Addr is the TCP address to listen on, e.g. ":8080".
+	Addr string
+
+	// ShutdownTimeout bounds how long in-flight requests may take to drain
+	// after a shutdown signal. Defaults to 30 seconds.
+	ShutdownTimeout time.Duration
+
+	// DrainDelay is how long the server keeps accepting requests after a
+	// shutdown signal while /readyz reports not ready, giving load balancers
+	// time to stop routing new traffic. Defaults to 0.
+	DrainDelay time.Duration
+
+	// PingTimeout bounds each readiness check. Defaults to 5 seconds.
+	PingTimeout time.Duration
+
+	client     xollm.Client
+	mux        *http.ServeMux
+	httpServer *http.Server
+	draining   atomic.Bool
+	drainer    xollm.Drainer
+}
+
+// New creates a Server listening on addr that serves client.
+func New(addr string, client xollm.Client) *Server {
+	s := &Server{
+		Addr:            addr,
+		ShutdownTimeout: defaultShutdownTimeout,
+		PingTimeout:     defaultPingTimeout,
+		client:          client,
+		mux:             http.NewServeMux(),
+	}
+	s.mux.Handle("/generate", s.track(SSEHandler(client)))
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/readyz", s.handleReadyz)
+	s.httpServer = &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Handle registers an additional handler for pattern.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, s.track(handler))
+}
+
+// track registers each request to handler with the server's drainer.
+// Requests arriving after the drainer shut down are rejected with 503.
+func (s *Server) track(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, done, err := s.drainer.Begin(r.Context())
+		if err != nil {
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		defer done()
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Handler returns the server's root handler, useful for tests or for
+// mounting the gateway inside another server.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// ListenAndServe starts serving and blocks until ctx is cancelled or the
+// process receives SIGTERM or SIGINT. It then drains connections as
+// described in Shutdown and returns.
+//
+// A nil error is returned after a clean shutdown.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve is like ListenAndServe but accepts connections on listener.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout()+s.DrainDelay)
+	defer cancel()
+	return s.Shutdown(shutdownCtx)
+}
+
+// Shutdown gracefully stops the server. Readiness immediately reports not
+// ready; after DrainDelay the listener is closed and in-flight requests are
+// given until ctx expires (or ShutdownTimeout, whichever is sooner) to
+// complete. Generations still running then are cancelled, and their
+// connections closed once the handlers return.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.draining.Store(true)
+
+	if s.DrainDelay > 0 {
+		xollm.Logger(ctx).Info("server: draining, waiting before closing listener", "delay", s.DrainDelay)
+		select {
+		case <-time.After(s.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.shutdownTimeout())
+	defer cancel()
+
+	httpErr := make(chan error, 1)
+	go func() {
+		httpErr <- s.httpServer.Shutdown(ctx)
+	}()
+	drainErr := s.drainer.Shutdown(ctx)
+	if err := <-httpErr; err != nil {
+		// Force-close connections that did not drain in time
+		s.httpServer.Close()
+		if drainErr == nil {
+			drainErr = err
+		}
+	}
+	if drainErr != nil {
+		return fmt.Errorf("server shutdown did not complete: %w", drainErr)
+	}
+	return nil
+}
+
+// handleHealthz reports liveness.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+// handleReadyz reports readiness based on draining state and client Ping.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if s.draining.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "shutting down")
+		return
+	}
+
+	timeout := s.PingTimeout
+	if timeout <= 0 {
+		timeout = defaultPingTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	if err := xollm.Ping(ctx, s.client); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "%s not ready: %v\n", s.client.ProviderName(), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+// shutdownTimeout returns the configured timeout or its default.
+func (s *Server) shutdownTimeout() time.Duration {
+	if s.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return s.ShutdownTimeout
+}
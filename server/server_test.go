@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm"
+)
+
+// pingingClient adds Ping to mockClient
+type pingingClient struct {
+	mockClient
+	pingErr error
+}
+
+func (p *pingingClient) Ping(ctx context.Context) error {
+	return p.pingErr
+}
+
+func TestServer_Healthz(t *testing.T) {
+	s := New(":0", &mockClient{})
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestServer_Readyz(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *pingingClient
+		status int
+	}{
+		{name: "ping succeeds", client: &pingingClient{}, status: http.StatusOK},
+		{name: "ping fails", client: &pingingClient{pingErr: errors.New("unreachable")}, status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(":0", tt.client)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestServer_ReadyzReportsDraining(t *testing.T) {
+	s := New(":0", &pingingClient{})
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected no error from Shutdown(), got: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 while draining, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "shutting down") {
+		t.Errorf("Expected body to mention shutting down, got: %s", rec.Body.String())
+	}
+}
+
+func TestServer_ServeAndShutdownOnContextCancel(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	s := New(listener.Addr().String(), &mockClient{response: "hi"})
+	s.ShutdownTimeout = 2 * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx, listener)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/healthz")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+}
+
+func TestServer_ShutdownWaitsForInFlightRequests(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	started := make(chan struct{})
+	s := New(listener.Addr().String(), &mockClient{})
+	s.Handle("/slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("finished"))
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx, listener)
+	}()
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			respCh <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	if body := <-respCh; body != "finished" {
+		t.Errorf("Expected in-flight request to complete, got: %s", body)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected clean shutdown, got: %v", err)
+	}
+}
+
+// blockingClient blocks until its context is cancelled and records why
+type blockingClient struct {
+	mockClient
+	started chan struct{}
+	cause   chan error
+}
+
+func (b *blockingClient) Generate(ctx context.Context, prompt string) (string, error) {
+	close(b.started)
+	<-ctx.Done()
+	b.cause <- context.Cause(ctx)
+	return "", ctx.Err()
+}
+
+func TestServer_ShutdownCancelsGenerationsAfterDeadline(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	client := &blockingClient{started: make(chan struct{}), cause: make(chan error, 1)}
+	s := New(listener.Addr().String(), client)
+	go s.httpServer.Serve(listener)
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Post("http://"+listener.Addr().String()+"/generate", "application/json", strings.NewReader(`{"prompt": "hi"}`))
+		if err != nil {
+			respCh <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+	<-client.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = s.Shutdown(ctx)
+	if err == nil || !strings.Contains(err.Error(), "cancelled 1 in-flight") {
+		t.Errorf("Expected Shutdown to report the cancelled generation, got: %v", err)
+	}
+	if cause := <-client.cause; !errors.Is(cause, xollm.ErrShuttingDown) {
+		t.Errorf("Expected the generation to be cancelled by the shutdown, got cause: %v", cause)
+	}
+	if body := <-respCh; !strings.Contains(body, "event: error") {
+		t.Errorf("Expected the handler to report the cancellation, got: %s", body)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/generate", strings.NewReader(`{"prompt": "hi"}`)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected new generations to be rejected with 503, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,130 @@
+// Package server provides HTTP handlers for exposing xollm clients to web applications.
+//
+// SSEHandler streams a generation to the browser as Server-Sent Events, so a
+// streaming UI can be wired up with a single line:
+//
+//	http.Handle("/generate", server.SSEHandler(client))
+//
+// The browser side can consume it with the standard EventSource API (GET with
+// a "prompt" query parameter) or with fetch (POST with a JSON body).
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/xostack/xollm"
+)
+
+// maxRequestBodyBytes limits the size of POSTed generation requests.
+const maxRequestBodyBytes = 1 << 20
+
+// GenerateRequest is the JSON body accepted by the handlers in this package.
+type GenerateRequest struct {
+	Prompt string `json:"prompt"`
+}
+
+// sseChunk is the data payload of a "message" event.
+type sseChunk struct {
+	Text string `json:"text"`
+}
+
+// sseError is the data payload of an "error" event.
+type sseError struct {
+	Error string `json:"error"`
+}
+
+// SSEHandler returns an http.Handler that streams generations from client as
+// Server-Sent Events.
+//
+// The prompt is read from the "prompt" query parameter on GET requests and
+// from a JSON GenerateRequest body on POST requests. Each generated chunk is
+// sent as an unnamed event whose data is {"text": "..."}. The stream ends
+// with a "done" event on success or an "error" event whose data is
+// {"error": "..."} on failure.
+//
+// Clients that do not implement xollm.Streamer are still supported; their
+// full response is sent as a single chunk. Generation is cancelled when the
+// HTTP client disconnects.
+func SSEHandler(client xollm.Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prompt, status, err := readPrompt(r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported by this connection", http.StatusInternalServerError)
+			return
+		}
+
+		chunks, err := xollm.GenerateStream(r.Context(), client, prompt)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to start generation: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		header := w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for chunk := range chunks {
+			switch {
+			case chunk.Err != nil:
+				writeEvent(w, "error", sseError{Error: chunk.Err.Error()})
+			case chunk.Done:
+				writeEvent(w, "done", struct{}{})
+			case chunk.Text != "":
+				writeEvent(w, "", sseChunk{Text: chunk.Text})
+			default:
+				continue
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+// readPrompt extracts the prompt from a GET query or POST JSON body.
+// It returns the HTTP status to use when the request is invalid.
+func readPrompt(r *http.Request) (string, int, error) {
+	var prompt string
+	switch r.Method {
+	case http.MethodGet:
+		prompt = r.URL.Query().Get("prompt")
+	case http.MethodPost:
+		var req GenerateRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBodyBytes))
+		if err := decoder.Decode(&req); err != nil {
+			return "", http.StatusBadRequest, fmt.Errorf("invalid JSON request body: %w", err)
+		}
+		prompt = req.Prompt
+	default:
+		return "", http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method)
+	}
+
+	if prompt == "" {
+		return "", http.StatusBadRequest, fmt.Errorf("prompt is required")
+	}
+	return prompt, http.StatusOK, nil
+}
+
+// writeEvent writes a single SSE event with a JSON data payload.
+// An empty event name produces a default "message" event.
+func writeEvent(w http.ResponseWriter, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload = []byte(`{"error":"failed to encode event"}`)
+		event = "error"
+	}
+	if event != "" {
+		fmt.Fprintf(w, "event: %s\n", event)
+	}
+	fmt.Fprintf(w, "data: %s\n\n", payload)
+}
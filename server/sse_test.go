@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xostack/xollm"
+)
+
+// mockClient implements xollm.Client for testing
+type mockClient struct {
+	response string
+	err      error
+}
+
+func (m *mockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return m.response, m.err
+}
+
+func (m *mockClient) ProviderName() string { return "mock" }
+
+func (m *mockClient) Close() error { return nil }
+
+// mockStreamer adds native streaming to mockClient
+type mockStreamer struct {
+	mockClient
+	chunks []string
+}
+
+func (m *mockStreamer) GenerateStream(ctx context.Context, prompt string) (<-chan xollm.StreamChunk, error) {
+	ch := make(chan xollm.StreamChunk, len(m.chunks)+1)
+	for _, c := range m.chunks {
+		ch <- xollm.StreamChunk{Text: c}
+	}
+	ch <- xollm.StreamChunk{Done: true}
+	close(ch)
+	return ch, nil
+}
+
+func TestSSEHandler_StreamsChunks(t *testing.T) {
+	handler := SSEHandler(&mockStreamer{chunks: []string{"Hello", " world"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/generate?prompt=hi", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected content type 'text/event-stream', got '%s'", ct)
+	}
+
+	expected := "data: {\"text\":\"Hello\"}\n\n" +
+		"data: {\"text\":\" world\"}\n\n" +
+		"event: done\ndata: {}\n\n"
+	if rec.Body.String() != expected {
+		t.Errorf("Unexpected body:\n%s\nexpected:\n%s", rec.Body.String(), expected)
+	}
+}
+
+func TestSSEHandler_POSTWithNonStreamingClient(t *testing.T) {
+	handler := SSEHandler(&mockClient{response: "complete"})
+
+	req := httptest.NewRequest(http.MethodPost, "/generate", strings.NewReader(`{"prompt":"hi"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `data: {"text":"complete"}`) {
+		t.Errorf("Expected single chunk with full response, got:\n%s", rec.Body.String())
+	}
+	if !strings.HasSuffix(rec.Body.String(), "event: done\ndata: {}\n\n") {
+		t.Errorf("Expected stream to end with done event, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestSSEHandler_GenerationError(t *testing.T) {
+	handler := SSEHandler(&mockClient{err: errors.New("provider down")})
+
+	req := httptest.NewRequest(http.MethodGet, "/generate?prompt=hi", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	expected := "event: error\ndata: {\"error\":\"provider down\"}\n\n"
+	if rec.Body.String() != expected {
+		t.Errorf("Unexpected body:\n%s\nexpected:\n%s", rec.Body.String(), expected)
+	}
+}
+
+func TestSSEHandler_BadRequests(t *testing.T) {
+	handler := SSEHandler(&mockClient{response: "x"})
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		target string
+		status int
+	}{
+		{name: "missing prompt", method: http.MethodGet, target: "/generate", status: http.StatusBadRequest},
+		{name: "invalid JSON", method: http.MethodPost, target: "/generate", body: "{", status: http.StatusBadRequest},
+		{name: "empty JSON prompt", method: http.MethodPost, target: "/generate", body: `{}`, status: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodDelete, target: "/generate", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.target, body)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestSSEHandler_OverHTTP(t *testing.T) {
+	server := httptest.NewServer(SSEHandler(&mockStreamer{chunks: []string{"a", "b"}}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?prompt=hi")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	if strings.Count(string(body), "data: ") != 3 {
+		t.Errorf("Expected 3 events over HTTP, got:\n%s", body)
+	}
+}
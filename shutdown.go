@@ -0,0 +1,142 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrShuttingDown is returned for work started after a graceful shutdown
+// began, and is the cause of the contexts of in-flight work cancelled when
+// the shutdown deadline passes (see context.Cause).
+var ErrShuttingDown = errors.New("shutting down")
+
+// Shutdowner is implemented by components that shut down gracefully: Pool,
+// the server package's Server, and the batch processors built on Drainer.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown shuts down components concurrently and returns their errors
+// joined. Each stops accepting new work, waits for its in-flight
+// generations until ctx is done, then cancels the rest.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	err := xollm.Shutdown(ctx, srv, pool)
+func Shutdown(ctx context.Context, components ...Shutdowner) error {
+	errs := make([]error, len(components))
+	var wg sync.WaitGroup
+	for i, c := range components {
+		wg.Add(1)
+		go func(i int, c Shutdowner) {
+			defer wg.Done()
+			errs[i] = c.Shutdown(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Drainer tracks in-flight work so it can be shut down gracefully instead
+// of all at once: Shutdown refuses new work, waits for the work in flight
+// until its deadline, and only then cancels what is left. The zero value is
+// ready to use and a Drainer is safe for concurrent use.
+//
+//	ctx, done, err := drainer.Begin(ctx)
+//	if err != nil {
+//		return err // shutting down
+//	}
+//	defer done()
+//	text, err := client.Generate(ctx, prompt)
+type Drainer struct {
+	initOnce sync.Once
+	base     context.Context
+	cancel   context.CancelCauseFunc
+
+	mu       sync.Mutex
+	closed   bool
+	wg       sync.WaitGroup
+	inFlight atomic.Int64
+}
+
+// init creates the context whose cancellation cancels all in-flight work.
+func (d *Drainer) init() {
+	d.initOnce.Do(func() {
+		d.base, d.cancel = context.WithCancelCause(context.Background())
+	})
+}
+
+// Begin registers a unit of work. The returned context is derived from ctx
+// and is also cancelled, with cause ErrShuttingDown, if a shutdown deadline
+// passes while the work is in flight. done must be called when the work
+// ends; calling it again has no effect. Begin fails with ErrShuttingDown
+// once Shutdown was called.
+func (d *Drainer) Begin(ctx context.Context) (context.Context, func(), error) {
+	d.init()
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil, nil, ErrShuttingDown
+	}
+	d.wg.Add(1)
+	d.inFlight.Add(1)
+	d.mu.Unlock()
+
+	workCtx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(d.base, func() { cancel(ErrShuttingDown) })
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			stop()
+			cancel(nil)
+			d.inFlight.Add(-1)
+			d.wg.Done()
+		})
+	}
+	return workCtx, done, nil
+}
+
+// InFlight returns the number of units of work in flight.
+func (d *Drainer) InFlight() int {
+	return int(d.inFlight.Load())
+}
+
+// ShuttingDown reports whether Shutdown was called.
+func (d *Drainer) ShuttingDown() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// Shutdown stops new work from beginning and waits for the work in flight
+// to finish. If ctx is done first, the remaining work's contexts are
+// cancelled and Shutdown waits for it to return, then reports how many
+// units were cancelled in an error wrapping ctx.Err().
+func (d *Drainer) Shutdown(ctx context.Context) error {
+	d.init()
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+	}
+	remaining := d.InFlight()
+	d.cancel(ErrShuttingDown)
+	<-drained
+	if remaining == 0 {
+		return nil
+	}
+	return fmt.Errorf("cancelled %d in-flight requests after shutdown deadline: %w", remaining, ctx.Err())
+}
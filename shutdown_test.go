@@ -0,0 +1,139 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xostack/xollm/config"
+)
+
+func TestDrainer_WaitsForInFlightWork(t *testing.T) {
+	var d Drainer
+	ctx, done, err := d.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	if d.InFlight() != 1 {
+		t.Errorf("Expected 1 in flight, got %d", d.InFlight())
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		done()
+		done() // a second call has no effect
+	}()
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected a clean shutdown, got: %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected the work's context to be released once done")
+	}
+	if context.Cause(ctx) == ErrShuttingDown {
+		t.Error("Expected work that finished in time not to be cancelled by the shutdown")
+	}
+	if d.InFlight() != 0 || !d.ShuttingDown() {
+		t.Errorf("Unexpected state after shutdown: %d in flight", d.InFlight())
+	}
+
+	if _, _, err := d.Begin(context.Background()); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected ErrShuttingDown after shutdown, got: %v", err)
+	}
+}
+
+func TestDrainer_CancelsAfterDeadline(t *testing.T) {
+	var d Drainer
+	ctx, done, err := d.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	go func() {
+		<-ctx.Done()
+		done()
+	}()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = d.Shutdown(shutdownCtx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "cancelled 1 in-flight") {
+		t.Errorf("Expected the deadline error, got: %v", err)
+	}
+	if !errors.Is(context.Cause(ctx), ErrShuttingDown) {
+		t.Errorf("Expected the work to be cancelled with ErrShuttingDown, got: %v", context.Cause(ctx))
+	}
+}
+
+func TestDrainer_ParentCancellation(t *testing.T) {
+	var d Drainer
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, done, err := d.Begin(parent)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	defer done()
+	cancel()
+	<-ctx.Done()
+	if d.ShuttingDown() {
+		t.Error("Expected cancelling one unit of work not to shut the drainer down")
+	}
+}
+
+type shutdownFunc func(ctx context.Context) error
+
+func (f shutdownFunc) Shutdown(ctx context.Context) error { return f(ctx) }
+
+func TestShutdown_JoinsErrors(t *testing.T) {
+	calls := make(chan string, 2)
+	err := Shutdown(context.Background(),
+		shutdownFunc(func(ctx context.Context) error { calls <- "a"; return nil }),
+		shutdownFunc(func(ctx context.Context) error { calls <- "b"; return errors.New("b failed") }),
+	)
+	if err == nil || err.Error() != "b failed" {
+		t.Errorf("Expected the failing component's error, got: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Errorf("Expected both components to be shut down, got %d", len(calls))
+	}
+}
+
+func TestPool_Shutdown(t *testing.T) {
+	originalGetClient := GetClient
+	defer func() { GetClient = originalGetClient }()
+	GetClient = func(cfg config.Config, debugMode bool) (Client, error) {
+		return &stubClient{response: "ok"}, nil
+	}
+
+	pool := NewPool(config.Config{DefaultProvider: "ollama"}, false)
+	ctx, done, err := pool.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- pool.Shutdown(context.Background())
+	}()
+
+	// In-flight work can still get clients while the pool drains
+	time.Sleep(10 * time.Millisecond)
+	client, err := pool.Default()
+	if err != nil {
+		t.Fatalf("Expected Get to work while draining, got: %v", err)
+	}
+	if _, err := client.Generate(ctx, "hi"); err != nil {
+		t.Errorf("Generate failed: %v", err)
+	}
+	if _, _, err := pool.Begin(context.Background()); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected new work to be refused, got: %v", err)
+	}
+	done()
+
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Expected a clean shutdown, got: %v", err)
+	}
+	if _, err := pool.Default(); err == nil {
+		t.Error("Expected Get to fail after shutdown")
+	}
+}
@@ -0,0 +1,330 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// StreamChunk is a single incremental piece of a streamed generation.
+// See llm.StreamChunk for the delivery contract.
+type StreamChunk = llm.StreamChunk
+
+// PartialError is the error of a stream that failed after producing text;
+// Text holds what was generated before the failure. See llm.PartialError.
+type PartialError = llm.PartialError
+
+// Usage reports the tokens a generation consumed. See llm.Usage.
+type Usage = llm.Usage
+
+// Streamer is implemented by clients that can stream generated text as it
+// is produced instead of returning it all at once. All bundled providers
+// implement it.
+//
+// GenerateStream returns a channel that yields chunks until a terminal chunk
+// (Done or Err) is sent, after which the channel is closed. Cancelling ctx
+// aborts the stream; implementations must still close the channel.
+type Streamer interface {
+	GenerateStream(ctx context.Context, prompt string) (<-chan StreamChunk, error)
+}
+
+// GenerateStream streams a generation from client.
+//
+// If client implements Streamer its native streaming is used. Otherwise the
+// full response is obtained with Generate and delivered as a single chunk,
+// so callers can use one code path for every provider.
+//
+// If a native stream fails after delivering text, the error chunk carries a
+// *PartialError holding the text received so far.
+func GenerateStream(ctx context.Context, client Client, prompt string) (<-chan StreamChunk, error) {
+	if streamer, ok := client.(Streamer); ok {
+		chunks, err := streamer.GenerateStream(ctx, prompt)
+		if err != nil {
+			return nil, err
+		}
+		return trackPartial(ctx, chunks), nil
+	}
+
+	chunks := make(chan StreamChunk, 2)
+	go func() {
+		defer close(chunks)
+		response, err := client.Generate(ctx, prompt)
+		if err != nil {
+			chunks <- StreamChunk{Err: err}
+			return
+		}
+		chunks <- StreamChunk{Text: response}
+		chunks <- StreamChunk{Done: true}
+	}()
+	return chunks, nil
+}
+
+// trackPartial forwards chunks, accumulating their text so that a
+// terminal error can be reported as a *PartialError. If ctx is cancelled
+// while the consumer is not reading, the rest of the stream is discarded.
+func trackPartial(ctx context.Context, in <-chan StreamChunk) <-chan StreamChunk {
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		var text strings.Builder
+		for chunk := range in {
+			text.WriteString(chunk.Text)
+			if chunk.Err != nil && text.Len() > 0 {
+				var partial *PartialError
+				if !errors.As(chunk.Err, &partial) {
+					chunk.Err = &PartialError{Text: text.String(), Err: chunk.Err}
+				}
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				for range in {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// CollectStream reads a stream to the end and returns the concatenated
+// text. If the stream fails, the text received so far is returned together
+// with the error.
+func CollectStream(chunks <-chan StreamChunk) (string, error) {
+	var text strings.Builder
+	var err error
+	for chunk := range chunks {
+		text.WriteString(chunk.Text)
+		if chunk.Err != nil {
+			err = chunk.Err
+		}
+	}
+	return text.String(), err
+}
+
+// PartialText returns the text generated before a stream failed, or ""
+// if err does not carry partial output.
+func PartialText(err error) string {
+	var partial *PartialError
+	if errors.As(err, &partial) {
+		return partial.Text
+	}
+	return ""
+}
+
+// StreamOption sets a callback of GenerateWithCallbacks.
+type StreamOption func(*streamCallbacks)
+
+// streamCallbacks holds the callbacks of GenerateWithCallbacks.
+type streamCallbacks struct {
+	onToken func(string)
+	onUsage func(Usage)
+}
+
+// OnToken calls fn with each piece of text as it arrives, for rendering a
+// response token by token. Pieces may hold several tokens, depending on
+// the provider.
+func OnToken(fn func(text string)) StreamOption {
+	return func(c *streamCallbacks) {
+		c.onToken = fn
+	}
+}
+
+// OnUsage calls fn with the token usage of the generation once it
+// completes, if the provider reports it.
+func OnUsage(fn func(usage Usage)) StreamOption {
+	return func(c *streamCallbacks) {
+		c.onUsage = fn
+	}
+}
+
+// GenerateWithCallbacks streams a generation from client like
+// GenerateStream, but delivers it to callbacks instead of a channel, and
+// returns the full text once the stream ends. Callbacks run on the calling
+// goroutine, one at a time and in order. While a callback runs, no more of
+// the response is read, so a slow consumer such as a terminal applies
+// backpressure to the provider rather than buffering the response.
+//
+//	text, err := xollm.GenerateWithCallbacks(ctx, client, prompt, xollm.OnToken(func(s string) {
+//		fmt.Print(s)
+//	}))
+//
+// If the stream fails after delivering text, the error is a *PartialError
+// and the text received so far is returned with it.
+func GenerateWithCallbacks(ctx context.Context, client Client, prompt string, opts ...StreamOption) (string, error) {
+	var callbacks streamCallbacks
+	for _, opt := range opts {
+		opt(&callbacks)
+	}
+
+	chunks, err := GenerateStream(ctx, client, prompt)
+	if err != nil {
+		return "", err
+	}
+	var text strings.Builder
+	for chunk := range chunks {
+		if chunk.Text != "" {
+			text.WriteString(chunk.Text)
+			if callbacks.onToken != nil {
+				callbacks.onToken(chunk.Text)
+			}
+		}
+		if chunk.Err != nil {
+			err = chunk.Err
+		}
+		if chunk.Done && chunk.Usage != nil && callbacks.onUsage != nil {
+			callbacks.onUsage(*chunk.Usage)
+		}
+	}
+	return text.String(), err
+}
+
+// ErrStreamCancelled ends streams stopped with Stream.Cancel.
+var ErrStreamCancelled = errors.New("stream cancelled")
+
+// Stream is a streamed generation that can be stopped before it completes,
+// e.g. by a "stop generating" button, independently of the context it was
+// opened with.
+//
+//	stream, err := xollm.OpenStream(ctx, client, prompt)
+//	if err != nil {
+//		return err
+//	}
+//	go func() {
+//		<-stopButton
+//		usage := stream.Cancel()
+//		log.Printf("stopped after %d tokens", usage.TotalTokens())
+//	}()
+//	for chunk := range stream.Chunks() {
+//		fmt.Print(chunk.Text)
+//	}
+//
+// A Stream is safe for concurrent use.
+type Stream struct {
+	prompt string
+	chunks chan StreamChunk
+	cancel context.CancelCauseFunc
+	ended  chan struct{} // Closed once the provider stream has ended
+
+	mu    sync.Mutex
+	text  strings.Builder
+	usage *Usage // Reported by the provider, if it did
+}
+
+// OpenStream starts streaming a generation from client like GenerateStream,
+// returning a Stream that can also be stopped with Cancel.
+func OpenStream(ctx context.Context, client Client, prompt string) (*Stream, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	in, err := GenerateStream(ctx, client, prompt)
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+	s := &Stream{
+		prompt: prompt,
+		// One slot keeps the terminal chunk of a cancelled stream for a
+		// consumer that is not reading at the time
+		chunks: make(chan StreamChunk, 1),
+		cancel: cancel,
+		ended:  make(chan struct{}),
+	}
+	go s.forward(ctx, in)
+	return s, nil
+}
+
+// forward passes the chunks of in to the consumer, recording the text and
+// usage. Once the stream is cancelled, the remaining text is discarded and
+// the terminal chunk reports ErrStreamCancelled.
+func (s *Stream) forward(ctx context.Context, in <-chan StreamChunk) {
+	defer close(s.ended)
+	defer close(s.chunks)
+	defer s.cancel(nil)
+	terminated := false
+	for chunk := range in {
+		s.mu.Lock()
+		s.text.WriteString(chunk.Text)
+		if chunk.Usage != nil {
+			s.usage = chunk.Usage
+		}
+		s.mu.Unlock()
+
+		if context.Cause(ctx) == ErrStreamCancelled {
+			continue
+		}
+		terminal := chunk.Done || chunk.Err != nil
+		select {
+		case s.chunks <- chunk:
+			terminated = terminal
+		case <-ctx.Done():
+			// Opened with a context that is now done; as with
+			// GenerateStream, a consumer that isn't reading misses the
+			// rest, but the terminal chunk is kept if there is room
+			if terminal {
+				select {
+				case s.chunks <- chunk:
+					terminated = true
+				default:
+				}
+			}
+		}
+	}
+	if !terminated && context.Cause(ctx) == ErrStreamCancelled {
+		s.deliverCancelled()
+	}
+}
+
+// deliverCancelled replaces text the consumer hasn't read yet with the
+// terminal chunk of a cancelled stream.
+func (s *Stream) deliverCancelled() {
+	var err error = ErrStreamCancelled
+	if text := s.Text(); text != "" {
+		err = &PartialError{Text: text, Err: ErrStreamCancelled}
+	}
+	select {
+	case <-s.chunks:
+	default:
+	}
+	s.chunks <- StreamChunk{Err: err} // The only sender, so there is room
+}
+
+// Chunks returns the channel the generation is delivered on, following the
+// contract of GenerateStream. After Cancel, it yields at most a terminal
+// chunk whose error wraps ErrStreamCancelled, then closes.
+func (s *Stream) Chunks() <-chan StreamChunk {
+	return s.chunks
+}
+
+// Cancel stops the generation, closes the connection to the provider and
+// waits for it to be released, then returns the tokens used so far. Calling
+// it again, or after the stream ended, only returns the usage.
+func (s *Stream) Cancel() Usage {
+	s.cancel(ErrStreamCancelled)
+	<-s.ended
+	return s.Usage()
+}
+
+// Text returns the text generated so far.
+func (s *Stream) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.text.String()
+}
+
+// Usage returns the tokens the generation used so far: the provider's
+// counts if the stream completed and reported them, otherwise an estimate
+// from the prompt and the text generated so far, marked Estimated.
+func (s *Stream) Usage() Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.usage != nil {
+		return *s.usage
+	}
+	return Usage{
+		PromptTokens:     EstimateTokens(s.prompt),
+		CompletionTokens: EstimateTokens(s.text.String()),
+		Estimated:        true,
+	}
+}
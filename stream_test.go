@@ -0,0 +1,345 @@
+package xollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubClient implements Client for testing
+type stubClient struct {
+	response string
+	err      error
+}
+
+func (s *stubClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return s.response, s.err
+}
+
+func (s *stubClient) ProviderName() string { return "stub" }
+
+func (s *stubClient) Close() error { return nil }
+
+// stubStreamer adds native streaming to stubClient
+type stubStreamer struct {
+	stubClient
+	chunks    []string
+	streamErr error // ends the stream with an error instead of Done
+}
+
+func (s *stubStreamer) GenerateStream(ctx context.Context, prompt string) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk, len(s.chunks)+1)
+	for _, c := range s.chunks {
+		ch <- StreamChunk{Text: c}
+	}
+	if s.streamErr != nil {
+		ch <- StreamChunk{Err: s.streamErr}
+	} else {
+		ch <- StreamChunk{Done: true}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func collectStream(t *testing.T, ch <-chan StreamChunk) ([]string, error) {
+	t.Helper()
+	var texts []string
+	var streamErr error
+	sawTerminal := false
+	for chunk := range ch {
+		if sawTerminal {
+			t.Error("Received chunk after terminal chunk")
+		}
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+			sawTerminal = true
+			continue
+		}
+		if chunk.Done {
+			sawTerminal = true
+			continue
+		}
+		texts = append(texts, chunk.Text)
+	}
+	if !sawTerminal {
+		t.Error("Stream closed without a terminal chunk")
+	}
+	return texts, streamErr
+}
+
+func TestGenerateStream_FallbackToGenerate(t *testing.T) {
+	ch, err := GenerateStream(context.Background(), &stubClient{response: "full answer"}, "prompt")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	texts, streamErr := collectStream(t, ch)
+	if streamErr != nil {
+		t.Fatalf("Expected no stream error, got: %v", streamErr)
+	}
+	if len(texts) != 1 || texts[0] != "full answer" {
+		t.Errorf("Expected single chunk 'full answer', got %v", texts)
+	}
+}
+
+func TestGenerateStream_FallbackError(t *testing.T) {
+	ch, err := GenerateStream(context.Background(), &stubClient{err: errors.New("boom")}, "prompt")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	_, streamErr := collectStream(t, ch)
+	if streamErr == nil || streamErr.Error() != "boom" {
+		t.Errorf("Expected stream error 'boom', got: %v", streamErr)
+	}
+}
+
+func TestGenerateStream_UsesNativeStreamer(t *testing.T) {
+	client := &stubStreamer{chunks: []string{"Hel", "lo"}}
+	ch, err := GenerateStream(context.Background(), client, "prompt")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	texts, streamErr := collectStream(t, ch)
+	if streamErr != nil {
+		t.Fatalf("Expected no stream error, got: %v", streamErr)
+	}
+	if len(texts) != 2 || texts[0] != "Hel" || texts[1] != "lo" {
+		t.Errorf("Expected native chunks [Hel lo], got %v", texts)
+	}
+}
+
+func TestGenerateStream_PartialError(t *testing.T) {
+	cause := errors.New("connection reset")
+	client := &stubStreamer{chunks: []string{"Once upon ", "a time"}, streamErr: cause}
+	ch, err := GenerateStream(context.Background(), client, "prompt")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	text, streamErr := CollectStream(ch)
+	if text != "Once upon a time" {
+		t.Errorf("Expected collected text, got '%s'", text)
+	}
+	var partial *PartialError
+	if !errors.As(streamErr, &partial) {
+		t.Fatalf("Expected *PartialError, got %T: %v", streamErr, streamErr)
+	}
+	if partial.Text != "Once upon a time" || !errors.Is(streamErr, cause) {
+		t.Errorf("Unexpected partial error: %+v", partial)
+	}
+	if PartialText(streamErr) != "Once upon a time" {
+		t.Errorf("Expected PartialText to return the partial output")
+	}
+}
+
+func TestGenerateStream_ErrorWithoutText(t *testing.T) {
+	cause := errors.New("rejected")
+	ch, _ := GenerateStream(context.Background(), &stubStreamer{streamErr: cause}, "prompt")
+
+	_, streamErr := CollectStream(ch)
+	if streamErr != cause {
+		t.Errorf("Expected the original error when no text was produced, got: %v", streamErr)
+	}
+	if PartialText(streamErr) != "" {
+		t.Error("Expected no partial text")
+	}
+}
+
+func TestCollectStream_Success(t *testing.T) {
+	ch, _ := GenerateStream(context.Background(), &stubStreamer{chunks: []string{"a", "b"}}, "prompt")
+	text, err := CollectStream(ch)
+	if err != nil || text != "ab" {
+		t.Errorf("Expected 'ab', got '%s' (%v)", text, err)
+	}
+}
+
+// pacedStreamer streams over an unbuffered channel, counting the chunks
+// the consumer has taken
+type pacedStreamer struct {
+	stubClient
+	chunks []string
+	sent   atomic.Int32
+}
+
+func (s *pacedStreamer) GenerateStream(ctx context.Context, prompt string) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		for _, c := range s.chunks {
+			ch <- StreamChunk{Text: c}
+			s.sent.Add(1)
+		}
+		ch <- StreamChunk{Done: true, Usage: &Usage{PromptTokens: 1, CompletionTokens: len(s.chunks)}}
+	}()
+	return ch, nil
+}
+
+func TestGenerateWithCallbacks(t *testing.T) {
+	client := &pacedStreamer{chunks: []string{"a", "b", "c", "d", "e"}}
+	var tokens []string
+	var usage Usage
+	text, err := GenerateWithCallbacks(context.Background(), client, "prompt",
+		OnToken(func(s string) {
+			// The producer can only be a chunk or two ahead of a slow consumer
+			if ahead := int(client.sent.Load()) - len(tokens); ahead > 2 {
+				t.Errorf("Expected backpressure, producer is %d chunks ahead", ahead)
+			}
+			time.Sleep(5 * time.Millisecond)
+			tokens = append(tokens, s)
+		}),
+		OnUsage(func(u Usage) { usage = u }),
+	)
+	if err != nil || text != "abcde" {
+		t.Fatalf("Expected \"abcde\", got %q, %v", text, err)
+	}
+	if strings.Join(tokens, ",") != "a,b,c,d,e" {
+		t.Errorf("Expected every token in order, got %v", tokens)
+	}
+	if usage.CompletionTokens != 5 {
+		t.Errorf("Expected the usage callback, got %+v", usage)
+	}
+}
+
+func TestGenerateWithCallbacks_PartialFailure(t *testing.T) {
+	failure := errors.New("connection reset")
+	client := &stubStreamer{chunks: []string{"Hel", "lo"}, streamErr: failure}
+	var tokens []string
+	text, err := GenerateWithCallbacks(context.Background(), client, "prompt", OnToken(func(s string) { tokens = append(tokens, s) }))
+	if text != "Hello" || !errors.Is(err, failure) || PartialText(err) != "Hello" {
+		t.Errorf("Expected the partial text and a *PartialError, got %q, %v", text, err)
+	}
+	if len(tokens) != 2 {
+		t.Errorf("Expected both tokens, got %v", tokens)
+	}
+}
+
+func TestGenerateWithCallbacks_NonStreaming(t *testing.T) {
+	var tokens []string
+	text, err := GenerateWithCallbacks(context.Background(), &stubClient{response: "whole"}, "prompt", OnToken(func(s string) { tokens = append(tokens, s) }))
+	if err != nil || text != "whole" || len(tokens) != 1 || tokens[0] != "whole" {
+		t.Errorf("Expected a single token with the whole response, got %q, %v, %v", text, tokens, err)
+	}
+}
+
+// endlessStreamer streams "token " until its context is cancelled, like a
+// provider connection, and records when it has let go of it
+type endlessStreamer struct {
+	stubClient
+	closed atomic.Bool
+}
+
+func (s *endlessStreamer) GenerateStream(ctx context.Context, prompt string) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		defer s.closed.Store(true)
+		for {
+			select {
+			case ch <- StreamChunk{Text: "token "}:
+			case <-ctx.Done():
+				return // connection closed without a terminal chunk
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func TestStream_Cancel(t *testing.T) {
+	streamer := &endlessStreamer{}
+	stream, err := OpenStream(context.Background(), streamer, "write forever")
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if chunk := <-stream.Chunks(); chunk.Text != "token " {
+			t.Fatalf("Unexpected chunk %+v", chunk)
+		}
+	}
+
+	usage := stream.Cancel()
+	if !streamer.closed.Load() {
+		t.Error("Expected the provider stream to be closed when Cancel returns")
+	}
+	if !usage.Estimated || usage.CompletionTokens < 3 || usage.PromptTokens == 0 {
+		t.Errorf("Expected an estimate of the tokens used so far, got %+v", usage)
+	}
+
+	var terminal *StreamChunk
+	for chunk := range stream.Chunks() {
+		if chunk.Text != "" {
+			t.Errorf("Expected no more text after Cancel, got %q", chunk.Text)
+		}
+		if chunk.Err != nil {
+			terminal = &chunk
+		}
+	}
+	if terminal == nil || !errors.Is(terminal.Err, ErrStreamCancelled) || !strings.HasPrefix(PartialText(terminal.Err), "token token token") {
+		t.Errorf("Expected a terminal chunk with ErrStreamCancelled and the partial text, got %+v", terminal)
+	}
+	if again := stream.Cancel(); again != stream.Usage() {
+		t.Errorf("Expected a second Cancel to return the same usage, got %+v", again)
+	}
+}
+
+func TestStream_Completed(t *testing.T) {
+	stream, err := OpenStream(context.Background(), &pacedStreamer{chunks: []string{"Hello", " world"}}, "hi")
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+	texts, err := collectStream(t, stream.Chunks())
+	if err != nil || strings.Join(texts, "") != "Hello world" {
+		t.Fatalf("Unexpected stream result %q, %v", texts, err)
+	}
+
+	// Cancelling a finished stream reports the provider's usage
+	if usage := stream.Cancel(); usage.Estimated || usage.CompletionTokens != 2 {
+		t.Errorf("Expected the reported usage, got %+v", usage)
+	}
+	if stream.Text() != "Hello world" {
+		t.Errorf("Unexpected text %q", stream.Text())
+	}
+}
+
+func TestStream_CancelNonStreaming(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	client := &blockingClient{release: release}
+	stream, err := OpenStream(context.Background(), client, "hi")
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+
+	done := make(chan Usage)
+	go func() { done <- stream.Cancel() }()
+	select {
+	case usage := <-done:
+		if usage.CompletionTokens != 0 {
+			t.Errorf("Expected no completion tokens, got %+v", usage)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Cancel to abort a blocked Generate")
+	}
+	if _, err := collectStream(t, stream.Chunks()); !errors.Is(err, ErrStreamCancelled) {
+		t.Errorf("Expected ErrStreamCancelled, got %v", err)
+	}
+}
+
+// blockingClient blocks in Generate until released or cancelled
+type blockingClient struct {
+	stubClient
+	release chan struct{}
+}
+
+func (c *blockingClient) Generate(ctx context.Context, prompt string) (string, error) {
+	select {
+	case <-c.release:
+		return "late", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,33 @@
+package xollm
+
+import (
+	"context"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// WithTags returns a copy of ctx carrying tags that label the requests made
+// with it, e.g. with the tenant or feature they belong to, for quotas, logs
+// and cost attribution. See llm.WithTags.
+//
+//	ctx = xollm.WithTags(ctx, map[string]string{"tenant": customerID})
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	return llm.WithTags(ctx, tags)
+}
+
+// TagsFromContext returns a copy of the tags carried by ctx.
+func TagsFromContext(ctx context.Context) map[string]string {
+	return llm.TagsFromContext(ctx)
+}
+
+// TagFromContext returns the value of one tag carried by ctx, or "".
+func TagFromContext(ctx context.Context, key string) string {
+	return llm.TagFromContext(ctx, key)
+}
+
+// WithSessionKey returns a copy of ctx whose requests belong to the
+// conversation key, so that clients balancing across several hosts with
+// WithStickySessions keep them on one host. See llm.WithSessionKey.
+func WithSessionKey(ctx context.Context, key string) context.Context {
+	return llm.WithSessionKey(ctx, key)
+}
@@ -0,0 +1,135 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// ModelInfo describes the limits of a model. See llm.ModelInfo.
+type ModelInfo = llm.ModelInfo
+
+// TokenCounter is implemented by clients that can count tokens exactly for
+// their model, typically through a provider counting endpoint.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, text string) (int, error)
+}
+
+// CountTokens counts the tokens in text for client's model.
+//
+// Clients that implement TokenCounter are asked for an exact count; for all
+// others the count comes from EstimateTokensFor.
+func CountTokens(ctx context.Context, client Client, text string) (int, error) {
+	if counter, ok := client.(TokenCounter); ok {
+		return counter.CountTokens(ctx, text)
+	}
+	return EstimateTokensFor(client, text), nil
+}
+
+// EstimateTokensFor counts the tokens in text locally with the tokenizer
+// registered for client's model (see TokenizerFor), without a provider
+// request, e.g. to reserve a budget before every request. Clients that
+// don't implement ModelNamer get the EstimateTokens heuristic.
+func EstimateTokensFor(client Client, text string) int {
+	if namer, ok := client.(ModelNamer); ok {
+		return llm.CountTokensFor(namer.ModelName(), text)
+	}
+	return EstimateTokens(text)
+}
+
+// EstimateTokens returns a rough, provider-independent token estimate for text.
+func EstimateTokens(text string) int {
+	return llm.EstimateTokens(text)
+}
+
+// Tokenizer counts tokens locally for a family of models. See
+// llm.Tokenizer.
+type Tokenizer = llm.Tokenizer
+
+// TokenizerFunc adapts a function to Tokenizer.
+type TokenizerFunc = llm.TokenizerFunc
+
+// ModelNamer is implemented by clients that report their model. All
+// bundled providers implement it.
+type ModelNamer = llm.ModelNamer
+
+// BPEEstimator estimates the tokens of OpenAI-compatible models'
+// tiktoken-style BPE tokenizers. See llm.BPEEstimator.
+type BPEEstimator = llm.BPEEstimator
+
+// SentencePieceEstimator estimates the tokens of Llama, Gemma and Mistral
+// models' SentencePiece tokenizers. See llm.SentencePieceEstimator.
+type SentencePieceEstimator = llm.SentencePieceEstimator
+
+// BPE is an exact byte-level BPE tokenizer. See llm.BPE.
+type BPE = llm.BPE
+
+// LoadTiktoken reads a tiktoken vocabulary file into an exact tokenizer,
+// e.g. to register it for a model with RegisterTokenizer.
+func LoadTiktoken(r io.Reader) (*BPE, error) {
+	return llm.LoadTiktoken(r)
+}
+
+// RegisterTokenizer makes t count the tokens of models whose name starts
+// with prefix. See llm.RegisterTokenizer.
+func RegisterTokenizer(prefix string, t Tokenizer) {
+	llm.RegisterTokenizer(prefix, t)
+}
+
+// TokenizerFor returns the tokenizer used for model. Models of the GPT,
+// Qwen and DeepSeek families get a BPEEstimator, Llama, Gemma, Gemini and
+// Mistral models a SentencePieceEstimator, others the EstimateTokens
+// heuristic.
+func TokenizerFor(model string) Tokenizer {
+	return llm.TokenizerFor(model)
+}
+
+// EstimateCounter is a TokenCounter backed by EstimateTokens.
+// Its zero value is ready to use.
+type EstimateCounter struct{}
+
+// CountTokens implements TokenCounter.
+func (EstimateCounter) CountTokens(ctx context.Context, text string) (int, error) {
+	return EstimateTokens(text), nil
+}
+
+// LookupModel returns the limits (context window, max output) for a model
+// name, as used by the trimming and budgeting helpers. Models are found in
+// the bundled registry or among those registered with RegisterModel, which
+// includes every model looked up with FetchModelInfo.
+func LookupModel(name string) (ModelInfo, bool) {
+	return llm.LookupModel(name)
+}
+
+// RegisterModel adds or replaces a model's limits in the registry used by
+// LookupModel, the pre-flight check and prompt trimming.
+func RegisterModel(info ModelInfo) {
+	llm.RegisterModel(info)
+}
+
+// ModelInfoProvider is implemented by clients that look up model limits
+// from the provider: Ollama through /api/show, Groq and Gemini through
+// their models endpoints.
+type ModelInfoProvider = llm.ModelInfoProvider
+
+// ContextWindowProvider is implemented by clients whose context window
+// differs from their model's registry entry, like Ollama's num_ctx.
+// Conversation trimming in the chat package prefers it to the registry.
+type ContextWindowProvider = llm.ContextWindowProvider
+
+// FetchModelInfo returns the limits and modality flags of model, or of the
+// client's own model if model is empty. Clients implementing
+// ModelInfoProvider ask the provider and register the answer, so later
+// pre-flight checks and trimming use the live limits. Other clients are
+// answered from the registry.
+func FetchModelInfo(ctx context.Context, client Client, model string) (ModelInfo, error) {
+	if provider, ok := client.(ModelInfoProvider); ok {
+		return provider.ModelInfo(ctx, model)
+	}
+	if info, found := LookupModel(model); found {
+		return info, nil
+	}
+	return ModelInfo{}, fmt.Errorf("no limits known for %s model %q", client.ProviderName(), model)
+}
@@ -0,0 +1,112 @@
+package xollm
+
+import (
+	"context"
+	"testing"
+)
+
+// countingClient adds exact token counting to stubClient
+type countingClient struct {
+	stubClient
+}
+
+func (c *countingClient) CountTokens(ctx context.Context, text string) (int, error) {
+	return len(text), nil
+}
+
+func TestCountTokens_UsesClientCounter(t *testing.T) {
+	n, err := CountTokens(context.Background(), &countingClient{}, "hello world")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if n != 11 {
+		t.Errorf("Expected exact count 11, got %d", n)
+	}
+}
+
+func TestCountTokens_FallsBackToEstimate(t *testing.T) {
+	n, err := CountTokens(context.Background(), &stubClient{}, "hello world")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if n != EstimateTokens("hello world") {
+		t.Errorf("Expected estimated count %d, got %d", EstimateTokens("hello world"), n)
+	}
+}
+
+func TestEstimateCounter(t *testing.T) {
+	var counter TokenCounter = EstimateCounter{}
+	n, err := counter.CountTokens(context.Background(), "abcdefgh")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 tokens, got %d", n)
+	}
+}
+
+func TestLookupModel_DefaultModels(t *testing.T) {
+	for _, name := range []string{"gemma-3-27b-it", "gemma2-9b-it", "gemma:2b"} {
+		info, ok := LookupModel(name)
+		if !ok {
+			t.Errorf("Expected default model '%s' to be known", name)
+			continue
+		}
+		if info.ContextWindow <= 0 {
+			t.Errorf("Expected positive context window for '%s'", name)
+		}
+	}
+}
+
+// infoClient adds model lookups to stubClient
+type infoClient struct {
+	stubClient
+}
+
+func (c *infoClient) ModelInfo(ctx context.Context, model string) (ModelInfo, error) {
+	return ModelInfo{Name: model, ContextWindow: 1234}, nil
+}
+
+func TestFetchModelInfo(t *testing.T) {
+	info, err := FetchModelInfo(context.Background(), &infoClient{}, "live-model")
+	if err != nil || info.ContextWindow != 1234 {
+		t.Errorf("Expected the client's answer, got %+v, %v", info, err)
+	}
+
+	info, err = FetchModelInfo(context.Background(), &stubClient{}, "gemma2-9b-it")
+	if err != nil || info.ContextWindow != 8192 {
+		t.Errorf("Expected the registry's answer, got %+v, %v", info, err)
+	}
+
+	if _, err := FetchModelInfo(context.Background(), &stubClient{}, "no-such-model"); err == nil {
+		t.Error("Expected an error for an unknown model")
+	}
+}
+
+// namedClient adds a model name to stubClient
+type namedClient struct {
+	stubClient
+	model string
+}
+
+func (c *namedClient) ModelName() string {
+	return c.model
+}
+
+func TestEstimateTokensFor(t *testing.T) {
+	text := "Count 1234567 tokens, please."
+	if got, want := EstimateTokensFor(&namedClient{model: "gpt-4o"}, text), (BPEEstimator{}).CountTokens(text); got != want {
+		t.Errorf("Expected the BPE estimate %d, got %d", want, got)
+	}
+	if got, want := EstimateTokensFor(&namedClient{model: "gemma:2b"}, text), (SentencePieceEstimator{}).CountTokens(text); got != want {
+		t.Errorf("Expected the SentencePiece estimate %d, got %d", want, got)
+	}
+	if got := EstimateTokensFor(&stubClient{}, text); got != EstimateTokens(text) {
+		t.Errorf("Expected clients without a model to use EstimateTokens, got %d", got)
+	}
+
+	n, err := CountTokens(context.Background(), &namedClient{model: "gpt-4o"}, text)
+	if err != nil || n != (BPEEstimator{}).CountTokens(text) {
+		t.Errorf("Expected CountTokens to fall back to the model's tokenizer, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,88 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// Tool describes a function the model may ask the caller to run, with a
+// JSON Schema for its arguments. See llm.Tool.
+type Tool = llm.Tool
+
+// ToolCall is a model's request to run a tool. See llm.ToolCall.
+type ToolCall = llm.ToolCall
+
+// ToolChoice controls whether the model calls tools: ToolChoiceAuto,
+// ToolChoiceNone, ToolChoiceRequired or the name of a tool to force.
+type ToolChoice = llm.ToolChoice
+
+// Tool choices.
+const (
+	ToolChoiceAuto     = llm.ToolChoiceAuto
+	ToolChoiceNone     = llm.ToolChoiceNone
+	ToolChoiceRequired = llm.ToolChoiceRequired
+)
+
+// ToolResponse is the text and tool calls returned by a generation with
+// tools. See llm.ToolResponse.
+type ToolResponse = llm.ToolResponse
+
+// ToolCaller is implemented by clients that support tool calling.
+type ToolCaller = llm.ToolCaller
+
+// GenerateWithTools sends prompt to client along with tools the model may
+// call. The caller runs the returned ToolCalls itself. It fails for clients
+// that don't implement ToolCaller.
+//
+//	resp, err := xollm.GenerateWithTools(ctx, client, "Weather in Oslo?", []xollm.Tool{{
+//		Name:        "get_weather",
+//		Description: "Get the current weather for a city",
+//		Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+//	}}, xollm.ToolChoiceAuto)
+func GenerateWithTools(ctx context.Context, client Client, prompt string, tools []Tool, choice ToolChoice) (*ToolResponse, error) {
+	caller, ok := client.(ToolCaller)
+	if !ok {
+		return nil, fmt.Errorf("%s client does not support tool calling", client.ProviderName())
+	}
+	return caller.GenerateWithTools(ctx, prompt, tools, choice)
+}
+
+// ToolChatter is implemented by clients that support tool calling in
+// conversations. See llm.ToolChatter.
+type ToolChatter = llm.ToolChatter
+
+// ToolResultMessage returns the message sending result, the output of
+// running call, back to the model.
+func ToolResultMessage(call ToolCall, result string) Message {
+	return llm.ToolResultMessage(call, result)
+}
+
+// ChatWithTools sends a conversation to client along with tools the model
+// may call. To let the model use the results of its calls, append the
+// response's Message and a ToolResultMessage per call to the conversation
+// and call ChatWithTools again, until the response has no tool calls. It
+// fails for clients that don't implement ToolChatter.
+//
+//	messages := []xollm.Message{{Role: xollm.RoleUser, Content: "Weather in Oslo?"}}
+//	for {
+//		resp, err := xollm.ChatWithTools(ctx, client, messages, tools, xollm.ToolChoiceAuto)
+//		if err != nil {
+//			return err
+//		}
+//		if len(resp.ToolCalls) == 0 {
+//			return show(resp.Text)
+//		}
+//		messages = append(messages, resp.Message())
+//		for _, call := range resp.ToolCalls {
+//			messages = append(messages, xollm.ToolResultMessage(call, run(call)))
+//		}
+//	}
+func ChatWithTools(ctx context.Context, client Client, messages []Message, tools []Tool, choice ToolChoice) (*ToolResponse, error) {
+	chatter, ok := client.(ToolChatter)
+	if !ok {
+		return nil, fmt.Errorf("%s client does not support tool calling in chats", client.ProviderName())
+	}
+	return chatter.ChatWithTools(ctx, messages, tools, choice)
+}
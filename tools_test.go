@@ -0,0 +1,80 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// toolClient adds tool calling to stubClient
+type toolClient struct {
+	stubClient
+}
+
+func (c *toolClient) GenerateWithTools(ctx context.Context, prompt string, tools []Tool, choice ToolChoice) (*ToolResponse, error) {
+	return &ToolResponse{ToolCalls: []ToolCall{{ID: "1", Name: tools[0].Name}}}, nil
+}
+
+func TestGenerateWithTools_UsesToolCaller(t *testing.T) {
+	resp, err := GenerateWithTools(context.Background(), &toolClient{}, "hi", []Tool{{Name: "lookup"}}, ToolChoiceAuto)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "lookup" {
+		t.Errorf("Expected a call to lookup, got %+v", resp.ToolCalls)
+	}
+}
+
+func TestGenerateWithTools_Unsupported(t *testing.T) {
+	_, err := GenerateWithTools(context.Background(), &stubClient{}, "hi", []Tool{{Name: "lookup"}}, ToolChoiceAuto)
+	if err == nil || !strings.Contains(err.Error(), "does not support tool calling") {
+		t.Errorf("Expected unsupported error, got: %v", err)
+	}
+}
+
+// toolChatClient answers the first turn with a tool call and the second
+// with the tool's result.
+type toolChatClient struct {
+	stubClient
+}
+
+func (c *toolChatClient) ChatWithTools(ctx context.Context, messages []Message, tools []Tool, choice ToolChoice) (*ToolResponse, error) {
+	last := messages[len(messages)-1]
+	if last.Role == RoleTool {
+		return &ToolResponse{Text: "It is " + last.Content}, nil
+	}
+	return &ToolResponse{ToolCalls: []ToolCall{{ID: "call_1", Name: tools[0].Name}}}, nil
+}
+
+func TestChatWithTools_Loop(t *testing.T) {
+	client := &toolChatClient{}
+	tools := []Tool{{Name: "get_weather"}}
+	messages := []Message{{Role: RoleUser, Content: "Weather in Oslo?"}}
+
+	resp, err := ChatWithTools(context.Background(), client, messages, tools, ToolChoiceAuto)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	messages = append(messages, resp.Message())
+	for _, call := range resp.ToolCalls {
+		messages = append(messages, ToolResultMessage(call, "sunny"))
+	}
+	if messages[1].Role != RoleAssistant || len(messages[1].ToolCalls) != 1 || messages[2].ToolCallID != "call_1" {
+		t.Fatalf("Unexpected conversation %+v", messages)
+	}
+
+	resp, err = ChatWithTools(context.Background(), client, messages, tools, ToolChoiceAuto)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if resp.Text != "It is sunny" || len(resp.ToolCalls) != 0 {
+		t.Errorf("Expected the final answer, got %+v", resp)
+	}
+}
+
+func TestChatWithTools_Unsupported(t *testing.T) {
+	_, err := ChatWithTools(context.Background(), &toolClient{}, []Message{{Role: RoleUser}}, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "does not support tool calling in chats") {
+		t.Errorf("Expected unsupported error, got: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package xollm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// Transcriber is implemented by clients that transcribe speech. The Groq
+// client implements it with its Whisper models. See llm.Transcriber.
+type Transcriber = llm.Transcriber
+
+// Audio is a recording to transcribe. See llm.Audio.
+type Audio = llm.Audio
+
+// TranscriptionOptions tune a transcription. See llm.TranscriptionOptions.
+type TranscriptionOptions = llm.TranscriptionOptions
+
+// Transcription is the text of a recording. See llm.Transcription.
+type Transcription = llm.Transcription
+
+// TranscriptionSegment is a timed stretch of a transcript. See
+// llm.TranscriptionSegment.
+type TranscriptionSegment = llm.TranscriptionSegment
+
+// AudioFromFile reads a recording from the file at path.
+func AudioFromFile(path string) (Audio, error) {
+	return llm.AudioFromFile(path)
+}
+
+// WithTranscriptionModel sets the model that transcribes the client's
+// speech. Groq clients default to whisper-large-v3-turbo.
+func WithTranscriptionModel(model string) ClientOption {
+	return llm.WithTranscriptionModel(model)
+}
+
+// Transcribe returns the text spoken in audio. It fails for clients that
+// don't implement Transcriber, such as Ollama and Gemini.
+//
+//	audio, err := xollm.AudioFromFile("meeting.m4a")
+//	...
+//	transcript, err := xollm.Transcribe(ctx, client, audio, xollm.TranscriptionOptions{Language: "en"})
+func Transcribe(ctx context.Context, client Client, audio Audio, opts TranscriptionOptions) (*Transcription, error) {
+	t, ok := client.(Transcriber)
+	if !ok {
+		return nil, fmt.Errorf("%s client does not support transcription", client.ProviderName())
+	}
+	return t.Transcribe(ctx, audio, opts)
+}
@@ -0,0 +1,39 @@
+package xollm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// transcribingClient returns the audio's filename as its transcript
+type transcribingClient struct {
+	stubClient
+	opts TranscriptionOptions
+}
+
+func (c *transcribingClient) Transcribe(ctx context.Context, audio Audio, opts TranscriptionOptions) (*Transcription, error) {
+	c.opts = opts
+	return &Transcription{Text: "heard " + audio.Filename}, nil
+}
+
+func TestTranscribe(t *testing.T) {
+	client := &transcribingClient{}
+	got, err := Transcribe(context.Background(), client, Audio{Filename: "memo.wav", Data: []byte("RIFF")}, TranscriptionOptions{Language: "de"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got.Text != "heard memo.wav" {
+		t.Errorf("Unexpected transcript %q", got.Text)
+	}
+	if client.opts.Language != "de" {
+		t.Errorf("Expected the options to be passed on, got %+v", client.opts)
+	}
+}
+
+func TestTranscribe_Unsupported(t *testing.T) {
+	_, err := Transcribe(context.Background(), &stubClient{}, Audio{Data: []byte("RIFF")}, TranscriptionOptions{})
+	if err == nil || !strings.Contains(err.Error(), "does not support transcription") {
+		t.Errorf("Expected an unsupported error, got: %v", err)
+	}
+}
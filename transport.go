@@ -0,0 +1,67 @@
+package xollm
+
+import (
+	"net/http"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// TransportOptions tune the connection pool shared by all provider clients.
+// See llm.TransportOptions.
+type TransportOptions = llm.TransportOptions
+
+// ConfigureTransport replaces the HTTP transport shared by provider clients
+// with one tuned by opts. Call it once at startup, before creating clients:
+//
+//	xollm.ConfigureTransport(xollm.TransportOptions{
+//		MaxIdleConnsPerHost: 64,
+//		IdleConnTimeout:     2 * time.Minute,
+//	})
+//
+// Clients created earlier keep using the previous transport. To tune a
+// single client instead, use WithTransportOptions.
+func ConfigureTransport(opts TransportOptions) {
+	llm.ConfigureSharedTransport(opts)
+}
+
+// WithTransportOptions gives a client a transport of its own tuned by opts
+// instead of the shared one, e.g. tighter timeouts for an interactive
+// client while batch clients keep the defaults:
+//
+//	client, err := groq.NewClient(ctx, key, "", 60, false,
+//		xollm.WithTransportOptions(xollm.TransportOptions{
+//			DialTimeout:           2 * time.Second,
+//			ResponseHeaderTimeout: 10 * time.Second,
+//		}))
+//
+// Zero fields take the value from llm.DefaultTransportOptions, not from
+// ConfigureTransport. The client pools its connections separately.
+func WithTransportOptions(opts TransportOptions) ClientOption {
+	return llm.WithTransportOptions(opts)
+}
+
+// DialFunc opens a network connection, like net.Dialer.DialContext.
+type DialFunc = llm.DialFunc
+
+// WithDialer makes a client open its connections with dial instead of a
+// plain TCP dialer, to route provider traffic through a SOCKS5 proxy, a
+// WireGuard interface or a custom DNS resolver. The client keeps the
+// settings of the shared transport but pools its connections separately:
+//
+//	socks, _ := proxy.SOCKS5("tcp", "127.0.0.1:1080", nil, proxy.Direct)
+//	client, err := groq.NewClient(ctx, key, "", 60, false,
+//		xollm.WithDialer(socks.(proxy.ContextDialer).DialContext))
+//
+// Set TransportOptions.DialContext instead to route every client. Every
+// request of the client goes through the dialer, Gemini's context caching
+// included.
+func WithDialer(dial DialFunc) ClientOption {
+	return llm.WithDialer(dial)
+}
+
+// WithTransport makes a client send its requests through rt instead of the
+// shared transport, e.g. for custom TLS settings or instrumentation.
+// Request hooks still run before rt.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return llm.WithTransport(rt)
+}
@@ -0,0 +1,18 @@
+package xollm
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xostack/xollm/llm"
+)
+
+func TestConfigureTransport(t *testing.T) {
+	ConfigureTransport(TransportOptions{MaxIdleConnsPerHost: 7})
+	defer ConfigureTransport(llm.DefaultTransportOptions)
+
+	tr, ok := llm.SharedTransport().(*http.Transport)
+	if !ok || tr.MaxIdleConnsPerHost != 7 {
+		t.Errorf("Expected transport with MaxIdleConnsPerHost 7, got %v", llm.SharedTransport())
+	}
+}
@@ -0,0 +1,84 @@
+package xollm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/xostack/xollm/llm"
+)
+
+// SchemaOf returns the JSON Schema of the values of T, with its struct
+// fields required unless tagged omitempty or pointers. See llm.SchemaFor.
+func SchemaOf[T any]() (json.RawMessage, error) {
+	return llm.SchemaFor(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// GenerateAsOption configures GenerateAs.
+type GenerateAsOption func(*generateAsOptions)
+
+// generateAsOptions holds the settings of GenerateAs.
+type generateAsOptions struct {
+	retries int
+}
+
+// WithOutputRetries makes GenerateAs ask again, up to n times, when the
+// response doesn't match the schema of the type, telling the model what
+// was wrong. By default it fails on the first malformed response.
+func WithOutputRetries(n int) GenerateAsOption {
+	return func(o *generateAsOptions) {
+		o.retries = n
+	}
+}
+
+// retryPrompt is the prompt of a request repeated after the response text
+// failed validation with err.
+func retryPrompt(prompt, text string, err error) string {
+	return prompt + "\n\nA previous response to this request was invalid:\n\n" + text +
+		"\n\nThe problem: " + err.Error() + "\n\nRespond again with corrected JSON and nothing else."
+}
+
+// GenerateAs asks client for a JSON response matching the schema of T (see
+// SchemaOf) with GenerateJSON, and decodes it into a T. T is usually a
+// struct; a response missing one of its required fields, or otherwise not
+// matching, fails with a *SchemaError, unless WithOutputRetries allows
+// asking again. Other failures, such as network errors, are returned at
+// once. It fails for clients that don't implement JSONGenerator.
+//
+//	type Review struct {
+//		Sentiment string   `json:"sentiment" description:"positive, negative or neutral"`
+//		Topics    []string `json:"topics"`
+//	}
+//	review, err := xollm.GenerateAs[Review](ctx, client, "Analyse this review: "+text, xollm.WithOutputRetries(2))
+func GenerateAs[T any](ctx context.Context, client Client, prompt string, opts ...GenerateAsOption) (T, error) {
+	var zero T
+	o := generateAsOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	schema, err := SchemaOf[T]()
+	if err != nil {
+		return zero, fmt.Errorf("no schema for %T: %w", zero, err)
+	}
+
+	request := prompt
+	for attempt := 0; ; attempt++ {
+		var value T
+		text, err := GenerateJSON(ctx, client, request, schema)
+		var schemaErr *SchemaError
+		malformed := errors.As(err, &schemaErr)
+		if err == nil {
+			if err = json.Unmarshal([]byte(text), &value); err == nil {
+				return value, nil
+			}
+			err = fmt.Errorf("%s response does not decode into %T: %w", client.ProviderName(), value, err)
+			malformed = true
+		}
+		if !malformed || attempt >= o.retries {
+			return zero, err
+		}
+		request = retryPrompt(prompt, text, err)
+	}
+}
@@ -0,0 +1,80 @@
+package xollm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// typedReview is the result type of the GenerateAs tests.
+type typedReview struct {
+	Sentiment string   `json:"sentiment"`
+	Topics    []string `json:"topics"`
+}
+
+// sequenceJSONClient returns its responses in turn, recording the prompts.
+type sequenceJSONClient struct {
+	stubClient
+	responses []string
+	prompts   []string
+}
+
+func (c *sequenceJSONClient) GenerateJSON(ctx context.Context, prompt string, schema json.RawMessage) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	response := c.responses[0]
+	if len(c.responses) > 1 {
+		c.responses = c.responses[1:]
+	}
+	return response, nil
+}
+
+func TestGenerateAs(t *testing.T) {
+	client := &jsonClient{stubClient: stubClient{response: `{"sentiment": "positive", "topics": ["price"]}`}}
+	review, err := GenerateAs[typedReview](context.Background(), client, "Analyse")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if review.Sentiment != "positive" || len(review.Topics) != 1 {
+		t.Errorf("Unexpected review: %+v", review)
+	}
+	if !strings.Contains(string(client.schema), `"required":["sentiment","topics"]`) {
+		t.Errorf("Expected the schema of the type, got %s", client.schema)
+	}
+}
+
+func TestGenerateAs_Retries(t *testing.T) {
+	client := &sequenceJSONClient{responses: []string{`{"sentiment": "positive"}`, `{"sentiment": "positive", "topics": []}`}}
+	_, err := GenerateAs[typedReview](context.Background(), client, "Analyse")
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || len(client.prompts) != 1 {
+		t.Fatalf("Expected a *SchemaError without retries, got %v after %d requests", err, len(client.prompts))
+	}
+
+	client = &sequenceJSONClient{responses: []string{`{"sentiment": "positive"}`, `{"sentiment": "positive", "topics": []}`}}
+	review, err := GenerateAs[typedReview](context.Background(), client, "Analyse", WithOutputRetries(2))
+	if err != nil {
+		t.Fatalf("Expected the retry to succeed, got: %v", err)
+	}
+	if review.Sentiment != "positive" || len(client.prompts) != 2 {
+		t.Errorf("Expected one retry, got %+v after %d requests", review, len(client.prompts))
+	}
+	if !strings.HasPrefix(client.prompts[1], "Analyse") || !strings.Contains(client.prompts[1], "topics") {
+		t.Errorf("Expected the retry to repeat the request and name the problem, got %q", client.prompts[1])
+	}
+}
+
+func TestGenerateAs_Errors(t *testing.T) {
+	failing := errors.New("network down")
+	client := &sequenceJSONClient{}
+	if _, err := GenerateAs[typedReview](context.Background(), &jsonClient{stubClient: stubClient{err: failing}}, "Analyse", WithOutputRetries(3)); !errors.Is(err, failing) {
+		t.Errorf("Expected the client's error without retries, got: %v", err)
+	}
+	if _, err := GenerateAs[chan int](context.Background(), client, "Analyse"); err == nil {
+		t.Error("Expected an error for a type without a schema")
+	}
+	if _, err := GenerateAs[typedReview](context.Background(), &stubClient{}, "Analyse"); err == nil {
+		t.Error("Expected an error for a client without structured output")
+	}
+}
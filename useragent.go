@@ -0,0 +1,24 @@
+package xollm
+
+import "github.com/xostack/xollm/llm"
+
+// SetAppIdentifier names the application in the User-Agent of every
+// provider request, "xollm/<version> (<app>)", so providers and internal
+// gateways can attribute the traffic. Call it once at startup:
+//
+//	xollm.SetAppIdentifier("support-bot/2.1")
+func SetAppIdentifier(app string) {
+	llm.SetAppIdentifier(app)
+}
+
+// WithAppIdentifier names the application in the User-Agent of one
+// client's requests, instead of the one set with SetAppIdentifier.
+func WithAppIdentifier(app string) ClientOption {
+	return llm.WithAppIdentifier(app)
+}
+
+// UserAgent returns the User-Agent sent with provider requests of clients
+// without their own application identifier.
+func UserAgent() string {
+	return llm.UserAgent("")
+}
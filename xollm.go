@@ -48,7 +48,10 @@ import (
 // abstracting away provider-specific implementation details while maintaining
 // a consistent API surface.
 //
-// All methods should be safe for concurrent use unless otherwise specified.
+// All methods must be safe for concurrent use unless otherwise specified.
+// The clients of the provider packages are, so one client can serve any
+// number of goroutines; their tests run concurrent calls under the race
+// detector (make test).
 type Client interface {
 	// Generate takes a context and a prompt string and returns the LLM's response string.
 	//